@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/course"
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// courseAliasStore loads and saves the course alias map (the preferences Gist)
+type courseAliasStore interface {
+	LoadCourseAliases(ctx context.Context) (course.Aliases, error)
+	SaveCourseAliases(ctx context.Context, aliases course.Aliases) error
+}
+
+// aliasStore holds the course aliases edited with /admin course-map
+var aliasStore courseAliasStore
+
+// courseAliases are the aliases courseClient matches with; nil until loaded
+var courseAliases course.Aliases
+
+// loadCourseAliases loads the course aliases on first use and hands them to courseClient
+func loadCourseAliases() (course.Aliases, error) {
+	if courseAliases != nil {
+		return courseAliases, nil
+	}
+	aliases := make(course.Aliases)
+	if aliasStore != nil {
+		var err error
+		if aliases, err = aliasStore.LoadCourseAliases(botCtx); err != nil {
+			return nil, fmt.Errorf("loading course aliases: %w", err)
+		}
+	}
+	setCourseAliases(aliases)
+	return aliases, nil
+}
+
+// setCourseAliases replaces the aliases courseClient matches with
+func setCourseAliases(aliases course.Aliases) {
+	courseAliases = aliases
+	if courseClient != nil {
+		courseClient.SetAliases(aliases)
+	}
+}
+
+// isAdmin reports whether chatID is the admin chat
+func isAdmin(chatID string) bool {
+	return *adminChat != "" && chatID == *adminChat
+}
+
+// handleAdmin handles maintainer commands. Outside the admin chat it answers like an
+// unknown command, so it isn't advertised.
+// Format: /admin course-map add|remove|list ...
+func handleAdmin(chatID string, parts []string, dryRun bool) (string, []*event.Event) {
+	if !isAdmin(chatID) {
+		return fmt.Sprintf("Unknown command: %s\n\nUse /help to see available commands.", parts[0]), nil
+	}
+	if len(parts) < 2 || strings.ToLower(parts[1]) != "course-map" {
+		return "🛠️ <b>Admin Commands</b>\n\n" +
+			"/admin course-map list - Show course aliases\n" +
+			"/admin course-map add &lt;course ID&gt; &lt;title pattern&gt; - Match events whose title contains the pattern to a course\n" +
+			"/admin course-map remove &lt;title pattern&gt; - Go back to the fuzzy match", nil
+	}
+	return handleCourseMap(chatID, parts[2:], dryRun), nil
+}
+
+// handleCourseMap lists and edits the course aliases consulted before the Golf Course
+// API's fuzzy match
+func handleCourseMap(chatID string, args []string, dryRun bool) string {
+	aliases, err := loadCourseAliases()
+	if err != nil {
+		reportError(err, "")
+		return "❌ Couldn't load the course aliases. Please try again later."
+	}
+
+	action := ""
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+
+	switch action {
+	case "", "list":
+		return formatCourseAliases(aliases)
+
+	case "add":
+		if len(args) < 3 {
+			return "❌ Usage: /admin course-map add &lt;course ID&gt; &lt;title pattern&gt;\n\nExample: /admin course-map add 12345 Bali Hai"
+		}
+		courseID, err := strconv.Atoi(args[1])
+		if err != nil || courseID <= 0 {
+			return fmt.Sprintf("❌ Invalid course ID: %s\n\nUse the Golf Course API's numeric course ID.", html.EscapeString(args[1]))
+		}
+		pattern := strings.Join(args[2:], " ")
+		updated := maps.Clone(aliases)
+		updated.Add(pattern, courseID, chatID, clk.Now())
+		if msg := saveCourseAliases(updated, dryRun); msg != "" {
+			return msg
+		}
+		return fmt.Sprintf("✅ Events with \"%s\" in the title now match course <code>%d</code>.", html.EscapeString(pattern), courseID)
+
+	case "remove":
+		if len(args) < 2 {
+			return "❌ Usage: /admin course-map remove &lt;title pattern&gt;"
+		}
+		pattern := strings.Join(args[1:], " ")
+		updated := maps.Clone(aliases)
+		if !updated.Remove(pattern) {
+			return fmt.Sprintf("ℹ️ No alias for \"%s\". Use /admin course-map list to see them.", html.EscapeString(pattern))
+		}
+		if msg := saveCourseAliases(updated, dryRun); msg != "" {
+			return msg
+		}
+		return fmt.Sprintf("✅ Removed the alias for \"%s\". Those events use the fuzzy match again.", html.EscapeString(pattern))
+	}
+
+	return fmt.Sprintf("❌ Unknown action: %s\n\nUse add, remove, or list.", html.EscapeString(action))
+}
+
+// saveCourseAliases saves edited aliases and starts matching with them; the loaded
+// aliases are left as they are if the save fails. Returns an error message for the
+// admin, or "" on success.
+func saveCourseAliases(aliases course.Aliases, dryRun bool) string {
+	if dryRun || aliasStore == nil {
+		return "[DRY RUN] Would save the course aliases"
+	}
+	if err := aliasStore.SaveCourseAliases(botCtx, aliases); err != nil {
+		reportError(fmt.Errorf("saving course aliases: %w", err), "")
+		return "❌ Couldn't save the course aliases. Please try again later."
+	}
+	setCourseAliases(aliases)
+	return ""
+}
+
+// formatCourseAliases lists the course aliases for /admin course-map list
+func formatCourseAliases(aliases course.Aliases) string {
+	if len(aliases) == 0 {
+		return "🗺️ No course aliases yet.\n\nUse /admin course-map add &lt;course ID&gt; &lt;title pattern&gt; to fix a wrong course match."
+	}
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("🗺️ <b>Course Aliases</b> (%d)\n\n", len(aliases)))
+	for _, alias := range aliases.List() {
+		msg.WriteString(fmt.Sprintf("• \"%s\" → <code>%d</code>\n", html.EscapeString(alias.Pattern), alias.CourseID))
+	}
+	msg.WriteString("\nEvents whose title contains a pattern use that course instead of the fuzzy match; the longest matching pattern wins.")
+	return msg.String()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/course"
+)
+
+// fakeAliasStore keeps course aliases in memory
+type fakeAliasStore struct {
+	saved   course.Aliases
+	saveErr error
+}
+
+func (s *fakeAliasStore) LoadCourseAliases(ctx context.Context) (course.Aliases, error) {
+	return make(course.Aliases), nil
+}
+
+func (s *fakeAliasStore) SaveCourseAliases(ctx context.Context, aliases course.Aliases) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.saved = aliases
+	return nil
+}
+
+func TestAdminCourseMap(t *testing.T) {
+	store := &fakeAliasStore{}
+	oldAdmin, oldStore, oldAliases := *adminChat, aliasStore, courseAliases
+	*adminChat, aliasStore, courseAliases = "999", store, nil
+	defer func() { *adminChat, aliasStore, courseAliases = oldAdmin, oldStore, oldAliases }()
+
+	modified := false
+	if response, _ := processCommand(nil, "12345", "/admin course-map list", &modified, "", false); !strings.Contains(response, "Unknown command") {
+		t.Errorf("non-admin /admin = %q, want unknown command", response)
+	}
+
+	response, _ := processCommand(nil, "999", "/admin course-map add 42 Bali Hai", &modified, "", false)
+	if !strings.Contains(response, "course <code>42</code>") {
+		t.Fatalf("add = %q", response)
+	}
+	if alias, ok := store.saved.Match("Bali Hai Golf Club"); !ok || alias.CourseID != 42 || alias.AddedBy != "999" {
+		t.Errorf("saved aliases = %+v, want Bali Hai → 42", store.saved)
+	}
+
+	if response, _ := processCommand(nil, "999", "/admin course-map list", &modified, "", false); !strings.Contains(response, `"Bali Hai" → <code>42</code>`) {
+		t.Errorf("list = %q", response)
+	}
+
+	// A failed save leaves the aliases in use as they were
+	store.saveErr = errors.New("gist unavailable")
+	if response, _ := processCommand(nil, "999", "/admin course-map remove bali hai", &modified, "", false); !strings.Contains(response, "Couldn't save") {
+		t.Errorf("remove with failing store = %q", response)
+	}
+	if _, ok := courseAliases.Match("Bali Hai"); !ok {
+		t.Error("failed save shouldn't drop the alias")
+	}
+
+	store.saveErr = nil
+	if response, _ := processCommand(nil, "999", "/admin course-map remove bali hai", &modified, "", false); !strings.Contains(response, "Removed") {
+		t.Errorf("remove = %q", response)
+	}
+	if len(courseAliases) != 0 {
+		t.Errorf("aliases after remove = %v, want none", courseAliases)
+	}
+	if modified {
+		t.Error("course aliases aren't user preferences; modified should stay false")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// builtinAliases are shortcuts everyone has: alias → command name
+var builtinAliases = map[string]string{
+	"e":  "events",
+	"me": "my-events",
+}
+
+// expandAlias replaces an alias at the start of a command with what it stands for, so
+// "/ne 25" with ne = "/near Las Vegas" becomes "/near Las Vegas 25". Commands always win
+// over aliases, and an expansion isn't expanded again. parts is returned unchanged when
+// parts[0] isn't an alias.
+func expandAlias(prefs preferences.Preferences, chatID string, parts []string) []string {
+	name := strings.TrimPrefix(normalizeCommand(parts[0]), "/")
+	if _, ok := commandsByName[name]; ok {
+		return parts
+	}
+	if target, ok := builtinAliases[name]; ok {
+		return append([]string{"/" + target}, parts[1:]...)
+	}
+	if user, ok := prefs[chatID]; ok {
+		if expansion := user.GetAlias(name); expansion != "" {
+			return append(strings.Fields(expansion), parts[1:]...)
+		}
+	}
+	return parts
+}
+
+// handleAlias lists, defines, or deletes the user's command shortcuts.
+// Format: /alias [name "/command args" | delete name]
+func handleAlias(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if len(args) == 0 {
+		return formatAliases(user)
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(args[0], "/"))
+	if len(args) == 2 && (name == "delete" || name == "remove") {
+		target := strings.ToLower(strings.TrimPrefix(args[1], "/"))
+		if !user.RemoveAlias(target) {
+			return fmt.Sprintf("ℹ️ You don't have an alias /%s", html.EscapeString(target))
+		}
+		*modified = true
+		return fmt.Sprintf("✅ Alias /%s deleted", target)
+	}
+	if len(args) == 1 {
+		if expansion := user.GetAlias(name); expansion != "" {
+			return fmt.Sprintf("🔗 /%s → <code>%s</code>", name, html.EscapeString(expansion))
+		}
+		return "❌ Please give the command the alias stands for.\n\nUsage: /alias &lt;name&gt; \"/command args\""
+	}
+
+	if _, ok := commandsByName[name]; ok {
+		return fmt.Sprintf("❌ /%s is already a command, so it can't be an alias.", name)
+	}
+	if _, ok := builtinAliases[name]; ok {
+		return fmt.Sprintf("❌ /%s is a built-in alias for /%s.", name, builtinAliases[name])
+	}
+
+	expansion, errMsg := validateUserInput(strings.Trim(strings.Join(args[1:], " "), `"'`), preferences.MaxAliasExpansionLength, "Alias command")
+	if errMsg != "" {
+		return errMsg
+	}
+	fields := strings.Fields(expansion)
+	target := strings.TrimPrefix(normalizeCommand(fields[0]), "/")
+	if _, ok := builtinAliases[target]; ok {
+		target = builtinAliases[target]
+	}
+	if _, ok := commandsByName[target]; !ok || !strings.HasPrefix(expansion, "/") || target == "alias" {
+		return fmt.Sprintf("❌ <code>%s</code> isn't a command an alias can stand for.\n\nAliases start with a command, e.g. /alias ne \"/near Las Vegas\"", html.EscapeString(expansion))
+	}
+	// Store the command itself, since expansions aren't expanded again: "/e NV" is saved
+	// as "/events NV"
+	expansion = strings.Join(append([]string{"/" + target}, fields[1:]...), " ")
+
+	if err := user.SetAlias(name, expansion); err != nil {
+		switch {
+		case errors.Is(err, preferences.ErrInvalidAliasName):
+			return "❌ Alias names are up to 20 lowercase letters, digits, or hyphens."
+		case errors.Is(err, preferences.ErrInvalidAliasExpansion):
+			return fmt.Sprintf("❌ Alias commands are up to %d characters.", preferences.MaxAliasExpansionLength)
+		case errors.Is(err, preferences.ErrTooManyAliases):
+			return fmt.Sprintf("❌ You already have %d aliases.\n\nDelete one with /alias delete &lt;name&gt; first.", preferences.MaxAliases)
+		}
+		return "❌ Couldn't save the alias."
+	}
+	*modified = true
+	return fmt.Sprintf("✅ /%s now runs <code>%s</code>\n\nAnything you type after it is added on the end.", name, html.EscapeString(user.GetAlias(name)))
+}
+
+// formatAliases lists the built-in aliases and the user's own
+func formatAliases(user *preferences.UserPreferences) string {
+	var msg strings.Builder
+	msg.WriteString("🔗 <b>Command Aliases</b>\n\n<b>Built-in:</b>\n")
+	for _, name := range slices.Sorted(maps.Keys(builtinAliases)) {
+		msg.WriteString(fmt.Sprintf("/%s → /%s\n", name, builtinAliases[name]))
+	}
+
+	msg.WriteString("\n<b>Yours:</b>\n")
+	if len(user.Aliases) == 0 {
+		msg.WriteString("None yet.\n")
+	}
+	for _, name := range slices.Sorted(maps.Keys(user.Aliases)) {
+		msg.WriteString(fmt.Sprintf("/%s → <code>%s</code>\n", name, html.EscapeString(user.Aliases[name])))
+	}
+
+	msg.WriteString("\nAdd one with /alias ne \"/near Las Vegas\", remove it with /alias delete ne.")
+	return msg.String()
+}
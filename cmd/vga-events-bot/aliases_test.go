@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestHandleAlias(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	for _, args := range [][]string{
+		{"events", "/search", "Vegas"},
+		{"e", "/search"},
+		{"x", "hello"},
+		{"x", "/nope"},
+		{"x", "/alias", "y", "/events"},
+	} {
+		if response := handleAlias(prefs, "111", args, &modified); !strings.HasPrefix(response, "❌") || modified {
+			t.Errorf("handleAlias(%q) should be refused, got %q", args, response)
+		}
+	}
+
+	response := handleAlias(prefs, "111", strings.Fields(`ne "/near Las Vegas"`), &modified)
+	if !modified || prefs.GetUser("111").GetAlias("ne") != "/near Las Vegas" {
+		t.Fatalf("define: %q", response)
+	}
+	if list := handleAlias(prefs, "111", nil, &modified); !strings.Contains(list, "/ne → <code>/near Las Vegas</code>") || !strings.Contains(list, "/me → /my-events") {
+		t.Errorf("list: %q", list)
+	}
+
+	// A built-in alias is stored as the command it stands for, so it still runs
+	if response := handleAlias(prefs, "111", []string{"nv", "/e", "NV"}, &modified); !strings.Contains(response, "<code>/events NV</code>") {
+		t.Errorf("alias of a built-in alias: %q", response)
+	}
+	if got := expandAlias(prefs, "111", []string{"/nv"}); !reflect.DeepEqual(got, []string{"/events", "NV"}) {
+		t.Errorf("expandAlias(/nv) = %q, want /events NV", got)
+	}
+
+	modified = false
+	if handleAlias(prefs, "111", []string{"delete", "ne"}, &modified); !modified || prefs.GetUser("111").GetAlias("ne") != "" {
+		t.Error("delete should remove the alias")
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	if err := prefs.GetUser("111").SetAlias("ne", "/near Las Vegas"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		chatID string
+		text   string
+		want   string
+	}{
+		{"111", "/ne 50", "/near Las Vegas 50"},
+		{"111", "/NE@VGAEventsBot", "/near Las Vegas"},
+		{"111", "/e NV", "/events NV"},
+		{"111", "/me", "/my-events"},
+		{"111", "/events", "/events"},
+		{"222", "/ne", "/ne"},
+	}
+	for _, tt := range tests {
+		got := expandAlias(prefs, tt.chatID, strings.Fields(tt.text))
+		if want := strings.Fields(tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("expandAlias(%q) = %q, want %q", tt.text, got, want)
+		}
+	}
+
+	if got := expandAlias(nil, "111", []string{"/ne"}); got[0] != "/ne" {
+		t.Errorf("nil prefs: %q", got)
+	}
+}
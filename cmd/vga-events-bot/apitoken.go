@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const apiTokenUsage = "/api-token new - Create a token (replaces any existing one)\n/api-token revoke - Stop the token from working"
+
+// handleAPIToken shows, creates, rotates, or revokes the user's personal API token
+func handleAPIToken(prefs preferences.Preferences, chatID string, args []string, baseURL string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	action := ""
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+
+	switch action {
+	case "":
+		if !user.HasAPIToken() {
+			return "🔑 <b>API Token</b>\n\nYou don't have an API token. A token lets you read your tracked events, filters, and calendar feed from the HTTP API.\n\n" + apiTokenUsage
+		}
+		created := time.Unix(user.APITokenCreated, 0).UTC()
+		return fmt.Sprintf("🔑 <b>API Token</b>\n\nYou have a token, created %s. It can't be shown again; create a new one if you've lost it.\n\n%s",
+			created.Format("Jan 2, 2006"), apiTokenUsage)
+
+	case "new", "rotate":
+		rotated := user.HasAPIToken()
+		token := user.NewAPIToken(clk.Now())
+		*modified = true
+
+		var msg strings.Builder
+		msg.WriteString("🔑 <b>Your API Token</b>\n\n")
+		msg.WriteString(fmt.Sprintf("<code>%s</code>\n\n", token))
+		if rotated {
+			msg.WriteString("Your previous token no longer works.\n\n")
+		}
+		msg.WriteString("⚠️ Keep it private: anyone with it can read your tracked events and notes. It won't be shown again, so save it now (and delete this message if you like).\n\n")
+		msg.WriteString(formatAPIEndpoints(baseURL, token))
+		msg.WriteString("\n\nUse /api-token revoke if it's ever exposed.")
+		return msg.String()
+
+	case "revoke":
+		if !user.RevokeAPIToken() {
+			return "ℹ️ You don't have an API token."
+		}
+		*modified = true
+		return "✅ API token revoked. It stops working within a minute."
+
+	default:
+		return fmt.Sprintf("❌ Unknown option: %s\n\n%s", html.EscapeString(args[0]), apiTokenUsage)
+	}
+}
+
+// formatAPIEndpoints lists the API endpoints, with full URLs when the API's base URL
+// is configured
+func formatAPIEndpoints(baseURL, token string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	var msg strings.Builder
+	msg.WriteString("<b>Endpoints</b> (send <code>Authorization: Bearer &lt;token&gt;</code>):\n")
+	for _, path := range []string{"/api/v1/me", "/api/v1/events", "/api/v1/tracked", "/api/v1/filters"} {
+		msg.WriteString(fmt.Sprintf("• %s%s\n", html.EscapeString(baseURL), path))
+	}
+	if baseURL != "" {
+		msg.WriteString(fmt.Sprintf("\n📅 Calendar feed to subscribe to:\n%s/api/v1/calendar.ics?token=%s", html.EscapeString(baseURL), token))
+	} else {
+		msg.WriteString("• /api/v1/calendar.ics?token=&lt;token&gt; (calendar feed)")
+	}
+	return msg.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestHandleAPIToken(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if response := handleAPIToken(prefs, "123", nil, "", &modified); modified || !strings.Contains(response, "don't have an API token") {
+		t.Errorf("status without token: %q", response)
+	}
+
+	response := handleAPIToken(prefs, "123", []string{"new"}, "https://api.example.com/", &modified)
+	if !modified || !strings.Contains(response, preferences.APITokenPrefix) {
+		t.Fatalf("unexpected response: %q", response)
+	}
+	if !strings.Contains(response, "https://api.example.com/api/v1/calendar.ics?token=vga_") {
+		t.Errorf("calendar feed URL missing: %q", response)
+	}
+	if _, ok := prefs.FindAPIToken(extractToken(response)); !ok {
+		t.Error("token in the reply should authenticate")
+	}
+
+	response = handleAPIToken(prefs, "123", []string{"rotate"}, "", &modified)
+	if !strings.Contains(response, "previous token no longer works") {
+		t.Errorf("rotation: %q", response)
+	}
+	if response := handleAPIToken(prefs, "123", nil, "", &modified); !strings.Contains(response, "You have a token") {
+		t.Errorf("status with token: %q", response)
+	}
+
+	modified = false
+	if response := handleAPIToken(prefs, "123", []string{"revoke"}, "", &modified); !modified || !strings.Contains(response, "revoked") {
+		t.Errorf("revoke: %q", response)
+	}
+	if response := handleAPIToken(prefs, "123", []string{"bogus"}, "", &modified); !strings.HasPrefix(response, "❌") {
+		t.Errorf("unknown option: %q", response)
+	}
+}
+
+// extractToken returns the token shown in a /api-token new reply
+func extractToken(response string) string {
+	_, rest, _ := strings.Cut(response, "<code>")
+	token, _, _ := strings.Cut(rest, "</code>")
+	return token
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// eventLookup holds what's known about event IDs when deciding what to archive
+type eventLookup struct {
+	events map[string]*event.Event // current and recently removed events
+	// complete is true when the lookup includes the snapshot's removed events, so an ID
+	// missing from it was removed long enough ago to have dropped out of the snapshot
+	complete bool
+}
+
+// loadEventLookup fetches current events and, if a snapshot directory is configured,
+// adds recently removed events from the snapshot
+func loadEventLookup() (*eventLookup, error) {
+	events, err := fetchEvents()
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no events returned")
+	}
+
+	lookup := &eventLookup{events: make(map[string]*event.Event, len(events))}
+	for _, evt := range events {
+		lookup.events[evt.ID] = evt
+	}
+
+	if snapshotStore != nil {
+		snapshot, err := snapshotStore.LoadSnapshot("all")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not load snapshot for removed events: %v\n", err)
+		} else if len(snapshot.Events) > 0 {
+			for id, evt := range snapshot.RemovedEvents {
+				if _, exists := lookup.events[id]; !exists {
+					lookup.events[id] = evt
+				}
+			}
+			lookup.complete = true
+		}
+	}
+
+	return lookup, nil
+}
+
+// isStale reports whether a tracked event should be archived: its date (or removal) is before
+// the cutoff, or it's gone from both the site and the snapshot
+func (l *eventLookup) isStale(eventID string, cutoff time.Time) bool {
+	evt, ok := l.events[eventID]
+	if !ok {
+		return l.complete
+	}
+
+	if date := event.ParseDate(evt.DateText); !date.IsZero() {
+		return date.Before(cutoff)
+	}
+	return !evt.RemovedAt.IsZero() && evt.RemovedAt.Before(cutoff)
+}
+
+// archiveStaleEvents archives statuses and notes of stale events for every user,
+// including inactive ones. Returns the number of events archived.
+func archiveStaleEvents(prefs preferences.Preferences, lookup *eventLookup, afterDays int, now time.Time) int {
+	cutoff := now.AddDate(0, 0, -afterDays)
+	archived := 0
+
+	for _, user := range prefs {
+		for _, eventID := range user.TrackedEventIDs() {
+			if !lookup.isStale(eventID, cutoff) {
+				continue
+			}
+
+			var title, date string
+			if evt, ok := lookup.events[eventID]; ok {
+				title, date = evt.Title, evt.DateText
+			}
+			if user.ArchiveEvent(eventID, title, date) {
+				archived++
+			}
+		}
+	}
+
+	return archived
+}
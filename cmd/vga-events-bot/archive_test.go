@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestArchiveStaleEvents(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+
+	past := &event.Event{ID: "past", Title: "Spring Open", DateText: "3.1.26"}
+	recent := &event.Event{ID: "recent", Title: "Fall Classic", DateText: "10.1.26"}
+	upcoming := &event.Event{ID: "upcoming", Title: "Winter Scramble", DateText: "12.1.26"}
+	removed := &event.Event{ID: "removed", Title: "Cancelled Cup", RemovedAt: now.AddDate(0, 0, -40)}
+
+	tests := []struct {
+		name     string
+		complete bool
+		want     []string // events left tracked
+	}{
+		{"with snapshot", true, []string{"recent", "upcoming"}},
+		{"without snapshot", false, []string{"gone", "recent", "upcoming"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := preferences.NewPreferences()
+			user := prefs.GetUser("12345")
+			for _, id := range []string{"past", "recent", "upcoming", "removed", "gone"} {
+				user.SetEventStatus(id, preferences.EventStatusRegistered)
+			}
+
+			lookup := &eventLookup{
+				events: map[string]*event.Event{
+					past.ID: past, recent.ID: recent, upcoming.ID: upcoming, removed.ID: removed,
+				},
+				complete: tt.complete,
+			}
+
+			archived := archiveStaleEvents(prefs, lookup, 30, now)
+			if archived != 5-len(tt.want) {
+				t.Errorf("archived %d events, want %d", archived, 5-len(tt.want))
+			}
+
+			got := user.TrackedEventIDs()
+			if len(got) != len(tt.want) {
+				t.Fatalf("tracked = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tracked = %v, want %v", got, tt.want)
+					break
+				}
+			}
+
+			if rec := user.ArchivedEvents["past"]; rec == nil || rec.Title != "Spring Open" {
+				t.Errorf("expected past event archived with title, got %+v", rec)
+			}
+		})
+	}
+}
@@ -18,12 +18,19 @@ func processBulkCommand(parts []string, prefs preferences.Preferences, chatID st
 	subcommand := strings.ToLower(parts[1])
 
 	switch subcommand {
+	case "select":
+		// /bulk select - pick events with checkboxes
+		return handleSelectMode(prefs, chatID, botToken, dryRun)
+
 	case "register":
 		// /bulk register <event_ids>
 		if len(parts) < 3 {
 			return "❌ Please specify event IDs.\n\nUsage: /bulk register &lt;event_id1&gt; &lt;event_id2&gt; ...\nUsage: /bulk register &lt;event_id1,event_id2,...&gt;", nil
 		}
-		eventIDs := parseBulkEventIDs(parts[2:])
+		eventIDs, unknown := resolveEventIDs(parseBulkEventIDs(parts[2:]))
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown), nil
+		}
 		return handleBulkRegister(prefs, chatID, eventIDs, modified)
 
 	case "note":
@@ -33,7 +40,10 @@ func processBulkCommand(parts []string, prefs preferences.Preferences, chatID st
 		}
 		// First part after "note" is event IDs (can be comma-separated or space-separated if quoted)
 		eventIDsPart := parts[2]
-		eventIDs := parseEventIDList(eventIDsPart)
+		eventIDs, unknown := resolveEventIDs(parseEventIDList(eventIDsPart))
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown), nil
+		}
 
 		// Rest is note text
 		noteText := strings.Join(parts[3:], " ")
@@ -50,10 +60,13 @@ func processBulkCommand(parts []string, prefs preferences.Preferences, chatID st
 		}
 		status := strings.ToLower(parts[2])
 		eventIDsPart := parts[3]
-		eventIDs := parseEventIDList(eventIDsPart)
+		eventIDs, unknown := resolveEventIDs(parseEventIDList(eventIDsPart))
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown), nil
+		}
 		return handleBulkStatus(prefs, chatID, status, eventIDs, modified)
 
 	default:
-		return fmt.Sprintf("❌ Unknown bulk subcommand: %s\n\nAvailable: register, note, status\nOr use /bulk without parameters for menu.", subcommand), nil
+		return fmt.Sprintf("❌ Unknown bulk subcommand: %s\n\nAvailable: select, register, note, status\nOr use /bulk without parameters for menu.", subcommand), nil
 	}
 }
@@ -4,10 +4,13 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
+	"github.com/pfrederiksen/vga-events/internal/calendar"
 	"github.com/pfrederiksen/vga-events/internal/event"
 	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
 )
 
 const (
@@ -205,3 +208,107 @@ func handleBulkStatus(prefs preferences.Preferences, chatID, status string, even
 
 	return response.String(), nil
 }
+
+// myEventsGroupKeyboard returns the actions on a whole /my-events status group: export
+// it, clear it (Maybe), or move events whose registration has closed to Maybe
+// (Interested, when there are any)
+func myEventsGroupKeyboard(status string, group []*event.Event) *telegram.InlineKeyboardMarkup {
+	row := []telegram.InlineKeyboardButton{
+		{Text: "📥 Export this group", CallbackData: "bulk:export-" + status},
+	}
+	switch status {
+	case preferences.EventStatusMaybe:
+		row = append(row, telegram.InlineKeyboardButton{Text: "🗑 Clear all Maybe", CallbackData: "bulk:clear-maybe"})
+	case preferences.EventStatusInterested:
+		if n := countPastDeadline(group); n > 0 {
+			row = append(row, telegram.InlineKeyboardButton{
+				Text:         fmt.Sprintf("⏬ Past deadline → Maybe (%d)", n),
+				CallbackData: "bulk:downgrade-interested",
+			})
+		}
+	}
+	return &telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{row}}
+}
+
+// deadlinePassed reports whether the event's registration deadline has passed
+func deadlinePassed(evt *event.Event) bool {
+	days, ok := evt.DaysUntilDeadlineAt(clk.Now())
+	return ok && days < 0
+}
+
+// countPastDeadline counts the events whose registration deadline has passed
+func countPastDeadline(events []*event.Event) int {
+	count := 0
+	for _, evt := range events {
+		if deadlinePassed(evt) {
+			count++
+		}
+	}
+	return count
+}
+
+// pastDeadlineEventIDs returns the user's Interested events, among those listed, whose
+// registration deadline has passed
+func pastDeadlineEventIDs(user *preferences.UserPreferences, allEvents []*event.Event) []string {
+	var eventIDs []string
+	for _, evt := range allEvents {
+		if user.GetEventStatus(evt.ID) == preferences.EventStatusInterested && deadlinePassed(evt) {
+			eventIDs = append(eventIDs, evt.ID)
+		}
+	}
+	return eventIDs
+}
+
+// exportStatusGroup sends the user's listed events with a status as one calendar file.
+//
+// Returns a response message with the number of events exported.
+func exportStatusGroup(prefs preferences.Preferences, chatID, status, botToken string, dryRun bool) string {
+	user := prefs.GetUser(chatID)
+	_, statusText := getStatusDisplay(status)
+	name := strings.ToLower(statusText)
+
+	eventIDs := user.GetEventsByStatus(status)
+	if len(eventIDs) == 0 {
+		return fmt.Sprintf("ℹ️ No %s events to export", name)
+	}
+
+	// Fetch current events to get full event data
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return "❌ Error fetching event data"
+	}
+
+	var events []*event.Event
+	for _, evt := range allEvents {
+		if user.GetEventStatus(evt.ID) == status {
+			events = append(events, evt)
+		}
+	}
+
+	if len(events) == 0 {
+		return fmt.Sprintf("ℹ️ None of your %s events are currently available on the VGA website", name)
+	}
+
+	// Generate combined .ics file
+	icsContent := calendar.GenerateMultiEventICS(events)
+	filename := fmt.Sprintf("vga-%s-events.ics", status)
+
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would export %d %s event(s) to calendar", len(events), name)
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		return errSendingCalendarFile
+	}
+
+	caption := fmt.Sprintf("📅 <b>Your %s Events</b>\n\n%d event(s) ready to import to your calendar!", statusText, len(events))
+	if err := client.SendDocument(botCtx, filename, []byte(icsContent), caption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
+		return errSendingCalendarFile
+	}
+
+	return fmt.Sprintf("✅ Calendar file sent with <b>%d</b> %s event(s)!", len(events), name)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pfrederiksen/vga-events/internal/event"
 	"github.com/pfrederiksen/vga-events/internal/preferences"
 )
 
@@ -298,3 +299,58 @@ func TestHandleBulkStatus(t *testing.T) {
 		})
 	}
 }
+
+// deadlineEvents have registration deadlines before and after the fake clock's date
+func deadlineEvents() []*event.Event {
+	return []*event.Event{
+		{ID: "closed", State: "NV", Title: "Wolf Creek", DateText: "Oct 30 2026", RegistrationDeadline: "Oct 10 2026"},
+		{ID: "open", State: "NV", Title: "Shadow Creek", DateText: "Nov 20 2026", RegistrationDeadline: "Nov 01 2026"},
+		{ID: "none", State: "NV", Title: "Paiute", DateText: "Nov 25 2026"},
+	}
+}
+
+func TestMyEventsGroupKeyboard(t *testing.T) {
+	useFakeClock(t)
+
+	tests := []struct {
+		status string
+		group  []*event.Event
+		want   []string
+	}{
+		{preferences.EventStatusRegistered, deadlineEvents(), []string{"bulk:export-registered"}},
+		{preferences.EventStatusMaybe, deadlineEvents(), []string{"bulk:export-maybe", "bulk:clear-maybe"}},
+		{preferences.EventStatusInterested, deadlineEvents(), []string{"bulk:export-interested", "bulk:downgrade-interested"}},
+		{preferences.EventStatusInterested, deadlineEvents()[1:], []string{"bulk:export-interested"}},
+	}
+	for _, tt := range tests {
+		if got := callbackData(myEventsGroupKeyboard(tt.status, tt.group)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("myEventsGroupKeyboard(%s, %d events) = %v, want %v", tt.status, len(tt.group), got, tt.want)
+		}
+	}
+}
+
+func TestDowngradePastDeadlineInterested(t *testing.T) {
+	useFakeClock(t)
+	serveEvents(t, deadlineEvents())
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	for _, evt := range deadlineEvents() {
+		user.SetEventStatus(evt.ID, preferences.EventStatusInterested)
+	}
+
+	modified := false
+	text, _ := handleBulkCallback(prefs, "123", "downgrade-interested", &modified, "token", false)
+	if !contains(text, "Marked <b>1</b> event(s) as <b>Maybe</b>") || !modified {
+		t.Errorf("downgrade = %q, want one event moved to Maybe", text)
+	}
+	if user.GetEventStatus("closed") != preferences.EventStatusMaybe ||
+		user.GetEventStatus("open") != preferences.EventStatusInterested ||
+		user.GetEventStatus("none") != preferences.EventStatusInterested {
+		t.Errorf("EventStatuses = %v, want only the closed event downgraded", user.EventStatuses)
+	}
+
+	text, _ = handleBulkCallback(prefs, "123", "downgrade-interested", &modified, "token", false)
+	if !contains(text, "None of your Interested events") {
+		t.Errorf("second downgrade = %q", text)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// calendarCacheTTL is how long an event's calendar file is reused before the event
+	// is fetched again, so date and city changes reach the file
+	calendarCacheTTL = time.Hour
+	// calendarCacheSize caps the cached calendar files; the oldest is dropped to make room
+	calendarCacheSize = 500
+)
+
+// calendarFile is an event's generated .ics file. FileID is set after the first upload,
+// and later taps re-send it by file_id instead of uploading the bytes again.
+type calendarFile struct {
+	Filename string
+	Caption  string
+	Label    string // "NV - Title", for dry runs
+	Content  []byte
+	FileID   string
+	cachedAt time.Time
+}
+
+// calendarCache keeps "Add to Calendar" files per event ID for the life of the process
+type calendarCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[string]*calendarFile
+}
+
+func newCalendarCache(ttl time.Duration, size int) *calendarCache {
+	return &calendarCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*calendarFile),
+	}
+}
+
+// calendarFiles caches the files sent by handleCalendarCallback
+var calendarFiles = newCalendarCache(calendarCacheTTL, calendarCacheSize)
+
+// get returns a copy of the cached file for eventID, if it's younger than the TTL
+func (c *calendarCache) get(eventID string, now time.Time) (calendarFile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	file, ok := c.entries[eventID]
+	if !ok || now.Sub(file.cachedAt) >= c.ttl {
+		return calendarFile{}, false
+	}
+	return *file, true
+}
+
+// put caches a newly generated file for eventID, replacing any older one, and returns
+// the cached copy
+func (c *calendarCache) put(eventID string, file calendarFile, now time.Time) calendarFile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[eventID]; !ok && len(c.entries) >= c.size {
+		var oldestID string
+		for id, entry := range c.entries {
+			if oldestID == "" || entry.cachedAt.Before(c.entries[oldestID].cachedAt) {
+				oldestID = id
+			}
+		}
+		delete(c.entries, oldestID)
+	}
+	file.cachedAt = now
+	c.entries[eventID] = &file
+	return file
+}
+
+// setFileID records the file_id Telegram gave eventID's file on upload. It's ignored if
+// the file was regenerated in the meantime.
+func (c *calendarCache) setFileID(eventID string, cachedAt time.Time, fileID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if file, ok := c.entries[eventID]; ok && file.cachedAt.Equal(cachedAt) {
+		file.FileID = fileID
+	}
+}
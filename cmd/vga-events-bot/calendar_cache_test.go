@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarCache(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	cache := newCalendarCache(time.Hour, 2)
+
+	first := cache.put("evt1", calendarFile{Content: []byte("ics1")}, now)
+	if _, ok := cache.get("evt1", now.Add(59*time.Minute)); !ok {
+		t.Fatal("file should be cached within the TTL")
+	}
+	if _, ok := cache.get("evt1", now.Add(time.Hour)); ok {
+		t.Error("file should expire after the TTL")
+	}
+
+	cache.setFileID("evt1", first.cachedAt, "doc1")
+	if file, _ := cache.get("evt1", now); file.FileID != "doc1" {
+		t.Errorf("FileID = %q, want doc1", file.FileID)
+	}
+
+	// A file_id from an upload of an older copy isn't kept
+	cache.put("evt1", calendarFile{Content: []byte("ics1b")}, now.Add(time.Minute))
+	cache.setFileID("evt1", first.cachedAt, "stale")
+	if file, _ := cache.get("evt1", now.Add(time.Minute)); file.FileID != "" {
+		t.Errorf("FileID = %q, want none for the regenerated file", file.FileID)
+	}
+
+	// The oldest file is dropped to make room
+	cache.put("evt2", calendarFile{}, now.Add(2*time.Minute))
+	cache.put("evt3", calendarFile{}, now.Add(3*time.Minute))
+	if _, ok := cache.get("evt1", now.Add(3*time.Minute)); ok {
+		t.Error("oldest file should be evicted")
+	}
+	if _, ok := cache.get("evt3", now.Add(3*time.Minute)); !ok {
+		t.Error("newest file should be cached")
+	}
+}
@@ -2,13 +2,13 @@ package main
 
 import (
 	"fmt"
+	"html"
 	"os"
 	"strings"
 
 	"github.com/pfrederiksen/vga-events/internal/calendar"
 	"github.com/pfrederiksen/vga-events/internal/event"
 	"github.com/pfrederiksen/vga-events/internal/preferences"
-	"github.com/pfrederiksen/vga-events/internal/scraper"
 	"github.com/pfrederiksen/vga-events/internal/telegram"
 )
 
@@ -24,7 +24,7 @@ func handleMenuCallback(param string, prefs preferences.Preferences, chatID stri
 		responseText, _ := handleMyEvents(prefs, chatID, botToken, dryRun, modified)
 		return responseText, nil
 	case "upcoming":
-		return handleUpcomingEventsCallback(prefs, chatID, botToken, dryRun), nil
+		return handleUpcomingEventsCallback(prefs, chatID, botToken, dryRun, modified), nil
 	case "reminders":
 		return showRemindersKeyboard(prefs, chatID)
 	case "search":
@@ -44,11 +44,32 @@ Example:
 	}
 }
 
+// handleRunCallback runs the command from a "Did you mean" suggestion button
+func handleRunCallback(callbackData string, prefs preferences.Preferences, chatID string, modified *bool, botToken string, dryRun bool) string {
+	// Format: run:COMMAND [ARGS]; arguments may themselves contain colons
+	commandText := strings.TrimSpace(strings.TrimPrefix(callbackData, "run:"))
+	if commandText == "" {
+		return "❌ Invalid command"
+	}
+
+	responseText, events := processCommand(prefs, chatID, "/"+commandText, modified, botToken, dryRun)
+	if len(events) > 0 {
+		sendResponse(botToken, chatID, responseText, events, dryRun)
+		responseText = ""
+	}
+	if responseText == "" {
+		// The command sent its own messages
+		responseText = fmt.Sprintf("▶️ Ran /%s", html.EscapeString(commandText))
+	}
+
+	return responseText
+}
+
 // handleStatusCallback handles event status update callbacks
 func handleStatusCallback(callbackData string, prefs preferences.Preferences, chatID string, modified *bool) string {
-	// Format: status:EVENT_ID:STATUS (e.g., "status:abc123:interested")
+	// Format: status:EVENT_ID:STATUS[:VARIANT_TAG] (e.g., "status:abc123:interested")
 	parts := strings.Split(callbackData, ":")
-	if len(parts) != 3 {
+	if len(parts) != 3 && len(parts) != 4 {
 		return "❌ Invalid status request"
 	}
 	eventID := parts[1]
@@ -133,50 +154,71 @@ func handleReminderCallback(callbackData string, prefs preferences.Preferences,
 	}
 }
 
-// handleCalendarCallback handles calendar download callbacks
+// handleCalendarCallback handles calendar download callbacks. Files are cached per event
+// (see calendarCache), so repeat taps skip fetching the events and re-send the uploaded
+// file by its file_id.
 func handleCalendarCallback(eventID string, chatID string, botToken string, dryRun bool) string {
-	// Fetch fresh events from VGA website to find the event
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
-		return "❌ Error fetching event data"
-	}
+	file, cached := calendarFiles.get(eventID, clk.Now())
+	if !cached {
+		// Fetch fresh events from VGA website to find the event
+		allEvents, err := fetchEvents()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+			return "❌ Error fetching event data"
+		}
 
-	// Find the event by ID
-	var evt *event.Event
-	for _, e := range allEvents {
-		if e.ID == eventID {
-			evt = e
-			break
+		// Find the event by ID
+		var evt *event.Event
+		for _, e := range allEvents {
+			if e.ID == eventID {
+				evt = e
+				break
+			}
 		}
-	}
 
-	if evt == nil {
-		fmt.Fprintf(os.Stderr, "Event %s not found in current events\n", eventID)
-		return "❌ Event not found. This event may have been removed from the VGA website."
-	}
+		if evt == nil {
+			fmt.Fprintf(os.Stderr, "Event %s not found in current events\n", eventID)
+			return "❌ Event not found. This event may have been removed from the VGA website."
+		}
 
-	// Generate .ics file
-	icsContent := calendar.GenerateICS(evt)
-	filename := fmt.Sprintf("vga-event-%s.ics", evt.State)
+		// Generate .ics file
+		file = calendarFile{
+			Filename: fmt.Sprintf("vga-event-%s.ics", evt.State),
+			Caption:  fmt.Sprintf("📅 <b>%s - %s</b>\n\nTap to add to your calendar!", evt.State, evt.Title),
+			Label:    fmt.Sprintf("%s - %s", evt.State, evt.Title),
+			Content:  []byte(calendar.GenerateICS(evt)),
+		}
+		file = calendarFiles.put(eventID, file, clk.Now())
+	}
 
 	// Send the .ics file
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating Telegram client: %v\n", err)
 			return errSendingCalendarFile
 		}
 
-		caption := fmt.Sprintf("📅 <b>%s - %s</b>\n\nTap to add to your calendar!", evt.State, evt.Title)
-		if err := client.SendDocument(filename, []byte(icsContent), caption); err != nil {
+		if file.FileID != "" {
+			err := client.SendDocumentID(botCtx, file.FileID, file.Caption)
+			if err == nil {
+				return "✅ Calendar file sent! Tap it to add the event to your calendar."
+			}
+			// Upload the bytes again below
+			fmt.Fprintf(os.Stderr, "Error re-sending cached calendar file: %v\n", err)
+		}
+
+		fileID, err := client.UploadDocument(botCtx, file.Filename, file.Content, file.Caption)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
 			return errSendingCalendarFile
 		}
+		if fileID != "" {
+			calendarFiles.setFileID(eventID, file.cachedAt, fileID)
+		}
 
 		return "✅ Calendar file sent! Tap it to add the event to your calendar."
 	}
 
-	return fmt.Sprintf("[DRY RUN] Would send .ics file for event: %s - %s", evt.State, evt.Title)
+	return fmt.Sprintf("[DRY RUN] Would send .ics file for event: %s", file.Label)
 }
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// sentCall is one Telegram API call a handler made
+type sentCall struct {
+	Method    string
+	ChatID    string
+	MessageID int
+	Text      string
+	Keyboard  *telegram.InlineKeyboardMarkup
+	Filename  string
+}
+
+// recordingSender records the calls made for one chat instead of sending them
+type recordingSender struct {
+	chatID string
+	calls  *[]sentCall
+}
+
+func (s *recordingSender) record(call sentCall) {
+	call.ChatID = s.chatID
+	*s.calls = append(*s.calls, call)
+}
+
+func (s *recordingSender) SendMessage(_ context.Context, text string) error {
+	s.record(sentCall{Method: "SendMessage", Text: text})
+	return nil
+}
+
+func (s *recordingSender) SendMessageWithKeyboard(_ context.Context, text string, keyboard *telegram.InlineKeyboardMarkup) error {
+	s.record(sentCall{Method: "SendMessageWithKeyboard", Text: text, Keyboard: keyboard})
+	return nil
+}
+
+// SendTrackedMessage records like SendMessageWithKeyboard, numbering messages by call
+func (s *recordingSender) SendTrackedMessage(_ context.Context, text string, keyboard *telegram.InlineKeyboardMarkup) (int, error) {
+	messageID := len(*s.calls) + 1
+	s.record(sentCall{Method: "SendMessageWithKeyboard", MessageID: messageID, Text: text, Keyboard: keyboard})
+	return messageID, nil
+}
+
+func (s *recordingSender) EditMessageText(_ context.Context, chatID string, messageID int, text string, keyboard *telegram.InlineKeyboardMarkup) error {
+	*s.calls = append(*s.calls, sentCall{Method: "EditMessageText", ChatID: chatID, MessageID: messageID, Text: text, Keyboard: keyboard})
+	return nil
+}
+
+func (s *recordingSender) AnswerCallbackQuery(_ context.Context, _ string, text string, _ bool) error {
+	s.record(sentCall{Method: "AnswerCallbackQuery", Text: text})
+	return nil
+}
+
+func (s *recordingSender) SendPhoto(_ context.Context, photoURL, caption string) error {
+	s.record(sentCall{Method: "SendPhoto", Text: caption, Filename: photoURL})
+	return nil
+}
+
+func (s *recordingSender) SendVoice(_ context.Context, fileID, caption string) error {
+	s.record(sentCall{Method: "SendVoice", Text: caption, Filename: fileID})
+	return nil
+}
+
+func (s *recordingSender) SendDocument(_ context.Context, filename string, _ []byte, caption string) error {
+	s.record(sentCall{Method: "SendDocument", Text: caption, Filename: filename})
+	return nil
+}
+
+func (s *recordingSender) SendDocumentID(_ context.Context, fileID, caption string) error {
+	s.record(sentCall{Method: "SendDocumentID", Text: caption, Filename: fileID})
+	return nil
+}
+
+func (s *recordingSender) UploadDocument(_ context.Context, filename string, _ []byte, caption string) (string, error) {
+	s.record(sentCall{Method: "UploadDocument", Text: caption, Filename: filename})
+	return "file-" + filename, nil
+}
+
+func (s *recordingSender) DownloadFile(_ context.Context, _ string) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *recordingSender) SendPoll(_ context.Context, question string, _ []string) (*telegram.SentPoll, error) {
+	s.record(sentCall{Method: "SendPoll", Text: question})
+	return &telegram.SentPoll{}, nil
+}
+
+func (s *recordingSender) StopPoll(_ context.Context, messageID int) error {
+	s.record(sentCall{Method: "StopPoll", MessageID: messageID})
+	return nil
+}
+
+// recordSends replaces newSender for the rest of the test, returning the calls made
+func recordSends(t *testing.T) *[]sentCall {
+	t.Helper()
+	calls := &[]sentCall{}
+	original := newSender
+	newSender = func(_, chatID string) (Sender, error) {
+		return &recordingSender{chatID: chatID, calls: calls}, nil
+	}
+	t.Cleanup(func() { newSender = original })
+	return calls
+}
+
+// serveEvents makes fetchEvents return events for the rest of the test
+func serveEvents(t *testing.T, events []*event.Event) {
+	t.Helper()
+	original, originalCache := scrapeEvents, calendarFiles
+	scrapeEvents = func(context.Context) ([]*event.Event, error) {
+		return events, nil
+	}
+	calendarFiles = newCalendarCache(calendarCacheTTL, calendarCacheSize)
+	t.Cleanup(func() { scrapeEvents, calendarFiles = original, originalCache })
+}
+
+// callbackData lists the callback data of a keyboard's buttons
+func callbackData(keyboard *telegram.InlineKeyboardMarkup) []string {
+	var data []string
+	if keyboard != nil {
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				data = append(data, button.CallbackData)
+			}
+		}
+	}
+	return data
+}
+
+func callbackEvents() []*event.Event {
+	return []*event.Event{
+		{ID: "evt1", State: "NV", Title: "Wolf Creek", City: "Mesquite", DateText: futureDate(10)},
+		{ID: "evt2", State: "NV", Title: "Shadow Creek", City: "Las Vegas", DateText: futureDate(20)},
+		{ID: "evt3", State: "CA", Title: "Pebble Beach", City: "Pebble Beach", DateText: futureDate(30)},
+	}
+}
+
+func TestHandleCallbackQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		setup        func(user *preferences.UserPreferences)
+		wantText     string   // In the edited message
+		wantKeyboard string   // Callback data of a button on the edited message
+		wantSent     []string // Other calls, in order
+		wantModified bool
+		check        func(t *testing.T, user *preferences.UserPreferences, calls []sentCall)
+	}{
+		{
+			name:         "subscribe to a state",
+			data:         "subscribe:NV",
+			wantSent:     []string{"SendMessageWithKeyboard"},
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, calls []sentCall) {
+				if !slices.Contains(user.States, "NV") {
+					t.Errorf("States = %v, want NV", user.States)
+				}
+				preview := calls[0]
+				if !strings.Contains(preview.Text, "<b>2 event(s)</b>") || !slices.Contains(callbackData(preview.Keyboard), "preview:NV:0") {
+					t.Errorf("preview prompt = %q with %v", preview.Text, callbackData(preview.Keyboard))
+				}
+			},
+		},
+		{
+			name:         "subscribe without a state shows the picker",
+			data:         "subscribe",
+			wantText:     "Select a state or region",
+			wantKeyboard: "states:1",
+		},
+		{
+			name:         "state picker page",
+			data:         "states:1",
+			wantText:     "(page 2/",
+			wantKeyboard: "states:0",
+		},
+		{
+			name:         "unsubscribe from a state",
+			data:         "unsubscribe:NV",
+			setup:        func(u *preferences.UserPreferences) { u.States = []string{"NV", "CA"} },
+			wantText:     "Unsubscribed from",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if !slices.Equal(user.States, []string{"CA"}) {
+					t.Errorf("States = %v, want [CA]", user.States)
+				}
+			},
+		},
+		{
+			name:         "unsubscribe from all states",
+			data:         "unsubscribe-all:confirm",
+			setup:        func(u *preferences.UserPreferences) { u.States = []string{"NV", "CA"} },
+			wantText:     "Unsubscribed from all 2 state(s)",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if len(user.States) != 0 {
+					t.Errorf("States = %v, want none", user.States)
+				}
+			},
+		},
+		{
+			name:     "unsubscribe from all without subscriptions",
+			data:     "unsubscribe-all:confirm",
+			wantText: "no active subscriptions",
+		},
+		{
+			name:     "unsubscribe from all without confirming",
+			data:     "unsubscribe-all:yes",
+			setup:    func(u *preferences.UserPreferences) { u.States = []string{"NV"} },
+			wantText: "Invalid action",
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if len(user.States) != 1 {
+					t.Errorf("States = %v, want NV kept", user.States)
+				}
+			},
+		},
+		{
+			name:         "digest frequency",
+			data:         "digest:daily",
+			wantText:     "Digest frequency updated to <b>daily</b>",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if user.DigestFrequency != preferences.DigestFrequencyDaily {
+					t.Errorf("DigestFrequency = %q", user.DigestFrequency)
+				}
+			},
+		},
+		{
+			name:     "invalid digest frequency",
+			data:     "digest:hourly",
+			wantText: "Invalid digest frequency",
+		},
+		{
+			name:         "settings menu",
+			data:         "settings",
+			wantText:     "Choose what to change",
+			wantKeyboard: "settings:events",
+		},
+		{
+			name:         "settings page",
+			data:         "settings:notify",
+			wantText:     "Notifications",
+			wantKeyboard: "settings:toggle:removals",
+		},
+		{
+			name:         "toggle a setting",
+			data:         "settings:toggle:past",
+			wantText:     "<b>Past events:</b> shown",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if user.HidePastEvents {
+					t.Error("HidePastEvents should be toggled off")
+				}
+			},
+		},
+		{
+			name:         "days ahead",
+			data:         "settings:days:30",
+			wantText:     "the next 30 days",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if user.DaysAhead != 30 {
+					t.Errorf("DaysAhead = %d, want 30", user.DaysAhead)
+				}
+			},
+		},
+		{
+			name:         "digest hour",
+			data:         "settings:hour:0",
+			wantText:     "at 00:00 UTC",
+			wantKeyboard: "settings:day:0",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if user.DigestHour != 0 || user.DigestTimezone != "UTC" {
+					t.Errorf("DigestHour = %d in %q, want midnight UTC", user.DigestHour, user.DigestTimezone)
+				}
+			},
+		},
+		{
+			name:     "invalid digest day",
+			data:     "settings:day:9",
+			wantText: "Invalid setting",
+		},
+		{
+			name:         "preview the soonest event",
+			data:         "preview:NV:1",
+			wantSent:     []string{"SendMessageWithKeyboard"},
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, calls []sentCall) {
+				if !strings.Contains(calls[0].Text, "Wolf Creek") {
+					t.Errorf("previewed %q, want the soonest event", calls[0].Text)
+				}
+				if !user.HasSeenEvent("evt1") || !user.HasSeenEvent("evt2") || user.HasSeenEvent("evt3") {
+					t.Errorf("SeenEventIDs = %v, want every NV event", user.SeenEventIDs)
+				}
+			},
+		},
+		{
+			name:         "decline the preview",
+			data:         "preview:NV:0",
+			wantText:     "only be notified about new events",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if !user.HasSeenEvent("evt1") || !user.HasSeenEvent("evt2") {
+					t.Errorf("SeenEventIDs = %v, want every NV event", user.SeenEventIDs)
+				}
+			},
+		},
+		{
+			name:     "invalid preview",
+			data:     "preview:NV",
+			wantText: "Invalid preview request",
+		},
+		{
+			name:         "event status",
+			data:         "status:evt1:interested",
+			wantText:     "Event marked as",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if got := user.GetEventStatus("evt1"); got != preferences.EventStatusInterested {
+					t.Errorf("status = %q, want interested", got)
+				}
+			},
+		},
+		{
+			name:     "invalid event status",
+			data:     "status:evt1:bogus",
+			wantText: "Invalid status",
+		},
+		{
+			name:         "add a reminder",
+			data:         "reminder:add:7",
+			wantKeyboard: "reminder:remove:7",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if !slices.Equal(user.ReminderDays, []int{7}) {
+					t.Errorf("ReminderDays = %v, want [7]", user.ReminderDays)
+				}
+			},
+		},
+		{
+			name:         "remove a reminder",
+			data:         "reminder:remove:7",
+			setup:        func(u *preferences.UserPreferences) { u.ReminderDays = []int{1, 7} },
+			wantKeyboard: "reminder:add:7",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if !slices.Equal(user.ReminderDays, []int{1}) {
+					t.Errorf("ReminderDays = %v, want [1]", user.ReminderDays)
+				}
+			},
+		},
+		{
+			name:     "save reminders",
+			data:     "reminder:done:0",
+			setup:    func(u *preferences.UserPreferences) { u.ReminderDays = []int{1, 7} },
+			wantText: "<b>1 day, 1 week</b> before",
+		},
+		{
+			name:     "calendar file",
+			data:     "calendar:evt1",
+			wantText: "Calendar file sent",
+			wantSent: []string{"UploadDocument"},
+			check: func(t *testing.T, _ *preferences.UserPreferences, calls []sentCall) {
+				if calls[0].Filename != "vga-event-NV.ics" || !strings.Contains(calls[0].Text, "Wolf Creek") {
+					t.Errorf("uploaded %q with caption %q", calls[0].Filename, calls[0].Text)
+				}
+			},
+		},
+		{
+			name:     "calendar file for a removed event",
+			data:     "calendar:gone",
+			wantText: "Event not found",
+		},
+		{
+			name: "clear skipped events",
+			data: "bulk:clear-skipped",
+			setup: func(u *preferences.UserPreferences) {
+				u.SetEventStatus("evt1", preferences.EventStatusSkip)
+				u.SetEventStatus("evt2", preferences.EventStatusSkip)
+				u.SetEventStatus("evt3", preferences.EventStatusRegistered)
+			},
+			wantText:     "Cleared <b>2</b> skipped event(s)",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if len(user.EventStatuses) != 1 || user.GetEventStatus("evt3") != preferences.EventStatusRegistered {
+					t.Errorf("EventStatuses = %v, want only evt3", user.EventStatuses)
+				}
+			},
+		},
+		{
+			name:     "export registered events",
+			data:     "bulk:export-registered",
+			setup:    func(u *preferences.UserPreferences) { u.SetEventStatus("evt3", preferences.EventStatusRegistered) },
+			wantText: "Calendar file sent with <b>1</b> registered event(s)",
+			wantSent: []string{"SendDocument"},
+			check: func(t *testing.T, _ *preferences.UserPreferences, calls []sentCall) {
+				if calls[0].Filename != "vga-registered-events.ics" {
+					t.Errorf("sent %q", calls[0].Filename)
+				}
+			},
+		},
+		{
+			name:     "export without registered events",
+			data:     "bulk:export-registered",
+			wantText: "No registered events to export",
+		},
+		{
+			name:     "export interested events",
+			data:     "bulk:export-interested",
+			setup:    func(u *preferences.UserPreferences) { u.SetEventStatus("evt2", preferences.EventStatusInterested) },
+			wantText: "Calendar file sent with <b>1</b> interested event(s)",
+			wantSent: []string{"SendDocument"},
+			check: func(t *testing.T, _ *preferences.UserPreferences, calls []sentCall) {
+				if calls[0].Filename != "vga-interested-events.ics" || !strings.Contains(calls[0].Text, "Your Interested Events") {
+					t.Errorf("sent %q with caption %q", calls[0].Filename, calls[0].Text)
+				}
+			},
+		},
+		{
+			name: "clear maybe events",
+			data: "bulk:clear-maybe",
+			setup: func(u *preferences.UserPreferences) {
+				u.SetEventStatus("evt1", preferences.EventStatusMaybe)
+				u.SetEventStatus("evt2", preferences.EventStatusInterested)
+			},
+			wantText:     "Cleared <b>1</b> Maybe event(s)",
+			wantModified: true,
+			check: func(t *testing.T, user *preferences.UserPreferences, _ []sentCall) {
+				if len(user.EventStatuses) != 1 || user.GetEventStatus("evt2") != preferences.EventStatusInterested {
+					t.Errorf("EventStatuses = %v, want only evt2", user.EventStatuses)
+				}
+			},
+		},
+		{
+			name:     "clear maybe without maybe events",
+			data:     "bulk:clear-maybe",
+			wantText: "No Maybe events to clear",
+		},
+		{
+			name:     "unknown bulk action",
+			data:     "bulk:delete-everything",
+			wantText: "Unknown bulk action",
+		},
+		{
+			name:         "main menu",
+			data:         "menu:main",
+			wantKeyboard: "menu:all-events",
+		},
+		{
+			name:         "reminders menu",
+			data:         "menu:reminders",
+			wantKeyboard: "reminder:done:0",
+		},
+		{
+			name:     "search menu",
+			data:     "menu:search",
+			wantText: "Search Events",
+		},
+		{
+			name:     "unknown menu action",
+			data:     "menu:bogus",
+			wantText: "Unknown menu action",
+		},
+		{
+			name:     "unknown action",
+			data:     "bogus:1",
+			wantText: "Unknown action",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := recordSends(t)
+			serveEvents(t, callbackEvents())
+
+			prefs := preferences.NewPreferences()
+			user := prefs.GetUser("111")
+			if tt.setup != nil {
+				tt.setup(user)
+			}
+			modified := false
+
+			callback := &telegram.CallbackQuery{
+				ID:      "cb1",
+				From:    telegram.User{ID: 111, FirstName: "Pat"},
+				Message: &telegram.Message{MessageID: 42, Chat: telegram.Chat{ID: 111}},
+				Data:    tt.data,
+			}
+			handleCallbackQuery(prefs, callback, &modified, "token", false)
+
+			// Handlers send first; every tap ends with an answer and an edit of the
+			// tapped message
+			got := *calls
+			if len(got) < 2 || got[len(got)-2].Method != "AnswerCallbackQuery" || got[len(got)-1].Method != "EditMessageText" {
+				t.Fatalf("calls = %+v, want an answer, then an edit", got)
+			}
+			edit := got[len(got)-1]
+			if edit.ChatID != "111" || edit.MessageID != 42 {
+				t.Errorf("edited message %d in chat %s, want 42 in 111", edit.MessageID, edit.ChatID)
+			}
+			if !strings.Contains(edit.Text, tt.wantText) {
+				t.Errorf("edited text = %q, want it to contain %q", edit.Text, tt.wantText)
+			}
+			if tt.wantKeyboard != "" && !slices.Contains(callbackData(edit.Keyboard), tt.wantKeyboard) {
+				t.Errorf("keyboard = %v, want a %q button", callbackData(edit.Keyboard), tt.wantKeyboard)
+			}
+
+			var sent []string
+			for _, call := range got[:len(got)-2] {
+				sent = append(sent, call.Method)
+			}
+			if !slices.Equal(sent, tt.wantSent) {
+				t.Errorf("sent %v, want %v", sent, tt.wantSent)
+			}
+			if modified != tt.wantModified {
+				t.Errorf("modified = %v, want %v", modified, tt.wantModified)
+			}
+			if tt.check != nil {
+				tt.check(t, user, got)
+			}
+		})
+	}
+}
+
+func TestHandleCallbackQueryCachedCalendarFile(t *testing.T) {
+	calls := recordSends(t)
+	serveEvents(t, callbackEvents())
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	callback := &telegram.CallbackQuery{ID: "cb1", From: telegram.User{ID: 111}, Data: "calendar:evt1"}
+	handleCallbackQuery(prefs, callback, &modified, "token", false)
+	handleCallbackQuery(prefs, callback, &modified, "token", false)
+
+	// Without a message to edit, the reply is sent as a new message; the second tap
+	// re-sends the uploaded file by its ID
+	var methods []string
+	for _, call := range *calls {
+		methods = append(methods, call.Method)
+	}
+	want := []string{
+		"UploadDocument", "AnswerCallbackQuery", "SendMessage",
+		"SendDocumentID", "AnswerCallbackQuery", "SendMessage",
+	}
+	if !slices.Equal(methods, want) {
+		t.Fatalf("calls = %v, want %v", methods, want)
+	}
+	if id := (*calls)[3].Filename; id != "file-vga-event-NV.ics" {
+		t.Errorf("re-sent file %q, want the uploaded file's ID", id)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/errs"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// cardHash identifies a card's text, so the refresh pass only edits cards that changed
+func cardHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// renderEventCard formats an event card with the user's current status and note
+func renderEventCard(prefs preferences.Preferences, chatID string, evt *event.Event) (string, *telegram.InlineKeyboardMarkup) {
+	user := prefs.GetUser(chatID)
+	return telegram.FormatEventWithStatusAndNote(evt, user.GetEventStatus(evt.ID), user.GetEventNote(evt.ID), chatID, prefs)
+}
+
+// renderCancelledCard formats the card of a removed event, without buttons
+func renderCancelledCard(prefs preferences.Preferences, chatID string, evt *event.Event) string {
+	user := prefs.GetUser(chatID)
+	return telegram.FormatCancelledCard(evt, user.GetEventStatus(evt.ID), user.GetEventNote(evt.ID), chatID, prefs)
+}
+
+// sendEventCard sends an event card and tracks its message so --refresh-cards can
+// update it later
+func sendEventCard(client Sender, prefs preferences.Preferences, chatID string, evt *event.Event, modified *bool) error {
+	msg, keyboard := renderEventCard(prefs, chatID, evt)
+	messageID, err := client.SendTrackedMessage(botCtx, msg, keyboard)
+	if err != nil {
+		return err
+	}
+	if messageID > 0 {
+		prefs.GetUser(chatID).TrackEventCard(evt.ID, messageID, cardHash(msg), clk.Now())
+		*modified = true
+	}
+	return nil
+}
+
+// removedEvent returns a recently removed event from the --data-dir snapshot, or nil
+func removedEvent(eventID string) *event.Event {
+	if snapshotStore == nil {
+		return nil
+	}
+	snapshot, err := snapshotStore.LoadSnapshot("all")
+	if err != nil || snapshot == nil {
+		return nil
+	}
+	return snapshot.RemovedEvents[eventID]
+}
+
+// handleRefreshCallback re-renders an event card from the VGA site's current listing.
+// cardID is the card's message in the user's own chat, tracked from then on, or 0.
+// Format: refresh:EVENT_ID
+func handleRefreshCallback(prefs preferences.Preferences, chatID, eventID string, cardID int, modified *bool) (string, *telegram.InlineKeyboardMarkup) {
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	var evt *event.Event
+	for _, e := range allEvents {
+		if e.ID == eventID {
+			evt = e
+			break
+		}
+	}
+	user := prefs.GetUser(chatID)
+	if evt == nil {
+		if cardID > 0 && user.ForgetEventCard(cardID) {
+			*modified = true
+		}
+		if removed := removedEvent(eventID); removed != nil {
+			return renderCancelledCard(prefs, chatID, removed), nil
+		}
+		return "⚠️ This event is no longer listed on the VGA website.", nil
+	}
+
+	msg, keyboard := renderEventCard(prefs, chatID, evt)
+	if cardID > 0 {
+		user.TrackEventCard(evt.ID, cardID, cardHash(msg), clk.Now())
+		*modified = true
+	}
+	return msg, keyboard
+}
+
+// refreshEventCards edits each user's recent event cards whose event details, status,
+// or note changed since they were last shown, using the --data-dir snapshot, and
+// saves the updated tracking
+func refreshEventCards(ctx context.Context, prefs preferences.Preferences, gist *preferences.GistStorage, botToken string, dryRun bool) {
+	if snapshotStore == nil {
+		fmt.Fprintf(os.Stderr, "Error: --refresh-cards needs --data-dir\n")
+		os.Exit(1)
+	}
+	snapshot, err := snapshotStore.LoadSnapshot("all")
+	if err != nil || snapshot == nil {
+		fmt.Fprintf(os.Stderr, "Error loading snapshot: %v\n", err)
+		os.Exit(1)
+	}
+
+	edited, modified := refreshCards(ctx, prefs, snapshot, botToken, dryRun)
+	if modified && !dryRun {
+		if err := savePreferences(ctx, gist, prefs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+			errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("✅ Refreshed %d event card(s)\n", edited)
+}
+
+// refreshCards does the work of refreshEventCards against a snapshot. Cards of removed
+// events are marked cancelled, and then, like cards of events gone from the snapshot or
+// whose message is gone, stop being tracked; cards of events missing but not yet
+// removed are left alone. Reports how many cards were edited and
+// whether any tracking changed.
+func refreshCards(ctx context.Context, prefs preferences.Preferences, snapshot *event.Snapshot, botToken string, dryRun bool) (int, bool) {
+	now := clk.Now()
+	events := snapshot.Listed()
+	edited := 0
+	modified := false
+	for chatID, user := range prefs {
+		if user.PruneEventCards(now) {
+			modified = true
+		}
+		if !user.Active && len(user.EventCards) > 0 {
+			// Deactivated users may have blocked the bot, so their cards can't be edited
+			user.EventCards = nil
+			modified = true
+		}
+		if len(user.EventCards) == 0 {
+			continue
+		}
+
+		var client Sender
+		for _, card := range append([]*preferences.EventCard(nil), user.EventCards...) {
+			var msg string
+			var keyboard *telegram.InlineKeyboardMarkup
+			evt, listed := events[card.EventID]
+			switch {
+			case listed:
+				msg, keyboard = renderEventCard(prefs, chatID, evt)
+			case snapshot.Events[card.EventID] != nil:
+				// Missing from the last scrape but not yet removed: leave the card as is
+				continue
+			case snapshot.RemovedEvents[card.EventID] != nil:
+				msg = renderCancelledCard(prefs, chatID, snapshot.RemovedEvents[card.EventID])
+			default:
+				user.ForgetEventCard(card.MessageID)
+				modified = true
+				continue
+			}
+			hash := cardHash(msg)
+			if hash == card.Hash {
+				continue
+			}
+			if dryRun {
+				fmt.Printf("[DRY RUN] Would refresh card %d (%s) for %s\n", card.MessageID, card.EventID, chatID)
+				continue
+			}
+
+			if client == nil {
+				var err error
+				if client, err = newSender(botToken, chatID); err != nil {
+					fmt.Fprintf(os.Stderr, "Error creating client for %s: %v\n", chatID, err)
+					break
+				}
+			}
+			err := client.EditMessageText(ctx, chatID, card.MessageID, msg, keyboard)
+			switch {
+			case errors.Is(err, errs.ErrNotFound):
+				// Deleted by the user, or the chat is gone
+				user.ForgetEventCard(card.MessageID)
+				modified = true
+				continue
+			case err != nil && !strings.Contains(err.Error(), "message is not modified"):
+				fmt.Fprintf(os.Stderr, "Error refreshing card %d for %s: %v\n", card.MessageID, chatID, err)
+				continue
+			}
+			if listed {
+				card.Hash = hash
+			} else {
+				// Cancelled for good
+				user.ForgetEventCard(card.MessageID)
+			}
+			modified = true
+			edited++
+			if telegram.Pause(ctx, 100*time.Millisecond) != nil {
+				return edited, modified // Shutting down
+			}
+		}
+	}
+	return edited, modified
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+	"github.com/pfrederiksen/vga-events/internal/testutil"
+)
+
+// goneSender fails edits of one message as Telegram does once it's deleted
+type goneSender struct {
+	*recordingSender
+	gone int
+}
+
+func (s *goneSender) EditMessageText(ctx context.Context, chatID string, messageID int, text string, keyboard *telegram.InlineKeyboardMarkup) error {
+	if messageID == s.gone {
+		return fmt.Errorf("message to edit not found: %w", errs.ErrNotFound)
+	}
+	return s.recordingSender.EditMessageText(ctx, chatID, messageID, text, keyboard)
+}
+
+func useFakeClock(t *testing.T) *testutil.FakeClock {
+	t.Helper()
+	fake := testutil.NewFakeClock(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	original := clk
+	clk = fake
+	preferences.SetClock(fake)
+	event.SetClock(fake)
+	t.Cleanup(func() {
+		clk = original
+		preferences.SetClock(original)
+		event.SetClock(original)
+	})
+	return fake
+}
+
+func TestSendEventCardTracksMessage(t *testing.T) {
+	calls := recordSends(t)
+	useFakeClock(t)
+	prefs := preferences.NewPreferences()
+	evt := callbackEvents()[0]
+
+	client, _ := newSender("token", "123")
+	modified := false
+	if err := sendEventCard(client, prefs, "123", evt, &modified); err != nil {
+		t.Fatal(err)
+	}
+
+	cards := prefs.GetUser("123").EventCards
+	if len(cards) != 1 || cards[0].EventID != "evt1" || cards[0].MessageID != (*calls)[0].MessageID {
+		t.Fatalf("EventCards = %+v, want the sent message tracked", cards)
+	}
+	if !modified {
+		t.Error("tracking a card should mark preferences modified")
+	}
+	if !strings.Contains(strings.Join(callbackData((*calls)[0].Keyboard), " "), "refresh:evt1") {
+		t.Error("card should have a Refresh button")
+	}
+}
+
+func TestHandleRefreshCallback(t *testing.T) {
+	useFakeClock(t)
+	events := callbackEvents()
+	serveEvents(t, events)
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	user.TrackEventCard("evt1", 7, "old", clk.Now())
+	user.TrackEventCard("gone", 8, "old", clk.Now())
+
+	modified := false
+	text, keyboard := handleRefreshCallback(prefs, "123", "evt1", 7, &modified)
+	if !strings.Contains(text, "Wolf Creek") || keyboard == nil {
+		t.Errorf("refresh = %q, want the re-rendered card", text)
+	}
+	if user.EventCards[0].Hash != cardHash(text) || !modified {
+		t.Errorf("refreshed card hash = %q, want the new text's", user.EventCards[0].Hash)
+	}
+
+	text, keyboard = handleRefreshCallback(prefs, "123", "gone", 8, &modified)
+	if !strings.Contains(text, "no longer listed") || keyboard != nil {
+		t.Errorf("refresh of a removed event = %q", text)
+	}
+	if len(user.EventCards) != 1 {
+		t.Errorf("a removed event's card should stop being tracked, have %d", len(user.EventCards))
+	}
+
+	// With the snapshot's recently removed events, the card is marked cancelled
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := event.NewSnapshot()
+	snapshot.RemovedEvents["gone"] = &event.Event{ID: "gone", State: "NV", Title: "Old Course"}
+	if err := store.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
+		t.Fatal(err)
+	}
+	original := snapshotStore
+	snapshotStore = store
+	t.Cleanup(func() { snapshotStore = original })
+
+	text, keyboard = handleRefreshCallback(prefs, "123", "gone", 0, &modified)
+	if !strings.Contains(text, "CANCELLED") || !strings.Contains(text, "<s>Old Course</s>") || keyboard != nil {
+		t.Errorf("refresh of a recently removed event = %q, want the cancelled card", text)
+	}
+}
+
+func TestRefreshCards(t *testing.T) {
+	calls := recordSends(t)
+	fake := useFakeClock(t)
+
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	snapshot := event.NewSnapshot()
+	for _, evt := range callbackEvents() {
+		snapshot.Events[evt.ID] = evt
+	}
+	user.TrackEventCard("evt1", 1, "stale", fake.Now())
+	fake.Advance(preferences.EventCardMaxAge + time.Hour)
+	user.TrackEventCard("evt1", 5, "stale", fake.Now()) // Only this one is recent
+
+	edited, modified := refreshCards(context.Background(), prefs, snapshot, "token", false)
+	if edited != 1 || !modified {
+		t.Fatalf("refreshCards() = %d, %v, want 1 edit", edited, modified)
+	}
+	if len(*calls) != 1 || (*calls)[0].Method != "EditMessageText" || (*calls)[0].MessageID != 5 {
+		t.Fatalf("calls = %+v, want one edit of message 5", *calls)
+	}
+	if len(user.EventCards) != 1 || user.EventCards[0].Hash == "stale" {
+		t.Errorf("EventCards = %+v, want message 5 with its new hash", user.EventCards)
+	}
+
+	// Nothing changed since, so a second pass edits nothing
+	if edited, _ := refreshCards(context.Background(), prefs, snapshot, "token", false); edited != 0 {
+		t.Errorf("second refreshCards() edited %d cards", edited)
+	}
+}
+
+func TestRefreshCardsSkipsUnchangedAndForgetsGone(t *testing.T) {
+	calls := recordSends(t)
+	fake := useFakeClock(t)
+	newSender = func(_, chatID string) (Sender, error) {
+		return &goneSender{recordingSender: &recordingSender{chatID: chatID, calls: calls}, gone: 4}, nil
+	}
+
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	snapshot := event.NewSnapshot()
+	for _, evt := range callbackEvents() {
+		snapshot.Events[evt.ID] = evt
+	}
+	current, _ := renderEventCard(prefs, "123", snapshot.Events["evt2"])
+	user.TrackEventCard("evt2", 2, cardHash(current), fake.Now())
+	user.TrackEventCard("aged-out", 3, "stale", fake.Now())
+	user.TrackEventCard("evt3", 4, "stale", fake.Now())
+
+	edited, modified := refreshCards(context.Background(), prefs, snapshot, "token", false)
+	if edited != 0 || !modified || len(*calls) != 0 {
+		t.Fatalf("refreshCards() = %d, %v with calls %+v, want no edits", edited, modified, *calls)
+	}
+	if len(user.EventCards) != 1 || user.EventCards[0].MessageID != 2 {
+		t.Errorf("EventCards = %+v, want only the unchanged card", user.EventCards)
+	}
+}
+
+func TestRefreshCardsCancelsRemoved(t *testing.T) {
+	calls := recordSends(t)
+	fake := useFakeClock(t)
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	snapshot := event.NewSnapshot()
+	removed := callbackEvents()[0]
+	snapshot.RemovedEvents[removed.ID] = removed
+	user.TrackEventCard(removed.ID, 9, "stale", fake.Now())
+
+	edited, _ := refreshCards(context.Background(), prefs, snapshot, "token", false)
+	if edited != 1 || len(*calls) != 1 {
+		t.Fatalf("refreshCards() edited %d cards, want the removed event's", edited)
+	}
+	if call := (*calls)[0]; !strings.Contains(call.Text, "CANCELLED") || !strings.Contains(call.Text, "<s>Wolf Creek</s>") || call.Keyboard != nil || call.MessageID != 9 {
+		t.Errorf("edit = %+v, want message 9 marked cancelled without buttons", call)
+	}
+	if len(user.EventCards) != 0 {
+		t.Errorf("a cancelled card should stop being tracked, have %+v", user.EventCards)
+	}
+}
+
+func TestRefreshCardsLeavesMissingEvents(t *testing.T) {
+	calls := recordSends(t)
+	fake := useFakeClock(t)
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	snapshot := event.NewSnapshot()
+	missing := *callbackEvents()[0]
+	missing.MissingCount = 1
+	snapshot.Events[missing.ID] = &missing
+	user.TrackEventCard(missing.ID, 9, "stale", fake.Now())
+
+	edited, modified := refreshCards(context.Background(), prefs, snapshot, "token", false)
+	if edited != 0 || modified || len(*calls) != 0 {
+		t.Fatalf("refreshCards() = %d, %v with calls %+v, want the card left alone", edited, modified, *calls)
+	}
+	if len(user.EventCards) != 1 {
+		t.Errorf("a missing event's card should stay tracked, have %+v", user.EventCards)
+	}
+}
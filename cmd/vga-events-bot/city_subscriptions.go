@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/geo"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// parseCitySubscription parses /subscribe-city arguments: a city name, optionally
+// followed by a state code ("Las Vegas NV" or "Las Vegas, NV") and a radius ("25mi").
+// Returns an error message for the user when the arguments are invalid.
+func parseCitySubscription(args []string) (preferences.CitySubscription, string) {
+	var sub preferences.CitySubscription
+	text := strings.TrimSpace(strings.Join(args, " "))
+
+	// Radius: "25mi", "25 mi", "25 miles", or a bare number at the end
+	fields := strings.Fields(text)
+	if n := len(fields); n > 1 && (strings.EqualFold(fields[n-1], "mi") || strings.EqualFold(fields[n-1], "miles")) {
+		fields[n-2] += "mi"
+		fields = fields[:n-1]
+	}
+	if n := len(fields); n > 1 {
+		last := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(fields[n-1]), "miles"), "mi")
+		if radius, err := strconv.Atoi(last); err == nil {
+			if radius < 1 || radius > preferences.MaxCityRadiusMiles {
+				return sub, fmt.Sprintf("❌ Radius must be between 1 and %d miles.", preferences.MaxCityRadiusMiles)
+			}
+			sub.RadiusMiles = radius
+			fields = fields[:n-1]
+		}
+	}
+	text = strings.Trim(strings.Join(fields, " "), `"' `)
+
+	// State: "City, ST" or a trailing state code after a multi-word name
+	if i := strings.LastIndex(text, ","); i >= 0 {
+		state := strings.ToUpper(strings.TrimSpace(text[i+1:]))
+		if state == AllStatesCode || !preferences.IsValidState(state) {
+			return sub, fmt.Sprintf("❌ Invalid state code: %s", html.EscapeString(state))
+		}
+		sub.State = state
+		text = strings.TrimSpace(text[:i])
+	} else if fields := strings.Fields(text); len(fields) > 1 {
+		last := strings.ToUpper(fields[len(fields)-1])
+		if len(last) == 2 && last != AllStatesCode && preferences.IsValidState(last) {
+			sub.State = last
+			text = strings.Join(fields[:len(fields)-1], " ")
+		}
+	}
+	text = strings.Trim(text, `"' `)
+
+	city, errMsg := validateUserInput(text, 100, "City name")
+	if errMsg != "" {
+		return sub, errMsg
+	}
+	sub.City = city
+	return sub, ""
+}
+
+// handleSubscribeCity adds a city subscription
+func handleSubscribeCity(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify a city.\n\nUsage: /subscribe-city &lt;city&gt; [STATE] [radius]\n\nExamples:\n/subscribe-city Las Vegas\n/subscribe-city \"Las Vegas\" NV 25mi"
+	}
+
+	sub, errMsg := parseCitySubscription(args)
+	if errMsg != "" {
+		return errMsg
+	}
+
+	var warning string
+	if sub.RadiusMiles > 0 {
+		if _, ok := geo.Lookup(sub.City, sub.State); !ok {
+			warning = fmt.Sprintf("\n\n⚠️ I couldn't locate %s, so only events in that city will match.", html.EscapeString(sub.City))
+			if sub.State == "" {
+				warning += " Adding a state code may help."
+			}
+			sub.RadiusMiles = 0
+		}
+	}
+
+	if !prefs.AddCity(chatID, sub) {
+		return fmt.Sprintf("❌ You can follow up to %d cities.\n\nUse /unsubscribe-city &lt;city&gt; to remove one.", preferences.MaxCitySubscriptions)
+	}
+	*modified = true
+
+	response := fmt.Sprintf("✅ <b>Subscribed to %s!</b>\n\n", html.EscapeString(sub.String()))
+	response += "You'll be notified about new events there, even in states you're not subscribed to."
+	response += warning
+	response += "\n\nUse /list to see all your subscriptions."
+	return response
+}
+
+// handleUnsubscribeCity removes a city subscription
+func handleUnsubscribeCity(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify a city.\n\nUsage: /unsubscribe-city &lt;city&gt; [STATE]"
+	}
+
+	sub, errMsg := parseCitySubscription(args)
+	if errMsg != "" {
+		return errMsg
+	}
+
+	if prefs.RemoveCity(chatID, sub.City, sub.State) == 0 {
+		return fmt.Sprintf("ℹ️ You're not subscribed to %s.\n\nUse /list to see your subscriptions.", html.EscapeString(sub.City))
+	}
+	*modified = true
+	return fmt.Sprintf("✅ Unsubscribed from %s.", html.EscapeString(sub.City))
+}
+
+// handleHome shows, sets, or clears the home city used for "miles from home" hints on
+// new-event cards
+func handleHome(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if len(args) == 0 {
+		if user.Home == nil {
+			return "🏠 You haven't set a home city.\n\nUsage: /home &lt;city&gt; &lt;STATE&gt;\nExample: /home Las Vegas NV\n\nNew-event cards will then show how far away each event is."
+		}
+		return fmt.Sprintf("🏠 Your home city is <b>%s</b>.\n\nChange it with /home &lt;city&gt; &lt;STATE&gt; or remove it with /home clear.", html.EscapeString(user.Home.String()))
+	}
+
+	if len(args) == 1 && strings.EqualFold(args[0], "clear") {
+		if user.Home == nil {
+			return "ℹ️ You haven't set a home city."
+		}
+		user.Home = nil
+		*modified = true
+		return "✅ Home city removed. New-event cards won't show distances."
+	}
+
+	home, errMsg := parseCitySubscription(args)
+	if errMsg != "" {
+		return errMsg
+	}
+	if home.State == "" {
+		return "❌ Please include the state, e.g. /home Las Vegas NV"
+	}
+	if _, ok := geo.Lookup(home.City, home.State); !ok {
+		return fmt.Sprintf("❌ I couldn't locate %s. Try the nearest major golf city.", html.EscapeString(home.String()))
+	}
+	home.RadiusMiles = 0
+
+	user.Home = &home
+	*modified = true
+	return fmt.Sprintf("✅ Home city set to <b>%s</b>.\n\nNew-event cards will show how many miles away each event is.", html.EscapeString(home.String()))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestParseCitySubscription(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    preferences.CitySubscription
+		wantErr bool
+	}{
+		{"Las Vegas", preferences.CitySubscription{City: "Las Vegas"}, false},
+		{`"Las Vegas" NV`, preferences.CitySubscription{City: "Las Vegas", State: "NV"}, false},
+		{"Las Vegas, nv 25mi", preferences.CitySubscription{City: "Las Vegas", State: "NV", RadiusMiles: 25}, false},
+		{"Las Vegas NV 25 miles", preferences.CitySubscription{City: "Las Vegas", State: "NV", RadiusMiles: 25}, false},
+		{"Scottsdale 40", preferences.CitySubscription{City: "Scottsdale", RadiusMiles: 40}, false},
+		{"Boulder City", preferences.CitySubscription{City: "Boulder City"}, false},
+		{"Las Vegas 500mi", preferences.CitySubscription{}, true},
+		{"Las Vegas, XX", preferences.CitySubscription{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			got, errMsg := parseCitySubscription(strings.Fields(tt.args))
+			if (errMsg != "") != tt.wantErr {
+				t.Fatalf("error = %q, wantErr %v", errMsg, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleSubscribeCity(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	response := handleSubscribeCity(prefs, "123", strings.Fields("Las Vegas NV 25mi"), &modified)
+	if !modified || !strings.Contains(response, "Las Vegas, NV (within 25 mi)") {
+		t.Errorf("unexpected response: %q", response)
+	}
+
+	// A radius around a city that can't be located falls back to the name
+	response = handleSubscribeCity(prefs, "123", strings.Fields("Smallville 10mi"), &modified)
+	if !strings.Contains(response, "couldn't locate") {
+		t.Errorf("expected a warning, got %q", response)
+	}
+	if cities := prefs.GetUser("123").Cities; len(cities) != 2 || cities[1].RadiusMiles != 0 {
+		t.Errorf("cities = %+v", cities)
+	}
+
+	list := handleList(prefs, "123")
+	if !strings.Contains(list, "Las Vegas, NV (within 25 mi)") || !strings.Contains(list, "Smallville") {
+		t.Errorf("/list should show cities, got %q", list)
+	}
+
+	modified = false
+	handleUnsubscribeCity(prefs, "123", []string{"smallville"}, &modified)
+	if !modified || len(prefs.GetUser("123").Cities) != 1 {
+		t.Error("unsubscribe-city should remove the city")
+	}
+}
+
+func TestHandleHome(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if response := handleHome(prefs, "111", nil, &modified); !strings.Contains(response, "haven't set") {
+		t.Errorf("no home: %q", response)
+	}
+	if response := handleHome(prefs, "111", []string{"Nowhereville", "NV"}, &modified); modified || !strings.Contains(response, "couldn't locate") {
+		t.Errorf("unknown city: %q", response)
+	}
+
+	handleHome(prefs, "111", []string{"Las", "Vegas", "NV"}, &modified)
+	if home := prefs.GetUser("111").Home; !modified || home == nil || home.City != "Las Vegas" || home.State != "NV" {
+		t.Fatalf("home = %+v", home)
+	}
+
+	handleHome(prefs, "111", []string{"clear"}, &modified)
+	if prefs.GetUser("111").Home != nil {
+		t.Error("/home clear should remove the home city")
+	}
+}
@@ -0,0 +1,1536 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+)
+
+// commandRegistry is the single list of commands, in /help order.
+// It is assigned in init because handlers reference the registry (e.g. /help).
+var commandRegistry []botCommand
+
+func init() {
+	commandRegistry = []botCommand{
+		{
+			Name: "menu", Summary: "Quick actions menu", Emoji: "🎯",
+			Localized:   map[string]string{"es": "Menú de acciones rápidas"},
+			Icon:        "🎯",
+			Title:       "Quick Actions Menu",
+			Description: "Interactive menu with quick access to the most common actions. Great starting point if you're not sure what to do.",
+			Usage:       []usageLine{{"", "Show quick actions menu"}},
+			Sections: []helpSection{
+				{"Available Actions", []string{
+					"• View My Events",
+					"• View All Events",
+					"• Search Events",
+					"• Manage Subscriptions",
+					"• Configure Settings",
+					"• View Statistics",
+					"• Get Help",
+				}},
+				{"Tips", []string{
+					"• Fastest way to navigate the bot",
+					"• No need to remember commands",
+					"• All major features accessible",
+					"• Use anytime you need quick access",
+				}},
+			},
+			Related: []string{"help"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleMenuWithKeyboard(ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "search", Summary: "Search for events by keyword", Emoji: "🔍",
+			Cost:        costFetch,
+			Localized:   map[string]string{"es": "Buscar eventos por palabra clave"},
+			Icon:        "🔍",
+			Title:       "Search for Events",
+			Description: "Search across all your subscribed states for events matching a keyword. Searches event titles, cities, and state names.",
+			Usage:       []usageLine{{"<keyword>", "Search for events"}},
+			Examples: []usageLine{
+				{`"Pine Valley"`, "Find Pine Valley events"},
+				{"Championship", "Find championship events"},
+				{"Las Vegas", "Find events in Las Vegas"},
+				{"NV", "Find all Nevada events"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Search is case-insensitive",
+					"• Use quotes for multi-word exact phrases",
+					"• Only searches your subscribed states",
+					"• Results show course info if available",
+				}},
+			},
+			Related: []string{"near", "events"},
+			Handler: handleSearchCommand,
+		},
+		{
+			Name: "near", Summary: "Find events near a city", Emoji: "📍",
+			Cost:        costFetch,
+			Localized:   map[string]string{"es": "Buscar eventos cerca de una ciudad"},
+			Icon:        "📍",
+			Title:       "Find Events Near a City",
+			Description: "Find VGA events happening near a specific city. Uses fuzzy matching to find events in the same city or nearby locations.",
+			Usage:       []usageLine{{"<city>", "Find events near a city"}},
+			Examples: []usageLine{
+				{"Las Vegas", "Events near Las Vegas"},
+				{`"San Diego"`, "Events near San Diego"},
+				{"Phoenix", "Events near Phoenix"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Searches your subscribed states only",
+					"• City names are case-insensitive",
+					"• Use quotes for multi-word city names",
+					"• Shows events in matching cities",
+				}},
+			},
+			Related: []string{"search", "events"},
+			Handler: handleNearCommand,
+		},
+		{
+			Name: "events", Summary: "View all events for your subscribed states", Emoji: "📅",
+			Cost:        costFetch,
+			Localized:   map[string]string{"es": "Ver todos los eventos de tus estados"},
+			Icon:        "📅",
+			Title:       "View All Events",
+			Description: "View all upcoming VGA events in your subscribed states. Shows event details and course information if available.",
+			Usage:       []usageLine{{"", "List all upcoming events"}},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Only shows events in subscribed states",
+					"• Click any event to mark status (⭐ Interested, ✅ Registered, etc.)",
+					"• Events sorted by date",
+					"• Includes golf course details when available",
+				}},
+			},
+			Related: []string{"my-events", "search", "subscribe"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleAllEvents(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun, ctx.modified)
+			},
+		},
+		{
+			Name: "season", Summary: "Your season schedule, month by month", Emoji: "🗓",
+			Cost:        costFetch,
+			Localized:   map[string]string{"es": "Tu temporada, mes a mes"},
+			Icon:        "🗓",
+			Title:       "Season Schedule",
+			Description: "A compact month-by-month schedule of upcoming events in your subscribed states, one line per event with your status. Export the whole season to your calendar with one tap.",
+			Usage:       []usageLine{{"", "Show your season schedule"}},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• ✅ Registered, ⭐ Interested, 🤔 Maybe, ▫️ Not marked",
+					"• Skipped and past events are left out",
+					"• The calendar export includes your statuses and notes",
+				}},
+			},
+			Related: []string{"events", "my-events", "export-calendar"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleSeason(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "my-events", Summary: "View your tracked events", Emoji: "⭐",
+			Localized:   map[string]string{"es": "Ver tus eventos marcados"},
+			Icon:        "⭐",
+			Title:       "View Your Tracked Events",
+			Description: "View events you've marked with a status: ⭐ Interested, ✅ Registered, 🤔 Maybe. Excludes events marked as ❌ Skip.",
+			Usage:       []usageLine{{"", "List your tracked events"}},
+			Sections: []helpSection{
+				{"Event Statuses", []string{
+					"• ⭐ Interested - Events you want to attend",
+					"• ✅ Registered - Events you've signed up for",
+					"• 🤔 Maybe - Events you're considering",
+					"• ❌ Skip - Events you're not interested in (hidden)",
+				}},
+				{"Tips", []string{
+					"• Shows your personal notes if added",
+					"• Get reminders for ⭐ and ✅ events",
+					"• Friends can see your ✅ events (if sharing enabled)",
+					"• Click event to change status",
+				}},
+			},
+			Related: []string{"events", "note", "reminders"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleMyEvents(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun, ctx.modified)
+			},
+		},
+		{
+			Name: "note", Summary: "Add a note to an event", Emoji: "📝",
+			Localized:   map[string]string{"es": "Agregar una nota a un evento"},
+			Icon:        "📝",
+			Title:       "Add Notes to Events",
+			Description: "Add personal notes to events. Notes appear in notifications, reminders, and event details. Maximum 500 characters.",
+			Usage: []usageLine{
+				{"<event_id>", "Show a note and its attachment"},
+				{"<event_id> <text>", "Add or update a note"},
+				{"<event_id> clear", "Remove a note and its attachment"},
+				{"<event_id> detach", "Remove just the attachment"},
+			},
+			Examples: []usageLine{
+				{"abc123 Bringing guest clubs", ""},
+				{"abc123 Playing with John and Sarah", ""},
+				{"abc123 clear", "Remove the note"},
+				{"NV-417 Early tee time", "Use the 🔖 code from the event card"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Event codes like NV-417 work anywhere an event ID does",
+					"• Notes are private (only you see them)",
+					"• Appear in event notifications and reminders",
+					"• Update anytime by sending new note",
+					"• Max 500 characters per note",
+					"• Attach a photo or file (e.g. a pairing sheet) by sending it with the caption /note <event_id>",
+					"• After /note <event_id>, send a voice message to add it to the note",
+				}},
+			},
+			Related: []string{"notes", "my-events"},
+			Handler: handleNoteCommand,
+		},
+		{
+			Name: "notes", Summary: "List all events with notes", Emoji: "📋",
+			Localized:   map[string]string{"es": "Listar eventos con notas"},
+			Icon:        "📋",
+			Title:       "List Events with Notes",
+			Description: "View all events where you've added personal notes. Shows event details along with your notes.",
+			Usage:       []usageLine{{"", "List all events with notes"}},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Only shows events you've added notes to",
+					"• Includes event status (⭐ Interested, ✅ Registered, etc.)",
+					"• Click event for more details",
+					"• Use /note to add or edit notes",
+				}},
+			},
+			Related: []string{"note", "my-events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleListNotes(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "filter", Summary: "Filter events (date, course, city, tag, weekends)", Emoji: "🔍",
+			Localized:   map[string]string{"es": "Filtrar eventos (fecha, campo, ciudad, etiqueta, fines de semana)"},
+			Icon:        "🔍",
+			Title:       "Event Filtering",
+			Description: "Create custom filters to narrow down events by date, course, city, tag, or weekends only. Save filters as presets for quick reuse.",
+			Usage: []usageLine{
+				{"", "Show current filter status"},
+				{"date <range>", "Filter by date range"},
+				{"course <name>", "Filter by course name"},
+				{"city <name>", "Filter by city"},
+				{"tag <tag>", "Filter by tag: championship, major, qualifier, scramble, charity"},
+				{"weekends", "Toggle weekends-only"},
+				{"save <name>", "Save current filter"},
+				{"load <name>", "Load saved filter"},
+				{"delete <name>", "Delete saved filter"},
+				{"schedule <name> daily|weekly [day] [hour]", "Send a saved filter's matches on a schedule"},
+				{"unschedule <name>", "Stop a scheduled report"},
+				{"clear", "Remove active filter"},
+			},
+			Sections: []helpSection{
+				{"Date Range Examples", []string{
+					`/filter date "Mar 1-15" - March 1 to 15`,
+					`/filter date "March 1 - April 15" - March 1 to April 15`,
+					`/filter date "March" - Entire month of March`,
+				}},
+				{"Course/City/Tag Examples", []string{
+					`/filter course "Pebble Beach" - Events at Pebble Beach`,
+					`/filter city "Las Vegas" - Events in Las Vegas`,
+					`/filter tag championship - Championships (tags are inferred from event titles)`,
+				}},
+				{"Combining Filters", []string{
+					`1. /filter date "Mar 1-15" - Set date range`,
+					"2. /filter weekends - Add weekends-only",
+					`3. /filter course "Pebble" - Add course filter`,
+					`4. /filter save "March Pebble Weekends" - Save combination`,
+				}},
+				{"Scheduled Reports", []string{
+					`/filter schedule "March Weekends" weekly - Mondays at your digest hour`,
+					`/filter schedule "March Weekends" weekly fri 6pm - Fridays at 18:00 UTC`,
+					`/filter schedule "Pebble" daily 7am - Every day at 07:00 UTC`,
+					"Reports list upcoming matching events in your states; empty reports aren't sent",
+				}},
+				{"Tips", []string{
+					"• Filters apply to your subscribed states",
+					"• Combine multiple criteria (date + course + weekends)",
+					"• Save useful filters as presets",
+					"• Active filter applies to /events and /search",
+					"• Use /filter to see current active filter",
+				}},
+			},
+			Related: []string{"filters", "events", "search"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return processFilterCommand(ctx.parts, ctx.prefs, ctx.chatID, ctx.modified)
+			},
+		},
+		{
+			Name: "filters", Summary: "List all saved filters", Emoji: "📋",
+			Localized:   map[string]string{"es": "Listar filtros guardados"},
+			Icon:        "📋",
+			Title:       "List Saved Filters",
+			Description: "View all your saved filter presets. Shows filter criteria and which one is currently active.",
+			Usage:       []usageLine{{"", "List all saved filters"}},
+			Sections: []helpSection{
+				{"Filter Actions", []string{
+					`• /filter load "name" - Activate a saved filter`,
+					`• /filter delete "name" - Remove a saved filter`,
+					"• /filter - View current active filter",
+				}},
+				{"Example Workflow", []string{
+					`1. Create a filter: /filter date "March"`,
+					"2. Add criteria: /filter weekends",
+					`3. Save it: /filter save "March Weekends"`,
+					`4. Later, load it: /filter load "March Weekends"`,
+				}},
+				{"Tips", []string{
+					"• Saved filters persist across sessions",
+					"• Quick way to reuse common filter combinations",
+					"• Active filter shown with ✅ checkmark",
+					"• Delete unused filters to keep list clean",
+				}},
+			},
+			Related: []string{"filter", "events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleFiltersList(ctx.prefs, ctx.chatID)
+			},
+		},
+		{
+			Name: "reminders", Summary: "Configure event reminders", Emoji: "🔔",
+			Localized:   map[string]string{"es": "Configurar recordatorios"},
+			Icon:        "🔔",
+			Title:       "Configure Event Reminders",
+			Description: "Set when you want to be reminded about events marked ⭐ Interested or ✅ Registered. Reminders sent daily at 9 AM UTC.",
+			Usage:       []usageLine{{"", "Show reminder configuration menu"}},
+			Sections: []helpSection{
+				{"Options", []string{
+					"• 1 day before event",
+					"• 3 days before event",
+					"• 1 week before event",
+					"• 2 weeks before event",
+				}},
+				{"Tips", []string{
+					"• Only reminded about ⭐ and ✅ events",
+					"• Reminders include your notes",
+					"• Can disable reminders entirely",
+					"• Configure via interactive buttons",
+				}},
+			},
+			Related: []string{"my-events", "note", "settings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleRemindersWithKeyboard(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "notify-removals", Summary: "Toggle removal notifications", Emoji: "⚠️",
+			Localized:   map[string]string{"es": "Activar avisos de eventos eliminados"},
+			Icon:        "⚠️",
+			Title:       "Toggle Removal Notifications",
+			Description: "Get notified when events are removed or cancelled from the VGA website. Two priority levels based on your engagement.",
+			Usage: []usageLine{
+				{"on", "Enable notifications"},
+				{"off", "Disable notifications"},
+				{"", "Show current setting"},
+			},
+			Sections: []helpSection{
+				{"Notification Levels", []string{
+					"⚠️ <b>High Priority:</b> Events you're registered for (✅) or tracking (⭐)",
+					"ℹ️ <b>Low Priority:</b> Events in your subscribed states",
+				}},
+				{"Tips", []string{
+					"• Includes your notes if you had any",
+					"• Removal notifications sent immediately",
+					"• Default: ON",
+					"• Removed events kept for 30 days",
+				}},
+			},
+			Related: []string{"my-events", "settings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleNotifyRemovals(ctx.prefs, ctx.chatID, ctx.arg(1), ctx.modified)
+			},
+		},
+		{
+			Name: "test-notification", Summary: "Preview your notifications", Emoji: "🧪",
+			Localized:   map[string]string{"es": "Vista previa de tus notificaciones"},
+			Icon:        "🧪",
+			Title:       "Test Notifications",
+			Description: "Send yourself a sample new-event notification, reminder, and digest rendered with your current settings, so you can check formatting and delivery without waiting for a real event.",
+			Usage:       []usageLine{{"", "Send sample notifications"}},
+			Sections: []helpSection{
+				{"What's Used", []string{
+					"• An upcoming event from your subscribed states",
+					"• Your active filter",
+					"• Course details, photo, and tee times",
+					"• Your reminder days and digest frequency",
+				}},
+				{"Tips", []string{
+					"• A sample event is used if nothing matches",
+					"• Sample messages don't change your tracked events",
+				}},
+			},
+			Related: []string{"settings", "reminders", "filter"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleTestNotification(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "stats", Summary: "View your engagement statistics", Emoji: "📊",
+			Localized:   map[string]string{"es": "Ver tus estadísticas"},
+			Icon:        "📊",
+			Title:       "View Engagement Statistics",
+			Description: "View your VGA Events Bot usage statistics and engagement metrics.",
+			Usage: []usageLine{
+				{"", "Show this week's stats (default)"},
+				{"week", "This week's statistics"},
+				{"month", "This month's statistics"},
+				{"all", "All-time statistics"},
+				{"household", "Combined stats for linked accounts"},
+			},
+			Sections: []helpSection{
+				{"Metrics Tracked", []string{
+					"• Events tracked (⭐ ✅ 🤔)",
+					"• Events skipped (❌)",
+					"• Notes added",
+					"• Searches performed",
+					"• Commands used",
+				}},
+				{"Tips", []string{
+					"• Stats reset weekly (Sundays at 11:59 PM UTC)",
+					"• Historical data saved for trends",
+					"• Weekly stats archived automatically",
+				}},
+			},
+			Related: []string{"my-events", "notes"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				period := "week"
+				if arg := ctx.arg(1); arg != "" {
+					period = strings.ToLower(arg)
+				}
+				return handleStats(ctx.prefs, ctx.chatID, period), nil
+			},
+		},
+		{
+			Name: "bulk", Summary: "Bulk actions for multiple events", Emoji: "🔧",
+			Localized:   map[string]string{"es": "Acciones para varios eventos"},
+			Icon:        "🔧",
+			Title:       "Bulk Operations",
+			Description: "Perform actions on multiple events at once. Useful for managing, tracking, or organizing events in bulk.",
+			Usage: []usageLine{
+				{"", "Show bulk actions menu (interactive)"},
+				{"select", "Pick events with checkboxes, then mark or export them"},
+				{"register <event_ids>", "Mark multiple events as registered"},
+				{"note <event_ids> <note_text>", "Add same note to multiple events"},
+				{"status <status> <event_ids>", "Set status for multiple events"},
+			},
+			Examples: []usageLine{
+				{"register abc123 def456", "Mark two events as registered"},
+				{`note abc123,def456 "Must play early"`, "Add note to two events"},
+				{"status interested abc123,def456", "Mark two events as interested"},
+				{"status skip abc123 def456 ghi789", "Skip three events"},
+			},
+			Sections: []helpSection{
+				{"Event ID Formats", []string{
+					"• Space-separated: /bulk register abc123 def456 ghi789",
+					"• Comma-separated: /bulk register abc123,def456,ghi789",
+				}},
+				{"Valid Statuses", []string{
+					"• interested - ⭐ Mark as interested",
+					"• registered - ✅ Mark as registered",
+					"• maybe - 🤔 Mark as maybe",
+					"• skip - ❌ Mark to skip",
+				}},
+				{"Select Mode (/bulk select)", []string{
+					"• Tap ⬜ events to check them; pages of 8 with ◀️/▶️",
+					"• Mark selected as ✅ Registered or ❌ Skip, or 📥 Export to .ics",
+					"• Your selection is kept until you apply it or tap Done",
+				}},
+				{"Interactive Actions (via /bulk menu)", []string{
+					"• <b>Clear Skipped Events</b> - Remove all ❌ Skip status marks",
+					"• <b>Export Registered Events</b> - Download calendar file (.ics) of all ✅ Registered events",
+				}},
+				{"Tips", []string{
+					"• Operations are immediate and show success count",
+					"• Use the 🔖 code from event cards (e.g. NV-417) or the full event ID",
+					"• Use comma or space to separate multiple IDs",
+					"• Note text limited to 500 characters",
+				}},
+			},
+			Related: []string{"my-events", "note", "export-calendar"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return processBulkCommand(ctx.parts, ctx.prefs, ctx.chatID, ctx.modified, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "export-calendar", Summary: "Download all events as .ics file", Emoji: "📅",
+			Cost:        costHeavy,
+			Localized:   map[string]string{"es": "Descargar eventos como archivo .ics"},
+			Icon:        "📅",
+			Title:       "Export to Calendar",
+			Description: "Download VGA events as an iCalendar (.ics) file compatible with Google Calendar, Apple Calendar, Outlook, etc.",
+			Usage: []usageLine{
+				{"", "Export all subscribed events"},
+				{"<STATE>", "Export events from specific state"},
+				{"[STATE] [registered] [interested] [split] [date range]", "Narrow the export; options can be combined in any order"},
+			},
+			Examples: []usageLine{
+				{"", "All events from subscribed states"},
+				{"NV", "Only Nevada events"},
+				{"registered", "Events you're registered for, in any state"},
+				{"NV Apr 1-30", "Nevada events in April"},
+				{"split", "One calendar per state, zipped together"},
+			},
+			Sections: []helpSection{
+				{"Options", []string{
+					"• registered / interested - Only events with that status (either, if both are given)",
+					"• Date range - Same formats as /filter date: Mar 1-15, March 1 - April 15, or March",
+					"• split - A .zip with a separate .ics file for each state",
+				}},
+				{"Tips", []string{
+					"• Import .ics file into any calendar app",
+					"• Events include full details and location",
+					"• Re-export anytime to get updates",
+					"• File sent directly in Telegram",
+				}},
+			},
+			Related: []string{"bulk", "events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleExportCalendar(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "export-pdf", Summary: "Download a printable PDF schedule", Emoji: "📄",
+			Cost:        costHeavy,
+			Localized:   map[string]string{"es": "Descargar un calendario PDF para imprimir"},
+			Icon:        "📄",
+			Title:       "Printable Schedule",
+			Description: "Download a one-page PDF schedule (date, course, city, and your status) to print or share.",
+			Usage: []usageLine{
+				{"", "Your tracked events"},
+				{"<STATE>", "Upcoming events in a state"},
+			},
+			Examples: []usageLine{
+				{"", "Events you've marked ⭐, ✅, or 🤔"},
+				{"NV", "Upcoming Nevada events"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Fits on one page; long schedules list the first events and a count of the rest",
+					"• Skipped events are left out",
+				}},
+			},
+			Related: []string{"export-calendar", "season", "my-events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				state := strings.ToUpper(strings.TrimSpace(ctx.arg(1)))
+				return handleExportPDF(ctx.prefs, ctx.chatID, state, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "export-all", Summary: "Download all your data as a ZIP", Emoji: "📦",
+			Cost:        costHeavy,
+			Localized:   map[string]string{"es": "Descargar todos tus datos en un ZIP"},
+			Icon:        "📦",
+			Title:       "Export Your Data",
+			Description: "Download everything the bot keeps for you in one ZIP file.",
+			Usage: []usageLine{
+				{"", "Send the ZIP"},
+			},
+			Sections: []helpSection{
+				{"What's Inside", []string{
+					"• preferences.json - Your settings, subscriptions, and statuses",
+					"• notes.md - Your event notes",
+					"• tracked-events.csv - Tracked and archived events, for spreadsheets",
+					"• calendar.ics - Your tracked events, for any calendar app",
+				}},
+			},
+			Related: []string{"export-calendar", "export-pdf", "notes"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleExportAll(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "import", Summary: "Import tracked events from a CSV", Emoji: "📥",
+			Localized:   map[string]string{"es": "Importar eventos desde un CSV"},
+			Icon:        "📥",
+			Title:       "Import Events from CSV",
+			Description: "Send a spreadsheet of events as a .csv file to mark them all at once. Each row is matched to a current event by title, and by date and state when given.",
+			Usage: []usageLine{
+				{"[status]", "Caption for the .csv file; matched events get this status (default interested)"},
+			},
+			Examples: []usageLine{
+				{"", "Mark the listed events interested"},
+				{"registered", "Mark the listed events registered"},
+			},
+			Sections: []helpSection{
+				{"Columns", []string{
+					"• With a header row, in any order: title, date, state, status, event_id",
+					"• Without a header: title, date, state",
+					"• A row's status column wins over the caption's",
+					"• Dates like 2026-04-18, 4/18/2026, or Apr 18 2026",
+				}},
+				{"Tips", []string{
+					"• The reply lists the rows that didn't match, by line",
+					"• The tracked-events.csv from /export-all imports as is",
+					"• Up to 200 rows per file",
+				}},
+			},
+			Related: []string{"bulk", "export-all", "my-events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return importUsage, nil
+			},
+		},
+		{
+			Name: "invite", Summary: "Get your friend invite code", Emoji: "👥",
+			Localized:   map[string]string{"es": "Obtener tu código de invitación"},
+			Icon:        "👥",
+			Title:       "Get Friend Invite Code",
+			Description: "Generate your personal invite code to share with golf buddies. Friends who join can see which events you're registered for.",
+			Usage: []usageLine{
+				{"", "Show your invite code"},
+				{"new", "Replace your code; the old one stops working"},
+				{"new <duration> [once]", "A code that expires, or works for one friend"},
+			},
+			Examples: []usageLine{{"new 7d", "Code valid for a week"}, {"new once", "Code for one friend"}},
+			Sections: []helpSection{
+				{"How It Works", []string{
+					"1. You send /invite to get your code",
+					"2. Share code with your friend",
+					"3. They send /join &lt;your_code&gt;",
+					"4. Approve their request and you're connected!",
+				}},
+				{"Privacy", []string{
+					"• Friends see events you mark as ✅ Registered",
+					"• Requires both users to enable sharing in /settings",
+					"• Only shows registered events (not ⭐ or 🤔)",
+					"• You can disable sharing anytime",
+				}},
+			},
+			Related: []string{"join", "friends", "settings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleInvite(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "friends", Summary: "View your friend list", Emoji: "👥",
+			Localized:   map[string]string{"es": "Ver tu lista de amigos"},
+			Icon:        "👥",
+			Title:       "View Friend List",
+			Description: "See your list of connected golf buddies. When both you and a friend enable sharing, you'll see events they've registered for.",
+			Usage:       []usageLine{{"", "Show your friend list"}},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Shows who can see your registered events",
+					"• Sharing must be enabled by both users",
+					"• Use /settings to control sharing",
+					`• Friends see "👥 Your friends: @username" on shared events`,
+				}},
+			},
+			Related: []string{"invite", "join", "settings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleFriends(ctx.prefs, ctx.chatID), nil
+			},
+		},
+		{
+			Name: "join", Summary: "Join via friend invite code", Emoji: "👥",
+			Localized:   map[string]string{"es": "Unirte con un código de invitación"},
+			Icon:        "👥",
+			Title:       "Add a Friend",
+			Description: "Send a friend request to a golf buddy using their invite code. Once they approve it, see which events they're registered for (when both have sharing enabled).",
+			Usage:       []usageLine{{"<invite_code>", "Add friend using their code"}},
+			Examples:    []usageLine{{"K7QM2XPA", "Connect with friend"}},
+			Sections: []helpSection{
+				{"Steps", []string{
+					"1. Get friend's invite code (they use /invite)",
+					"2. Send /join &lt;their_code&gt;",
+					"3. They approve your request",
+					"4. Both enable sharing in /settings to see each other's registered events",
+				}},
+				{"Privacy", []string{
+					"• Sharing is optional (configure in /settings)",
+					"• Only ✅ Registered events are shared",
+					"• Either user can disable sharing anytime",
+				}},
+			},
+			Related: []string{"invite", "friends", "settings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				if len(ctx.parts) < 2 {
+					return "❌ Please provide an invite code.\n\nUsage: /join <invite_code>", nil
+				}
+				return handleJoin(ctx.prefs, ctx.chatID, ctx.parts[1], ctx.modified, ctx.botToken, ctx.dryRun), nil
+			},
+		},
+		{
+			Name: "block", Summary: "Block a user's friend requests", Emoji: "🚫",
+			Localized:   map[string]string{"es": "Bloquear solicitudes de amistad de un usuario"},
+			Icon:        "🚫",
+			Title:       "Block a User",
+			Description: "Stop someone from sending you friend requests. If you're friends, the friendship ends and nothing is shared between you anymore. They aren't told.",
+			Usage: []usageLine{
+				{"", "List the users you've blocked"},
+				{"<user_id>", "Block a user (the ID shown in /friends or a friend request)"},
+			},
+			Examples: []usageLine{{"123456789", "Block that user"}},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Friend requests have a 🚫 Block button too",
+					"• /unblock &lt;user_id&gt; undoes it",
+				}},
+			},
+			Related: []string{"unblock", "friends", "invite"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleBlock(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "unblock", Summary: "Unblock a user", Hidden: true,
+			Localized:   map[string]string{"es": "Desbloquear a un usuario"},
+			Icon:        "✅",
+			Title:       "Unblock a User",
+			Description: "Let a user you blocked send you friend requests again. You aren't friends again until you approve a new request.",
+			Usage:       []usageLine{{"<user_id>", "Unblock a user"}},
+			Related:     []string{"block", "friends"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleUnblock(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "discuss", Summary: "Talk about an event with friends", Emoji: "💬",
+			Localized:   map[string]string{"es": "Conversar con amigos sobre un evento"},
+			Icon:        "💬",
+			Title:       "Event Discussion",
+			Description: "Plan an event with your golf buddies — carpools, tee times, who's in. Messages are kept with the event and sent to friends who are tracking it.",
+			Usage: []usageLine{
+				{"<event_id>", "Show the discussion"},
+				{"<event_id> <message>", "Send a message to friends"},
+			},
+			Examples: []usageLine{
+				{"NV-417", "Read the discussion"},
+				{"NV-417 Anyone want to carpool?", ""},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Tap 💬 Discuss on any event to open its discussion",
+					"• Only friends you added with /invite or /join see your messages",
+					"• Messages are kept for 90 days",
+				}},
+			},
+			Related: []string{"friends", "invite", "note"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleDiscuss(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified, ctx.botToken, ctx.dryRun), nil
+			},
+		},
+		{
+			Name: "group-note", Summary: "Shared notes on an event for your friends", Emoji: "📌",
+			Localized:   map[string]string{"es": "Notas compartidas de un evento con tus amigos"},
+			Icon:        "📌",
+			Title:       "Group Notes",
+			Description: "A note on an event that you and your friends can all read and add to — meeting spots, tee times, who's driving. Each line shows who added it, and your friends are told when you add one. Separate from your private /note.",
+			Usage: []usageLine{
+				{"<event_id>", "Show the group note"},
+				{"<event_id> <text>", "Add a line and tell your friends"},
+				{"<event_id> clear", "Remove the lines you added"},
+			},
+			Examples: []usageLine{
+				{"NV-417", "Read the group note"},
+				{"NV-417 Meeting at the range at 7:30", ""},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Only friends you added with /invite or /join can see it",
+					"• Use /discuss for back-and-forth; group notes are for plans to keep",
+					"• You can only remove your own lines",
+				}},
+			},
+			Related: []string{"note", "discuss", "friends"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleGroupNote(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified, ctx.botToken, ctx.dryRun), nil
+			},
+		},
+		{
+			Name: "poll", Summary: "Vote with friends on which event to play", Emoji: "🗳️",
+			Localized:   map[string]string{"es": "Votar con amigos qué evento jugar"},
+			Icon:        "🗳️",
+			Title:       "Event Poll",
+			Description: "Can't agree on an event? Send a Telegram poll to your friends (or your group chat, if the bot is in it), then close it to see the winner and mark everyone who voted as registered in one tap.",
+			Usage: []usageLine{
+				{"<id1> <id2> [id3 ...]", "Start a poll between 2 to 10 events"},
+				{"close", "Close your latest poll and show the winner"},
+			},
+			Examples: []usageLine{
+				{"NV-417 NV-422 AZ-108", "Which of these three?"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• In a private chat the poll goes to you and your friends",
+					"• In a group chat the poll goes to the group",
+					"• Ties go to the event listed first",
+				}},
+			},
+			Related: []string{"friends", "discuss", "my-events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handlePoll(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "league", Summary: "Run a season series with friends", Emoji: "🏆",
+			Localized:   map[string]string{"es": "Organizar una liga de temporada con amigos"},
+			Icon:        "🏆",
+			Title:       "Leagues",
+			Description: "Pick a series of events to play with your friends over the season. Members record their scores at each event, finishes earn points, and /standings adds them up.",
+			Usage: []usageLine{
+				{"", "List your leagues"},
+				{"create <name>", "Start a league with you and your friends"},
+				{"<id>", "Show a league's events, members, and points"},
+				{"<id> add <event_ids>", "Add events to the series (admin)"},
+				{"<id> remove <event_id>", "Take an event and its scores out (admin)"},
+				{"<id> member add|remove <user_id>", "Change members (admin)"},
+				{"<id> points <1st,2nd,...>", "Set points by finish, or default (admin)"},
+				{"<id> delete", "Delete the league (admin)"},
+				{"<id> leave", "Leave a league"},
+			},
+			Examples: []usageLine{
+				{"create Tuesday Crew", "Start a league"},
+				{"K7QZP add NV-417 NV-422", "Add two events"},
+				{"K7QZP points 10,8,6,4,2", "Top five earn points"},
+			},
+			Sections: []helpSection{
+				{"Scoring", []string{
+					"• Members record strokes with /score &lt;id&gt; &lt;event_id&gt; &lt;strokes&gt;",
+					"• The lowest score at an event finishes 1st; ties share the place",
+					"• Default points: 25, 20, 16, 13, 11, 10, ... down to 1 for 15th",
+					"• Ties in the standings go to more wins, then the best finish",
+				}},
+				{"Members", []string{
+					"• A new league includes all your friends",
+					"• Only your friends can be added; see their user IDs in /friends",
+				}},
+			},
+			Related: []string{"score", "standings", "friends"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleLeague(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "score", Summary: "Record your score in a league event", Emoji: "🏌️",
+			Localized:   map[string]string{"es": "Registrar tu puntuación en una liga"},
+			Icon:        "🏌️",
+			Title:       "Record a League Score",
+			Description: "Record your total strokes at an event in one of your league's series. Finishes and points update right away.",
+			Usage: []usageLine{
+				{"<league_id> <event_id> <strokes>", "Record your score"},
+				{"<league_id> <event_id> clear", "Remove your score"},
+				{"<league_id> <event_id> <strokes> <user_id>", "Record a member's score (admin)"},
+			},
+			Examples: []usageLine{
+				{"K7QZP NV-417 84", "You shot 84 at NV-417"},
+			},
+			Related: []string{"standings", "league"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleScore(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "standings", Summary: "Show a league's standings", Emoji: "🥇",
+			Localized:   map[string]string{"es": "Ver la clasificación de una liga"},
+			Icon:        "🥇",
+			Title:       "League Standings",
+			Description: "Show the points table for a league, or the finishes at one of its events.",
+			Usage: []usageLine{
+				{"", "Your league's standings, or a list if you're in several"},
+				{"<league_id>", "A league's standings"},
+				{"<league_id> <event_id>", "Finishes at one event"},
+			},
+			Related: []string{"score", "league"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleStandings(ctx.prefs, ctx.chatID, ctx.parts[1:]), nil
+			},
+		},
+		{
+			Name: "trends", Summary: "See the last 30 days of VGA event trends", Emoji: "📈",
+			Localized:   map[string]string{"es": "Ver las tendencias de los últimos 30 días"},
+			Icon:        "📈",
+			Title:       "Event Trends",
+			Description: "Summarize the last 30 days across all users: new events per state, the fastest-filling events, the most-tracked courses, and how far ahead events are posted.",
+			Usage: []usageLine{
+				{"", "Show the last 30 days"},
+			},
+			Sections: []helpSection{
+				{"Notes", []string{
+					"• Fill rates need the field size the VGA site lists under an event",
+					"• The same report is posted to the announcements channel every month",
+				}},
+			},
+			Related: []string{"stats", "standings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleTrends(ctx.prefs), nil
+			},
+		},
+		{
+			Name: "link", Summary: "Link with your other account or household", Emoji: "🔗",
+			Localized:   map[string]string{"es": "Vincular con tu otra cuenta o tu hogar"},
+			Icon:        "🔗",
+			Title:       "Link Accounts",
+			Description: "Link two or more chats, such as your phone and desktop accounts or a spouse's, so event statuses, notes, and notification history are shared and each event counts once in combined stats.",
+			Usage: []usageLine{
+				{"", "Show linked accounts and get a link code"},
+				{"<code>", "Link with the account that sent the code"},
+			},
+			Sections: []helpSection{
+				{"How It Works", []string{
+					"1. Send /link to get a one-time code",
+					"2. From the other account, send /link &lt;code&gt;",
+					"3. Statuses and notes are now shared",
+				}},
+				{"Tips", []string{
+					"• Each new event is sent to only one linked account",
+					"• Codes expire after 24 hours",
+					"• Up to 4 accounts can be linked",
+					"• /stats household shows combined stats",
+				}},
+			},
+			Related: []string{"unlink", "stats"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleLink(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "unlink", Summary: "Stop sharing with linked accounts", Emoji: "🔗",
+			Localized:   map[string]string{"es": "Dejar de compartir con cuentas vinculadas"},
+			Icon:        "🔗",
+			Title:       "Unlink Account",
+			Description: "Leave your household. You keep a copy of your event statuses and notes, but changes are no longer shared.",
+			Usage:       []usageLine{{"", "Unlink this account"}},
+			Related:     []string{"link"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleUnlink(ctx.prefs, ctx.chatID, ctx.modified), nil
+			},
+		},
+		{
+			Name: "api-token", Summary: "Get a personal token for the HTTP API", Emoji: "🔑",
+			Flag:        flags.WebAPI,
+			Localized:   map[string]string{"es": "Obtener un token personal para la API"},
+			Icon:        "🔑",
+			Title:       "API Token",
+			Description: "Create a personal token to read your tracked events, notes, filters, and a subscribable calendar feed from the HTTP API. Each token only sees your own data.",
+			Usage: []usageLine{
+				{"", "Show whether you have a token"},
+				{"new", "Create a token, replacing any existing one"},
+				{"revoke", "Stop the token from working"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• The token is shown once; only a fingerprint is stored",
+					"• /api-token new rotates it: the old token stops working",
+					"• Calendar apps can use the feed URL with ?token=",
+				}},
+			},
+			Related: []string{"export-calendar", "my-events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleAPIToken(ctx.prefs, ctx.chatID, ctx.parts[1:], *apiURL, ctx.modified), nil
+			},
+		},
+		{
+			Name: "subscribe", Summary: "Choose states with buttons (or /subscribe NV)",
+			Localized:   map[string]string{"es": "Elegir estados para seguir"},
+			Icon:        "📥",
+			Title:       "Subscribe to State Events",
+			Description: "Subscribe to VGA events in specific states. You'll receive notifications whenever new events are posted.",
+			Usage: []usageLine{
+				{"", "Show state selection buttons"},
+				{"<STATE>", "Subscribe to a specific state"},
+				{"<REGION>", "Subscribe to every state in a region"},
+				{"majors", "Subscribe to national championships and majors in any state"},
+			},
+			Examples: []usageLine{
+				{"NV", "Subscribe to Nevada"},
+				{"CA", "Subscribe to California"},
+				{"southwest", "Subscribe to NV, AZ, CA, UT, and NM"},
+				{"ALL", "Subscribe to all states"},
+				{"majors", "Get national and major events wherever they are"},
+			},
+			Sections: []helpSection{
+				{"State Codes", []string{
+					"Use 2-letter state codes like NV, CA, TX, AZ, etc.",
+					"Use ALL to get events from all states.",
+				}},
+				{"Regions", []string{
+					"Regions subscribe you to several states at once, e.g. southwest, west-coast, or midwest. Send /subscribe to see them all.",
+					"States you're already subscribed to are kept. Unsubscribe from states individually with /unsubscribe.",
+				}},
+				{"National and Majors", []string{
+					"VGA lists national championships and majors separately from state events. /subscribe majors sends you those wherever they're held; digests show them in their own section.",
+				}},
+			},
+			Related: []string{"unsubscribe", "list", "manage"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				if len(ctx.parts) < 2 {
+					// Show state selection keyboard
+					return handleSubscribeWithKeyboard(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+				}
+				return handleSubscribe(ctx.prefs, ctx.chatID, ctx.parts[1], ctx.modified, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "unsubscribe", Summary: "Remove a state subscription", Hidden: true,
+			Localized:   map[string]string{"es": "Dejar de seguir un estado"},
+			Icon:        "📤",
+			Title:       "Unsubscribe from States",
+			Description: "Remove state subscriptions. You'll stop receiving notifications for that state.",
+			Usage: []usageLine{
+				{"<STATE>", "Unsubscribe from a specific state"},
+				{"majors", "Stop national and major events outside your states"},
+				{"all", "Remove all subscriptions (requires confirmation)"},
+			},
+			Examples: []usageLine{
+				{"NV", "Stop Nevada notifications"},
+				{"all", "Remove all state subscriptions"},
+			},
+			Related: []string{"subscribe", "list", "manage"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				if len(ctx.parts) < 2 {
+					return "❌ Please specify a state code or 'all'.\n\nUsage: /unsubscribe NV\nUsage: /unsubscribe all", nil
+				}
+				if strings.ToLower(strings.TrimSpace(ctx.parts[1])) == "all" {
+					// Show confirmation keyboard
+					return handleUnsubscribeAllWithKeyboard(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+				}
+				return handleUnsubscribe(ctx.prefs, ctx.chatID, ctx.parts[1], ctx.modified), nil
+			},
+		},
+		{
+			Name: "subscribe-city", Summary: "Follow events in a city (or within a radius)",
+			Localized:   map[string]string{"es": "Seguir eventos en una ciudad"},
+			Icon:        "🏙️",
+			Title:       "Subscribe to a City",
+			Description: "Get notified about new events in one city, or within a radius of it, even if you're not subscribed to its state.",
+			Usage: []usageLine{
+				{"<city>", "Follow events in a city"},
+				{"<city> <STATE>", "Only match the city in that state"},
+				{"<city> <STATE> <miles>mi", "Also match events within a radius"},
+			},
+			Examples: []usageLine{
+				{"Las Vegas", "Events in Las Vegas"},
+				{`"Las Vegas" NV 25mi`, "Events within 25 miles of Las Vegas"},
+				{"Scottsdale, AZ", "Events in Scottsdale, Arizona"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Follow up to 10 cities",
+					"• Radius is up to 200 miles and works for major golf cities; others match by name",
+					"• Subscribing to the same city again updates its radius",
+				}},
+			},
+			Related: []string{"unsubscribe-city", "subscribe", "list"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleSubscribeCity(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "unsubscribe-city", Summary: "Stop following a city", Hidden: true,
+			Localized:   map[string]string{"es": "Dejar de seguir una ciudad"},
+			Icon:        "🏙️",
+			Title:       "Unsubscribe from a City",
+			Description: "Stop notifications for a city you follow with /subscribe-city.",
+			Usage:       []usageLine{{"<city> [STATE]", "Stop following a city"}},
+			Examples:    []usageLine{{"Las Vegas", "Stop Las Vegas notifications"}},
+			Related:     []string{"subscribe-city", "list"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleUnsubscribeCity(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "home", Summary: "Set your home city for distances on event cards", Emoji: "🏠",
+			Flag:        flags.Geocoding,
+			Localized:   map[string]string{"es": "Definir tu ciudad para ver distancias"},
+			Icon:        "🏠",
+			Title:       "Home City",
+			Description: "Set the city you travel from. New-event cards then show how many miles away each event is, next to any conflicts with events you're already tracking.",
+			Usage: []usageLine{
+				{"", "Show your home city"},
+				{"<city> <STATE>", "Set your home city"},
+				{"clear", "Remove your home city"},
+			},
+			Examples: []usageLine{
+				{"Las Vegas NV", "Distances from Las Vegas"},
+				{"Scottsdale, AZ", "Distances from Scottsdale"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Works for major golf cities; others can't be located",
+					"• Distances are straight-line miles",
+				}},
+			},
+			Related: []string{"subscribe-city", "near"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleHome(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "follow-course", Summary: "Get every event at a course, in any state", Emoji: "⛳",
+			Localized:   map[string]string{"es": "Seguir un campo de golf"},
+			Icon:        "⛳",
+			Title:       "Follow a Course",
+			Description: "Get notified right away about every new event at a course, in any state, whether or not you're subscribed to its state and even if you get a digest.",
+			Usage:       []usageLine{{"<course name>", "Follow a course"}},
+			Examples: []usageLine{
+				{`"Chimera Golf Club"`, "Follow Chimera Golf Club"},
+				{"Wolf Creek", "Follow Wolf Creek"},
+			},
+			Sections: []helpSection{
+				{"Matching", []string{
+					"• Names match the way duplicate events are detected: case, \"The\", and suffixes like \"Golf Club\" or \"CC\" are ignored",
+					"• Events whose title includes the course name also match",
+					"• Follow up to 20 courses",
+				}},
+			},
+			Related: []string{"following", "subscribe"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleFollowCourse(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "following", Summary: "Manage the courses you follow",
+			Localized:   map[string]string{"es": "Administrar los campos que sigues"},
+			Icon:        "⛳",
+			Title:       "Followed Courses",
+			Description: "List the courses you follow, with a button to stop following each one.",
+			Usage:       []usageLine{{"", "Show your followed courses"}},
+			Related:     []string{"follow-course", "unfollow-course"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleFollowing(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+			},
+		},
+		{
+			Name: "unfollow-course", Summary: "Stop following a course", Hidden: true,
+			Localized:   map[string]string{"es": "Dejar de seguir un campo"},
+			Icon:        "⛳",
+			Title:       "Unfollow a Course",
+			Description: "Stop notifications for a course you follow with /follow-course.",
+			Usage:       []usageLine{{"<course name>", "Stop following a course"}},
+			Related:     []string{"following", "follow-course"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleUnfollowCourse(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "travel", Summary: "Follow a state while you're traveling", Emoji: "✈️",
+			Localized:   map[string]string{"es": "Seguir un estado durante un viaje"},
+			Icon:        "✈️",
+			Title:       "Travel Mode",
+			Description: "Temporarily follow a state for a trip. You're notified about new events there that fall within your travel dates, and the trip is removed once it's over.",
+			Usage: []usageLine{
+				{"", "List your trips"},
+				{"<STATE> <dates>", "Plan a trip"},
+				{"cancel <STATE>", "Cancel a trip"},
+			},
+			Examples: []usageLine{
+				{"NV Apr 10-20", "Events in Nevada from April 10 to 20"},
+				{"AZ Apr 28-May 3", "Trips can span months"},
+				{"cancel NV", "Cancel the Nevada trip"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Dates are the next upcoming ones, so a trip in January can be planned in December",
+					"• Trips can be up to 60 days, with up to 5 planned at once",
+					"• Trips show in /list until they end",
+				}},
+			},
+			Related: []string{"subscribe", "list"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleTravel(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "pause", Summary: "Mute notifications for a while", Emoji: "⏸️",
+			Localized:   map[string]string{"es": "Silenciar las notificaciones por un tiempo"},
+			Icon:        "⏸️",
+			Title:       "Pause Notifications",
+			Description: "Mute all notifications for a while without losing your subscriptions. New events are saved up and sent as one catch-up digest when the pause ends or you use /resume.",
+			Usage: []usageLine{
+				{"", "Show when your pause ends"},
+				{"<duration>", "Pause for days, weeks, or months"},
+			},
+			Examples: []usageLine{
+				{"3d", "Pause for 3 days"},
+				{"2w", "Pause for 2 weeks"},
+				{"1 month", "Pause for 30 days"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Pausing again changes when the pause ends",
+					"• Pauses can be up to 90 days",
+					"• Reminders and digests are muted too",
+				}},
+			},
+			Related: []string{"resume", "settings"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handlePause(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "resume", Summary: "Turn notifications back on", Emoji: "▶️",
+			Localized:   map[string]string{"es": "Reactivar las notificaciones"},
+			Icon:        "▶️",
+			Title:       "Resume Notifications",
+			Description: "End a pause early. You'll get a catch-up digest of the events that came up while notifications were paused.",
+			Usage: []usageLine{
+				{"", "Resume notifications now"},
+			},
+			Related: []string{"pause"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleResume(ctx.prefs, ctx.chatID, ctx.modified), nil
+			},
+		},
+		{
+			Name: "manage", Summary: "Manage your subscriptions with buttons",
+			Localized:   map[string]string{"es": "Administrar tus suscripciones"},
+			Icon:        "⚙️",
+			Title:       "Manage Subscriptions",
+			Description: "Interactive menu to manage your state subscriptions using buttons. Easier than typing commands.",
+			Usage:       []usageLine{{"", "Show subscription management menu"}},
+			Sections: []helpSection{
+				{"Features", []string{
+					"• View current subscriptions",
+					"• Add states with one tap",
+					"• Remove states with confirmation",
+					"• See all available states",
+				}},
+				{"Tips", []string{
+					"• More user-friendly than typing commands",
+					"• Shows state names, not just codes",
+					"• Confirms before removing states",
+					"• Same as /subscribe and /unsubscribe",
+				}},
+			},
+			Related: []string{"subscribe", "unsubscribe", "list"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				responseText, _ := handleManageWithKeyboard(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+				return responseText, nil
+			},
+		},
+		{
+			Name: "settings", Summary: "Configure notification preferences",
+			Localized:   map[string]string{"es": "Configurar notificaciones"},
+			Icon:        "⚙️",
+			Title:       "Notification Preferences",
+			Description: "Configure how and when you receive event notifications. The menu has a page for each group of settings; tap a button to change one.",
+			Usage: []usageLine{
+				{"", "Show settings menu"},
+				{"time [day] <hour> [time zone]", "Choose when your digest is sent"},
+			},
+			Examples: []usageLine{
+				{"time 7am", "Daily digest at 7 AM"},
+				{"time sat 8am America/Los_Angeles", "Weekly digest on Saturdays at 8 AM Pacific"},
+			},
+			Sections: []helpSection{
+				{"Options", []string{
+					"• <b>Notifications:</b> Immediate, Daily Digest, or Weekly Digest, plus change and removal notices",
+					"• <b>Digest Time:</b> The hour, and day for weekly digests",
+					"• <b>Events Shown:</b> Hide past events, and how far ahead to look",
+					"• <b>Reminders:</b> When to be reminded about events you're tracking",
+					"• <b>Weekly Stats:</b> Turn your /stats tracking on or off",
+					"• <b>Friend Sharing:</b> Let friends see your registered events",
+				}},
+				{"Notification Modes", []string{
+					"• <b>Immediate</b> - Instant notifications (default)",
+					"• <b>Daily Digest</b> - One summary a day, at 9 AM UTC unless you choose a time",
+					"• <b>Weekly Digest</b> - One summary a week, Mondays at 9 AM UTC unless you choose a time",
+				}},
+			},
+			Related: []string{"reminders", "notify-removals", "horizon", "friends"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				if strings.EqualFold(ctx.arg(1), "time") {
+					return handleDigestTime(ctx.prefs, ctx.chatID, ctx.parts[2:], ctx.modified), nil
+				}
+				responseText, _ := handleSettingsWithKeyboard(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun)
+				return responseText, nil
+			},
+		},
+		{
+			Name: "horizon", Summary: "Limit events to the next N days",
+			Localized:   map[string]string{"es": "Limitar los eventos a los próximos N días"},
+			Icon:        "📅",
+			Title:       "Event Horizon",
+			Description: "Choose how far ahead to look for events. /events, /search, /near, digests, and new-event notifications all leave out events beyond the horizon.",
+			Usage: []usageLine{
+				{"", "Show your current horizon"},
+				{"<days>", "Only include events this many days ahead"},
+				{"off", "Include events on any date"},
+			},
+			Examples: []usageLine{
+				{"60", "Events in the next 60 days"},
+				{"off", "No limit"},
+			},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• The horizon can be up to 365 days",
+					"• Also set from /settings → Events shown",
+					"• Events without a readable date are always included",
+				}},
+			},
+			Related: []string{"settings", "events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleHorizon(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "list", Summary: "Show your current subscriptions",
+			Localized:   map[string]string{"es": "Ver tus suscripciones"},
+			Icon:        "📋",
+			Title:       "Show Subscriptions",
+			Description: "Display all states you're currently subscribed to. You receive event notifications from these states.",
+			Usage:       []usageLine{{"", "Show your subscribed states"}},
+			Sections: []helpSection{
+				{"Tips", []string{
+					"• Shows state codes and full names",
+					"• Add states with /subscribe",
+					"• Remove states with /unsubscribe",
+					"• Use /manage for button-based management",
+				}},
+			},
+			Related: []string{"subscribe", "unsubscribe", "manage"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleList(ctx.prefs, ctx.chatID), nil
+			},
+		},
+		{
+			Name: "check", Summary: "Trigger an immediate check (experimental)", Hidden: true,
+			Cost:        costHeavy,
+			Localized:   map[string]string{"es": "Buscar eventos nuevos ahora (experimental)"},
+			Icon:        "🔄",
+			Title:       "Manual Event Check",
+			Description: "Trigger an immediate check for new events instead of waiting for the hourly automatic check. Experimental feature.",
+			Usage:       []usageLine{{"", "Check for new events now"}},
+			Sections: []helpSection{
+				{"How It Works", []string{
+					"• Triggers GitHub Actions workflow",
+					"• Checks VGA website for new events",
+					"• Sends notifications if new events found",
+					"• Usually completes within 1-2 minutes",
+				}},
+				{"Notes", []string{
+					"• This is an experimental feature",
+					"• Automatic checks run every hour",
+					"• Rate limits may apply",
+					"• No need to use frequently",
+				}},
+			},
+			Related: []string{"events", "my-events"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleCheck(ctx.prefs, ctx.chatID, ctx.botToken, ctx.dryRun, ctx.modified)
+			},
+		},
+		{
+			Name: "report", Summary: "Report a problem to the maintainer", Emoji: "🐞",
+			Cost:        costReport,
+			Localized:   map[string]string{"es": "Informar un problema al responsable"},
+			Icon:        "🐞",
+			Title:       "Report a Problem",
+			Description: "Send a problem report to the bot's maintainer. It includes your message, the names of your last few commands (not what you typed after them), and the bot's version.",
+			Usage:       []usageLine{{"<what went wrong>", "Send a report"}},
+			Examples: []usageLine{
+				{"/near Reno shows no events but /events NV does", "Report a wrong result"},
+			},
+			Sections: []helpSection{
+				{"Notes", []string{
+					"• Reports may be filed as a GitHub issue; your chat ID isn't included there",
+					"• Up to 1000 characters",
+				}},
+			},
+			Related: []string{"help"},
+			Handler: handleReport,
+		},
+		{
+			Name: "version", Summary: "Show the bot's version", Hidden: true,
+			Localized:   map[string]string{"es": "Ver la versión del bot"},
+			Icon:        "ℹ️",
+			Title:       "Bot Version",
+			Description: "Show the version, commit, and build date of the running bot. Include it when reporting a problem.",
+			Usage:       []usageLine{{"", "Show version information"}},
+			Related:     []string{"report"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return formatVersion(buildinfo.Get()), nil
+			},
+		},
+		{
+			Name: "admin", Summary: "Maintainer commands", Unlisted: true,
+			Localized:   map[string]string{"es": "Comandos de mantenimiento"},
+			Icon:        "🛠️",
+			Title:       "Admin Commands",
+			Description: "Maintainer commands. Only work in the admin chat (TELEGRAM_ADMIN_CHAT_ID).",
+			Usage: []usageLine{
+				{"course-map list", "Show course aliases"},
+				{"course-map add <course ID> <title pattern>", "Match events whose title contains the pattern to a course"},
+				{"course-map remove <title pattern>", "Go back to the fuzzy match"},
+			},
+			Examples: []usageLine{{"course-map add 12345 Bali Hai", "Use course 12345 for Bali Hai events"}},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleAdmin(ctx.chatID, ctx.parts, ctx.dryRun)
+			},
+		},
+		{
+			Name: "alias", Summary: "Create shortcuts for commands you use often", Emoji: "🔗",
+			Localized:   map[string]string{"es": "Crear atajos de comandos"},
+			Icon:        "🔗",
+			Title:       "Command Aliases",
+			Description: "Define your own short commands. An alias runs the command it stands for, with anything you type after it added on the end.",
+			Usage: []usageLine{
+				{"", "List built-in and personal aliases"},
+				{`<name> "/command args"`, "Create or change an alias"},
+				{"delete <name>", "Delete an alias"},
+			},
+			Examples: []usageLine{
+				{`ne "/near Las Vegas"`, "Then /ne or /ne 50 searches near Las Vegas"},
+				{`nv "/events NV"`, "Then /nv lists Nevada events"},
+			},
+			Sections: []helpSection{
+				{"Built-in", []string{
+					"• /e → /events",
+					"• /me → /my-events",
+				}},
+				{"Tips", []string{
+					"• Up to 20 aliases; names are lowercase letters, digits, or hyphens",
+					"• Existing command names can't be used as aliases",
+				}},
+			},
+			Related: []string{"help", "menu"},
+			Handler: func(ctx *commandContext) (string, []*event.Event) {
+				return handleAlias(ctx.prefs, ctx.chatID, ctx.parts[1:], ctx.modified), nil
+			},
+		},
+		{
+			Name: "help", Summary: "Show this help message",
+			Localized:   map[string]string{"es": "Mostrar la ayuda"},
+			Icon:        "❓",
+			Title:       "Command Help",
+			Description: "Show the list of all commands, or detailed help for a single command.",
+			Usage: []usageLine{
+				{"", "Show all commands"},
+				{"<command>", "Show detailed help for a command"},
+			},
+			Examples: []usageLine{
+				{"subscribe", "Help for /subscribe"},
+				{"my-events", "Help for /my-events"},
+			},
+			Related: []string{"menu", "start"},
+			Handler: handleHelpCommand,
+		},
+		{
+			Name: "start", Summary: "Get started", Unlisted: true,
+			Localized:   map[string]string{"es": "Comenzar"},
+			Icon:        "🚀",
+			Title:       "Get Started",
+			Description: "Welcome message and introduction to the bot. Shows the same information as /help.",
+			Usage:       []usageLine{{"", "Show welcome message"}},
+			Sections: []helpSection{
+				{"First Steps", []string{
+					"1. Use /subscribe to choose states",
+					"2. Browse events with /events",
+					"3. Mark events you're interested in",
+					"4. Get notifications when new events post",
+				}},
+			},
+			Related: []string{"help", "subscribe", "menu"},
+			Handler: handleHelpCommand,
+		},
+	}
+
+	commandsByName = make(map[string]*botCommand, len(commandRegistry))
+	for i := range commandRegistry {
+		commandsByName[commandRegistry[i].Name] = &commandRegistry[i]
+	}
+}
+
+// handleHelpCommand shows the command list, or detailed help when a command is given
+func handleHelpCommand(ctx *commandContext) (string, []*event.Event) {
+	if len(ctx.parts) >= 2 {
+		cmdName := strings.TrimPrefix(normalizeCommand(ctx.parts[1]), "/")
+		if target, ok := builtinAliases[cmdName]; ok {
+			cmdName = target
+		}
+		return getCommandHelp(cmdName), nil
+	}
+	return getHelpMessage(), nil
+}
+
+// handleSearchCommand validates the search keyword and runs the search
+func handleSearchCommand(ctx *commandContext) (string, []*event.Event) {
+	if len(ctx.parts) < 2 {
+		return `🔍 <b>Event Search</b>
+
+Please provide a search keyword.
+
+<b>Usage:</b> /search &lt;keyword&gt;
+
+<b>Examples:</b>
+/search "Pine Valley"
+/search Championship
+/search Las Vegas
+/search NV`, nil
+	}
+	keyword := strings.Join(ctx.parts[1:], " ")
+	keyword = strings.Trim(keyword, `"'`) // Remove quotes if present
+
+	// Validate input
+	keyword, errMsg := validateUserInput(keyword, 100, "Search keyword")
+	if errMsg != "" {
+		return errMsg, nil
+	}
+
+	return handleSearch(ctx.prefs, ctx.chatID, keyword, ctx.botToken, ctx.dryRun, ctx.modified)
+}
+
+// handleNearCommand validates the city name and finds nearby events
+func handleNearCommand(ctx *commandContext) (string, []*event.Event) {
+	if len(ctx.parts) < 2 {
+		return "❌ Please specify a city name.\n\nUsage: /near &lt;city&gt;\n\nExamples:\n/near Las Vegas\n/near \"San Diego\"", nil
+	}
+	// Join remaining parts as city name (supports multi-word cities)
+	cityName := strings.Join(ctx.parts[1:], " ")
+	cityName = strings.Trim(cityName, `"'`) // Remove quotes if present
+
+	// Validate input
+	cityName, errMsg := validateUserInput(cityName, 100, "City name")
+	if errMsg != "" {
+		return errMsg, nil
+	}
+
+	return handleNear(ctx.prefs, ctx.chatID, cityName, ctx.botToken, ctx.dryRun, ctx.modified)
+}
+
+// handleNoteCommand adds, updates, or clears a note on an event
+func handleNoteCommand(ctx *commandContext) (string, []*event.Event) {
+	if len(ctx.parts) < 2 {
+		return "❌ Please specify an event ID.\n\nUsage: /note &lt;event_id&gt; &lt;note_text&gt;\nUsage: /note &lt;event_id&gt; clear", nil
+	}
+	eventIDs, unknown := resolveEventIDs(ctx.parts[1:2])
+	if len(unknown) > 0 {
+		return formatUnknownCodes(unknown), nil
+	}
+	eventID := eventIDs[0]
+
+	// Just an event ID shows the note and re-sends its attachment
+	if len(ctx.parts) == 2 {
+		return handleShowNote(ctx.prefs, ctx.chatID, eventID, ctx.modified, ctx.botToken, ctx.dryRun), nil
+	}
+
+	switch strings.ToLower(ctx.parts[2]) {
+	case "clear":
+		return handleRemoveNote(ctx.prefs, ctx.chatID, eventID, ctx.modified)
+	case "detach":
+		return handleDetachNote(ctx.prefs, ctx.chatID, eventID, ctx.modified), nil
+	}
+
+	// Join remaining parts as note text
+	noteText := strings.Join(ctx.parts[2:], " ")
+
+	// Validate input
+	noteText, errMsg := validateUserInput(noteText, 500, "Note text")
+	if errMsg != "" {
+		return errMsg, nil
+	}
+
+	return handleAddNote(ctx.prefs, ctx.chatID, eventID, noteText, ctx.modified)
+}
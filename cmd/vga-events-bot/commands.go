@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// commandHandler runs a command and returns (responseText, events) like processCommand
+type commandHandler func(ctx *commandContext) (string, []*event.Event)
+
+// commandContext carries everything a command handler needs
+type commandContext struct {
+	prefs    preferences.Preferences
+	chatID   string
+	parts    []string // Command text split on whitespace; parts[0] is the command
+	modified *bool
+	botToken string
+	dryRun   bool
+}
+
+// arg returns the i-th word of the command text, or "" if missing
+func (ctx *commandContext) arg(i int) string {
+	if i < len(ctx.parts) {
+		return ctx.parts[i]
+	}
+	return ""
+}
+
+// usageLine is one "/command args - text" line in detailed help
+type usageLine struct {
+	Args string // Arguments after the command (plain text, escaped when rendered)
+	Text string // Explanation; omitted from the line when empty
+}
+
+// helpSection is an extra titled block in detailed help (Tips, Options, ...)
+type helpSection struct {
+	Title string
+	Lines []string // HTML lines
+}
+
+// botCommand describes a command: its handler, /help content, and Telegram menu entry
+type botCommand struct {
+	Name      string            // Command name without the leading slash
+	Summary   string            // One-line description (English)
+	Emoji     string            // Shown after the summary in /help
+	Localized map[string]string // Summary translations keyed by Telegram language code
+	Hidden    bool              // Works for everyone but is left out of the global command menu
+	Unlisted  bool              // Left out of the /help listing and every command menu
+	Cost      commandCost       // Cooldown tier; costFree commands only count toward the global limit
+	Flag      *flags.Flag       // Feature flag the command ships behind; nil if it's always available
+
+	// Detailed help for /help <command>
+	Icon        string
+	Title       string
+	Description string
+	Usage       []usageLine
+	Examples    []usageLine
+	Sections    []helpSection
+	Related     []string // Names of related commands
+
+	Handler commandHandler
+}
+
+// commandsByName indexes commandRegistry by command name
+var commandsByName map[string]*botCommand
+
+// commandLanguages are the language codes registered in addition to the default list
+var commandLanguages = []string{"es"}
+
+// telegramCommandName converts a command name to Telegram's allowed form
+// (lowercase letters, digits, and underscores only)
+func telegramCommandName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// normalizeCommand maps a command as typed or picked from the menu to its registry form:
+// lowercase, without an @botname suffix, and with menu underscores turned back into hyphens
+func normalizeCommand(command string) string {
+	command = strings.ToLower(command)
+	if at := strings.Index(command, "@"); at > 0 {
+		command = command[:at]
+	}
+	return strings.ReplaceAll(command, "_", "-")
+}
+
+// buildBotCommands returns the setMyCommands payload for a language.
+// Hidden commands are only included when includeHidden is set (per-chat scopes).
+func buildBotCommands(languageCode string, includeHidden bool) []telegram.BotCommand {
+	commands := make([]telegram.BotCommand, 0, len(commandRegistry))
+	for _, cmd := range commandRegistry {
+		if cmd.Unlisted || (cmd.Hidden && !includeHidden) {
+			continue
+		}
+
+		description := cmd.Summary
+		if localized, ok := cmd.Localized[languageCode]; ok && languageCode != "" {
+			description = localized
+		}
+		// Telegram counts the limit in characters; cutting bytes could split one
+		description = truncateRunes(description, telegram.MaxCommandDescriptionLen)
+
+		commands = append(commands, telegram.BotCommand{
+			Command:     telegramCommandName(cmd.Name),
+			Description: description,
+		})
+	}
+	return commands
+}
+
+// commandSyncTarget is one setMyCommands call (scope + language)
+type commandSyncTarget struct {
+	Scope        *telegram.BotCommandScope
+	LanguageCode string
+	Commands     []telegram.BotCommand
+}
+
+// buildCommandSyncTargets returns every scope/language combination to register.
+// Global scopes get the public list; each chat in chatIDs also gets hidden commands.
+func buildCommandSyncTargets(chatIDs []string) []commandSyncTarget {
+	languages := append([]string{""}, commandLanguages...)
+	var targets []commandSyncTarget
+
+	for _, lang := range languages {
+		targets = append(targets, commandSyncTarget{
+			Scope:        &telegram.BotCommandScope{Type: "default"},
+			LanguageCode: lang,
+			Commands:     buildBotCommands(lang, false),
+		})
+	}
+
+	for _, chatID := range chatIDs {
+		for _, lang := range languages {
+			targets = append(targets, commandSyncTarget{
+				Scope:        &telegram.BotCommandScope{Type: "chat", ChatID: chatID},
+				LanguageCode: lang,
+				Commands:     buildBotCommands(lang, true),
+			})
+		}
+	}
+
+	return targets
+}
+
+// commandsVersion returns a short hash of the command registry payload.
+// It changes whenever a command name or description changes.
+func commandsVersion() string {
+	payload := map[string][]telegram.BotCommand{}
+	for _, lang := range append([]string{""}, commandLanguages...) {
+		payload["public:"+lang] = buildBotCommands(lang, false)
+		payload["full:"+lang] = buildBotCommands(lang, true)
+	}
+
+	data, _ := json.Marshal(payload) // #nosec G104 - marshaling plain structs cannot fail
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// describeScope returns a readable label for a sync target
+func describeScope(target commandSyncTarget) string {
+	label := target.Scope.Type
+	if target.Scope.ChatID != "" {
+		label = fmt.Sprintf("chat %s", target.Scope.ChatID)
+	}
+	if target.LanguageCode != "" {
+		label += " [" + target.LanguageCode + "]"
+	}
+	return label
+}
+
+// syncCommands registers the command registry with Telegram via setMyCommands.
+// Scopes whose current list already matches are skipped, so repeated runs are cheap.
+func syncCommands(ctx context.Context, botToken string, chatIDs []string, dryRun bool) error {
+	targets := buildCommandSyncTargets(chatIDs)
+	fmt.Printf("Command list version %s (%d scope(s))\n", commandsVersion(), len(targets))
+
+	if dryRun {
+		for _, target := range targets {
+			fmt.Printf("[DRY RUN] Would register %d command(s) for %s\n", len(target.Commands), describeScope(target))
+		}
+		return nil
+	}
+
+	client, err := telegram.NewBotClient(botToken)
+	if err != nil {
+		return fmt.Errorf("creating client: %w", err)
+	}
+
+	updated := 0
+	for _, target := range targets {
+		current, err := client.GetMyCommands(ctx, target.Scope, target.LanguageCode)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not read commands for %s: %v\n", describeScope(target), err)
+		} else if reflect.DeepEqual(current, target.Commands) {
+			fmt.Printf("Up to date: %s\n", describeScope(target))
+			continue
+		}
+
+		if err := client.SetMyCommands(ctx, target.Commands, target.Scope, target.LanguageCode); err != nil {
+			return fmt.Errorf("setting commands for %s: %w", describeScope(target), err)
+		}
+		fmt.Printf("Registered %d command(s) for %s\n", len(target.Commands), describeScope(target))
+		updated++
+	}
+
+	fmt.Printf("Command sync complete: %d updated, %d unchanged\n", updated, len(targets)-updated)
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+func TestCommandRegistryValid(t *testing.T) {
+	validName := regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+	seen := make(map[string]bool)
+
+	for _, cmd := range commandRegistry {
+		name := telegramCommandName(cmd.Name)
+		if !validName.MatchString(name) {
+			t.Errorf("Command %q is not a valid Telegram command name", name)
+		}
+		if seen[name] {
+			t.Errorf("Duplicate command %q in registry", name)
+		}
+		seen[name] = true
+
+		if cmd.Summary == "" {
+			t.Errorf("Command %q has no summary", cmd.Name)
+		}
+		for _, lang := range commandLanguages {
+			if cmd.Localized[lang] == "" {
+				t.Errorf("Command %q missing %q description", cmd.Name, lang)
+			}
+		}
+	}
+
+	if len(commandRegistry) > telegram.MaxBotCommands {
+		t.Errorf("Registry has %d commands, Telegram allows %d", len(commandRegistry), telegram.MaxBotCommands)
+	}
+}
+
+func TestNormalizeCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/events", "/events"},
+		{"/My-Events", "/my-events"},
+		{"/my_events", "/my-events"},
+		{"/export_calendar@VGAEventsBot", "/export-calendar"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeCommand(tt.input); got != tt.want {
+			t.Errorf("normalizeCommand(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestBuildBotCommands(t *testing.T) {
+	public := buildBotCommands("", false)
+	full := buildBotCommands("", true)
+
+	listed := 0
+	for _, cmd := range commandRegistry {
+		if !cmd.Unlisted {
+			listed++
+		}
+	}
+	if len(full) != listed {
+		t.Errorf("Full list has %d commands, want %d", len(full), listed)
+	}
+	if len(public) >= len(full) {
+		t.Errorf("Public list (%d) should omit hidden commands (full: %d)", len(public), len(full))
+	}
+	for _, cmd := range public {
+		if cmd.Command == "check" {
+			t.Error("Public list should not include hidden /check command")
+		}
+	}
+
+	spanish := buildBotCommands("es", false)
+	if spanish[0].Description == public[0].Description {
+		t.Errorf("Spanish description should be localized, got %q", spanish[0].Description)
+	}
+}
+
+func TestBuildBotCommandsTruncatesByCharacter(t *testing.T) {
+	original := commandRegistry
+	t.Cleanup(func() { commandRegistry = original })
+	commandRegistry = []botCommand{{
+		Name:      "events",
+		Summary:   "Show events",
+		Localized: map[string]string{"ja": strings.Repeat("イベントを表示", 50)},
+	}}
+
+	description := buildBotCommands("ja", false)[0].Description
+	if !utf8.ValidString(description) || utf8.RuneCountInString(description) != telegram.MaxCommandDescriptionLen {
+		t.Errorf("description has %d characters (valid UTF-8: %v), want %d", utf8.RuneCountInString(description), utf8.ValidString(description), telegram.MaxCommandDescriptionLen)
+	}
+}
+
+func TestBuildCommandSyncTargets(t *testing.T) {
+	languages := len(commandLanguages) + 1
+
+	targets := buildCommandSyncTargets(nil)
+	if len(targets) != languages {
+		t.Errorf("Global targets = %d, want %d", len(targets), languages)
+	}
+
+	targets = buildCommandSyncTargets([]string{"111", "222"})
+	if len(targets) != languages*3 {
+		t.Errorf("Targets with chats = %d, want %d", len(targets), languages*3)
+	}
+	last := targets[len(targets)-1]
+	if last.Scope.Type != "chat" || last.Scope.ChatID != "222" {
+		t.Errorf("Last target scope = %+v, want chat 222", last.Scope)
+	}
+}
+
+func TestCommandsVersionStable(t *testing.T) {
+	v1 := commandsVersion()
+	v2 := commandsVersion()
+	if v1 != v2 || len(v1) != 12 {
+		t.Errorf("commandsVersion() = %q, %q; want stable 12-char hash", v1, v2)
+	}
+}
+
+func TestHelpListsRegistryCommands(t *testing.T) {
+	help := getHelpMessage()
+	for _, cmd := range commandRegistry {
+		if cmd.Unlisted {
+			continue
+		}
+		if !strings.Contains(help, "/"+cmd.Name+" - ") {
+			t.Errorf("getHelpMessage() missing registry command /%s", cmd.Name)
+		}
+	}
+}
+
+func TestCommandRegistryComplete(t *testing.T) {
+	for _, cmd := range commandRegistry {
+		if cmd.Handler == nil {
+			t.Errorf("Command %q has no handler", cmd.Name)
+		}
+		if cmd.Title == "" || cmd.Description == "" || len(cmd.Usage) == 0 {
+			t.Errorf("Command %q is missing detailed help (title, description, usage)", cmd.Name)
+		}
+		for _, related := range cmd.Related {
+			if _, ok := commandsByName[related]; !ok {
+				t.Errorf("Command %q lists unknown related command %q", cmd.Name, related)
+			}
+		}
+	}
+}
+
+func TestGetCommandHelpFromRegistry(t *testing.T) {
+	help := getCommandHelp("note")
+
+	wantLines := []string{
+		"📝 <b>/note - Add Notes to Events</b>",
+		"/note &lt;event_id&gt; &lt;text&gt; - Add or update a note",
+		"/note abc123 Bringing guest clubs\n",
+		"<b>Tips:</b>",
+		"/notes - " + commandsByName["notes"].Summary,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(help, want) {
+			t.Errorf("getCommandHelp(\"note\") missing %q, got:\n%s", want, help)
+		}
+	}
+}
+
+func TestProcessCommandUnknown(t *testing.T) {
+	modified := false
+	for _, text := range []string{"/nonexistent", "/@bot"} {
+		response, _ := processCommand(nil, "12345", text, &modified, "", true)
+		if !strings.Contains(response, "Unknown command") {
+			t.Errorf("processCommand(%q) = %q, want unknown command response", text, response)
+		}
+	}
+}
+
+func TestProcessCommandHelpAliases(t *testing.T) {
+	modified := false
+	for _, text := range []string{"/help my_events", "/help /my-events", "/HELP@VGAEventsBot my-events"} {
+		response, _ := processCommand(nil, "12345", text, &modified, "", true)
+		if !strings.Contains(response, "/my-events - View Your Tracked Events") {
+			t.Errorf("processCommand(%q) did not return /my-events help, got:\n%s", text, response)
+		}
+	}
+}
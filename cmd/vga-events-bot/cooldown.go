@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// commandCost is a command's cooldown tier. Every command counts toward the global
+// per-user limit; commands that scrape or generate files are also limited per command.
+type commandCost int
+
+const (
+	costFree   commandCost = iota // Only the global limit applies
+	costFetch                     // Fetches the event list from the VGA site
+	costHeavy                     // Full scrape plus file generation or a notification run
+	costReport                    // Messages the maintainer (/report)
+)
+
+// cooldownTier is how many times a user may run one command of a cost within a window
+type cooldownTier struct {
+	limit  int
+	window time.Duration
+}
+
+// cooldownTiers maps each limited cost to its tier
+var cooldownTiers = map[commandCost]cooldownTier{
+	costFetch:  {limit: 2, window: time.Minute},
+	costHeavy:  {limit: 1, window: 5 * time.Minute},
+	costReport: {limit: 2, window: 10 * time.Minute},
+}
+
+// commandCooldowns enforces per-command cooldowns. Exempt chats (the admin chat) are
+// never limited.
+type commandCooldowns struct {
+	limiters map[commandCost]*RateLimiter
+	exempt   map[string]bool
+}
+
+// newCommandCooldowns creates cooldowns for every tier in cooldownTiers
+func newCommandCooldowns(exemptChats ...string) *commandCooldowns {
+	c := &commandCooldowns{
+		limiters: make(map[commandCost]*RateLimiter, len(cooldownTiers)),
+		exempt:   make(map[string]bool),
+	}
+	for cost, tier := range cooldownTiers {
+		c.limiters[cost] = NewRateLimiter(tier.limit, tier.window)
+	}
+	for _, chatID := range exemptChats {
+		if chatID != "" {
+			c.exempt[chatID] = true
+		}
+	}
+	return c
+}
+
+// cooldowns is set in main; commands aren't limited per command when it's nil
+var cooldowns *commandCooldowns
+
+// check records a use of command by chatID and returns 0 if it's allowed, or how long
+// until it will be
+func (c *commandCooldowns) check(chatID, command string, cost commandCost) time.Duration {
+	if c == nil || c.exempt[chatID] {
+		return 0
+	}
+	limiter, ok := c.limiters[cost]
+	if !ok {
+		return 0
+	}
+	return limiter.Check(chatID + ":" + command)
+}
+
+// cleanup drops expired entries from every tier
+func (c *commandCooldowns) cleanup() {
+	if c == nil {
+		return
+	}
+	for _, limiter := range c.limiters {
+		limiter.CleanupOldEntries()
+	}
+}
+
+// cooldownMessage tells the user when they can run a command again
+func cooldownMessage(command string, cost commandCost, wait time.Duration) string {
+	tier := cooldownTiers[cost]
+	return fmt.Sprintf("⏳ <b>/%s is limited to %s.</b>\n\nPlease try again in %s.",
+		command, formatCooldownLimit(tier), formatWait(wait))
+}
+
+// formatCooldownLimit describes a tier, e.g. "2 uses per minute" or "1 use per 5 minutes"
+func formatCooldownLimit(tier cooldownTier) string {
+	uses := "uses"
+	if tier.limit == 1 {
+		uses = "use"
+	}
+	window := "minute"
+	if minutes := int(tier.window / time.Minute); minutes > 1 {
+		window = fmt.Sprintf("%d minutes", minutes)
+	}
+	return fmt.Sprintf("%d %s per %s", tier.limit, uses, window)
+}
+
+// formatWait formats a wait rounded up to the second, e.g. "45s" or "4m 10s"
+func formatWait(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs < 60 {
+		return fmt.Sprintf("%ds", max(secs, 1))
+	}
+	if secs%60 == 0 {
+		return fmt.Sprintf("%dm", secs/60)
+	}
+	return fmt.Sprintf("%dm %ds", secs/60, secs%60)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestCommandCooldowns(t *testing.T) {
+	c := newCommandCooldowns("admin")
+
+	// Free commands are never limited per command
+	for i := 0; i < 5; i++ {
+		if wait := c.check("1", "list", costFree); wait != 0 {
+			t.Fatalf("free command limited after %d uses", i)
+		}
+	}
+
+	// Heavy commands allow one use per window, per command
+	if wait := c.check("1", "export-calendar", costHeavy); wait != 0 {
+		t.Error("first heavy command should be allowed")
+	}
+	wait := c.check("1", "export-calendar", costHeavy)
+	if wait <= 4*time.Minute || wait > 5*time.Minute {
+		t.Errorf("second heavy command wait = %v, want just under 5m", wait)
+	}
+	if wait := c.check("1", "check", costHeavy); wait != 0 {
+		t.Error("cooldowns should be tracked per command")
+	}
+	if wait := c.check("2", "export-calendar", costHeavy); wait != 0 {
+		t.Error("cooldowns should be tracked per user")
+	}
+
+	// The admin chat is exempt
+	for i := 0; i < 3; i++ {
+		if wait := c.check("admin", "check", costHeavy); wait != 0 {
+			t.Fatal("admin chat should never be limited")
+		}
+	}
+
+	// No cooldowns configured
+	var none *commandCooldowns
+	if wait := none.check("1", "check", costHeavy); wait != 0 {
+		t.Error("nil cooldowns should allow everything")
+	}
+}
+
+func TestCooldownMessage(t *testing.T) {
+	msg := cooldownMessage("export-calendar", costHeavy, 4*time.Minute+9500*time.Millisecond)
+	for _, want := range []string{"/export-calendar", "1 use per 5 minutes", "try again in 4m 10s"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message should contain %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestFormatWait(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want string
+	}{
+		{200 * time.Millisecond, "1s"},
+		{45 * time.Second, "45s"},
+		{2 * time.Minute, "2m"},
+		{150 * time.Second, "2m 30s"},
+	}
+	for _, tt := range tests {
+		if got := formatWait(tt.wait); got != tt.want {
+			t.Errorf("formatWait(%v) = %q, want %q", tt.wait, got, tt.want)
+		}
+	}
+}
+
+func TestProcessCommandCooldown(t *testing.T) {
+	original := cooldowns
+	cooldowns = newCommandCooldowns()
+	defer func() { cooldowns = original }()
+
+	// Use up the tier directly so the handler itself isn't run
+	cooldowns.check("42", "check", costHeavy)
+
+	modified := false
+	response, _ := processCommand(preferences.NewPreferences(), "42", "/check", &modified, "", true)
+	if !strings.Contains(response, "try again in") {
+		t.Errorf("response = %q, want cooldown message", response)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/eventquery"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// sendDigests sends the queued digests selected by which (see digestSelected) and
+// saves the cleared queues
+func sendDigests(ctx context.Context, prefs preferences.Preferences, gist *preferences.GistStorage, botToken, which string, dryRun bool) {
+	sent, modified := drainDigests(ctx, prefs, botToken, which, dryRun)
+	if modified {
+		if err := savePreferences(ctx, gist, prefs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+			errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("✅ Sent %d digest(s)\n", sent)
+}
+
+// digestSelected reports whether a digest run for which includes the user: "daily" or
+// "weekly" picks everyone on that frequency, "due" those whose digest day and hour, in
+// their time zone, is now
+func digestSelected(user *preferences.UserPreferences, which string, now time.Time) bool {
+	if which == "due" {
+		return user.DigestDue(now)
+	}
+	return user.DigestFrequency == which
+}
+
+// drainDigests sends each selected user their queued events, filtered by their
+// subscriptions and time filters, and clears the queues it sent. Paused users keep
+// their queue for the catch-up digest. Reports how many digests were sent and
+// whether any queue changed.
+func drainDigests(ctx context.Context, prefs preferences.Preferences, botToken, which string, dryRun bool) (int, bool) {
+	now := clk.Now()
+
+	// A retried run skips users who already got this period's digest
+	var ledger *storage.DigestLedger
+	if snapshotStore != nil {
+		var err error
+		if ledger, err = snapshotStore.LoadDigests(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading digest ledger: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	sent := 0
+	modified := false
+	for _, chatID := range prefs.GetAllUsers() {
+		user := prefs.GetUser(chatID)
+		if !user.Active || user.IsPaused(now) || !digestSelected(user, which, now) || len(user.PendingEvents) == 0 {
+			continue
+		}
+		digestType := user.DigestFrequency
+		period := digestPeriod(digestType, now)
+		if ledger != nil && !*forceDigest {
+			if prev := ledger.Sent(errreport.HashChatID(chatID), period); prev != nil {
+				// The earlier run sent this queue but may not have saved it cleared
+				fmt.Printf("Already sent the %s digest to %s at %s; skipping (use --force to resend)\n", period, chatID, prev.At)
+				if !dryRun {
+					user.ClearPendingEvents()
+					modified = true
+				}
+				continue
+			}
+		}
+
+		// Subscriptions and time filters can change between queueing and sending
+		events := eventquery.ForUser(user, now).Subscriptions().HidePast().Horizon().Sort().Run(user.PendingEvents)
+		if len(events) == 0 {
+			// Everything queued has passed or no longer matches
+			if !dryRun {
+				user.ClearPendingEvents()
+				modified = true
+			}
+			continue
+		}
+
+		msg := telegram.FormatDigest(events, digestType)
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would send %s digest to %s:\n%s\n\n", digestType, chatID, msg)
+			continue
+		}
+
+		client, err := newSender(botToken, chatID)
+		if err == nil {
+			err = client.SendMessage(ctx, msg)
+		}
+		recordDigestDelivery(chatID, err)
+		if err != nil {
+			// The queue is kept for the next run
+			fmt.Fprintf(os.Stderr, "Error sending digest to %s: %v\n", chatID, err)
+			continue
+		}
+
+		if ledger != nil {
+			entry := &storage.DigestEntry{
+				At:     now.UTC().Format(time.RFC3339),
+				User:   errreport.HashChatID(chatID),
+				Period: period,
+				Events: len(events),
+				RunID:  os.Getenv("GITHUB_RUN_ID"),
+			}
+			if err := ledger.Record(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: recording digest: %v\n", err)
+			}
+		}
+		user.ClearPendingEvents()
+		modified = true
+		sent++
+		fmt.Printf("Sent %s digest with %d event(s) to %s\n", digestType, len(events), chatID)
+	}
+	return sent, modified
+}
+
+const digestTimeUsage = `Usage: /settings time [day] <hour> [time zone]
+
+Examples:
+/settings time 7am
+/settings time sat 8am America/Los_Angeles
+/settings time 18 Europe/London
+
+The day is for weekly digests. Time zones are names like America/New_York.`
+
+// formatDigestTime describes when the user's digest is sent, e.g. "weekly digest on
+// Saturday at 08:00 America/Los_Angeles"
+func formatDigestTime(user *preferences.UserPreferences) string {
+	tz := user.DigestLocation().String()
+	if user.DigestFrequency == preferences.DigestFrequencyWeekly {
+		return fmt.Sprintf("weekly digest on %s at %02d:00 %s", time.Weekday(user.DigestDayOfWeek), user.DigestHour, tz)
+	}
+	return fmt.Sprintf("daily digest at %02d:00 %s", user.DigestHour, tz)
+}
+
+// digestSchedule describes the digest time for either frequency, e.g. "at 09:00 UTC
+// (weekly digests on Monday)"
+func digestSchedule(user *preferences.UserPreferences) string {
+	return fmt.Sprintf("at %02d:00 %s (weekly digests on %s)", user.DigestHour, user.DigestLocation(), time.Weekday(user.DigestDayOfWeek))
+}
+
+// handleDigestTime sets the digest day, hour, and time zone. args are the words after
+// "time", in any order; what's left out keeps its current value.
+func handleDigestTime(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return digestTimeUsage
+	}
+
+	user := prefs.GetUser(chatID)
+	day, hour, tz := time.Weekday(user.DigestDayOfWeek), user.DigestHour, ""
+	for _, opt := range args {
+		if weekday, ok := weekdayNames[strings.ToLower(opt)]; ok {
+			day = weekday
+		} else if h, ok := parseReportHour(opt); ok {
+			hour = h
+		} else if tz == "" {
+			tz = opt
+		} else {
+			return fmt.Sprintf("❌ Couldn't understand %q.\n\n%s", html.EscapeString(opt), digestTimeUsage)
+		}
+	}
+
+	if err := user.SetDigestTime(day, hour, tz); err != nil {
+		if tz != "" {
+			return fmt.Sprintf("❌ Unknown time zone %q. Use a name like America/Chicago.\n\n%s", html.EscapeString(tz), digestTimeUsage)
+		}
+		return "❌ Couldn't set the digest time.\n\n" + digestTimeUsage
+	}
+	*modified = true
+
+	if user.DigestFrequency == preferences.DigestFrequencyImmediate {
+		return "✅ Digest time saved.\n\nYou get events immediately now; choose a daily or weekly digest in /settings to use it."
+	}
+	return fmt.Sprintf("✅ You'll get a %s.", html.EscapeString(formatDigestTime(user)))
+}
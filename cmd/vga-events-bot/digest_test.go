@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/testutil"
+)
+
+func TestDigestPeriod(t *testing.T) {
+	tests := []struct {
+		digestType string
+		now        time.Time
+		want       string
+	}{
+		{"daily", time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), "daily:2026-10-15"},
+		{"daily", time.Date(2026, 10, 15, 23, 30, 0, 0, time.FixedZone("PDT", -7*3600)), "daily:2026-10-16"},
+		{"weekly", time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), "weekly:2026-W42"},
+		{"weekly", time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC), "weekly:2026-W42"},
+		{"weekly", time.Date(2027, 1, 1, 9, 0, 0, 0, time.UTC), "weekly:2026-W53"},
+	}
+	for _, tt := range tests {
+		if got := digestPeriod(tt.digestType, tt.now); got != tt.want {
+			t.Errorf("digestPeriod(%q, %v) = %q, want %q", tt.digestType, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestSendDigestOncePerPeriod(t *testing.T) {
+	calls := recordSends(t)
+	fake := testutil.NewFakeClock(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	oldClk, oldStore, oldForce := clk, snapshotStore, *forceDigest
+	t.Cleanup(func() { clk, snapshotStore, *forceDigest = oldClk, oldStore, oldForce })
+	clk = fake
+
+	dir := t.TempDir()
+	store, err := storage.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotStore = store
+
+	digestFile := filepath.Join(dir, "digest_123.json")
+	if err := os.WriteFile(digestFile, []byte(`{"new_events": [{"id": "e1", "state": "NV", "title": "Reno Open", "date": "Nov 1"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func() {
+		sendDigest(context.Background(), "token", "123", digestFile, "daily")
+	}
+
+	send()
+	send() // A retried run
+	if len(*calls) != 1 {
+		t.Fatalf("sent %d digests, want 1 (the retry should be skipped)", len(*calls))
+	}
+
+	*forceDigest = true
+	send()
+	if len(*calls) != 2 {
+		t.Fatalf("sent %d digests, want 2 (--force resends)", len(*calls))
+	}
+	*forceDigest = false
+
+	fake.Advance(24 * time.Hour)
+	send()
+	if len(*calls) != 3 {
+		t.Errorf("sent %d digests, want 3 (the next day is a new period)", len(*calls))
+	}
+}
+
+func TestDrainDigests(t *testing.T) {
+	calls := recordSends(t)
+	fake := testutil.NewFakeClock(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	oldClk, oldStore := clk, snapshotStore
+	t.Cleanup(func() { clk, snapshotStore = oldClk, oldStore })
+	clk = fake
+
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotStore = store
+
+	prefs := preferences.NewPreferences()
+	queue := func(chatID, frequency string, events ...*event.Event) *preferences.UserPreferences {
+		user := prefs.GetUser(chatID)
+		user.States = []string{"NV"}
+		user.DigestFrequency = frequency
+		user.QueueDigest(events, fake.Now())
+		return user
+	}
+	daily := queue("1", "daily", &event.Event{ID: "e1", State: "NV", Title: "Reno Open", DateText: "Nov 01 2026"})
+	stale := queue("2", "daily", &event.Event{ID: "e2", State: "NV", Title: "Old Open", DateText: "Oct 01 2026"})
+	paused := queue("3", "daily", &event.Event{ID: "e3", State: "NV", Title: "Vegas Open", DateText: "Nov 02 2026"})
+	paused.Pause(fake.Now().AddDate(0, 0, 7))
+	weekly := queue("4", "weekly", &event.Event{ID: "e4", State: "NV", Title: "Tahoe Open", DateText: "Nov 03 2026"})
+
+	sent, modified := drainDigests(context.Background(), prefs, "token", "daily", false)
+	if sent != 1 || !modified {
+		t.Fatalf("drainDigests() = %d, %v; want 1 sent and modified", sent, modified)
+	}
+	if len(*calls) != 1 || (*calls)[0].ChatID != "1" || !strings.Contains((*calls)[0].Text, "Reno Open") {
+		t.Fatalf("sends = %+v, want one digest to 1 with Reno Open", *calls)
+	}
+	if len(daily.PendingEvents) != 0 {
+		t.Error("sent digest should clear the queue")
+	}
+	if len(stale.PendingEvents) != 0 {
+		t.Error("a queue of only past events should be cleared without a digest")
+	}
+	if len(paused.PendingEvents) != 1 || len(weekly.PendingEvents) != 1 {
+		t.Error("paused and weekly users should keep their queues")
+	}
+
+	// A retried run in the same period doesn't resend a queue that wasn't saved cleared
+	daily.QueueDigest([]*event.Event{{ID: "e1", State: "NV", Title: "Reno Open", DateText: "Nov 01 2026"}}, fake.Now())
+	if sent, modified := drainDigests(context.Background(), prefs, "token", "daily", false); sent != 0 || !modified || len(*calls) != 1 {
+		t.Errorf("retried drainDigests() = %d, %v; want 0 sent and the queue cleared", sent, modified)
+	}
+	if len(daily.PendingEvents) != 0 {
+		t.Error("retried run should clear the already-sent queue")
+	}
+
+	fake.Advance(24 * time.Hour)
+	daily.QueueDigest([]*event.Event{{ID: "e5", State: "NV", Title: "Elko Open", DateText: "Nov 04 2026"}}, fake.Now())
+	if sent, _ := drainDigests(context.Background(), prefs, "token", "daily", false); sent != 1 {
+		t.Errorf("next day's drainDigests() sent %d, want 1", sent)
+	}
+}
+
+func TestDrainDigestsDue(t *testing.T) {
+	calls := recordSends(t)
+	fake := testutil.NewFakeClock(time.Date(2026, 10, 17, 14, 5, 0, 0, time.UTC)) // Saturday 7:05 AM PDT
+	oldClk, oldStore := clk, snapshotStore
+	t.Cleanup(func() { clk, snapshotStore = oldClk, oldStore })
+	clk, snapshotStore = fake, nil
+
+	prefs := preferences.NewPreferences()
+	queue := func(chatID, frequency string, day time.Weekday, hour int, tz string) {
+		user := prefs.GetUser(chatID)
+		user.States = []string{"NV"}
+		user.DigestFrequency = frequency
+		if err := user.SetDigestTime(day, hour, tz); err != nil {
+			t.Fatal(err)
+		}
+		user.QueueDigest([]*event.Event{{ID: "e" + chatID, State: "NV", Title: "Reno Open", DateText: "Nov 01 2026"}}, fake.Now())
+	}
+	queue("1", "weekly", time.Saturday, 7, "America/Los_Angeles")
+	queue("2", "daily", time.Monday, 7, "America/Los_Angeles")
+	queue("3", "weekly", time.Monday, 7, "America/Los_Angeles") // Wrong day
+	queue("4", "daily", time.Monday, 9, "UTC")                  // Wrong hour
+
+	sent, _ := drainDigests(context.Background(), prefs, "token", "due", false)
+	if sent != 2 {
+		t.Fatalf("drainDigests(due) sent %d, want 2", sent)
+	}
+	for _, call := range *calls {
+		if call.ChatID != "1" && call.ChatID != "2" {
+			t.Errorf("sent a digest to %s, whose digest isn't due", call.ChatID)
+		}
+	}
+}
+
+func TestHandleDigestTime(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	user.DigestFrequency = preferences.DigestFrequencyWeekly
+	modified := false
+
+	resp := handleDigestTime(prefs, "123", []string{"sat", "8am", "America/Chicago"}, &modified)
+	if !modified || !strings.Contains(resp, "weekly digest on Saturday at 08:00 America/Chicago") {
+		t.Errorf("handleDigestTime() = %q, modified %v", resp, modified)
+	}
+
+	// Leaving out the day and time zone keeps them
+	resp = handleDigestTime(prefs, "123", []string{"18"}, &modified)
+	if !strings.Contains(resp, "Saturday at 18:00 America/Chicago") {
+		t.Errorf("handleDigestTime(18) = %q", resp)
+	}
+
+	modified = false
+	resp = handleDigestTime(prefs, "123", []string{"7am", "Nowhere/Special"}, &modified)
+	if modified || !strings.Contains(resp, "Unknown time zone") {
+		t.Errorf("handleDigestTime() with a bad time zone = %q, modified %v", resp, modified)
+	}
+	if user.DigestHour != 18 {
+		t.Errorf("a failed change should keep the hour, got %d", user.DigestHour)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// threadMessagesShown is how many of the latest messages a discussion view shows
+const threadMessagesShown = 10
+
+// handleDiscuss shows an event's discussion with friends, or adds a message to it and
+// relays the message to the friends taking part.
+// Format: /discuss <event_id> [message]
+func handleDiscuss(prefs preferences.Preferences, chatID string, args []string, modified *bool, botToken string, dryRun bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify an event ID.\n\nUsage: /discuss &lt;event_id&gt; [message]"
+	}
+	eventIDs, unknown := resolveKnownEventIDs(args[:1])
+	if len(unknown) > 0 {
+		return formatUnknownCodes(unknown)
+	}
+	eventID := eventIDs[0]
+
+	if len(args) == 1 {
+		return formatThread(prefs, chatID, eventID)
+	}
+
+	user := prefs.GetUser(chatID)
+	if len(user.FriendChatIDs) == 0 {
+		return "👥 Discussions are shared with your friends, and you haven't added any yet.\n\nUse /invite to get a code for your friends."
+	}
+
+	text, errMsg := validateUserInput(strings.Join(args[1:], " "), preferences.MaxCommentLength, "Message")
+	if errMsg != "" {
+		return errMsg
+	}
+
+	participants := prefs.DiscussionParticipants(chatID, eventID)
+	user.AddComment(eventID, text, clk.Now())
+	*modified = true
+
+	relayed := relayComment(participants, chatID, eventID, text, botToken, dryRun)
+
+	var msg strings.Builder
+	switch {
+	case len(participants) == 0:
+		msg.WriteString("✅ Message added. None of your friends are tracking this event yet; they'll see it when they open the discussion.\n\n")
+	case dryRun:
+		msg.WriteString(fmt.Sprintf("[DRY RUN] Would send your message to %d friend(s).\n\n", len(participants)))
+	default:
+		msg.WriteString(fmt.Sprintf("✅ Sent to %d of %d friend(s).\n\n", relayed, len(participants)))
+	}
+	msg.WriteString(formatThread(prefs, chatID, eventID))
+	return msg.String()
+}
+
+// relayComment sends a new discussion message to each participant with a button to
+// reply, and returns how many it reached
+func relayComment(participants []string, fromChatID, eventID, text, botToken string, dryRun bool) int {
+	if dryRun {
+		return 0
+	}
+
+	label, _ := describeEvent(eventID)
+	msg := fmt.Sprintf("💬 <b>Friend <code>%s</code></b> on %s:\n\n%s", fromChatID, label, html.EscapeString(text))
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "💬 View & reply", CallbackData: "discuss:" + eventID}},
+		},
+	}
+
+	sent := 0
+	for _, friendID := range participants {
+		client, err := newSender(botToken, friendID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating client for discussion relay: %v\n", err)
+			continue
+		}
+		if err := client.SendMessageWithKeyboard(botCtx, msg, keyboard); err != nil {
+			fmt.Fprintf(os.Stderr, "Error relaying discussion message to %s: %v\n", friendID, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// formatThread shows the latest messages in an event's discussion and how to reply
+func formatThread(prefs preferences.Preferences, chatID, eventID string) string {
+	label, ref := describeEvent(eventID)
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("💬 <b>Discussion</b> — %s\n\n", label))
+
+	thread := prefs.Thread(chatID, eventID)
+	if len(thread) == 0 {
+		msg.WriteString("No messages yet. Start the conversation with your friends!\n")
+	}
+	if len(thread) > threadMessagesShown {
+		msg.WriteString(fmt.Sprintf("<i>%d earlier message(s) not shown</i>\n\n", len(thread)-threadMessagesShown))
+		thread = thread[len(thread)-threadMessagesShown:]
+	}
+	for _, c := range thread {
+		author := fmt.Sprintf("Friend <code>%s</code>", c.ChatID)
+		if c.ChatID == chatID {
+			author = "You"
+		}
+		at := time.Unix(c.At, 0).UTC().Format("Jan 2 15:04")
+		msg.WriteString(fmt.Sprintf("<b>%s</b> <i>%s UTC</i>\n%s\n\n", author, at, html.EscapeString(c.Text)))
+	}
+
+	msg.WriteString(fmt.Sprintf("\nReply with:\n<code>/discuss %s your message</code>\n\n", ref))
+	msg.WriteString("Messages go to your friends who are tracking this event or have joined the discussion.")
+	return msg.String()
+}
+
+// describeEvent returns a label naming the event for discussion messages, and the
+// reference (short code, or ID) to reply with. Titles come from the snapshot when
+// there is one.
+func describeEvent(eventID string) (label, ref string) {
+	evt := snapshotEvent(eventID)
+	if evt == nil {
+		return fmt.Sprintf("event <code>%s</code>", html.EscapeString(eventID)), eventID
+	}
+	ref = eventID
+	if evt.ShortCode != "" {
+		ref = evt.ShortCode
+	}
+	return fmt.Sprintf("<b>%s</b> (%s)", html.EscapeString(evt.Title), evt.State), ref
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestHandleDiscuss(t *testing.T) {
+	serveEvents(t, []*event.Event{{ID: "evt1", State: "NV", Title: "Reno Open"}})
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if response := handleDiscuss(prefs, "111", nil, &modified, "", true); !strings.Contains(response, "Usage") {
+		t.Errorf("no args: %q", response)
+	}
+	if response := handleDiscuss(prefs, "111", []string{"evt1", "hi"}, &modified, "", true); modified || !strings.Contains(response, "/invite") {
+		t.Errorf("without friends: %q", response)
+	}
+
+	prefs.GetUser("111").AddFriend("222")
+	prefs.GetUser("222").AddFriend("111")
+	prefs.GetUser("222").SetEventStatus("evt1", preferences.EventStatusRegistered)
+
+	response := handleDiscuss(prefs, "111", []string{"evt1", "Carpool", "<from>", "Henderson?"}, &modified, "", true)
+	if !modified || !strings.Contains(response, "Would send your message to 1 friend") {
+		t.Errorf("posting in dry run: %q", response)
+	}
+	if !strings.Contains(response, "Carpool &lt;from&gt; Henderson?") {
+		t.Errorf("the thread should show the escaped message: %q", response)
+	}
+
+	modified = false
+	if response := handleDiscuss(prefs, "111", []string{"<b>x</b>", "hi"}, &modified, "", true); modified || !strings.Contains(response, "Unknown event") || strings.Contains(response, "<b>x") {
+		t.Errorf("an ID that isn't an event should be rejected and escaped: %q", response)
+	}
+
+	thread := handleDiscuss(prefs, "222", []string{"evt1"}, &modified, "", true)
+	if !strings.Contains(thread, "Friend <code>111</code>") || !strings.Contains(thread, "/discuss evt1") {
+		t.Errorf("friend's view of the thread: %q", thread)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/pfrederiksen/vga-events/internal/experiment"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// activeExperiment is the A/B experiment button taps are counted for (nil unless --experiment is set)
+var activeExperiment *experiment.Experiment
+
+// recordExperimentTap counts a tap on a status or calendar button from a notification
+// in an experiment. Those buttons end in a variant tag ("status:ID:registered:b",
+// "calendar:ID:b"); buttons without one aren't counted.
+func recordExperimentTap(prefs preferences.Preferences, chatID, action string, parts []string, modified *bool) {
+	if activeExperiment == nil {
+		return
+	}
+
+	var tag string
+	switch {
+	case action == "status" && len(parts) == 4:
+		tag = parts[3]
+	case action == "calendar" && len(parts) == 3:
+		tag = parts[2]
+	default:
+		return
+	}
+
+	variant, ok := activeExperiment.VariantForTag(tag)
+	if !ok {
+		return
+	}
+	prefs.GetUser(chatID).RecordExperimentTap(activeExperiment.Name, variant)
+	*modified = true
+}
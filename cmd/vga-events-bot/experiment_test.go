@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/experiment"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestRecordExperimentTap(t *testing.T) {
+	activeExperiment = experiment.NewEventFormat
+	t.Cleanup(func() { activeExperiment = nil })
+
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "NV")
+	modified := false
+
+	for _, data := range []string{
+		"status:abc:registered:b",
+		"calendar:abc:b",
+		"calendar:abc:a",
+		"status:abc:registered", // Not from an experiment message
+		"status:abc:registered:z",
+		"menu:events",
+	} {
+		parts := strings.Split(data, ":")
+		recordExperimentTap(prefs, "123", parts[0], parts, &modified)
+	}
+
+	user := prefs.GetUser("123")
+	if got := user.ExperimentTaps["new-event-format/compact"]; got != 2 {
+		t.Errorf("compact taps = %d, want 2", got)
+	}
+	if got := user.ExperimentTaps["new-event-format/control"]; got != 1 {
+		t.Errorf("control taps = %d, want 1", got)
+	}
+	if !modified {
+		t.Error("recording a tap should mark preferences modified")
+	}
+
+	// Tagged status buttons still work
+	if msg := handleStatusCallback("status:abc:registered:b", prefs, "123", &modified); !strings.Contains(msg, "Registered") {
+		t.Errorf("handleStatusCallback() = %q", msg)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/export"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// handleExportAll sends the user a ZIP of their data: preferences, notes, tracked events,
+// and a calendar of the tracked events
+func handleExportAll(prefs preferences.Preferences, chatID, botToken string, dryRun bool) (string, []*event.Event) {
+	user := prefs.GetUser(chatID)
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	now := clk.Now()
+	archive, err := export.Build(user, allEvents, now)
+	if err != nil {
+		reportError(fmt.Errorf("building data export: %w", err), "")
+		return "❌ Couldn't build your export. Please try again later.", nil
+	}
+
+	tracked := len(user.TrackedEventIDs()) + len(user.ArchivedEvents)
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would send a data export with %d tracked event(s) (%d bytes)", tracked, len(archive)), nil
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return "❌ Error sending export file", nil
+	}
+	filename := fmt.Sprintf("vga-events-export-%s.zip", now.Format("2006-01-02"))
+	caption := fmt.Sprintf("📦 <b>Your VGA Events Data</b>\n\nPreferences, notes, %d tracked event(s), and a calendar file.", tracked)
+	if err := client.SendDocument(botCtx, filename, archive, caption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
+		return "❌ Error sending export file", nil
+	}
+	return "", nil // Already sent
+}
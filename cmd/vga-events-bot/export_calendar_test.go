@@ -0,0 +1,28 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExportCalendarArgs(t *testing.T) {
+	args, err := parseExportCalendarArgs(strings.Fields("nv Registered interested Apr 1-30 split"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.state != "NV" || !args.perState || !reflect.DeepEqual(args.statuses, []string{"registered", "interested"}) {
+		t.Errorf("parsed %+v", args)
+	}
+	if args.from == nil || args.from.Month() != time.April || args.from.Day() != 1 || args.to.Day() != 30 {
+		t.Errorf("date range = %v - %v, want Apr 1-30", args.from, args.to)
+	}
+
+	if args, err := parseExportCalendarArgs(strings.Fields("ALL")); err != nil || args.state != AllStatesCode || args.from != nil {
+		t.Errorf("ALL: %+v, %v", args, err)
+	}
+	if _, err := parseExportCalendarArgs(strings.Fields("NV someday")); err == nil {
+		t.Error("an unknown option should be an error")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/pdf"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// pdfSchedule returns the /export-pdf schedule: the user's tracked events (any status but
+// skip), or the upcoming events in state when one is given, with the user's statuses
+func pdfSchedule(user *preferences.UserPreferences, allEvents []*event.Event, state string, now time.Time) pdf.Schedule {
+	var events []*event.Event
+	for _, evt := range allEvents {
+		status := user.GetEventStatus(evt.ID)
+		if state != "" {
+			if strings.EqualFold(evt.State, state) && !evt.IsPastEventAt(now) && status != preferences.EventStatusSkip {
+				events = append(events, evt)
+			}
+		} else if status != "" && status != preferences.EventStatusSkip {
+			events = append(events, evt)
+		}
+	}
+	event.SortByDate(events)
+
+	subtitle := "Tracked events"
+	if state != "" {
+		subtitle = state + " events"
+	}
+	return pdf.Schedule{
+		Title:     "VGA Golf Schedule",
+		Subtitle:  fmt.Sprintf("%s (%d)", subtitle, len(events)),
+		Rows:      pdf.EventRows(events, user.EventStatuses),
+		Generated: now,
+	}
+}
+
+func handleExportPDF(prefs preferences.Preferences, chatID, state string, botToken string, dryRun bool) (string, []*event.Event) {
+	if state != "" && (state == AllStatesCode || !preferences.IsValidState(state)) {
+		return fmt.Sprintf(`❌ Invalid state code: %s
+
+<b>Usage:</b>
+/export-pdf - Your tracked events
+/export-pdf NV - Upcoming Nevada events`, state), nil
+	}
+
+	user := prefs.GetUser(chatID)
+	if state == "" && len(user.EventStatuses) == 0 {
+		return `📄 <b>No Tracked Events</b>
+
+Mark events ⭐ Interested, ✅ Registered, or 🤔 Maybe to build your schedule, or use /export-pdf &lt;STATE&gt; for a state's schedule.`, nil
+	}
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	schedule := pdfSchedule(user, allEvents, state, clk.Now())
+	if len(schedule.Rows) == 0 {
+		return "📄 <b>No Events Found</b>\n\nThere's nothing to put on your schedule right now.", nil
+	}
+	doc := pdf.Render(schedule)
+
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would send a PDF schedule with %d event(s) (%d bytes)", len(schedule.Rows), len(doc)), nil
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return "❌ Error sending PDF file", nil
+	}
+	filename := "vga-schedule.pdf"
+	if state != "" {
+		filename = fmt.Sprintf("vga-schedule-%s.pdf", state)
+	}
+	caption := fmt.Sprintf("📄 <b>Your VGA Schedule</b>\n\n%d event(s), ready to print.", len(schedule.Rows))
+	if err := client.SendDocument(botCtx, filename, doc, caption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
+		return "❌ Error sending PDF file", nil
+	}
+	return "", nil // Already sent
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestPDFSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	user := preferences.NewPreferences().GetUser("111")
+	user.SetEventStatus("reg", preferences.EventStatusRegistered)
+	user.SetEventStatus("skip", preferences.EventStatusSkip)
+
+	all := []*event.Event{
+		{ID: "reg", Title: "Wolf Creek", State: "NV", DateText: "Apr 4 2026"},
+		{ID: "open", Title: "Paiute", State: "NV", DateText: "Mar 20 2026"},
+		{ID: "skip", Title: "Shadow Creek", State: "NV", DateText: "Apr 5 2026"},
+		{ID: "past", Title: "Coyote Springs", State: "NV", DateText: "Jan 5 2026"},
+		{ID: "ca", Title: "Torrey Pines", State: "CA", DateText: "Apr 6 2026"},
+	}
+
+	tracked := pdfSchedule(user, all, "", now)
+	if len(tracked.Rows) != 1 || tracked.Rows[0].Course != "Wolf Creek" || tracked.Rows[0].Status != "Registered" {
+		t.Errorf("tracked rows = %+v", tracked.Rows)
+	}
+
+	state := pdfSchedule(user, all, "NV", now)
+	if len(state.Rows) != 2 || state.Rows[0].Course != "Paiute" || state.Rows[1].Course != "Wolf Creek" {
+		t.Errorf("state rows = %+v", state.Rows)
+	}
+	if !strings.HasPrefix(state.Subtitle, "NV events") {
+		t.Errorf("subtitle = %q", state.Subtitle)
+	}
+}
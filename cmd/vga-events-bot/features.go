@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// features are the feature flags from --flags-file and VGA_FLAGS (nil leaves every
+// flag at its default)
+var features *flags.Set
+
+// logFeatures prints how each feature flag is rolled out
+func logFeatures() {
+	for _, f := range flags.Known() {
+		fmt.Printf("Feature %s: %s\n", f.Name, features.Status(f))
+	}
+}
+
+// featureUnavailable is the reply to a command whose feature is off for the chat
+func featureUnavailable(command string) string {
+	return fmt.Sprintf("🚧 /%s isn't available yet. Use /help to see available commands.", command)
+}
+
+// recordCohorts notes in an existing user's weekly stats which side of each staged
+// rollout they're on, for vga-events prefs rollout
+func recordCohorts(prefs preferences.Preferences, chatID string, modified *bool) {
+	user, ok := prefs[chatID]
+	if !ok {
+		return
+	}
+	for _, f := range flags.Known() {
+		if features.Staged(f) && user.RecordCohort(f.Name, features.EnabledFor(f, chatID)) {
+			*modified = true
+		}
+	}
+}
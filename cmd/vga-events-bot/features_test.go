@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestFeatureFlaggedCommands(t *testing.T) {
+	set, err := flags.Parse([]byte(`{"enable_geocoding": {"chats": ["111"]}, "enable_natural_queries": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	features = set
+	t.Cleanup(func() { features = nil })
+
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if got, _ := processCommand(prefs, "222", "/home Las Vegas NV", &modified, "", true); !strings.Contains(got, "/home isn't available yet") {
+		t.Errorf("/home outside the rollout: %s", got)
+	}
+	if modified || prefs.GetUser("222").Home != nil {
+		t.Error("a command behind a flag shouldn't run")
+	}
+	if got, _ := processCommand(prefs, "111", "/home Las Vegas NV", &modified, "", true); strings.Contains(got, "isn't available") {
+		t.Errorf("/home for a listed chat: %s", got)
+	}
+
+	if got, _ := processCommand(prefs, "111", "events in NV", &modified, "", true); !strings.Contains(got, "Please send a command") {
+		t.Errorf("plain text with natural queries off: %s", got)
+	}
+}
+
+func TestRecordCohorts(t *testing.T) {
+	set, err := flags.Parse([]byte(`{"enable_web_api": {"chats": ["111"]}, "enable_geocoding": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	features = set
+	t.Cleanup(func() { features = nil })
+
+	prefs := preferences.NewPreferences()
+	prefs.GetUser("111").EnableStats = true
+	prefs.GetUser("222").EnableStats = true
+
+	modified := false
+	recordInteraction(prefs, "111", &modified)
+	recordInteraction(prefs, "222", &modified)
+	if !modified {
+		t.Fatal("recording a cohort should modify preferences")
+	}
+	for chatID, want := range map[string]map[string]bool{
+		"111": {flags.WebAPI.Name: true},
+		"222": {flags.WebAPI.Name: false},
+	} {
+		got := prefs[chatID].WeeklyStats.Cohorts
+		if len(got) != len(want) || got[flags.WebAPI.Name] != want[flags.WebAPI.Name] {
+			t.Errorf("chat %s cohorts = %v, want %v (only staged flags)", chatID, got, want)
+		}
+	}
+
+	modified = false
+	recordCohorts(prefs, "111", &modified)
+	recordCohorts(prefs, "333", &modified)
+	if modified {
+		t.Error("an unchanged cohort or an unknown user shouldn't modify preferences")
+	}
+}
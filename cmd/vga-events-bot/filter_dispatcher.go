@@ -57,6 +57,16 @@ Examples:
 		cityName := strings.Join(parts[2:], " ")
 		return handleFilterCity(prefs, chatID, cityName, modified)
 
+	case "tag":
+		if len(parts) < 3 {
+			return `❌ Please specify a tag.
+
+Usage: /filter tag championship
+
+Tags: ` + strings.Join(tagNames(), ", "), nil
+		}
+		return handleFilterTag(prefs, chatID, parts[2], modified)
+
 	case "weekends":
 		return handleFilterWeekends(prefs, chatID, modified)
 
@@ -104,6 +114,18 @@ Use /filters to see all saved filters.`, nil
 		filterName = strings.Trim(filterName, "\"")
 		return handleFilterDelete(prefs, chatID, filterName, modified)
 
+	case "schedule":
+		return handleFilterSchedule(prefs, chatID, parts[2:], modified)
+
+	case "unschedule":
+		if len(parts) < 3 {
+			return `❌ Please specify the filter whose report to stop.
+
+Usage: /filter unschedule "My Weekend Events"`, nil
+		}
+		filterName := strings.Trim(strings.Join(parts[2:], " "), "\"")
+		return handleFilterUnschedule(prefs, chatID, filterName, modified)
+
 	default:
 		return `❌ Unknown filter subcommand.
 
@@ -111,10 +133,13 @@ Available subcommands:
 • /filter date "Mar 1-15" - Set date range
 • /filter course "Pebble Beach" - Filter by course
 • /filter city "Las Vegas" - Filter by city
+• /filter tag championship - Filter by tag
 • /filter weekends - Toggle weekends-only
 • /filter save "name" - Save current filter
 • /filter load "name" - Load saved filter
 • /filter delete "name" - Delete saved filter
+• /filter schedule "name" weekly - Get a saved filter's matches weekly (or daily)
+• /filter unschedule "name" - Stop a scheduled report
 • /filter clear - Remove active filter
 • /filter - Show current filter status
 
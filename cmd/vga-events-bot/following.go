@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// handleFollowCourse adds a course the user is notified about in any state
+func handleFollowCourse(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify a course.\n\nUsage: /follow-course &lt;course name&gt;\n\nExample: /follow-course \"Chimera Golf Club\""
+	}
+
+	course, errMsg := validateUserInput(strings.Trim(strings.Join(args, " "), `"' `), 100, "Course name")
+	if errMsg != "" {
+		return errMsg
+	}
+
+	user := prefs.GetUser(chatID)
+	if user.FollowsCourseName(course) {
+		return fmt.Sprintf("ℹ️ You already follow %s.\n\nUse /following to see your courses.", html.EscapeString(course))
+	}
+	if !prefs.FollowCourse(chatID, course) {
+		return fmt.Sprintf("❌ You can follow up to %d courses.\n\nUse /following to remove one.", preferences.MaxFollowedCourses)
+	}
+	*modified = true
+
+	return fmt.Sprintf("✅ <b>Following %s!</b>\n\nYou'll be notified right away about new events there, in any state, even if you get a digest.\n\nUse /following to manage your courses.",
+		html.EscapeString(course))
+}
+
+// handleUnfollowCourse removes a followed course by name
+func handleUnfollowCourse(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify a course.\n\nUsage: /unfollow-course &lt;course name&gt;"
+	}
+
+	course := strings.Trim(strings.Join(args, " "), `"' `)
+	if !prefs.UnfollowCourse(chatID, course) {
+		return fmt.Sprintf("ℹ️ You don't follow %s.\n\nUse /following to see your courses.", html.EscapeString(course))
+	}
+	*modified = true
+	return fmt.Sprintf("✅ Stopped following %s.", html.EscapeString(course))
+}
+
+// buildFollowingList lists the user's followed courses with a button to unfollow each
+func buildFollowingList(prefs preferences.Preferences, chatID string) (string, *telegram.InlineKeyboardMarkup) {
+	courses := prefs.GetUser(chatID).FollowedCourses
+	if len(courses) == 0 {
+		return "⛳ <b>Followed Courses</b>\n\nYou don't follow any courses.\n\nUse /follow-course &lt;course name&gt; to be notified about every event at a course, in any state.", nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("⛳ <b>Followed Courses</b> (%d)\n\n", len(courses)))
+	keyboard := &telegram.InlineKeyboardMarkup{}
+	for i, course := range courses {
+		msg.WriteString(fmt.Sprintf("• %s\n", html.EscapeString(course)))
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []telegram.InlineKeyboardButton{
+			{Text: fmt.Sprintf("❌ %s", course), CallbackData: fmt.Sprintf("unfollow:%d", i)},
+		})
+	}
+	msg.WriteString("\nTap a course to stop following it.")
+	return msg.String(), keyboard
+}
+
+// handleFollowing shows the followed courses list
+func handleFollowing(prefs preferences.Preferences, chatID, botToken string, dryRun bool) (string, []*event.Event) {
+	text, keyboard := buildFollowingList(prefs, chatID)
+
+	if keyboard != nil && !dryRun {
+		client, err := newSender(botToken, chatID)
+		if err == nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
+			}
+			return "", nil // Already sent via keyboard
+		}
+	}
+
+	return text, nil
+}
+
+// handleUnfollowCallback unfollows a course from the /following list.
+// Format: unfollow:INDEX
+func handleUnfollowCallback(param string, prefs preferences.Preferences, chatID string, modified *bool) (string, *telegram.InlineKeyboardMarkup) {
+	courses := prefs.GetUser(chatID).FollowedCourses
+	i, err := strconv.Atoi(param)
+	if err != nil || i < 0 || i >= len(courses) {
+		// The list changed since it was shown
+		return buildFollowingList(prefs, chatID)
+	}
+
+	prefs.UnfollowCourse(chatID, courses[i])
+	*modified = true
+	return buildFollowingList(prefs, chatID)
+}
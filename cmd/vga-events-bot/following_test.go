@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestFollowCourseCommands(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	response := handleFollowCourse(prefs, "123", []string{`"Chimera`, `Golf`, `Club"`}, &modified)
+	if !modified || !strings.Contains(response, "Following Chimera Golf Club") {
+		t.Errorf("unexpected response: %q", response)
+	}
+
+	modified = false
+	response = handleFollowCourse(prefs, "123", []string{"the", "chimera"}, &modified)
+	if modified || !strings.Contains(response, "already follow") {
+		t.Errorf("following the same course again: %q", response)
+	}
+
+	if list := handleList(prefs, "123"); !strings.Contains(list, "Chimera Golf Club") {
+		t.Errorf("/list should show followed courses, got %q", list)
+	}
+
+	handleFollowCourse(prefs, "123", []string{"Wolf", "Creek"}, &modified)
+	text, keyboard := buildFollowingList(prefs, "123")
+	if !strings.Contains(text, "(2)") || len(keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("unexpected list: %q", text)
+	}
+
+	modified = false
+	text, _ = handleUnfollowCallback("0", prefs, "123", &modified)
+	if !modified || strings.Contains(text, "Chimera") || !strings.Contains(text, "Wolf Creek") {
+		t.Errorf("unfollow callback should remove Chimera, got %q", text)
+	}
+
+	// A stale index just redraws the list
+	modified = false
+	handleUnfollowCallback("5", prefs, "123", &modified)
+	if modified {
+		t.Error("stale index should not modify preferences")
+	}
+
+	response = handleUnfollowCourse(prefs, "123", []string{"wolf", "creek"}, &modified)
+	if !modified || !strings.Contains(response, "Stopped following") {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
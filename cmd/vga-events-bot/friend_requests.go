@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// handleJoin sends a friend request to the owner of an invite code, who approves or
+// declines it with buttons. Requests to someone who blocked the sender look sent but
+// go nowhere.
+func handleJoin(prefs preferences.Preferences, chatID, inviteCode string, modified *bool, botToken string, dryRun bool) string {
+	user := prefs.GetUser(chatID)
+	if user == nil {
+		return errUserNotFound
+	}
+
+	now := clk.Now()
+	friendChatID, expired, ok := prefs.FindInviteCode(inviteCode, now)
+	if !ok {
+		return fmt.Sprintf("❌ Invalid invite code: <code>%s</code>\n\nMake sure you entered the code correctly.", html.EscapeString(inviteCode))
+	}
+	if friendChatID == chatID {
+		return "❌ You can't add yourself as a friend!"
+	}
+	if expired {
+		return "⌛ That invite code has expired.\n\nAsk your friend for a new one (/invite new)."
+	}
+	if user.IsBlocked(friendChatID) {
+		return fmt.Sprintf("🚫 You blocked this user.\n\nUse /unblock %s first if you want to be friends.", friendChatID)
+	}
+
+	const sent = "📨 <b>Friend Request Sent!</b>\n\nYou'll be connected once they approve it. We'll let you know."
+	switch err := prefs.RequestFriend(chatID, friendChatID, now); {
+	case errors.Is(err, preferences.ErrAlreadyFriends):
+		return "ℹ️ You're already friends with this user!"
+	case errors.Is(err, preferences.ErrRequestPending):
+		return "ℹ️ Your friend request is still waiting for their approval."
+	case errors.Is(err, preferences.ErrBlocked):
+		return sent
+	}
+	prefs.GetUser(friendChatID).UseInviteCode(now)
+	*modified = true
+
+	notifyFriendRequest(friendChatID, chatID, botToken, dryRun)
+	return sent
+}
+
+// friendRequestKeyboard has the Approve, Decline, and Block buttons for a request
+func friendRequestKeyboard(fromChatID string) *telegram.InlineKeyboardMarkup {
+	return &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{
+				{Text: "✅ Approve", CallbackData: "friend:approve:" + fromChatID},
+				{Text: "❌ Decline", CallbackData: "friend:decline:" + fromChatID},
+			},
+			{{Text: "🚫 Block", CallbackData: "friend:block:" + fromChatID}},
+		},
+	}
+}
+
+// notifyFriendRequest asks toChatID to approve a friend request
+func notifyFriendRequest(toChatID, fromChatID, botToken string, dryRun bool) {
+	msg := fmt.Sprintf("👥 <b>Friend Request</b>\n\nUser <code>%s</code> used your invite code and wants to be friends.\n\nFriends can see the events you're registered for (when you both enable sharing), and share discussions and group notes.", fromChatID)
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would send friend request from %s to %s\n", fromChatID, toChatID)
+		return
+	}
+	client, err := newSender(botToken, toChatID)
+	if err == nil {
+		err = client.SendMessageWithKeyboard(botCtx, msg, friendRequestKeyboard(fromChatID))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending friend request to %s: %v\n", toChatID, err)
+	}
+}
+
+// notifyRequester tells the sender of a friend request that it was approved
+func notifyRequester(requesterChatID, text, botToken string, dryRun bool) {
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would send to %s:\n%s\n\n", requesterChatID, text)
+		return
+	}
+	client, err := newSender(botToken, requesterChatID)
+	if err == nil {
+		err = client.SendMessage(botCtx, text)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error notifying %s about their friend request: %v\n", requesterChatID, err)
+	}
+}
+
+// handleFriendRequestCallback answers the buttons on a friend request
+// Format: friend:approve|decline|block:CHAT_ID
+func handleFriendRequestCallback(prefs preferences.Preferences, chatID string, parts []string, modified *bool, botToken string, dryRun bool) string {
+	if len(parts) < 3 || parts[2] == "" {
+		return "❌ Invalid action"
+	}
+	switch parts[1] {
+	case "approve":
+		return approveFriendRequest(prefs, chatID, parts[2], modified, botToken, dryRun)
+	case "decline":
+		if !prefs.GetUser(chatID).DeclineFriend(parts[2]) {
+			return "ℹ️ This friend request is no longer pending."
+		}
+		*modified = true
+		return fmt.Sprintf("❌ Declined the friend request from <code>%s</code>.\n\nThey aren't told. To stop further requests from them, use /block %s", html.EscapeString(parts[2]), html.EscapeString(parts[2]))
+	case "block":
+		return blockUser(prefs, chatID, parts[2], modified)
+	}
+	return "❌ Invalid action"
+}
+
+// approveFriendRequest makes the requester a friend, saving both sides together so
+// neither is kept without the other
+func approveFriendRequest(prefs preferences.Preferences, chatID, from string, modified *bool, botToken string, dryRun bool) string {
+	if _, ok := prefs.GetUser(chatID).FriendRequests[from]; !ok {
+		return "ℹ️ This friend request is no longer pending."
+	}
+	err := commitUsers(prefs, []string{chatID, from}, func(p preferences.Preferences) error {
+		p.ApproveFriend(chatID, from)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving friendship: %v\n", err)
+		errReporter.Error(botCtx, err, errreport.Context{Command: "friend approve", ChatID: chatID})
+		return "❌ Couldn't save the friendship right now. Please tap Approve again in a moment."
+	}
+	*modified = true
+
+	notifyRequester(from, fmt.Sprintf("✅ <b>Friend Request Approved!</b>\n\nYou're now connected with user <code>%s</code>.\n\nUse /friends to see your friend list, and enable sharing in /settings to see each other's registered events.", chatID), botToken, dryRun)
+
+	return fmt.Sprintf(`✅ <b>Friend Added!</b>
+
+You're now connected with user <code>%s</code>
+
+<b>Next steps:</b>
+• Use /friends to see your friend list
+• Enable event sharing in /settings to see when they're registered for events
+• Use /my-events to coordinate attendance
+
+Both you and your friend need to enable sharing to see each other's event registrations.`, from)
+}
+
+// blockUser blocks other, ending any friendship with them. other must be a numeric
+// chat ID, or a friend or requester of the user.
+func blockUser(prefs preferences.Preferences, chatID, other string, modified *bool) string {
+	if other == chatID {
+		return "❌ You can't block yourself."
+	}
+	user := prefs.GetUser(chatID)
+	if _, requested := user.FriendRequests[other]; !requested && !user.IsFriend(other) {
+		if _, err := strconv.ParseInt(other, 10, 64); err != nil {
+			return fmt.Sprintf("❌ <code>%s</code> isn't a user ID.\n\nUse the ID shown in /friends or a friend request.", html.EscapeString(other))
+		}
+	}
+	if user.IsBlocked(other) {
+		return fmt.Sprintf("ℹ️ User <code>%s</code> is already blocked.", html.EscapeString(other))
+	}
+	err := commitUsers(prefs, []string{chatID, other}, func(p preferences.Preferences) error {
+		return p.Block(chatID, other)
+	})
+	switch {
+	case errors.Is(err, preferences.ErrAlreadyBlocked):
+		return fmt.Sprintf("ℹ️ User <code>%s</code> is already blocked.", html.EscapeString(other))
+	case errors.Is(err, preferences.ErrTooManyBlocked):
+		return fmt.Sprintf("❌ You've blocked %d users, the most you can.\n\nUse /unblock &lt;user_id&gt; to make room.", preferences.MaxBlockedChats)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error saving block: %v\n", err)
+		errReporter.Error(botCtx, err, errreport.Context{Command: "/block", ChatID: chatID})
+		return "❌ Couldn't save that right now. Please try again in a moment."
+	}
+	*modified = true
+	return fmt.Sprintf("🚫 <b>Blocked</b> user <code>%s</code>\n\nThey can't send you friend requests, and you no longer share events, discussions, or group notes. They aren't told.\n\nUse /unblock %s to undo.", html.EscapeString(other), html.EscapeString(other))
+}
+
+// handleBlock blocks a user by chat ID, or lists blocked users
+// Format: /block [user_id]
+func handleBlock(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		user := prefs.GetUser(chatID)
+		if len(user.BlockedChatIDs) == 0 {
+			return "🚫 You haven't blocked anyone.\n\nUsage: /block &lt;user_id&gt; (the ID shown in /friends or a friend request)"
+		}
+		var msg strings.Builder
+		msg.WriteString("🚫 <b>Blocked Users</b>\n\n")
+		for _, id := range user.BlockedChatIDs {
+			msg.WriteString(fmt.Sprintf("• <code>%s</code>\n", id))
+		}
+		msg.WriteString("\nUse /unblock &lt;user_id&gt; to unblock someone.")
+		return msg.String()
+	}
+	return blockUser(prefs, chatID, args[0], modified)
+}
+
+// handleUnblock lets a blocked user send friend requests again
+// Format: /unblock <user_id>
+func handleUnblock(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify a user ID.\n\nUsage: /unblock &lt;user_id&gt;"
+	}
+	if !prefs.GetUser(chatID).Unblock(args[0]) {
+		return fmt.Sprintf("ℹ️ User <code>%s</code> isn't blocked.", html.EscapeString(args[0]))
+	}
+	*modified = true
+	return fmt.Sprintf("✅ Unblocked user <code>%s</code>\n\nThey can send you a friend request again with your invite code.", html.EscapeString(args[0]))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// handleGroupNote shows an event's group note, adds a line to it and tells the user's
+// friends, or clears the user's own lines.
+// Format: /group-note <event_id> [text | clear]
+func handleGroupNote(prefs preferences.Preferences, chatID string, args []string, modified *bool, botToken string, dryRun bool) string {
+	if len(args) == 0 {
+		return "❌ Please specify an event ID.\n\nUsage: /group-note &lt;event_id&gt; [text]"
+	}
+	eventIDs, unknown := resolveKnownEventIDs(args[:1])
+	if len(unknown) > 0 {
+		return formatUnknownCodes(unknown)
+	}
+	eventID := eventIDs[0]
+
+	if len(args) == 1 {
+		return formatGroupNote(prefs, chatID, eventID)
+	}
+
+	user := prefs.GetUser(chatID)
+	if len(args) == 2 && strings.EqualFold(args[1], "clear") {
+		if user.ClearGroupNote(eventID) == 0 {
+			return "ℹ️ You haven't added anything to this group note."
+		}
+		*modified = true
+		return "✅ Your lines were removed from the group note.\n\n" + formatGroupNote(prefs, chatID, eventID)
+	}
+
+	if len(user.FriendChatIDs) == 0 {
+		return "👥 Group notes are shared with your friends, and you haven't added any yet.\n\nUse /invite to get a code for your friends, or /note for a personal note."
+	}
+
+	text, errMsg := validateUserInput(strings.Join(args[1:], " "), preferences.MaxGroupNoteLength, "Note text")
+	if errMsg != "" {
+		return errMsg
+	}
+
+	user.AppendGroupNote(eventID, text, clk.Now())
+	*modified = true
+
+	var msg strings.Builder
+	if dryRun {
+		msg.WriteString(fmt.Sprintf("[DRY RUN] Would tell %d friend(s) about the change.\n\n", len(user.FriendChatIDs)))
+	} else {
+		notified := notifyGroupNote(user.FriendChatIDs, chatID, eventID, text, botToken)
+		msg.WriteString(fmt.Sprintf("✅ Added to the group note. %d of %d friend(s) notified.\n\n", notified, len(user.FriendChatIDs)))
+	}
+	msg.WriteString(formatGroupNote(prefs, chatID, eventID))
+	return msg.String()
+}
+
+// notifyGroupNote tells friends about a line added to a group note, with a button to
+// view the whole note, and returns how many it reached
+func notifyGroupNote(friendIDs []string, fromChatID, eventID, text, botToken string) int {
+	label, _ := describeEvent(eventID)
+	msg := fmt.Sprintf("📌 <b>Friend <code>%s</code></b> updated the group note for %s:\n\n+ %s", fromChatID, label, html.EscapeString(text))
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "📌 View group note", CallbackData: "gnote:" + eventID}},
+		},
+	}
+
+	sent := 0
+	for _, friendID := range friendIDs {
+		client, err := newSender(botToken, friendID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating client for group note notification: %v\n", err)
+			continue
+		}
+		if err := client.SendMessageWithKeyboard(botCtx, msg, keyboard); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending group note notification to %s: %v\n", friendID, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// formatGroupNote shows an event's group note with who added each line
+func formatGroupNote(prefs preferences.Preferences, chatID, eventID string) string {
+	label, ref := describeEvent(eventID)
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("📌 <b>Group Note</b> — %s\n\n", label))
+
+	lines := prefs.GroupNote(chatID, eventID)
+	if len(lines) == 0 {
+		msg.WriteString("Nothing here yet. Add plans your friends should know about, like where to meet.\n")
+	}
+	for _, line := range lines {
+		author := fmt.Sprintf("Friend <code>%s</code>", line.ChatID)
+		if line.ChatID == chatID {
+			author = "You"
+		}
+		at := time.Unix(line.At, 0).UTC().Format("Jan 2")
+		msg.WriteString(fmt.Sprintf("• %s <i>— %s, %s</i>\n", html.EscapeString(line.Text), author, at))
+	}
+
+	msg.WriteString(fmt.Sprintf("\nAdd a line with:\n<code>/group-note %s your text</code>", ref))
+	return msg.String()
+}
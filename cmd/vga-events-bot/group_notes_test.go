@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestHandleGroupNote(t *testing.T) {
+	serveEvents(t, []*event.Event{{ID: "evt1", State: "NV", Title: "Reno Open"}})
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if response := handleGroupNote(prefs, "111", []string{"evt1", "Meet", "at", "7:30"}, &modified, "", true); modified || !strings.Contains(response, "/invite") {
+		t.Errorf("without friends: %q", response)
+	}
+
+	prefs.GetUser("111").AddFriend("222")
+	prefs.GetUser("222").AddFriend("111")
+
+	response := handleGroupNote(prefs, "111", []string{"evt1", "Meet", "<range>", "at", "7:30"}, &modified, "", true)
+	if !modified || !strings.Contains(response, "tell 1 friend(s)") {
+		t.Fatalf("add: %q", response)
+	}
+
+	if response := handleGroupNote(prefs, "111", []string{"evt9", "Meet", "at", "7:30"}, &modified, "", true); !strings.Contains(response, "Unknown event") || len(prefs.GroupNote("111", "evt9")) != 0 {
+		t.Errorf("an ID that isn't an event: %q", response)
+	}
+
+	// The friend sees the line, attributed to its author
+	response = handleGroupNote(prefs, "222", []string{"evt1"}, &modified, "", true)
+	if !strings.Contains(response, "• Meet &lt;range&gt; at 7:30 <i>— Friend <code>111</code>") {
+		t.Errorf("friend's view: %q", response)
+	}
+
+	if response := handleGroupNote(prefs, "222", []string{"evt1", "clear"}, &modified, "", true); !strings.Contains(response, "haven't added") {
+		t.Errorf("clearing someone else's line: %q", response)
+	}
+	if response := handleGroupNote(prefs, "111", []string{"evt1", "clear"}, &modified, "", true); !strings.Contains(response, "Nothing here yet") {
+		t.Errorf("clear: %q", response)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// helpArgEscaper escapes usage arguments like <event_id> for Telegram HTML
+var helpArgEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;")
+
+// getHelpMessage returns the /help and /start message with the command list from the registry
+func getHelpMessage() string {
+	return fmt.Sprintf(`🤖 <b>VGA Events Bot</b>
+
+I help you track VGA Golf events in your favorite states!
+
+<b>Commands:</b>
+
+%s/help &lt;command&gt; - Get detailed help for any command
+
+<b>Event Tracking:</b>
+Mark events with status buttons:
+• ⭐ Interested - Events you want to attend
+• ✅ Registered - Events you've signed up for
+• 🤔 Maybe - Events you're considering
+• ❌ Skip - Events you're not interested in
+
+<b>Reminders:</b>
+Get reminded before events you've marked as ⭐ Interested or ✅ Registered.
+Configure reminder timing with /reminders (1 day, 3 days, 1 week, or 2 weeks before).
+
+<b>Bulk Actions:</b>
+Manage multiple events at once with /bulk:
+• Clear all skipped events
+• Export all registered events to calendar
+
+<b>Friends &amp; Sharing:</b>
+Connect with golf buddies to coordinate events:
+• /invite - Get your invite code to share with friends
+• /join &lt;code&gt; - Send a friend request using their invite code
+• /friends - View your friend list
+• /block &lt;user_id&gt; - Stop a user's friend requests
+When both you and a friend enable sharing in /settings, you'll see when they're registered for events.
+
+<b>State Codes:</b>
+Use 2-letter state codes like NV, CA, TX, etc.
+Use %s to subscribe to all states.
+
+<b>Notifications:</b>
+You'll receive messages whenever new events are posted in your subscribed states.
+• <b>Immediate mode</b> - Get notified right away (default)
+• <b>Daily digest</b> - Receive a daily summary at 9 AM UTC
+• <b>Weekly digest</b> - Receive a weekly summary on Mondays
+
+Change your preferences with /settings, and when digests arrive with /settings time
+
+Checks run every hour.
+
+━━━━━━━━━━━━━━━━━━━━━━
+<b>Support &amp; Info:</b>
+Need help or have feedback? Contact @iamdesertpaul
+
+Created by Paul Frederiksen
+Open source at github.com/pfrederiksen/vga-events`, formatCommandList(), AllStatesCode)
+}
+
+// formatCommandList renders the registry as the /help command listing
+func formatCommandList() string {
+	var sb strings.Builder
+	for _, cmd := range commandRegistry {
+		if cmd.Unlisted {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("/%s - %s", cmd.Name, cmd.Summary))
+		if cmd.Emoji != "" {
+			sb.WriteString(" " + cmd.Emoji)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatUsageLines renders "/command args - text" lines for a help section
+func formatUsageLines(sb *strings.Builder, name string, lines []usageLine) {
+	for _, line := range lines {
+		sb.WriteString("/" + name)
+		if line.Args != "" {
+			sb.WriteString(" " + helpArgEscaper.Replace(line.Args))
+		}
+		if line.Text != "" {
+			sb.WriteString(" - " + line.Text)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// getCommandHelp returns detailed help for a specific command
+func getCommandHelp(cmdName string) string {
+	cmd, ok := commandsByName[cmdName]
+	if !ok {
+		return fmt.Sprintf(`❓ <b>Unknown Command: /%s</b>
+
+No help available for this command.
+
+Use /help to see all available commands.
+
+<b>Popular Commands:</b>
+/subscribe - Subscribe to states
+/events - View upcoming events
+/search - Search for events
+/my-events - View tracked events
+/settings - Configure preferences
+
+<b>Get Detailed Help:</b>
+/help subscribe - Help for /subscribe
+/help search - Help for /search
+etc.`, helpArgEscaper.Replace(cmdName))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s <b>/%s - %s</b>\n\n", cmd.Icon, cmd.Name, cmd.Title))
+	sb.WriteString(fmt.Sprintf("<b>Description:</b>\n%s\n\n", cmd.Description))
+
+	sb.WriteString("<b>Usage:</b>\n")
+	formatUsageLines(&sb, cmd.Name, cmd.Usage)
+
+	if len(cmd.Examples) > 0 {
+		sb.WriteString("\n<b>Examples:</b>\n")
+		formatUsageLines(&sb, cmd.Name, cmd.Examples)
+	}
+
+	for _, section := range cmd.Sections {
+		sb.WriteString(fmt.Sprintf("\n<b>%s:</b>\n", section.Title))
+		sb.WriteString(strings.Join(section.Lines, "\n"))
+		sb.WriteString("\n")
+	}
+
+	if tier, ok := cooldownTiers[cmd.Cost]; ok {
+		sb.WriteString(fmt.Sprintf("\n⏳ <i>Limited to %s.</i>\n", formatCooldownLimit(tier)))
+	}
+
+	if len(cmd.Related) > 0 {
+		sb.WriteString("\n<b>Related Commands:</b>\n")
+		for _, name := range cmd.Related {
+			if related, ok := commandsByName[name]; ok {
+				sb.WriteString(fmt.Sprintf("/%s - %s\n", related.Name, related.Summary))
+			}
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
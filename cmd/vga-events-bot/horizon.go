@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const horizonUsage = "Usage: /horizon &lt;days&gt;\n\nExamples:\n/horizon 60\n/horizon off"
+
+// parseHorizonDays parses a /horizon argument: a number of days, optionally with a "d"
+// or "days" suffix, or "off" for no limit
+func parseHorizonDays(text string) (int, bool) {
+	text = strings.ToLower(strings.ReplaceAll(text, " ", ""))
+	switch text {
+	case "off", "none", "any", "all":
+		return 0, true
+	}
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "days"), "d")
+	days, err := strconv.Atoi(text)
+	if err != nil || days < 0 {
+		return 0, false
+	}
+	return days, true
+}
+
+// handleHorizon shows or sets how many days ahead events are listed and notified
+func handleHorizon(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if len(args) == 0 {
+		return fmt.Sprintf("📅 You see events for %s.\n\nUse /horizon &lt;days&gt; to change it, or /horizon off for any date.\n\n%s",
+			describeDaysAhead(user.DaysAhead), horizonUsage)
+	}
+
+	days, ok := parseHorizonDays(strings.Join(args, " "))
+	if !ok {
+		return "❌ Couldn't read that number of days.\n\n" + horizonUsage
+	}
+	if !user.SetDaysAhead(days) {
+		return fmt.Sprintf("❌ The horizon can be up to %d days.", preferences.MaxDaysAhead)
+	}
+	*modified = true
+
+	if days == 0 {
+		return "✅ Horizon removed. /events, /search, /near, digests, and notifications include events on any date."
+	}
+	return fmt.Sprintf("✅ /events, /search, /near, digests, and notifications now include events in %s.", describeDaysAhead(days))
+}
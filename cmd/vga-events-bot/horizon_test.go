@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestParseHorizonDays(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+		ok   bool
+	}{
+		{"60", 60, true},
+		{"30d", 30, true},
+		{"14 days", 14, true},
+		{"off", 0, true},
+		{"0", 0, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseHorizonDays(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseHorizonDays(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHandleHorizon(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("123")
+	modified := false
+
+	if resp := handleHorizon(prefs, "123", nil, &modified); modified || !strings.Contains(resp, "any date") {
+		t.Errorf("handleHorizon() = %q, modified %v", resp, modified)
+	}
+
+	resp := handleHorizon(prefs, "123", []string{"60"}, &modified)
+	if !modified || user.DaysAhead != 60 || !strings.Contains(resp, "the next 60 days") {
+		t.Errorf("handleHorizon(60) = %q, DaysAhead %d, modified %v", resp, user.DaysAhead, modified)
+	}
+
+	modified = false
+	resp = handleHorizon(prefs, "123", []string{"1000"}, &modified)
+	if modified || user.DaysAhead != 60 || !strings.Contains(resp, "up to 365 days") {
+		t.Errorf("handleHorizon(1000) = %q, DaysAhead %d, modified %v", resp, user.DaysAhead, modified)
+	}
+
+	handleHorizon(prefs, "123", []string{"off"}, &modified)
+	if !modified || user.DaysAhead != 0 {
+		t.Errorf("handleHorizon(off) left DaysAhead at %d", user.DaysAhead)
+	}
+}
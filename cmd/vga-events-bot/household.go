@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// errHouseholdFull means linking would make a household larger than allowed
+var errHouseholdFull = errors.New("household full")
+
+// handleLink shows the household and a new link code, or links with another chat's code
+func handleLink(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if len(args) == 0 {
+		code := user.NewLinkCode(clk.Now())
+		*modified = true
+
+		var msg strings.Builder
+		msg.WriteString("🔗 <b>Link Accounts</b>\n\n")
+		if len(user.LinkedChatIDs) > 0 {
+			msg.WriteString("Linked with:\n")
+			for _, id := range user.LinkedChatIDs {
+				msg.WriteString(fmt.Sprintf("• <code>%s</code>\n", id))
+			}
+			msg.WriteString("\n")
+		}
+		msg.WriteString(fmt.Sprintf("Your link code: <code>%s</code>\n\n", code))
+		msg.WriteString(fmt.Sprintf("From your other account (or a family member's), send:\n/link %s\n\n", code))
+		msg.WriteString("Linked accounts share event statuses, notes, and notification history, so each new event is sent to only one of you. ")
+		msg.WriteString(fmt.Sprintf("The code works once and expires in %d hours.\n\nUse /unlink to leave.", int(preferences.LinkCodeTTL.Hours())))
+		return msg.String()
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(args[0]))
+	otherChatID, ok := prefs.FindLinkCode(code, clk.Now())
+	if !ok {
+		return fmt.Sprintf("❌ Invalid or expired link code: <code>%s</code>\n\nSend /link from the other account to get a new code.", html.EscapeString(code))
+	}
+	if otherChatID == chatID {
+		return "❌ That's your own link code. Send it from the other account."
+	}
+	for _, id := range user.LinkedChatIDs {
+		if id == otherChatID {
+			return "ℹ️ These accounts are already linked."
+		}
+	}
+	// Every member's household list changes, so they're saved together
+	members := append(prefs.Household(chatID), prefs.Household(otherChatID)...)
+	err := commitUsers(prefs, members, func(p preferences.Preferences) error {
+		if !p.LinkAccounts(chatID, otherChatID) {
+			return errHouseholdFull
+		}
+		return nil
+	})
+	if errors.Is(err, errHouseholdFull) {
+		return fmt.Sprintf("❌ A household can link at most %d accounts.", preferences.MaxHouseholdSize)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving linked accounts: %v\n", err)
+		return "❌ Couldn't link the accounts right now. Please try again in a moment."
+	}
+	*modified = true
+
+	return fmt.Sprintf(`✅ <b>Accounts Linked!</b>
+
+You're now linked with <code>%s</code>.
+
+• Event statuses and notes are shared
+• Each new event is sent to only one of you
+• /stats household counts each event once
+
+Use /unlink to stop sharing.`, otherChatID)
+}
+
+// handleUnlink removes the chat from its household
+func handleUnlink(prefs preferences.Preferences, chatID string, modified *bool) string {
+	if len(prefs.GetUser(chatID).LinkedChatIDs) == 0 {
+		return "ℹ️ This account isn't linked.\n\nUse /link to link with another account."
+	}
+	err := commitUsers(prefs, prefs.Household(chatID), func(p preferences.Preferences) error {
+		p.UnlinkAccount(chatID)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving unlinked account: %v\n", err)
+		return "❌ Couldn't unlink the account right now. Please try again in a moment."
+	}
+	*modified = true
+	return "✅ <b>Account unlinked.</b>\n\nYou keep a copy of your event statuses and notes, but changes are no longer shared."
+}
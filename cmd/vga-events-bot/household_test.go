@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// failingStorage refuses every save, as if the Gist were unreachable
+type failingStorage struct{}
+
+func (failingStorage) Load(ctx context.Context) (preferences.Preferences, error) {
+	return preferences.NewPreferences(), nil
+}
+
+func (failingStorage) Save(ctx context.Context, prefs preferences.Preferences) error {
+	return errors.New("gist unavailable")
+}
+
+func TestMultiUserChangesRollBack(t *testing.T) {
+	prefsStore = failingStorage{}
+	defer func() { prefsStore = nil }()
+
+	prefs := preferences.NewPreferences()
+	prefs.AddState("111", "NV")
+	code := prefs.GetUser("111").GetInviteCode()
+	modified := false
+
+	handleJoin(prefs, "222", code, &modified, "", true)
+	modified = false
+	response := handleFriendRequestCallback(prefs, "111", []string{"friend", "approve", "222"}, &modified, "", true)
+	if modified || !strings.Contains(response, "tap Approve again") {
+		t.Errorf("approving with a failed save: modified=%v, %q", modified, response)
+	}
+	if prefs.GetUser("111").IsFriend("222") || prefs.GetUser("222").IsFriend("111") {
+		t.Error("neither side of the friendship should be kept when the save fails")
+	}
+	if len(prefs.GetUser("111").FriendRequests) != 1 {
+		t.Error("the request should still be pending after a failed approval")
+	}
+
+	linkCode := prefs.GetUser("111").NewLinkCode(time.Now())
+	response = handleLink(prefs, "222", []string{linkCode}, &modified)
+	if modified || !strings.Contains(response, "Couldn't link") {
+		t.Errorf("/link with a failed save: modified=%v, %q", modified, response)
+	}
+	if len(prefs.GetUser("111").LinkedChatIDs) != 0 || len(prefs.GetUser("222").LinkedChatIDs) != 0 {
+		t.Error("neither account should be linked when the save fails")
+	}
+}
+
+func TestLinkCommands(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	response := handleLink(prefs, "111", nil, &modified)
+	code := prefs.GetUser("111").LinkCode
+	if !modified || code == "" || !strings.Contains(response, code) {
+		t.Fatalf("/link should show a new code, got %q", response)
+	}
+
+	modified = false
+	if response := handleLink(prefs, "111", []string{code}, &modified); modified || !strings.Contains(response, "your own link code") {
+		t.Errorf("using own code: %q", response)
+	}
+	if response := handleLink(prefs, "222", []string{"NOPE1234"}, &modified); modified || !strings.Contains(response, "Invalid or expired") {
+		t.Errorf("unknown code: %q", response)
+	}
+
+	response = handleLink(prefs, "222", []string{strings.ToLower(code)}, &modified)
+	if !modified || !strings.Contains(response, "Accounts Linked") {
+		t.Fatalf("unexpected response: %q", response)
+	}
+	prefs.GetUser("111").SetEventStatus("evt1", preferences.EventStatusRegistered)
+	if prefs.GetUser("222").GetEventStatus("evt1") != preferences.EventStatusRegistered {
+		t.Error("linked accounts should share statuses")
+	}
+
+	if stats := handleStats(prefs, "222", "household"); !strings.Contains(stats, "Household") || !strings.Contains(stats, "2 linked accounts") {
+		t.Errorf("/stats household: %q", stats)
+	}
+
+	modified = false
+	if response := handleUnlink(prefs, "222", &modified); !modified || !strings.Contains(response, "unlinked") {
+		t.Errorf("unlink: %q", response)
+	}
+	if response := handleUnlink(prefs, "222", &modified); !strings.Contains(response, "isn't linked") {
+		t.Errorf("unlink twice: %q", response)
+	}
+	if stats := handleStats(prefs, "222", "household"); !strings.Contains(stats, "isn't linked") {
+		t.Errorf("/stats household when unlinked: %q", stats)
+	}
+}
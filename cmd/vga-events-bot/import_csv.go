@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const (
+	// maxImportBytes caps the CSV files /import reads
+	maxImportBytes = 256 << 10
+	// maxImportRows caps the rows /import matches, not counting the header
+	maxImportRows = 200
+	// maxImportListed caps the matched and unmatched rows listed in the reply
+	maxImportListed = 15
+)
+
+const importUsage = `📥 <b>Import Events from CSV</b>
+
+Send a .csv file with the caption:
+/import [status]
+
+Each row is matched to a current event by its title, and by its date and state when given. Matched events are marked with the status in the caption (default interested), or the row's own status column.
+
+<b>Columns</b> (with a header row, any order): title, date, state, status, event_id
+Without a header: title, date, state`
+
+// importRow is a row of an imported CSV
+type importRow struct {
+	Line    int // Line in the file, for the report
+	EventID string
+	Title   string
+	Date    string
+	State   string
+	Status  string
+}
+
+// importColumns maps header names to importRow fields
+var importColumns = map[string]string{
+	"event_id": "event_id", "id": "event_id", "code": "event_id",
+	"title": "title", "event": "title", "course": "title", "name": "title",
+	"date":   "date",
+	"state":  "state",
+	"status": "status",
+}
+
+// parseImportCSV reads the rows of an imported CSV. A first row naming a title column is
+// a header; otherwise the columns are title, date, and state.
+func parseImportCSV(data []byte) ([]importRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	columns := map[string]int{"title": 0, "date": 1, "state": 2}
+	var rows []importRow
+	for first := true; ; first = false {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+
+		if first {
+			if header := importHeader(record); header != nil {
+				columns = header
+				continue
+			}
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := importRow{
+			Line:    line,
+			EventID: field("event_id"),
+			Title:   field("title"),
+			Date:    field("date"),
+			State:   strings.ToUpper(field("state")),
+			Status:  strings.ToLower(field("status")),
+		}
+		if row.EventID == "" && row.Title == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// importHeader returns the columns named in record, or nil if it isn't a header row
+func importHeader(record []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := importColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		if _, ok := columns["event_id"]; !ok {
+			return nil
+		}
+	}
+	return columns
+}
+
+// parseImportDate parses a spreadsheet date ("2026-04-18", "4/18/2026") or an event date
+// ("Apr 18 2026"), returning the zero time if it can't
+func parseImportDate(text string) time.Time {
+	for _, layout := range []string{"2006-01-02", "1/2/2006", "January 2, 2006", "Jan 2, 2006"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t
+		}
+	}
+	return event.ParseDate(text)
+}
+
+// matchImportRow returns the event a row is for: by event ID or short code, else by title
+func matchImportRow(row importRow, events []*event.Event) *event.Event {
+	if row.EventID != "" {
+		for _, evt := range events {
+			if evt.ID == row.EventID || strings.EqualFold(evt.ShortCode, row.EventID) {
+				return evt
+			}
+		}
+	}
+	if row.Title == "" {
+		return nil
+	}
+	return event.FindByTitle(events, row.Title, row.State, parseImportDate(row.Date))
+}
+
+// importResult is what /import did with a CSV
+type importResult struct {
+	Matched   []importMatch
+	Unmatched []importRow
+	Skipped   int // Rows past maxImportRows
+}
+
+// importMatch is a row matched to an event and the status it was given
+type importMatch struct {
+	Row    importRow
+	Event  *event.Event
+	Status string
+}
+
+// importEvents matches rows to events and gives each matched event the row's status, or
+// status when the row has none. An event matched by several rows takes the first.
+func importEvents(user *preferences.UserPreferences, rows []importRow, events []*event.Event, status string) importResult {
+	var result importResult
+	if len(rows) > maxImportRows {
+		result.Skipped = len(rows) - maxImportRows
+		rows = rows[:maxImportRows]
+	}
+
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		evt := matchImportRow(row, events)
+		if evt == nil || seen[evt.ID] {
+			result.Unmatched = append(result.Unmatched, row)
+			continue
+		}
+		seen[evt.ID] = true
+
+		rowStatus := status
+		if preferences.IsValidEventStatus(row.Status) {
+			rowStatus = row.Status
+		}
+		if user.GetEventStatus(evt.ID) != rowStatus && user.SetEventStatus(evt.ID, rowStatus) {
+			user.IncrementEventStatus(rowStatus)
+		}
+		result.Matched = append(result.Matched, importMatch{Row: row, Event: evt, Status: rowStatus})
+	}
+	return result
+}
+
+// formatImportResult reports the matched and unmatched rows of an import
+func formatImportResult(result importResult) string {
+	var msg strings.Builder
+	total := len(result.Matched) + len(result.Unmatched)
+	msg.WriteString(fmt.Sprintf("📥 <b>Import Complete</b>\n\nMatched <b>%d</b> of %d row(s).\n", len(result.Matched), total))
+
+	if len(result.Matched) > 0 {
+		msg.WriteString("\n<b>Matched:</b>\n")
+		for i, match := range result.Matched {
+			if i == maxImportListed {
+				msg.WriteString(fmt.Sprintf("<i>...and %d more</i>\n", len(result.Matched)-maxImportListed))
+				break
+			}
+			emoji, _ := getStatusDisplay(match.Status)
+			msg.WriteString(fmt.Sprintf("%s %s - %s (%s)\n", emoji, match.Event.State, html.EscapeString(match.Event.Title), html.EscapeString(match.Event.DateText)))
+		}
+	}
+
+	if len(result.Unmatched) > 0 {
+		msg.WriteString("\n<b>Not matched:</b>\n")
+		for i, row := range result.Unmatched {
+			if i == maxImportListed {
+				msg.WriteString(fmt.Sprintf("<i>...and %d more</i>\n", len(result.Unmatched)-maxImportListed))
+				break
+			}
+			label := row.Title
+			if label == "" {
+				label = row.EventID
+			}
+			if row.Date != "" {
+				label += ", " + row.Date
+			}
+			msg.WriteString(fmt.Sprintf("• Line %d: %s\n", row.Line, html.EscapeString(label)))
+		}
+		msg.WriteString("\nRows match when the title is close to an event's and the date and state, if given, are the same. Rows for an event already matched are left out.\n")
+	}
+
+	if result.Skipped > 0 {
+		msg.WriteString(fmt.Sprintf("\n⚠️ Only the first %d rows were imported; %d more were left out.\n", maxImportRows, result.Skipped))
+	}
+	return strings.TrimRight(msg.String(), "\n")
+}
+
+// isImportCaption reports whether a file's caption is an /import command
+func isImportCaption(caption string) bool {
+	parts := strings.Fields(caption)
+	return len(parts) > 0 && normalizeCommand(parts[0]) == "/import"
+}
+
+// handleImportCSV imports the CSV file sent with an /import [status] caption, marking
+// each event it matches
+func handleImportCSV(prefs preferences.Preferences, chatID string, msg *Message, modified *bool, botToken string, dryRun bool) string {
+	if msg.Document == nil {
+		return importUsage
+	}
+
+	status := preferences.EventStatusInterested
+	if parts := strings.Fields(msg.Caption); len(parts) > 1 {
+		status = strings.ToLower(parts[1])
+		if !preferences.IsValidEventStatus(status) {
+			return "❌ Invalid status. Must be one of: interested, registered, maybe, skip"
+		}
+	}
+	if msg.Document.FileSize > maxImportBytes {
+		return fmt.Sprintf("❌ That file is too large to import (max %d KB).", maxImportBytes>>10)
+	}
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would import %s, marking matched events %s", html.EscapeString(msg.Document.FileName), status)
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return "❌ Couldn't read the file. Please try again later."
+	}
+	data, err := client.DownloadFile(botCtx, msg.Document.FileID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error downloading import file: %v\n", err)
+		return "❌ Couldn't read the file. Please try again later."
+	}
+	rows, err := parseImportCSV(data)
+	if err != nil {
+		return fmt.Sprintf("❌ Couldn't read that CSV: %s", html.EscapeString(err.Error()))
+	}
+	if len(rows) == 0 {
+		return "❌ No rows to import.\n\n" + importUsage
+	}
+
+	events, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents
+	}
+
+	result := importEvents(prefs.GetUser(chatID), rows, events, status)
+	if len(result.Matched) > 0 {
+		*modified = true
+	}
+	return formatImportResult(result)
+}
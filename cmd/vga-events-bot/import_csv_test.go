@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	t.Run("header", func(t *testing.T) {
+		data := "\ufeffState,Course,Date,Status\nnv,Wolf Creek,2026-04-18,Registered\n,,,\nCA,Torrey Pines,4/4/2026,\n"
+		rows, err := parseImportCSV([]byte(data))
+		if err != nil {
+			t.Fatalf("parseImportCSV() error: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("rows = %+v, want 2", rows)
+		}
+		want := importRow{Line: 2, Title: "Wolf Creek", Date: "2026-04-18", State: "NV", Status: "registered"}
+		if rows[0] != want {
+			t.Errorf("rows[0] = %+v, want %+v", rows[0], want)
+		}
+		if rows[1].Line != 4 || rows[1].Title != "Torrey Pines" {
+			t.Errorf("rows[1] = %+v", rows[1])
+		}
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		rows, err := parseImportCSV([]byte("Wolf Creek,Apr 18 2026,NV\nPaiute\n"))
+		if err != nil {
+			t.Fatalf("parseImportCSV() error: %v", err)
+		}
+		if len(rows) != 2 || rows[0].Date != "Apr 18 2026" || rows[0].State != "NV" || rows[1].Title != "Paiute" {
+			t.Errorf("rows = %+v", rows)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		data := "event_id,title,date,city,state,status,note,archived\nabc123,Wolf Creek,Apr 18 2026,Mesquite,NV,maybe,,false\n"
+		rows, err := parseImportCSV([]byte(data))
+		if err != nil {
+			t.Fatalf("parseImportCSV() error: %v", err)
+		}
+		if len(rows) != 1 || rows[0].EventID != "abc123" || rows[0].Status != "maybe" {
+			t.Errorf("rows = %+v", rows)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := parseImportCSV([]byte("title\n\"unterminated\n")); err == nil {
+			t.Error("expected an error for a malformed CSV")
+		}
+	})
+}
+
+func TestImportEvents(t *testing.T) {
+	events := []*event.Event{
+		{ID: "wolf", ShortCode: "NV-417", State: "NV", Title: "Wolf Creek Golf Club", DateText: "Apr 18 2026"},
+		{ID: "wolf2", State: "NV", Title: "Wolf Creek Golf Club", DateText: "May 9 2026"},
+		{ID: "torrey", State: "CA", Title: "Torrey Pines South", DateText: "Apr 4 2026"},
+		{ID: "paiute", State: "NV", Title: "Paiute Snow Mountain", DateText: "Mar 20 2026"},
+	}
+	rows := []importRow{
+		{Line: 2, Title: "Wolf Creek", Date: "5/9/2026"},
+		{Line: 3, Title: "Torrey Pines", State: "CA", Status: "registered"},
+		{Line: 4, EventID: "nv-417"},
+		{Line: 5, Title: "Pebble Beach"},
+		{Line: 6, Title: "torrey pines south"},
+	}
+
+	user := preferences.NewPreferences().GetUser("111")
+	result := importEvents(user, rows, events, preferences.EventStatusInterested)
+
+	var matched []string
+	for _, m := range result.Matched {
+		matched = append(matched, m.Event.ID+"="+m.Status)
+	}
+	if got := strings.Join(matched, ","); got != "wolf2=interested,torrey=registered,wolf=interested" {
+		t.Errorf("matched = %s", got)
+	}
+	if len(result.Unmatched) != 2 || result.Unmatched[0].Line != 5 || result.Unmatched[1].Line != 6 {
+		t.Errorf("unmatched = %+v", result.Unmatched)
+	}
+	if user.GetEventStatus("torrey") != preferences.EventStatusRegistered || user.GetEventStatus("paiute") != "" {
+		t.Errorf("statuses = %v", user.EventStatuses)
+	}
+
+	report := formatImportResult(result)
+	for _, want := range []string{"Matched <b>3</b> of 5", "✅ CA - Torrey Pines South", "• Line 5: Pebble Beach"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestImportEvents_RowLimit(t *testing.T) {
+	rows := make([]importRow, maxImportRows+5)
+	for i := range rows {
+		rows[i] = importRow{Line: i + 1, Title: "Nowhere"}
+	}
+	result := importEvents(preferences.NewPreferences().GetUser("111"), rows, nil, preferences.EventStatusInterested)
+	if result.Skipped != 5 || len(result.Unmatched) != maxImportRows {
+		t.Errorf("skipped = %d, unmatched = %d", result.Skipped, len(result.Unmatched))
+	}
+	if report := formatImportResult(result); !strings.Contains(report, "...and") || !strings.Contains(report, "5 more were left out") {
+		t.Errorf("report = %s", report)
+	}
+}
+
+func TestHandleImportCSV(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if got := handleImportCSV(prefs, "111", &Message{Caption: "/import"}, &modified, "", true); got != importUsage {
+		t.Errorf("no file: got %q", got)
+	}
+	doc := &Document{FileID: "f1", FileName: "events.csv", FileSize: 100}
+	if got := handleImportCSV(prefs, "111", &Message{Caption: "/import soon", Document: doc}, &modified, "", true); !strings.Contains(got, "Invalid status") {
+		t.Errorf("bad status: got %q", got)
+	}
+	if got := handleImportCSV(prefs, "111", &Message{Caption: "/import registered", Document: doc}, &modified, "", true); !strings.Contains(got, "[DRY RUN]") || !strings.Contains(got, "registered") {
+		t.Errorf("dry run: got %q", got)
+	}
+	big := &Document{FileID: "f2", FileName: "big.csv", FileSize: maxImportBytes + 1}
+	if got := handleImportCSV(prefs, "111", &Message{Caption: "/import", Document: big}, &modified, "", true); !strings.Contains(got, "too large") {
+		t.Errorf("large file: got %q", got)
+	}
+	if modified {
+		t.Error("nothing should be saved without an import")
+	}
+	if !isImportCaption("/Import@vga_bot registered") || isImportCaption("/note NV-417") || isImportCaption("") {
+		t.Error("isImportCaption misjudged a caption")
+	}
+}
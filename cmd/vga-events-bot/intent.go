@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/eventquery"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// maxQueryResults limits how many events a plain-text query sends
+const maxQueryResults = 10
+
+// eventQuery is a plain-text question interpreted as event filter criteria
+type eventQuery struct {
+	Filter *filter.Filter
+}
+
+// queryStopWords are words ignored when looking for a course keyword
+var queryStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "any": true, "are": true, "is": true, "there": true,
+	"what": true, "whats": true, "which": true, "when": true, "where": true, "show": true,
+	"me": true, "find": true, "list": true, "get": true, "give": true, "i": true, "can": true,
+	"play": true, "golf": true, "event": true, "events": true, "tournament": true,
+	"tournaments": true, "game": true, "games": true, "in": true, "at": true, "on": true,
+	"near": true, "for": true, "to": true, "of": true, "this": true, "next": true,
+	"coming": true, "up": true, "upcoming": true, "please": true, "do": true, "you": true,
+	"have": true, "with": true, "and": true, "or": true, "around": true, "days": true,
+	"day": true, "week": true, "weekend": true, "weekends": true, "month": true, "happening": true,
+	"today": true, "tomorrow": true, "only": true, "within": true, "hey": true, "hi": true,
+	"what's": true, "anything": true, "something": true, "going": true, "there's": true,
+}
+
+var (
+	queryPunctuation = regexp.MustCompile(`[?!.,;]+`)
+	queryNextDays    = regexp.MustCompile(`\bnext (\d{1,3}) days?\b`)
+	queryNearCity    = regexp.MustCompile(`\bnear ([a-z][a-z .'-]*?)(?:\s+(?:this|next|on|in|for|today|tomorrow|within)\b|$)`)
+	queryMonth       = regexp.MustCompile(`\b(?:in )?(january|february|march|april|june|july|august|september|october|november|december|jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)\b`)
+	queryStateCode   = regexp.MustCompile(`\b[Ii]n ([A-Z]{2})\b`)
+)
+
+// queryStateCodes are the states a question can name
+var queryStateCodes = []string{
+	"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "FL", "GA", "HI", "ID", "IL", "IN", "IA",
+	"KS", "KY", "LA", "ME", "MD", "MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ",
+	"NM", "NY", "NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VT",
+	"VA", "WA", "WV", "WI", "WY",
+}
+
+// stateNameCodes maps lowercase state names to their codes, longest names first
+// so "west virginia" wins over "virginia"
+func stateNameCodes() [][2]string {
+	pairs := make([][2]string, 0, len(queryStateCodes))
+	for _, code := range queryStateCodes {
+		pairs = append(pairs, [2]string{strings.ToLower(preferences.GetStateName(code)), code})
+	}
+
+	// Longest names first
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && len(pairs[j][0]) > len(pairs[j-1][0]); j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+	return pairs
+}
+
+// startOfDay returns midnight UTC for the given time's date
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// endOfDay returns 23:59:59 UTC for the given time's date
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, time.UTC)
+}
+
+// setDateRange sets the filter's date range to cover from..to (whole days)
+func setDateRange(f *filter.Filter, from, to time.Time) {
+	start := startOfDay(from)
+	end := endOfDay(to)
+	f.DateFrom = &start
+	f.DateTo = &end
+}
+
+// parseQueryDates recognizes relative date phrases and removes them from the text
+func parseQueryDates(text string, f *filter.Filter, now time.Time) string {
+	// Saturday of the current weekend (today if it's Saturday, yesterday if Sunday)
+	daysToSat := (int(time.Saturday) - int(now.Weekday()) + 7) % 7
+	saturday := now.AddDate(0, 0, daysToSat)
+	if now.Weekday() == time.Sunday {
+		saturday = now.AddDate(0, 0, -1)
+	}
+
+	switch {
+	case strings.Contains(text, "next weekend"):
+		setDateRange(f, saturday.AddDate(0, 0, 7), saturday.AddDate(0, 0, 8))
+		return strings.Replace(text, "next weekend", "", 1)
+	case strings.Contains(text, "this weekend"):
+		setDateRange(f, saturday, saturday.AddDate(0, 0, 1))
+		return strings.Replace(text, "this weekend", "", 1)
+	case strings.Contains(text, "next week"):
+		daysToMonday := (8 - int(now.Weekday())) % 7
+		if daysToMonday == 0 {
+			daysToMonday = 7
+		}
+		monday := now.AddDate(0, 0, daysToMonday)
+		setDateRange(f, monday, monday.AddDate(0, 0, 6))
+		return strings.Replace(text, "next week", "", 1)
+	case strings.Contains(text, "this week"):
+		sunday := now.AddDate(0, 0, (7-int(now.Weekday()))%7)
+		setDateRange(f, now, sunday)
+		return strings.Replace(text, "this week", "", 1)
+	case strings.Contains(text, "next month"):
+		first := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		setDateRange(f, first, first.AddDate(0, 1, -1))
+		return strings.Replace(text, "next month", "", 1)
+	case strings.Contains(text, "this month"):
+		last := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		setDateRange(f, now, last)
+		return strings.Replace(text, "this month", "", 1)
+	case strings.Contains(text, "tomorrow"):
+		setDateRange(f, now.AddDate(0, 0, 1), now.AddDate(0, 0, 1))
+		return strings.Replace(text, "tomorrow", "", 1)
+	case strings.Contains(text, "today"):
+		setDateRange(f, now, now)
+		return strings.Replace(text, "today", "", 1)
+	}
+
+	if m := queryNextDays.FindStringSubmatch(text); m != nil {
+		if days, err := strconv.Atoi(m[1]); err == nil && days > 0 {
+			setDateRange(f, now, now.AddDate(0, 0, days))
+			return strings.Replace(text, m[0], "", 1)
+		}
+	}
+
+	if m := queryMonth.FindStringSubmatch(text); m != nil {
+		// "may" is also a verb; only treat it as a month after "in"
+		if m[1] != "may" || strings.HasPrefix(m[0], "in ") {
+			month := m[1]
+			if month == "sept" {
+				month = "sep"
+			}
+			if from, to, err := filter.ParseDateRangeAt(month, now); err == nil {
+				f.DateFrom, f.DateTo = from, to
+				return strings.Replace(text, m[0], "", 1)
+			}
+		}
+	}
+
+	if strings.Contains(text, "weekend") {
+		f.WeekendsOnly = true
+		text = strings.Replace(text, "weekends", "", 1)
+		return strings.Replace(text, "weekend", "", 1)
+	}
+
+	return text
+}
+
+// parseEventQuery interprets a plain-text message like "any events in Nevada next weekend?".
+// Returns nil when the message doesn't look like a question about events.
+func parseEventQuery(message string, now time.Time) *eventQuery {
+	f := filter.NewFilter()
+
+	// State codes are only recognized in uppercase after "in" ("events in NV"), or as the
+	// whole question ("NV"), so words like "OK", "HI", or "ME" aren't states
+	codes := queryStateCode.FindAllStringSubmatch(message, -1)
+	if whole := strings.TrimSpace(queryPunctuation.ReplaceAllString(message, " ")); len(whole) == 2 {
+		codes = append(codes, []string{whole, whole})
+	}
+	for _, m := range codes {
+		if slices.Contains(queryStateCodes, m[1]) {
+			f.States = appendUnique(f.States, m[1])
+		}
+	}
+
+	text := " " + strings.ToLower(queryPunctuation.ReplaceAllString(message, " ")) + " "
+
+	for _, pair := range stateNameCodes() {
+		name, code := " "+pair[0]+" ", pair[1]
+		if strings.Contains(text, name) {
+			f.States = appendUnique(f.States, code)
+			text = strings.Replace(text, name, " ", 1)
+		}
+	}
+	text = strings.TrimSpace(strings.Join(strings.Fields(text), " "))
+
+	text = parseQueryDates(text, f, now)
+
+	if m := queryNearCity.FindStringSubmatch(text); m != nil {
+		if city := strings.TrimSpace(m[1]); city != "" {
+			f.Cities = append(f.Cities, strings.Title(city)) //nolint:staticcheck // simple ASCII city names
+			text = strings.Replace(text, "near "+m[1], "", 1)
+		}
+	}
+
+	// Remaining significant words become a course keyword ("championship events in CA")
+	var keywords []string
+	mentionsEvents := false
+	for _, word := range strings.Fields(text) {
+		switch word {
+		case "event", "events", "tournament", "tournaments":
+			mentionsEvents = true
+		}
+		if !queryStopWords[word] && !preferences.IsValidState(word) {
+			keywords = append(keywords, word)
+		}
+	}
+
+	if f.IsEmpty() && !mentionsEvents {
+		return nil
+	}
+	if len(keywords) > 0 && len(keywords) <= 3 {
+		f.Courses = []string{strings.Join(keywords, " ")}
+	} else if len(keywords) > 3 && f.IsEmpty() {
+		// Too many unknown words to be confident this is an event question
+		return nil
+	}
+
+	return &eventQuery{Filter: f}
+}
+
+// appendUnique appends value if it isn't already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// describeQuery renders the interpreted query for confirmation
+func describeQuery(q *eventQuery, states []string) string {
+	description := q.Filter.String()
+	if q.Filter.IsEmpty() {
+		description = "Upcoming events"
+	}
+	if len(q.Filter.States) == 0 && len(states) > 0 {
+		description += fmt.Sprintf(" | Your states: %s", strings.Join(states, ", "))
+	}
+	return html.EscapeString(description)
+}
+
+// naturalQueryFallback is the reply for plain text we couldn't interpret
+const naturalQueryFallback = `🤔 I didn't understand that.
+
+Try asking something like:
+• <i>any events in Nevada next weekend?</i>
+• <i>events near Las Vegas this month</i>
+• <i>championship events in CA</i>
+
+Or use /help to see all commands.`
+
+// handleNaturalQuery answers a plain-text question about events with a filtered search
+func handleNaturalQuery(prefs preferences.Preferences, chatID, message, botToken string, dryRun bool, modified *bool) (string, []*event.Event) {
+	query := parseEventQuery(message, clk.Now().UTC())
+	if query == nil {
+		return naturalQueryFallback, nil
+	}
+
+	// Without an explicit state, search the user's subscribed states
+	var states []string
+	if len(query.Filter.States) == 0 {
+		states = prefs.GetStates(chatID)
+		if len(states) == 0 {
+			return "🔎 <b>Interpreted as:</b> " + describeQuery(query, nil) + "\n\nYou're not subscribed to any states yet. Name a state in your question (e.g. <i>events in Nevada</i>) or use /subscribe.", nil
+		}
+	}
+
+	header := "🔎 <b>Interpreted as:</b> " + describeQuery(query, states)
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	// The question's own dates replace the user's horizon
+	q := userQuery(prefs.GetUser(chatID)).Upcoming().Sort()
+	if len(states) > 0 {
+		q = q.InStates(states)
+	}
+	matchingEvents := q.Run(query.Filter.Apply(allEvents))
+
+	if len(matchingEvents) == 0 {
+		return header + "\n\nNo matching events found. Try a wider date range or use /events.", nil
+	}
+
+	eventsToSend := matchingEvents
+	if len(eventsToSend) > maxQueryResults {
+		eventsToSend = eventsToSend[:maxQueryResults]
+	}
+
+	if dryRun {
+		return fmt.Sprintf("%s\n\n[DRY RUN] Would send %d of %d matching event(s)", header, len(eventsToSend), len(matchingEvents)), nil
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return "❌ Error sending results", nil
+	}
+
+	headerMsg := fmt.Sprintf("%s\n\nFound %d event(s), showing %d:", header, len(matchingEvents), len(eventsToSend))
+	if err := client.SendMessage(botCtx, headerMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
+	}
+
+	user := prefs.GetUser(chatID)
+	for i, evt := range eventsToSend {
+		if err := sendEventCard(client, prefs, chatID, evt, modified); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
+		}
+
+		// Rate limiting
+		if i < len(eventsToSend)-1 {
+			if telegram.Pause(botCtx, 1*time.Second) != nil {
+				break // Shutting down
+			}
+		}
+	}
+
+	// Track stats: events viewed
+	user.IncrementEventsViewed(len(eventsToSend))
+	*modified = true
+
+	return "", nil // Already sent
+}
+
+// userQuery starts an event query for the user at the bot's clock time
+func userQuery(user *preferences.UserPreferences) *eventquery.Query {
+	return eventquery.ForUser(user, clk.Now())
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEventQuery(t *testing.T) {
+	// Thursday, October 15, 2026
+	now := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		message      string
+		wantStates   []string
+		wantFrom     string
+		wantTo       string
+		wantCities   []string
+		wantCourses  []string
+		wantWeekends bool
+	}{
+		{
+			name:       "state name next weekend",
+			message:    "any events in Nevada next weekend?",
+			wantStates: []string{"NV"},
+			wantFrom:   "2026-10-24",
+			wantTo:     "2026-10-25",
+		},
+		{
+			name:       "state code this weekend",
+			message:    "events in NV this weekend",
+			wantStates: []string{"NV"},
+			wantFrom:   "2026-10-17",
+			wantTo:     "2026-10-18",
+		},
+		{
+			name:       "multi-word state preferred",
+			message:    "West Virginia tournaments",
+			wantStates: []string{"WV"},
+		},
+		{
+			name:       "near city this month",
+			message:    "events near Las Vegas this month",
+			wantCities: []string{"Las Vegas"},
+			wantFrom:   "2026-10-15",
+			wantTo:     "2026-10-31",
+		},
+		{
+			name:     "next N days",
+			message:  "events in the next 14 days",
+			wantFrom: "2026-10-15",
+			wantTo:   "2026-10-29",
+		},
+		{
+			name:     "next week",
+			message:  "what's on next week",
+			wantFrom: "2026-10-19",
+			wantTo:   "2026-10-25",
+		},
+		{
+			name:     "tomorrow",
+			message:  "anything tomorrow?",
+			wantFrom: "2026-10-16",
+			wantTo:   "2026-10-16",
+		},
+		{
+			name:        "course keyword with state",
+			message:     "championship events in CA",
+			wantStates:  []string{"CA"},
+			wantCourses: []string{"championship"},
+		},
+		{
+			name:         "weekends only",
+			message:      "weekend events in Texas",
+			wantStates:   []string{"TX"},
+			wantWeekends: true,
+		},
+		{
+			name:       "state code alone",
+			message:    "NV",
+			wantStates: []string{"NV"},
+		},
+		{
+			name:       "uppercase words that look like states",
+			message:    "OK any events in CA? HI ME",
+			wantStates: []string{"CA"},
+		},
+		{
+			name:    "plain events question",
+			message: "any events?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := parseEventQuery(tt.message, now)
+			if q == nil {
+				t.Fatalf("parseEventQuery(%q) = nil, want query", tt.message)
+			}
+			f := q.Filter
+
+			if strings.Join(f.States, ",") != strings.Join(tt.wantStates, ",") {
+				t.Errorf("States = %v, want %v", f.States, tt.wantStates)
+			}
+			if strings.Join(f.Cities, ",") != strings.Join(tt.wantCities, ",") {
+				t.Errorf("Cities = %v, want %v", f.Cities, tt.wantCities)
+			}
+			if strings.Join(f.Courses, ",") != strings.Join(tt.wantCourses, ",") {
+				t.Errorf("Courses = %v, want %v", f.Courses, tt.wantCourses)
+			}
+			if f.WeekendsOnly != tt.wantWeekends {
+				t.Errorf("WeekendsOnly = %v, want %v", f.WeekendsOnly, tt.wantWeekends)
+			}
+
+			if tt.wantFrom == "" {
+				if f.DateFrom != nil || f.DateTo != nil {
+					t.Errorf("unexpected date range %v - %v", f.DateFrom, f.DateTo)
+				}
+				return
+			}
+			if f.DateFrom == nil || f.DateTo == nil {
+				t.Fatalf("date range not set")
+			}
+			if got := f.DateFrom.Format("2006-01-02"); got != tt.wantFrom {
+				t.Errorf("DateFrom = %s, want %s", got, tt.wantFrom)
+			}
+			if got := f.DateTo.Format("2006-01-02"); got != tt.wantTo {
+				t.Errorf("DateTo = %s, want %s", got, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestParseEventQueryMonth(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+
+	q := parseEventQuery("golf in March in Arizona", now)
+	if q == nil || q.Filter.DateFrom == nil {
+		t.Fatalf("expected a month date range, got %+v", q)
+	}
+	if q.Filter.DateFrom.Month() != time.March {
+		t.Errorf("DateFrom month = %s, want March", q.Filter.DateFrom.Month())
+	}
+	if strings.Join(q.Filter.States, ",") != "AZ" {
+		t.Errorf("States = %v, want [AZ]", q.Filter.States)
+	}
+}
+
+func TestParseEventQueryMonthUsesNow(t *testing.T) {
+	// A month that has passed this year means next year's, relative to now
+	now := time.Date(2030, time.November, 1, 9, 0, 0, 0, time.UTC)
+
+	q := parseEventQuery("events in March", now)
+	if q == nil || q.Filter.DateFrom == nil {
+		t.Fatalf("expected a month date range, got %+v", q)
+	}
+	if got := q.Filter.DateFrom.Format("2006-01"); got != "2031-03" {
+		t.Errorf("DateFrom = %s, want 2031-03", got)
+	}
+}
+
+func TestParseEventQueryUnrecognized(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+
+	for _, message := range []string{"hello", "thanks!", "what is the weather like", "ok"} {
+		if q := parseEventQuery(message, now); q != nil {
+			t.Errorf("parseEventQuery(%q) = %+v, want nil", message, q.Filter)
+		}
+	}
+}
+
+func TestProcessCommandPlainTextFallback(t *testing.T) {
+	modified := false
+	response, _ := processCommand(nil, "12345", "hello there", &modified, "", true)
+	if !strings.Contains(response, "/help") {
+		t.Errorf("expected fallback pointing to /help, got %q", response)
+	}
+}
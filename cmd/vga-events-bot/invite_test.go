@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestInviteNewOnce(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("111", "NV")
+	prefs.AddState("333", "CA")
+	modified := false
+
+	oldCode := prefs.GetUser("111").GetInviteCode()
+	response := handleInvite(prefs, "111", []string{"new", "7d", "once"}, &modified)
+	code := prefs.GetUser("111").GetInviteCode()
+	if !modified || code == oldCode || !strings.Contains(response, code) || !strings.Contains(response, "Expires") {
+		t.Fatalf("/invite new 7d once: modified=%v, %q", modified, response)
+	}
+
+	if got := handleJoin(prefs, "222", oldCode, &modified, "", true); !strings.Contains(got, "Invalid invite code") {
+		t.Errorf("/join with the replaced code = %q", got)
+	}
+	if got := handleJoin(prefs, "111", code, &modified, "", true); !strings.Contains(got, "can't add yourself") {
+		t.Errorf("/join with your own code = %q", got)
+	}
+	if got := handleJoin(prefs, "222", strings.ToLower(code), &modified, "", true); !strings.Contains(got, "Request Sent") {
+		t.Fatalf("/join = %q", got)
+	}
+	if got := handleJoin(prefs, "333", code, &modified, "", true); !strings.Contains(got, "Invalid invite code") {
+		t.Errorf("single-use code used twice: %q", got)
+	}
+
+	if got := handleInvite(prefs, "111", []string{"new", "1y"}, &modified); !strings.Contains(got, "Couldn't read") {
+		t.Errorf("/invite new 1y = %q", got)
+	}
+}
+
+func TestInviteSavesMigratedCode(t *testing.T) {
+	prefs := preferences.Preferences{"123456789": {InviteCode: "456789", Active: true}}
+	modified := false
+
+	handleInvite(prefs, "123456789", nil, &modified)
+	code := prefs["123456789"].InviteCode
+	if !modified || code == "456789" {
+		t.Fatalf("/invite with a legacy code: modified=%v, code %q", modified, code)
+	}
+
+	data, err := prefs.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error: %v", err)
+	}
+	reloaded, err := preferences.FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON() error: %v", err)
+	}
+	modified = false
+	if got := handleInvite(reloaded, "123456789", nil, &modified); modified || !strings.Contains(got, code) {
+		t.Errorf("/invite after reload: modified=%v, want code %q unchanged in %q", modified, code, got)
+	}
+}
+
+func TestFriendRequestApproval(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("111", "NV")
+	code := prefs.GetUser("111").GetInviteCode()
+	modified := false
+
+	handleJoin(prefs, "222", code, &modified, "", true)
+	if prefs.GetUser("222").IsFriend("111") {
+		t.Fatal("/join shouldn't add a friend before approval")
+	}
+	if got := handleFriends(prefs, "111"); !strings.Contains(got, "Waiting for your approval") || !strings.Contains(got, "222") {
+		t.Errorf("/friends should list the pending request: %q", got)
+	}
+	if got := handleJoin(prefs, "222", code, &modified, "", true); !strings.Contains(got, "still waiting") {
+		t.Errorf("repeated /join = %q", got)
+	}
+
+	got := handleFriendRequestCallback(prefs, "111", []string{"friend", "approve", "222"}, &modified, "", true)
+	if !strings.Contains(got, "Friend Added") || !prefs.GetUser("111").IsFriend("222") || !prefs.GetUser("222").IsFriend("111") {
+		t.Fatalf("approve = %q", got)
+	}
+	if got := handleFriendRequestCallback(prefs, "111", []string{"friend", "approve", "222"}, &modified, "", true); !strings.Contains(got, "no longer pending") {
+		t.Errorf("approving twice = %q", got)
+	}
+}
+
+func TestBlockCommands(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("111", "NV")
+	code := prefs.GetUser("111").GetInviteCode()
+	modified := false
+
+	handleJoin(prefs, "222", code, &modified, "", true)
+	if got := handleFriendRequestCallback(prefs, "111", []string{"friend", "block", "222"}, &modified, "", true); !strings.Contains(got, "Blocked") {
+		t.Fatalf("block button = %q", got)
+	}
+	if len(prefs.GetUser("111").FriendRequests) != 0 {
+		t.Error("blocking should drop the pending request")
+	}
+
+	// A blocked user is told the request was sent, but it isn't recorded
+	if got := handleJoin(prefs, "222", code, &modified, "", true); !strings.Contains(got, "Request Sent") || len(prefs.GetUser("111").FriendRequests) != 0 {
+		t.Errorf("/join by a blocked user = %q", got)
+	}
+
+	if got := handleBlock(prefs, "111", nil, &modified); !strings.Contains(got, "222") {
+		t.Errorf("/block list = %q", got)
+	}
+	if got := handleBlock(prefs, "111", []string{"111"}, &modified); !strings.Contains(got, "can't block yourself") {
+		t.Errorf("/block self = %q", got)
+	}
+	if got := handleBlock(prefs, "111", []string{"<b>x</b>"}, &modified); !strings.Contains(got, "isn't a user ID") || len(prefs.GetUser("111").BlockedChatIDs) != 1 {
+		t.Errorf("/block with text that isn't a chat ID = %q", got)
+	}
+	if got := handleBlock(prefs, "111", []string{"222"}, &modified); !strings.Contains(got, "already blocked") || len(prefs.GetUser("111").BlockedChatIDs) != 1 {
+		t.Errorf("/block twice = %q", got)
+	}
+	if got := handleUnblock(prefs, "111", []string{"222"}, &modified); !strings.Contains(got, "Unblocked") {
+		t.Errorf("/unblock = %q", got)
+	}
+	if got := handleJoin(prefs, "222", code, &modified, "", true); !strings.Contains(got, "Request Sent") || len(prefs.GetUser("111").FriendRequests) != 1 {
+		t.Errorf("/join after unblocking = %q", got)
+	}
+}
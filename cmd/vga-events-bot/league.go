@@ -0,0 +1,397 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/league"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const leagueUsage = `🏆 <b>Leagues</b>
+
+/league create &lt;name&gt; - Start a season series with your friends
+/league &lt;id&gt; - Show a league's events and members
+/league &lt;id&gt; add &lt;event_ids&gt; - Add events to the series (admin)
+/league &lt;id&gt; remove &lt;event_id&gt; - Take an event out (admin)
+/league &lt;id&gt; member add|remove &lt;user_id&gt; - Change members (admin)
+/league &lt;id&gt; points 25,20,16,... - Set points by finish (admin)
+/league &lt;id&gt; delete - Delete the league (admin)
+/league &lt;id&gt; leave - Leave a league
+
+/score &lt;id&gt; &lt;event_id&gt; &lt;strokes&gt; - Record your score
+/standings [id] - Show the standings`
+
+// memberLabel names a league member for chatID: "You", or their user ID
+func memberLabel(memberID, chatID string) string {
+	if memberID == chatID {
+		return "You"
+	}
+	return fmt.Sprintf("<code>%s</code>", memberID)
+}
+
+// leagueForChat finds a league chatID runs or is a member of, or returns an error message
+func leagueForChat(prefs preferences.Preferences, chatID, id string) (preferences.LeagueRef, string) {
+	ref, ok := prefs.FindLeague(id)
+	if !ok || (ref.AdminID != chatID && !ref.League.IsMember(chatID)) {
+		return preferences.LeagueRef{}, fmt.Sprintf("❌ No league <code>%s</code>. Use /league to see yours.", html.EscapeString(id))
+	}
+	return ref, ""
+}
+
+// handleLeague lists, creates, and manages season series.
+// Format: /league [create <name> | <id> [add|remove|member|points|delete|leave ...]]
+func handleLeague(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return formatLeagues(prefs, chatID)
+	}
+	if strings.EqualFold(args[0], "create") {
+		return handleLeagueCreate(prefs, chatID, args[1:], modified)
+	}
+
+	ref, errMsg := leagueForChat(prefs, chatID, args[0])
+	if errMsg != "" {
+		return errMsg
+	}
+	l := ref.League
+	if len(args) == 1 {
+		return formatLeague(ref, chatID)
+	}
+
+	action := strings.ToLower(args[1])
+	if action == "leave" {
+		if ref.AdminID == chatID {
+			return "❌ You run this league. Use /league " + l.ID + " delete to end it."
+		}
+		l.RemoveMember(chatID)
+		*modified = true
+		return fmt.Sprintf("👋 You left <b>%s</b>; your scores were removed.", html.EscapeString(l.Name))
+	}
+	if ref.AdminID != chatID {
+		return "❌ Only the league admin can change the league."
+	}
+
+	switch action {
+	case "add":
+		if len(args) < 3 {
+			return "❌ Usage: /league " + l.ID + " add &lt;event_ids&gt;"
+		}
+		eventIDs, unknown := resolveKnownEventIDs(parseBulkEventIDs(args[2:]))
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown)
+		}
+		added, err := l.AddEvents(eventIDs)
+		if added > 0 {
+			*modified = true
+		}
+		msg := fmt.Sprintf("✅ Added %d event(s) to <b>%s</b> (%d in the series).", added, html.EscapeString(l.Name), len(l.EventIDs))
+		if errors.Is(err, league.ErrTooManyEvents) {
+			msg += fmt.Sprintf("\n\n⚠️ A series can have at most %d events.", league.MaxEvents)
+		}
+		return msg
+
+	case "remove":
+		if len(args) < 3 {
+			return "❌ Usage: /league " + l.ID + " remove &lt;event_id&gt;"
+		}
+		eventIDs, unknown := resolveEventIDs(args[2:3])
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown)
+		}
+		if !l.RemoveEvent(eventIDs[0]) {
+			return fmt.Sprintf("ℹ️ Event <code>%s</code> isn't in the series.", html.EscapeString(eventIDs[0]))
+		}
+		*modified = true
+		return fmt.Sprintf("✅ Removed event <code>%s</code> and its scores from <b>%s</b>.", html.EscapeString(eventIDs[0]), html.EscapeString(l.Name))
+
+	case "member":
+		return handleLeagueMember(prefs, chatID, l, args[2:], modified)
+
+	case "points":
+		if len(args) < 3 {
+			return "❌ Usage: /league " + l.ID + " points 25,20,16,... or /league " + l.ID + " points default"
+		}
+		if strings.EqualFold(args[2], "default") {
+			l.Points = nil
+		} else {
+			points, err := league.ParsePoints(strings.Join(args[2:], ""))
+			if err != nil {
+				return fmt.Sprintf("❌ %s\n\nGive points for 1st, 2nd, 3rd, ... separated by commas, e.g. 10,8,6,4,2,1", html.EscapeString(err.Error()))
+			}
+			l.Points = points
+		}
+		*modified = true
+		return fmt.Sprintf("✅ Points for <b>%s</b>: %s", html.EscapeString(l.Name), formatPoints(l))
+
+	case "delete":
+		prefs.GetUser(chatID).RemoveLeague(l.ID)
+		*modified = true
+		return fmt.Sprintf("🗑️ Deleted <b>%s</b>.", html.EscapeString(l.Name))
+	}
+
+	return leagueUsage
+}
+
+// handleLeagueCreate starts a league with the chat and its friends as members
+func handleLeagueCreate(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	name, errMsg := validateUserInput(strings.Join(args, " "), league.MaxNameLength, "League name")
+	if errMsg != "" {
+		return errMsg + "\n\nUsage: /league create &lt;name&gt;"
+	}
+
+	user := prefs.GetUser(chatID)
+	l, err := user.AddLeague(name, chatID, user.FriendChatIDs, clk.Now())
+	if err != nil {
+		return fmt.Sprintf("❌ You already run %d leagues. Delete one with /league &lt;id&gt; delete first.", preferences.MaxLeagues)
+	}
+	*modified = true
+
+	return fmt.Sprintf(`🏆 <b>%s</b> created (ID <code>%s</code>)
+
+Members: you and %d friend(s).
+
+Next, add the events in the series:
+/league %s add &lt;event_ids&gt;
+
+Members record scores with /score %s &lt;event_id&gt; &lt;strokes&gt;, and /standings %s shows the table.`,
+		html.EscapeString(l.Name), l.ID, len(l.Members)-1, l.ID, l.ID, l.ID)
+}
+
+// handleLeagueMember adds or removes a league member. Only the admin's friends can be added.
+func handleLeagueMember(prefs preferences.Preferences, chatID string, l *league.League, args []string, modified *bool) string {
+	if len(args) < 2 {
+		return "❌ Usage: /league " + l.ID + " member add|remove &lt;user_id&gt;"
+	}
+	memberID := args[1]
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if !prefs.GetUser(chatID).IsFriend(memberID) {
+			return fmt.Sprintf("❌ <code>%s</code> isn't one of your friends. Use /friends to see their user IDs.", html.EscapeString(memberID))
+		}
+		added, err := l.AddMember(memberID)
+		if err != nil {
+			return fmt.Sprintf("❌ A league can have at most %d members.", league.MaxMembers)
+		}
+		if !added {
+			return fmt.Sprintf("ℹ️ <code>%s</code> is already a member.", memberID)
+		}
+		*modified = true
+		return fmt.Sprintf("✅ Added <code>%s</code> to <b>%s</b>.", memberID, html.EscapeString(l.Name))
+
+	case "remove":
+		if memberID == chatID {
+			return "❌ You run this league. Use /league " + l.ID + " delete to end it."
+		}
+		if !l.RemoveMember(memberID) {
+			return fmt.Sprintf("ℹ️ <code>%s</code> isn't a member.", html.EscapeString(memberID))
+		}
+		*modified = true
+		return fmt.Sprintf("✅ Removed <code>%s</code> and their scores from <b>%s</b>.", memberID, html.EscapeString(l.Name))
+	}
+	return "❌ Usage: /league " + l.ID + " member add|remove &lt;user_id&gt;"
+}
+
+// handleScore records a member's score at a series event. The admin may record scores
+// for any member by adding their user ID.
+// Format: /score <league_id> <event_id> <strokes|clear> [user_id]
+func handleScore(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) < 3 {
+		return "❌ Usage: /score &lt;league_id&gt; &lt;event_id&gt; &lt;strokes&gt;\n\nUse clear instead of strokes to remove your score."
+	}
+	ref, errMsg := leagueForChat(prefs, chatID, args[0])
+	if errMsg != "" {
+		return errMsg
+	}
+	l := ref.League
+
+	eventIDs, unknown := resolveEventIDs(args[1:2])
+	if len(unknown) > 0 {
+		return formatUnknownCodes(unknown)
+	}
+	eventID := eventIDs[0]
+
+	score := 0
+	if !strings.EqualFold(args[2], "clear") {
+		var err error
+		if score, err = strconv.Atoi(args[2]); err != nil {
+			return fmt.Sprintf("❌ Invalid score: %s\n\nGive your total strokes, e.g. /score %s %s 84", html.EscapeString(args[2]), l.ID, html.EscapeString(args[1]))
+		}
+	}
+
+	memberID := chatID
+	if len(args) > 3 {
+		if ref.AdminID != chatID {
+			return "❌ Only the league admin can record scores for other members."
+		}
+		memberID = args[3]
+	}
+
+	switch err := l.RecordScore(eventID, memberID, score); {
+	case errors.Is(err, league.ErrNotMember):
+		return fmt.Sprintf("❌ <code>%s</code> isn't a member of <b>%s</b>.", html.EscapeString(memberID), html.EscapeString(l.Name))
+	case errors.Is(err, league.ErrNotInSeries):
+		return fmt.Sprintf("❌ Event <code>%s</code> isn't in the <b>%s</b> series. Use /league %s to see its events.", html.EscapeString(eventID), html.EscapeString(l.Name), l.ID)
+	case err != nil:
+		return fmt.Sprintf("❌ The score must be between %d and %d strokes.", league.MinScore, league.MaxScore)
+	}
+	*modified = true
+
+	label, _ := describeEvent(eventID)
+	if score == 0 {
+		return fmt.Sprintf("✅ Removed the score for %s at %s.", memberLabel(memberID, chatID), label)
+	}
+	msg := fmt.Sprintf("✅ Recorded %d for %s at %s.\n\n", score, memberLabel(memberID, chatID), label)
+	return msg + formatEventResults(l, eventID, chatID)
+}
+
+// handleStandings shows a league's standings, or one event's results with an event ID.
+// Without a league ID it shows the chat's only league, or lists them.
+// Format: /standings [league_id] [event_id]
+func handleStandings(prefs preferences.Preferences, chatID string, args []string) string {
+	if len(args) == 0 {
+		refs := prefs.LeaguesFor(chatID)
+		if len(refs) != 1 {
+			return formatLeagues(prefs, chatID)
+		}
+		return formatStandings(refs[0], chatID)
+	}
+
+	ref, errMsg := leagueForChat(prefs, chatID, args[0])
+	if errMsg != "" {
+		return errMsg
+	}
+	if len(args) > 1 {
+		eventIDs, unknown := resolveEventIDs(args[1:2])
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown)
+		}
+		if !ref.League.HasEvent(eventIDs[0]) {
+			return fmt.Sprintf("❌ Event <code>%s</code> isn't in the series.", html.EscapeString(eventIDs[0]))
+		}
+		label, _ := describeEvent(eventIDs[0])
+		return fmt.Sprintf("🏌️ <b>%s</b> at %s\n\n", html.EscapeString(ref.League.Name), label) + formatEventResults(ref.League, eventIDs[0], chatID)
+	}
+	return formatStandings(ref, chatID)
+}
+
+// formatLeagues lists the leagues chatID runs or is in
+func formatLeagues(prefs preferences.Preferences, chatID string) string {
+	refs := prefs.LeaguesFor(chatID)
+	if len(refs) == 0 {
+		return "🏆 You're not in any leagues yet.\n\n" + leagueUsage
+	}
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("🏆 <b>Your Leagues</b> (%d)\n\n", len(refs)))
+	for _, ref := range refs {
+		role := ""
+		if ref.AdminID == chatID {
+			role = " · admin"
+		}
+		msg.WriteString(fmt.Sprintf("• <b>%s</b> <code>%s</code> - %d event(s), %d member(s)%s\n",
+			html.EscapeString(ref.League.Name), ref.League.ID, len(ref.League.EventIDs), len(ref.League.Members), role))
+	}
+	msg.WriteString("\nUse /standings &lt;id&gt; for a league's table, or /league &lt;id&gt; for its events.")
+	return msg.String()
+}
+
+// formatLeague shows a league's series, members, and points
+func formatLeague(ref preferences.LeagueRef, chatID string) string {
+	l := ref.League
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("🏆 <b>%s</b> <code>%s</code>\n", html.EscapeString(l.Name), l.ID))
+	msg.WriteString(fmt.Sprintf("Admin: %s\n\n", memberLabel(ref.AdminID, chatID)))
+
+	msg.WriteString(fmt.Sprintf("<b>Series</b> (%d)\n", len(l.EventIDs)))
+	if len(l.EventIDs) == 0 {
+		msg.WriteString("No events yet.\n")
+	}
+	for i, eventID := range l.EventIDs {
+		label, _ := describeEvent(eventID)
+		msg.WriteString(fmt.Sprintf("%d. %s - %d score(s)\n", i+1, label, len(l.Scores[eventID])))
+	}
+
+	msg.WriteString(fmt.Sprintf("\n<b>Members</b> (%d)\n", len(l.Members)))
+	for _, memberID := range l.Members {
+		msg.WriteString("• " + memberLabel(memberID, chatID) + "\n")
+	}
+	msg.WriteString("\n<b>Points:</b> " + formatPoints(l))
+	return msg.String()
+}
+
+// formatPoints shows a league's points table, e.g. "1st 25 · 2nd 20 · 3rd 16"
+func formatPoints(l *league.League) string {
+	table := l.Points
+	if len(table) == 0 {
+		table = league.DefaultPoints
+	}
+	parts := make([]string, len(table))
+	for i, p := range table {
+		parts[i] = fmt.Sprintf("%s %d", ordinal(i+1), p)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatStandings shows a league's standings table
+func formatStandings(ref preferences.LeagueRef, chatID string) string {
+	l := ref.League
+	played := len(l.Scores)
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("🏆 <b>%s Standings</b>\n", html.EscapeString(l.Name)))
+	msg.WriteString(fmt.Sprintf("%d of %d event(s) scored\n\n", played, len(l.EventIDs)))
+
+	for _, s := range l.Standings() {
+		medal := fmt.Sprintf("%d.", s.Place)
+		if s.Points > 0 {
+			switch s.Place {
+			case 1:
+				medal = "🥇"
+			case 2:
+				medal = "🥈"
+			case 3:
+				medal = "🥉"
+			}
+		}
+		detail := fmt.Sprintf("%d played", s.Played)
+		if s.Wins > 0 {
+			detail += fmt.Sprintf(", %d win(s)", s.Wins)
+		}
+		msg.WriteString(fmt.Sprintf("%s %s - <b>%d</b> pts (%s)\n", medal, memberLabel(s.MemberID, chatID), s.Points, detail))
+	}
+
+	if played == 0 {
+		msg.WriteString(fmt.Sprintf("\nNo scores yet. Record yours with /score %s &lt;event_id&gt; &lt;strokes&gt;", l.ID))
+	}
+	return strings.TrimRight(msg.String(), "\n")
+}
+
+// formatEventResults lists the finishes at one series event
+func formatEventResults(l *league.League, eventID, chatID string) string {
+	results := l.Results(eventID)
+	if len(results) == 0 {
+		return "No scores yet."
+	}
+	var msg strings.Builder
+	for _, f := range results {
+		msg.WriteString(fmt.Sprintf("%s %s - %d (%d pts)\n", ordinal(f.Place), memberLabel(f.MemberID, chatID), f.Score, f.Points))
+	}
+	return strings.TrimRight(msg.String(), "\n")
+}
+
+// ordinal returns "1st", "2nd", "3rd", "4th", ...
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
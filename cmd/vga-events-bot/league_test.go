@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestLeagueCommands(t *testing.T) {
+	serveEvents(t, []*event.Event{{ID: "evt1", State: "NV"}, {ID: "evt2", State: "NV"}, {ID: "evt3", State: "NV"}})
+	prefs := preferences.NewPreferences()
+	admin := prefs.GetUser("111")
+	admin.FriendChatIDs = []string{"222"}
+	prefs.GetUser("222").FriendChatIDs = []string{"111"}
+	prefs.GetUser("333")
+	modified := false
+
+	if got := handleLeague(prefs, "111", []string{"create", "Tuesday", "Crew"}, &modified); !strings.Contains(got, "Tuesday Crew") || !modified {
+		t.Fatalf("create: %s", got)
+	}
+	id := admin.Leagues[0].ID
+
+	if got := handleLeague(prefs, "111", []string{id, "add", "evt1,evt2"}, &modified); !strings.Contains(got, "Added 2 event(s)") {
+		t.Errorf("add: %s", got)
+	}
+	if got := handleLeague(prefs, "111", []string{id, "add", "evt9"}, &modified); !strings.Contains(got, "Unknown event") || len(admin.Leagues[0].EventIDs) != 2 {
+		t.Errorf("adding an ID that isn't an event: %s", got)
+	}
+	if got := handleLeague(prefs, "222", []string{id, "add", "evt3"}, &modified); !strings.Contains(got, "Only the league admin") {
+		t.Errorf("member add: %s", got)
+	}
+	if got := handleLeague(prefs, "333", []string{id}, &modified); !strings.Contains(got, "No league") {
+		t.Errorf("outsider view: %s", got)
+	}
+	if got := handleLeague(prefs, "111", []string{id, "member", "add", "333"}, &modified); !strings.Contains(got, "isn't one of your friends") {
+		t.Errorf("add a non-friend: %s", got)
+	}
+	if got := handleLeague(prefs, "111", []string{id, "points", "10,8,6"}, &modified); !strings.Contains(got, "1st 10 · 2nd 8 · 3rd 6") {
+		t.Errorf("points: %s", got)
+	}
+
+	if got := handleScore(prefs, "111", []string{id, "evt1", "84"}, &modified); !strings.Contains(got, "Recorded 84 for You") {
+		t.Errorf("score: %s", got)
+	}
+	if got := handleScore(prefs, "222", []string{id, "evt1", "80"}, &modified); !strings.Contains(got, "1st You - 80 (10 pts)") {
+		t.Errorf("member score: %s", got)
+	}
+	if got := handleScore(prefs, "222", []string{id, "evt1", "80", "111"}, &modified); !strings.Contains(got, "Only the league admin") {
+		t.Errorf("member scoring for another: %s", got)
+	}
+	if got := handleScore(prefs, "111", []string{id, "evt9", "80"}, &modified); !strings.Contains(got, "isn't in the") {
+		t.Errorf("event outside the series: %s", got)
+	}
+	if got := handleScore(prefs, "111", []string{id, "evt1", "eighty"}, &modified); !strings.Contains(got, "Invalid score") {
+		t.Errorf("bad score: %s", got)
+	}
+
+	got := handleStandings(prefs, "222", nil)
+	if !strings.Contains(got, "1 of 2 event(s) scored") || !strings.Contains(got, "🥇 You - <b>10</b> pts") || !strings.Contains(got, "🥈 <code>111</code> - <b>8</b> pts") {
+		t.Errorf("standings: %s", got)
+	}
+	if got := handleStandings(prefs, "111", []string{id, "evt1"}); !strings.Contains(got, "2nd You - 84 (8 pts)") {
+		t.Errorf("event results: %s", got)
+	}
+
+	if got := handleLeague(prefs, "222", []string{id, "leave"}, &modified); !strings.Contains(got, "You left") {
+		t.Errorf("leave: %s", got)
+	}
+	if got := handleLeague(prefs, "111", []string{id, "delete"}, &modified); !strings.Contains(got, "Deleted") || len(admin.Leagues) != 0 {
+		t.Errorf("delete: %s", got)
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	for n, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 22: "22nd", 101: "101st", 111: "111th"} {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -1,28 +1,48 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"html"
 	"io"
+	"maps"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
 	"github.com/pfrederiksen/vga-events/internal/calendar"
+	"github.com/pfrederiksen/vga-events/internal/clock"
 	"github.com/pfrederiksen/vga-events/internal/course"
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/errs"
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/experiment"
 	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/geo"
+	"github.com/pfrederiksen/vga-events/internal/links"
 	"github.com/pfrederiksen/vga-events/internal/preferences"
-	"github.com/pfrederiksen/vga-events/internal/scraper"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/teetime"
 	"github.com/pfrederiksen/vga-events/internal/telegram"
+	"github.com/pfrederiksen/vga-events/internal/transcribe"
 )
 
 const (
 	// AllStatesCode is the special state code to match all states
 	AllStatesCode = "ALL"
+	// majorsCode subscribes to national/majors events with /subscribe majors
+	majorsCode = "majors"
 
 	// Error messages
 	errFetchingEvents      = "❌ Error fetching events. Please try again later."
@@ -36,24 +56,82 @@ var (
 	githubToken      = flag.String("github-token", os.Getenv("TELEGRAM_GITHUB_TOKEN"), "GitHub token (or env: TELEGRAM_GITHUB_TOKEN)")
 	golfCourseAPIKey = flag.String("golf-api-key", os.Getenv("GOLF_COURSE_API_KEY"), "Golf Course API key (or env: GOLF_COURSE_API_KEY)")
 	encryptionKey    = flag.String("encryption-key", os.Getenv("TELEGRAM_ENCRYPTION_KEY"), "Encryption key for sensitive data (or env: TELEGRAM_ENCRYPTION_KEY)")
+	teeTimeURL       = flag.String("tee-time-url", os.Getenv("TEE_TIME_SEARCH_URL"), "Tee-time search URL template with {course}, {city}, {state}, {date} (or env: TEE_TIME_SEARCH_URL)")
+	teeTimeAPIURL    = flag.String("tee-time-api-url", os.Getenv("TEE_TIME_API_URL"), "Tee-time availability API endpoint (or env: TEE_TIME_API_URL)")
+	teeTimeAPIKey    = flag.String("tee-time-api-key", os.Getenv("TEE_TIME_API_KEY"), "Tee-time availability API key (or env: TEE_TIME_API_KEY)")
+	transcribeURL    = flag.String("transcribe-url", os.Getenv("VGA_TRANSCRIBE_URL"), "OpenAI-compatible /audio/transcriptions endpoint for voice notes (or env: VGA_TRANSCRIBE_URL)")
+	transcribeKey    = flag.String("transcribe-api-key", os.Getenv("VGA_TRANSCRIBE_API_KEY"), "Transcription API key (or env: VGA_TRANSCRIBE_API_KEY)")
+	transcribeModel  = flag.String("transcribe-model", os.Getenv("VGA_TRANSCRIBE_MODEL"), "Transcription model (default whisper-1, or env: VGA_TRANSCRIBE_MODEL)")
+	errorDSN         = flag.String("error-dsn", os.Getenv("ERROR_REPORT_DSN"), "Sentry DSN or rollbar://token to report handler errors and panics to (or env: ERROR_REPORT_DSN)")
+	chatHashSalt     = flag.String("chat-hash-salt", os.Getenv("ERROR_REPORT_SALT"), "Secret chat IDs are hashed with in error reports, issues, and ledgers; use the same one everywhere (or env: ERROR_REPORT_SALT)")
+	adminChat        = flag.String("admin-chat", os.Getenv("TELEGRAM_ADMIN_CHAT_ID"), "Chat ID notified when a command handler panics, and sent /report messages (or env: TELEGRAM_ADMIN_CHAT_ID)")
+	reportRepo       = flag.String("report-repo", os.Getenv("VGA_REPORT_REPO"), "GitHub repository (owner/name) /report opens issues in (or env: VGA_REPORT_REPO)")
+	reportToken      = flag.String("report-github-token", os.Getenv("VGA_REPORT_GITHUB_TOKEN"), "GitHub token that can open issues in --report-repo (or env: VGA_REPORT_GITHUB_TOKEN)")
+	regionsFile      = flag.String("regions-file", os.Getenv("VGA_REGIONS_FILE"), "JSON file of extra /subscribe region presets (or env: VGA_REGIONS_FILE)")
+	dataDir          = flag.String("data-dir", os.Getenv("VGA_EVENTS_DATA_DIR"), "Snapshot directory from vga-events, keeps event short codes in sync with notifications (or env: VGA_EVENTS_DATA_DIR)")
+	apiURL           = flag.String("api-url", os.Getenv("VGA_API_URL"), "Public base URL of vga-events serve-api, shown with /api-token (or env: VGA_API_URL)")
 	dryRun           = flag.Bool("dry-run", false, "Show what would be done without making changes")
+	linkUTM          = flag.Bool("utm", os.Getenv("VGA_LINK_UTM") == "true", "Add utm_source, utm_medium, and utm_campaign to outbound links (or env: VGA_LINK_UTM=true)")
+	linkMedium       = flag.String("utm-medium", links.DefaultMedium, "utm_medium for tagged links")
+	shortenerURL     = flag.String("shortener-url", os.Getenv("VGA_SHORTENER_URL"), "Self-hosted link shortener endpoint (or env: VGA_SHORTENER_URL)")
+	shortenerToken   = flag.String("shortener-token", os.Getenv("VGA_SHORTENER_TOKEN"), "Bearer token for the link shortener (or env: VGA_SHORTENER_TOKEN)")
+	clickURL         = flag.String("click-url", os.Getenv("VGA_CLICK_URL"), "Base URL of vga-events serve-api; registration links go through its /r/ redirect to count clicks (or env: VGA_CLICK_URL)")
+	experimentName   = flag.String("experiment", os.Getenv("VGA_EXPERIMENT"), "Count button taps per variant for this A/B experiment, e.g. new-event-format (or env: VGA_EXPERIMENT)")
+	flagsFile        = flag.String("flags-file", os.Getenv("VGA_FLAGS_FILE"), "JSON file of feature flags; VGA_FLAGS overrides it, e.g. enable_web_api=25% (or env: VGA_FLAGS_FILE)")
 	loop             = flag.Bool("loop", false, "Run continuously with long polling (for real-time responses)")
 	loopDuration     = flag.Duration("loop-duration", 5*time.Hour+50*time.Minute, "Maximum duration for loop mode (default 5h50m)")
 	// Digest mode flags
-	digest     = flag.String("digest", "", "Send digest to specific chat ID (used by GitHub Actions)")
-	digestFile = flag.String("digest-file", "", "Path to digest events JSON file")
-	digestType = flag.String("digest-type", "daily", "Type of digest: daily or weekly")
+	digest         = flag.String("digest", "", "Send digest to specific chat ID (used by GitHub Actions)")
+	digestFile     = flag.String("digest-file", "", "Path to digest events JSON file (.json or .json.gz)")
+	digestType     = flag.String("digest-type", "daily", "Type of digest: daily or weekly")
+	forceDigest    = flag.Bool("force", false, "With --digest or --send-digests, send even if the --data-dir ledger shows this period's digest was already sent")
+	sendAllDigests = flag.String("send-digests", "", "Send queued digests and exit: daily or weekly for every user on that frequency, due for users whose digest time is this hour")
 	// Stats rollover flag
 	archiveWeeklyStats = flag.Bool("archive-weekly-stats", false, "Archive current week's stats to history for all users")
+	sendReports        = flag.Bool("send-scheduled-reports", false, "Send the saved-filter reports that are due for all users and exit")
+	reengage           = flag.Bool("reengage", false, "Deactivate users who didn't answer the re-engagement message in time, ask users inactive for 90+ days whether they still want notifications, and exit")
+	refreshCardsFlag   = flag.Bool("refresh-cards", false, "Edit recent event cards whose event, status, or note changed (needs --data-dir) and exit")
+	postTrendsTo       = flag.String("post-trends", "", "Post the last 30 days' trends (needs --data-dir) to this chat or channel, e.g. @vgaevents, and exit")
+	reengageSend       = flag.Bool("reengage-send", true, "With --reengage, send the re-engagement message (false only lists inactive users)")
+	archiveAfterDays   = flag.Int("archive-after-days", preferences.DefaultArchiveAfterDays, "With --archive-weekly-stats, archive statuses and notes for events this many days past (0 disables)")
+	// Command menu registration flags
+	syncCommandsFlag = flag.Bool("sync-commands", false, "Register the command list with Telegram (setMyCommands) and exit")
+	commandsChats    = flag.String("commands-chats", "", "Comma-separated chat IDs that also get hidden commands in their menu (used with --sync-commands)")
+	// Build and startup checks
+	versionFlag   = flag.Bool("version", false, "Print version information and exit")
+	selfCheckOnly = flag.Bool("self-check", false, "Check the configuration, bot token, and Gist access, then exit")
 )
 
 // Global course API client (initialized if key provided)
 var courseClient *course.Client
 
+// Global tee-time provider client (initialized if a provider is configured)
+var teeTimeClient *teetime.Client
+
+// Global transcription provider client for voice notes (initialized if configured)
+var transcriber *transcribe.Client
+
+// prefsStore saves changes spanning several users as soon as they're made (nil in
+// dry-run mode, where they're only applied in memory)
+var prefsStore preferences.Storage
+
+// clk is the bot's source of the current time for scheduled work and rate limiting;
+// tests swap in a testutil.FakeClock
+var clk clock.Clock = clock.System
+
+// botCtx is canceled when the bot is asked to shut down (SIGINT/SIGTERM). Command and
+// callback handlers are reached through the command registry, so they use it for API
+// calls instead of taking a context parameter.
+var botCtx = context.Background()
+
+// shutdownSaveTimeout bounds the final preferences save after a shutdown signal
+const shutdownSaveTimeout = 15 * time.Second
+
 type Update struct {
 	UpdateID      int                     `json:"update_id"`
 	Message       *Message                `json:"message,omitempty"`
 	CallbackQuery *telegram.CallbackQuery `json:"callback_query,omitempty"`
+	PollAnswer    *telegram.PollAnswer    `json:"poll_answer,omitempty"`
 }
 
 type Message struct {
@@ -62,6 +140,32 @@ type Message struct {
 	Chat      Chat   `json:"chat"`
 	Date      int64  `json:"date"`
 	Text      string `json:"text"`
+
+	// Photo and file messages (for note attachments); their text is in Caption
+	Caption  string      `json:"caption,omitempty"`
+	Photo    []PhotoSize `json:"photo,omitempty"`
+	Document *Document   `json:"document,omitempty"`
+	Voice    *Voice      `json:"voice,omitempty"`
+}
+
+// PhotoSize is one size of a photo; Telegram lists them smallest first
+type PhotoSize struct {
+	FileID   string `json:"file_id"`
+	FileSize int64  `json:"file_size"`
+}
+
+// Voice is a voice message
+type Voice struct {
+	FileID   string `json:"file_id"`
+	Duration int    `json:"duration"` // Seconds
+	FileSize int64  `json:"file_size"`
+}
+
+// Document is a file sent as a document
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
 }
 
 type User struct {
@@ -81,27 +185,40 @@ type RateLimiter struct {
 	requests map[string][]time.Time
 	limit    int           // max requests
 	window   time.Duration // time window
+	clock    clock.Clock
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return NewRateLimiterWithClock(limit, window, clk)
+}
+
+// NewRateLimiterWithClock creates a rate limiter that reads the time from c
+func NewRateLimiterWithClock(limit int, window time.Duration, c clock.Clock) *RateLimiter {
 	return &RateLimiter{
 		requests: make(map[string][]time.Time),
 		limit:    limit,
 		window:   window,
+		clock:    c,
 	}
 }
 
 // Allow checks if a request from the given chatID should be allowed
 func (rl *RateLimiter) Allow(chatID string) bool {
+	return rl.Check(chatID) == 0
+}
+
+// Check records a request for key and returns 0 if it's allowed, or how long until the
+// oldest request in the window expires and another would be
+func (rl *RateLimiter) Check(key string) time.Duration {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.clock.Now()
 	cutoff := now.Add(-rl.window)
 
-	// Get existing requests for this chat ID
-	timestamps := rl.requests[chatID]
+	// Get existing requests for this key
+	timestamps := rl.requests[key]
 
 	// Remove timestamps older than the window
 	var validTimestamps []time.Time
@@ -113,14 +230,14 @@ func (rl *RateLimiter) Allow(chatID string) bool {
 
 	// Check if limit exceeded
 	if len(validTimestamps) >= rl.limit {
-		return false
+		return validTimestamps[0].Sub(cutoff)
 	}
 
 	// Add current timestamp and update
 	validTimestamps = append(validTimestamps, now)
-	rl.requests[chatID] = validTimestamps
+	rl.requests[key] = validTimestamps
 
-	return true
+	return 0
 }
 
 // CleanupOldEntries removes expired entries to prevent memory growth
@@ -128,7 +245,7 @@ func (rl *RateLimiter) CleanupOldEntries() {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.clock.Now()
 	cutoff := now.Add(-rl.window)
 
 	for chatID, timestamps := range rl.requests {
@@ -146,8 +263,14 @@ func (rl *RateLimiter) CleanupOldEntries() {
 	}
 }
 
-// processUpdate handles a single Telegram update (message or callback) with rate limiting
+// processUpdate handles a single Telegram update (message or callback) with rate limiting.
+// A panic in a handler is recovered and reported so the remaining updates still run.
 func processUpdate(update Update, prefs preferences.Preferences, prefsModified *bool, botToken string, dryRun bool, rateLimiter *RateLimiter) {
+	defer recoverUpdate(update, botToken, dryRun)
+
+	currentReport = updateReportContext(update)
+	defer func() { currentReport = errreport.Context{} }()
+
 	if update.CallbackQuery != nil {
 		// Handle callback query (button press)
 		chatID := fmt.Sprintf("%d", update.CallbackQuery.From.ID)
@@ -159,7 +282,11 @@ func processUpdate(update Update, prefs preferences.Preferences, prefsModified *
 			return
 		}
 
+		recordInteraction(prefs, chatID, prefsModified)
 		handleCallbackQuery(prefs, update.CallbackQuery, prefsModified, botToken, dryRun)
+	} else if update.PollAnswer != nil {
+		// Vote in a /poll; nothing is sent back
+		handlePollAnswer(prefs, update.PollAnswer, prefsModified)
 	} else if update.Message != nil {
 		// Handle text message
 		chatID := fmt.Sprintf("%d", update.Message.Chat.ID)
@@ -174,6 +301,26 @@ func processUpdate(update Update, prefs preferences.Preferences, prefsModified *
 			return
 		}
 
+		recordInteraction(prefs, chatID, prefsModified)
+
+		// A voice message is transcribed into (or kept with) the note just opened
+		if update.Message.Voice != nil {
+			sendResponse(botToken, chatID, handleVoiceNote(prefs, chatID, update.Message, prefsModified, botToken, dryRun), nil, dryRun)
+			return
+		}
+
+		// A CSV sent with an /import caption marks the events it lists
+		if update.Message.Document != nil && isImportCaption(update.Message.Caption) {
+			sendResponse(botToken, chatID, handleImportCSV(prefs, chatID, update.Message, prefsModified, botToken, dryRun), nil, dryRun)
+			return
+		}
+
+		// A photo or file is attached to the note named in its caption
+		if len(update.Message.Photo) > 0 || update.Message.Document != nil {
+			sendResponse(botToken, chatID, handleNoteAttachment(prefs, chatID, update.Message, prefsModified), nil, dryRun)
+			return
+		}
+
 		// Parse command
 		response, initialEvents := processCommand(prefs, chatID, text, prefsModified, botToken, dryRun)
 
@@ -182,6 +329,15 @@ func processUpdate(update Update, prefs preferences.Preferences, prefsModified *
 	}
 }
 
+// recordInteraction notes that an existing user was active, which also answers a
+// re-engagement message and reactivates a user deactivated for not answering one
+func recordInteraction(prefs preferences.Preferences, chatID string, modified *bool) {
+	if user, ok := prefs[chatID]; ok && user.RecordInteraction(clk.Now()) {
+		*modified = true
+	}
+	recordCohorts(prefs, chatID, modified)
+}
+
 // validateUserInput validates and sanitizes user-provided text input
 // Returns (sanitized text, error message)
 func validateUserInput(input string, maxLength int, fieldName string) (string, string) {
@@ -217,11 +373,67 @@ func validateUserInput(input string, maxLength int, fieldName string) (string, s
 func main() {
 	flag.Parse()
 
+	if *versionFlag {
+		fmt.Print(buildinfo.Get().String("vga-events-bot"))
+		os.Exit(0)
+	}
+	if *chatHashSalt == "" {
+		fmt.Fprintln(os.Stderr, "Warning: ERROR_REPORT_SALT is not set; hashed chat IDs in reports and ledgers can be reversed")
+	}
+	errreport.SetChatIDKey(*chatHashSalt)
+	// Library code that doesn't take the time reads the same clock
+	preferences.SetClock(clk)
+	event.SetClock(clk)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	botCtx = ctx
+	links.Configure(links.Options{UTM: *linkUTM, Medium: *linkMedium, ShortenerURL: *shortenerURL, ShortenerToken: *shortenerToken, RedirectURL: *clickURL})
+
 	if *botToken == "" {
 		fmt.Fprintf(os.Stderr, "Error: bot token is required (use --bot-token or TELEGRAM_BOT_TOKEN env var)\n")
 		os.Exit(1)
 	}
 
+	if *regionsFile != "" {
+		if err := preferences.LoadRegions(*regionsFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading regions: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *experimentName != "" {
+		exp, err := experiment.Lookup(*experimentName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		activeExperiment = exp
+	}
+
+	loadedFeatures, err := flags.Load(*flagsFile, os.Getenv("VGA_FLAGS"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading feature flags: %v\n", err)
+		os.Exit(1)
+	}
+	features = loadedFeatures
+	logFeatures()
+
+	// Command sync mode: only needs the bot token
+	if *syncCommandsFlag {
+		var chatIDs []string
+		for _, id := range strings.Split(*commandsChats, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				chatIDs = append(chatIDs, id)
+			}
+		}
+		if err := syncCommands(ctx, *botToken, chatIDs, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "Error syncing commands: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if *gistID == "" {
 		fmt.Fprintf(os.Stderr, "Error: gist ID is required (use --gist-id or TELEGRAM_GIST_ID env var)\n")
 		os.Exit(1)
@@ -241,11 +453,93 @@ func main() {
 	if *encryptionKey != "" {
 		fmt.Println("Encryption enabled for sensitive data")
 	}
+	if !*dryRun {
+		prefsStore = storage
+	}
+	aliasStore = storage
+
+	// Fail fast on a bad token or Gist rather than erroring on every update
+	bot, err := telegram.NewBotClient(*botToken)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	check := selfCheck(ctx, bot, storage)
+	for _, warning := range check.warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	for _, problem := range check.problems {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", problem)
+	}
+	if len(check.problems) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("vga-events-bot %s", buildinfo.Get().Short())
+	if check.botName != "" {
+		fmt.Printf(" running as %s", check.botName)
+	}
+	fmt.Println()
+	if *selfCheckOnly {
+		if len(check.warnings) > 0 {
+			fmt.Println("Self-check incomplete: see the warnings above")
+			os.Exit(1)
+		}
+		fmt.Println("Self-check passed")
+		os.Exit(0)
+	}
 
 	// Initialize Golf Course API client if key is provided
 	if *golfCourseAPIKey != "" {
 		courseClient = course.NewClient(*golfCourseAPIKey)
 		fmt.Println("Golf Course API enabled")
+
+		// Maintainer aliases fix titles the fuzzy match gets wrong
+		if _, err := loadCourseAliases(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Initialize tee-time provider if configured
+	if *teeTimeURL != "" || *teeTimeAPIURL != "" {
+		teeTimeClient = teetime.NewClient(teetime.Config{
+			SearchURL:       *teeTimeURL,
+			AvailabilityURL: *teeTimeAPIURL,
+			APIKey:          *teeTimeAPIKey,
+		})
+		fmt.Println("Tee-time provider enabled")
+	}
+
+	// Transcribe voice notes if a provider is configured; otherwise they're kept as audio
+	if *transcribeURL != "" {
+		transcriber = transcribe.NewClient(transcribe.Config{
+			URL:    *transcribeURL,
+			APIKey: *transcribeKey,
+			Model:  *transcribeModel,
+		})
+		fmt.Println("Voice note transcription enabled")
+	}
+
+	// Report errors to Sentry/Rollbar if configured
+	errReporter, err = errreport.New(errreport.Options{DSN: *errorDSN, Component: "bot", Release: buildinfo.Get().Short()})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: error reporting disabled: %v\n", err)
+	}
+
+	// Report handler panics to the admin chat if one is configured
+	if *adminChat != "" {
+		reporter, err := newPanicReporter(*botToken, *adminChat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not set up panic reports: %v\n", err)
+		} else {
+			panicReports = reporter
+		}
+	}
+
+	// Use saved snapshot short codes if a data directory is configured
+	if *dataDir != "" {
+		if err := initSnapshotStore(*dataDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not open data directory: %v\n", err)
+		}
 	}
 
 	// Digest mode: send digest and exit
@@ -254,41 +548,90 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: --digest-file is required when using --digest\n")
 			os.Exit(1)
 		}
-		sendDigest(*botToken, *digest, *digestFile, *digestType)
+		sendDigest(ctx, *botToken, *digest, *digestFile, *digestType)
 		os.Exit(0)
 	}
 
 	// Load preferences
-	prefs, err := storage.Load()
+	var prefs preferences.Preferences
+	err = errs.DefaultBackoff.Retry(ctx, func() error {
+		var loadErr error
+		prefs, loadErr = storage.Load(ctx)
+		return loadErr
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading preferences: %v\n", err)
+		errReporter.Error(ctx, err, errreport.Context{Command: "load preferences"})
 		os.Exit(1)
 	}
 
+	// Digests mode: send the queued digests and exit
+	if *sendAllDigests != "" {
+		if *sendAllDigests != preferences.DigestFrequencyDaily && *sendAllDigests != preferences.DigestFrequencyWeekly && *sendAllDigests != "due" {
+			fmt.Fprintf(os.Stderr, "Error: --send-digests must be daily, weekly, or due\n")
+			os.Exit(1)
+		}
+		sendDigests(ctx, prefs, storage, *botToken, *sendAllDigests, *dryRun)
+		os.Exit(0)
+	}
+
 	// Archive weekly stats mode: archive stats and exit
 	if *archiveWeeklyStats {
-		archiveWeeklyStatsForAllUsers(prefs, storage)
+		archiveWeeklyStatsForAllUsers(ctx, prefs, storage)
+		os.Exit(0)
+	}
+
+	// Scheduled reports mode: send due saved-filter reports and exit
+	if *sendReports {
+		sendScheduledReports(ctx, prefs, storage, *botToken, *dryRun)
+		os.Exit(0)
+	}
+
+	// Card refresh mode: edit recent event cards that changed and exit
+	if *refreshCardsFlag {
+		refreshEventCards(ctx, prefs, storage, *botToken, *dryRun)
+		os.Exit(0)
+	}
+
+	// Trends mode: post the monthly trends report and exit
+	if *postTrendsTo != "" {
+		postTrends(ctx, prefs, *botToken, *postTrendsTo, *dryRun)
+		os.Exit(0)
+	}
+
+	// Re-engagement mode: ask inactive users to confirm, deactivate non-responders, and exit
+	if *reengage {
+		runReengagement(ctx, prefs, storage, *botToken, *dryRun, *reengageSend)
 		os.Exit(0)
 	}
 
 	fmt.Printf("Loaded preferences for %d users\n", len(prefs))
+	logPreferencesSize(prefs)
 
 	// Initialize rate limiter: 10 commands per minute per user
 	rateLimiter := NewRateLimiter(10, time.Minute)
+	// Scraping and export commands are also limited per command; the admin chat isn't
+	cooldowns = newCommandCooldowns(*adminChat)
 
 	// Start cleanup goroutine to prevent memory growth
 	go func() {
 		ticker := time.NewTicker(5 * time.Minute)
 		defer ticker.Stop()
-		for range ticker.C {
-			rateLimiter.CleanupOldEntries()
+		for {
+			select {
+			case <-ticker.C:
+				rateLimiter.CleanupOldEntries()
+				cooldowns.cleanup()
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
 	if *loop {
-		runLoop(storage, prefs, *botToken, *dryRun, *loopDuration, rateLimiter)
+		runLoop(ctx, storage, prefs, *botToken, *dryRun, *loopDuration, rateLimiter)
 	} else {
-		runOnce(storage, prefs, *botToken, *dryRun, rateLimiter)
+		runOnce(ctx, storage, prefs, *botToken, *dryRun, rateLimiter)
 	}
 }
 
@@ -302,15 +645,25 @@ func sendResponse(botToken, chatID, response string, initialEvents []*event.Even
 		return
 	}
 
+	// Commands that send their own keyboard message return no response
+	if response == "" && len(initialEvents) == 0 {
+		return
+	}
+
 	// Send response
-	tempClient, err := telegram.NewClient(botToken, chatID)
+	tempClient, err := newSender(botToken, chatID)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating client for chat %s: %v\n", chatID, err)
 		return
 	}
 
-	if err := tempClient.SendMessage(response); err != nil {
+	if err := tempClient.SendMessage(botCtx, response); err != nil {
 		fmt.Fprintf(os.Stderr, "Error sending response to %s: %v\n", chatID, err)
+		if errs.IsPermanent(err) {
+			// The chat is gone or has blocked the bot; the initial events won't get through either
+			return
+		}
+		reportError(fmt.Errorf("sending response: %w", err), "")
 	} else {
 		fmt.Printf("Sent response to %s\n", chatID)
 	}
@@ -320,22 +673,48 @@ func sendResponse(botToken, chatID, response string, initialEvents []*event.Even
 		fmt.Printf("Sending %d initial events to %s...\n", len(initialEvents), chatID)
 		for i, evt := range initialEvents {
 			msg := telegram.FormatEvent(evt)
-			if err := tempClient.SendMessage(msg); err != nil {
+			if err := tempClient.SendMessage(botCtx, msg); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending initial event to %s: %v\n", chatID, err)
 			}
 			// Rate limiting
 			if i < len(initialEvents)-1 {
-				time.Sleep(1 * time.Second)
+				if err := telegram.Pause(botCtx, 1*time.Second); err != nil {
+					fmt.Fprintf(os.Stderr, "Stopped sending initial events to %s: %v\n", chatID, err)
+					return
+				}
 			}
 		}
 		fmt.Printf("Sent initial events to %s\n", chatID)
 	}
 }
 
-func runLoop(storage *preferences.GistStorage, prefs preferences.Preferences, botToken string, dryRun bool, duration time.Duration, rateLimiter *RateLimiter) {
+// savePreferences saves prefs to the Gist. If ctx was canceled by a shutdown signal the
+// save still gets a short grace period, so changes made before the signal aren't lost.
+func savePreferences(ctx context.Context, storage *preferences.GistStorage, prefs preferences.Preferences) error {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), shutdownSaveTimeout)
+		defer cancel()
+	}
+	return errs.DefaultBackoff.Retry(ctx, func() error {
+		return storage.Save(ctx, prefs)
+	})
+}
+
+// commitUsers applies a change to several users and saves it right away, rolling the
+// users back if it can't be saved (see preferences.UnitOfWork)
+func commitUsers(prefs preferences.Preferences, chatIDs []string, change func(preferences.Preferences) error) error {
+	if prefsStore == nil {
+		return change(prefs)
+	}
+	return preferences.NewUnitOfWork(prefsStore).Apply(botCtx, prefs, chatIDs, change)
+}
+
+func runLoop(ctx context.Context, storage *preferences.GistStorage, prefs preferences.Preferences, botToken string, dryRun bool, duration time.Duration, rateLimiter *RateLimiter) {
 	fmt.Printf("Starting long polling loop (will run for %v)...\n", duration)
 	startTime := time.Now()
 	offset := 0
+	shared := preferences.NewShared(prefs)
 
 	for {
 		// Check if we've exceeded our time limit
@@ -343,27 +722,47 @@ func runLoop(storage *preferences.GistStorage, prefs preferences.Preferences, bo
 			fmt.Printf("Reached time limit (%v), exiting gracefully...\n", duration)
 			break
 		}
+		if ctx.Err() != nil {
+			fmt.Println("Shutdown requested, exiting gracefully...")
+			break
+		}
 
 		// Get updates with long polling (30 second timeout)
-		updates, err := getUpdatesWithTimeout(botToken, offset, 30)
+		updates, err := getUpdatesWithTimeout(ctx, botToken, offset, 30)
 		if err != nil {
+			if ctx.Err() != nil {
+				continue // Shutting down; the check above ends the loop
+			}
 			fmt.Fprintf(os.Stderr, "Error getting updates: %v\n", err)
-			time.Sleep(5 * time.Second) // Brief pause before retrying
+			if errors.Is(err, errs.ErrAuth) || errors.Is(err, errs.ErrNotFound) {
+				// Telegram answers 401/404 for a revoked or mistyped token; polling again won't help
+				fmt.Fprintln(os.Stderr, "Bot token was rejected, stopping")
+				break
+			}
+			_ = telegram.Pause(ctx, 5*time.Second) // Brief pause before retrying
 			continue
 		}
 
-		if len(updates) == 0 {
+		// Pauses that ran out get their catch-up digest
+		var prefsModified bool
+		shared.UpdateAll(func(prefs preferences.Preferences) {
+			prefsModified = deliverEndedPauses(prefs, botToken, dryRun)
+		})
+
+		if len(updates) == 0 && !prefsModified {
 			// No new messages, continue polling
 			continue
 		}
 
-		fmt.Printf("Processing %d message(s)...\n", len(updates))
-
-		prefsModified := false
+		if len(updates) > 0 {
+			fmt.Printf("Processing %d message(s)...\n", len(updates))
+		}
 
 		// Process each update
 		for _, update := range updates {
-			processUpdate(update, prefs, &prefsModified, botToken, dryRun, rateLimiter)
+			shared.UpdateAll(func(prefs preferences.Preferences) {
+				processUpdate(update, prefs, &prefsModified, botToken, dryRun, rateLimiter)
+			})
 
 			// Update offset to mark this update as processed
 			if update.UpdateID >= offset {
@@ -376,8 +775,14 @@ func runLoop(storage *preferences.GistStorage, prefs preferences.Preferences, bo
 			if dryRun {
 				fmt.Println("[DRY RUN] Would save updated preferences to Gist")
 			} else {
-				if err := storage.Save(prefs); err != nil {
+				// Save a copy so handlers aren't blocked while it's written
+				snapshot, err := shared.Snapshot()
+				if err == nil {
+					err = savePreferences(ctx, storage, snapshot)
+				}
+				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+					errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
 				} else {
 					fmt.Println("Preferences saved successfully")
 				}
@@ -385,25 +790,37 @@ func runLoop(storage *preferences.GistStorage, prefs preferences.Preferences, bo
 		}
 	}
 
+	// After a shutdown signal, acknowledge processed updates so the next run doesn't
+	// handle them again (the HTTP client timeout bounds this call)
+	if offset > 0 && !dryRun && ctx.Err() != nil {
+		if _, err := getUpdates(context.WithoutCancel(ctx), botToken, offset); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to acknowledge processed messages: %v\n", err)
+		}
+	}
+
 	fmt.Println("Long polling loop completed")
 }
 
-func runOnce(storage *preferences.GistStorage, prefs preferences.Preferences, botToken string, dryRun bool, rateLimiter *RateLimiter) {
+func runOnce(ctx context.Context, storage *preferences.GistStorage, prefs preferences.Preferences, botToken string, dryRun bool, rateLimiter *RateLimiter) {
 	// Get updates from Telegram
-	updates, err := getUpdates(botToken, 0)
+	updates, err := getUpdates(ctx, botToken, 0)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting updates: %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(updates) == 0 {
+	// Pauses that ran out get their catch-up digest
+	prefsModified := deliverEndedPauses(prefs, botToken, dryRun)
+
+	if len(updates) == 0 && !prefsModified {
 		fmt.Println("No new messages to process")
 		os.Exit(0)
 	}
 
-	fmt.Printf("Processing %d message(s)...\n", len(updates))
+	if len(updates) > 0 {
+		fmt.Printf("Processing %d message(s)...\n", len(updates))
+	}
 
-	prefsModified := false
 	maxUpdateID := 0
 
 	// Process each update
@@ -423,8 +840,9 @@ func runOnce(storage *preferences.GistStorage, prefs preferences.Preferences, bo
 			prefsJSON, _ := prefs.ToJSON()
 			fmt.Printf("Updated preferences:\n%s\n", string(prefsJSON))
 		} else {
-			if err := storage.Save(prefs); err != nil {
+			if err := savePreferences(ctx, storage, prefs); err != nil {
 				fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+				errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
 				os.Exit(1)
 			}
 			fmt.Println("Preferences saved successfully")
@@ -436,7 +854,7 @@ func runOnce(storage *preferences.GistStorage, prefs preferences.Preferences, bo
 	// Acknowledge processed messages by calling getUpdates with next offset
 	// This prevents reprocessing the same messages on the next run
 	if maxUpdateID > 0 && !dryRun {
-		_, err := getUpdates(botToken, maxUpdateID+1)
+		_, err := getUpdates(context.WithoutCancel(ctx), botToken, maxUpdateID+1)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to acknowledge processed messages: %v\n", err)
 		} else {
@@ -457,23 +875,18 @@ func handlePreviewCallback(prefs preferences.Preferences, callback *telegram.Cal
 	countStr := parts[2]
 
 	// Fetch current events for this state
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return "❌ Error fetching events"
 	}
 
-	// Filter events by state and sort by date (soonest first)
-	var stateEvents []*event.Event
-	for _, evt := range allEvents {
-		if state == AllStatesCode || strings.EqualFold(evt.State, state) {
-			stateEvents = append(stateEvents, evt)
-		}
-	}
-
-	// Sort by date (soonest first)
-	event.SortByDate(stateEvents)
+	// All of the state's events are marked seen; those within the user's time filters
+	// are shown, soonest first
+	callbackChatID := fmt.Sprintf("%d", callback.From.ID)
+	user := prefs.GetUser(callbackChatID)
+	stateEvents := userQuery(user).InStates([]string{state}).Run(allEvents)
+	shownEvents := userQuery(user).HidePast().Horizon().Sort().Run(stateEvents)
 
 	// Determine how many to send
 	var eventsToSend []*event.Event
@@ -483,23 +896,21 @@ func handlePreviewCallback(prefs preferences.Preferences, callback *telegram.Cal
 		// User chose not to see events
 		responseText = "✅ Got it! You'll only be notified about new events going forward."
 	} else if countStr == "all" {
-		eventsToSend = stateEvents
+		eventsToSend = shownEvents
 	} else {
 		count := 0
 		if _, err := fmt.Sscanf(countStr, "%d", &count); err == nil {
-			if count > 0 && count < len(stateEvents) {
-				eventsToSend = stateEvents[:count]
+			if count > 0 && count < len(shownEvents) {
+				eventsToSend = shownEvents[:count]
 			} else {
-				eventsToSend = stateEvents
+				eventsToSend = shownEvents
 			}
 		} else {
-			eventsToSend = stateEvents // Default to all if parsing fails
+			eventsToSend = shownEvents // Default to all if parsing fails
 		}
 	}
 
 	// Mark ALL state events as seen (not just the ones we're sending)
-	callbackChatID := fmt.Sprintf("%d", callback.From.ID)
-	user := prefs.GetUser(callbackChatID)
 	for _, evt := range stateEvents {
 		user.MarkEventSeen(evt.ID)
 	}
@@ -507,23 +918,21 @@ func handlePreviewCallback(prefs preferences.Preferences, callback *telegram.Cal
 
 	// Send the requested events
 	if len(eventsToSend) > 0 && !dryRun {
-		client, err := telegram.NewClient(botToken, callbackChatID)
+		client, err := newSender(botToken, callbackChatID)
 		if err != nil {
 			return fmt.Sprintf("❌ Error sending events: %v", err)
 		}
 
 		for i, evt := range eventsToSend {
-			user := prefs.GetUser(callbackChatID)
-			currentStatus := user.GetEventStatus(evt.ID)
-			note := user.GetEventNote(evt.ID)
-			msg, keyboard := telegram.FormatEventWithStatusAndNote(evt, currentStatus, note, callbackChatID, prefs)
-			if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
+			if err := sendEventCard(client, prefs, callbackChatID, evt, modified); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
 			}
 
 			// Rate limiting
 			if i < len(eventsToSend)-1 {
-				time.Sleep(1 * time.Second)
+				if telegram.Pause(botCtx, 1*time.Second) != nil {
+					break // Shutting down
+				}
 			}
 		}
 
@@ -535,7 +944,7 @@ func handlePreviewCallback(prefs preferences.Preferences, callback *telegram.Cal
 	return responseText
 }
 
-func handleUpcomingEventsCallback(prefs preferences.Preferences, chatID string, botToken string, dryRun bool) string {
+func handleUpcomingEventsCallback(prefs preferences.Preferences, chatID string, botToken string, dryRun bool, modified *bool) string {
 	// Get user's subscribed states
 	states := prefs.GetStates(chatID)
 	if len(states) == 0 {
@@ -547,23 +956,14 @@ Use /subscribe to start receiving event notifications!`
 	}
 
 	// Fetch current events
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return errFetchingEvents
 	}
 
-	// Filter events by subscribed states
-	var filteredEvents []*event.Event
-	for _, evt := range allEvents {
-		for _, state := range states {
-			if state == AllStatesCode || strings.EqualFold(evt.State, state) {
-				filteredEvents = append(filteredEvents, evt)
-				break
-			}
-		}
-	}
+	// The same events as /events, soonest first
+	filteredEvents := userQuery(prefs.GetUser(chatID)).InStates(states).HidePast().Horizon().ApplyFilter().Sort().Run(allEvents)
 
 	if len(filteredEvents) == 0 {
 		return fmt.Sprintf(`📅 <b>No Upcoming Events</b>
@@ -573,9 +973,6 @@ No events found for your subscribed states: %s
 Check back later or subscribe to more states with /subscribe`, strings.Join(states, ", "))
 	}
 
-	// Sort by date (soonest first)
-	event.SortByDate(filteredEvents)
-
 	// Limit to 10 events
 	eventsToSend := filteredEvents
 	if len(eventsToSend) > 10 {
@@ -584,7 +981,7 @@ Check back later or subscribe to more states with /subscribe`, strings.Join(stat
 
 	// Send events with calendar buttons
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return "❌ Error sending events"
 		}
@@ -596,23 +993,21 @@ Showing %d of %d events for %s
 
 Sorted by soonest first:`, len(eventsToSend), len(filteredEvents), strings.Join(states, ", "))
 
-		if err := client.SendMessage(headerMsg); err != nil {
+		if err := client.SendMessage(botCtx, headerMsg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
 		}
 
 		// Send each event with calendar button
 		for i, evt := range eventsToSend {
-			user := prefs.GetUser(chatID)
-			currentStatus := user.GetEventStatus(evt.ID)
-			note := user.GetEventNote(evt.ID)
-			msg, keyboard := telegram.FormatEventWithStatusAndNote(evt, currentStatus, note, chatID, prefs)
-			if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
+			if err := sendEventCard(client, prefs, chatID, evt, modified); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
 			}
 
 			// Rate limiting
 			if i < len(eventsToSend)-1 {
-				time.Sleep(1 * time.Second)
+				if telegram.Pause(botCtx, 1*time.Second) != nil {
+					break // Shutting down
+				}
 			}
 		}
 
@@ -626,8 +1021,10 @@ Sorted by soonest first:`, len(eventsToSend), len(filteredEvents), strings.Join(
 func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.CallbackQuery, modified *bool, botToken string, dryRun bool) {
 	chatID := fmt.Sprintf("%d", callback.From.ID)
 	messageID := 0
+	messageChatID := chatID // Differs from chatID for buttons in group chats
 	if callback.Message != nil {
 		messageID = callback.Message.MessageID
+		messageChatID = fmt.Sprintf("%d", callback.Message.Chat.ID)
 	}
 
 	fmt.Printf("Callback from %s (chat %s): %s\n", callback.From.FirstName, chatID, callback.Data)
@@ -644,6 +1041,8 @@ func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.Callb
 		param = parts[1]
 	}
 
+	recordExperimentTap(prefs, chatID, action, parts, modified)
+
 	var responseText string
 	var keyboard *telegram.InlineKeyboardMarkup
 
@@ -653,9 +1052,20 @@ func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.Callb
 			responseText, _ = handleSubscribe(prefs, chatID, param, modified, botToken, dryRun)
 		} else {
 			// Show state selection keyboard
-			responseText, keyboard = showStateSelectionKeyboard()
+			responseText, keyboard = showStateSelectionKeyboard(prefs, chatID, 0)
 		}
 
+	case "unfollow":
+		// Unfollow a course from the /following list
+		// Format: unfollow:INDEX
+		responseText, keyboard = handleUnfollowCallback(param, prefs, chatID, modified)
+
+	case "states":
+		// Page through the state selection keyboard
+		// Format: states:PAGE
+		page, _ := strconv.Atoi(param)
+		responseText, keyboard = showStateSelectionKeyboard(prefs, chatID, page)
+
 	case "unsubscribe":
 		responseText = handleUnsubscribe(prefs, chatID, param, modified)
 
@@ -679,16 +1089,18 @@ func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.Callb
 		responseText, keyboard = showManageSubscriptionsKeyboard(prefs, chatID)
 
 	case "settings":
-		responseText, keyboard = showSettingsKeyboard(prefs, chatID)
+		// Settings menu pages and the changes made on them
+		// Format: settings[:PAGE] | settings:toggle:SETTING | settings:days|hour|day:VALUE
+		responseText, keyboard = handleSettingsCallback(prefs, chatID, parts, modified)
 
 	case "digest":
-		if user := prefs.GetUser(chatID); user != nil {
-			if user.SetDigestFrequency(param) {
-				*modified = true
-				responseText = fmt.Sprintf("✅ Digest frequency updated to <b>%s</b>", param)
-			} else {
-				responseText = "❌ Invalid digest frequency"
-			}
+		// Format: digest:immediate|daily|weekly
+		if user := prefs.GetUser(chatID); user.SetDigestFrequency(param) {
+			*modified = true
+			responseText, keyboard = showSettingsPage(prefs, chatID, settingsPageNotify)
+			responseText = fmt.Sprintf("✅ Digest frequency updated to <b>%s</b>\n\n", param) + responseText
+		} else {
+			responseText = "❌ Invalid digest frequency"
 		}
 
 	case "preview":
@@ -698,6 +1110,14 @@ func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.Callb
 		// Handle menu actions
 		responseText, keyboard = handleMenuCallback(param, prefs, chatID, botToken, dryRun, modified)
 
+	case "refresh":
+		// Re-render an event card from the current listing, tracking it if it's in the user's own chat
+		cardID := 0
+		if messageChatID == chatID {
+			cardID = messageID
+		}
+		responseText, keyboard = handleRefreshCallback(prefs, chatID, param, cardID, modified)
+
 	case "status":
 		// Handle event status update
 		responseText = handleStatusCallback(callback.Data, prefs, chatID, modified)
@@ -712,9 +1132,64 @@ func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.Callb
 
 	case "bulk":
 		// Handle bulk actions
-		// Format: bulk:ACTION (e.g., "bulk:clear-skipped", "bulk:export-registered")
+		// Format: bulk:ACTION (e.g., "bulk:clear-skipped", "bulk:export-registered", "bulk:clear-maybe")
 		responseText, keyboard = handleBulkCallback(prefs, chatID, param, modified, botToken, dryRun)
 
+	case "select":
+		// Handle bulk select mode
+		// Format: select:ACTION[:PAGE[:ARG]] (e.g., "select:toggle:0:EVENT_ID")
+		responseText, keyboard = handleSelectCallback(callback.Data, prefs, chatID, modified, botToken, dryRun)
+
+	case "new":
+		// List new events past the per-run cap ("and N more" notification)
+		// Format: new:SINCE:PAGE
+		responseText, keyboard = handleNewEventsCallback(callback.Data, prefs, chatID)
+
+	case "run":
+		// Run a suggested command from a "Did you mean" button
+		// Format: run:COMMAND [ARGS] (e.g., "run:subscribe NV")
+		responseText = handleRunCallback(callback.Data, prefs, chatID, modified, botToken, dryRun)
+
+	case "season":
+		// Send the /season schedule as a calendar file
+		// Format: season:export
+		responseText = handleSeasonExportCallback(prefs, chatID, botToken, dryRun)
+
+	case "discuss":
+		// Show an event's discussion with friends
+		// Format: discuss:EVENT_ID
+		responseText = formatThread(prefs, chatID, param)
+
+	case "gnote":
+		// Show an event's group note
+		// Format: gnote:EVENT_ID
+		responseText = formatGroupNote(prefs, chatID, param)
+
+	case "reengage":
+		// Answer the "still want notifications?" message
+		// Format: reengage:keep | reengage:stop
+		responseText = handleReengageCallback(prefs, chatID, param, modified)
+
+	case "sharing":
+		// Per-friend sharing settings
+		// Format: sharing:menu | sharing:default | sharing:friend:CHAT_ID
+		responseText, keyboard = handleSharingCallback(prefs, chatID, parts, modified)
+
+	case "friend":
+		// Answer a friend request
+		// Format: friend:approve|decline|block:CHAT_ID
+		responseText = handleFriendRequestCallback(prefs, chatID, parts, modified, botToken, dryRun)
+
+	case "poll-close":
+		// Close a /poll and announce the winner
+		// Format: poll-close:POLL_ID
+		responseText, keyboard = handlePollCloseCallback(prefs, param, modified, botToken, dryRun)
+
+	case "poll-reg":
+		// Mark everyone who voted in a /poll as registered for the winner
+		// Format: poll-reg:POLL_ID
+		responseText = handlePollRegisterCallback(prefs, chatID, param, modified)
+
 	case "ack-change":
 		// Acknowledge event change notification
 		// Format: ack-change:EVENT_ID
@@ -726,24 +1201,24 @@ func handleCallbackQuery(prefs preferences.Preferences, callback *telegram.Callb
 
 	// Answer the callback query
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.AnswerCallbackQuery(callback.ID, "", false); err != nil {
+			if err := client.AnswerCallbackQuery(botCtx, callback.ID, "", false); err != nil {
 				fmt.Fprintf(os.Stderr, "Error answering callback: %v\n", err)
 			}
 
 			// Edit the message with new text and keyboard
 			if messageID > 0 {
-				if err := client.EditMessageText(chatID, messageID, responseText, keyboard); err != nil {
+				if err := client.EditMessageText(botCtx, messageChatID, messageID, responseText, keyboard); err != nil {
 					fmt.Fprintf(os.Stderr, "Error editing message: %v\n", err)
 				}
 			} else {
 				// If no message ID, send new message
 				var sendErr error
 				if keyboard != nil {
-					sendErr = client.SendMessageWithKeyboard(responseText, keyboard)
+					sendErr = client.SendMessageWithKeyboard(botCtx, responseText, keyboard)
 				} else {
-					sendErr = client.SendMessage(responseText)
+					sendErr = client.SendMessage(botCtx, responseText)
 				}
 				if sendErr != nil {
 					fmt.Fprintf(os.Stderr, "Error sending message: %v\n", err)
@@ -762,897 +1237,57 @@ func processCommand(prefs preferences.Preferences, chatID, text string, modified
 		return "Please send a command. Use /help to see available commands.", nil
 	}
 
-	command := strings.ToLower(parts[0])
-
-	// Try UI command dispatcher
-	if responseText, events, handled := processUICommand(command, prefs, chatID, botToken, dryRun); handled {
-		return responseText, events
-	}
-
-	// Try event command dispatcher
-	if responseText, events, handled := processEventCommand(command, prefs, chatID, botToken, dryRun, modified); handled {
-		return responseText, events
-	}
-
-	switch command {
-	case "/start", "/help":
-		// Check if user requested help for a specific command
-		if len(parts) >= 2 {
-			cmdName := strings.ToLower(strings.TrimPrefix(parts[1], "/"))
-			return getCommandHelp(cmdName), nil
-		}
-		return getHelpMessage(), nil
-
-	case "/subscribe":
-		if len(parts) < 2 {
-			// Show state selection keyboard
-			return handleSubscribeWithKeyboard(chatID, botToken, dryRun)
-		}
-		return handleSubscribe(prefs, chatID, parts[1], modified, botToken, dryRun)
-
-	case "/unsubscribe":
-		if len(parts) < 2 {
-			return "❌ Please specify a state code or 'all'.\n\nUsage: /unsubscribe NV\nUsage: /unsubscribe all", nil
+	// Plain text (not a command) is treated as a question about events
+	if !strings.HasPrefix(parts[0], "/") {
+		if !features.EnabledFor(flags.NaturalQueries, chatID) {
+			return "Please send a command. Use /help to see available commands.", nil
 		}
-		arg := strings.ToLower(strings.TrimSpace(parts[1]))
-		if arg == "all" {
-			// Show confirmation keyboard
-			return handleUnsubscribeAllWithKeyboard(prefs, chatID, botToken, dryRun)
-		}
-		return handleUnsubscribe(prefs, chatID, parts[1], modified), nil
-
-	case "/search":
-		if len(parts) < 2 {
-			return `🔍 <b>Event Search</b>
-
-Please provide a search keyword.
-
-<b>Usage:</b> /search &lt;keyword&gt;
-
-<b>Examples:</b>
-/search "Pine Valley"
-/search Championship
-/search Las Vegas
-/search NV`, nil
-		}
-		keyword := strings.Join(parts[1:], " ")
-		keyword = strings.Trim(keyword, `"'`) // Remove quotes if present
-
-		// Validate input
-		keyword, errMsg := validateUserInput(keyword, 100, "Search keyword")
-		if errMsg != "" {
-			return errMsg, nil
-		}
-
-		return handleSearch(prefs, chatID, keyword, botToken, dryRun, modified)
-
-	case "/export-calendar":
-		// Optional parameter: state code
-		var stateFilter string
-		if len(parts) >= 2 {
-			stateFilter = strings.ToUpper(strings.TrimSpace(parts[1]))
-		}
-		return handleExportCalendar(prefs, chatID, stateFilter, botToken, dryRun)
-
-	case "/note":
-		if len(parts) < 2 {
-			return "❌ Please specify an event ID.\n\nUsage: /note &lt;event_id&gt; &lt;note_text&gt;\nUsage: /note &lt;event_id&gt; clear", nil
-		}
-		eventID := parts[1]
-
-		// Check if second param is "clear"
-		if len(parts) >= 3 && strings.ToLower(parts[2]) == "clear" {
-			return handleRemoveNote(prefs, chatID, eventID, modified)
-		}
-
-		// Need note text
-		if len(parts) < 3 {
-			return "❌ Please provide note text.\n\nUsage: /note &lt;event_id&gt; &lt;note_text&gt;", nil
-		}
-
-		// Join remaining parts as note text
-		noteText := strings.Join(parts[2:], " ")
-
-		// Validate input
-		noteText, errMsg := validateUserInput(noteText, 500, "Note text")
-		if errMsg != "" {
-			return errMsg, nil
-		}
-
-		return handleAddNote(prefs, chatID, eventID, noteText, modified)
-
-	case "/notes":
-		return handleListNotes(prefs, chatID, botToken, dryRun)
-
-	case "/near":
-		if len(parts) < 2 {
-			return "❌ Please specify a city name.\n\nUsage: /near &lt;city&gt;\n\nExamples:\n/near Las Vegas\n/near \"San Diego\"", nil
-		}
-		// Join remaining parts as city name (supports multi-word cities)
-		cityName := strings.Join(parts[1:], " ")
-		cityName = strings.Trim(cityName, `"'`) // Remove quotes if present
-
-		// Validate input
-		cityName, errMsg := validateUserInput(cityName, 100, "City name")
-		if errMsg != "" {
-			return errMsg, nil
-		}
-
-		return handleNear(prefs, chatID, cityName, botToken, dryRun, modified)
-
-	case "/reminders":
-		return handleRemindersWithKeyboard(prefs, chatID, botToken, dryRun)
-
-	case "/notify-removals":
-		arg := ""
-		if len(parts) >= 2 {
-			arg = parts[1]
-		}
-		return handleNotifyRemovals(prefs, chatID, arg, modified)
-
-	case "/bulk":
-		// Handle bulk operations with subcommands
-		return processBulkCommand(parts, prefs, chatID, modified, botToken, dryRun)
-	case "/stats":
-		// Optional parameter: week, month, all
-		period := "week"
-		if len(parts) >= 2 {
-			period = strings.ToLower(parts[1])
-		}
-		return handleStats(prefs, chatID, period), nil
-
-	case "/check":
-		return handleCheck(prefs, chatID, botToken, dryRun, modified)
-
-	case "/invite":
-		return handleInvite(prefs, chatID), nil
-
-	case "/friends":
-		return handleFriends(prefs, chatID), nil
-
-	case "/join":
-		if len(parts) < 2 {
-			return "❌ Please provide an invite code.\n\nUsage: /join <invite_code>", nil
-		}
-		return handleJoin(prefs, chatID, parts[1], modified), nil
-
-	case "/filter":
-		// Handle filter operations with subcommands
-		return processFilterCommand(parts, prefs, chatID, modified)
-
-	case "/filters":
-		return handleFiltersList(prefs, chatID)
-
-	default:
-		return fmt.Sprintf("Unknown command: %s\n\nUse /help to see available commands.", command), nil
-	}
-}
-
-func getHelpMessage() string {
-	return fmt.Sprintf(`🤖 <b>VGA Events Bot</b>
-
-I help you track VGA Golf events in your favorite states!
-
-<b>Commands:</b>
-
-/menu - Quick actions menu 🎯
-/search - Search for events by keyword 🔍
-/near - Find events near a city 📍
-/events - View all events for your subscribed states 📅
-/my-events - View your tracked events ⭐
-/note - Add a note to an event 📝
-/notes - List all events with notes 📋
-/filter - Filter events (date, course, city, weekends) 🔍
-/filters - List all saved filters 📋
-/reminders - Configure event reminders 🔔
-/notify-removals - Toggle removal notifications ⚠️
-/stats - View your engagement statistics 📊
-/bulk - Bulk actions for multiple events 🔧
-/export-calendar - Download all events as .ics file 📅
-/invite - Get your friend invite code 👥
-/friends - View your friend list 👥
-/join - Join via friend invite code 👥
-/subscribe - Choose states with buttons (or /subscribe NV)
-/manage - Manage your subscriptions with buttons
-/settings - Configure notification preferences
-/list - Show your current subscriptions
-/check - Trigger an immediate check (experimental)
-/help - Show this help message
-/help &lt;command&gt; - Get detailed help for any command
-
-<b>Event Tracking:</b>
-Mark events with status buttons:
-• ⭐ Interested - Events you want to attend
-• ✅ Registered - Events you've signed up for
-• 🤔 Maybe - Events you're considering
-• ❌ Skip - Events you're not interested in
-
-<b>Reminders:</b>
-Get reminded before events you've marked as ⭐ Interested or ✅ Registered.
-Configure reminder timing with /reminders (1 day, 3 days, 1 week, or 2 weeks before).
-
-<b>Bulk Actions:</b>
-Manage multiple events at once with /bulk:
-• Clear all skipped events
-• Export all registered events to calendar
-
-<b>Friends &amp; Sharing:</b>
-Connect with golf buddies to coordinate events:
-• /invite - Get your invite code to share with friends
-• /join &lt;code&gt; - Add a friend using their invite code
-• /friends - View your friend list
-When both you and a friend enable sharing in /settings, you'll see when they're registered for events.
+		return handleNaturalQuery(prefs, chatID, text, botToken, dryRun, modified)
+	}
 
-<b>State Codes:</b>
-Use 2-letter state codes like NV, CA, TX, etc.
-Use %s to subscribe to all states.
+	parts = expandAlias(prefs, chatID, parts)
+	command := normalizeCommand(parts[0])
 
-<b>Notifications:</b>
-You'll receive messages whenever new events are posted in your subscribed states.
-• <b>Immediate mode</b> - Get notified right away (default)
-• <b>Daily digest</b> - Receive a daily summary at 9 AM UTC
-• <b>Weekly digest</b> - Receive a weekly summary on Mondays
+	cmd, ok := commandsByName[strings.TrimPrefix(command, "/")]
+	if !ok {
+		return handleUnknownCommand(command, parts, chatID, botToken, dryRun)
+	}
 
-Change your preferences with /settings
+	if cmd.Name != "report" {
+		recentCommands.record(chatID, cmd.Name, clk.Now())
+	}
 
-Checks run every hour.
+	if !features.EnabledFor(cmd.Flag, chatID) {
+		return featureUnavailable(cmd.Name), nil
+	}
 
-━━━━━━━━━━━━━━━━━━━━━━
-<b>Support &amp; Info:</b>
-Need help or have feedback? Contact @iamdesertpaul
+	if wait := cooldowns.check(chatID, cmd.Name, cmd.Cost); wait > 0 {
+		fmt.Printf("Cooldown active for /%s in chat %s\n", cmd.Name, chatID)
+		return cooldownMessage(cmd.Name, cmd.Cost, wait), nil
+	}
 
-Created by Paul Frederiksen
-Open source at github.com/pfrederiksen/vga-events`, AllStatesCode)
+	return cmd.Handler(&commandContext{
+		prefs:    prefs,
+		chatID:   chatID,
+		parts:    parts,
+		modified: modified,
+		botToken: botToken,
+		dryRun:   dryRun,
+	})
 }
 
-// getCommandHelp returns detailed help for a specific command
-func getCommandHelp(cmdName string) string {
-	switch cmdName {
-	case "subscribe":
-		return `📥 <b>/subscribe - Subscribe to State Events</b>
-
-<b>Description:</b>
-Subscribe to VGA events in specific states. You'll receive notifications whenever new events are posted.
-
-<b>Usage:</b>
-/subscribe - Show state selection buttons
-/subscribe &lt;STATE&gt; - Subscribe to a specific state
-
-<b>Examples:</b>
-/subscribe NV - Subscribe to Nevada
-/subscribe CA - Subscribe to California
-/subscribe ALL - Subscribe to all states
-
-<b>State Codes:</b>
-Use 2-letter state codes like NV, CA, TX, AZ, etc.
-Use ALL to get events from all states.
-
-<b>Related Commands:</b>
-/unsubscribe - Remove state subscriptions
-/list - View your current subscriptions
-/manage - Manage subscriptions with buttons`
-
-	case "unsubscribe":
-		return `📤 <b>/unsubscribe - Unsubscribe from States</b>
-
-<b>Description:</b>
-Remove state subscriptions. You'll stop receiving notifications for that state.
-
-<b>Usage:</b>
-/unsubscribe &lt;STATE&gt; - Unsubscribe from a specific state
-/unsubscribe all - Remove all subscriptions (requires confirmation)
-
-<b>Examples:</b>
-/unsubscribe NV - Stop Nevada notifications
-/unsubscribe all - Remove all state subscriptions
-
-<b>Related Commands:</b>
-/subscribe - Add new state subscriptions
-/list - View current subscriptions
-/manage - Manage subscriptions with buttons`
-
-	case "search":
-		return `🔍 <b>/search - Search for Events</b>
-
-<b>Description:</b>
-Search across all your subscribed states for events matching a keyword. Searches event titles, cities, and state names.
-
-<b>Usage:</b>
-/search &lt;keyword&gt; - Search for events
-
-<b>Examples:</b>
-/search "Pine Valley" - Find Pine Valley events
-/search Championship - Find championship events
-/search Las Vegas - Find events in Las Vegas
-/search NV - Find all Nevada events
-
-<b>Tips:</b>
-• Search is case-insensitive
-• Use quotes for multi-word exact phrases
-• Only searches your subscribed states
-• Results show course info if available
-
-<b>Related Commands:</b>
-/near - Find events near a specific city
-/events - View all upcoming events`
-
-	case "near":
-		return `📍 <b>/near - Find Events Near a City</b>
-
-<b>Description:</b>
-Find VGA events happening near a specific city. Uses fuzzy matching to find events in the same city or nearby locations.
-
-<b>Usage:</b>
-/near &lt;city&gt; - Find events near a city
-
-<b>Examples:</b>
-/near Las Vegas - Events near Las Vegas
-/near "San Diego" - Events near San Diego
-/near Phoenix - Events near Phoenix
-
-<b>Tips:</b>
-• Searches your subscribed states only
-• City names are case-insensitive
-• Use quotes for multi-word city names
-• Shows events in matching cities
-
-<b>Related Commands:</b>
-/search - Search by keyword
-/events - View all events`
-
-	case "note":
-		return `📝 <b>/note - Add Notes to Events</b>
-
-<b>Description:</b>
-Add personal notes to events. Notes appear in notifications, reminders, and event details. Maximum 500 characters.
-
-<b>Usage:</b>
-/note &lt;event_id&gt; &lt;text&gt; - Add or update a note
-/note &lt;event_id&gt; clear - Remove a note
-
-<b>Examples:</b>
-/note abc123 Bringing guest clubs
-/note abc123 Playing with John and Sarah
-/note abc123 clear - Remove the note
-
-<b>Tips:</b>
-• Notes are private (only you see them)
-• Appear in event notifications and reminders
-• Update anytime by sending new note
-• Max 500 characters per note
-
-<b>Related Commands:</b>
-/notes - List all events with notes
-/my-events - View tracked events (shows notes)`
-
-	case "notes":
-		return `📋 <b>/notes - List Events with Notes</b>
-
-<b>Description:</b>
-View all events where you've added personal notes. Shows event details along with your notes.
-
-<b>Usage:</b>
-/notes - List all events with notes
-
-<b>Tips:</b>
-• Only shows events you've added notes to
-• Includes event status (⭐ Interested, ✅ Registered, etc.)
-• Click event for more details
-• Use /note to add or edit notes
-
-<b>Related Commands:</b>
-/note - Add or edit event notes
-/my-events - View all tracked events`
-
-	case "events":
-		return `📅 <b>/events - View All Events</b>
-
-<b>Description:</b>
-View all upcoming VGA events in your subscribed states. Shows event details and course information if available.
-
-<b>Usage:</b>
-/events - List all upcoming events
-
-<b>Tips:</b>
-• Only shows events in subscribed states
-• Click any event to mark status (⭐ Interested, ✅ Registered, etc.)
-• Events sorted by date
-• Includes golf course details when available
-
-<b>Related Commands:</b>
-/my-events - View only tracked events
-/search - Search for specific events
-/subscribe - Add more states`
-
-	case "my-events":
-		return `⭐ <b>/my-events - View Your Tracked Events</b>
-
-<b>Description:</b>
-View events you've marked with a status: ⭐ Interested, ✅ Registered, 🤔 Maybe. Excludes events marked as ❌ Skip.
-
-<b>Usage:</b>
-/my-events - List your tracked events
-
-<b>Event Statuses:</b>
-• ⭐ Interested - Events you want to attend
-• ✅ Registered - Events you've signed up for
-• 🤔 Maybe - Events you're considering
-• ❌ Skip - Events you're not interested in (hidden)
-
-<b>Tips:</b>
-• Shows your personal notes if added
-• Get reminders for ⭐ and ✅ events
-• Friends can see your ✅ events (if sharing enabled)
-• Click event to change status
-
-<b>Related Commands:</b>
-/events - View all events
-/note - Add notes to events
-/reminders - Configure reminder timing`
-
-	case "reminders":
-		return `🔔 <b>/reminders - Configure Event Reminders</b>
-
-<b>Description:</b>
-Set when you want to be reminded about events marked ⭐ Interested or ✅ Registered. Reminders sent daily at 9 AM UTC.
-
-<b>Usage:</b>
-/reminders - Show reminder configuration menu
-
-<b>Options:</b>
-• 1 day before event
-• 3 days before event
-• 1 week before event
-• 2 weeks before event
-
-<b>Tips:</b>
-• Only reminded about ⭐ and ✅ events
-• Reminders include your notes
-• Can disable reminders entirely
-• Configure via interactive buttons
-
-<b>Related Commands:</b>
-/my-events - View tracked events
-/note - Add notes to events
-/settings - Configure other preferences`
-
-	case "notify-removals":
-		return `⚠️ <b>/notify-removals - Toggle Removal Notifications</b>
-
-<b>Description:</b>
-Get notified when events are removed or cancelled from the VGA website. Two priority levels based on your engagement.
-
-<b>Usage:</b>
-/notify-removals on - Enable notifications
-/notify-removals off - Disable notifications
-/notify-removals - Show current setting
-
-<b>Notification Levels:</b>
-⚠️ <b>High Priority:</b> Events you're registered for (✅) or tracking (⭐)
-ℹ️ <b>Low Priority:</b> Events in your subscribed states
-
-<b>Tips:</b>
-• Includes your notes if you had any
-• Removal notifications sent immediately
-• Default: ON
-• Removed events kept for 30 days
-
-<b>Related Commands:</b>
-/my-events - View tracked events
-/settings - Other notification preferences`
-
-	case "stats":
-		return `📊 <b>/stats - View Engagement Statistics</b>
-
-<b>Description:</b>
-View your VGA Events Bot usage statistics and engagement metrics.
-
-<b>Usage:</b>
-/stats - Show this week's stats (default)
-/stats week - This week's statistics
-/stats month - This month's statistics
-/stats all - All-time statistics
-
-<b>Metrics Tracked:</b>
-• Events tracked (⭐ ✅ 🤔)
-• Events skipped (❌)
-• Notes added
-• Searches performed
-• Commands used
-
-<b>Tips:</b>
-• Stats reset weekly (Sundays at 11:59 PM UTC)
-• Historical data saved for trends
-• Weekly stats archived automatically
-
-<b>Related Commands:</b>
-/my-events - View tracked events
-/notes - View events with notes`
-
-	case "bulk":
-		return `🔧 <b>/bulk - Bulk Operations</b>
-
-<b>Description:</b>
-Perform actions on multiple events at once. Useful for managing, tracking, or organizing events in bulk.
-
-<b>Usage:</b>
-/bulk - Show bulk actions menu (interactive)
-/bulk register &lt;event_ids&gt; - Mark multiple events as registered
-/bulk note &lt;event_ids&gt; &lt;note_text&gt; - Add same note to multiple events
-/bulk status &lt;status&gt; &lt;event_ids&gt; - Set status for multiple events
-
-<b>Event ID Formats:</b>
-• Space-separated: /bulk register abc123 def456 ghi789
-• Comma-separated: /bulk register abc123,def456,ghi789
-
-<b>Examples:</b>
-/bulk register abc123 def456 - Mark two events as registered
-/bulk note abc123,def456 "Must play early" - Add note to two events
-/bulk status interested abc123,def456 - Mark two events as interested
-/bulk status skip abc123 def456 ghi789 - Skip three events
-
-<b>Valid Statuses:</b>
-• interested - ⭐ Mark as interested
-• registered - ✅ Mark as registered
-• maybe - 🤔 Mark as maybe
-• skip - ❌ Mark to skip
-
-<b>Interactive Actions (via /bulk menu):</b>
-• <b>Clear Skipped Events</b> - Remove all ❌ Skip status marks
-• <b>Export Registered Events</b> - Download calendar file (.ics) of all ✅ Registered events
-
-<b>Tips:</b>
-• Operations are immediate and show success count
-• Event IDs can be found in event notifications
-• Use comma or space to separate multiple IDs
-• Note text limited to 500 characters
-
-<b>Related Commands:</b>
-/my-events - View tracked events
-/note - Add note to single event
-/export-calendar - Export all events`
-
-	case "export-calendar":
-		return `📅 <b>/export-calendar - Export to Calendar</b>
-
-<b>Description:</b>
-Download VGA events as an iCalendar (.ics) file compatible with Google Calendar, Apple Calendar, Outlook, etc.
-
-<b>Usage:</b>
-/export-calendar - Export all subscribed events
-/export-calendar &lt;STATE&gt; - Export events from specific state
-
-<b>Examples:</b>
-/export-calendar - All events from subscribed states
-/export-calendar NV - Only Nevada events
-/export-calendar CA - Only California events
-
-<b>Tips:</b>
-• Import .ics file into any calendar app
-• Events include full details and location
-• Re-export anytime to get updates
-• File sent directly in Telegram
-
-<b>Related Commands:</b>
-/bulk - Export only registered events
-/events - View events before exporting`
-
-	case "invite":
-		return `👥 <b>/invite - Get Friend Invite Code</b>
-
-<b>Description:</b>
-Generate your personal invite code to share with golf buddies. Friends who join can see which events you're registered for.
-
-<b>Usage:</b>
-/invite - Show your invite code
-
-<b>How It Works:</b>
-1. You send /invite to get your code
-2. Share code with your friend
-3. They send /join &lt;your_code&gt;
-4. You're now connected!
-
-<b>Privacy:</b>
-• Friends see events you mark as ✅ Registered
-• Requires both users to enable sharing in /settings
-• Only shows registered events (not ⭐ or 🤔)
-• You can disable sharing anytime
-
-<b>Related Commands:</b>
-/join - Join using a friend's code
-/friends - View your friend list
-/settings - Enable/disable event sharing`
-
-	case "friends":
-		return `👥 <b>/friends - View Friend List</b>
-
-<b>Description:</b>
-See your list of connected golf buddies. When both you and a friend enable sharing, you'll see events they've registered for.
-
-<b>Usage:</b>
-/friends - Show your friend list
-
-<b>Tips:</b>
-• Shows who can see your registered events
-• Sharing must be enabled by both users
-• Use /settings to control sharing
-• Friends see "👥 Your friends: @username" on shared events
-
-<b>Related Commands:</b>
-/invite - Get your invite code
-/join - Add a friend
-/settings - Enable/disable sharing`
-
-	case "join":
-		return `👥 <b>/join - Add a Friend</b>
-
-<b>Description:</b>
-Connect with a golf buddy using their invite code. See which events they're registered for (when both have sharing enabled).
-
-<b>Usage:</b>
-/join &lt;invite_code&gt; - Add friend using their code
-
-<b>Examples:</b>
-/join ABC123XYZ - Connect with friend
-
-<b>Steps:</b>
-1. Get friend's invite code (they use /invite)
-2. Send /join &lt;their_code&gt;
-3. Both enable sharing in /settings
-4. See each other's registered events!
-
-<b>Privacy:</b>
-• Sharing is optional (configure in /settings)
-• Only ✅ Registered events are shared
-• Either user can disable sharing anytime
-
-<b>Related Commands:</b>
-/invite - Get your invite code
-/friends - View connected friends
-/settings - Enable/disable sharing`
-
-	case "list":
-		return `📋 <b>/list - Show Subscriptions</b>
-
-<b>Description:</b>
-Display all states you're currently subscribed to. You receive event notifications from these states.
-
-<b>Usage:</b>
-/list - Show your subscribed states
-
-<b>Tips:</b>
-• Shows state codes and full names
-• Add states with /subscribe
-• Remove states with /unsubscribe
-• Use /manage for button-based management
-
-<b>Related Commands:</b>
-/subscribe - Add more states
-/unsubscribe - Remove states
-/manage - Manage with buttons`
-
-	case "manage":
-		return `⚙️ <b>/manage - Manage Subscriptions</b>
-
-<b>Description:</b>
-Interactive menu to manage your state subscriptions using buttons. Easier than typing commands.
-
-<b>Usage:</b>
-/manage - Show subscription management menu
-
-<b>Features:</b>
-• View current subscriptions
-• Add states with one tap
-• Remove states with confirmation
-• See all available states
-
-<b>Tips:</b>
-• More user-friendly than typing commands
-• Shows state names, not just codes
-• Confirms before removing states
-• Same as /subscribe and /unsubscribe
-
-<b>Related Commands:</b>
-/subscribe - Subscribe via command
-/unsubscribe - Unsubscribe via command
-/list - View current subscriptions`
-
-	case "settings":
-		return `⚙️ <b>/settings - Notification Preferences</b>
-
-<b>Description:</b>
-Configure how and when you receive event notifications. Control digest mode, friend sharing, and more.
-
-<b>Usage:</b>
-/settings - Show settings menu
-
-<b>Options:</b>
-• <b>Notification Mode:</b> Immediate, Daily Digest, or Weekly Digest
-• <b>Friend Sharing:</b> Let friends see your registered events
-• <b>Event Reminders:</b> Enable/disable reminder notifications
-• <b>Removal Notifications:</b> Get notified about cancelled events
-
-<b>Notification Modes:</b>
-• <b>Immediate</b> - Instant notifications (default)
-• <b>Daily Digest</b> - One summary at 9 AM UTC
-• <b>Weekly Digest</b> - Monday summary at 9 AM UTC
-
-<b>Related Commands:</b>
-/reminders - Configure reminder timing
-/notify-removals - Toggle removal notifications
-/friends - View sharing status`
-
-	case "menu":
-		return `🎯 <b>/menu - Quick Actions Menu</b>
-
-<b>Description:</b>
-Interactive menu with quick access to the most common actions. Great starting point if you're not sure what to do.
-
-<b>Usage:</b>
-/menu - Show quick actions menu
-
-<b>Available Actions:</b>
-• View My Events
-• View All Events
-• Search Events
-• Manage Subscriptions
-• Configure Settings
-• View Statistics
-• Get Help
-
-<b>Tips:</b>
-• Fastest way to navigate the bot
-• No need to remember commands
-• All major features accessible
-• Use anytime you need quick access
-
-<b>Related Commands:</b>
-/help - Show all available commands`
-
-	case "check":
-		return `🔄 <b>/check - Manual Event Check</b>
-
-<b>Description:</b>
-Trigger an immediate check for new events instead of waiting for the hourly automatic check. Experimental feature.
-
-<b>Usage:</b>
-/check - Check for new events now
-
-<b>How It Works:</b>
-• Triggers GitHub Actions workflow
-• Checks VGA website for new events
-• Sends notifications if new events found
-• Usually completes within 1-2 minutes
-
-<b>Notes:</b>
-• This is an experimental feature
-• Automatic checks run every hour
-• Rate limits may apply
-• No need to use frequently
-
-<b>Related Commands:</b>
-/events - View current events
-/my-events - View tracked events`
-
-	case "start":
-		return `🚀 <b>/start - Get Started</b>
-
-<b>Description:</b>
-Welcome message and introduction to the bot. Shows the same information as /help.
-
-<b>Usage:</b>
-/start - Show welcome message
-
-<b>First Steps:</b>
-1. Use /subscribe to choose states
-2. Browse events with /events
-3. Mark events you're interested in
-4. Get notifications when new events post
-
-<b>Related Commands:</b>
-/help - Show all commands
-/subscribe - Subscribe to states
-/menu - Quick actions menu`
-
-	case "filter":
-		return `🔍 <b>/filter - Event Filtering</b>
-
-<b>Description:</b>
-Create custom filters to narrow down events by date, course, city, or weekends only. Save filters as presets for quick reuse.
-
-<b>Usage:</b>
-/filter - Show current filter status
-/filter date &lt;range&gt; - Filter by date range
-/filter course &lt;name&gt; - Filter by course name
-/filter city &lt;name&gt; - Filter by city
-/filter weekends - Toggle weekends-only
-/filter save &lt;name&gt; - Save current filter
-/filter load &lt;name&gt; - Load saved filter
-/filter delete &lt;name&gt; - Delete saved filter
-/filter clear - Remove active filter
-
-<b>Date Range Examples:</b>
-/filter date "Mar 1-15" - March 1 to 15
-/filter date "March 1 - April 15" - March 1 to April 15
-/filter date "March" - Entire month of March
-
-<b>Course/City Examples:</b>
-/filter course "Pebble Beach" - Events at Pebble Beach
-/filter city "Las Vegas" - Events in Las Vegas
-
-<b>Combining Filters:</b>
-1. /filter date "Mar 1-15" - Set date range
-2. /filter weekends - Add weekends-only
-3. /filter course "Pebble" - Add course filter
-4. /filter save "March Pebble Weekends" - Save combination
-
-<b>Tips:</b>
-• Filters apply to your subscribed states
-• Combine multiple criteria (date + course + weekends)
-• Save useful filters as presets
-• Active filter applies to /events and /search
-• Use /filter to see current active filter
-
-<b>Related Commands:</b>
-/filters - List all saved filters
-/events - View filtered events
-/search - Search with active filter applied`
-
-	case "filters":
-		return `📋 <b>/filters - List Saved Filters</b>
-
-<b>Description:</b>
-View all your saved filter presets. Shows filter criteria and which one is currently active.
-
-<b>Usage:</b>
-/filters - List all saved filters
-
-<b>Filter Actions:</b>
-• /filter load "name" - Activate a saved filter
-• /filter delete "name" - Remove a saved filter
-• /filter - View current active filter
-
-<b>Example Workflow:</b>
-1. Create a filter: /filter date "March"
-2. Add criteria: /filter weekends
-3. Save it: /filter save "March Weekends"
-4. Later, load it: /filter load "March Weekends"
-
-<b>Tips:</b>
-• Saved filters persist across sessions
-• Quick way to reuse common filter combinations
-• Active filter shown with ✅ checkmark
-• Delete unused filters to keep list clean
-
-<b>Related Commands:</b>
-/filter - Create and manage filters
-/events - View events with active filter`
-
-	default:
-		return fmt.Sprintf(`❓ <b>Unknown Command: /%s</b>
-
-No help available for this command.
-
-Use /help to see all available commands.
-
-<b>Popular Commands:</b>
-/subscribe - Subscribe to states
-/events - View upcoming events
-/search - Search for events
-/my-events - View tracked events
-/settings - Configure preferences
-
-<b>Get Detailed Help:</b>
-/help subscribe - Help for /subscribe
-/help search - Help for /search
-etc.`, cmdName)
-	}
-}
 func handleSubscribe(prefs preferences.Preferences, chatID, state string, modified *bool, botToken string, dryRun bool) (string, []*event.Event) {
+	if strings.EqualFold(strings.TrimSpace(state), majorsCode) {
+		return handleSubscribeMajors(prefs, chatID, modified), nil
+	}
+	if region := preferences.GetRegion(state); region != nil {
+		return handleSubscribeRegion(prefs, chatID, region, modified), nil
+	}
+
 	state = strings.ToUpper(strings.TrimSpace(state))
 
 	if !preferences.IsValidState(state) {
-		return fmt.Sprintf("❌ Invalid state code: %s\n\nPlease use a valid 2-letter state code (e.g., NV, CA, TX) or %s.", state, AllStatesCode), nil
+		return fmt.Sprintf("❌ Invalid state code: %s\n\nPlease use a valid 2-letter state code (e.g., NV, CA, TX), a region (e.g., southwest), or %s.", state, AllStatesCode), nil
 	}
 
 	if prefs.HasState(chatID, state) {
@@ -1673,21 +1308,15 @@ func handleSubscribe(prefs preferences.Preferences, chatID, state string, modifi
 	// Check if there are existing events for this state
 	if !dryRun {
 		fmt.Printf("Checking for existing events in state %s...\n", state)
-		sc := scraper.New()
-		allEvents, err := sc.FetchEvents()
+		allEvents, err := fetchEvents()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to fetch events: %v\n", err)
 			response += "⚠️ Unable to check for existing events, but you're subscribed!"
 			return response, nil
 		}
 
-		// Filter and count events by state
-		var stateEvents []*event.Event
-		for _, evt := range allEvents {
-			if state == AllStatesCode || strings.EqualFold(evt.State, state) {
-				stateEvents = append(stateEvents, evt)
-			}
-		}
+		// Count the state's events the preview would show
+		stateEvents := userQuery(prefs.GetUser(chatID)).InStates([]string{state}).HidePast().Horizon().Run(allEvents)
 
 		totalEvents := len(stateEvents)
 		if totalEvents == 0 {
@@ -1703,9 +1332,9 @@ func handleSubscribe(prefs preferences.Preferences, chatID, state string, modifi
 		keyboard := buildEventPreviewKeyboard(state, totalEvents)
 
 		// Send keyboard message
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(response, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, response, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending preview keyboard: %v\n", err)
 				return response, nil // Fallback to plain message
 			}
@@ -1720,6 +1349,9 @@ func handleSubscribe(prefs preferences.Preferences, chatID, state string, modifi
 }
 
 func handleUnsubscribe(prefs preferences.Preferences, chatID, state string, modified *bool) string {
+	if strings.EqualFold(strings.TrimSpace(state), majorsCode) {
+		return handleUnsubscribeMajors(prefs, chatID, modified)
+	}
 	state = strings.ToUpper(strings.TrimSpace(state))
 
 	if !prefs.RemoveState(chatID, state) {
@@ -1762,11 +1394,12 @@ func handleRemoveNote(prefs preferences.Preferences, chatID, eventID string, mod
 	}
 
 	// Check if note exists
-	if user.GetEventNote(eventID) == "" {
+	if user.GetEventNote(eventID) == "" && user.GetNoteAttachment(eventID) == nil {
 		return fmt.Sprintf("ℹ️ No note found for event <code>%s</code>", eventID), nil
 	}
 
 	user.RemoveEventNote(eventID)
+	user.RemoveNoteAttachment(eventID)
 	*modified = true
 
 	return fmt.Sprintf("✅ Note removed for event <code>%s</code>", eventID), nil
@@ -1793,8 +1426,14 @@ func handleStats(prefs preferences.Preferences, chatID, period string) string {
 	case "all":
 		stats = user.GetAllTimeStats()
 		periodName = "All Time"
+	case "household":
+		if len(user.LinkedChatIDs) == 0 {
+			return "ℹ️ This account isn't linked with any others.\n\nUse /link to share event tracking with your other account or household."
+		}
+		stats = prefs.HouseholdStats(chatID)
+		periodName = "Household"
 	default:
-		return "❌ Invalid period. Use: /stats, /stats week, or /stats all"
+		return "❌ Invalid period. Use: /stats, /stats week, /stats all, or /stats household"
 	}
 
 	if stats == nil {
@@ -1813,6 +1452,11 @@ func handleStats(prefs preferences.Preferences, chatID, period string) string {
 		formatStatusCounts(&msg, stats.EventsMarked)
 	}
 
+	// Conversion from interested to registered (from status history)
+	if converted, total := user.StatusConversion(preferences.EventStatusInterested, preferences.EventStatusRegistered); total > 0 {
+		msg.WriteString(fmt.Sprintf("\n🎯 <b>Interested → Registered:</b> %d of %d (%d%%)\n", converted, total, converted*100/total))
+	}
+
 	// Total marked
 	totalMarked := 0
 	for _, count := range stats.EventsMarked {
@@ -1829,37 +1473,91 @@ func handleStats(prefs preferences.Preferences, chatID, period string) string {
 			msg.WriteString(fmt.Sprintf("\n📈 <b>%d week(s)</b> of history available\n", len(user.StatsHistory)))
 			msg.WriteString("Use /stats all to see all-time stats")
 		}
+	} else if period == "household" {
+		msg.WriteString(fmt.Sprintf("\n<i>%d linked accounts, each event counted once</i>", len(user.LinkedChatIDs)+1))
 	} else {
 		// All-time view
-		weekCount := len(user.StatsHistory) + 1 // +1 for current week
-		msg.WriteString(fmt.Sprintf("\n<i>Tracking for %d week(s)</i>", weekCount))
+		msg.WriteString(fmt.Sprintf("\n<i>Tracking for %d week(s)</i>", user.TrackingWeeks()))
 	}
 
 	return msg.String()
 }
 
-// handleInvite displays the user's invite code
-func handleInvite(prefs preferences.Preferences, chatID string) string {
+const inviteUsage = "Usage:\n/invite - Show your invite code\n/invite new - Replace it (the old code stops working)\n/invite new 7d - A code that expires\n/invite new once - A code that works for one friend"
+
+// handleInvite displays the user's invite code, or replaces it with "/invite new",
+// optionally with an expiry ("7d", "2w") and "once" for a single-use code
+func handleInvite(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	// GetUser gives a new user, or one with a legacy code, a fresh code that must be saved
+	storedCode := ""
+	if existing, ok := prefs[chatID]; ok {
+		storedCode = existing.InviteCode
+	}
 	user := prefs.GetUser(chatID)
 	if user == nil {
 		return errUserNotFound
 	}
+	if user.InviteCode != storedCode {
+		*modified = true
+	}
+
+	now := clk.Now()
+	header := "👥 <b>Invite Friends</b>"
+	if len(args) > 0 {
+		if !strings.EqualFold(args[0], "new") {
+			return "❌ Unknown option.\n\n" + inviteUsage
+		}
+		var ttl time.Duration
+		singleUse := false
+		for _, arg := range args[1:] {
+			if strings.EqualFold(arg, "once") {
+				singleUse = true
+				continue
+			}
+			days, ok := parsePauseDays(arg)
+			if !ok {
+				return fmt.Sprintf("❌ Couldn't read <code>%s</code>.\n\n%s", html.EscapeString(arg), inviteUsage)
+			}
+			ttl = time.Duration(days) * 24 * time.Hour
+			if ttl > preferences.MaxInviteTTL {
+				return fmt.Sprintf("❌ Invite codes can last up to %d days.", int(preferences.MaxInviteTTL.Hours()/24))
+			}
+		}
+		user.NewInviteCode(now, ttl, singleUse)
+		*modified = true
+		header = "✅ <b>New Invite Code</b>\n\nYour old code no longer works."
+	}
 
 	inviteCode := user.GetInviteCode()
+	var limits []string
+	if user.InviteExpires != 0 {
+		if user.InviteExpired(now) {
+			limits = append(limits, "⌛ This code has expired. Use /invite new for a fresh one.")
+		} else {
+			limits = append(limits, "⏳ Expires "+formatPausedUntil(user.InviteExpiresTime()))
+		}
+	}
+	if user.InviteSingleUse {
+		limits = append(limits, "1️⃣ Works for one friend, then a new code is made")
+	}
+	limitText := ""
+	if len(limits) > 0 {
+		limitText = "\n" + strings.Join(limits, "\n") + "\n"
+	}
 
-	msg := fmt.Sprintf(`👥 <b>Invite Friends</b>
+	msg := fmt.Sprintf(`%s
 
 Your invite code: <code>%s</code>
-
+%s
 <b>How it works:</b>
 1. Share your invite code with friends
 2. They send: /join %s
-3. You'll be connected as friends!
+3. Approve their friend request and you're connected!
 
 <b>Privacy Note:</b>
 When you're friends with someone and both have sharing enabled (/settings), you can see when they're registered for the same events.
 
-Use /friends to see your current friends.`, inviteCode, inviteCode)
+Use /invite new to replace your code, or /friends to see your current friends.`, header, inviteCode, limitText, inviteCode)
 
 	return msg
 }
@@ -1871,6 +1569,11 @@ func handleFriends(prefs preferences.Preferences, chatID string) string {
 		return errUserNotFound
 	}
 
+	pending := ""
+	if requests := user.PendingFriendRequests(); len(requests) > 0 {
+		pending = fmt.Sprintf("\n\n📨 <b>Waiting for your approval:</b> <code>%s</code>\nUse the buttons on each friend request message.", strings.Join(requests, "</code>, <code>"))
+	}
+
 	if len(user.FriendChatIDs) == 0 {
 		return `👥 <b>Friends</b>
 
@@ -1883,7 +1586,7 @@ Use /invite to get your invite code and share it with friends!
 • Coordinate golf outings together
 • Share events with your group
 
-Privacy is built-in: you control what you share via settings.`
+Privacy is built-in: you control what you share via settings.` + pending
 	}
 
 	msg := fmt.Sprintf(`👥 <b>Your Friends</b>
@@ -1893,70 +1596,16 @@ You have <b>%d friend(s)</b>:
 `, len(user.FriendChatIDs))
 
 	for i, friendChatID := range user.FriendChatIDs {
-		msg += fmt.Sprintf("%d. User ID: <code>%s</code>\n", i+1, friendChatID)
+		msg += fmt.Sprintf("%d. User ID: <code>%s</code> — sharing: %s\n", i+1, friendChatID, sharingLabel(user.SharingFor(friendChatID)))
 	}
 
-	msg += "\n<b>Sharing Status:</b> "
-	if user.ShareEvents {
-		msg += "✅ Enabled (friends can see your registered events)"
-	} else {
-		msg += "❌ Disabled (use /settings to enable)"
-	}
+	msg += "\nChange what each friend sees in /settings → 👥 Sharing."
 
-	msg += "\n\nUse /invite to add more friends!"
+	msg += "\n\nUse /invite to add more friends, or /block &lt;user_id&gt; to block someone." + pending
 
 	return msg
 }
 
-// handleJoin processes a friend invite
-func handleJoin(prefs preferences.Preferences, chatID, inviteCode string, modified *bool) string {
-	user := prefs.GetUser(chatID)
-	if user == nil {
-		return errUserNotFound
-	}
-
-	// Check if trying to add themselves
-	if user.GetInviteCode() == inviteCode {
-		return "❌ You can't add yourself as a friend!"
-	}
-
-	// Find the user with this invite code
-	var friendChatID string
-	for _, potentialFriendID := range prefs.GetAllUsers() {
-		potentialFriend := prefs.GetUser(potentialFriendID)
-		if potentialFriend.GetInviteCode() == inviteCode {
-			friendChatID = potentialFriendID
-			break
-		}
-	}
-
-	if friendChatID == "" {
-		return fmt.Sprintf("❌ Invalid invite code: <code>%s</code>\n\nMake sure you entered the code correctly.", inviteCode)
-	}
-
-	// Check if already friends
-	if user.IsFriend(friendChatID) {
-		return "ℹ️ You're already friends with this user!"
-	}
-
-	// Add friend (bidirectional)
-	user.AddFriend(friendChatID)
-	friend := prefs.GetUser(friendChatID)
-	friend.AddFriend(chatID)
-	*modified = true
-
-	return fmt.Sprintf(`✅ <b>Friend Added!</b>
-
-You're now connected with user <code>%s</code>
-
-<b>Next steps:</b>
-• Use /friends to see your friend list
-• Enable event sharing in /settings to see when they're registered for events
-• Use /my-events to coordinate attendance
-
-Both you and your friend need to enable sharing to see each other's event registrations.`, friendChatID)
-}
-
 // handleNear finds events near a specified city
 func handleNear(prefs preferences.Preferences, chatID, cityName, botToken string, dryRun bool, modified *bool) (string, []*event.Event) {
 	user := prefs.GetUser(chatID)
@@ -1965,73 +1614,47 @@ func handleNear(prefs preferences.Preferences, chatID, cityName, botToken string
 	}
 
 	// Fetch current events
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return "❌ Error fetching events. Please try again later.", nil
 	}
 
-	// Filter events by subscribed states first
-	var subscribedEvents []*event.Event
-	for _, evt := range allEvents {
-		for _, state := range user.States {
-			if evt.State == state {
-				subscribedEvents = append(subscribedEvents, evt)
-				break
-			}
-		}
-	}
-
-	// Filter by city (case-insensitive substring match)
-	normalizedCity := strings.ToLower(strings.TrimSpace(cityName))
-	var matchingEvents []*event.Event
-	for _, evt := range subscribedEvents {
-		if strings.Contains(strings.ToLower(evt.City), normalizedCity) {
-			// Apply user's date filter if enabled
-			if user.DaysAhead > 0 && !evt.IsWithinDays(user.DaysAhead) {
-				continue
-			}
-			// Filter past events if enabled
-			if user.HidePastEvents && evt.IsPastEvent() {
-				continue
-			}
-			matchingEvents = append(matchingEvents, evt)
-		}
-	}
+	// Match the city by substring on cleaned names, so "N Las Vegas" finds North Las Vegas
+	normalizedCity := geo.NormalizeCity(geo.CleanCity(cityName, ""))
+	matchingEvents := userQuery(user).States().HidePast().Horizon().
+		Where(func(evt *event.Event) bool {
+			return strings.Contains(geo.NormalizeCity(evt.City), normalizedCity)
+		}).
+		Sort().Run(allEvents)
 
 	if len(matchingEvents) == 0 {
 		return fmt.Sprintf("📍 No events found near <b>%s</b> in your subscribed states.\n\nTry a different city name or check your subscriptions with /list", cityName), nil
 	}
 
-	// Sort by date
-	event.SortByDate(matchingEvents)
-
 	// Send header
-	client, err := telegram.NewClient(botToken, chatID)
+	client, err := newSender(botToken, chatID)
 	if err != nil {
 		return fmt.Sprintf("📍 <b>Events near %s</b>\n\nFound %d event(s)", cityName, len(matchingEvents)), nil
 	}
 
 	headerMsg := fmt.Sprintf("📍 <b>Events near %s</b>\n\nFound %d event(s) in your subscribed states:", cityName, len(matchingEvents))
-	if err := client.SendMessage(headerMsg); err != nil {
+	if err := client.SendMessage(botCtx, headerMsg); err != nil {
 		fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
 	}
 
 	// Send each event
 	for i, evt := range matchingEvents {
-		currentStatus := user.GetEventStatus(evt.ID)
-		note := user.GetEventNote(evt.ID)
-		msg, keyboard := telegram.FormatEventWithStatusAndNote(evt, currentStatus, note, chatID, prefs)
-
 		if !dryRun {
-			if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
+			if err := sendEventCard(client, prefs, chatID, evt, modified); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
 			}
 
 			// Rate limiting
 			if i < len(matchingEvents)-1 {
-				time.Sleep(1 * time.Second)
+				if telegram.Pause(botCtx, 1*time.Second) != nil {
+					break // Shutting down
+				}
 			}
 		}
 	}
@@ -2052,16 +1675,30 @@ func handleListNotes(prefs preferences.Preferences, chatID, botToken string, dry
 		return errUserNotFound, nil
 	}
 
-	if len(user.EventNotes) == 0 {
+	// Notes can be just an attachment
+	eventIDs := slices.Sorted(maps.Keys(user.EventNotes))
+	for eventID := range user.NoteAttachments {
+		if _, ok := user.EventNotes[eventID]; !ok {
+			eventIDs = append(eventIDs, eventID)
+		}
+	}
+	if len(eventIDs) == 0 {
 		return "📝 You have no notes.\n\nUse /note &lt;event_id&gt; &lt;text&gt; to add a note to an event.", nil
 	}
+	slices.Sort(eventIDs)
 
-	response := fmt.Sprintf("📝 <b>Your Event Notes</b> (%d)\n\n", len(user.EventNotes))
+	response := fmt.Sprintf("📝 <b>Your Event Notes</b> (%d)\n\n", len(eventIDs))
 
 	// List each event with note
-	for eventID, noteText := range user.EventNotes {
+	for _, eventID := range eventIDs {
 		response += fmt.Sprintf("Event ID: <code>%s</code>\n", eventID)
-		response += fmt.Sprintf("📝 <i>%s</i>\n\n", noteText)
+		if noteText := user.EventNotes[eventID]; noteText != "" {
+			response += fmt.Sprintf("📝 <i>%s</i>\n", noteText)
+		}
+		if attachment := user.GetNoteAttachment(eventID); attachment != nil {
+			response += fmt.Sprintf("📎 %s attached (/note %s to view)\n", attachment.Kind, eventID)
+		}
+		response += "\n"
 	}
 
 	response += "Use /note &lt;event_id&gt; clear to remove a note.\n"
@@ -2072,8 +1709,12 @@ func handleListNotes(prefs preferences.Preferences, chatID, botToken string, dry
 
 func handleList(prefs preferences.Preferences, chatID string) string {
 	states := prefs.GetStates(chatID)
+	cities := prefs.GetUser(chatID).Cities
+	courses := prefs.GetUser(chatID).FollowedCourses
+	trips := prefs.GetUser(chatID).Travel
+	majors := prefs.GetUser(chatID).Majors
 
-	if len(states) == 0 {
+	if len(states) == 0 && len(cities) == 0 && len(courses) == 0 && len(trips) == 0 && !majors {
 		return `📋 <b>Your Subscriptions</b>
 
 You have no active subscriptions.
@@ -2082,14 +1723,53 @@ Use /subscribe &lt;STATE&gt; to start receiving notifications.
 Example: /subscribe NV`
 	}
 
-	response := "📋 <b>Your Subscriptions</b>\n\nYou're subscribed to:\n"
-	for _, state := range states {
-		stateName := preferences.GetStateName(state)
-		response += fmt.Sprintf("• %s (%s)\n", stateName, state)
+	response := "📋 <b>Your Subscriptions</b>\n\n"
+	if user := prefs.GetUser(chatID); user.IsPaused(clk.Now()) {
+		response += fmt.Sprintf("⏸️ <i>Paused until %s (/resume)</i>\n\n", formatPausedUntil(user.PausedUntilTime()))
+	}
+	if len(states) > 0 {
+		response += "You're subscribed to:\n"
+		for _, state := range states {
+			stateName := preferences.GetStateName(state)
+			response += fmt.Sprintf("• %s (%s)\n", stateName, state)
+		}
+	}
+	if len(cities) > 0 {
+		if len(states) > 0 {
+			response += "\n"
+		}
+		response += "🏙️ Cities:\n"
+		for _, city := range cities {
+			response += fmt.Sprintf("• %s\n", html.EscapeString(city.String()))
+		}
+	}
+	if len(courses) > 0 {
+		if len(states) > 0 || len(cities) > 0 {
+			response += "\n"
+		}
+		response += "⛳ Courses (/following):\n"
+		for _, course := range courses {
+			response += fmt.Sprintf("• %s\n", html.EscapeString(course))
+		}
+	}
+	if len(trips) > 0 {
+		if len(states) > 0 || len(cities) > 0 || len(courses) > 0 {
+			response += "\n"
+		}
+		response += "✈️ Travel (/travel):\n"
+		for _, trip := range trips {
+			response += fmt.Sprintf("• <i>%s</i>\n", trip.String())
+		}
+	}
+	if majors {
+		if len(states) > 0 || len(cities) > 0 || len(courses) > 0 || len(trips) > 0 {
+			response += "\n"
+		}
+		response += "🏆 National &amp; majors events, in any state\n"
 	}
 
-	response += "\nUse /subscribe &lt;STATE&gt; to add more\n"
-	response += "Use /unsubscribe &lt;STATE&gt; to remove"
+	response += "\nUse /subscribe &lt;STATE&gt; or /subscribe-city &lt;city&gt; to add more\n"
+	response += "Use /unsubscribe &lt;STATE&gt; or /unsubscribe-city &lt;city&gt; to remove"
 
 	return response
 }
@@ -2097,8 +1777,8 @@ Example: /subscribe NV`
 func handleCheck(prefs preferences.Preferences, chatID, botToken string, dryRun bool, modified *bool) (string, []*event.Event) {
 	user := prefs.GetUser(chatID)
 
-	// Check if user is subscribed to any states
-	if len(user.States) == 0 {
+	// Check if user is subscribed to any states or cities
+	if len(user.States) == 0 && len(user.Cities) == 0 && len(user.FollowedCourses) == 0 && len(user.Travel) == 0 && !user.Majors {
 		return `🔍 <b>Manual Check</b>
 
 You're not subscribed to any states yet!
@@ -2107,26 +1787,16 @@ Use /subscribe to choose states you want to follow.`, nil
 	}
 
 	// Fetch all events
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return errFetchingEvents, nil
 	}
 
-	// Filter by subscribed states and exclude already seen events
+	// Filter by subscribed states and cities and exclude already seen events
 	var unseenEvents []*event.Event
 	for _, evt := range allEvents {
-		// Check if event is in a subscribed state
-		isSubscribed := false
-		for _, state := range user.States {
-			if strings.EqualFold(evt.State, state) {
-				isSubscribed = true
-				break
-			}
-		}
-
-		if !isSubscribed {
+		if !user.MatchesSubscriptions(evt) {
 			continue
 		}
 
@@ -2137,7 +1807,20 @@ Use /subscribe to choose states you want to follow.`, nil
 	}
 
 	if len(unseenEvents) == 0 {
-		statesText := strings.Join(user.States, ", ")
+		subscriptions := append([]string(nil), user.States...)
+		for _, city := range user.Cities {
+			subscriptions = append(subscriptions, html.EscapeString(city.String()))
+		}
+		for _, course := range user.FollowedCourses {
+			subscriptions = append(subscriptions, html.EscapeString(course))
+		}
+		for _, trip := range user.Travel {
+			subscriptions = append(subscriptions, trip.String())
+		}
+		if user.Majors {
+			subscriptions = append(subscriptions, "national &amp; majors")
+		}
+		statesText := strings.Join(subscriptions, ", ")
 		return fmt.Sprintf(`🔍 <b>Manual Check</b>
 
 No new events found!
@@ -2152,7 +1835,7 @@ The bot will notify you when new events are posted.`, statesText), nil
 
 	// Send events
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return "❌ Error sending events", nil
 		}
@@ -2163,7 +1846,7 @@ The bot will notify you when new events are posted.`, statesText), nil
 
 Found %d new event(s) in %s:`, len(unseenEvents), statesText)
 
-		if err := client.SendMessage(headerMsg); err != nil {
+		if err := client.SendMessage(botCtx, headerMsg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
 		}
 
@@ -2171,9 +1854,16 @@ Found %d new event(s) in %s:`, len(unseenEvents), statesText)
 		for i, evt := range unseenEvents {
 			note := user.GetEventNote(evt.ID)
 			courseDetails := getCourseDetails(evt)
+			sendCourseImage(client, courseDetails)
 			msg := telegram.FormatEventWithCourse(evt, courseDetails, note)
-			if err := client.SendMessage(msg); err != nil {
-				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
+			var sendErr error
+			if keyboard := telegram.CourseKeyboard(courseDetails); keyboard != nil {
+				sendErr = client.SendMessageWithKeyboard(botCtx, msg, keyboard)
+			} else {
+				sendErr = client.SendMessage(botCtx, msg)
+			}
+			if sendErr != nil {
+				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, sendErr)
 			}
 
 			// Mark as seen
@@ -2181,10 +1871,12 @@ Found %d new event(s) in %s:`, len(unseenEvents), statesText)
 
 			// Rate limiting
 			if i < len(unseenEvents)-1 {
+				delay := 1 * time.Second
 				if courseClient != nil {
-					time.Sleep(2 * time.Second)
-				} else {
-					time.Sleep(1 * time.Second)
+					delay = 2 * time.Second
+				}
+				if telegram.Pause(botCtx, delay) != nil {
+					break // Shutting down
 				}
 			}
 		}
@@ -2199,35 +1891,23 @@ Found %d new event(s) in %s:`, len(unseenEvents), statesText)
 
 func handleSearch(prefs preferences.Preferences, chatID, keyword string, botToken string, dryRun bool, modified *bool) (string, []*event.Event) {
 	// Fetch all events
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return errFetchingEvents, nil
 	}
 
-	// Filter events by keyword (case-insensitive search in title, city, state)
+	// Match the keyword case-insensitively in title, city, and state, within the
+	// user's time filters
 	keywordLower := strings.ToLower(keyword)
-	var matchingEvents []*event.Event
-	for _, evt := range allEvents {
-		if strings.Contains(strings.ToLower(evt.Title), keywordLower) ||
-			strings.Contains(strings.ToLower(evt.City), keywordLower) ||
-			strings.Contains(strings.ToLower(evt.State), keywordLower) {
-			matchingEvents = append(matchingEvents, evt)
-		}
-	}
-
-	// Apply past event filtering (default: hide past events)
 	user := prefs.GetUser(chatID)
-	if user.HidePastEvents {
-		var upcomingEvents []*event.Event
-		for _, evt := range matchingEvents {
-			if !evt.IsPastEvent() {
-				upcomingEvents = append(upcomingEvents, evt)
-			}
-		}
-		matchingEvents = upcomingEvents
-	}
+	matchingEvents := userQuery(user).
+		Where(func(evt *event.Event) bool {
+			return strings.Contains(strings.ToLower(evt.Title), keywordLower) ||
+				strings.Contains(strings.ToLower(evt.City), keywordLower) ||
+				strings.Contains(strings.ToLower(evt.State), keywordLower)
+		}).
+		HidePast().Horizon().Sort().Run(allEvents)
 
 	if len(matchingEvents) == 0 {
 		return fmt.Sprintf(`🔍 <b>No Results</b>
@@ -2237,9 +1917,6 @@ No events found matching "%s"
 Try a different search term or use /menu to see all upcoming events.`, keyword), nil
 	}
 
-	// Sort by date (soonest first)
-	event.SortByDate(matchingEvents)
-
 	// Limit to 10 events
 	eventsToSend := matchingEvents
 	if len(eventsToSend) > 10 {
@@ -2248,7 +1925,7 @@ Try a different search term or use /menu to see all upcoming events.`, keyword),
 
 	// Send results
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return "❌ Error sending results", nil
 		}
@@ -2260,23 +1937,21 @@ Found %d event(s) matching "%s"
 
 Showing first %d results:`, len(matchingEvents), keyword, len(eventsToSend))
 
-		if err := client.SendMessage(headerMsg); err != nil {
+		if err := client.SendMessage(botCtx, headerMsg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
 		}
 
 		// Send each event with calendar button and subscribe option
 		for i, evt := range eventsToSend {
-			user := prefs.GetUser(chatID)
-			currentStatus := user.GetEventStatus(evt.ID)
-			note := user.GetEventNote(evt.ID)
-			msg, keyboard := telegram.FormatEventWithStatusAndNote(evt, currentStatus, note, chatID, prefs)
-			if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
+			if err := sendEventCard(client, prefs, chatID, evt, modified); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
 			}
 
 			// Rate limiting
 			if i < len(eventsToSend)-1 {
-				time.Sleep(1 * time.Second)
+				if telegram.Pause(botCtx, 1*time.Second) != nil {
+					break // Shutting down
+				}
 			}
 		}
 
@@ -2291,24 +1966,70 @@ Showing first %d results:`, len(matchingEvents), keyword, len(eventsToSend))
 	return fmt.Sprintf("[DRY RUN] Would send %d search results for '%s'", len(eventsToSend), keyword), nil
 }
 
-func handleExportCalendar(prefs preferences.Preferences, chatID, stateFilter string, botToken string, dryRun bool) (string, []*event.Event) {
-	// Get user's subscribed states
-	states := prefs.GetStates(chatID)
+// exportCalendarArgs are the options of /export-calendar
+type exportCalendarArgs struct {
+	state    string
+	statuses []string
+	from, to *time.Time
+	perState bool
+}
+
+// parseExportCalendarArgs parses /export-calendar's arguments, in any order: a state
+// code, registered and/or interested, split, and a date range as for /filter date
+func parseExportCalendarArgs(args []string) (exportCalendarArgs, error) {
+	var parsed exportCalendarArgs
+	var dateWords []string
+	for _, arg := range args {
+		word := strings.ToLower(strings.TrimSpace(arg))
+		switch {
+		case word == "":
+		case word == preferences.EventStatusRegistered || word == preferences.EventStatusInterested:
+			if !slices.Contains(parsed.statuses, word) {
+				parsed.statuses = append(parsed.statuses, word)
+			}
+		case word == "split" || word == "per-state":
+			parsed.perState = true
+		case len(dateWords) == 0 && parsed.state == "" && (strings.ToUpper(word) == AllStatesCode || (len(word) == 2 && preferences.IsValidState(strings.ToUpper(word)))):
+			parsed.state = strings.ToUpper(word)
+		default:
+			dateWords = append(dateWords, arg)
+		}
+	}
+	if len(dateWords) > 0 {
+		from, to, err := filter.ParseDateRangeAt(strings.Join(dateWords, " "), clk.Now())
+		if err != nil {
+			return parsed, err
+		}
+		parsed.from, parsed.to = from, to
+	}
+	return parsed, nil
+}
 
-	// If no state filter provided, use all subscribed states
-	var filterStates []string
-	if stateFilter != "" {
-		// Validate state code
-		if !preferences.IsValidState(stateFilter) {
-			return fmt.Sprintf(`❌ Invalid state code: %s
+func handleExportCalendar(prefs preferences.Preferences, chatID string, args []string, botToken string, dryRun bool) (string, []*event.Event) {
+	opts, err := parseExportCalendarArgs(args)
+	if err != nil {
+		return fmt.Sprintf(`❌ %s
 
 <b>Usage:</b>
 /export-calendar - Export all your subscribed events
 /export-calendar NV - Export events from Nevada
-/export-calendar %s - Export events from all states`, stateFilter, AllStatesCode), nil
-		}
-		filterStates = []string{stateFilter}
-	} else {
+/export-calendar %s - Export events from all states
+/export-calendar registered - Only events you're registered for
+/export-calendar Apr 1-30 - Only events in a date range
+/export-calendar split - One file per state, zipped`, html.EscapeString(err.Error()), AllStatesCode), nil
+	}
+
+	// Get user's subscribed states
+	states := prefs.GetStates(chatID)
+
+	// With no state, use all subscribed states; a status filter alone covers every state
+	var filterStates []string
+	switch {
+	case opts.state != "":
+		filterStates = []string{opts.state}
+	case len(opts.statuses) > 0:
+		filterStates = []string{AllStatesCode}
+	default:
 		if len(states) == 0 {
 			return `📅 <b>No Subscriptions</b>
 
@@ -2322,80 +2043,103 @@ Or use /export-calendar &lt;STATE&gt; to export events from a specific state.`,
 	}
 
 	// Fetch all events
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return errFetchingEvents, nil
 	}
 
-	// Filter events by states
-	var filteredEvents []*event.Event
-	for _, evt := range allEvents {
-		for _, state := range filterStates {
-			if state == AllStatesCode || strings.EqualFold(evt.State, state) {
-				filteredEvents = append(filteredEvents, evt)
-				break
-			}
+	// Exports keep past events, so registered rounds stay in the calendar
+	user := prefs.GetUser(chatID)
+	filteredEvents := userQuery(user).InStates(filterStates).Sort().Run(allEvents)
+
+	// Label events with the user's statuses and notes, and apply the filters
+	bulkOpts := calendar.BulkOptions{
+		Events:   make(map[string]*calendar.EventOptions, len(filteredEvents)),
+		Statuses: opts.statuses,
+		PerState: opts.perState,
+	}
+	for _, evt := range filteredEvents {
+		bulkOpts.Events[evt.ID] = &calendar.EventOptions{
+			Status: user.GetEventStatus(evt.ID),
+			Note:   user.GetEventNote(evt.ID),
 		}
 	}
+	if opts.from != nil {
+		bulkOpts.From, bulkOpts.To = *opts.from, *opts.to
+	}
 
-	if len(filteredEvents) == 0 {
-		return fmt.Sprintf(`📅 <b>No Events Found</b>
-
-No events found for %s
+	baseName := "vga-events"
+	if len(filterStates) == 1 && filterStates[0] != AllStatesCode {
+		baseName = fmt.Sprintf("vga-events-%s", filterStates[0])
+	}
+	calendarName := fmt.Sprintf("VGA Golf Events - %s", strings.Join(filterStates, ", "))
+	file, err := calendar.GenerateBulkFile(filteredEvents, calendarName, baseName, bulkOpts)
+	if err != nil {
+		reportError(fmt.Errorf("generating calendar export: %w", err), "")
+		return "❌ Error generating calendar file", nil
+	}
 
-Try /export-calendar with a different state, or check back later.`, strings.Join(filterStates, ", ")), nil
+	// Describe the filters for the messages
+	scope := strings.Join(filterStates, ", ")
+	if len(opts.statuses) > 0 {
+		scope = strings.Join(opts.statuses, " or ") + " events in " + scope
+	}
+	if opts.from != nil {
+		scope += fmt.Sprintf(" (%s - %s)", opts.from.Format("Jan 2"), opts.to.Format("Jan 2, 2006"))
 	}
 
-	// Sort by date (soonest first)
-	event.SortByDate(filteredEvents)
+	if file == nil {
+		return fmt.Sprintf(`📅 <b>No Events Found</b>
 
-	// Generate bulk ICS file
-	calendarName := fmt.Sprintf("VGA Golf Events - %s", strings.Join(filterStates, ", "))
-	icsContent := calendar.GenerateBulkICS(filteredEvents, calendarName)
+No events found for %s
 
-	if len(icsContent) == 0 {
-		return "❌ Error generating calendar file", nil
+Try /export-calendar with a different state, or check back later.`, scope), nil
 	}
 
 	// Send the .ics file
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return errSendingCalendarFile, nil
 		}
 
-		filename := "vga-events.ics"
-		if len(filterStates) == 1 && filterStates[0] != AllStatesCode {
-			filename = fmt.Sprintf("vga-events-%s.ics", filterStates[0])
+		importHint := "Tap the file to import all events into your calendar app!"
+		if opts.perState {
+			importHint = fmt.Sprintf("Unzip it for one calendar per state (%s) and import the ones you want.", strings.Join(file.States, ", "))
 		}
-
 		caption := fmt.Sprintf(`📅 <b>VGA Events Calendar</b>
 
 ✅ Exported %d event(s) from %s
 
-Tap the file to import all events into your calendar app!`, len(filteredEvents), strings.Join(filterStates, ", "))
+%s`, file.Events, scope, importHint)
 
-		if err := client.SendDocument(filename, []byte(icsContent), caption); err != nil {
+		if err := client.SendDocument(botCtx, file.Name, file.Content, caption); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
 			return errSendingCalendarFile, nil
 		}
 
-		fmt.Printf("Sent bulk calendar file to %s (%d events)\n", chatID, len(filteredEvents))
+		fmt.Printf("Sent bulk calendar file to %s (%d events)\n", chatID, file.Events)
 		return "", nil // Already sent
 	}
 
-	return fmt.Sprintf("[DRY RUN] Would send bulk calendar file with %d events for %s", len(filteredEvents), strings.Join(filterStates, ", ")), nil
+	return fmt.Sprintf("[DRY RUN] Would send %s with %d events for %s", file.Name, file.Events, scope), nil
 }
 
-// getCourseDetails fetches course information for an event
+// getCourseDetails fetches course information and tee-time details for an event
 func getCourseDetails(evt *event.Event) *telegram.CourseDetails {
+	// Silently ignore API errors, the booking link still works
+	details, _ := telegram.AddTeeTimeDetails(botCtx, teeTimeClient, evt, lookupCourseDetails(evt))
+	return details
+}
+
+// lookupCourseDetails fetches course information for an event from the Golf Course API
+func lookupCourseDetails(evt *event.Event) *telegram.CourseDetails {
 	if courseClient == nil {
 		return nil
 	}
 
-	courseInfo, err := courseClient.FindBestMatch(evt.Title, evt.City, evt.State)
+	courseInfo, err := courseClient.FindBestMatch(botCtx, evt.Title, evt.City, evt.State)
 	if err != nil {
 		// Silently ignore API errors
 		return nil
@@ -2405,6 +2149,9 @@ func getCourseDetails(evt *event.Event) *telegram.CourseDetails {
 		return nil
 	}
 
+	// Fill in website, phone, and image from the course detail endpoint
+	courseClient.EnrichDetails(botCtx, courseInfo)
+
 	// Collect all tees (combined, no distinction between gender)
 	// Deduplicate by tee name - keep first occurrence (male tees come first)
 	seenTees := make(map[string]bool)
@@ -2428,10 +2175,22 @@ func getCourseDetails(evt *event.Event) *telegram.CourseDetails {
 	}
 
 	return &telegram.CourseDetails{
-		Name:    courseInfo.GetDisplayName(),
-		Tees:    tees,
-		Website: "",
-		Phone:   "",
+		Name:     courseInfo.GetDisplayName(),
+		Tees:     tees,
+		Website:  courseInfo.Website,
+		Phone:    courseInfo.Phone,
+		ImageURL: courseInfo.ImageURL,
+	}
+}
+
+// sendCourseImage sends the course photo ahead of an event card, if one is available
+func sendCourseImage(client Sender, courseDetails *telegram.CourseDetails) {
+	if courseDetails == nil || courseDetails.ImageURL == "" {
+		return
+	}
+
+	if err := client.SendPhoto(botCtx, courseDetails.ImageURL, telegram.FormatCourseImageCaption(courseDetails)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending course image: %v\n", err)
 	}
 }
 
@@ -2453,8 +2212,7 @@ Then use /my-events to see all your tracked events!`, nil
 	}
 
 	// Fetch current events from VGA website
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return errFetchingEvents, nil
@@ -2510,7 +2268,7 @@ They may have been removed from the VGA website or marked as "Skip".`, nil
 
 	// Send events
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return "❌ Error sending events", nil
 		}
@@ -2520,7 +2278,7 @@ They may have been removed from the VGA website or marked as "Skip".`, nil
 
 You have %d tracked event(s):`, totalEvents)
 
-		if err := client.SendMessage(headerMsg); err != nil {
+		if err := client.SendMessage(botCtx, headerMsg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
 		}
 
@@ -2543,9 +2301,9 @@ You have %d tracked event(s):`, totalEvents)
 				continue
 			}
 
-			// Send group header
+			// Send group header, with actions on the whole group
 			groupHeader := fmt.Sprintf("\n<b>%s (%d)</b>", statusNames[status], len(group))
-			if err := client.SendMessage(groupHeader); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, groupHeader, myEventsGroupKeyboard(status, group)); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending group header: %v\n", err)
 			}
 
@@ -2553,17 +2311,24 @@ You have %d tracked event(s):`, totalEvents)
 			for i, evt := range group {
 				note := user.GetEventNote(evt.ID)
 				courseDetails := getCourseDetails(evt)
+				sendCourseImage(client, courseDetails)
 				msg, keyboard := telegram.FormatEventWithStatusAndCourse(evt, courseDetails, status, note, chatID, prefs)
-				if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
+				if history := formatStatusHistory(user.GetStatusHistory(evt.ID)); history != "" {
+					// Insert history before the registration link
+					msg = strings.Replace(msg, "\n🔗 <a href=", "\n"+history+"\n\n🔗 <a href=", 1)
+				}
+				if err := client.SendMessageWithKeyboard(botCtx, msg, keyboard); err != nil {
 					fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
 				}
 
 				// Rate limiting (longer if using course API)
 				if i < len(group)-1 {
+					delay := 1 * time.Second
 					if courseClient != nil {
-						time.Sleep(2 * time.Second)
-					} else {
-						time.Sleep(1 * time.Second)
+						delay = 2 * time.Second
+					}
+					if telegram.Pause(botCtx, delay) != nil {
+						break // Shutting down
 					}
 				}
 			}
@@ -2591,38 +2356,15 @@ Use /subscribe to start receiving events!`, nil
 	}
 
 	// Fetch all events
-	sc := scraper.New()
-	allEvents, err := sc.FetchEvents()
+	allEvents, err := fetchEvents()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 		return errFetchingEvents, nil
 	}
 
-	// Filter events by subscribed states
-	var filteredEvents []*event.Event
-	for _, evt := range allEvents {
-		for _, state := range states {
-			if state == AllStatesCode || strings.EqualFold(evt.State, state) {
-				filteredEvents = append(filteredEvents, evt)
-				break
-			}
-		}
-	}
-
-	// Apply past event filtering (default: hide past events)
+	// Subscribed states, time filters, and the active filter, soonest first
 	user := prefs.GetUser(chatID)
-	if user.HidePastEvents {
-		var upcomingEvents []*event.Event
-		for _, evt := range filteredEvents {
-			if !evt.IsPastEvent() {
-				upcomingEvents = append(upcomingEvents, evt)
-			}
-		}
-		filteredEvents = upcomingEvents
-	}
-
-	// Apply user's active filter if any
-	filteredEvents = user.ApplyFiltersToEvents(filteredEvents)
+	filteredEvents := userQuery(user).InStates(states).HidePast().Horizon().ApplyFilter().Sort().Run(allEvents)
 
 	// Build filter status message
 	filterStatus := ""
@@ -2644,9 +2386,6 @@ Check back later or subscribe to more states with /subscribe`, strings.Join(stat
 		return noEventsMsg, nil
 	}
 
-	// Sort by date (soonest first)
-	event.SortByDate(filteredEvents)
-
 	// Limit to 50 events to avoid overwhelming the user
 	eventsToSend := filteredEvents
 	if len(eventsToSend) > 50 {
@@ -2655,7 +2394,7 @@ Check back later or subscribe to more states with /subscribe`, strings.Join(stat
 
 	// Send events with status tracking buttons
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return "❌ Error sending events", nil
 		}
@@ -2667,23 +2406,26 @@ Found %d event(s) for %s%s
 
 Showing %d event(s), sorted by date:`, len(filteredEvents), strings.Join(states, ", "), filterStatus, len(eventsToSend))
 
-		if err := client.SendMessage(headerMsg); err != nil {
+		selectKeyboard := &telegram.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telegram.InlineKeyboardButton{
+				{{Text: "☑️ Select mode", CallbackData: "select:page:0"}},
+			},
+		}
+		if err := client.SendMessageWithKeyboard(botCtx, headerMsg, selectKeyboard); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
 		}
 
 		// Send each event with status buttons
-		user := prefs.GetUser(chatID)
 		for i, evt := range eventsToSend {
-			currentStatus := user.GetEventStatus(evt.ID)
-			note := user.GetEventNote(evt.ID)
-			msg, keyboard := telegram.FormatEventWithStatusAndNote(evt, currentStatus, note, chatID, prefs)
-			if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
+			if err := sendEventCard(client, prefs, chatID, evt, modified); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending event %s: %v\n", evt.ID, err)
 			}
 
 			// Rate limiting
 			if i < len(eventsToSend)-1 {
-				time.Sleep(1 * time.Second)
+				if telegram.Pause(botCtx, 1*time.Second) != nil {
+					break // Shutting down
+				}
 			}
 		}
 
@@ -2731,28 +2473,49 @@ func buildEventPreviewKeyboard(state string, totalEvents int) *telegram.InlineKe
 	}
 }
 
-// showStateSelectionKeyboard returns a keyboard with popular states
-func showStateSelectionKeyboard() (string, *telegram.InlineKeyboardMarkup) {
-	keyboard := &telegram.InlineKeyboardMarkup{
-		InlineKeyboard: [][]telegram.InlineKeyboardButton{
-			{
-				{Text: "🌴 California (CA)", CallbackData: "subscribe:CA"},
-				{Text: "🎰 Nevada (NV)", CallbackData: "subscribe:NV"},
-			},
-			{
-				{Text: "🤠 Texas (TX)", CallbackData: "subscribe:TX"},
-				{Text: "🌵 Arizona (AZ)", CallbackData: "subscribe:AZ"},
-			},
-			{
-				{Text: "🏖️ Florida (FL)", CallbackData: "subscribe:FL"},
-				{Text: "🗽 New York (NY)", CallbackData: "subscribe:NY"},
-			},
-			{
-				{Text: "🇺🇸 All States", CallbackData: fmt.Sprintf("subscribe:%s", AllStatesCode)},
-			},
-		},
+// handleSubscribeRegion subscribes a user to every state in a region preset
+func handleSubscribeRegion(prefs preferences.Preferences, chatID string, region *preferences.Region, modified *bool) string {
+	added := prefs.AddRegion(chatID, region)
+	if len(added) == 0 {
+		return fmt.Sprintf("ℹ️ You're already subscribed to every state in %s (%s).\n\nUse /list to see all your subscriptions.",
+			html.EscapeString(region.Name), strings.Join(region.States, ", "))
+	}
+	*modified = true
+
+	response := fmt.Sprintf("✅ <b>Subscribed to %s!</b>\n\n", html.EscapeString(region.Name))
+	response += fmt.Sprintf("Added: %s\n", strings.Join(added, ", "))
+	if skipped := len(region.States) - len(added); skipped > 0 {
+		response += fmt.Sprintf("(%d state(s) you already had)\n", skipped)
+	}
+	response += "\nYou'll receive notifications when new events are posted.\n\n"
+	response += fmt.Sprintf("<b>Your subscriptions:</b> %s\n\nUse /events to see current events.", strings.Join(prefs.GetStates(chatID), ", "))
+	return response
+}
+
+// handleSubscribeMajors turns on notifications for national/majors events, whatever
+// their state
+func handleSubscribeMajors(prefs preferences.Preferences, chatID string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if user.Majors {
+		return "ℹ️ You're already subscribed to national &amp; majors events.\n\nUse /list to see all your subscriptions."
+	}
+	user.Majors = true
+	*modified = true
+	return "✅ <b>Subscribed to national &amp; majors events!</b>\n\n" +
+		"You'll be notified about national championships and majors in any state, and digests list them in their own section.\n\n" +
+		"Use /unsubscribe majors to stop."
+}
+
+// handleUnsubscribeMajors turns off notifications for national/majors events outside
+// the user's other subscriptions
+func handleUnsubscribeMajors(prefs preferences.Preferences, chatID string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if !user.Majors {
+		return "ℹ️ You weren't subscribed to national &amp; majors events.\n\nUse /list to see your current subscriptions."
 	}
-	return "📍 <b>Select a state to subscribe:</b>\n\nOr type: /subscribe STATE", keyboard
+	user.Majors = false
+	*modified = true
+	return "✅ <b>Unsubscribed from national &amp; majors events</b>\n\nYou'll still get those in your subscribed states and cities."
 }
 
 // showManageSubscriptionsKeyboard shows current subscriptions with unsubscribe buttons
@@ -2788,46 +2551,14 @@ Use /subscribe to get started!`, nil
 	return text, keyboard
 }
 
-// showSettingsKeyboard shows user settings with digest options
-func showSettingsKeyboard(prefs preferences.Preferences, chatID string) (string, *telegram.InlineKeyboardMarkup) {
-	user := prefs.GetUser(chatID)
-
-	keyboard := &telegram.InlineKeyboardMarkup{
-		InlineKeyboard: [][]telegram.InlineKeyboardButton{
-			{
-				{Text: "📨 Immediate", CallbackData: "digest:immediate"},
-			},
-			{
-				{Text: "📅 Daily Digest", CallbackData: "digest:daily"},
-			},
-			{
-				{Text: "📆 Weekly Digest", CallbackData: "digest:weekly"},
-			},
-		},
-	}
-
-	text := fmt.Sprintf(`⚙️ <b>Settings</b>
-
-<b>Current digest frequency:</b> %s
-
-<b>Notification Mode:</b>
-• <b>Immediate</b> - Get notified as soon as new events are posted
-• <b>Daily</b> - Receive a daily digest at 9 AM UTC
-• <b>Weekly</b> - Receive a weekly digest on Mondays at 9 AM UTC
-
-Select your preferred mode:`, user.DigestFrequency)
-
-	return text, keyboard
-}
-
 // handleSubscribeWithKeyboard shows the subscription keyboard when /subscribe is called without args
-func handleSubscribeWithKeyboard(chatID, botToken string, dryRun bool) (string, []*event.Event) {
-	text, keyboard := showStateSelectionKeyboard()
+func handleSubscribeWithKeyboard(prefs preferences.Preferences, chatID, botToken string, dryRun bool) (string, []*event.Event) {
+	text, keyboard := showStateSelectionKeyboard(prefs, chatID, 0)
 
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
 			}
 			return "", nil // Already sent via keyboard
@@ -2842,9 +2573,9 @@ func handleManageWithKeyboard(prefs preferences.Preferences, chatID, botToken st
 	text, keyboard := showManageSubscriptionsKeyboard(prefs, chatID)
 
 	if keyboard != nil && !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
 			}
 			return "", nil // Already sent via keyboard
@@ -2859,9 +2590,9 @@ func handleSettingsWithKeyboard(prefs preferences.Preferences, chatID, botToken
 	text, keyboard := showSettingsKeyboard(prefs, chatID)
 
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
 			}
 			return "", nil // Already sent via keyboard
@@ -2889,9 +2620,9 @@ func handleUnsubscribeAllWithKeyboard(prefs preferences.Preferences, chatID, bot
 	}
 
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
 			}
 			return "", nil // Already sent via keyboard
@@ -2906,9 +2637,9 @@ func handleMenuWithKeyboard(chatID, botToken string, dryRun bool) (string, []*ev
 	text, keyboard := showMenuKeyboard()
 
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
 			}
 			return "", nil // Already sent via keyboard
@@ -2923,9 +2654,9 @@ func handleRemindersWithKeyboard(prefs preferences.Preferences, chatID, botToken
 	text, keyboard := showRemindersKeyboard(prefs, chatID)
 
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err == nil {
-			if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
 			}
 			return "", nil // Already sent via keyboard
@@ -3037,11 +2768,11 @@ Select an action below:`
 	return text, keyboard
 }
 
-func getUpdates(botToken string, offset int) ([]Update, error) {
-	return getUpdatesWithTimeout(botToken, offset, 0)
+func getUpdates(ctx context.Context, botToken string, offset int) ([]Update, error) {
+	return getUpdatesWithTimeout(ctx, botToken, offset, 0)
 }
 
-func getUpdatesWithTimeout(botToken string, offset int, timeoutSeconds int) ([]Update, error) {
+func getUpdatesWithTimeout(ctx context.Context, botToken string, offset int, timeoutSeconds int) ([]Update, error) {
 	url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates", botToken)
 	params := []string{}
 
@@ -3064,15 +2795,21 @@ func getUpdatesWithTimeout(botToken string, offset int, timeoutSeconds int) ([]U
 	}
 
 	client := &http.Client{Timeout: clientTimeout}
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("fetching updates: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Telegram API error (status %d): %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		statusErr := errs.Status(resp.StatusCode, "Telegram API error (status %d): %s", resp.StatusCode, string(body))
+		statusErr.RetryAfter = errs.ParseRetryAfter(resp.Header)
+		return nil, statusErr
 	}
 
 	var result struct {
@@ -3091,10 +2828,36 @@ func getUpdatesWithTimeout(botToken string, offset int, timeoutSeconds int) ([]U
 	return result.Result, nil
 }
 
+// recordDigestDelivery appends a digest send to the delivery log in the data directory.
+// Deliveries are grouped by the Actions run that sent them (GITHUB_RUN_ID).
+func recordDigestDelivery(chatID string, sendErr error) {
+	if snapshotStore == nil {
+		return
+	}
+	d := &storage.Delivery{
+		At:      clk.Now().UTC().Format(time.RFC3339),
+		RunID:   os.Getenv("GITHUB_RUN_ID"),
+		User:    errreport.HashChatID(chatID),
+		Channel: "telegram",
+		Type:    "digest",
+		Result:  storage.DeliverySent,
+	}
+	if sendErr != nil {
+		d.Result = storage.DeliveryFailed
+		if errs.IsPermanent(sendErr) {
+			d.Result = storage.DeliveryUnreachable
+		}
+		d.Error = sendErr.Error()
+	}
+	if err := snapshotStore.AppendDelivery(d); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: recording delivery: %v\n", err)
+	}
+}
+
 // sendDigest sends a digest message to a specific user
-func sendDigest(botToken, chatID, digestFile, digestType string) {
+func sendDigest(ctx context.Context, botToken, chatID, digestFile, digestType string) {
 	// Read digest events from file
-	f, err := os.Open(digestFile) // #nosec G304 - File path from CLI flag, user controlled
+	f, err := storage.OpenFile(digestFile) // Plain or gzip-compressed JSON
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening digest file: %v\n", err)
 		os.Exit(1)
@@ -3105,37 +2868,78 @@ func sendDigest(botToken, chatID, digestFile, digestType string) {
 		}
 	}()
 
-	var result struct {
-		NewEvents []*event.Event `json:"new_events"`
-	}
-
-	decoder := json.NewDecoder(f)
-	if err := decoder.Decode(&result); err != nil {
+	newEvents := make([]*event.Event, 0)
+	err = event.StreamDiff(f, event.DiffStream{OnNew: func(evt *event.Event) error {
+		newEvents = append(newEvents, evt)
+		return nil
+	}})
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing digest JSON: %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(result.NewEvents) == 0 {
+	if len(newEvents) == 0 {
 		fmt.Println("No events in digest")
 		return
 	}
 
+	// A retried run skips users who already got this period's digest
+	var ledger *storage.DigestLedger
+	period := digestPeriod(digestType, clk.Now())
+	if snapshotStore != nil {
+		if ledger, err = snapshotStore.LoadDigests(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading digest ledger: %v\n", err)
+			os.Exit(1)
+		}
+		if sent := ledger.Sent(errreport.HashChatID(chatID), period); sent != nil && !*forceDigest {
+			fmt.Printf("Already sent the %s digest to %s at %s; skipping (use --force to resend)\n", period, chatID, sent.At)
+			return
+		}
+	}
+
 	// Create Telegram client
-	client, err := telegram.NewClient(botToken, chatID)
+	client, err := newSender(botToken, chatID)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating Telegram client: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Format and send digest message
-	digestMsg := telegram.FormatDigest(result.NewEvents, digestType)
+	digestMsg := telegram.FormatDigest(newEvents, digestType)
 
-	if err := client.SendMessage(digestMsg); err != nil {
+	err = client.SendMessage(ctx, digestMsg)
+	recordDigestDelivery(chatID, err)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error sending digest: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Successfully sent %s digest with %d event(s) to %s\n", digestType, len(result.NewEvents), chatID)
+	if ledger != nil {
+		entry := &storage.DigestEntry{
+			At:     clk.Now().UTC().Format(time.RFC3339),
+			User:   errreport.HashChatID(chatID),
+			Period: period,
+			Events: len(newEvents),
+			RunID:  os.Getenv("GITHUB_RUN_ID"),
+		}
+		if err := ledger.Record(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: recording digest: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Successfully sent %s digest with %d event(s) to %s\n", digestType, len(newEvents), chatID)
+}
+
+// digestPeriod returns the ledger key for the digest period containing now: the UTC
+// date for daily digests (e.g. "daily:2026-10-15") and the ISO week for weekly ones
+// (e.g. "weekly:2026-W42")
+func digestPeriod(digestType string, now time.Time) string {
+	now = now.UTC()
+	if digestType == "weekly" {
+		year, week := now.ISOWeek()
+		return fmt.Sprintf("weekly:%d-W%02d", year, week)
+	}
+	return digestType + ":" + now.Format("2006-01-02")
 }
 
 // handleBulkWithKeyboard shows the bulk actions menu
@@ -3143,12 +2947,12 @@ func handleBulkWithKeyboard(prefs preferences.Preferences, chatID, botToken stri
 	text, keyboard := showBulkActionsKeyboard(prefs, chatID)
 
 	if !dryRun {
-		client, err := telegram.NewClient(botToken, chatID)
+		client, err := newSender(botToken, chatID)
 		if err != nil {
 			return "❌ Error displaying bulk actions menu", nil
 		}
 
-		if err := client.SendMessageWithKeyboard(text, keyboard); err != nil {
+		if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
 			return "❌ Error sending bulk actions menu", nil
 		}
 		return "", nil // Message sent via keyboard
@@ -3169,6 +2973,9 @@ func showBulkActionsKeyboard(prefs preferences.Preferences, chatID string) (stri
 
 Manage multiple events at once:
 
+<b>Select Mode:</b>
+• Check events from a list and mark or export them together
+
 <b>Event Status Cleanup:</b>
 • Clear all skipped events
 
@@ -3184,6 +2991,9 @@ Manage multiple events at once:
 
 	keyboard := &telegram.InlineKeyboardMarkup{
 		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{
+				{Text: "☑️ Select Events", CallbackData: "select:page:0"},
+			},
 			{
 				{Text: fmt.Sprintf("🗑 Clear Skipped (%d)", skippedCount), CallbackData: "bulk:clear-skipped"},
 			},
@@ -3218,65 +3028,62 @@ func handleBulkCallback(prefs preferences.Preferences, chatID, action string, mo
 
 		return fmt.Sprintf("✅ Cleared <b>%d</b> skipped event(s)", len(skippedEvents)), nil
 
-	case "export-registered":
-		// Export all registered events to a single calendar file
-		registeredEventIDs := user.GetEventsByStatus(preferences.EventStatusRegistered)
-		if len(registeredEventIDs) == 0 {
-			return "ℹ️ No registered events to export", nil
+	case "clear-maybe":
+		// Clear all Maybe events, from the /my-events group
+		maybeEvents := user.GetEventsByStatus(preferences.EventStatusMaybe)
+		if len(maybeEvents) == 0 {
+			return "ℹ️ No Maybe events to clear", nil
+		}
+
+		for _, eventID := range maybeEvents {
+			user.RemoveEventStatus(eventID)
 		}
+		*modified = true
+
+		return fmt.Sprintf("✅ Cleared <b>%d</b> Maybe event(s)", len(maybeEvents)), nil
 
-		// Fetch current events to get full event data
-		sc := scraper.New()
-		allEvents, err := sc.FetchEvents()
+	case "downgrade-interested":
+		// Interested events whose registration has closed become Maybe
+		allEvents, err := fetchEvents()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
 			return "❌ Error fetching event data", nil
 		}
-
-		// Find registered events
-		var registeredEvents []*event.Event
-		for _, evt := range allEvents {
-			for _, regID := range registeredEventIDs {
-				if evt.ID == regID {
-					registeredEvents = append(registeredEvents, evt)
-					break
-				}
-			}
-		}
-
-		if len(registeredEvents) == 0 {
-			return "ℹ️ None of your registered events are currently available on the VGA website", nil
+		eventIDs := pastDeadlineEventIDs(user, allEvents)
+		if len(eventIDs) == 0 {
+			return "ℹ️ None of your Interested events are past their registration deadline", nil
 		}
+		responseText, _ := handleBulkStatus(prefs, chatID, preferences.EventStatusMaybe, eventIDs, modified)
+		return responseText, nil
 
-		// Generate combined .ics file
-		icsContent := calendar.GenerateMultiEventICS(registeredEvents)
-		filename := "vga-registered-events.ics"
+	case "export-registered", "export-interested", "export-maybe":
+		// Export a status group to a single calendar file
+		return exportStatusGroup(prefs, chatID, strings.TrimPrefix(action, "export-"), botToken, dryRun), nil
 
-		if !dryRun {
-			client, err := telegram.NewClient(botToken, chatID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-				return errSendingCalendarFile, nil
-			}
+	default:
+		return "❌ Unknown bulk action", nil
+	}
+}
 
-			caption := fmt.Sprintf("📅 <b>Your Registered Events</b>\n\n%d event(s) ready to import to your calendar!", len(registeredEvents))
-			if err := client.SendDocument(filename, []byte(icsContent), caption); err != nil {
-				fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
-				return errSendingCalendarFile, nil
-			}
+// logPreferencesSize prints the preferences document size and warns when it nears the Gist limit
+func logPreferencesSize(prefs preferences.Preferences) {
+	report, err := preferences.MeasureSize(prefs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not measure preferences size: %v\n", err)
+		return
+	}
 
-			return fmt.Sprintf("✅ Calendar file sent with <b>%d</b> registered event(s)!", len(registeredEvents)), nil
+	fmt.Printf("Preferences size: %s (%.1f%% of Gist limit)\n", preferences.FormatBytes(report.Total), report.PercentOfLimit())
+	if warning := report.Warning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "⚠️ Warning: %s\n", warning)
+		for _, u := range report.Largest(3) {
+			fmt.Fprintf(os.Stderr, "   user %s: %s\n", u.ChatID, preferences.FormatBytes(u.Bytes))
 		}
-
-		return fmt.Sprintf("[DRY RUN] Would export %d registered event(s) to calendar", len(registeredEvents)), nil
-
-	default:
-		return "❌ Unknown bulk action", nil
 	}
 }
 
 // archiveWeeklyStatsForAllUsers archives the current week's stats to history for all users
-func archiveWeeklyStatsForAllUsers(prefs preferences.Preferences, storage *preferences.GistStorage) {
+func archiveWeeklyStatsForAllUsers(ctx context.Context, prefs preferences.Preferences, storage *preferences.GistStorage) {
 	fmt.Println("📊 Archiving weekly stats for all users...")
 
 	chatIDs := prefs.GetAllUsers()
@@ -3293,19 +3100,37 @@ func archiveWeeklyStatsForAllUsers(prefs preferences.Preferences, storage *prefe
 		}
 
 		// Archive current week to history
-		user.ArchiveCurrentWeek()
+		user.ArchiveCurrentWeekAt(clk.Now())
 		archivedCount++
 
 		fmt.Printf("✅ Archived stats for user %s\n", chatID)
 	}
 
+	// Drop travel subscriptions for trips that are over
+	if expired := prefs.ExpireTravel(clk.Now()); expired > 0 {
+		fmt.Printf("✈️ Removed %d finished trip(s)\n", expired)
+	}
+
+	// Move statuses and notes of long-past or removed events out of the live preferences
+	if *archiveAfterDays > 0 {
+		lookup, err := loadEventLookup()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping event archive, could not fetch events: %v\n", err)
+		} else {
+			archived := archiveStaleEvents(prefs, lookup, *archiveAfterDays, clk.Now())
+			fmt.Printf("🗄 Archived %d stale tracked event(s)\n", archived)
+		}
+	}
+
 	// Save updated preferences
-	if err := storage.Save(prefs); err != nil {
+	if err := savePreferences(ctx, storage, prefs); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+		errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
 		os.Exit(1)
 	}
 
 	fmt.Printf("✅ Successfully archived stats for %d user(s)\n", archivedCount)
+	logPreferencesSize(prefs)
 }
 
 // handleNotifyRemovals toggles the removal notification setting
@@ -3373,7 +3198,7 @@ func handleFilterDate(prefs preferences.Preferences, chatID, dateRange string, m
 	user := prefs.GetUser(chatID)
 
 	// Parse date range
-	from, to, err := filter.ParseDateRange(dateRange)
+	from, to, err := filter.ParseDateRangeAt(dateRange, clk.Now())
 	if err != nil {
 		return fmt.Sprintf("❌ Invalid date range: %s\n\nExamples:\n• /filter date \"Mar 1-15\"\n• /filter date \"March 1 - April 15\"\n• /filter date \"March\"", err.Error()), nil
 	}
@@ -3487,6 +3312,46 @@ func handleFilterCity(prefs preferences.Preferences, chatID, cityName string, mo
 	return fmt.Sprintf("✅ City filter added: <b>%s</b>\n\nActive filter:\n%s\n\nUse /filter save \"name\" to save this filter.", cityName, activeFilter.String()), nil
 }
 
+// handleFilterTag adds a tag filter
+func handleFilterTag(prefs preferences.Preferences, chatID, tagName string, modified *bool) (string, []*event.Event) {
+	user := prefs.GetUser(chatID)
+
+	rule, ok := event.LookupTag(strings.Trim(tagName, "\""))
+	if !ok {
+		return fmt.Sprintf("❌ Unknown tag: %s\n\nTags: %s", html.EscapeString(tagName), strings.Join(tagNames(), ", ")), nil
+	}
+
+	// Get or create current filter
+	activeFilter := user.GetActiveFilter()
+	if activeFilter == nil {
+		activeFilter = filter.NewFilter()
+	} else {
+		// Clone to avoid modifying saved preset
+		activeFilter = activeFilter.Clone()
+	}
+
+	// Add tag to filter (if not already present)
+	if !slices.Contains(activeFilter.Tags, rule.Tag) {
+		activeFilter.Tags = append(activeFilter.Tags, rule.Tag)
+	}
+
+	// Save as temporary active filter
+	user.SaveFilter("_temp_active", activeFilter)
+	user.SetActiveFilter("_temp_active")
+	*modified = true
+
+	return fmt.Sprintf("✅ Tag filter added: %s <b>%s</b>\n\nActive filter:\n%s\n\nUse /filter save \"name\" to save this filter.", rule.Emoji, rule.Label, activeFilter.String()), nil
+}
+
+// tagNames lists the tags /filter tag accepts
+func tagNames() []string {
+	names := make([]string, len(event.TagRules))
+	for i, rule := range event.TagRules {
+		names[i] = rule.Tag
+	}
+	return names
+}
+
 // handleFilterWeekends toggles weekends-only filter
 func handleFilterWeekends(prefs preferences.Preferences, chatID string, modified *bool) (string, []*event.Event) {
 	user := prefs.GetUser(chatID)
@@ -3644,12 +3509,16 @@ func handleFiltersList(prefs preferences.Preferences, chatID string) (string, []
 		if filterObj != nil {
 			msg.WriteString(fmt.Sprintf("   %s\n", filterObj.String()))
 		}
+		if report := user.GetScheduledReport(name); report != nil {
+			msg.WriteString(fmt.Sprintf("   🗓 Sent %s\n", formatReportSchedule(report)))
+		}
 		msg.WriteString("\n")
 	}
 
 	msg.WriteString("\n<b>Commands:</b>\n")
 	msg.WriteString("• /filter load \"name\" - Load a filter\n")
 	msg.WriteString("• /filter delete \"name\" - Delete a filter\n")
+	msg.WriteString("• /filter schedule \"name\" weekly - Get its matches weekly\n")
 	msg.WriteString("• /filter clear - Clear active filter")
 
 	return msg.String(), nil
@@ -3,6 +3,9 @@ package main
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/testutil"
 )
 
 func TestGetCommandHelp(t *testing.T) {
@@ -103,3 +106,30 @@ func TestGetHelpMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestRateLimiterWindow(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC))
+	rl := NewRateLimiterWithClock(2, time.Minute, clock)
+
+	if !rl.Allow("1") || !rl.Allow("1") {
+		t.Fatal("the first two requests should be allowed")
+	}
+	clock.Advance(20 * time.Second)
+	if wait := rl.Check("1"); wait != 40*time.Second {
+		t.Errorf("Check() over the limit = %v, want 40s", wait)
+	}
+	if !rl.Allow("2") {
+		t.Error("limits should be per key")
+	}
+
+	clock.Advance(41 * time.Second)
+	if !rl.Allow("1") {
+		t.Error("request after the window should be allowed")
+	}
+
+	clock.Advance(2 * time.Minute)
+	rl.CleanupOldEntries()
+	if len(rl.requests) != 0 {
+		t.Errorf("CleanupOldEntries() left %d keys", len(rl.requests))
+	}
+}
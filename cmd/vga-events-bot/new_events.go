@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// newEventsPageSize is how many events each page of the "View all" list shows
+const newEventsPageSize = 10
+
+// newEventsSince returns the listed events matching the user's subscriptions (states,
+// cities, trips, followed courses, and majors) that they first saw at or after since
+// (Unix seconds), soonest first
+func newEventsSince(user *preferences.UserPreferences, allEvents []*event.Event, since int64) []*event.Event {
+	return userQuery(user).Subscriptions().
+		Where(func(evt *event.Event) bool {
+			seenAt, ok := user.SeenEventIDs[evt.ID]
+			return ok && seenAt >= since
+		}).
+		Sort().Run(allEvents)
+}
+
+// buildNewEventsPage renders one page of the new events list opened from the
+// "and N more" notification
+func buildNewEventsPage(events []*event.Event, since int64, page int) (string, *telegram.InlineKeyboardMarkup) {
+	if len(events) == 0 {
+		return "📋 <b>New Events</b>\n\nThese events are no longer listed. Use /events to see what's current.", nil
+	}
+
+	totalPages := (len(events) + newEventsPageSize - 1) / newEventsPageSize
+	page = max(0, min(page, totalPages-1))
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("📋 <b>New Events</b> (%d)\n\nPage %d/%d, soonest first:\n\n", len(events), page+1, totalPages))
+
+	start := page * newEventsPageSize
+	end := min(start+newEventsPageSize, len(events))
+	for _, evt := range events[start:end] {
+		msg.WriteString("• ")
+		if evt.ShortCode != "" {
+			msg.WriteString(fmt.Sprintf("<code>%s</code> ", evt.ShortCode))
+		}
+		if evt.DateText != "" {
+			msg.WriteString(fmt.Sprintf("%s · ", html.EscapeString(evt.DateText)))
+		}
+		msg.WriteString(fmt.Sprintf("<b>%s</b>", html.EscapeString(evt.Title)))
+		if evt.City != "" {
+			msg.WriteString(fmt.Sprintf(" — %s, %s", html.EscapeString(evt.City), evt.State))
+		} else {
+			msg.WriteString(fmt.Sprintf(" — %s", evt.State))
+		}
+		msg.WriteString("\n")
+	}
+	msg.WriteString("\n<i>Use a code with /note or /bulk to track an event.</i>")
+
+	var nav []telegram.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, telegram.InlineKeyboardButton{Text: "◀️ Prev", CallbackData: fmt.Sprintf("new:%d:%d", since, page-1)})
+	}
+	if page < totalPages-1 {
+		nav = append(nav, telegram.InlineKeyboardButton{Text: "Next ▶️", CallbackData: fmt.Sprintf("new:%d:%d", since, page+1)})
+	}
+	if len(nav) == 0 {
+		return msg.String(), nil
+	}
+	return msg.String(), &telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{nav}}
+}
+
+// handleNewEventsCallback handles the "View all" button and its page navigation.
+// Format: new:SINCE:PAGE
+func handleNewEventsCallback(callbackData string, prefs preferences.Preferences, chatID string) (string, *telegram.InlineKeyboardMarkup) {
+	parts := strings.Split(callbackData, ":")
+	if len(parts) != 3 {
+		return "❌ Invalid request", nil
+	}
+	since, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "❌ Invalid request", nil
+	}
+	page, _ := strconv.Atoi(parts[2])
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	return buildNewEventsPage(newEventsSince(prefs.GetUser(chatID), allEvents, since), since, page)
+}
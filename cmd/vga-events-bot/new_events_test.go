@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestNewEventsSince(t *testing.T) {
+	user := &preferences.UserPreferences{
+		States:       []string{"NV"},
+		Cities:       []preferences.CitySubscription{{City: "Phoenix", State: "AZ"}},
+		SeenEventIDs: map[string]int64{"old": 100, "new1": 200, "new2": 250, "ca": 200, "az": 300},
+	}
+	events := []*event.Event{
+		{ID: "old", State: "NV", DateText: "01.01.27"},
+		{ID: "new1", State: "NV", DateText: "03.01.27"},
+		{ID: "new2", State: "NV", DateText: "02.01.27"},
+		{ID: "ca", State: "CA", DateText: "02.01.27"},
+		{ID: "unseen", State: "NV", DateText: "02.01.27"},
+		{ID: "az", State: "AZ", City: "Phoenix", DateText: "04.01.27"},
+	}
+
+	got := newEventsSince(user, events, 200)
+	if len(got) != 3 || got[0].ID != "new2" || got[1].ID != "new1" || got[2].ID != "az" {
+		ids := make([]string, len(got))
+		for i, evt := range got {
+			ids[i] = evt.ID
+		}
+		t.Errorf("newEventsSince() = %v, want [new2 new1 az]", ids)
+	}
+}
+
+func TestBuildNewEventsPage(t *testing.T) {
+	var events []*event.Event
+	for i := 0; i < 25; i++ {
+		events = append(events, &event.Event{ID: fmt.Sprintf("e%d", i), State: "NV", Title: fmt.Sprintf("Course <%d>", i), ShortCode: fmt.Sprintf("NV-%d", i)})
+	}
+
+	text, keyboard := buildNewEventsPage(events, 123, 0)
+	if !strings.Contains(text, "Page 1/3") || !strings.Contains(text, "NV-0") || strings.Contains(text, "NV-10<") {
+		t.Errorf("first page text = %q", text)
+	}
+	if strings.Contains(text, "<0>") {
+		t.Error("titles should be HTML-escaped")
+	}
+	if keyboard == nil || len(keyboard.InlineKeyboard[0]) != 1 || keyboard.InlineKeyboard[0][0].CallbackData != "new:123:1" {
+		t.Errorf("first page should only have a Next button, got %+v", keyboard)
+	}
+
+	_, keyboard = buildNewEventsPage(events, 123, 1)
+	if keyboard == nil || len(keyboard.InlineKeyboard[0]) != 2 {
+		t.Errorf("middle page should have Prev and Next, got %+v", keyboard)
+	}
+
+	// Out-of-range pages clamp to the last page
+	text, _ = buildNewEventsPage(events, 123, 9)
+	if !strings.Contains(text, "Page 3/3") {
+		t.Errorf("out-of-range page text = %q, want last page", text)
+	}
+
+	if text, keyboard := buildNewEventsPage(nil, 123, 0); keyboard != nil || !strings.Contains(text, "no longer listed") {
+		t.Errorf("empty list = %q, %+v", text, keyboard)
+	}
+}
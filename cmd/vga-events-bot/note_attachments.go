@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// maxTranscribeSeconds is the longest voice message sent for transcription; longer ones
+// are kept as recordings
+const maxTranscribeSeconds = 120
+
+const voiceUsage = "🎤 To add a voice note, open the note first with /note &lt;event_id&gt;, then send the voice message."
+
+const attachUsage = "📎 To attach this to an event note, send it again with the caption:\n/note &lt;event_id&gt; [note text]"
+
+// messageAttachment returns the photo (largest size) or file in a message, or nil
+func messageAttachment(msg *Message) *preferences.NoteAttachment {
+	if n := len(msg.Photo); n > 0 {
+		largest := msg.Photo[n-1]
+		return &preferences.NoteAttachment{FileID: largest.FileID, Kind: preferences.AttachmentPhoto, Size: largest.FileSize}
+	}
+	if msg.Document != nil {
+		return &preferences.NoteAttachment{FileID: msg.Document.FileID, Kind: preferences.AttachmentDocument, Size: msg.Document.FileSize}
+	}
+	return nil
+}
+
+// handleNoteAttachment attaches a photo or file to the event note named in its caption
+// ("/note NV-417 Pairing sheet"); any text after the event ID becomes the note
+func handleNoteAttachment(prefs preferences.Preferences, chatID string, msg *Message, modified *bool) string {
+	attachment := messageAttachment(msg)
+	parts := strings.Fields(msg.Caption)
+	if attachment == nil || len(parts) < 2 || normalizeCommand(parts[0]) != "/note" {
+		return attachUsage
+	}
+
+	eventIDs, unknown := resolveEventIDs(parts[1:2])
+	if len(unknown) > 0 {
+		return formatUnknownCodes(unknown)
+	}
+	eventID := eventIDs[0]
+
+	noteText := ""
+	if len(parts) > 2 {
+		var errMsg string
+		if noteText, errMsg = validateUserInput(strings.Join(parts[2:], " "), 500, "Note text"); errMsg != "" {
+			return errMsg
+		}
+	}
+
+	user := prefs.GetUser(chatID)
+	if err := user.SetNoteAttachment(eventID, attachment); err != nil {
+		return attachErrorMessage(err)
+	}
+	if noteText != "" {
+		user.SetEventNote(eventID, noteText)
+	}
+	*modified = true
+
+	response := fmt.Sprintf("📎 Attached to your note for event <code>%s</code>.", eventID)
+	if note := user.GetEventNote(eventID); note != "" {
+		response += fmt.Sprintf("\n\n<i>%s</i>", note)
+	}
+	return response + fmt.Sprintf("\n\nUse /note %s to see it again.", eventID)
+}
+
+// attachErrorMessage explains why SetNoteAttachment refused a file
+func attachErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, preferences.ErrAttachmentTooLarge):
+		return fmt.Sprintf("❌ That file is too large to attach (max %d MB).", preferences.MaxAttachmentBytes>>20)
+	case errors.Is(err, preferences.ErrTooManyAttachments):
+		return fmt.Sprintf("❌ You already have %d notes with attachments.\n\nRemove one with /note &lt;event_id&gt; detach first.", preferences.MaxNoteAttachments)
+	}
+	return "❌ Couldn't attach the file."
+}
+
+// handleShowNote shows an event's note, re-sending its attachment with the note as the
+// caption when there is one. A voice message sent next is added to this note.
+func handleShowNote(prefs preferences.Preferences, chatID, eventID string, modified *bool, botToken string, dryRun bool) string {
+	user := prefs.GetUser(chatID)
+	user.SetPendingNote(eventID, clk.Now())
+	*modified = true
+
+	voiceHint := fmt.Sprintf("🎤 Send a voice message in the next %d minutes to add to this note.", int(preferences.PendingNoteWindow.Minutes()))
+	note := user.GetEventNote(eventID)
+	attachment := user.GetNoteAttachment(eventID)
+	if note == "" && attachment == nil {
+		return fmt.Sprintf("ℹ️ No note yet for event <code>%s</code>\n\nUsage: /note &lt;event_id&gt; &lt;note_text&gt;\n\n%s", eventID, voiceHint)
+	}
+
+	text := fmt.Sprintf("📝 Note for event <code>%s</code>", eventID)
+	if note != "" {
+		text += fmt.Sprintf(":\n\n<i>%s</i>", note)
+	}
+	text += "\n\n" + voiceHint
+	if attachment == nil {
+		return text
+	}
+	return sendNoteAttachment(chatID, attachment, text, botToken, dryRun)
+}
+
+// sendNoteAttachment re-sends an attachment by file_id with caption, returning "" once
+// sent, or caption with an explanation if it couldn't be
+func sendNoteAttachment(chatID string, attachment *preferences.NoteAttachment, caption string, botToken string, dryRun bool) string {
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would re-send %s %s\n\n%s", attachment.Kind, attachment.FileID, caption)
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return caption
+	}
+	switch attachment.Kind {
+	case preferences.AttachmentPhoto:
+		err = client.SendPhoto(botCtx, attachment.FileID, caption)
+	case preferences.AttachmentVoice:
+		err = client.SendVoice(botCtx, attachment.FileID, caption)
+	default:
+		err = client.SendDocumentID(botCtx, attachment.FileID, caption)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error re-sending note attachment: %v\n", err)
+		return caption + "\n\n⚠️ The attachment is no longer available; send it again to re-attach it."
+	}
+	return ""
+}
+
+// handleDetachNote removes the attachment from an event's note, keeping the text
+func handleDetachNote(prefs preferences.Preferences, chatID, eventID string, modified *bool) string {
+	if !prefs.GetUser(chatID).RemoveNoteAttachment(eventID) {
+		return fmt.Sprintf("ℹ️ The note for event <code>%s</code> has no attachment", eventID)
+	}
+	*modified = true
+	return fmt.Sprintf("✅ Attachment removed from the note for event <code>%s</code>", eventID)
+}
+
+// handleVoiceNote adds a voice message to the note for the event in its caption
+// ("/note NV-417"), or else the note opened last with /note. With a transcription
+// provider configured the speech is added to the note as text; otherwise, or if
+// transcription fails, the recording is kept and re-sent when the note is shown.
+func handleVoiceNote(prefs preferences.Preferences, chatID string, msg *Message, modified *bool, botToken string, dryRun bool) string {
+	user := prefs.GetUser(chatID)
+	if user.PendingNote != nil {
+		*modified = true
+	}
+	eventID := user.TakePendingNote(clk.Now())
+	if parts := strings.Fields(msg.Caption); len(parts) >= 2 && normalizeCommand(parts[0]) == "/note" {
+		eventIDs, unknown := resolveEventIDs(parts[1:2])
+		if len(unknown) > 0 {
+			return formatUnknownCodes(unknown)
+		}
+		eventID = eventIDs[0]
+	}
+	if eventID == "" {
+		return voiceUsage
+	}
+
+	warning := ""
+	if transcriber != nil && msg.Voice.Duration <= maxTranscribeSeconds {
+		if dryRun {
+			return fmt.Sprintf("[DRY RUN] Would transcribe the voice message into the note for event <code>%s</code>", eventID)
+		}
+		text, err := transcribeVoice(chatID, msg.Voice.FileID, botToken)
+		if err == nil {
+			note := text
+			if existing := user.GetEventNote(eventID); existing != "" {
+				note = existing + "\n" + text
+			}
+			if note, errMsg := validateUserInput(note, 500, "Note text"); errMsg == "" {
+				user.SetEventNote(eventID, note)
+				*modified = true
+				return fmt.Sprintf("🎤 Transcribed and added to your note for event <code>%s</code>:\n\n<i>%s</i>", eventID, html.EscapeString(text))
+			}
+			warning = "⚠️ The note is full, so the recording was saved instead.\n\n"
+		} else {
+			fmt.Fprintf(os.Stderr, "Error transcribing voice note: %v\n", err)
+			warning = "⚠️ Couldn't transcribe it, so the recording was saved instead.\n\n"
+		}
+	}
+
+	attachment := &preferences.NoteAttachment{FileID: msg.Voice.FileID, Kind: preferences.AttachmentVoice, Size: msg.Voice.FileSize}
+	if err := user.SetNoteAttachment(eventID, attachment); err != nil {
+		return attachErrorMessage(err)
+	}
+	*modified = true
+	return fmt.Sprintf("%s🎤 Voice note saved for event <code>%s</code>. Use /note %s to play it.", warning, eventID, eventID)
+}
+
+// transcribeVoice downloads a voice message and returns its text from the provider
+func transcribeVoice(chatID, fileID, botToken string) (string, error) {
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return "", err
+	}
+	audio, err := client.DownloadFile(botCtx, fileID)
+	if err != nil {
+		return "", fmt.Errorf("downloading voice message: %w", err)
+	}
+	return transcriber.Transcribe(botCtx, "voice.oga", audio)
+}
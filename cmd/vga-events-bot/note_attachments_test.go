@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestHandleNoteAttachment(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+	photo := []PhotoSize{{FileID: "small", FileSize: 1000}, {FileID: "large", FileSize: 90000}}
+
+	if response := handleNoteAttachment(prefs, "111", &Message{Photo: photo, Caption: "nice shot"}, &modified); response != attachUsage || modified {
+		t.Errorf("caption without /note: %q", response)
+	}
+
+	response := handleNoteAttachment(prefs, "111", &Message{Photo: photo, Caption: "/note evt1 Pairing sheet"}, &modified)
+	user := prefs.GetUser("111")
+	if attachment := user.GetNoteAttachment("evt1"); !modified || attachment == nil || attachment.FileID != "large" || attachment.Kind != preferences.AttachmentPhoto {
+		t.Fatalf("attach: %q, %+v", response, attachment)
+	}
+	if user.GetEventNote("evt1") != "Pairing sheet" {
+		t.Errorf("caption text should become the note, got %q", user.GetEventNote("evt1"))
+	}
+
+	doc := &Message{Document: &Document{FileID: "doc", FileSize: preferences.MaxAttachmentBytes + 1}, Caption: "/note evt2"}
+	if response := handleNoteAttachment(prefs, "111", doc, &modified); !strings.Contains(response, "too large") {
+		t.Errorf("oversized file: %q", response)
+	}
+
+	if response := handleShowNote(prefs, "111", "evt1", &modified, "", true); !strings.Contains(response, "Would re-send photo large") || !strings.Contains(response, "Pairing sheet") {
+		t.Errorf("show: %q", response)
+	}
+
+	handleDetachNote(prefs, "111", "evt1", &modified)
+	if user.GetNoteAttachment("evt1") != nil || user.GetEventNote("evt1") == "" {
+		t.Error("detach should remove just the attachment")
+	}
+}
+
+func TestHandleVoiceNote(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+	voice := &Message{Voice: &Voice{FileID: "voice1", Duration: 12, FileSize: 20000}}
+
+	if response := handleVoiceNote(prefs, "111", voice, &modified, "", true); response != voiceUsage {
+		t.Errorf("voice without an open note: %q", response)
+	}
+
+	handleShowNote(prefs, "111", "evt1", &modified, "", true)
+	response := handleVoiceNote(prefs, "111", voice, &modified, "", true)
+	user := prefs.GetUser("111")
+	if attachment := user.GetNoteAttachment("evt1"); attachment == nil || attachment.Kind != preferences.AttachmentVoice {
+		t.Fatalf("voice should be kept without a transcriber: %q, %+v", response, attachment)
+	}
+	if user.PendingNote != nil {
+		t.Error("the open note should be used up")
+	}
+	if response := handleVoiceNote(prefs, "111", voice, &modified, "", true); response != voiceUsage {
+		t.Errorf("second voice message: %q", response)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// maxPreviewDigestEvents limits how many events the sample digest lists
+const maxPreviewDigestEvents = 5
+
+// previewMessage is one rendered sample notification
+type previewMessage struct {
+	Label    string
+	Text     string
+	Keyboard *telegram.InlineKeyboardMarkup
+	Course   *telegram.CourseDetails // Set for the new-event sample so the course photo is sent too
+}
+
+// newSamplePreviewEvent returns an event a week out, used when no real event matches the user's settings
+func newSamplePreviewEvent() *event.Event {
+	return &event.Event{
+		ID:       "sample0000000000",
+		State:    "NV",
+		Title:    "Sample Golf Course",
+		DateText: clk.Now().UTC().AddDate(0, 0, 7).Format("Jan 2 2006"),
+		City:     "Las Vegas",
+	}
+}
+
+// selectPreviewEvents picks upcoming events the user would actually be notified about:
+// in their subscribed states, within their days-ahead horizon, and passing their active
+// filter. Falls back to a sample event.
+func selectPreviewEvents(user *preferences.UserPreferences, allEvents []*event.Event) ([]*event.Event, bool) {
+	candidates := userQuery(user).States().Upcoming().Horizon().ApplyFilter().Sort().Run(allEvents)
+	if len(candidates) == 0 {
+		return []*event.Event{newSamplePreviewEvent()}, false
+	}
+	return candidates, true
+}
+
+// previewReminderDays returns the reminder offset to show in the sample reminder
+func previewReminderDays(user *preferences.UserPreferences) int {
+	if len(user.ReminderDays) > 0 {
+		return user.ReminderDays[0]
+	}
+	return 1
+}
+
+// previewDigestFrequency returns the digest frequency to show in the sample digest
+func previewDigestFrequency(user *preferences.UserPreferences) string {
+	if user.DigestFrequency == "daily" || user.DigestFrequency == "weekly" {
+		return user.DigestFrequency
+	}
+	return "daily"
+}
+
+// describeDelivery summarizes when the user's real notifications are delivered
+func describeDelivery(user *preferences.UserPreferences) string {
+	var sb strings.Builder
+
+	switch user.DigestFrequency {
+	case "daily", "weekly":
+		sb.WriteString("• New events: " + formatDigestTime(user) + "\n")
+	default:
+		sb.WriteString("• New events: sent immediately\n")
+	}
+
+	if len(user.ReminderDays) == 0 {
+		sb.WriteString("• Reminders: off\n")
+	} else {
+		days := make([]string, len(user.ReminderDays))
+		for i, d := range user.ReminderDays {
+			days[i] = fmt.Sprintf("%d", d)
+		}
+		sb.WriteString(fmt.Sprintf("• Reminders: %s day(s) before, at 09:00 UTC\n", strings.Join(days, ", ")))
+	}
+
+	if user.GetActiveFilter() != nil {
+		sb.WriteString(fmt.Sprintf("• Active filter: %s\n", user.ActiveFilter))
+	}
+
+	return sb.String()
+}
+
+// buildNotificationPreviews renders a sample new-event notification, reminder, and digest
+// exactly as the notifier would send them for this user
+func buildNotificationPreviews(user *preferences.UserPreferences, events []*event.Event, course *telegram.CourseDetails) []previewMessage {
+	evt := events[0]
+
+	newEventMsg := telegram.FormatEventWithCourse(evt, course, user.GetEventNote(evt.ID))
+	reminderMsg, reminderKeyboard := telegram.FormatReminder(evt, previewReminderDays(user))
+
+	digestEvents := events
+	if len(digestEvents) > maxPreviewDigestEvents {
+		digestEvents = digestEvents[:maxPreviewDigestEvents]
+	}
+
+	return []previewMessage{
+		{Label: "New event", Text: newEventMsg, Keyboard: telegram.CourseKeyboard(course), Course: course},
+		{Label: "Reminder", Text: reminderMsg, Keyboard: reminderKeyboard},
+		{Label: "Digest", Text: telegram.FormatDigest(digestEvents, previewDigestFrequency(user))},
+	}
+}
+
+// handleTestNotification sends the user sample notifications rendered with their current settings
+func handleTestNotification(prefs preferences.Preferences, chatID, botToken string, dryRun bool) (string, []*event.Event) {
+	user := prefs.GetUser(chatID)
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	events, found := selectPreviewEvents(user, allEvents)
+
+	header := "🧪 <b>Test Notifications</b>\n\nHere's what your notifications look like with your current settings:\n\n"
+	header += describeDelivery(user)
+	if !found {
+		header += "\n<i>No upcoming events match your states and filters, so a sample event is used.</i>"
+	}
+
+	var course *telegram.CourseDetails
+	if found {
+		course = getCourseDetails(events[0])
+	}
+	previews := buildNotificationPreviews(user, events, course)
+
+	if dryRun {
+		labels := make([]string, len(previews))
+		for i, p := range previews {
+			labels[i] = p.Label
+		}
+		return fmt.Sprintf("%s\n\n[DRY RUN] Would send %d sample notification(s): %s", header, len(previews), strings.Join(labels, ", ")), nil
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return "❌ Error sending test notifications", nil
+	}
+
+	if err := client.SendMessage(botCtx, header); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending header: %v\n", err)
+	}
+
+	for i, p := range previews {
+		sendCourseImage(client, p.Course)
+
+		var sendErr error
+		if p.Keyboard != nil {
+			sendErr = client.SendMessageWithKeyboard(botCtx, p.Text, p.Keyboard)
+		} else {
+			sendErr = client.SendMessage(botCtx, p.Text)
+		}
+		if sendErr != nil {
+			fmt.Fprintf(os.Stderr, "Error sending %s preview: %v\n", p.Label, sendErr)
+		}
+
+		// Rate limiting
+		if i < len(previews)-1 {
+			if telegram.Pause(botCtx, 1*time.Second) != nil {
+				break // Shutting down
+			}
+		}
+	}
+
+	return "", nil // Already sent
+}
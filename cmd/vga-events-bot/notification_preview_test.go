@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func futureDate(days int) string {
+	return time.Now().UTC().AddDate(0, 0, days).Format("Jan 2 2006")
+}
+
+func TestSelectPreviewEvents(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("12345")
+	user.States = []string{"NV"}
+
+	events := []*event.Event{
+		{ID: "ca1", State: "CA", Title: "Pebble Beach", DateText: futureDate(3)},
+		{ID: "nv2", State: "NV", Title: "Shadow Creek", DateText: futureDate(10), City: "Las Vegas"},
+		{ID: "nv1", State: "NV", Title: "Wolf Creek", DateText: futureDate(5), City: "Mesquite"},
+		{ID: "nvold", State: "NV", Title: "Old Event", DateText: futureDate(-5)},
+	}
+
+	selected, found := selectPreviewEvents(user, events)
+	if !found {
+		t.Fatal("expected real events to be selected")
+	}
+	if len(selected) != 2 || selected[0].ID != "nv1" {
+		t.Errorf("selected = %v, want [nv1 nv2] sorted by date", eventIDs(selected))
+	}
+
+	// Active filter narrows the selection
+	f := filter.NewFilter()
+	f.Cities = []string{"Las Vegas"}
+	user.SaveFilter("vegas", f)
+	user.SetActiveFilter("vegas")
+
+	selected, _ = selectPreviewEvents(user, events)
+	if len(selected) != 1 || selected[0].ID != "nv2" {
+		t.Errorf("selected with filter = %v, want [nv2]", eventIDs(selected))
+	}
+
+	// Nothing matches: sample event
+	user.States = []string{"TX"}
+	selected, found = selectPreviewEvents(user, events)
+	if found || len(selected) != 1 || selected[0].ID != "sample0000000000" {
+		t.Errorf("expected sample event fallback, got %v (found=%v)", eventIDs(selected), found)
+	}
+}
+
+func TestBuildNotificationPreviews(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("12345")
+	user.ReminderDays = []int{3, 7}
+	user.DigestFrequency = "weekly"
+
+	events := []*event.Event{
+		{ID: "nv1", State: "NV", Title: "Wolf Creek", DateText: futureDate(5), City: "Mesquite"},
+	}
+
+	previews := buildNotificationPreviews(user, events, nil)
+	if len(previews) != 3 {
+		t.Fatalf("got %d previews, want 3", len(previews))
+	}
+
+	checks := []struct {
+		label string
+		want  string
+	}{
+		{"New event", "Wolf Creek"},
+		{"Reminder", "In 3 days"},
+		{"Digest", "Weekly digest"},
+	}
+	for i, c := range checks {
+		if previews[i].Label != c.label {
+			t.Errorf("preview %d label = %q, want %q", i, previews[i].Label, c.label)
+		}
+		if !strings.Contains(previews[i].Text, c.want) {
+			t.Errorf("%s preview missing %q, got:\n%s", c.label, c.want, previews[i].Text)
+		}
+	}
+}
+
+func TestDescribeDelivery(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("12345")
+
+	user.DigestFrequency = "daily"
+	user.DigestHour = 18
+	user.ReminderDays = []int{1, 7}
+	got := describeDelivery(user)
+	for _, want := range []string{"daily digest at 18:00 UTC", "1, 7 day(s) before"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describeDelivery() missing %q, got:\n%s", want, got)
+		}
+	}
+
+	user.DigestFrequency = "immediate"
+	user.ReminderDays = nil
+	got = describeDelivery(user)
+	for _, want := range []string{"sent immediately", "Reminders: off"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describeDelivery() missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func eventIDs(events []*event.Event) []string {
+	ids := make([]string, len(events))
+	for i, evt := range events {
+		ids[i] = evt.ID
+	}
+	return ids
+}
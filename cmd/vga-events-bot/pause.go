@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+const pauseUsage = "Usage: /pause &lt;duration&gt;\n\nExamples:\n/pause 3d\n/pause 2w\n/pause 1 month"
+
+// parsePauseDays parses a pause length such as "3d", "2w", "1 month", or "10 days" into days
+func parsePauseDays(text string) (int, bool) {
+	text = strings.ToLower(strings.ReplaceAll(text, " ", ""))
+	i := strings.IndexFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(text[:i])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	switch text[i:] {
+	case "d", "day", "days":
+		return n, true
+	case "w", "wk", "wks", "week", "weeks":
+		return n * 7, true
+	case "m", "mo", "month", "months":
+		return n * 30, true
+	}
+	return 0, false
+}
+
+// handlePause mutes notifications for a while, keeping subscriptions
+func handlePause(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if len(args) == 0 {
+		if user.IsPaused(clk.Now()) {
+			return fmt.Sprintf("⏸️ Notifications are paused until %s.\n\nUse /resume to turn them back on now, or /pause &lt;duration&gt; to change how long.",
+				formatPausedUntil(user.PausedUntilTime()))
+		}
+		return "❌ Please specify how long to pause.\n\n" + pauseUsage
+	}
+
+	days, ok := parsePauseDays(strings.Join(args, " "))
+	if !ok {
+		return "❌ Couldn't read that duration.\n\n" + pauseUsage
+	}
+	if days > preferences.MaxPauseDays {
+		return fmt.Sprintf("❌ You can pause for up to %d days.", preferences.MaxPauseDays)
+	}
+
+	until := clk.Now().AddDate(0, 0, days)
+	user.Pause(until)
+	*modified = true
+
+	return fmt.Sprintf("⏸️ <b>Notifications paused until %s</b>\n\nYour subscriptions are kept. New events are saved up and sent as one catch-up digest when the pause ends.\n\nUse /resume to turn notifications back on sooner.",
+		formatPausedUntil(until))
+}
+
+// handleResume ends a pause and replies with the catch-up digest
+func handleResume(prefs preferences.Preferences, chatID string, modified *bool) string {
+	user := prefs.GetUser(chatID)
+	if user.PausedUntil == 0 {
+		return "ℹ️ Notifications aren't paused.\n\nUse /pause &lt;duration&gt; to mute them for a while."
+	}
+
+	events := user.Resume()
+	*modified = true
+	return "▶️ <b>Notifications resumed!</b>" + formatCatchUp(events)
+}
+
+// deliverEndedPauses sends the catch-up digest to users whose pause ran out without
+// /resume and clears their pause. Returns true if preferences were modified.
+func deliverEndedPauses(prefs preferences.Preferences, botToken string, dryRun bool) bool {
+	now := clk.Now()
+	modified := false
+	for chatID, user := range prefs {
+		if !user.PauseEnded(now) {
+			continue
+		}
+
+		text := "▶️ <b>Your pause has ended</b>\n\nNotifications are back on." + formatCatchUp(user.PendingEvents)
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would send catch-up digest to %s:\n%s\n\n", chatID, text)
+		} else {
+			client, err := newSender(botToken, chatID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating client for chat %s: %v\n", chatID, err)
+				continue
+			}
+			err = client.SendMessage(botCtx, text)
+			recordDigestDelivery(chatID, err)
+			if err != nil && !errs.IsPermanent(err) {
+				// Try again on the next pass
+				fmt.Fprintf(os.Stderr, "Error sending catch-up digest to %s: %v\n", chatID, err)
+				continue
+			}
+		}
+
+		user.Resume()
+		modified = true
+	}
+	return modified
+}
+
+// formatCatchUp formats the events queued during a pause, to follow a resume message
+func formatCatchUp(events []*event.Event) string {
+	if len(events) == 0 {
+		return "\n\nNo new events came up while you were away."
+	}
+	return "\n\n" + telegram.FormatDigest(events, "catch-up")
+}
+
+// formatPausedUntil formats when a pause ends, e.g. "Oct 29, 2026 at 14:00 UTC"
+func formatPausedUntil(t time.Time) string {
+	return t.UTC().Format("Jan 2, 2006 at 15:04 UTC")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestParsePauseDays(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+		ok   bool
+	}{
+		{"3d", 3, true},
+		{"2w", 14, true},
+		{"2 weeks", 14, true},
+		{"1 month", 30, true},
+		{"10 days", 10, true},
+		{"2W", 14, true},
+		{"0d", 0, false},
+		{"w", 0, false},
+		{"2 fortnights", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePauseDays(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parsePauseDays(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "NV")
+	modified := false
+
+	response := handleResume(prefs, "123", &modified)
+	if modified || !strings.Contains(response, "aren't paused") {
+		t.Errorf("resume without pause: %q", response)
+	}
+
+	response = handlePause(prefs, "123", []string{"2w"}, &modified)
+	if !modified || !strings.Contains(response, "Notifications paused until") {
+		t.Errorf("unexpected response: %q", response)
+	}
+	user := prefs.GetUser("123")
+	if !user.IsPaused(time.Now().AddDate(0, 0, 13)) || user.IsPaused(time.Now().AddDate(0, 0, 15)) {
+		t.Errorf("expected a two-week pause, paused until %v", user.PausedUntilTime())
+	}
+	if !strings.Contains(handlePause(prefs, "123", nil, &modified), "paused until") {
+		t.Error("/pause with no arguments should show when the pause ends")
+	}
+	if !strings.Contains(handleList(prefs, "123"), "Paused until") {
+		t.Error("/list should show the pause")
+	}
+
+	modified = false
+	for _, args := range [][]string{{"soon"}, {"91d"}} {
+		if response := handlePause(prefs, "123", args, &modified); modified || !strings.HasPrefix(response, "❌") {
+			t.Errorf("handlePause(%v) should fail, got %q", args, response)
+		}
+	}
+
+	user.AddPendingEvent(&event.Event{ID: "a", Title: "Wolf Creek", State: "NV", DateText: "Apr 12 2026"})
+	response = handleResume(prefs, "123", &modified)
+	if !modified || !strings.Contains(response, "Notifications resumed") || !strings.Contains(response, "Wolf Creek") {
+		t.Errorf("resume should include the catch-up digest: %q", response)
+	}
+	if user.PausedUntil != 0 || len(user.PendingEvents) != 0 {
+		t.Error("resume should clear the pause and the queue")
+	}
+}
+
+func TestDeliverEndedPauses(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.GetUser("ended").Pause(time.Now().Add(-time.Hour))
+	prefs.GetUser("ended").AddPendingEvent(&event.Event{ID: "a", Title: "Wolf Creek", State: "NV"})
+	prefs.GetUser("paused").Pause(time.Now().Add(time.Hour))
+	prefs.GetUser("active")
+
+	if !deliverEndedPauses(prefs, "token", true) {
+		t.Fatal("deliverEndedPauses() = false, want true")
+	}
+	if u := prefs.GetUser("ended"); u.PausedUntil != 0 || len(u.PendingEvents) != 0 {
+		t.Error("ended pause should be cleared after the catch-up digest")
+	}
+	if !prefs.GetUser("paused").IsPaused(time.Now()) {
+		t.Error("ongoing pause should be left alone")
+	}
+	if deliverEndedPauses(prefs, "token", true) {
+		t.Error("nothing left to deliver")
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// pollQuestion is asked in every /poll
+const pollQuestion = "⛳ Which event should we play?"
+
+// handlePoll starts a poll between events, or closes the latest one and reports the winner.
+// In a group the poll goes to the group; in a private chat it goes to the user and
+// their friends.
+// Format: /poll <id1> <id2> [id3 ...] or /poll close
+func handlePoll(prefs preferences.Preferences, chatID string, args []string, modified *bool, botToken string, dryRun bool) (string, []*event.Event) {
+	if len(args) == 0 {
+		return "❌ Please list 2 to 10 events to vote on.\n\nUsage: /poll &lt;id1&gt; &lt;id2&gt; [id3 ...]\nClose the latest poll: /poll close", nil
+	}
+
+	user := prefs.GetUser(chatID)
+	if strings.EqualFold(args[0], "close") {
+		poll := user.LatestOpenPoll()
+		if poll == nil {
+			return "ℹ️ You have no open polls. Start one with /poll &lt;id1&gt; &lt;id2&gt;", nil
+		}
+		text, keyboard := closePoll(prefs, chatID, poll, modified, botToken, dryRun)
+		return sendWithKeyboard(chatID, text, keyboard, botToken, dryRun), nil
+	}
+
+	eventIDs, unknown := resolveEventIDs(args)
+	if len(unknown) > 0 {
+		return formatUnknownCodes(unknown), nil
+	}
+	eventIDs = uniqueStrings(eventIDs)
+	if len(eventIDs) < 2 || len(eventIDs) > telegram.MaxPollOptions {
+		return fmt.Sprintf("❌ A poll needs 2 to %d different events (got %d).", telegram.MaxPollOptions, len(eventIDs)), nil
+	}
+
+	recipients := []string{chatID}
+	if !isGroupChat(chatID) {
+		if len(user.FriendChatIDs) == 0 {
+			return "👥 Polls go to your friends, and you haven't added any yet.\n\nUse /invite to get a code for your friends, or add the bot to your group chat and send /poll there.", nil
+		}
+		recipients = append(recipients, user.FriendChatIDs...)
+	}
+
+	options := make([]string, len(eventIDs))
+	for i, id := range eventIDs {
+		options[i] = pollOption(id)
+	}
+
+	poll := user.AddPoll(eventIDs, clk.Now())
+	*modified = true
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would send poll %s to %d chat(s): %s\n", poll.ID, len(recipients), strings.Join(options, " | "))
+	} else {
+		for _, recipient := range recipients {
+			client, err := newSender(botToken, recipient)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating client for poll: %v\n", err)
+				continue
+			}
+			sent, err := client.SendPoll(botCtx, pollQuestion, options)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending poll to %s: %v\n", recipient, err)
+				continue
+			}
+			poll.Messages = append(poll.Messages, preferences.PollMessage{ChatID: recipient, MessageID: sent.MessageID, TelegramID: sent.PollID})
+		}
+		if len(poll.Messages) == 0 {
+			poll.Closed = true
+			return "❌ Couldn't send the poll. Please try again later.", nil
+		}
+	}
+
+	text := fmt.Sprintf("🗳️ <b>Poll started</b> with %d events, sent to %d chat(s).\n\nWhen everyone has voted, tap below or send /poll close to see the winner.", len(eventIDs), len(recipients))
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "🏁 Close poll & pick winner", CallbackData: "poll-close:" + poll.ID}},
+		},
+	}
+	return sendWithKeyboard(chatID, text, keyboard, botToken, dryRun), nil
+}
+
+// handlePollAnswer records a vote from a poll_answer update
+func handlePollAnswer(prefs preferences.Preferences, answer *telegram.PollAnswer, modified *bool) {
+	_, poll := prefs.FindTelegramPoll(answer.PollID)
+	if poll == nil {
+		return
+	}
+	voterID := fmt.Sprintf("%d", answer.User.ID)
+	if poll.RecordVote(voterID, answer.OptionIDs) {
+		*modified = true
+	}
+}
+
+// handlePollCloseCallback closes a poll from its "Close poll" button
+// Format: poll-close:POLL_ID
+func handlePollCloseCallback(prefs preferences.Preferences, pollID string, modified *bool, botToken string, dryRun bool) (string, *telegram.InlineKeyboardMarkup) {
+	ownerID, poll := prefs.FindPollByID(pollID)
+	if poll == nil {
+		return "❌ This poll has expired.", nil
+	}
+	return closePoll(prefs, ownerID, poll, modified, botToken, dryRun)
+}
+
+// closePoll stops voting, announces the winner to the other chats the poll was sent to,
+// and returns the result with a button to register everyone who voted
+func closePoll(prefs preferences.Preferences, ownerID string, poll *preferences.EventPoll, modified *bool, botToken string, dryRun bool) (string, *telegram.InlineKeyboardMarkup) {
+	if _, _, ok := poll.Winner(); !ok {
+		return "🗳️ No votes yet. The poll stays open; try again once your friends have voted.", nil
+	}
+
+	text, keyboard := formatPollResult(poll)
+	if !poll.Closed {
+		poll.Closed = true
+		*modified = true
+
+		for _, m := range poll.Messages {
+			if dryRun {
+				continue
+			}
+			client, err := newSender(botToken, m.ChatID)
+			if err != nil {
+				continue
+			}
+			if err := client.StopPoll(botCtx, m.MessageID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error stopping poll in %s: %v\n", m.ChatID, err)
+			}
+			if m.ChatID == ownerID {
+				continue // The owner gets the result as the reply
+			}
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending poll result to %s: %v\n", m.ChatID, err)
+			}
+		}
+	}
+	return text, keyboard
+}
+
+// formatPollResult shows the vote counts and the winner
+func formatPollResult(poll *preferences.EventPoll) (string, *telegram.InlineKeyboardMarkup) {
+	winner, tied, _ := poll.Winner()
+	counts := poll.Tally()
+
+	var msg strings.Builder
+	msg.WriteString("🏆 <b>Poll Result</b>\n\n")
+	msg.WriteString(fmt.Sprintf("We're playing <b>%s</b>", html.EscapeString(pollOption(poll.EventIDs[winner]))))
+	if tied {
+		msg.WriteString(" (won a tie as the first listed)")
+	}
+	msg.WriteString("\n\n")
+	for i, id := range poll.EventIDs {
+		marker := "•"
+		if i == winner {
+			marker = "🏆"
+		}
+		msg.WriteString(fmt.Sprintf("%s %s — %d vote(s)\n", marker, html.EscapeString(pollOption(id)), counts[i]))
+	}
+	msg.WriteString(fmt.Sprintf("\n%d voter(s). Once you've signed up, tap below to mark everyone who voted as ✅ Registered.", len(poll.Votes)))
+
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "✅ Mark us registered", CallbackData: "poll-reg:" + poll.ID}},
+		},
+	}
+	return msg.String(), keyboard
+}
+
+// handlePollRegisterCallback marks the winning event as registered for everyone who
+// voted and for the user tapping the button. Voters who have never used the bot are
+// skipped.
+// Format: poll-reg:POLL_ID
+func handlePollRegisterCallback(prefs preferences.Preferences, chatID, pollID string, modified *bool) string {
+	_, poll := prefs.FindPollByID(pollID)
+	if poll == nil {
+		return "❌ This poll has expired."
+	}
+	winner, _, ok := poll.Winner()
+	if !poll.Closed || !ok {
+		return "❌ This poll hasn't been decided yet."
+	}
+	eventID := poll.EventIDs[winner]
+
+	members := append([]string{chatID}, poll.Voters()...)
+	marked := 0
+	for _, memberID := range uniqueStrings(members) {
+		var user *preferences.UserPreferences
+		if memberID == chatID {
+			user = prefs.GetUser(chatID)
+		} else if _, ok := prefs[memberID]; ok {
+			user = prefs.GetUser(memberID)
+		} else {
+			continue
+		}
+		if user.GetEventStatus(eventID) == preferences.EventStatusRegistered {
+			marked++
+			continue
+		}
+		if user.SetEventStatus(eventID, preferences.EventStatusRegistered) {
+			user.IncrementEventStatus(preferences.EventStatusRegistered)
+			*modified = true
+			marked++
+		}
+	}
+
+	return fmt.Sprintf("✅ <b>%s</b> marked as Registered for %d player(s).", html.EscapeString(pollOption(eventID)), marked)
+}
+
+// pollOption labels an event as a poll option, e.g. "Spring Classic (NV, Mar 28)"
+func pollOption(eventID string) string {
+	evt := snapshotEvent(eventID)
+	if evt == nil {
+		return truncateRunes(eventID, telegram.MaxPollOptionLength)
+	}
+	details := evt.State
+	if evt.DateText != "" {
+		details += ", " + evt.DateText
+	}
+	suffix := fmt.Sprintf(" (%s)", details)
+	title := truncateRunes(evt.Title, telegram.MaxPollOptionLength-len([]rune(suffix)))
+	return title + suffix
+}
+
+// truncateRunes shortens s to at most n characters, ending with "…" if cut
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:max(n, 0)])
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// uniqueStrings returns ids without duplicates, keeping the first occurrence of each
+func uniqueStrings(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// isGroupChat reports whether a chat ID belongs to a group; Telegram gives groups
+// negative IDs
+func isGroupChat(chatID string) bool {
+	return strings.HasPrefix(chatID, "-")
+}
+
+// sendWithKeyboard sends text with a keyboard and returns "" so the command doesn't send
+// it again, or returns the text to send plainly in dry-run mode or if sending fails
+func sendWithKeyboard(chatID, text string, keyboard *telegram.InlineKeyboardMarkup, botToken string, dryRun bool) string {
+	if dryRun || keyboard == nil {
+		return text
+	}
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return text
+	}
+	if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending keyboard: %v\n", err)
+		return text
+	}
+	return ""
+}
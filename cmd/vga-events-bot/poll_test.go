@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+func TestHandlePoll(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	if response, _ := handlePoll(prefs, "111", []string{"evt1"}, &modified, "", true); !strings.Contains(response, "2 to 10") {
+		t.Errorf("one event: %q", response)
+	}
+	if response, _ := handlePoll(prefs, "111", []string{"evt1", "evt2"}, &modified, "", true); modified || !strings.Contains(response, "/invite") {
+		t.Errorf("without friends: %q", response)
+	}
+	if response, _ := handlePoll(prefs, "-100123", []string{"evt1", "evt2"}, &modified, "", true); !modified || !strings.Contains(response, "sent to 1 chat") {
+		t.Errorf("group poll: %q", response)
+	}
+
+	user := prefs.GetUser("111")
+	user.AddFriend("222")
+	prefs.GetUser("222").AddFriend("111")
+	response, _ := handlePoll(prefs, "111", []string{"evt1", "evt2", "evt1"}, &modified, "", true)
+	if !strings.Contains(response, "with 2 events, sent to 2 chat(s)") {
+		t.Fatalf("friend poll: %q", response)
+	}
+	poll := user.LatestOpenPoll()
+	poll.Messages = []preferences.PollMessage{{ChatID: "111", TelegramID: "tg-1"}, {ChatID: "222", TelegramID: "tg-2"}}
+
+	if response, _ := handlePoll(prefs, "111", []string{"close"}, &modified, "", true); !strings.Contains(response, "No votes yet") || poll.Closed {
+		t.Errorf("closing without votes: %q", response)
+	}
+
+	handlePollAnswer(prefs, &telegram.PollAnswer{PollID: "tg-2", User: telegram.User{ID: 222}, OptionIDs: []int{1}}, &modified)
+	handlePollAnswer(prefs, &telegram.PollAnswer{PollID: "unknown", User: telegram.User{ID: 333}, OptionIDs: []int{0}}, &modified)
+	if len(poll.Votes) != 1 || poll.Votes["222"] != 1 {
+		t.Fatalf("votes = %v, want only 222's", poll.Votes)
+	}
+
+	if response := handlePollRegisterCallback(prefs, "111", poll.ID, &modified); !strings.Contains(response, "hasn't been decided") {
+		t.Errorf("registering before close: %q", response)
+	}
+
+	text, keyboard := handlePollCloseCallback(prefs, poll.ID, &modified, "", true)
+	if !poll.Closed || !strings.Contains(text, "We're playing <b>evt2</b>") || keyboard == nil {
+		t.Fatalf("close: %q", text)
+	}
+	if data := keyboard.InlineKeyboard[0][0].CallbackData; data != "poll-reg:"+poll.ID {
+		t.Errorf("result button = %q", data)
+	}
+
+	response = handlePollRegisterCallback(prefs, "111", poll.ID, &modified)
+	if !strings.Contains(response, "for 2 player(s)") {
+		t.Errorf("register: %q", response)
+	}
+	for _, id := range []string{"111", "222"} {
+		if prefs.GetUser(id).GetEventStatus("evt2") != preferences.EventStatusRegistered {
+			t.Errorf("%s should be registered for the winner", id)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/issues"
+)
+
+const (
+	// maxRecentCommands is how many of a user's commands /report includes
+	maxRecentCommands = 5
+	// maxProblemReport caps the text of a /report
+	maxProblemReport = 1000
+	// maxIssueTitle caps the report text used as the GitHub issue title
+	maxIssueTitle = 60
+)
+
+// githubAPIURL is the GitHub API base /report opens issues through; tests point it at
+// an httptest server
+var githubAPIURL = issues.DefaultAPIURL
+
+// recentCommand is one command a user ran
+type recentCommand struct {
+	name string
+	at   time.Time
+}
+
+// commandHistory keeps each chat's last few command names, oldest first, so a /report
+// shows what led up to it. Only names are kept, never arguments, and only in memory.
+type commandHistory struct {
+	mu    sync.Mutex
+	size  int
+	chats map[string][]recentCommand
+}
+
+// newCommandHistory creates a history keeping size commands per chat
+func newCommandHistory(size int) *commandHistory {
+	return &commandHistory{size: size, chats: make(map[string][]recentCommand)}
+}
+
+// recentCommands is the bot's command history
+var recentCommands = newCommandHistory(maxRecentCommands)
+
+// record adds a command to chatID's history, dropping the oldest once it's full
+func (h *commandHistory) record(chatID, name string, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cmds := append(h.chats[chatID], recentCommand{name: name, at: now})
+	if len(cmds) > h.size {
+		cmds = cmds[len(cmds)-h.size:]
+	}
+	h.chats[chatID] = cmds
+}
+
+// recent returns a copy of chatID's history, oldest first
+func (h *commandHistory) recent(chatID string) []recentCommand {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]recentCommand(nil), h.chats[chatID]...)
+}
+
+// problemReport is what /report sends on
+type problemReport struct {
+	chatID  string
+	text    string
+	recent  []recentCommand
+	version string
+	at      time.Time
+}
+
+// handleReport forwards a user's problem report to the admin chat and, if
+// --report-repo is set, opens a GitHub issue for it.
+// Format: /report <text>
+func handleReport(ctx *commandContext) (string, []*event.Event) {
+	if len(ctx.parts) < 2 {
+		return "🐞 <b>Report a Problem</b>\n\nTell us what went wrong:\n/report &lt;what happened&gt;\n\n" +
+			"Example: /report /near Reno shows no events but /events NV does", nil
+	}
+	text, errMsg := validateUserInput(strings.Join(ctx.parts[1:], " "), maxProblemReport, "Report")
+	if errMsg != "" {
+		return errMsg, nil
+	}
+	if *adminChat == "" && *reportRepo == "" {
+		return "❌ Problem reports aren't set up for this bot.", nil
+	}
+
+	report := problemReport{
+		chatID:  ctx.chatID,
+		text:    text,
+		recent:  recentCommands.recent(ctx.chatID),
+		version: buildinfo.Get().Short(),
+		at:      clk.Now(),
+	}
+	if ctx.dryRun {
+		fmt.Printf("[DRY RUN] Would send problem report:\n%s\n\n", formatAdminReport(report))
+		return "✅ Thanks! Your report was sent to the maintainer.", nil
+	}
+
+	sent := false
+	if *adminChat != "" {
+		if err := sendAdminReport(ctx.botToken, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending problem report to the admin chat: %v\n", err)
+		} else {
+			sent = true
+		}
+	}
+	issueURL := ""
+	if *reportRepo != "" {
+		title, body := formatIssue(report)
+		client := issues.NewClient(issues.Config{Repo: *reportRepo, Token: *reportToken, APIURL: githubAPIURL})
+		created, err := client.Create(botCtx, issues.Issue{Title: title, Body: body, Labels: []string{"bot-report"}})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening GitHub issue for problem report: %v\n", err)
+			reportError(err, "")
+		} else {
+			sent = true
+			issueURL = created.URL
+		}
+	}
+
+	if !sent {
+		return "❌ Sorry, your report couldn't be sent. Please try again later.", nil
+	}
+	msg := "✅ Thanks! Your report was sent to the maintainer."
+	if issueURL != "" {
+		msg += fmt.Sprintf("\n\nTracked at %s", html.EscapeString(issueURL))
+	}
+	return msg, nil
+}
+
+// sendAdminReport messages the report to the admin chat
+func sendAdminReport(botToken string, report problemReport) error {
+	client, err := newSender(botToken, *adminChat)
+	if err != nil {
+		return err
+	}
+	return client.SendMessage(botCtx, formatAdminReport(report))
+}
+
+// formatRecent lists the recent commands with how long before the report each ran
+func formatRecent(report problemReport) []string {
+	lines := make([]string, 0, len(report.recent))
+	for _, c := range report.recent {
+		ago := report.at.Sub(c.at).Truncate(time.Second)
+		lines = append(lines, fmt.Sprintf("/%s (%s ago)", c.name, ago))
+	}
+	return lines
+}
+
+// formatAdminReport builds the admin chat message for a report
+func formatAdminReport(report problemReport) string {
+	var msg strings.Builder
+	msg.WriteString("🐞 <b>Problem report</b>\n\n")
+	msg.WriteString(fmt.Sprintf("<b>From:</b> <code>%s</code>\n", html.EscapeString(report.chatID)))
+	msg.WriteString(fmt.Sprintf("<b>Version:</b> %s\n", html.EscapeString(report.version)))
+	if recent := formatRecent(report); len(recent) > 0 {
+		msg.WriteString("<b>Recent commands:</b> " + strings.Join(recent, ", ") + "\n")
+	}
+	msg.WriteString("\n" + html.EscapeString(report.text))
+	return msg.String()
+}
+
+// formatIssue builds the GitHub issue title and Markdown body for a report. Issues may
+// be public, so the chat ID is hashed with the deployment's ERROR_REPORT_SALT, without
+// which it can't be recovered.
+func formatIssue(report problemReport) (string, string) {
+	title := strings.Join(strings.Fields(report.text), " ")
+	if runes := []rune(title); len(runes) > maxIssueTitle {
+		title = strings.TrimSpace(string(runes[:maxIssueTitle])) + "…"
+	}
+
+	var body strings.Builder
+	body.WriteString("Reported from the Telegram bot with /report.\n\n")
+	for _, line := range strings.Split(report.text, "\n") {
+		body.WriteString("> " + line + "\n")
+	}
+	body.WriteString("\n| | |\n|---|---|\n")
+	body.WriteString(fmt.Sprintf("| Reporter | `%s` |\n", errreport.HashChatID(report.chatID)))
+	body.WriteString(fmt.Sprintf("| Version | `%s` |\n", report.version))
+	body.WriteString(fmt.Sprintf("| Reported | %s |\n", report.at.UTC().Format(time.RFC3339)))
+	if recent := formatRecent(report); len(recent) > 0 {
+		body.WriteString("| Recent commands | " + strings.Join(recent, ", ") + " |\n")
+	}
+	return "Bot report: " + title, body.String()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/testutil"
+)
+
+func TestCommandHistory(t *testing.T) {
+	h := newCommandHistory(3)
+	now := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)
+	for _, name := range []string{"start", "events", "near", "search"} {
+		h.record("1", name, now)
+	}
+	h.record("2", "help", now)
+
+	var names []string
+	for _, c := range h.recent("1") {
+		names = append(names, c.name)
+	}
+	if got := strings.Join(names, ","); got != "events,near,search" {
+		t.Errorf("recent() = %s, want the last 3 oldest first", got)
+	}
+	if len(h.recent("3")) != 0 {
+		t.Error("a chat with no commands should have no history")
+	}
+}
+
+func TestHandleReport(t *testing.T) {
+	fake := testutil.NewFakeClock(time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC))
+	oldClk, oldHistory, oldAdmin, oldRepo, oldToken, oldAPI := clk, recentCommands, *adminChat, *reportRepo, *reportToken, githubAPIURL
+	t.Cleanup(func() {
+		clk, recentCommands, *adminChat, *reportRepo, *reportToken, githubAPIURL = oldClk, oldHistory, oldAdmin, oldRepo, oldToken, oldAPI
+	})
+	clk, recentCommands = fake, newCommandHistory(maxRecentCommands)
+
+	var issue struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/issues" || r.Header.Get("Authorization") != "token issues-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&issue)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url": "https://github.com/owner/repo/issues/7"}`))
+	}))
+	defer server.Close()
+	githubAPIURL = server.URL
+
+	prefs := preferences.NewPreferences()
+	modified := false
+	*adminChat, *reportRepo = "", ""
+	if got, _ := processCommand(prefs, "123", "/report it broke", &modified, "", false); !strings.Contains(got, "aren't set up") {
+		t.Errorf("/report with nowhere to send: %s", got)
+	}
+
+	*adminChat, *reportRepo, *reportToken = "999", "owner/repo", "issues-token"
+	processCommand(prefs, "123", "/list", &modified, "", true)
+	fake.Advance(90 * time.Second)
+	calls := recordSends(t)
+	got, _ := processCommand(prefs, "123", "/report <b>/near</b> shows nothing", &modified, "", false)
+	if !strings.Contains(got, "Thanks") || !strings.Contains(got, "issues/7") {
+		t.Errorf("/report reply: %s", got)
+	}
+
+	if len(*calls) != 1 || (*calls)[0].ChatID != "999" {
+		t.Fatalf("admin chat messages = %+v", *calls)
+	}
+	admin := (*calls)[0].Text
+	for _, want := range []string{"<code>123</code>", "/list (1m30s ago)", "&lt;b&gt;/near&lt;/b&gt; shows nothing"} {
+		if !strings.Contains(admin, want) {
+			t.Errorf("admin report missing %q:\n%s", want, admin)
+		}
+	}
+
+	if issue.Title != "Bot report: <b>/near</b> shows nothing" {
+		t.Errorf("issue title = %q", issue.Title)
+	}
+	if strings.Contains(issue.Body, "123") || !strings.Contains(issue.Body, "/list (1m30s ago)") {
+		t.Errorf("issue body should list recent commands without the chat ID:\n%s", issue.Body)
+	}
+
+	// /report itself isn't recorded
+	if recent := recentCommands.recent("123"); len(recent) != 1 || recent[0].name != "list" {
+		t.Errorf("recent commands = %+v", recent)
+	}
+}
+
+func TestHandleReportFailures(t *testing.T) {
+	oldAdmin, oldRepo, oldAPI := *adminChat, *reportRepo, githubAPIURL
+	t.Cleanup(func() { *adminChat, *reportRepo, githubAPIURL = oldAdmin, oldRepo, oldAPI })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	githubAPIURL = server.URL
+	*adminChat, *reportRepo = "", "owner/repo"
+
+	ctx := &commandContext{prefs: preferences.NewPreferences(), chatID: "123", parts: []string{"/report"}}
+	if got, _ := handleReport(ctx); !strings.Contains(got, "/report &lt;what happened&gt;") {
+		t.Errorf("/report without text: %s", got)
+	}
+
+	ctx.parts = []string{"/report", "it", "broke"}
+	if got, _ := handleReport(ctx); !strings.Contains(got, "couldn't be sent") {
+		t.Errorf("/report when the issue can't be opened: %s", got)
+	}
+
+	ctx.parts = []string{"/report", strings.Repeat("x", maxProblemReport+1)}
+	if got, _ := handleReport(ctx); !strings.Contains(got, "too long") {
+		t.Errorf("/report over the limit: %s", got)
+	}
+}
+
+func TestFormatIssueTitle(t *testing.T) {
+	title, _ := formatIssue(problemReport{text: strings.Repeat("word ", 30), at: time.Now()})
+	if n := len([]rune(strings.TrimPrefix(title, "Bot report: "))); n > maxIssueTitle+1 || !strings.HasSuffix(title, "…") {
+		t.Errorf("long title not shortened: %q", title)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// panicReportInterval is the minimum time between panic reports to the admin chat
+	panicReportInterval = 10 * time.Minute
+	// maxReportStack caps the escaped stack trace in an admin report (Telegram messages max out at 4096 chars)
+	maxReportStack = 2500
+
+	errInternal = "❌ Something went wrong handling that request. Please try again later."
+)
+
+// panicReporter sends recovered handler panics to the admin chat. Reports are
+// rate-limited so a panic on every update doesn't flood the chat; panics in between are
+// counted and mentioned in the next report.
+type panicReporter struct {
+	mu         sync.Mutex
+	interval   time.Duration
+	last       time.Time
+	suppressed int
+	send       func(text string) error
+}
+
+// newPanicReporter creates a reporter that messages chatID through the bot
+func newPanicReporter(botToken, chatID string) (*panicReporter, error) {
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return &panicReporter{
+		interval: panicReportInterval,
+		send: func(text string) error {
+			return client.SendMessage(botCtx, text)
+		},
+	}, nil
+}
+
+// panicReports is set in main when an admin chat is configured; panics are only logged otherwise
+var panicReports *panicReporter
+
+// report sends a panic report unless one was sent within the interval.
+// It returns whether the report was sent.
+func (r *panicReporter) report(summary string, value interface{}, stack []byte) bool {
+	r.mu.Lock()
+	now := clk.Now()
+	if !r.last.IsZero() && now.Sub(r.last) < r.interval {
+		r.suppressed++
+		r.mu.Unlock()
+		return false
+	}
+	r.last = now
+	suppressed := r.suppressed
+	r.suppressed = 0
+	r.mu.Unlock()
+
+	if err := r.send(formatPanicReport(summary, value, stack, suppressed)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending panic report: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// formatPanicReport builds the admin message for a recovered panic
+func formatPanicReport(summary string, value interface{}, stack []byte, suppressed int) string {
+	// Cut the escaped trace at a line break so no HTML entity is split
+	trace := html.EscapeString(string(stack))
+	if len(trace) > maxReportStack {
+		trace = trace[:maxReportStack]
+		if i := strings.LastIndex(trace, "\n"); i > 0 {
+			trace = trace[:i]
+		}
+		trace += "\n…"
+	}
+
+	var msg strings.Builder
+	msg.WriteString("🚨 <b>Bot handler panic</b>\n\n")
+	msg.WriteString(fmt.Sprintf("<b>Update:</b> %s\n", html.EscapeString(summary)))
+	msg.WriteString(fmt.Sprintf("<b>Panic:</b> <code>%s</code>\n", html.EscapeString(fmt.Sprint(value))))
+	if suppressed > 0 {
+		msg.WriteString(fmt.Sprintf("<i>%d more panic(s) since the last report</i>\n", suppressed))
+	}
+	msg.WriteString(fmt.Sprintf("\n<pre>%s</pre>", trace))
+	return msg.String()
+}
+
+// describeUpdate summarizes an update for logs and panic reports without including
+// message text beyond the command name
+func describeUpdate(update Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return fmt.Sprintf("#%d callback %q from chat %d", update.UpdateID, updateCommand(update), update.CallbackQuery.From.ID)
+	case update.Message != nil:
+		return fmt.Sprintf("#%d message %q from chat %d", update.UpdateID, updateCommand(update), update.Message.Chat.ID)
+	case update.PollAnswer != nil:
+		return fmt.Sprintf("#%d poll answer from chat %d", update.UpdateID, update.PollAnswer.User.ID)
+	}
+	return fmt.Sprintf("#%d", update.UpdateID)
+}
+
+// updateCommand returns the command ("/search") or callback action ("status") an update
+// triggers, or "" for plain text
+func updateCommand(update Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		action, _, _ := strings.Cut(update.CallbackQuery.Data, ":")
+		return action
+	case update.Message != nil:
+		if fields := strings.Fields(update.Message.Text); len(fields) > 0 && strings.HasPrefix(fields[0], "/") {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// updateChatID returns the chat an update came from, or "" if it has none
+func updateChatID(update Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return fmt.Sprintf("%d", update.CallbackQuery.From.ID)
+	case update.Message != nil:
+		return fmt.Sprintf("%d", update.Message.Chat.ID)
+	case update.PollAnswer != nil:
+		return fmt.Sprintf("%d", update.PollAnswer.User.ID)
+	}
+	return ""
+}
+
+// recoverUpdate is deferred by processUpdate so a panic in one handler is logged and
+// reported instead of killing the polling loop; processing continues with the next update
+func recoverUpdate(update Update, botToken string, dryRun bool) {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	stack := debug.Stack()
+	summary := describeUpdate(update)
+	fmt.Fprintf(os.Stderr, "PANIC handling update %s: %v\n%s\n", summary, value, stack)
+
+	if panicReports != nil && !dryRun {
+		panicReports.report(summary, value, stack)
+	}
+	errReporter.Panic(botCtx, value, stack, updateReportContext(update))
+
+	if chatID := updateChatID(update); chatID != "" {
+		sendResponse(botToken, chatID, errInternal, nil, dryRun)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+func TestRecoverUpdate(t *testing.T) {
+	var sent []string
+	original := panicReports
+	panicReports = &panicReporter{
+		interval: time.Hour,
+		send: func(text string) error {
+			sent = append(sent, text)
+			return nil
+		},
+	}
+	defer func() { panicReports = original }()
+
+	update := Update{UpdateID: 7, Message: &Message{Chat: Chat{ID: 42}, Text: "/search pebble"}}
+
+	// The panic must not escape
+	func() {
+		defer recoverUpdate(update, "", true)
+		panic("boom")
+	}()
+
+	// Reports are skipped in dry-run mode
+	if len(sent) != 0 {
+		t.Errorf("dry run sent %d report(s), want 0", len(sent))
+	}
+
+	func() {
+		defer recoverUpdate(update, "", false)
+		panic("boom")
+	}()
+
+	if len(sent) != 1 {
+		t.Fatalf("sent %d report(s), want 1", len(sent))
+	}
+	for _, want := range []string{"Bot handler panic", "boom", `/search`, "chat 42", "TestRecoverUpdate"} {
+		if !strings.Contains(sent[0], want) {
+			t.Errorf("report should contain %q, got:\n%s", want, sent[0])
+		}
+	}
+	if strings.Contains(sent[0], "pebble") {
+		t.Error("report should not include message text beyond the command")
+	}
+}
+
+func TestPanicReporterRateLimit(t *testing.T) {
+	sent := 0
+	var last string
+	reporter := &panicReporter{
+		interval: time.Hour,
+		send: func(text string) error {
+			sent++
+			last = text
+			return nil
+		},
+	}
+
+	if !reporter.report("first", "boom", nil) {
+		t.Error("first report should be sent")
+	}
+	if reporter.report("second", "boom", nil) || reporter.report("third", "boom", nil) {
+		t.Error("reports within the interval should be suppressed")
+	}
+	if sent != 1 {
+		t.Errorf("sent %d reports, want 1", sent)
+	}
+
+	// Once the interval passes, the next report mentions what was suppressed
+	reporter.last = time.Now().Add(-2 * time.Hour)
+	if !reporter.report("fourth", "boom", nil) {
+		t.Error("report after the interval should be sent")
+	}
+	if !strings.Contains(last, "2 more panic(s)") {
+		t.Errorf("report should mention suppressed panics, got:\n%s", last)
+	}
+}
+
+func TestDescribeUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update Update
+		want   string
+	}{
+		{
+			name:   "command",
+			update: Update{UpdateID: 1, Message: &Message{Chat: Chat{ID: 5}, Text: "/note abc123 secret"}},
+			want:   `#1 message "/note" from chat 5`,
+		},
+		{
+			name:   "plain text",
+			update: Update{UpdateID: 2, Message: &Message{Chat: Chat{ID: 5}, Text: "hello"}},
+			want:   `#2 message "" from chat 5`,
+		},
+		{
+			name:   "callback",
+			update: Update{UpdateID: 3, CallbackQuery: &telegram.CallbackQuery{From: telegram.User{ID: 9}, Data: "status:abc:registered"}},
+			want:   `#3 callback "status" from chat 9`,
+		},
+		{
+			name:   "empty",
+			update: Update{UpdateID: 4},
+			want:   "#4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeUpdate(tt.update); got != tt.want {
+				t.Errorf("describeUpdate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPanicReportTruncatesStack(t *testing.T) {
+	stack := []byte(strings.Repeat("<frame>\n", 1000))
+	msg := formatPanicReport("#1", "boom <x>", stack, 0)
+	if len(msg) > 4096 {
+		t.Errorf("report is %d chars, over Telegram's limit", len(msg))
+	}
+	if strings.Contains(msg, "<frame>") || strings.Contains(msg, "<x>") {
+		t.Error("report should escape HTML")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// reengageMessage asks an inactive user whether to keep their notifications
+func reengageMessage() (string, *telegram.InlineKeyboardMarkup) {
+	msg := fmt.Sprintf(`👋 <b>Still want VGA event notifications?</b>
+
+We haven't heard from you in a while. Tap <b>Keep</b> to keep getting new events, or <b>Unsubscribe</b> to stop them.
+
+If we don't hear back within %d days, notifications are turned off. Send any command to turn them back on.`, int(preferences.ReengageWindow.Hours()/24))
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{
+				{Text: "✅ Keep", CallbackData: "reengage:keep"},
+				{Text: "🔕 Unsubscribe", CallbackData: "reengage:stop"},
+			},
+		},
+	}
+	return msg, keyboard
+}
+
+// handleReengageCallback answers the re-engagement buttons. The tap itself already
+// counted as an interaction, so Keep has nothing left to change.
+// Format: reengage:keep | reengage:stop
+func handleReengageCallback(prefs preferences.Preferences, chatID, param string, modified *bool) string {
+	switch param {
+	case "keep":
+		return "👍 <b>Notifications kept!</b>\n\nYou'll keep getting new events for your subscriptions."
+	case "stop":
+		user := prefs.GetUser(chatID)
+		user.States = []string{}
+		user.Cities = nil
+		user.FollowedCourses = nil
+		user.Travel = nil
+		*modified = true
+		return "🔕 <b>Unsubscribed</b>\n\nYou won't get event notifications anymore.\n\nUse /subscribe &lt;STATE&gt; whenever you want them back."
+	}
+	return "❌ Invalid action"
+}
+
+// runReengagement deactivates users who didn't answer the re-engagement message in
+// time and, with send, asks users inactive for preferences.InactiveAfter whether they
+// still want notifications. Without send the inactive users are only listed.
+func runReengagement(ctx context.Context, prefs preferences.Preferences, storage *preferences.GistStorage, botToken string, dryRun, send bool) {
+	now := clk.Now()
+	reengage, deactivated := prefs.SweepInactive(now)
+	for _, chatID := range deactivated {
+		fmt.Printf("Deactivated %s: no answer to the re-engagement message\n", chatID)
+	}
+	fmt.Printf("ℹ️ %d user(s) inactive for %d+ days\n", len(reengage), int(preferences.InactiveAfter.Hours()/24))
+
+	sent := 0
+	for _, chatID := range reengage {
+		if !send {
+			fmt.Printf("Inactive: %s\n", chatID)
+			continue
+		}
+		msg, keyboard := reengageMessage()
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would ask %s whether to keep notifications\n", chatID)
+			continue
+		}
+		client, err := newSender(botToken, chatID)
+		if err == nil {
+			err = client.SendMessageWithKeyboard(ctx, msg, keyboard)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending re-engagement message to %s: %v\n", chatID, err)
+			continue
+		}
+		prefs.GetUser(chatID).MarkReengageSent(now)
+		sent++
+	}
+
+	if dryRun {
+		return
+	}
+	if err := savePreferences(ctx, storage, prefs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+		errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Asked %d inactive user(s), deactivated %d\n", sent, len(deactivated))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestReengageCallbacks(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "NV")
+	user := prefs.GetUser("123")
+	user.FollowedCourses = []string{"Wolf Creek"}
+	user.MarkReengageSent(time.Now().Add(-time.Hour))
+
+	// The tap counts as an interaction before the callback runs
+	modified := false
+	recordInteraction(prefs, "123", &modified)
+	if !modified || user.ReengageSentAt != 0 {
+		t.Fatal("tapping a button should answer the re-engagement message")
+	}
+
+	modified = false
+	if got := handleReengageCallback(prefs, "123", "keep", &modified); !strings.Contains(got, "kept") {
+		t.Errorf("keep response = %q", got)
+	}
+	if modified || len(user.States) != 1 {
+		t.Error("keep should leave subscriptions alone")
+	}
+
+	if got := handleReengageCallback(prefs, "123", "stop", &modified); !strings.Contains(got, "Unsubscribed") {
+		t.Errorf("stop response = %q", got)
+	}
+	if !modified || len(user.States) != 0 || len(user.FollowedCourses) != 0 {
+		t.Error("stop should remove all subscriptions")
+	}
+}
+
+func TestRecordInteractionSkipsUnknownUsers(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+	recordInteraction(prefs, "999", &modified)
+	if modified || len(prefs) != 0 {
+		t.Error("unknown chats shouldn't be added")
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+)
+
+// errReporter sends handler errors and panics to Sentry/Rollbar (nil, and a no-op,
+// unless --error-dsn is set)
+var errReporter *errreport.Reporter
+
+// currentReport describes the update being handled, so errors deep inside a handler are
+// reported with the command and chat that triggered them. Updates are handled one at a
+// time, so a single value is enough.
+var currentReport errreport.Context
+
+// updateReportContext returns the error report context for an update
+func updateReportContext(update Update) errreport.Context {
+	return errreport.Context{
+		Command: updateCommand(update),
+		ChatID:  updateChatID(update),
+	}
+}
+
+// reportError sends err to the error tracker along with the update being handled.
+// eventID may be empty.
+func reportError(err error, eventID string) {
+	c := currentReport
+	c.EventID = eventID
+	errReporter.Error(botCtx, err, c)
+}
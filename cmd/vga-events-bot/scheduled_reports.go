@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/eventquery"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// maxReportEvents is how many events a scheduled report lists before "and N more"
+const maxReportEvents = 20
+
+const reportScheduleUsage = `Usage: /filter schedule "name" daily|weekly [day] [hour]
+
+Examples:
+/filter schedule "March Weekends" weekly
+/filter schedule "March Weekends" weekly fri 6pm
+/filter schedule "Pebble" daily 7am
+
+Times are UTC.`
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// parseReportHour parses an hour of the day: "9", "09:00", "9am", "6pm"
+func parseReportHour(s string) (int, bool) {
+	s = strings.ToLower(s)
+	meridiem := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		s, meridiem = s[:len(s)-2], s[len(s)-2:]
+	}
+	hour, err := strconv.Atoi(strings.TrimSuffix(s, ":00"))
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, false
+	}
+	if meridiem != "" {
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	}
+	return hour, true
+}
+
+// formatReportSchedule describes when a report is sent, e.g. "weekly, Fridays at 18:00 UTC"
+func formatReportSchedule(report *preferences.ScheduledReport) string {
+	if report.Frequency == preferences.DigestFrequencyWeekly {
+		return fmt.Sprintf("weekly, %ss at %02d:00 UTC", time.Weekday(report.DayOfWeek), report.Hour)
+	}
+	return fmt.Sprintf("daily at %02d:00 UTC", report.Hour)
+}
+
+// handleFilterSchedule schedules a saved filter as a daily or weekly report.
+// args are the words after "schedule": a filter name (quoted or not), the frequency,
+// then an optional weekday and hour, which default to the user's digest settings.
+func handleFilterSchedule(prefs preferences.Preferences, chatID string, args []string, modified *bool) (string, []*event.Event) {
+	freqAt := -1
+	for i, arg := range args {
+		if lower := strings.ToLower(arg); lower == preferences.DigestFrequencyDaily || lower == preferences.DigestFrequencyWeekly {
+			freqAt = i
+			break
+		}
+	}
+	if freqAt < 1 {
+		return "❌ Please give a saved filter and how often to send it.\n\n" + reportScheduleUsage, nil
+	}
+	name := strings.Trim(strings.Join(args[:freqAt], " "), `"`)
+	frequency := strings.ToLower(args[freqAt])
+
+	user := prefs.GetUser(chatID)
+	hour, day := user.DigestHour, user.DigestDayOfWeek
+	if frequency == preferences.DigestFrequencyWeekly && day == 0 && user.DigestFrequency != preferences.DigestFrequencyWeekly {
+		day = int(time.Monday)
+	}
+	for _, opt := range args[freqAt+1:] {
+		if weekday, ok := weekdayNames[strings.ToLower(opt)]; ok {
+			day = int(weekday)
+		} else if h, ok := parseReportHour(opt); ok {
+			hour = h
+		} else {
+			return fmt.Sprintf("❌ Couldn't understand %q.\n\n%s", html.EscapeString(opt), reportScheduleUsage), nil
+		}
+	}
+
+	if err := user.ScheduleReport(name, frequency, hour, day, clk.Now()); err != nil {
+		switch {
+		case errors.Is(err, preferences.ErrUnknownFilter):
+			return fmt.Sprintf("❌ You don't have a saved filter named \"%s\".\n\nUse /filters to see your saved filters.", html.EscapeString(name)), nil
+		case errors.Is(err, preferences.ErrTooManyReports):
+			return fmt.Sprintf("❌ You already have %d scheduled reports.\n\nStop one with /filter unschedule \"name\" first.", preferences.MaxScheduledReports), nil
+		}
+		return "❌ Couldn't schedule the report.\n\n" + reportScheduleUsage, nil
+	}
+	*modified = true
+
+	return fmt.Sprintf(`🗓 <b>Report Scheduled</b>
+
+Events matching "%s" will be sent %s.
+
+Stop it with /filter unschedule "%s"`, html.EscapeString(name), formatReportSchedule(user.GetScheduledReport(name)), html.EscapeString(name)), nil
+}
+
+// handleFilterUnschedule stops a saved filter's scheduled report
+func handleFilterUnschedule(prefs preferences.Preferences, chatID, name string, modified *bool) (string, []*event.Event) {
+	if !prefs.GetUser(chatID).UnscheduleReport(name) {
+		return fmt.Sprintf("ℹ️ \"%s\" isn't scheduled.\n\nUse /filters to see your scheduled reports.", html.EscapeString(name)), nil
+	}
+	*modified = true
+	return fmt.Sprintf("✅ Stopped the scheduled report for \"%s\". The filter is still saved.", html.EscapeString(name)), nil
+}
+
+// reportEvents returns the upcoming events in the user's states that match a saved
+// filter, soonest first
+func reportEvents(user *preferences.UserPreferences, report *preferences.ScheduledReport, allEvents []*event.Event, now time.Time) []*event.Event {
+	f := user.GetFilter(report.Filter)
+	if f == nil {
+		return nil
+	}
+	return eventquery.ForUser(user, now).States().Upcoming().Where(f.Matches).Sort().Run(allEvents)
+}
+
+// formatScheduledReport formats a report as a digest-style message
+func formatScheduledReport(report *preferences.ScheduledReport, events []*event.Event) string {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("🗓 <b>%s Report: %s</b>\n\n", strings.ToUpper(report.Frequency[:1])+report.Frequency[1:], html.EscapeString(report.Filter)))
+	msg.WriteString(fmt.Sprintf("%d upcoming event(s) match your filter:\n\n", len(events)))
+
+	for i, evt := range events {
+		if i == maxReportEvents {
+			msg.WriteString(fmt.Sprintf("… and %d more\n", len(events)-maxReportEvents))
+			break
+		}
+		msg.WriteString("• " + html.EscapeString(evt.Title))
+		if evt.DateText != "" {
+			msg.WriteString(fmt.Sprintf(" (%s)", html.EscapeString(evt.DateText)))
+		}
+		if evt.City != "" {
+			msg.WriteString(fmt.Sprintf(" - %s, %s", html.EscapeString(evt.City), evt.State))
+		}
+		if evt.ShortCode != "" {
+			msg.WriteString(fmt.Sprintf(" <code>%s</code>", evt.ShortCode))
+		}
+		msg.WriteString("\n")
+	}
+
+	msg.WriteString(fmt.Sprintf("\n💬 <i>/filter load \"%s\" to browse these, /filter unschedule \"%s\" to stop this report</i>", html.EscapeString(report.Filter), html.EscapeString(report.Filter)))
+	return msg.String()
+}
+
+// sendScheduledReports sends every report that's due, then saves when they were sent.
+// Reports with no matching events are skipped quietly but still count as sent.
+func sendScheduledReports(ctx context.Context, prefs preferences.Preferences, storage *preferences.GistStorage, botToken string, dryRun bool) {
+	now := clk.Now()
+	due := 0
+	for _, chatID := range prefs.GetAllUsers() {
+		user := prefs.GetUser(chatID)
+		if user.Active && !user.IsPaused(now) {
+			due += len(user.DueReports(now))
+		}
+	}
+	if due == 0 {
+		fmt.Println("ℹ️ No scheduled reports due")
+		return
+	}
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		os.Exit(1)
+	}
+
+	sent := 0
+	for _, chatID := range prefs.GetAllUsers() {
+		user := prefs.GetUser(chatID)
+		if !user.Active || user.IsPaused(now) {
+			continue
+		}
+		for _, report := range user.DueReports(now) {
+			report.LastSent = now.Unix()
+			events := reportEvents(user, report, allEvents, now)
+			if len(events) == 0 {
+				continue
+			}
+			msg := formatScheduledReport(report, events)
+			if dryRun {
+				fmt.Printf("[DRY RUN] Would send report %q to %s:\n%s\n\n", report.Filter, chatID, msg)
+				continue
+			}
+
+			client, err := newSender(botToken, chatID)
+			if err == nil {
+				err = client.SendMessage(ctx, msg)
+			}
+			recordDigestDelivery(chatID, err)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending report %q to %s: %v\n", report.Filter, chatID, err)
+				continue
+			}
+			sent++
+		}
+	}
+
+	if dryRun {
+		return
+	}
+	if err := savePreferences(ctx, storage, prefs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving preferences: %v\n", err)
+		errReporter.Error(ctx, err, errreport.Context{Command: "save preferences"})
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Sent %d of %d due scheduled report(s)\n", sent, due)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestParseReportHour(t *testing.T) {
+	tests := map[string]int{"9": 9, "09:00": 9, "17": 17, "9am": 9, "12am": 0, "12pm": 12, "6PM": 18}
+	for input, want := range tests {
+		if got, ok := parseReportHour(input); !ok || got != want {
+			t.Errorf("parseReportHour(%q) = %d, %v; want %d", input, got, ok, want)
+		}
+	}
+	for _, input := range []string{"24", "13pm", "noon", "0am"} {
+		if _, ok := parseReportHour(input); ok {
+			t.Errorf("parseReportHour(%q) should fail", input)
+		}
+	}
+}
+
+func TestHandleFilterSchedule(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+	user := prefs.GetUser("111")
+	user.SaveFilter("March Weekends", filter.NewFilter())
+
+	if response, _ := handleFilterSchedule(prefs, "111", strings.Fields(`"Nope" weekly`), &modified); !strings.Contains(response, "don't have a saved filter") || modified {
+		t.Errorf("unknown filter: %q", response)
+	}
+	if response, _ := handleFilterSchedule(prefs, "111", strings.Fields(`"March Weekends"`), &modified); !strings.Contains(response, "Usage") {
+		t.Errorf("missing frequency: %q", response)
+	}
+
+	response, _ := handleFilterSchedule(prefs, "111", strings.Fields(`"March Weekends" weekly fri 6pm`), &modified)
+	if !modified || !strings.Contains(response, "weekly, Fridays at 18:00 UTC") {
+		t.Fatalf("schedule: %q", response)
+	}
+	if list, _ := handleFiltersList(prefs, "111"); !strings.Contains(list, "🗓 Sent weekly, Fridays at 18:00 UTC") {
+		t.Errorf("/filters should show the schedule:\n%s", list)
+	}
+
+	handleFilterUnschedule(prefs, "111", "March Weekends", &modified)
+	if user.GetScheduledReport("March Weekends") != nil {
+		t.Error("unschedule should remove the report")
+	}
+}
+
+func TestReportEvents(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	user := preferences.NewPreferences().GetUser("111")
+	user.States = []string{"NV"}
+	f := filter.NewFilter()
+	f.Courses = []string{"Shadow"}
+	user.SaveFilter("Shadow", f)
+	if err := user.ScheduleReport("Shadow", preferences.DigestFrequencyDaily, 9, 0, now); err != nil {
+		t.Fatal(err)
+	}
+
+	allEvents := []*event.Event{
+		{ID: "a", State: "NV", Title: "NV - Shadow Creek", DateText: "Apr 10 2026", City: "Las Vegas"},
+		{ID: "b", State: "NV", Title: "NV - Shadow Creek", DateText: "Jan 10 2026"},
+		{ID: "c", State: "CA", Title: "CA - Shadow Ridge", DateText: "Apr 11 2026"},
+		{ID: "d", State: "NV", Title: "NV - Wolf Creek", DateText: "Apr 12 2026"},
+	}
+	report := user.GetScheduledReport("Shadow")
+	events := reportEvents(user, report, allEvents, now)
+	if len(events) != 1 || events[0].ID != "a" {
+		t.Fatalf("reportEvents = %v", events)
+	}
+	if msg := formatScheduledReport(report, events); !strings.Contains(msg, "Daily Report: Shadow") || !strings.Contains(msg, "Shadow Creek (Apr 10 2026) - Las Vegas, NV") {
+		t.Errorf("formatScheduledReport:\n%s", msg)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/calendar"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// maxSeasonMessageLen keeps each /season message well under Telegram's 4096-character limit
+const maxSeasonMessageLen = 3500
+
+// seasonEvents returns the upcoming events in the user's subscribed states, soonest first,
+// leaving out events they've skipped
+func seasonEvents(prefs preferences.Preferences, chatID string, allEvents []*event.Event) []*event.Event {
+	user := prefs.GetUser(chatID)
+	return userQuery(user).States().Upcoming().
+		Where(func(evt *event.Event) bool { return user.GetEventStatus(evt.ID) != preferences.EventStatusSkip }).
+		Sort().Run(allEvents)
+}
+
+// formatSeason renders events (sorted by date) as a month-by-month schedule, split into
+// messages that fit Telegram's limit. A month is only split when it won't fit in a
+// message of its own.
+func formatSeason(user *preferences.UserPreferences, events []*event.Event) []string {
+	var months []string
+	lines := make(map[string][]string)
+	for _, evt := range events {
+		month := "Date TBD"
+		if date := event.ParseDate(evt.DateText); !date.IsZero() {
+			month = date.Format("January 2006")
+		}
+		if _, ok := lines[month]; !ok {
+			months = append(months, month)
+		}
+
+		emoji, _ := getStatusDisplay(user.GetEventStatus(evt.ID))
+		if emoji == "" {
+			emoji = "▫️"
+		}
+		line := fmt.Sprintf("%s %s — %s", emoji, html.EscapeString(evt.DateText), html.EscapeString(evt.Title))
+		if evt.City != "" {
+			line += fmt.Sprintf(" (%s, %s)", html.EscapeString(evt.City), evt.State)
+		}
+		lines[month] = append(lines[month], line+"\n")
+	}
+
+	var messages []string
+	var current strings.Builder
+	current.WriteString(fmt.Sprintf("🗓 <b>Your Season</b>\n\n%d upcoming event(s) • ✅ Registered ⭐ Interested 🤔 Maybe ▫️ Not marked\n", len(events)))
+	flush := func() {
+		messages = append(messages, strings.TrimRight(current.String(), "\n"))
+		current.Reset()
+	}
+
+	for _, month := range months {
+		header := fmt.Sprintf("\n📅 <b>%s</b>\n", month)
+		block := header + strings.Join(lines[month], "")
+		if current.Len()+len(block) > maxSeasonMessageLen && len(block) <= maxSeasonMessageLen {
+			flush()
+		}
+		if current.Len()+len(block) <= maxSeasonMessageLen {
+			current.WriteString(block)
+			continue
+		}
+
+		current.WriteString(header)
+		for _, line := range lines[month] {
+			if current.Len()+len(line) > maxSeasonMessageLen {
+				flush()
+				current.WriteString(fmt.Sprintf("📅 <b>%s</b> (continued)\n", month))
+			}
+			current.WriteString(line)
+		}
+	}
+	flush()
+	return messages
+}
+
+// seasonKeyboard offers the season as a calendar download
+func seasonKeyboard() *telegram.InlineKeyboardMarkup {
+	return &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "📅 Export season to calendar", CallbackData: "season:export"}},
+		},
+	}
+}
+
+func handleSeason(prefs preferences.Preferences, chatID string, botToken string, dryRun bool) (string, []*event.Event) {
+	if len(prefs.GetStates(chatID)) == 0 {
+		return `🗓 <b>Your Season</b>
+
+You're not subscribed to any states yet.
+
+Use /subscribe to pick the states you play in, then /season shows their schedule month by month.`, nil
+	}
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	events := seasonEvents(prefs, chatID, allEvents)
+	if len(events) == 0 {
+		return "🗓 <b>Your Season</b>\n\nNo upcoming events in your subscribed states right now.", nil
+	}
+
+	messages := formatSeason(prefs.GetUser(chatID), events)
+	if dryRun {
+		return strings.Join(messages, "\n\n") + "\n\n[📅 Export season to calendar]", nil
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return strings.Join(messages, "\n\n"), nil
+	}
+	for _, msg := range messages[:len(messages)-1] {
+		if err := client.SendMessage(botCtx, msg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending season: %v\n", err)
+		}
+		if telegram.Pause(botCtx, 1*time.Second) != nil {
+			return "", nil // Shutting down
+		}
+	}
+	return sendWithKeyboard(chatID, messages[len(messages)-1], seasonKeyboard(), botToken, dryRun), nil
+}
+
+// handleSeasonExportCallback sends the /season events as one .ics file, with each
+// event's status and note carried into the calendar entries
+func handleSeasonExportCallback(prefs preferences.Preferences, chatID string, botToken string, dryRun bool) string {
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents
+	}
+
+	events := seasonEvents(prefs, chatID, allEvents)
+	if len(events) == 0 {
+		return "ℹ️ No upcoming events in your subscribed states to export"
+	}
+
+	user := prefs.GetUser(chatID)
+	opts := make(map[string]*calendar.EventOptions, len(events))
+	for _, evt := range events {
+		opts[evt.ID] = &calendar.EventOptions{
+			Status: user.GetEventStatus(evt.ID),
+			Note:   user.GetEventNote(evt.ID),
+		}
+	}
+	icsContent := calendar.GenerateBulkICSWithOptions(events, "VGA Golf Season", opts)
+
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would export %d season event(s) to calendar", len(events))
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		return errSendingCalendarFile
+	}
+	caption := fmt.Sprintf("📅 <b>Your VGA Season</b>\n\n✅ Exported %d event(s)\n\nTap the file to import them into your calendar app!", len(events))
+	if err := client.SendDocument(botCtx, "vga-season.ics", []byte(icsContent), caption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
+		return errSendingCalendarFile
+	}
+	return fmt.Sprintf("✅ Calendar file sent with <b>%d</b> event(s)!", len(events))
+}
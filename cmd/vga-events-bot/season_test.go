@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestSeasonEvents(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("111", "NV")
+	user := prefs.GetUser("111")
+	user.SetEventStatus("skipped", preferences.EventStatusSkip)
+
+	all := []*event.Event{
+		{ID: "late", State: "NV", DateText: "Dec 5 2099"},
+		{ID: "early", State: "NV", DateText: "Nov 1 2099"},
+		{ID: "skipped", State: "NV", DateText: "Nov 2 2099"},
+		{ID: "past", State: "NV", DateText: "Jan 1 2020"},
+		{ID: "other", State: "CA", DateText: "Nov 3 2099"},
+	}
+	events := seasonEvents(prefs, "111", all)
+	if len(events) != 2 || events[0].ID != "early" || events[1].ID != "late" {
+		t.Errorf("seasonEvents() = %v", events)
+	}
+}
+
+func TestFormatSeason(t *testing.T) {
+	user := preferences.NewPreferences().GetUser("111")
+	user.SetEventStatus("a", preferences.EventStatusRegistered)
+	events := []*event.Event{
+		{ID: "a", Title: "Wolf Creek", City: "Mesquite", State: "NV", DateText: "Apr 4 2099"},
+		{ID: "b", Title: "Paiute <Sun>", State: "NV", DateText: "Apr 18 2099"},
+		{ID: "c", Title: "Shadow Creek", State: "NV", DateText: "May 2 2099"},
+		{ID: "d", Title: "Mystery Open", State: "NV", DateText: "TBD"},
+	}
+
+	messages := formatSeason(user, events)
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	for _, want := range []string{
+		"4 upcoming event(s)",
+		"📅 <b>April 2099</b>\n✅ Apr 4 2099 — Wolf Creek (Mesquite, NV)\n▫️ Apr 18 2099 — Paiute &lt;Sun&gt;",
+		"📅 <b>May 2099</b>",
+		"📅 <b>Date TBD</b>\n▫️ TBD — Mystery Open",
+	} {
+		if !strings.Contains(messages[0], want) {
+			t.Errorf("season missing %q:\n%s", want, messages[0])
+		}
+	}
+
+	// A long season is split between messages, each under the limit
+	var many []*event.Event
+	for i := range 200 {
+		many = append(many, &event.Event{ID: fmt.Sprint(i), Title: strings.Repeat("x", 40), State: "NV", DateText: fmt.Sprintf("Jun %d 2099", i%28+1)})
+	}
+	messages = formatSeason(user, many)
+	if len(messages) < 2 {
+		t.Fatalf("got %d messages, want the season split", len(messages))
+	}
+	for i, msg := range messages {
+		if len(msg) > maxSeasonMessageLen {
+			t.Errorf("message %d is %d bytes, over the limit", i, len(msg))
+		}
+	}
+	if !strings.HasPrefix(messages[1], "📅 <b>June 2099</b> (continued)") {
+		t.Errorf("second message should continue the month: %q", messages[1][:60])
+	}
+}
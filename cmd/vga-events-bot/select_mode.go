@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/calendar"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// selectPageSize is how many event checkboxes are shown per page in select mode
+const selectPageSize = 8
+
+// maxSelectLabelLen keeps checkbox button labels readable on phones
+const maxSelectLabelLen = 48
+
+// selectableEvents returns the events shown in select mode, matching /events:
+// subscribed states, time filters and active filter applied, sorted by date
+func selectableEvents(user *preferences.UserPreferences, allEvents []*event.Event) []*event.Event {
+	return userQuery(user).States().HidePast().Horizon().ApplyFilter().Sort().Run(allEvents)
+}
+
+// selectedEvents returns the events from the list that are currently selected
+func selectedEvents(user *preferences.UserPreferences, events []*event.Event) []*event.Event {
+	var selected []*event.Event
+	for _, evt := range events {
+		if user.IsEventSelected(evt.ID) {
+			selected = append(selected, evt)
+		}
+	}
+	return selected
+}
+
+// selectEventLabel returns the checkbox button text for an event
+func selectEventLabel(evt *event.Event, selected bool) string {
+	box := "⬜"
+	if selected {
+		box = "☑️"
+	}
+
+	label := fmt.Sprintf("%s %s", evt.State, evt.Title)
+	if evt.DateText != "" {
+		label = fmt.Sprintf("%s %s • %s", evt.State, evt.DateText, evt.Title)
+	}
+	if runes := []rune(label); len(runes) > maxSelectLabelLen {
+		label = string(runes[:maxSelectLabelLen-1]) + "…"
+	}
+
+	return box + " " + label
+}
+
+// buildSelectKeyboard renders one page of select mode: a checkbox row per event,
+// page navigation, and the actions footer for the current selection
+func buildSelectKeyboard(user *preferences.UserPreferences, events []*event.Event, page int) (string, *telegram.InlineKeyboardMarkup) {
+	totalPages := (len(events) + selectPageSize - 1) / selectPageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+
+	selectedCount := len(selectedEvents(user, events))
+
+	text := fmt.Sprintf(`☑️ <b>Select Events</b>
+
+Tap events to select them, then choose an action below.
+
+Page %d/%d • <b>%d</b> selected`, page+1, totalPages, selectedCount)
+
+	if len(events) == 0 {
+		text = "☑️ <b>Select Events</b>\n\nNo events found for your subscribed states and filters."
+	}
+
+	var rows [][]telegram.InlineKeyboardButton
+
+	start := page * selectPageSize
+	end := min(start+selectPageSize, len(events))
+	for _, evt := range events[start:end] {
+		rows = append(rows, []telegram.InlineKeyboardButton{
+			{Text: selectEventLabel(evt, user.IsEventSelected(evt.ID)), CallbackData: fmt.Sprintf("select:toggle:%d:%s", page, evt.ID)},
+		})
+	}
+
+	// Page navigation
+	if totalPages > 1 {
+		var nav []telegram.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, telegram.InlineKeyboardButton{Text: "◀️ Prev", CallbackData: fmt.Sprintf("select:page:%d", page-1)})
+		}
+		if page < totalPages-1 {
+			nav = append(nav, telegram.InlineKeyboardButton{Text: "Next ▶️", CallbackData: fmt.Sprintf("select:page:%d", page+1)})
+		}
+		rows = append(rows, nav)
+	}
+
+	// Actions footer
+	if selectedCount > 0 {
+		rows = append(rows,
+			[]telegram.InlineKeyboardButton{
+				{Text: fmt.Sprintf("✅ Registered (%d)", selectedCount), CallbackData: fmt.Sprintf("select:apply:%d:%s", page, preferences.EventStatusRegistered)},
+				{Text: fmt.Sprintf("❌ Skip (%d)", selectedCount), CallbackData: fmt.Sprintf("select:apply:%d:%s", page, preferences.EventStatusSkip)},
+			},
+			[]telegram.InlineKeyboardButton{
+				{Text: fmt.Sprintf("📥 Export (%d)", selectedCount), CallbackData: "select:export"},
+				{Text: "🧹 Clear", CallbackData: fmt.Sprintf("select:clear:%d", page)},
+			},
+		)
+	}
+	rows = append(rows, []telegram.InlineKeyboardButton{
+		{Text: "✔️ Done", CallbackData: "select:done"},
+	})
+
+	return text, &telegram.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// handleSelectMode sends the first page of select mode (/bulk select)
+func handleSelectMode(prefs preferences.Preferences, chatID, botToken string, dryRun bool) (string, []*event.Event) {
+	user := prefs.GetUser(chatID)
+	if len(user.States) == 0 {
+		return "☑️ <b>Select Events</b>\n\nYou're not subscribed to any states yet.\n\nUse /subscribe to choose states first.", nil
+	}
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+
+	text, keyboard := buildSelectKeyboard(user, selectableEvents(user, allEvents), 0)
+
+	if !dryRun {
+		client, err := newSender(botToken, chatID)
+		if err != nil {
+			return "❌ Error displaying select mode", nil
+		}
+
+		if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
+			return "❌ Error sending select mode", nil
+		}
+		return "", nil // Message sent via keyboard
+	}
+
+	return text, nil
+}
+
+// handleSelectCallback handles select mode buttons.
+// Format: select:page:N, select:toggle:N:EVENT_ID, select:apply:N:STATUS,
+// select:clear:N, select:export, select:done
+func handleSelectCallback(callbackData string, prefs preferences.Preferences, chatID string, modified *bool, botToken string, dryRun bool) (string, *telegram.InlineKeyboardMarkup) {
+	parts := strings.Split(callbackData, ":")
+	if len(parts) < 2 {
+		return "❌ Invalid selection", nil
+	}
+
+	user := prefs.GetUser(chatID)
+	action := parts[1]
+
+	if action == "done" {
+		count := len(user.SelectedEventIDs)
+		if count > 0 {
+			user.ClearEventSelection()
+			*modified = true
+		}
+		return "✔️ <b>Select mode closed.</b>\n\nUse /bulk select to start again.", nil
+	}
+
+	page := 0
+	if len(parts) > 2 {
+		if n, err := strconv.Atoi(parts[2]); err == nil {
+			page = n
+		}
+	}
+
+	allEvents, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+		return errFetchingEvents, nil
+	}
+	events := selectableEvents(user, allEvents)
+
+	switch action {
+	case "page":
+		// Just re-render the requested page
+
+	case "toggle":
+		if len(parts) < 4 {
+			return "❌ Invalid selection", nil
+		}
+		user.ToggleEventSelection(parts[3])
+		*modified = true
+
+	case "clear":
+		user.ClearEventSelection()
+		*modified = true
+
+	case "apply":
+		if len(parts) < 4 {
+			return "❌ Invalid selection", nil
+		}
+		selected := selectedEvents(user, events)
+		if len(selected) == 0 {
+			break
+		}
+
+		ids := make([]string, len(selected))
+		for i, evt := range selected {
+			ids[i] = evt.ID
+		}
+		result, _ := handleBulkStatus(prefs, chatID, parts[3], ids, modified)
+
+		user.ClearEventSelection()
+		*modified = true
+
+		text, keyboard := buildSelectKeyboard(user, events, page)
+		return result + "\n" + text, keyboard
+
+	case "export":
+		return exportSelectedEvents(user, events, chatID, botToken, dryRun), nil
+
+	default:
+		return "❌ Unknown select action", nil
+	}
+
+	return buildSelectKeyboard(user, events, page)
+}
+
+// exportSelectedEvents sends the selected events as a single .ics file
+func exportSelectedEvents(user *preferences.UserPreferences, events []*event.Event, chatID, botToken string, dryRun bool) string {
+	selected := selectedEvents(user, events)
+	if len(selected) == 0 {
+		return "ℹ️ No selected events to export"
+	}
+
+	if dryRun {
+		return fmt.Sprintf("[DRY RUN] Would export %d selected event(s) to calendar", len(selected))
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		return errSendingCalendarFile
+	}
+
+	icsContent := calendar.GenerateMultiEventICS(selected)
+	caption := fmt.Sprintf("📅 <b>Selected Events</b>\n\n%d event(s) ready to import to your calendar!", len(selected))
+	if err := client.SendDocument(botCtx, "vga-selected-events.ics", []byte(icsContent), caption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending document: %v\n", err)
+		return errSendingCalendarFile
+	}
+
+	return fmt.Sprintf("✅ Calendar file sent with <b>%d</b> selected event(s)!\n\nUse /bulk select to pick more events.", len(selected))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func makeSelectEvents(n int) []*event.Event {
+	events := make([]*event.Event, n)
+	for i := range events {
+		events[i] = &event.Event{
+			ID:       fmt.Sprintf("%040d", i),
+			State:    "NV",
+			Title:    fmt.Sprintf("Course %d", i),
+			DateText: futureDate(i + 1),
+		}
+	}
+	return events
+}
+
+func TestBuildSelectKeyboard(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("12345")
+	events := makeSelectEvents(10)
+
+	text, keyboard := buildSelectKeyboard(user, events, 0)
+	if !strings.Contains(text, "Page 1/2") || !strings.Contains(text, "<b>0</b> selected") {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+
+	// 8 event rows + nav + done
+	if len(keyboard.InlineKeyboard) != selectPageSize+2 {
+		t.Fatalf("got %d rows, want %d", len(keyboard.InlineKeyboard), selectPageSize+2)
+	}
+	first := keyboard.InlineKeyboard[0][0]
+	if !strings.HasPrefix(first.Text, "⬜") || first.CallbackData != "select:toggle:0:"+events[0].ID {
+		t.Errorf("unexpected first row: %+v", first)
+	}
+	for _, row := range keyboard.InlineKeyboard {
+		for _, btn := range row {
+			if len(btn.CallbackData) > maxCallbackDataLen {
+				t.Errorf("callback data too long (%d): %s", len(btn.CallbackData), btn.CallbackData)
+			}
+		}
+	}
+
+	// Selecting shows checkbox and the actions footer
+	user.ToggleEventSelection(events[9].ID)
+	text, keyboard = buildSelectKeyboard(user, events, 1)
+	if !strings.Contains(text, "Page 2/2") || !strings.Contains(text, "<b>1</b> selected") {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+	if got := keyboard.InlineKeyboard[1][0].Text; !strings.HasPrefix(got, "☑️") {
+		t.Errorf("selected event should be checked, got %q", got)
+	}
+
+	var callbacks []string
+	for _, row := range keyboard.InlineKeyboard {
+		for _, btn := range row {
+			callbacks = append(callbacks, btn.CallbackData)
+		}
+	}
+	joined := strings.Join(callbacks, " ")
+	for _, want := range []string{"select:page:0", "select:apply:1:registered", "select:apply:1:skip", "select:export", "select:done"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing callback %q in %v", want, callbacks)
+		}
+	}
+}
+
+func TestBuildSelectKeyboardPageBounds(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("12345")
+
+	text, keyboard := buildSelectKeyboard(user, makeSelectEvents(3), 5)
+	if !strings.Contains(text, "Page 1/1") {
+		t.Errorf("out-of-range page should clamp, got:\n%s", text)
+	}
+	// 3 events + done, no navigation
+	if len(keyboard.InlineKeyboard) != 4 {
+		t.Errorf("got %d rows, want 4", len(keyboard.InlineKeyboard))
+	}
+
+	text, _ = buildSelectKeyboard(user, nil, 0)
+	if !strings.Contains(text, "No events found") {
+		t.Errorf("expected empty message, got:\n%s", text)
+	}
+}
+
+func TestSelectableEvents(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("12345")
+	user.States = []string{"NV"}
+	user.HidePastEvents = true
+
+	events := []*event.Event{
+		{ID: "b", State: "NV", Title: "Later", DateText: futureDate(10)},
+		{ID: "a", State: "NV", Title: "Sooner", DateText: futureDate(2)},
+		{ID: "c", State: "CA", Title: "Other State", DateText: futureDate(3)},
+		{ID: "d", State: "NV", Title: "Past", DateText: futureDate(-3)},
+	}
+
+	got := eventIDs(selectableEvents(user, events))
+	if strings.Join(got, ",") != "a,b" {
+		t.Errorf("selectableEvents = %v, want [a b]", got)
+	}
+
+	// The days-ahead horizon applies here as in /events
+	user.DaysAhead = 5
+	got = eventIDs(selectableEvents(user, events))
+	if strings.Join(got, ",") != "a" {
+		t.Errorf("selectableEvents with a 5-day horizon = %v, want [a]", got)
+	}
+}
+
+func TestSelectEventLabelTruncates(t *testing.T) {
+	evt := &event.Event{State: "NV", DateText: "Mar 15 2027", Title: strings.Repeat("Long Course Name ", 10)}
+	label := selectEventLabel(evt, false)
+	if len([]rune(label)) > maxSelectLabelLen+2 || !strings.HasSuffix(label, "…") {
+		t.Errorf("label not truncated: %q", label)
+	}
+}
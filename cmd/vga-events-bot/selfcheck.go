@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
+	"github.com/pfrederiksen/vga-events/internal/errs"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// botAccount is the part of the Telegram client the self-check uses
+type botAccount interface {
+	GetMe(ctx context.Context) (*telegram.User, error)
+}
+
+// gistReader is the part of the Gist storage the self-check uses
+type gistReader interface {
+	Check(ctx context.Context) error
+}
+
+// selfCheckResult is what the startup self-check found
+type selfCheckResult struct {
+	problems []string // What to fix; the bot shouldn't start
+	warnings []string // Failures that may be temporary; the bot starts anyway
+	botName  string   // The bot's @username, once its token is confirmed
+}
+
+// checkConfig returns what's wrong with the optional flags, each saying how to fix it.
+// Required flags are checked where they're first needed.
+func checkConfig() []string {
+	var problems []string
+	if chat := *adminChat; chat != "" && !strings.HasPrefix(chat, "@") {
+		if _, err := strconv.ParseInt(chat, 10, 64); err != nil {
+			problems = append(problems, fmt.Sprintf("--admin-chat (TELEGRAM_ADMIN_CHAT_ID) is %q; use a numeric chat ID, or @name for a channel", chat))
+		}
+	}
+	if *reportRepo != "" {
+		if owner, name, ok := strings.Cut(*reportRepo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			problems = append(problems, fmt.Sprintf("--report-repo (VGA_REPORT_REPO) is %q; use owner/name, e.g. pfrederiksen/vga-events", *reportRepo))
+		}
+		if *reportToken == "" {
+			problems = append(problems, "--report-repo (VGA_REPORT_REPO) needs a token that can open issues there: set --report-github-token or VGA_REPORT_GITHUB_TOKEN")
+		}
+	}
+	return problems
+}
+
+// selfCheck validates the configuration and confirms the bot token and Gist work
+// before the bot starts, so a bad deploy fails with a message saying what to fix
+// instead of an error on every update. Network failures that retrying may fix are
+// only warnings.
+func selfCheck(ctx context.Context, bot botAccount, gist gistReader) selfCheckResult {
+	result := selfCheckResult{problems: checkConfig()}
+
+	if me, err := bot.GetMe(ctx); err != nil {
+		switch {
+		case errs.IsPermanent(err):
+			result.problems = append(result.problems, "Telegram rejected the bot token (TELEGRAM_BOT_TOKEN): copy it again from @BotFather with /token, or generate a new one if it was revoked")
+		default:
+			result.warnings = append(result.warnings, fmt.Sprintf("could not reach Telegram to check the bot token: %v", err))
+		}
+	} else {
+		result.botName = "@" + me.Username
+	}
+
+	if err := gist.Check(ctx); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrNotFound):
+			result.problems = append(result.problems, fmt.Sprintf("Gist %s wasn't found: check TELEGRAM_GIST_ID, and that the gist belongs to the account TELEGRAM_GITHUB_TOKEN is for", *gistID))
+		case errors.Is(err, errs.ErrAuth):
+			result.problems = append(result.problems, "GitHub rejected TELEGRAM_GITHUB_TOKEN: it may have expired, or lack the gist scope")
+		default:
+			result.warnings = append(result.warnings, fmt.Sprintf("could not read Gist %s: %v", *gistID, err))
+		}
+	}
+
+	return result
+}
+
+// formatVersion builds the /version message
+func formatVersion(info buildinfo.Info) string {
+	commit := info.Commit[:min(len(info.Commit), 12)]
+	if info.Modified {
+		commit += " (modified)"
+	}
+	var msg strings.Builder
+	msg.WriteString("ℹ️ <b>VGA Events Bot</b>\n\n")
+	msg.WriteString(fmt.Sprintf("<b>Version:</b> <code>%s</code>\n", html.EscapeString(info.Version)))
+	msg.WriteString(fmt.Sprintf("<b>Commit:</b> <code>%s</code>\n", html.EscapeString(commit)))
+	msg.WriteString(fmt.Sprintf("<b>Built:</b> %s\n", html.EscapeString(info.Date)))
+	msg.WriteString(fmt.Sprintf("<b>Go:</b> %s", html.EscapeString(info.GoVersion)))
+	return msg.String()
+}
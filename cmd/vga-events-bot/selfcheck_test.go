@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
+	"github.com/pfrederiksen/vga-events/internal/errs"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// fakeBot answers getMe with a fixed account or error
+type fakeBot struct{ err error }
+
+func (b fakeBot) GetMe(ctx context.Context) (*telegram.User, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &telegram.User{ID: 42, Username: "vga_events_bot"}, nil
+}
+
+// fakeGist fails the Gist read with err, if set
+type fakeGist struct{ err error }
+
+func (g fakeGist) Check(ctx context.Context) error { return g.err }
+
+func TestSelfCheck(t *testing.T) {
+	oldAdmin, oldRepo, oldToken := *adminChat, *reportRepo, *reportToken
+	defer func() { *adminChat, *reportRepo, *reportToken = oldAdmin, oldRepo, oldToken }()
+
+	tests := []struct {
+		name                  string
+		admin, repo, token    string
+		botErr, gistErr       error
+		wantProblems          []string
+		wantWarnings, wantBot bool
+	}{
+		{name: "all good", admin: "-1001234", repo: "owner/repo", token: "t", wantBot: true},
+		{name: "channel admin chat", admin: "@maintainers", wantBot: true},
+		{name: "bad admin chat", admin: "me", wantProblems: []string{"--admin-chat"}, wantBot: true},
+		{name: "bad report repo", repo: "vga-events", token: "t", wantProblems: []string{"use owner/name"}, wantBot: true},
+		{name: "report repo without token", repo: "owner/repo", wantProblems: []string{"VGA_REPORT_GITHUB_TOKEN"}, wantBot: true},
+		{
+			name:         "revoked bot token",
+			botErr:       errs.Status(http.StatusUnauthorized, "telegram API error: Unauthorized"),
+			wantProblems: []string{"@BotFather"},
+		},
+		{
+			name:         "missing gist",
+			gistErr:      errs.Status(http.StatusNotFound, "GitHub API error (status 404)"),
+			wantProblems: []string{"TELEGRAM_GIST_ID"},
+			wantBot:      true,
+		},
+		{
+			name:         "bad github token",
+			gistErr:      errs.Status(http.StatusUnauthorized, "GitHub API error (status 401)"),
+			wantProblems: []string{"gist scope"},
+			wantBot:      true,
+		},
+		{
+			name:         "network down",
+			botErr:       errors.New("dial tcp: connection refused"),
+			gistErr:      errs.Status(http.StatusBadGateway, "GitHub API error (status 502)"),
+			wantWarnings: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*adminChat, *reportRepo, *reportToken = tt.admin, tt.repo, tt.token
+			got := selfCheck(context.Background(), fakeBot{tt.botErr}, fakeGist{tt.gistErr})
+
+			if len(got.problems) != len(tt.wantProblems) {
+				t.Fatalf("problems = %q, want %d containing %q", got.problems, len(tt.wantProblems), tt.wantProblems)
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.Contains(got.problems[i], want) {
+					t.Errorf("problem %q doesn't mention %q", got.problems[i], want)
+				}
+			}
+			if (len(got.warnings) > 0) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want any: %v", got.warnings, tt.wantWarnings)
+			}
+			if (got.botName == "@vga_events_bot") != tt.wantBot {
+				t.Errorf("botName = %q, want confirmed: %v", got.botName, tt.wantBot)
+			}
+		})
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	msg := formatVersion(buildinfo.Info{Version: "dev", Commit: "0123456789abcdef0123", Date: "2026-01-02T03:04:05Z", GoVersion: "go1.24.0", Modified: true})
+	for _, want := range []string{"<code>dev</code>", "<code>0123456789ab (modified)</code>", "2026-01-02T03:04:05Z", "go1.24.0"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("formatVersion() = %q, missing %q", msg, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// Sender is the part of the Telegram Bot API the bot's handlers use, implemented by
+// *telegram.Client for one chat
+type Sender interface {
+	SendMessage(ctx context.Context, text string) error
+	SendMessageWithKeyboard(ctx context.Context, text string, keyboard *telegram.InlineKeyboardMarkup) error
+	SendTrackedMessage(ctx context.Context, text string, keyboard *telegram.InlineKeyboardMarkup) (int, error)
+	EditMessageText(ctx context.Context, chatID string, messageID int, text string, keyboard *telegram.InlineKeyboardMarkup) error
+	AnswerCallbackQuery(ctx context.Context, callbackID string, text string, showAlert bool) error
+	SendPhoto(ctx context.Context, photoURL, caption string) error
+	SendVoice(ctx context.Context, fileID, caption string) error
+	SendDocument(ctx context.Context, filename string, content []byte, caption string) error
+	SendDocumentID(ctx context.Context, fileID, caption string) error
+	UploadDocument(ctx context.Context, filename string, content []byte, caption string) (string, error)
+	DownloadFile(ctx context.Context, fileID string) ([]byte, error)
+	SendPoll(ctx context.Context, question string, options []string) (*telegram.SentPoll, error)
+	StopPoll(ctx context.Context, messageID int) error
+}
+
+// newSender returns a Sender for chatID. It's a variable so tests can record what
+// handlers send instead of calling Telegram.
+var newSender = func(botToken, chatID string) (Sender, error) {
+	client, err := telegram.NewClient(botToken, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// Pages of the /settings menu, in the callback data after "settings:"
+const (
+	settingsPageMain   = ""
+	settingsPageNotify = "notify"
+	settingsPageTime   = "time"
+	settingsPageEvents = "events"
+)
+
+// daysAheadOptions are the choices for how far ahead notified events can be; 0 is
+// no limit
+var daysAheadOptions = []int{0, 7, 14, 30, 60, 90}
+
+// settingsDays are the weekday buttons on the digest time page, Monday first
+var settingsDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// onOff labels a toggle button's state
+func onOff(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
+
+// checked marks the button for the current choice in a picker
+func checked(label string, current bool) string {
+	if current {
+		return "✓ " + label
+	}
+	return label
+}
+
+// describeDaysAhead describes the days-ahead setting
+func describeDaysAhead(days int) string {
+	if days <= 0 {
+		return "any date"
+	}
+	return fmt.Sprintf("the next %d days", days)
+}
+
+// describeReminders lists the reminder days, e.g. "1 day, 1 week before"
+func describeReminders(days []int) string {
+	if len(days) == 0 {
+		return "off"
+	}
+	labels := make([]string, 0, len(days))
+	for _, d := range days {
+		switch {
+		case d == 1:
+			labels = append(labels, "1 day")
+		case d%7 == 0:
+			labels = append(labels, pluralUnit(d/7, "week"))
+		default:
+			labels = append(labels, pluralUnit(d, "day"))
+		}
+	}
+	return strings.Join(labels, ", ") + " before"
+}
+
+// pluralUnit formats a count with its unit, e.g. "3 days"
+func pluralUnit(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// showSettingsKeyboard shows the main /settings page
+func showSettingsKeyboard(prefs preferences.Preferences, chatID string) (string, *telegram.InlineKeyboardMarkup) {
+	return showSettingsPage(prefs, chatID, settingsPageMain)
+}
+
+// showSettingsPage builds one page of the settings menu. Every page edits the same
+// message, with a button back to the main page.
+func showSettingsPage(prefs preferences.Preferences, chatID, page string) (string, *telegram.InlineKeyboardMarkup) {
+	user := prefs.GetUser(chatID)
+	back := []telegram.InlineKeyboardButton{{Text: "⬅️ Back to Settings", CallbackData: "settings"}}
+
+	switch page {
+	case settingsPageNotify:
+		text := `📬 <b>Notifications</b>
+
+• <b>Immediate</b> - Get notified as soon as new events are posted
+• <b>Daily</b> - One digest a day
+• <b>Weekly</b> - One digest a week
+
+Events at courses you follow are always sent right away. You can also be told when an event you're tracking changes, or when an event is removed from the VGA site.`
+		keyboard := &telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{
+				{Text: checked("📨 Immediate", user.DigestFrequency == preferences.DigestFrequencyImmediate), CallbackData: "digest:immediate"},
+				{Text: checked("📅 Daily", user.DigestFrequency == preferences.DigestFrequencyDaily), CallbackData: "digest:daily"},
+				{Text: checked("📆 Weekly", user.DigestFrequency == preferences.DigestFrequencyWeekly), CallbackData: "digest:weekly"},
+			},
+			{{Text: "🔄 Changes to tracked events: " + onOff(user.NotifyOnChanges), CallbackData: "settings:toggle:changes"}},
+			{{Text: "⚠️ Removed events: " + onOff(user.NotifyOnRemoval), CallbackData: "settings:toggle:removals"}},
+			back,
+		}}
+		return text, keyboard
+
+	case settingsPageTime:
+		text := fmt.Sprintf(`🕘 <b>Digest Time</b>
+
+Digests are sent %s. Pick an hour, and a day for weekly digests.
+
+Times are in %s. To change the time zone, send e.g. <code>/settings time 8am America/Chicago</code>.`,
+			digestSchedule(user), user.DigestLocation())
+		keyboard := &telegram.InlineKeyboardMarkup{}
+		for start := 0; start < 24; start += 6 {
+			row := make([]telegram.InlineKeyboardButton, 0, 6)
+			for hour := start; hour < start+6; hour++ {
+				row = append(row, telegram.InlineKeyboardButton{
+					Text:         checked(fmt.Sprintf("%02d", hour), hour == user.DigestHour),
+					CallbackData: fmt.Sprintf("settings:hour:%d", hour),
+				})
+			}
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+		}
+		var days []telegram.InlineKeyboardButton
+		for _, day := range settingsDays {
+			days = append(days, telegram.InlineKeyboardButton{
+				Text:         checked(day.String()[:3], int(day) == user.DigestDayOfWeek),
+				CallbackData: fmt.Sprintf("settings:day:%d", day),
+			})
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, days[:4], days[4:], back)
+		return text, keyboard
+
+	case settingsPageEvents:
+		past := "hidden"
+		if !user.HidePastEvents {
+			past = "shown"
+		}
+		text := fmt.Sprintf(`📅 <b>Events Shown</b>
+
+Choose which events you're notified about and shown in /events, /search, /near, and digests.
+
+<b>Past events:</b> %s
+<b>How far ahead:</b> %s
+
+For another limit, send e.g. <code>/horizon 45</code>.`, past, describeDaysAhead(user.DaysAhead))
+		keyboard := &telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "🕰 Hide past events: " + onOff(user.HidePastEvents), CallbackData: "settings:toggle:past"}},
+		}}
+		var row []telegram.InlineKeyboardButton
+		for _, days := range daysAheadOptions {
+			label := "Any"
+			if days > 0 {
+				label = fmt.Sprintf("%dd", days)
+			}
+			row = append(row, telegram.InlineKeyboardButton{
+				Text:         checked(label, days == user.DaysAhead),
+				CallbackData: fmt.Sprintf("settings:days:%d", days),
+			})
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row, back)
+		return text, keyboard
+	}
+
+	shown := "upcoming only"
+	if !user.HidePastEvents {
+		shown = "including past"
+	}
+	text := fmt.Sprintf(`⚙️ <b>Settings</b>
+
+📬 <b>Notifications:</b> %s
+🕘 <b>Digest time:</b> %s
+📅 <b>Events shown:</b> %s, %s
+🔔 <b>Reminders:</b> %s
+📊 <b>Weekly stats:</b> %s
+
+Choose what to change:`,
+		user.DigestFrequency, digestSchedule(user),
+		shown, describeDaysAhead(user.DaysAhead),
+		describeReminders(user.ReminderDays), onOff(user.EnableStats))
+	keyboard := &telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{
+		{
+			{Text: "📬 Notifications", CallbackData: "settings:" + settingsPageNotify},
+			{Text: "🕘 Digest time", CallbackData: "settings:" + settingsPageTime},
+		},
+		{
+			{Text: "📅 Events shown", CallbackData: "settings:" + settingsPageEvents},
+			{Text: "🔔 Reminders", CallbackData: "menu:reminders"},
+		},
+		{
+			{Text: "📊 Weekly stats: " + onOff(user.EnableStats), CallbackData: "settings:toggle:stats"},
+			{Text: "👥 Sharing", CallbackData: "sharing:menu"},
+		},
+	}}
+	return text, keyboard
+}
+
+// handleSettingsCallback applies a change from the settings menu and shows the page it
+// was made on
+// Format: settings[:PAGE] | settings:toggle:SETTING | settings:days|hour|day:VALUE
+func handleSettingsCallback(prefs preferences.Preferences, chatID string, parts []string, modified *bool) (string, *telegram.InlineKeyboardMarkup) {
+	user := prefs.GetUser(chatID)
+	if len(parts) < 3 {
+		page := ""
+		if len(parts) == 2 {
+			page = parts[1]
+		}
+		return showSettingsPage(prefs, chatID, page)
+	}
+
+	if parts[1] == "toggle" {
+		page := settingsPageMain
+		switch parts[2] {
+		case "changes":
+			user.NotifyOnChanges = !user.NotifyOnChanges
+			page = settingsPageNotify
+		case "removals":
+			user.NotifyOnRemoval = !user.NotifyOnRemoval
+			page = settingsPageNotify
+		case "past":
+			user.HidePastEvents = !user.HidePastEvents
+			page = settingsPageEvents
+		case "stats":
+			user.EnableStats = !user.EnableStats
+		default:
+			return "❌ Unknown setting", nil
+		}
+		*modified = true
+		return showSettingsPage(prefs, chatID, page)
+	}
+
+	value, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "❌ Invalid setting", nil
+	}
+	switch parts[1] {
+	case "days":
+		if !user.SetDaysAhead(value) {
+			return "❌ Invalid setting", nil
+		}
+		*modified = true
+		return showSettingsPage(prefs, chatID, settingsPageEvents)
+	case "hour":
+		if user.SetDigestTime(time.Weekday(user.DigestDayOfWeek), value, "") != nil {
+			return "❌ Invalid setting", nil
+		}
+	case "day":
+		if user.SetDigestTime(time.Weekday(value), user.DigestHour, "") != nil {
+			return "❌ Invalid setting", nil
+		}
+	default:
+		return "❌ Unknown setting", nil
+	}
+	*modified = true
+	return showSettingsPage(prefs, chatID, settingsPageTime)
+}
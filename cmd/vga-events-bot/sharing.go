@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// sharingLabel describes a sharing level on buttons and in /friends
+func sharingLabel(level string) string {
+	switch level {
+	case preferences.ShareRegistered:
+		return "✅ Registered only"
+	case preferences.ShareInterested:
+		return "✅⭐ Registered + interested"
+	}
+	return "🙈 Nothing"
+}
+
+// showSharingKeyboard shows the 👥 Sharing settings: the default level and one button
+// per friend, each tap moving to the next level
+func showSharingKeyboard(prefs preferences.Preferences, chatID string) (string, *telegram.InlineKeyboardMarkup) {
+	user := prefs.GetUser(chatID)
+
+	var text strings.Builder
+	text.WriteString(`👥 <b>Sharing</b>
+
+Choose what each friend can see of your event statuses. Tap a button to change it.
+
+• <b>Nothing</b> - They see none of your events, and you don't see theirs
+• <b>Registered only</b> - Events you've marked ✅ Registered
+• <b>Registered + interested</b> - Also events you've marked ⭐ Interested
+`)
+
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "New friends: " + sharingLabel(user.DefaultSharingLevel()), CallbackData: "sharing:default"}},
+		},
+	}
+	if len(user.FriendChatIDs) == 0 {
+		text.WriteString("\nYou have no friends yet. Use /invite to add some.")
+	}
+	for _, friendID := range user.FriendChatIDs {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []telegram.InlineKeyboardButton{
+			{Text: fmt.Sprintf("%s: %s", friendID, sharingLabel(user.SharingFor(friendID))), CallbackData: "sharing:friend:" + friendID},
+		})
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []telegram.InlineKeyboardButton{
+		{Text: "⬅️ Back to Settings", CallbackData: "settings"},
+	})
+	return text.String(), keyboard
+}
+
+// handleSharingCallback changes a sharing level and shows the updated menu
+// Format: sharing:menu | sharing:default | sharing:friend:CHAT_ID
+func handleSharingCallback(prefs preferences.Preferences, chatID string, parts []string, modified *bool) (string, *telegram.InlineKeyboardMarkup) {
+	user := prefs.GetUser(chatID)
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	switch action {
+	case "default":
+		user.SetDefaultSharing(preferences.NextSharingLevel(user.DefaultSharingLevel()))
+		*modified = true
+	case "friend":
+		if len(parts) < 3 || !user.IsFriend(parts[2]) {
+			return "❌ That user isn't your friend anymore.", nil
+		}
+		user.SetSharing(parts[2], preferences.NextSharingLevel(user.SharingFor(parts[2])))
+		*modified = true
+	}
+	return showSharingKeyboard(prefs, chatID)
+}
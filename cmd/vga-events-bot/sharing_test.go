@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestSharingCallback(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	user := prefs.GetUser("111")
+	user.AddFriend("222")
+	modified := false
+
+	text, keyboard := handleSharingCallback(prefs, "111", []string{"sharing", "menu"}, &modified)
+	if modified || !strings.Contains(text, "Sharing") || len(keyboard.InlineKeyboard) != 3 {
+		t.Fatalf("sharing menu: modified=%v, %d rows", modified, len(keyboard.InlineKeyboard))
+	}
+
+	_, keyboard = handleSharingCallback(prefs, "111", []string{"sharing", "friend", "222"}, &modified)
+	if !modified || user.SharingFor("222") != preferences.ShareRegistered {
+		t.Errorf("tapping a friend should move to the next level, got %q", user.SharingFor("222"))
+	}
+	if got := keyboard.InlineKeyboard[1][0].Text; !strings.Contains(got, "Registered only") {
+		t.Errorf("friend button = %q", got)
+	}
+
+	handleSharingCallback(prefs, "111", []string{"sharing", "default"}, &modified)
+	if user.DefaultSharingLevel() != preferences.ShareRegistered || !user.ShareEvents {
+		t.Errorf("default level = %q", user.DefaultSharingLevel())
+	}
+
+	if text, _ := handleSharingCallback(prefs, "111", []string{"sharing", "friend", "999"}, &modified); !strings.Contains(text, "isn't your friend") {
+		t.Errorf("unknown friend: %q", text)
+	}
+}
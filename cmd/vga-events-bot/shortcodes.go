@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/scraper"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+// Global snapshot storage (initialized if --data-dir is set); its short code
+// index keeps the bot's codes identical to the ones shown in notifications
+var snapshotStore *storage.Storage
+
+// initSnapshotStore opens the snapshot directory used for short codes
+func initSnapshotStore(dir string) error {
+	store, err := storage.New(dir)
+	if err != nil {
+		return err
+	}
+	snapshotStore = store
+	return nil
+}
+
+// scrapeEvents fetches the VGA calendar. It's a variable so tests can serve fixed
+// events instead.
+var scrapeEvents = func(ctx context.Context) ([]*event.Event, error) {
+	return scraper.New().FetchEvents(ctx)
+}
+
+// fetchEvents fetches current events and assigns their short codes
+func fetchEvents() ([]*event.Event, error) {
+	events, err := scrapeEvents(botCtx)
+	if err != nil {
+		if botCtx.Err() == nil {
+			reportError(fmt.Errorf("fetching events: %w", err), "")
+		}
+		return nil, err
+	}
+
+	var previous *event.Snapshot
+	if snapshotStore != nil {
+		previous, err = snapshotStore.LoadSnapshot("all")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not load snapshot for short codes: %v\n", err)
+			previous = nil
+		}
+	}
+
+	event.AssignShortCodes(events, previous)
+	return events, nil
+}
+
+// snapshotEvent returns an event from the latest snapshot without fetching, or nil if
+// there's no snapshot or the event isn't listed in it
+func snapshotEvent(eventID string) *event.Event {
+	if snapshotStore == nil {
+		return nil
+	}
+	snapshot, err := snapshotStore.LoadSnapshot("all")
+	if err != nil {
+		return nil
+	}
+	return snapshot.Listed()[eventID]
+}
+
+// resolveEventIDs replaces short codes ("NV-417") with full event IDs, looking them up
+// in the --data-dir snapshot's short code index. Only codes it doesn't have (events
+// listed since the last check, or no --data-dir) need a fetch of the VGA site.
+// Full IDs pass through unchanged. Returns the resolved IDs and any codes that matched no event.
+func resolveEventIDs(ids []string) ([]string, []string) {
+	ids, pending := resolveStoredCodes(ids)
+	if !pending {
+		return ids, nil
+	}
+
+	events, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+	}
+
+	return resolveEventIDsIn(ids, events)
+}
+
+// resolveKnownEventIDs is resolveEventIDs for commands that store what they're given:
+// full IDs must also name an event in the snapshot, listed or recently removed, or in
+// the current events when there's no snapshot. IDs that don't are returned as unknown.
+func resolveKnownEventIDs(ids []string) ([]string, []string) {
+	resolved, unknown := resolveEventIDs(ids)
+	if len(unknown) > 0 {
+		return resolved, unknown
+	}
+
+	known := knownEventIDs()
+	for _, id := range resolved {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	return resolved, unknown
+}
+
+// knownEventIDs returns the IDs of the snapshot's listed and recently removed events,
+// or of the current events if there's no snapshot
+func knownEventIDs() map[string]bool {
+	known := make(map[string]bool)
+	if snapshotStore != nil {
+		if snapshot, err := snapshotStore.LoadSnapshot("all"); err == nil {
+			for id := range snapshot.Listed() {
+				known[id] = true
+			}
+			for id := range snapshot.RemovedEvents {
+				known[id] = true
+			}
+			return known
+		}
+	}
+
+	events, err := fetchEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching events: %v\n", err)
+	}
+	for _, evt := range events {
+		known[evt.ID] = true
+	}
+	return known
+}
+
+// resolveStoredCodes replaces the short codes found in the snapshot's index with their
+// event IDs, and reports whether any codes are left
+func resolveStoredCodes(ids []string) ([]string, bool) {
+	var codes map[string]string
+	if snapshotStore != nil {
+		if snapshot, err := snapshotStore.LoadSnapshot("all"); err == nil {
+			codes = snapshot.ShortCodes
+		}
+	}
+
+	resolved := make([]string, 0, len(ids))
+	pending := false
+	for _, id := range ids {
+		if event.IsShortCode(id) {
+			if eventID, ok := codes[event.NormalizeShortCode(id)]; ok {
+				id = eventID
+			} else {
+				pending = true
+			}
+		}
+		resolved = append(resolved, id)
+	}
+	return resolved, pending
+}
+
+// resolveEventIDsIn resolves short codes against a list of events
+func resolveEventIDsIn(ids []string, events []*event.Event) ([]string, []string) {
+	resolved := make([]string, 0, len(ids))
+	var unknown []string
+
+	for _, id := range ids {
+		if !event.IsShortCode(id) {
+			resolved = append(resolved, id)
+			continue
+		}
+		if evt := event.FindByShortCode(events, id); evt != nil {
+			resolved = append(resolved, evt.ID)
+		} else {
+			unknown = append(unknown, event.NormalizeShortCode(id))
+		}
+	}
+
+	return resolved, unknown
+}
+
+// formatUnknownCodes returns the error shown when short codes don't match any current event
+func formatUnknownCodes(codes []string) string {
+	return fmt.Sprintf("❌ Unknown event code(s): %s\n\nCodes like <code>NV-417</code> are shown on event cards. Use /events to see current events.", html.EscapeString(strings.Join(codes, ", ")))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func TestResolveEventIDsIn(t *testing.T) {
+	events := []*event.Event{
+		{ID: "aaaa1111", State: "NV", ShortCode: "NV-417"},
+		{ID: "bbbb2222", State: "CA", ShortCode: "CA-102"},
+	}
+
+	resolved, unknown := resolveEventIDsIn([]string{"nv-417", "cccc3333", "CA-102", "TX-999"}, events)
+
+	if strings.Join(resolved, ",") != "aaaa1111,cccc3333,bbbb2222" {
+		t.Errorf("resolved = %v", resolved)
+	}
+	if strings.Join(unknown, ",") != "TX-999" {
+		t.Errorf("unknown = %v, want [TX-999]", unknown)
+	}
+}
+
+func TestResolveEventIDsWithoutCodes(t *testing.T) {
+	ids := []string{"abc123", "def456"}
+	resolved, unknown := resolveEventIDs(ids)
+	if strings.Join(resolved, ",") != "abc123,def456" || len(unknown) != 0 {
+		t.Errorf("full IDs should pass through unchanged, got %v / %v", resolved, unknown)
+	}
+}
+
+func TestResolveEventIDsFromSnapshot(t *testing.T) {
+	fetches := 0
+	original := scrapeEvents
+	scrapeEvents = func(context.Context) ([]*event.Event, error) {
+		fetches++
+		return []*event.Event{{ID: "cccc3333", State: "AZ"}}, nil
+	}
+	t.Cleanup(func() { scrapeEvents = original })
+
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := event.CreateSnapshot([]*event.Event{
+		{ID: "aaaa1111", State: "NV", ShortCode: "NV-417"},
+		{ID: "bbbb2222", State: "CA", ShortCode: "CA-102"},
+	}, "")
+	if err := store.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
+		t.Fatal(err)
+	}
+	originalStore := snapshotStore
+	snapshotStore = store
+	t.Cleanup(func() { snapshotStore = originalStore })
+
+	resolved, unknown := resolveEventIDs([]string{"nv-417", "dddd4444", "CA-102"})
+	if strings.Join(resolved, ",") != "aaaa1111,dddd4444,bbbb2222" || len(unknown) != 0 || fetches != 0 {
+		t.Errorf("resolved = %v, unknown = %v after %d fetches; want the snapshot's IDs without fetching", resolved, unknown, fetches)
+	}
+
+	// A code the snapshot doesn't have yet is looked up on the site
+	listed := &event.Event{ID: "cccc3333", State: "AZ"}
+	event.AssignShortCodes([]*event.Event{listed}, snapshot)
+	resolved, unknown = resolveEventIDs([]string{"NV-417", listed.ShortCode, "TX-999"})
+	if strings.Join(resolved, ",") != "aaaa1111,cccc3333" || strings.Join(unknown, ",") != "TX-999" || fetches != 1 {
+		t.Errorf("resolved = %v, unknown = %v after %d fetches", resolved, unknown, fetches)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// stateKeyboardPageSize is how many states each page of the subscribe keyboard shows,
+// two per row
+const stateKeyboardPageSize = 12
+
+// showStateSelectionKeyboard returns one page of the subscribe keyboard: every state in
+// alphabetical order with a checkmark on the user's subscriptions, Prev/Next buttons,
+// then the region presets and All States
+func showStateSelectionKeyboard(prefs preferences.Preferences, chatID string, page int) (string, *telegram.InlineKeyboardMarkup) {
+	codes := preferences.StateCodes()
+	totalPages := (len(codes) + stateKeyboardPageSize - 1) / stateKeyboardPageSize
+	page = max(0, min(page, totalPages-1))
+
+	subscribed := make(map[string]bool)
+	for _, state := range prefs.GetStates(chatID) {
+		subscribed[state] = true
+	}
+
+	keyboard := &telegram.InlineKeyboardMarkup{}
+
+	start := page * stateKeyboardPageSize
+	end := min(start+stateKeyboardPageSize, len(codes))
+	pageCodes := codes[start:end]
+	for i := 0; i < len(pageCodes); i += 2 {
+		var row []telegram.InlineKeyboardButton
+		for _, code := range pageCodes[i:min(i+2, len(pageCodes))] {
+			text := fmt.Sprintf("%s (%s)", preferences.GetStateName(code), code)
+			if subscribed[code] {
+				text = "✅ " + text
+			}
+			row = append(row, telegram.InlineKeyboardButton{Text: text, CallbackData: "subscribe:" + code})
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+
+	var nav []telegram.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, telegram.InlineKeyboardButton{Text: "◀️ Prev", CallbackData: fmt.Sprintf("states:%d", page-1)})
+	}
+	if page < totalPages-1 {
+		nav = append(nav, telegram.InlineKeyboardButton{Text: "Next ▶️", CallbackData: fmt.Sprintf("states:%d", page+1)})
+	}
+	if len(nav) > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, nav)
+	}
+
+	// Region presets, two per row
+	regions := preferences.Regions()
+	for i := 0; i < len(regions); i += 2 {
+		var row []telegram.InlineKeyboardButton
+		for _, r := range regions[i:min(i+2, len(regions))] {
+			row = append(row, telegram.InlineKeyboardButton{
+				Text:         fmt.Sprintf("🗺️ %s", r.Name),
+				CallbackData: "subscribe:" + r.Key,
+			})
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+
+	allText := "🇺🇸 All States"
+	if subscribed[AllStatesCode] {
+		allText = "✅ " + allText
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []telegram.InlineKeyboardButton{
+		{Text: allText, CallbackData: fmt.Sprintf("subscribe:%s", AllStatesCode)},
+	})
+
+	text := fmt.Sprintf("📍 <b>Select a state or region to subscribe:</b> (page %d/%d)\n\n✅ marks your current subscriptions.\nOr type: /subscribe STATE or /subscribe REGION", page+1, totalPages)
+	return text, keyboard
+}
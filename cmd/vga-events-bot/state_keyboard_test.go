@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestStateSelectionKeyboardCoversAllStates(t *testing.T) {
+	prefs := preferences.NewPreferences()
+
+	found := make(map[string]bool)
+	for page := 0; ; page++ {
+		_, keyboard := showStateSelectionKeyboard(prefs, "123", page)
+		hasNext := false
+		for _, row := range keyboard.InlineKeyboard {
+			if len(row) > 2 {
+				t.Errorf("page %d: row has %d buttons, want at most 2", page, len(row))
+			}
+			for _, button := range row {
+				if code, ok := strings.CutPrefix(button.CallbackData, "subscribe:"); ok && len(code) == 2 {
+					found[code] = true
+				}
+				if button.CallbackData == fmt.Sprintf("states:%d", page+1) {
+					hasNext = true
+				}
+			}
+		}
+		if !hasNext {
+			break
+		}
+		if page > 10 {
+			t.Fatal("too many pages")
+		}
+	}
+
+	for _, code := range preferences.StateCodes() {
+		if !found[code] {
+			t.Errorf("state %s missing from keyboard", code)
+		}
+	}
+}
+
+func TestStateSelectionKeyboardPage(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "AL")
+
+	text, keyboard := showStateSelectionKeyboard(prefs, "123", 0)
+	if !strings.Contains(text, "page 1/5") {
+		t.Errorf("text = %q, want page 1/5", text)
+	}
+	first := keyboard.InlineKeyboard[0]
+	if first[0].Text != "✅ Alabama (AL)" || first[1].Text != "Alaska (AK)" {
+		t.Errorf("first row = %q, %q; want alphabetical with subscription checkmark", first[0].Text, first[1].Text)
+	}
+
+	// Out-of-range pages are clamped, and the last page has only Prev
+	text, keyboard = showStateSelectionKeyboard(prefs, "123", 99)
+	if !strings.Contains(text, "page 5/5") {
+		t.Errorf("text = %q, want page 5/5", text)
+	}
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == "states:5" {
+				t.Error("last page should not have a Next button")
+			}
+		}
+	}
+
+	// Regions and All States follow the states on every page
+	last := keyboard.InlineKeyboard[len(keyboard.InlineKeyboard)-1]
+	if last[0].CallbackData != "subscribe:"+AllStatesCode {
+		t.Errorf("last row = %q, want All States", last[0].CallbackData)
+	}
+	found := false
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == "subscribe:southwest" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("keyboard missing region presets")
+	}
+}
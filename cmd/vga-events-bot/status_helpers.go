@@ -1,6 +1,14 @@
 package main
 
-import "github.com/pfrederiksen/vga-events/internal/preferences"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// maxHistoryShown limits the status history line on /my-events cards
+const maxHistoryShown = 4
 
 // getStatusDisplay returns emoji and display text for a status
 func getStatusDisplay(status string) (emoji, text string) {
@@ -16,3 +24,32 @@ func getStatusDisplay(status string) (emoji, text string) {
 	}
 	return "", ""
 }
+
+// formatStatusHistory returns a compact one-line status history for an event,
+// e.g. "🕓 ⭐ Oct 1 → ✅ Oct 5". Returns "" if no changes were recorded.
+func formatStatusHistory(history []preferences.StatusChange) string {
+	if len(history) == 0 {
+		return ""
+	}
+
+	shown := history
+	prefix := ""
+	if len(shown) > maxHistoryShown {
+		shown = shown[len(shown)-maxHistoryShown:]
+		prefix = "… "
+	}
+
+	steps := make([]string, len(shown))
+	for i, change := range shown {
+		emoji, _ := getStatusDisplay(change.To)
+		switch {
+		case change.To == "":
+			emoji = "↩️"
+		case emoji == "":
+			emoji = change.To
+		}
+		steps[i] = fmt.Sprintf("%s %s", emoji, change.At.Format("Jan 2"))
+	}
+
+	return "🕓 <i>" + prefix + strings.Join(steps, " → ") + "</i>"
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestFormatStatusHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 12, 0, 0, 0, time.UTC) }
+
+	if got := formatStatusHistory(nil); got != "" {
+		t.Errorf("empty history should render nothing, got %q", got)
+	}
+
+	history := []preferences.StatusChange{
+		{To: preferences.EventStatusInterested, At: day(1)},
+		{From: preferences.EventStatusInterested, To: preferences.EventStatusRegistered, At: day(5)},
+	}
+	if got := formatStatusHistory(history); got != "🕓 <i>⭐ Oct 1 → ✅ Oct 5</i>" {
+		t.Errorf("formatStatusHistory() = %q", got)
+	}
+
+	// Long histories show the most recent changes with an ellipsis; cleared shows ↩️
+	long := []preferences.StatusChange{
+		{To: preferences.EventStatusMaybe, At: day(1)},
+		{To: preferences.EventStatusInterested, At: day(2)},
+		{To: preferences.EventStatusSkip, At: day(3)},
+		{To: "", At: day(4)},
+		{To: preferences.EventStatusInterested, At: day(6)},
+	}
+	got := formatStatusHistory(long)
+	if !strings.HasPrefix(got, "🕓 <i>… ⭐ Oct 2") || !strings.Contains(got, "↩️ Oct 4") || strings.Contains(got, "🤔") {
+		t.Errorf("formatStatusHistory(long) = %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestHandleSubscribeRegion(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "NV")
+
+	modified := false
+	response, _ := handleSubscribe(prefs, "123", "Southwest", &modified, "", true)
+	if !modified {
+		t.Error("subscribing to a region should modify preferences")
+	}
+	if !strings.Contains(response, "Subscribed to Southwest") || !strings.Contains(response, "Added: AZ, CA, UT, NM") {
+		t.Errorf("unexpected response: %q", response)
+	}
+	if states := prefs.GetStates("123"); len(states) != 5 {
+		t.Errorf("states = %v, want 5", states)
+	}
+
+	modified = false
+	response, _ = handleSubscribe(prefs, "123", "southwest", &modified, "", true)
+	if modified {
+		t.Error("resubscribing to a region should not modify preferences")
+	}
+	if !strings.Contains(response, "already subscribed to every state") {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
+
+func TestHandleSubscribeMajors(t *testing.T) {
+	prefs := preferences.NewPreferences()
+
+	modified := false
+	response, _ := handleSubscribe(prefs, "123", "Majors", &modified, "", true)
+	if !modified || !prefs.GetUser("123").Majors {
+		t.Fatalf("/subscribe majors should turn on majors: %q", response)
+	}
+	if list := handleList(prefs, "123"); !strings.Contains(list, "National &amp; majors") {
+		t.Errorf("/list should show the majors subscription:\n%s", list)
+	}
+
+	modified = false
+	if response := handleUnsubscribe(prefs, "123", "majors", &modified); !modified || prefs.GetUser("123").Majors {
+		t.Errorf("/unsubscribe majors should turn off majors: %q", response)
+	}
+}
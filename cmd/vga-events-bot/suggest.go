@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// maxCallbackDataLen is Telegram's limit on inline button callback data
+const maxCallbackDataLen = 64
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// suggestCommand returns the registry command closest to an unknown command name.
+// Short names tolerate one typo, longer names up to three. Returns "" if nothing is close.
+func suggestCommand(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		return ""
+	}
+
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	if maxDistance > 3 {
+		maxDistance = 3
+	}
+
+	best := ""
+	bestDistance := maxDistance + 1
+	for _, cmd := range commandRegistry {
+		if cmd.Unlisted {
+			continue
+		}
+		if d := levenshtein(name, cmd.Name); d < bestDistance {
+			best = cmd.Name
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+// handleUnknownCommand replies to an unrecognized command, offering a one-tap
+// "Did you mean" button when a registry command is close enough
+func handleUnknownCommand(command string, parts []string, chatID, botToken string, dryRun bool) (string, []*event.Event) {
+	suggestion := ""
+	if strings.HasPrefix(command, "/") {
+		suggestion = suggestCommand(command)
+	}
+	if suggestion == "" {
+		return fmt.Sprintf("Unknown command: %s\n\nUse /help to see available commands.", command), nil
+	}
+
+	suggested := "/" + suggestion
+	if len(parts) > 1 {
+		suggested += " " + strings.Join(parts[1:], " ")
+	}
+
+	text := fmt.Sprintf("❓ Unknown command: %s\n\nDid you mean <b>/%s</b>?", html.EscapeString(command), suggestion)
+
+	// Keep the original arguments if they fit in the callback data
+	callbackData := "run:" + strings.TrimPrefix(suggested, "/")
+	if len(callbackData) > maxCallbackDataLen {
+		callbackData = "run:" + suggestion
+		suggested = "/" + suggestion
+	}
+
+	keyboard := &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: fmt.Sprintf("▶️ Run %s", suggested), CallbackData: callbackData}},
+			{{Text: "📖 All commands", CallbackData: "menu:help"}},
+		},
+	}
+
+	if !dryRun {
+		client, err := newSender(botToken, chatID)
+		if err == nil {
+			if err := client.SendMessageWithKeyboard(botCtx, text, keyboard); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending suggestion: %v\n", err)
+			}
+			return "", nil // Already sent via keyboard
+		}
+	}
+
+	return text, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"subscribe", "subscribe", 0},
+		{"subscrbe", "subscribe", 1},
+		{"evnets", "events", 2},
+		{"kitten", "sitting", 3},
+		{"", "help", 4},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/subscrbe", "subscribe"},
+		{"/evnts", "events"},
+		{"/my-evnets", "my-events"},
+		{"/remindrs", "reminders"},
+		{"/hlp", "help"},
+		{"/xyzzy", ""},
+		{"/", ""},
+	}
+
+	for _, tt := range tests {
+		if got := suggestCommand(tt.input); got != tt.want {
+			t.Errorf("suggestCommand(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandleUnknownCommand(t *testing.T) {
+	modified := false
+
+	response, _ := processCommand(nil, "12345", "/subscrbe NV", &modified, "", true)
+	if !strings.Contains(response, "Did you mean <b>/subscribe</b>?") {
+		t.Errorf("Expected subscribe suggestion, got %q", response)
+	}
+
+	response, _ = processCommand(nil, "12345", "/xyzzy", &modified, "", true)
+	if strings.Contains(response, "Did you mean") {
+		t.Errorf("Expected no suggestion for /xyzzy, got %q", response)
+	}
+}
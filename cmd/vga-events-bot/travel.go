@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const travelUsage = "Usage: /travel &lt;STATE&gt; &lt;dates&gt;\n\nExamples:\n/travel NV Apr 10-20\n/travel AZ Apr 28-May 3\n/travel cancel NV"
+
+// parseTravelWindow parses a trip's dates, e.g. "Apr 10-20", "Apr 28-May 3", or "Apr 10".
+// The year is the next one in which the trip hasn't ended yet.
+func parseTravelWindow(text string, now time.Time) (time.Time, time.Time, error) {
+	text = strings.NewReplacer("–", "-", "—", "-", " to ", "-").Replace(strings.TrimSpace(text))
+	startText, endText, isRange := strings.Cut(text, "-")
+
+	start, err := parseMonthDay(strings.TrimSpace(startText))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end := start
+	if isRange {
+		endText = strings.TrimSpace(endText)
+		if day, err := strconv.Atoi(endText); err == nil {
+			// "Apr 10-20": same month
+			end = time.Date(0, start.Month(), day, 0, 0, 0, 0, time.UTC)
+			if end.Month() != start.Month() {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid day %q", endText)
+			}
+		} else if end, err = parseMonthDay(endText); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start = start.AddDate(today.Year(), 0, 0)
+	end = end.AddDate(today.Year(), 0, 0)
+	if end.Before(start) {
+		// "Dec 28-Jan 3" crosses into the next year
+		end = end.AddDate(1, 0, 0)
+	}
+	if end.Before(today) {
+		start, end = start.AddDate(1, 0, 0), end.AddDate(1, 0, 0)
+	}
+
+	if days := int(end.Sub(start).Hours()/24) + 1; days > preferences.MaxTravelDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("trips can be at most %d days", preferences.MaxTravelDays)
+	}
+	return start, end, nil
+}
+
+// parseMonthDay parses "Apr 10" or "April 10" in year 0
+func parseMonthDay(text string) (time.Time, error) {
+	for _, layout := range []string{"Jan 2", "January 2", "1/2"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return time.Date(0, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("couldn't read the date %q", text)
+}
+
+// handleTravel adds, cancels, or lists trips
+func handleTravel(prefs preferences.Preferences, chatID string, args []string, modified *bool) string {
+	if len(args) == 0 {
+		return formatTravelList(prefs.GetUser(chatID).Travel)
+	}
+
+	if strings.EqualFold(args[0], "cancel") {
+		if len(args) < 2 {
+			return "❌ Please specify a state.\n\nUsage: /travel cancel &lt;STATE&gt;"
+		}
+		state := strings.ToUpper(args[1])
+		if prefs.GetUser(chatID).RemoveTravel(state) == 0 {
+			return fmt.Sprintf("ℹ️ You have no trip to %s planned.\n\nUse /travel to see your trips.", html.EscapeString(state))
+		}
+		*modified = true
+		return fmt.Sprintf("✅ Canceled your trip to %s.", preferences.GetStateName(state))
+	}
+
+	state := strings.ToUpper(args[0])
+	if state == AllStatesCode || !preferences.IsValidState(state) {
+		return fmt.Sprintf("❌ Invalid state code: %s\n\n%s", html.EscapeString(args[0]), travelUsage)
+	}
+	if len(args) < 2 {
+		return "❌ Please specify your travel dates.\n\n" + travelUsage
+	}
+
+	start, end, err := parseTravelWindow(strings.Join(args[1:], " "), clk.Now())
+	if err != nil {
+		return fmt.Sprintf("❌ %s.\n\n%s", html.EscapeString(capitalize(err.Error())), travelUsage)
+	}
+
+	trip := preferences.TravelSubscription{State: state, Start: start, End: end}
+	if !prefs.AddTravel(chatID, trip) {
+		return fmt.Sprintf("❌ You can plan up to %d trips.\n\nUse /travel cancel &lt;STATE&gt; to remove one.", preferences.MaxTravelSubscriptions)
+	}
+	*modified = true
+
+	response := fmt.Sprintf("✈️ <b>Trip to %s planned!</b>\n\n", preferences.GetStateName(state))
+	response += fmt.Sprintf("You'll be notified about new events in %s on %s.\n\n", state, preferences.FormatDateRange(start, end))
+	if prefs.HasState(chatID, state) {
+		response += "You're already subscribed to all of " + state + ", so this trip only matters if you unsubscribe."
+	} else {
+		response += "The trip is removed automatically once it's over."
+	}
+	return response
+}
+
+// formatTravelList lists planned trips
+func formatTravelList(trips []preferences.TravelSubscription) string {
+	if len(trips) == 0 {
+		return "✈️ <b>Travel</b>\n\nYou have no trips planned.\n\n" + travelUsage
+	}
+
+	var msg strings.Builder
+	msg.WriteString("✈️ <b>Your Trips</b>\n\n")
+	now := clk.Now()
+	for _, t := range trips {
+		msg.WriteString(fmt.Sprintf("• %s (%s)", preferences.GetStateName(t.State), t.String()))
+		if t.Expired(now) {
+			msg.WriteString(" — ended")
+		}
+		msg.WriteString("\n")
+	}
+	msg.WriteString("\nUse /travel cancel &lt;STATE&gt; to cancel a trip.")
+	return msg.String()
+}
+
+// capitalize upper-cases the first letter of an error message for display
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestParseTravelWindow(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text       string
+		start, end string
+		wantErr    bool
+	}{
+		{text: "Apr 10-20", start: "2026-04-10", end: "2026-04-20"},
+		{text: "April 10 - 20", start: "2026-04-10", end: "2026-04-20"},
+		{text: "Apr 28-May 3", start: "2026-04-28", end: "2026-05-03"},
+		{text: "Apr 10", start: "2026-04-10", end: "2026-04-10"},
+		{text: "4/10-4/12", start: "2026-04-10", end: "2026-04-12"},
+		{text: "Mar 10-16", start: "2026-03-10", end: "2026-03-16"}, // Still in progress
+		{text: "Feb 1-5", start: "2027-02-01", end: "2027-02-05"},   // Already over this year
+		{text: "Dec 28-Jan 3", start: "2026-12-28", end: "2027-01-03"},
+		{text: "Apr 1-Jul 1", wantErr: true},
+		{text: "Apr 10-31", wantErr: true},
+		{text: "next week", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := parseTravelWindow(tt.text, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTravelWindow(%q) expected error, got %v-%v", tt.text, start, end)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTravelWindow(%q) error: %v", tt.text, err)
+			continue
+		}
+		if got := start.Format("2006-01-02"); got != tt.start {
+			t.Errorf("parseTravelWindow(%q) start = %s, want %s", tt.text, got, tt.start)
+		}
+		if got := end.Format("2006-01-02"); got != tt.end {
+			t.Errorf("parseTravelWindow(%q) end = %s, want %s", tt.text, got, tt.end)
+		}
+	}
+}
+
+func TestHandleTravel(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	modified := false
+
+	response := handleTravel(prefs, "123", nil, &modified)
+	if modified || !strings.Contains(response, "no trips planned") {
+		t.Errorf("empty list: %q", response)
+	}
+
+	response = handleTravel(prefs, "123", []string{"nv", "Apr", "10-20"}, &modified)
+	if !modified || !strings.Contains(response, "Trip to Nevada planned") {
+		t.Errorf("unexpected response: %q", response)
+	}
+	if trips := prefs.GetUser("123").Travel; len(trips) != 1 || trips[0].State != "NV" {
+		t.Fatalf("expected one NV trip, got %+v", trips)
+	}
+
+	if list := handleList(prefs, "123"); !strings.Contains(list, "Travel") || !strings.Contains(list, "NV, Apr 10-20") {
+		t.Errorf("/list should show trips, got %q", list)
+	}
+
+	modified = false
+	for _, args := range [][]string{{"XX", "Apr", "10"}, {"ALL", "Apr", "10"}, {"NV"}, {"NV", "someday"}} {
+		if response := handleTravel(prefs, "123", args, &modified); modified || !strings.HasPrefix(response, "❌") {
+			t.Errorf("handleTravel(%v) should fail, got %q", args, response)
+		}
+	}
+
+	response = handleTravel(prefs, "123", []string{"cancel", "AZ"}, &modified)
+	if modified || !strings.Contains(response, "no trip to AZ") {
+		t.Errorf("canceling a missing trip: %q", response)
+	}
+	response = handleTravel(prefs, "123", []string{"cancel", "nv"}, &modified)
+	if !modified || !strings.Contains(response, "Canceled your trip to Nevada") {
+		t.Errorf("canceling a trip: %q", response)
+	}
+	if len(prefs.GetUser("123").Travel) != 0 {
+		t.Error("trip should be removed")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+	"github.com/pfrederiksen/vga-events/internal/trends"
+)
+
+// errNoHistory is returned when the bot runs without the snapshot directory
+var errNoHistory = errors.New("no snapshot history (--data-dir isn't set)")
+
+// loadTrendsInput gathers the snapshot history and the delivery and click logs from
+// --data-dir, and counts the users tracking each event
+func loadTrendsInput(prefs preferences.Preferences) (trends.Input, error) {
+	var in trends.Input
+	if snapshotStore == nil {
+		return in, errNoHistory
+	}
+
+	var err error
+	if in.History, err = snapshotStore.LoadHistory("all"); err != nil {
+		return in, err
+	}
+	if in.Deliveries, err = snapshotStore.LoadDeliveries(); err != nil {
+		return in, err
+	}
+	if in.Clicks, err = snapshotStore.LoadClicks(); err != nil {
+		return in, err
+	}
+
+	in.Tracking = make(map[string]int)
+	for _, user := range prefs {
+		for eventID := range user.EventStatuses {
+			in.Tracking[eventID]++
+		}
+	}
+	return in, nil
+}
+
+// handleTrends summarizes the last 30 days of VGA events
+func handleTrends(prefs preferences.Preferences) string {
+	in, err := loadTrendsInput(prefs)
+	if errors.Is(err, errNoHistory) {
+		return "📈 Trends aren't available right now: the bot has no event history to work from."
+	}
+	if err != nil {
+		reportError(err, "")
+		return "❌ Error loading event history. Please try again later."
+	}
+	return telegram.FormatTrends(trends.Build(in, clk.Now(), trends.DefaultDays))
+}
+
+// postTrends sends the trends report to a chat or channel; the monthly workflow posts
+// it to the announcements channel
+func postTrends(ctx context.Context, prefs preferences.Preferences, botToken, chatID string, dryRun bool) {
+	in, err := loadTrendsInput(prefs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	msg := telegram.FormatTrends(trends.Build(in, clk.Now(), trends.DefaultDays))
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would post trends to %s:\n%s\n", chatID, msg)
+		return
+	}
+
+	client, err := newSender(botToken, chatID)
+	if err == nil {
+		err = client.SendMessage(ctx, msg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error posting trends to %s: %v\n", chatID, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Posted trends to %s\n", chatID)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func TestHandleTrends(t *testing.T) {
+	prefs := preferences.NewPreferences()
+	prefs.GetUser("111").SetEventStatus("evt1", preferences.EventStatusInterested)
+	prefs.GetUser("222").SetEventStatus("evt1", preferences.EventStatusRegistered)
+
+	old := snapshotStore
+	defer func() { snapshotStore = old }()
+
+	snapshotStore = nil
+	if got := handleTrends(prefs); !strings.Contains(got, "aren't available") {
+		t.Errorf("without --data-dir: %s", got)
+	}
+
+	if err := initSnapshotStore(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	delta := &storage.SnapshotDelta{
+		At:    time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339),
+		Added: []*event.Event{{ID: "evt1", State: "NV", Title: "Shadow Creek"}},
+	}
+	if err := snapshotStore.AppendHistory("all", delta); err != nil {
+		t.Fatal(err)
+	}
+
+	got := handleTrends(prefs)
+	for _, want := range []string{"<b>1</b> new event", "📍 Nevada — 1", "• Shadow Creek — 2 users"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("handleTrends() missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/social"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// runDiff is the whole of one run's diff document
+type runDiff struct {
+	New       []*event.Event
+	Removed   []*event.Event
+	Changes   []*event.EventChange
+	NewStates []string // states listing their first ever events
+}
+
+// readDiff reads the new, removed, and changed events from a diff document
+func readDiff(r io.Reader) (*runDiff, error) {
+	d := &runDiff{}
+	err := event.StreamDiff(r, event.DiffStream{
+		OnNew: func(evt *event.Event) error {
+			d.New = append(d.New, evt)
+			return nil
+		},
+		OnRemoved: func(evt *event.Event) error {
+			d.Removed = append(d.Removed, evt)
+			return nil
+		},
+		OnChanged: func(change *event.EventChange) error {
+			d.Changes = append(d.Changes, change)
+			return nil
+		},
+		OnNewState: func(state string) error {
+			d.NewStates = append(d.NewStates, state)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	return d, nil
+}
+
+// diffCounts tallies a diff by state. Changed events are looked up among the run's new
+// events, then in the --data-dir snapshot; without either they only count in the totals.
+func diffCounts(d *runDiff, snapshot *event.Snapshot) map[string]event.DiffCounts {
+	states := make(map[string]string, len(d.New))
+	for _, evt := range d.New {
+		states[evt.ID] = evt.State
+	}
+	return event.CountByState(d.New, d.Removed, d.Changes, func(id string) string {
+		if state, ok := states[id]; ok {
+			return state
+		}
+		if snapshot != nil {
+			if evt, ok := snapshot.Events[id]; ok {
+				return evt.State
+			}
+		}
+		return ""
+	})
+}
+
+// loadDiff reads the run's diff from --events-file, or stdin
+func loadDiff() (*runDiff, error) {
+	reader := io.Reader(os.Stdin)
+	if *eventsFile != "" {
+		f, err := storage.OpenFile(*eventsFile) // Plain or gzip-compressed JSON
+		if err != nil {
+			return nil, fmt.Errorf("opening events file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+	return readDiff(reader)
+}
+
+// runAnnounce posts one summary of the run's diff to the public channel and/or Twitter
+func runAnnounce(ctx context.Context) error {
+	if *announceChannel == "" && *twitterToken == "" && !*dryRun {
+		return errors.New("--announce needs --announce-channel or --twitter-token")
+	}
+
+	d, err := loadDiff()
+	if err != nil {
+		return err
+	}
+
+	var snapshot *event.Snapshot
+	if *dataDir != "" && len(d.Changes) > 0 {
+		if store, err := storage.New(*dataDir); err == nil {
+			if snapshot, err = store.LoadSnapshot("all"); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: changed events won't be grouped by state: %v\n", err)
+			}
+		}
+	}
+
+	counts := diffCounts(d, snapshot)
+	if len(counts) == 0 {
+		fmt.Println("Nothing to announce")
+		return nil
+	}
+
+	msg := telegram.FormatDiffSummary(counts)
+	config := social.NewConfig()
+	if *socialConfig != "" {
+		if config, err = social.LoadConfig(*socialConfig); err != nil {
+			return err
+		}
+	}
+	post, err := config.FormatSummaryPost(social.ChannelTwitter, counts)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("--- Channel Announcement (%s) ---\n%s\n\n--- Twitter Post ---\n%s\n", *announceChannel, msg, post)
+		for _, state := range d.NewStates {
+			fmt.Printf("\n--- New State Announcement (%s) ---\n%s\n", *announceChannel, telegram.FormatNewState(state, d.New))
+		}
+		return nil
+	}
+
+	var failed error
+	if *announceChannel != "" {
+		client, err := telegram.NewClient(*botToken, *announceChannel)
+		if err == nil {
+			err = client.SendMessage(ctx, msg)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error posting to %s: %v\n", *announceChannel, err)
+			failed = err
+		} else {
+			fmt.Printf("Posted run summary to %s\n", *announceChannel)
+		}
+
+		// A state's first events get their own post
+		for _, state := range d.NewStates {
+			if failed != nil {
+				break
+			}
+			if err := client.SendMessage(ctx, telegram.FormatNewState(state, d.New)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error posting %s's first events to %s: %v\n", state, *announceChannel, err)
+				failed = err
+			} else {
+				fmt.Printf("Posted %s's first events to %s\n", state, *announceChannel)
+			}
+		}
+	}
+	if *twitterToken != "" {
+		id, err := social.NewTwitter(*twitterToken).Post(ctx, post)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error posting to Twitter: %v\n", err)
+			failed = err
+		} else {
+			fmt.Printf("Posted run summary to Twitter (%s)\n", id)
+		}
+	}
+	if failed != nil {
+		return errors.New("some announcements failed")
+	}
+	return nil
+}
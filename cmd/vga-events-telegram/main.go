@@ -1,36 +1,349 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
+	"github.com/pfrederiksen/vga-events/internal/clock"
 	"github.com/pfrederiksen/vga-events/internal/course"
+	"github.com/pfrederiksen/vga-events/internal/errreport"
+	"github.com/pfrederiksen/vga-events/internal/errs"
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/eventquery"
+	"github.com/pfrederiksen/vga-events/internal/experiment"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/hints"
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/plugin"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/teetime"
 	"github.com/pfrederiksen/vga-events/internal/telegram"
 )
 
 var (
-	botToken            = flag.String("bot-token", os.Getenv("TELEGRAM_BOT_TOKEN"), "Telegram bot token (or env: TELEGRAM_BOT_TOKEN)")
-	chatID              = flag.String("chat-id", os.Getenv("TELEGRAM_CHAT_ID"), "Telegram chat ID (or env: TELEGRAM_CHAT_ID)")
-	golfCourseAPIKey    = flag.String("golf-api-key", os.Getenv("GOLF_COURSE_API_KEY"), "Golf Course API key (or env: GOLF_COURSE_API_KEY)")
-	eventsFile          = flag.String("events-file", "", "Path to events JSON file (or read from stdin)")
-	dryRun              = flag.Bool("dry-run", false, "Print messages without sending")
-	maxMessages         = flag.Int("max-messages", 10, "Maximum number of messages to send")
-	stateFilter         = flag.String("state", "", "Only send messages for this state")
-	hidePast            = flag.Bool("hide-past", true, "Filter out past events (default: true)")
-	daysAhead           = flag.Int("days-ahead", 0, "Only show events within N days (0 = disabled)")
-	checkReminders      = flag.Bool("check-reminders", false, "Check if event matches reminder days (exits 0 if match, 1 if no match)")
-	reminderDays        = flag.Int("reminder-days", 0, "Number of days before event to send reminder (used with --check-reminders)")
-	removalNotification = flag.Bool("removal-notification", false, "Send removal notifications (reads from removed_events field)")
-	changeNotification  = flag.Bool("change-notification", false, "Send change notifications (reads from changed_events field)")
-	eventStatus         = flag.String("event-status", "", "Event status for removal/change notification (registered/interested/maybe)")
-	eventNote           = flag.String("event-note", "", "User's note for the event (for removal/change notifications)")
+	botToken             = flag.String("bot-token", os.Getenv("TELEGRAM_BOT_TOKEN"), "Telegram bot token (or env: TELEGRAM_BOT_TOKEN)")
+	chatID               = flag.String("chat-id", os.Getenv("TELEGRAM_CHAT_ID"), "Telegram chat ID (or env: TELEGRAM_CHAT_ID)")
+	golfCourseAPIKey     = flag.String("golf-api-key", os.Getenv("GOLF_COURSE_API_KEY"), "Golf Course API key (or env: GOLF_COURSE_API_KEY)")
+	eventsFile           = flag.String("events-file", "", "Path to events JSON file (or read from stdin)")
+	dryRun               = flag.Bool("dry-run", false, "Print messages without sending")
+	maxMessages          = flag.Int("max-messages", 10, "Maximum events to send one by one; new events past the cap are summarized in a single \"and N more\" message")
+	stateFilter          = flag.String("state", "", "Only send messages for this state")
+	hidePast             = flag.Bool("hide-past", true, "Filter out past events (default: true)")
+	daysAhead            = flag.Int("days-ahead", 0, "Only show events within N days (0 = disabled)")
+	checkReminders       = flag.Bool("check-reminders", false, "Check if event matches reminder days (exits 0 if match, 1 if no match)")
+	reminderDays         = flag.Int("reminder-days", 0, "Number of days before event to send reminder (used with --check-reminders)")
+	checkDeadlines       = flag.Bool("check-deadlines", false, "Check if event's registration deadline is --deadline-days away (exits 0 if match, 1 if no match)")
+	deadlineDays         = flag.Int("deadline-days", 2, "Days before the registration deadline to send a deadline reminder, 2 = 48h (used with --check-deadlines)")
+	checkCapacity        = flag.Bool("check-capacity", false, "Check if event's field is at least --capacity-threshold percent taken (exits 0 if match, 1 if no match)")
+	capacityThreshold    = flag.Int("capacity-threshold", 90, "Percent of the field taken before an almost-full alert is sent (used with --check-capacity)")
+	removalNotification  = flag.Bool("removal-notification", false, "Send removal notifications (reads from removed_events field)")
+	restoredNotification = flag.Bool("restored-notification", false, "Send \"event restored\" messages (reads from restored_events field) for events this chat was sent a removal notification for, per the --data-dir ledger")
+	newStateNotification = flag.Bool("new-state-notification", false, "Announce each state listing its first ever events (reads from new_states field), once per chat per the --data-dir ledger, then exit")
+	changeNotification   = flag.Bool("change-notification", false, "Send change notifications (reads from changed_events field)")
+	eventStatus          = flag.String("event-status", "", "Event status for removal/change notification (registered/interested/maybe)")
+	eventNote            = flag.String("event-note", "", "User's note for the event (for removal/change notifications)")
+	teeTimeURL           = flag.String("tee-time-url", os.Getenv("TEE_TIME_SEARCH_URL"), "Tee-time search URL template with {course}, {city}, {state}, {date} (or env: TEE_TIME_SEARCH_URL)")
+	teeTimeAPIURL        = flag.String("tee-time-api-url", os.Getenv("TEE_TIME_API_URL"), "Tee-time availability API endpoint (or env: TEE_TIME_API_URL)")
+	teeTimeAPIKey        = flag.String("tee-time-api-key", os.Getenv("TEE_TIME_API_KEY"), "Tee-time availability API key (or env: TEE_TIME_API_KEY)")
+	errorDSN             = flag.String("error-dsn", os.Getenv("ERROR_REPORT_DSN"), "Sentry DSN or rollbar://token to report send failures to (or env: ERROR_REPORT_DSN)")
+	chatHashSalt         = flag.String("chat-hash-salt", os.Getenv("ERROR_REPORT_SALT"), "Secret chat IDs are hashed with in error reports and the delivery ledger; use the same one everywhere (or env: ERROR_REPORT_SALT)")
+	dataDir              = flag.String("data-dir", os.Getenv("VGA_EVENTS_DATA_DIR"), "Directory to append the delivery log to; disabled when empty (or env: VGA_EVENTS_DATA_DIR)")
+	runID                = flag.String("run-id", os.Getenv("GITHUB_RUN_ID"), "Run ID recorded with each delivery (or env: GITHUB_RUN_ID)")
+	confirmDeliveries    = flag.Bool("confirm-deliveries", false, "Confirm every sent notification in the --data-dir ledger once seen lists are saved, then exit")
+	runReport            = flag.Bool("run-report", false, "Send --chat-id a summary of run --run-id (events parsed, diff, parse warnings, API errors, send failures, runtime, anomalies) from the --data-dir logs, then exit")
+	linkUTM              = flag.Bool("utm", os.Getenv("VGA_LINK_UTM") == "true", "Add utm_source, utm_medium, and utm_campaign to outbound links (or env: VGA_LINK_UTM=true)")
+	linkMedium           = flag.String("utm-medium", links.DefaultMedium, "utm_medium for tagged links")
+	shortenerURL         = flag.String("shortener-url", os.Getenv("VGA_SHORTENER_URL"), "Self-hosted link shortener endpoint (or env: VGA_SHORTENER_URL)")
+	shortenerToken       = flag.String("shortener-token", os.Getenv("VGA_SHORTENER_TOKEN"), "Bearer token for the link shortener (or env: VGA_SHORTENER_TOKEN)")
+	clickURL             = flag.String("click-url", os.Getenv("VGA_CLICK_URL"), "Base URL of vga-events serve-api; registration links go through its /r/ redirect to count clicks (or env: VGA_CLICK_URL)")
+	prefsFile            = flag.String("prefs-file", "", "Preferences JSON file; new-event cards get hints for --chat-id (conflicts with tracked events, distance from home), and removal notifications mark the cards the bot tracks cancelled")
+	experimentName       = flag.String("experiment", os.Getenv("VGA_EXPERIMENT"), "A/B experiment for new-event cards, e.g. new-event-format; users are split between its variants (or env: VGA_EXPERIMENT)")
+	flagsFile            = flag.String("flags-file", os.Getenv("VGA_FLAGS_FILE"), "JSON file of feature flags; VGA_FLAGS overrides it, e.g. enable_geocoding=off (or env: VGA_FLAGS_FILE)")
+	announce             = flag.Bool("announce", false, "Post one summary of the diff (new, changed, removed by state) to --announce-channel and/or Twitter, then exit")
+	announceChannel      = flag.String("announce-channel", os.Getenv("TELEGRAM_ANNOUNCE_CHANNEL"), "Public Telegram channel for --announce, e.g. @vgaevents (or env: TELEGRAM_ANNOUNCE_CHANNEL)")
+	twitterToken         = flag.String("twitter-token", os.Getenv("TWITTER_ACCESS_TOKEN"), "OAuth 2.0 user access token with tweet.write, to also post --announce summaries to Twitter (or env: TWITTER_ACCESS_TOKEN)")
+	socialConfig         = flag.String("social-config", os.Getenv("VGA_SOCIAL_CONFIG"), "Social post config JSON; its default hashtags are added to Twitter summaries (or env: VGA_SOCIAL_CONFIG)")
+	courseAliasesFile    = flag.String("course-aliases", os.Getenv("VGA_COURSE_ALIASES_FILE"), "Course alias JSON file (course_aliases.json from the preferences Gist), consulted before the Golf Course API's fuzzy match (or env: VGA_COURSE_ALIASES_FILE)")
+	pluginNames          = flag.String("plugins", os.Getenv("VGA_PLUGINS"), "Comma-separated plugins to run on each notification, e.g. directions (or env: VGA_PLUGINS)")
+	versionFlag          = flag.Bool("version", false, "Print version information and exit")
 )
 
+// clk is the clock reminders and time filters are worked out from
+var clk clock.Clock = clock.System
+
+// errReporter sends failures to Sentry/Rollbar (nil, and a no-op, unless --error-dsn is set)
+var errReporter *errreport.Reporter
+
+// activeExperiment is the A/B experiment new-event cards are part of (nil unless
+// --experiment is set), and experimentVariant this chat's variant in it
+var (
+	activeExperiment  *experiment.Experiment
+	experimentVariant string
+)
+
+// features are the feature flags from --flags-file and VGA_FLAGS
+var features *flags.Set
+
+// hintUser and hintEvents are the preferences and known events new-event card hints are
+// worked out from (hintUser is nil unless --prefs-file is set)
+var (
+	hintUser   *preferences.UserPreferences
+	hintEvents map[string]*event.Event
+)
+
+// readPreferences reads the bot's preferences from --prefs-file
+func readPreferences() (preferences.Preferences, error) {
+	data, err := storage.ReadFile(*prefsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading preferences: %w", err)
+	}
+	return preferences.FromJSON(data)
+}
+
+// loadHints loads the chat's preferences from --prefs-file, and the events they track
+// from the --data-dir snapshot plus the events being sent
+func loadHints(events []*event.Event) error {
+	prefs, err := readPreferences()
+	if err != nil {
+		return err
+	}
+	// Linked chats share statuses, so a household member's event can conflict too
+	prefs.ShareHouseholdData()
+	hintUser = prefs[*chatID]
+
+	hintEvents = make(map[string]*event.Event)
+	if *dataDir != "" {
+		if store, err := storage.New(*dataDir); err == nil {
+			if snapshot, err := store.LoadSnapshot("all"); err == nil {
+				hintEvents = snapshot.Listed()
+			}
+		}
+	}
+	for _, evt := range events {
+		hintEvents[evt.ID] = evt
+	}
+	return nil
+}
+
+// withHints adds this chat's hints for evt to a new-event card
+func withHints(text string, evt *event.Event) string {
+	user := hintUser
+	if user != nil && user.Home != nil && !features.EnabledFor(flags.Geocoding, *chatID) {
+		// Without geocoding, only the conflict hints are shown
+		withoutHome := *user
+		withoutHome.Home = nil
+		user = &withoutHome
+	}
+	return telegram.AddHints(text, hints.For(user, evt, hintEvents))
+}
+
+// deliveryLog records each send attempt (nil unless --data-dir is set)
+var deliveryLog *storage.Storage
+
+// recordDelivery appends the outcome of sending one notification to the delivery log
+func recordDelivery(kind, eventID string, err error) {
+	if deliveryLog == nil {
+		return
+	}
+	d := &storage.Delivery{
+		At:      time.Now().UTC().Format(time.RFC3339),
+		RunID:   *runID,
+		User:    errreport.HashChatID(*chatID),
+		Channel: "telegram",
+		EventID: eventID,
+		Type:    kind,
+		Result:  storage.DeliverySent,
+	}
+	if activeExperiment != nil && kind == "new" {
+		d.Experiment = activeExperiment.Name
+		d.Variant = experimentVariant
+	}
+	if err != nil {
+		d.Result = storage.DeliveryFailed
+		if errs.IsPermanent(err) {
+			d.Result = storage.DeliveryUnreachable
+		}
+		d.Error = err.Error()
+	}
+	if err := deliveryLog.AppendDelivery(d); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: recording delivery: %v\n", err)
+	}
+}
+
+// recordAPIError appends a failed API call to the run log for the run report
+func recordAPIError(api string, err error) {
+	if deliveryLog == nil {
+		return
+	}
+	r := &storage.RunRecord{
+		At:     time.Now().UTC().Format(time.RFC3339),
+		RunID:  *runID,
+		Source: storage.RunSourceNotifier,
+		API:    api,
+		Error:  err.Error(),
+	}
+	if err := deliveryLog.AppendRun(r); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: recording API error: %v\n", err)
+	}
+}
+
+// ledger tracks each notification through intent → sent → confirmed so a run that
+// crashed between sending and saving seen lists doesn't send duplicates (nil unless
+// --data-dir is set)
+var ledger *storage.Ledger
+
+// beginDelivery checks the ledger before sending a notification and records the intent
+// to send it. It returns false if the notification was already sent by an earlier run;
+// the caller skips it but still treats it as delivered, so it gets marked seen.
+func beginDelivery(eventID, kind string) bool {
+	if ledger == nil {
+		return true
+	}
+	user := errreport.HashChatID(*chatID)
+	switch ledger.Phase(user, eventID, kind) {
+	case storage.LedgerSent, storage.LedgerConfirmed:
+		fmt.Printf("Skipping %s notification for %s: already sent\n", kind, eventID)
+		return false
+	case storage.LedgerIntent:
+		// Telegram has no idempotency key, so a send cut off mid-request is sent again
+		fmt.Fprintf(os.Stderr, "Warning: previous %s notification for %s was interrupted; sending again\n", kind, eventID)
+	}
+	if err := ledger.Record(user, eventID, kind, storage.LedgerIntent); err != nil {
+		fmt.Fprintf(os.Stderr, "Error recording delivery intent: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// finishDelivery records that a notification was accepted by Telegram
+func finishDelivery(eventID, kind string) {
+	recordDelivery(notificationKind(), eventID, nil)
+	if ledger == nil {
+		return
+	}
+	if err := ledger.Record(errreport.HashChatID(*chatID), eventID, kind, storage.LedgerSent); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: recording delivery in ledger: %v\n", err)
+	}
+}
+
+// cancelCard edits the latest card the bot sent this chat for a removed event to mark
+// it cancelled, without its status buttons. Reports whether there was a card and it
+// was edited.
+func cancelCard(ctx context.Context, client *telegram.Client, prefs preferences.Preferences, evt *event.Event) bool {
+	user := prefs[*chatID]
+	if user == nil {
+		return false
+	}
+	messageID := user.LastEventMessage(evt.ID)
+	if messageID == 0 {
+		return false
+	}
+	text := telegram.FormatCancelledCard(evt, user.GetEventStatus(evt.ID), user.GetEventNote(evt.ID), *chatID, prefs)
+	if err := client.EditMessageText(ctx, *chatID, messageID, text, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: marking card %d cancelled for %s: %v\n", messageID, evt.ID, err)
+		return false
+	}
+	return true
+}
+
+// receivedRemoval keeps the events this chat was sent a removal notification for, so
+// only they hear that an event was restored. Without a ledger there's no record to go
+// by, and every event is kept.
+func receivedRemoval(events []*event.Event) []*event.Event {
+	if ledger == nil {
+		return events
+	}
+	user := errreport.HashChatID(*chatID)
+	kept := make([]*event.Event, 0, len(events))
+	for _, evt := range events {
+		switch ledger.Phase(user, evt.ID, "removed") {
+		case storage.LedgerSent, storage.LedgerConfirmed:
+			kept = append(kept, evt)
+		}
+	}
+	return kept
+}
+
+// runConfirmDeliveries confirms the notifications sent since the last confirmation
+func runConfirmDeliveries() {
+	if ledger == nil {
+		fmt.Fprintf(os.Stderr, "Error: --confirm-deliveries requires --data-dir\n")
+		os.Exit(1)
+	}
+	for _, e := range ledger.Unconfirmed() {
+		if e.Phase == storage.LedgerIntent {
+			fmt.Fprintf(os.Stderr, "Warning: %s notification for %s never completed and will be sent again\n", e.Type, e.EventID)
+		}
+	}
+	confirmed, err := ledger.ConfirmSent()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error confirming deliveries: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Confirmed %d delivered notification(s)\n", confirmed)
+}
+
+// ledgerKind returns the ledger type for the current mode. Reminders are keyed by how
+// many days ahead they're sent, so each reminder for an event is tracked separately.
+func ledgerKind() string {
+	if *checkReminders {
+		return fmt.Sprintf("reminder-%d", *reminderDays)
+	}
+	if *checkDeadlines {
+		return fmt.Sprintf("deadline-%d", *deadlineDays)
+	}
+	return notificationKind()
+}
+
+// notificationKind returns the delivery log type for the current mode
+func notificationKind() string {
+	switch {
+	case *changeNotification:
+		return "changed"
+	case *removalNotification:
+		return "removed"
+	case *restoredNotification:
+		return "restored"
+	case *newStateNotification:
+		return "new-state"
+	case *checkReminders:
+		return "reminder"
+	case *checkDeadlines:
+		return "deadline"
+	case *checkCapacity:
+		return "almost-full"
+	}
+	return "new"
+}
+
+// exitUnreachable is the exit code when the chat doesn't exist or has blocked the bot,
+// so scripts can tell a user who can't be reached from a temporary failure
+const exitUnreachable = 2
+
+// exitSendFailed reports a message about eventID that couldn't be sent and exits.
+// Transient failures have already been retried by the client by this point.
+func exitSendFailed(ctx context.Context, what, eventID string, err error) {
+	recordDelivery(notificationKind(), eventID, err)
+	fmt.Fprintf(os.Stderr, "Error sending %s for event %s: %v\n", what, eventID, err)
+	if errs.IsPermanent(err) {
+		fmt.Fprintf(os.Stderr, "Chat %s is unreachable; skipping remaining messages\n", *chatID)
+		os.Exit(exitUnreachable)
+	}
+	if ctx.Err() == nil {
+		errReporter.Error(ctx, err, errreport.Context{Command: what, ChatID: *chatID, EventID: eventID})
+	}
+	os.Exit(1)
+}
+
 // filterByState filters events by state code
 func filterByState(events []*event.Event, state string) []*event.Event {
 	if state == "" {
@@ -45,43 +358,48 @@ func filterByState(events []*event.Event, state string) []*event.Event {
 	return filtered
 }
 
-// filterByTime applies time-based filtering (past events, days ahead)
-func filterByTime(events []*event.Event, hidePastEvents bool, daysAheadFilter int) []*event.Event {
+// filterByTime applies time-based filtering (past events, days ahead) as of now
+func filterByTime(events []*event.Event, hidePastEvents bool, daysAheadFilter int, now time.Time) []*event.Event {
 	if !hidePastEvents && daysAheadFilter <= 0 {
 		return events
 	}
 
+	// The same rules the bot applies to a user with these settings
+	user := &preferences.UserPreferences{HidePastEvents: hidePastEvents, DaysAhead: daysAheadFilter}
+	filtered := eventquery.ForUser(user, now).HidePast().Horizon().Run(events)
+	if filtered == nil {
+		filtered = make([]*event.Event, 0)
+	}
+	return filtered
+}
+
+// filterByReminderDays filters events that are exactly X days away
+func filterByReminderDays(events []*event.Event, days int, now time.Time) []*event.Event {
 	filtered := make([]*event.Event, 0)
 	for _, evt := range events {
-		// Filter past events if enabled
-		if hidePastEvents && evt.IsPastEvent() {
-			continue
-		}
-		// Filter events beyond days_ahead window if enabled
-		if daysAheadFilter > 0 && !evt.IsWithinDays(daysAheadFilter) {
-			continue
+		if daysUntil, ok := evt.DaysUntilAt(now); ok && daysUntil == days {
+			filtered = append(filtered, evt)
 		}
-		filtered = append(filtered, evt)
 	}
 	return filtered
 }
 
-// filterByReminderDays filters events that are exactly X days away
-func filterByReminderDays(events []*event.Event, days int) []*event.Event {
+// filterByDeadlineDays filters events whose registration deadline is exactly X days away
+func filterByDeadlineDays(events []*event.Event, days int, now time.Time) []*event.Event {
 	filtered := make([]*event.Event, 0)
 	for _, evt := range events {
-		parsedDate := event.ParseDate(evt.DateText)
-		if parsedDate.IsZero() {
-			continue
+		if daysUntil, ok := evt.DaysUntilDeadlineAt(now); ok && daysUntil == days {
+			filtered = append(filtered, evt)
 		}
+	}
+	return filtered
+}
 
-		// Calculate days until event (at midnight)
-		now := time.Now()
-		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		eventDate := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, now.Location())
-		daysUntil := int(eventDate.Sub(today).Hours() / 24)
-
-		if daysUntil == days {
+// filterAlmostFull filters events whose field is at least percent taken
+func filterAlmostFull(events []*event.Event, percent int) []*event.Event {
+	filtered := make([]*event.Event, 0)
+	for _, evt := range events {
+		if evt.IsAlmostFull(float64(percent) / 100) {
 			filtered = append(filtered, evt)
 		}
 	}
@@ -106,24 +424,26 @@ func readEvents(filePath string) ([]*event.Event, error) {
 		reader = os.Stdin
 	}
 
-	var result struct {
-		NewEvents     []*event.Event       `json:"new_events"`
-		RemovedEvents []*event.Event       `json:"removed_events"`
-		ChangedEvents []*event.EventChange `json:"changed_events"`
+	// Stream only the array this mode needs; the rest of the document is skipped
+	events := make([]*event.Event, 0)
+	collect := func(evt *event.Event) error {
+		events = append(events, evt)
+		return nil
 	}
 
-	decoder := json.NewDecoder(reader)
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("parsing JSON: %w", err)
+	stream := event.DiffStream{OnNew: collect}
+	if *removalNotification {
+		stream = event.DiffStream{OnRemoved: collect}
+	} else if *restoredNotification {
+		stream = event.DiffStream{OnRestored: collect}
 	}
+	// Change notifications are handled by readChangedEvents, which needs the EventChange objects
 
-	// Return appropriate events based on mode
-	if *removalNotification {
-		return result.RemovedEvents, nil
+	if err := event.StreamDiff(reader, stream); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
 	}
-	// For change notifications, we need to return nil here and handle separately
-	// since we need the EventChange objects, not Event objects
-	return result.NewEvents, nil
+
+	return events, nil
 }
 
 // readChangedEvents reads changed events from file or stdin
@@ -145,27 +465,32 @@ func readChangedEvents(filePath string) ([]*event.EventChange, map[string]*event
 		reader = os.Stdin
 	}
 
-	var result struct {
-		NewEvents     []*event.Event       `json:"new_events"`
-		ChangedEvents []*event.EventChange `json:"changed_events"`
-	}
-
-	decoder := json.NewDecoder(reader)
-	if err := decoder.Decode(&result); err != nil {
+	changes := make([]*event.EventChange, 0)
+	eventsMap := make(map[string]*event.Event) // Event map for lookup
+
+	err := event.StreamDiff(reader, event.DiffStream{
+		OnNew: func(evt *event.Event) error {
+			eventsMap[evt.ID] = evt
+			return nil
+		},
+		OnRenamed: func(evt *event.Event) error {
+			eventsMap[evt.ID] = evt
+			return nil
+		},
+		OnChanged: func(change *event.EventChange) error {
+			changes = append(changes, change)
+			return nil
+		},
+	})
+	if err != nil {
 		return nil, nil, fmt.Errorf("parsing JSON: %w", err)
 	}
 
-	// Build event map for lookup
-	eventsMap := make(map[string]*event.Event)
-	for _, evt := range result.NewEvents {
-		eventsMap[evt.ID] = evt
-	}
-
-	return result.ChangedEvents, eventsMap, nil
+	return changes, eventsMap, nil
 }
 
 // handleChangeNotifications handles the change notification flow
-func handleChangeNotifications() {
+func handleChangeNotifications(ctx context.Context) {
 	changes, eventsMap, err := readChangedEvents(*eventsFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading changed events: %v\n", err)
@@ -231,18 +556,28 @@ func handleChangeNotifications() {
 			continue
 		}
 
+		// Each distinct change is its own notification in the ledger
+		kind := fmt.Sprintf("changed-%s-%s", change.ChangeType, change.NewValue)
+		if !beginDelivery(evt.ID, kind) {
+			continue
+		}
+
 		// Format the change message with status and note
 		msg, keyboard := telegram.FormatEventChangeWithNote(evt, change.ChangeType, change.OldValue, change.NewValue, *eventStatus, *eventNote)
+		msg, keyboard = applyNotifyHooks(ctx, notificationKind(), evt, msg, keyboard)
 
 		// Send message with keyboard
-		if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending change notification for event %s: %v\n", evt.ID, err)
-			os.Exit(1)
+		if err := client.SendMessageWithKeyboard(ctx, msg, keyboard); err != nil {
+			exitSendFailed(ctx, "change notification", evt.ID, err)
 		}
+		finishDelivery(evt.ID, kind)
 
 		// Rate limiting: wait between messages
 		if i < len(changes)-1 {
-			time.Sleep(1 * time.Second)
+			if err := telegram.Pause(ctx, 1*time.Second); err != nil {
+				fmt.Fprintf(os.Stderr, "Interrupted after sending %d of %d change notification(s)\n", i+1, len(changes))
+				os.Exit(1)
+			}
 		}
 	}
 
@@ -250,14 +585,15 @@ func handleChangeNotifications() {
 }
 
 // getCourseDetailsForEvent fetches course information for an event
-func getCourseDetailsForEvent(client *course.Client, evt *event.Event) *telegram.CourseDetails {
+func getCourseDetailsForEvent(ctx context.Context, client *course.Client, evt *event.Event) *telegram.CourseDetails {
 	if client == nil {
 		return nil
 	}
 
-	courseInfo, err := client.FindBestMatch(evt.Title, evt.City, evt.State)
+	courseInfo, err := client.FindBestMatch(ctx, evt.Title, evt.City, evt.State)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Error looking up course for %s: %v\n", evt.Title, err)
+		recordAPIError("golf-course", err)
 		return nil
 	}
 
@@ -265,6 +601,9 @@ func getCourseDetailsForEvent(client *course.Client, evt *event.Event) *telegram
 		return nil
 	}
 
+	// Fill in website, phone, and image from the course detail endpoint
+	client.EnrichDetails(ctx, courseInfo)
+
 	// Collect all tees (combined, no distinction between gender)
 	// Deduplicate by tee name - keep first occurrence (male tees come first)
 	seenTees := make(map[string]bool)
@@ -288,28 +627,94 @@ func getCourseDetailsForEvent(client *course.Client, evt *event.Event) *telegram
 	}
 
 	return &telegram.CourseDetails{
-		Name: courseInfo.GetDisplayName(),
-		Tees: tees,
+		Name:     courseInfo.GetDisplayName(),
+		Tees:     tees,
+		Website:  courseInfo.Website,
+		Phone:    courseInfo.Phone,
+		ImageURL: courseInfo.ImageURL,
+	}
+}
+
+// newCourseClient creates a Golf Course API client if a key is given, matching with the
+// --course-aliases overrides
+func newCourseClient() *course.Client {
+	if *golfCourseAPIKey == "" {
+		return nil
+	}
+	client := course.NewClient(*golfCourseAPIKey)
+	if *courseAliasesFile != "" {
+		aliases, err := loadCourseAliases(*courseAliasesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: course aliases disabled: %v\n", err)
+		} else {
+			client.SetAliases(aliases)
+		}
+	}
+	return client
+}
+
+// loadCourseAliases reads a course alias file. A missing file has no aliases.
+func loadCourseAliases(path string) (course.Aliases, error) {
+	aliases := make(course.Aliases)
+	data, err := storage.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, fmt.Errorf("reading course aliases: %w", err)
 	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("parsing course aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// newTeeTimeClient creates a tee-time provider client if a provider is configured
+func newTeeTimeClient() *teetime.Client {
+	if *teeTimeURL == "" && *teeTimeAPIURL == "" {
+		return nil
+	}
+	return teetime.NewClient(teetime.Config{
+		SearchURL:       *teeTimeURL,
+		AvailabilityURL: *teeTimeAPIURL,
+		APIKey:          *teeTimeAPIKey,
+	})
+}
+
+// addTeeTimeDetails adds tee-time details to course details (see
+// telegram.AddTeeTimeDetails), logging a failed availability lookup
+func addTeeTimeDetails(ctx context.Context, client *teetime.Client, evt *event.Event, details *telegram.CourseDetails) *telegram.CourseDetails {
+	details, err := telegram.AddTeeTimeDetails(ctx, client, evt, details)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Error checking tee times for %s: %v\n", evt.Title, err)
+		recordAPIError("tee-time", err)
+	}
+	return details
 }
 
 // handleDryRun handles dry run mode output for events
-func handleDryRun(events []*event.Event) {
+func handleDryRun(ctx context.Context, events []*event.Event) {
 	notificationType := "new event"
 	if *removalNotification {
 		notificationType = "removal"
+	} else if *restoredNotification {
+		notificationType = "restored event"
 	} else if *checkReminders {
 		notificationType = "reminder"
+	} else if *checkDeadlines {
+		notificationType = "registration deadline"
+	} else if *checkCapacity {
+		notificationType = "almost full"
 	}
 
 	fmt.Printf("DRY RUN MODE - Would send %d %s notification(s):\n\n", len(events), notificationType)
 
 	// Initialize Golf Course API client once if key is provided
-	var courseClient *course.Client
-	if *golfCourseAPIKey != "" {
-		courseClient = course.NewClient(*golfCourseAPIKey)
+	courseClient := newCourseClient()
+	if courseClient != nil {
 		fmt.Printf("Golf Course API enabled for dry run\n\n")
 	}
+	teeTimeClient := newTeeTimeClient()
 
 	for i, evt := range events {
 		var msg string
@@ -324,23 +729,57 @@ func handleDryRun(events []*event.Event) {
 				fmt.Printf("--- Removal Message %d/%d (Low Urgency) ---\n", i+1, len(events))
 			}
 			hasKeyboard = false
+		} else if *restoredNotification {
+			msg = telegram.FormatRestoredEvent(evt, *eventStatus)
+			fmt.Printf("--- Restored Message %d/%d ---\n", i+1, len(events))
+			hasKeyboard = false
+		} else if *checkDeadlines {
+			msg, _ = telegram.FormatDeadlineReminder(evt, *deadlineDays)
+			fmt.Printf("--- Deadline Reminder %d/%d ---\n", i+1, len(events))
+			hasKeyboard = true
+		} else if *checkCapacity {
+			msg, _ = telegram.FormatAlmostFull(evt)
+			fmt.Printf("--- Almost Full Alert %d/%d ---\n", i+1, len(events))
+			hasKeyboard = true
 		} else {
-			courseDetails := getCourseDetailsForEvent(courseClient, evt)
-			msg, _ = telegram.FormatEventWithStatusAndCourse(evt, courseDetails, "", "", "", nil)
-			fmt.Printf("--- Message %d/%d ---\n", i+1, len(events))
+			courseDetails := addTeeTimeDetails(ctx, teeTimeClient, evt, getCourseDetailsForEvent(ctx, courseClient, evt))
+			if activeExperiment != nil {
+				msg, _ = telegram.FormatNewEventVariant(experimentVariant, evt, courseDetails)
+				fmt.Printf("--- Message %d/%d (%s variant %s) ---\n", i+1, len(events), activeExperiment.Name, experimentVariant)
+			} else {
+				msg, _ = telegram.FormatEventWithStatusAndCourse(evt, courseDetails, "", "", "", nil)
+				fmt.Printf("--- Message %d/%d ---\n", i+1, len(events))
+			}
+			msg = withHints(msg, evt)
 			if courseDetails != nil {
 				fmt.Printf("Course info: %s (%d tee options)\n", courseDetails.Name, len(courseDetails.Tees))
+				if courseDetails.ImageURL != "" {
+					fmt.Printf("Course image: %s\n", courseDetails.ImageURL)
+				}
+				if courseDetails.TeeTimeURL != "" {
+					fmt.Printf("Tee times: %s\n", courseDetails.TeeTimeURL)
+				}
 			}
 			hasKeyboard = true
 		}
 
+		msg, pluginKeyboard := applyNotifyHooks(ctx, ledgerKind(), evt, msg, nil)
+
 		fmt.Println(msg)
 		fmt.Printf("\n(Length: %d characters)\n", len(msg))
 		if hasKeyboard {
-			fmt.Printf("Buttons: 📅 Calendar, ⭐ Interested, ✅ Registered, 🤔 Maybe, ❌ Skip\n\n")
+			fmt.Printf("Buttons: 📅 Calendar, ⭐ Interested, ✅ Registered, 🤔 Maybe, ❌ Skip\n")
 		} else {
-			fmt.Printf("No interactive buttons\n\n")
+			fmt.Printf("No interactive buttons\n")
 		}
+		if pluginKeyboard != nil {
+			for _, row := range pluginKeyboard.InlineKeyboard {
+				for _, button := range row {
+					fmt.Printf("Plugin button: %s → %s\n", button.Text, button.URL)
+				}
+			}
+		}
+		fmt.Println()
 	}
 }
 
@@ -373,10 +812,94 @@ func handleDryRunChanges(changes []*event.EventChange, eventsMap map[string]*eve
 
 func main() {
 	flag.Parse()
+	if *versionFlag {
+		fmt.Print(buildinfo.Get().String("vga-events-telegram"))
+		os.Exit(0)
+	}
+	if *chatHashSalt == "" {
+		fmt.Fprintln(os.Stderr, "Warning: ERROR_REPORT_SALT is not set; hashed chat IDs in reports and ledgers can be reversed")
+	}
+	errreport.SetChatIDKey(*chatHashSalt)
+	// Library code that doesn't take the time reads the same clock
+	preferences.SetClock(clk)
+	event.SetClock(clk)
+	runStart := time.Now()
+	links.Configure(links.Options{UTM: *linkUTM, Medium: *linkMedium, ShortenerURL: *shortenerURL, ShortenerToken: *shortenerToken, RedirectURL: *clickURL})
+
+	// Stop sending promptly on Ctrl-C or when a workflow run is canceled
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	if *dataDir != "" && !*dryRun {
+		if deliveryLog, err = storage.New(*dataDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: delivery log disabled: %v\n", err)
+		} else if ledger, err = deliveryLog.LoadLedger(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading delivery ledger: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Errors also go to the data directory's error log, for vga-events error-issues
+	reportOpts := errreport.Options{DSN: *errorDSN, Release: buildinfo.Get().Short(), Component: "notifier", RunID: *runID}
+	if deliveryLog != nil {
+		reportOpts.Log = deliveryLog
+	}
+	errReporter, err = errreport.New(reportOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: error reporting disabled: %v\n", err)
+	}
+
+	if *experimentName != "" {
+		if activeExperiment, err = experiment.Lookup(*experimentName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		experimentVariant = activeExperiment.Assign(*chatID)
+	}
+
+	if features, err = flags.Load(*flagsFile, os.Getenv("VGA_FLAGS")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading feature flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if hooks, err = plugin.Select(availablePlugins, *pluginNames); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *confirmDeliveries {
+		runConfirmDeliveries()
+		return
+	}
+
+	if *announce {
+		if err := runAnnounce(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *runReport {
+		if err := runRunReport(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *newStateNotification {
+		if err := runNewStateNotification(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Handle change notifications separately
 	if *changeNotification {
-		handleChangeNotifications()
+		handleChangeNotifications(ctx)
 		return
 	}
 
@@ -394,7 +917,10 @@ func main() {
 
 	// Apply filters
 	events = filterByState(events, *stateFilter)
-	events = filterByTime(events, *hidePast, *daysAhead)
+	events = filterByTime(events, *hidePast, *daysAhead, clk.Now())
+	if *restoredNotification {
+		events = receivedRemoval(events)
+	}
 
 	// Check reminders mode
 	if *checkReminders {
@@ -403,7 +929,7 @@ func main() {
 			os.Exit(1)
 		}
 
-		events = filterByReminderDays(events, *reminderDays)
+		events = filterByReminderDays(events, *reminderDays, clk.Now())
 
 		// If no events match, exit with code 1 (no reminder to send)
 		if len(events) == 0 {
@@ -411,8 +937,56 @@ func main() {
 		}
 	}
 
-	// Limit number of messages
+	// Check registration deadlines mode
+	if *checkDeadlines {
+		if *checkReminders {
+			fmt.Fprintf(os.Stderr, "Error: --check-deadlines and --check-reminders can't be used together\n")
+			os.Exit(1)
+		}
+		if *deadlineDays < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --deadline-days can't be negative\n")
+			os.Exit(1)
+		}
+
+		events = filterByDeadlineDays(events, *deadlineDays, clk.Now())
+
+		// If no events match, exit with code 1 (no reminder to send)
+		if len(events) == 0 {
+			os.Exit(1)
+		}
+	}
+
+	// Check almost-full mode. Nothing here remembers past alerts, so run it
+	// with --data-dir and the delivery ledger sends each one only once.
+	if *checkCapacity {
+		if *checkReminders || *checkDeadlines {
+			fmt.Fprintf(os.Stderr, "Error: --check-capacity can't be used with --check-reminders or --check-deadlines\n")
+			os.Exit(1)
+		}
+		if *capacityThreshold < 1 || *capacityThreshold > 100 {
+			fmt.Fprintf(os.Stderr, "Error: --capacity-threshold must be between 1 and 100\n")
+			os.Exit(1)
+		}
+
+		events = filterAlmostFull(events, *capacityThreshold)
+
+		// If no events match, exit with code 1 (no alert to send)
+		if len(events) == 0 {
+			os.Exit(1)
+		}
+	}
+
+	if *prefsFile != "" && notificationKind() == "new" {
+		if err := loadHints(events); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: card hints disabled: %v\n", err)
+		}
+	}
+
+	// Send the soonest events first, and only up to the cap
+	event.SortByDate(events)
+	overflow := 0
 	if len(events) > *maxMessages {
+		overflow = len(events) - *maxMessages
 		events = events[:*maxMessages]
 	}
 
@@ -423,7 +997,11 @@ func main() {
 
 	// Dry run mode
 	if *dryRun {
-		handleDryRun(events)
+		handleDryRun(ctx, events)
+		if overflow > 0 && notificationKind() == "new" {
+			msg, _ := telegram.FormatOverflowNotice(overflow, runStart)
+			fmt.Printf("--- Overflow Message ---\n%s\nButtons: 📋 View all\n\n", msg)
+		}
 		os.Exit(0)
 	}
 
@@ -445,19 +1023,34 @@ func main() {
 	}
 
 	// Initialize Golf Course API client if key is provided
-	var courseClient *course.Client
-	if *golfCourseAPIKey != "" {
-		courseClient = course.NewClient(*golfCourseAPIKey)
+	courseClient := newCourseClient()
+	if courseClient != nil {
 		fmt.Printf("Golf Course API enabled\n")
 	}
+	teeTimeClient := newTeeTimeClient()
+
+	// Cards the bot tracks for removed events are marked cancelled in place
+	var cardPrefs preferences.Preferences
+	if *prefsFile != "" && *removalNotification {
+		if cardPrefs, err = readPreferences(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: removed events' cards won't be marked cancelled: %v\n", err)
+		}
+	}
 
 	// Send messages with interactive buttons
 	for i, evt := range events {
+		if !beginDelivery(evt.ID, ledgerKind()) {
+			continue
+		}
+
 		var msg string
 		var keyboard *telegram.InlineKeyboardMarkup
+		cancelled := false
 
 		// Format message based on notification type
 		if *removalNotification {
+			// An edit doesn't notify, so tracked events still get the urgent message
+			cancelled = cancelCard(ctx, client, cardPrefs, evt) && *eventStatus == ""
 			// Removal notification - check if user had event tracked
 			if *eventStatus != "" {
 				// High urgency: user had this event tracked
@@ -468,45 +1061,90 @@ func main() {
 			}
 			// No keyboard for removal notifications
 			keyboard = nil
+		} else if *restoredNotification {
+			msg = telegram.FormatRestoredEvent(evt, *eventStatus)
 		} else if *checkReminders {
 			// Reminder notification
 			msg, keyboard = telegram.FormatReminder(evt, *reminderDays)
+		} else if *checkDeadlines {
+			// Registration deadline reminder
+			msg, keyboard = telegram.FormatDeadlineReminder(evt, *deadlineDays)
+		} else if *checkCapacity {
+			// Almost-full alert
+			msg, keyboard = telegram.FormatAlmostFull(evt)
 		} else {
 			// New event notification
 			// Look up course information if Golf Course API is enabled
-			courseDetails := getCourseDetailsForEvent(courseClient, evt)
+			courseDetails := getCourseDetailsForEvent(ctx, courseClient, evt)
 			if courseDetails != nil {
 				fmt.Printf("Found course info for %s: %s (%d tee options)\n",
 					evt.Title, courseDetails.Name, len(courseDetails.Tees))
+
+				// Attach the course photo ahead of the event card when the provider has one
+				if courseDetails.ImageURL != "" {
+					if err := client.SendPhoto(ctx, courseDetails.ImageURL, telegram.FormatCourseImageCaption(courseDetails)); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: Error sending course image for %s: %v\n", evt.ID, err)
+					}
+				}
 			}
+			courseDetails = addTeeTimeDetails(ctx, teeTimeClient, evt, courseDetails)
 			// Use status keyboard with course info for new events
-			msg, keyboard = telegram.FormatEventWithStatusAndCourse(evt, courseDetails, "", "", "", nil)
+			if activeExperiment != nil {
+				msg, keyboard = telegram.FormatNewEventVariant(experimentVariant, evt, courseDetails)
+				telegram.TagKeyboard(keyboard, activeExperiment.Tag(experimentVariant))
+			} else {
+				msg, keyboard = telegram.FormatEventWithStatusAndCourse(evt, courseDetails, "", "", "", nil)
+			}
+			msg = withHints(msg, evt)
 		}
-
-		// Send message
-		if keyboard != nil {
-			if err := client.SendMessageWithKeyboard(msg, keyboard); err != nil {
-				fmt.Fprintf(os.Stderr, "Error sending message for event %s: %v\n", evt.ID, err)
-				os.Exit(1)
+		msg, keyboard = applyNotifyHooks(ctx, ledgerKind(), evt, msg, keyboard)
+
+		// Send message, unless the cancelled card already tells the user
+		if cancelled {
+			fmt.Printf("Marked the card for %s cancelled instead of sending a removal notification\n", evt.ID)
+		} else if keyboard != nil {
+			if err := client.SendMessageWithKeyboard(ctx, msg, keyboard); err != nil {
+				exitSendFailed(ctx, "message", evt.ID, err)
 			}
 		} else {
-			if err := client.SendMessage(msg); err != nil {
-				fmt.Fprintf(os.Stderr, "Error sending message for event %s: %v\n", evt.ID, err)
-				os.Exit(1)
+			if err := client.SendMessage(ctx, msg); err != nil {
+				exitSendFailed(ctx, "message", evt.ID, err)
 			}
 		}
+		finishDelivery(evt.ID, ledgerKind())
 
 		// Rate limiting: wait between messages
 		if i < len(events)-1 {
-			time.Sleep(1 * time.Second)
+			if err := telegram.Pause(ctx, 1*time.Second); err != nil {
+				fmt.Fprintf(os.Stderr, "Interrupted after sending %d of %d message(s)\n", i+1, len(events))
+				os.Exit(1)
+			}
+		}
+	}
+
+	// New events past the cap get one summary; the workflow marks them seen after this
+	// run starts, so the button's list covers them
+	if overflow > 0 && notificationKind() == "new" {
+		if err := telegram.Pause(ctx, 1*time.Second); err != nil {
+			os.Exit(1)
+		}
+		msg, keyboard := telegram.FormatOverflowNotice(overflow, runStart)
+		if err := client.SendMessageWithKeyboard(ctx, msg, keyboard); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Error sending overflow notice: %v\n", err)
 		}
 	}
 
 	messageType := "message"
 	if *removalNotification {
 		messageType = "removal notification"
+	} else if *restoredNotification {
+		messageType = "restored event notification"
 	} else if *checkReminders {
 		messageType = "reminder"
+	} else if *checkDeadlines {
+		messageType = "deadline reminder"
+	} else if *checkCapacity {
+		messageType = "almost-full alert"
 	}
 	fmt.Printf("Successfully sent %d %s(s)\n", len(events), messageType)
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// runNewStateNotification sends --chat-id one announcement for each state listing its
+// first ever events (the diff's new_states). The --data-dir ledger is keyed by state,
+// so a rerun doesn't announce a state twice.
+func runNewStateNotification(ctx context.Context) error {
+	d, err := loadDiff()
+	if err != nil {
+		return err
+	}
+	if len(d.NewStates) == 0 {
+		fmt.Println("No new states to announce")
+		return nil
+	}
+
+	if *dryRun {
+		fmt.Printf("DRY RUN MODE - Would send %d new state announcement(s):\n\n", len(d.NewStates))
+		for i, state := range d.NewStates {
+			fmt.Printf("--- New State %d/%d ---\n%s\n\n", i+1, len(d.NewStates), telegram.FormatNewState(state, d.New))
+		}
+		return nil
+	}
+
+	if *chatID == "" {
+		return errors.New("--new-state-notification needs --chat-id")
+	}
+	client, err := telegram.NewClient(*botToken, *chatID)
+	if err != nil {
+		return err
+	}
+
+	for i, state := range d.NewStates {
+		if !beginDelivery(state, ledgerKind()) {
+			continue
+		}
+		if err := client.SendMessage(ctx, telegram.FormatNewState(state, d.New)); err != nil {
+			exitSendFailed(ctx, "new state announcement", state, err)
+		}
+		finishDelivery(state, ledgerKind())
+
+		if i < len(d.NewStates)-1 {
+			if err := telegram.Pause(ctx, 1*time.Second); err != nil {
+				fmt.Fprintf(os.Stderr, "Interrupted after sending %d of %d announcement(s)\n", i+1, len(d.NewStates))
+				os.Exit(1)
+			}
+		}
+	}
+	fmt.Printf("Successfully sent %d new state announcement(s)\n", len(d.NewStates))
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/plugin"
+	"github.com/pfrederiksen/vga-events/internal/plugin/directions"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// availablePlugins are the plugins compiled into the notifier; --plugins turns them on.
+// Forks add theirs here.
+var availablePlugins = []plugin.Plugin{
+	directions.Plugin{},
+}
+
+// hooks are the plugins turned on for this run
+var hooks *plugin.Set
+
+// applyNotifyHooks lets plugins edit a notification before it's sent. Their actions
+// are added to the keyboard as one row of link buttons.
+func applyNotifyHooks(ctx context.Context, kind string, evt *event.Event, msg string, keyboard *telegram.InlineKeyboardMarkup) (string, *telegram.InlineKeyboardMarkup) {
+	if hooks.Len() == 0 {
+		return msg, keyboard
+	}
+	n := &plugin.Notification{ChatID: *chatID, Kind: kind, Event: evt, Text: msg}
+	if err := hooks.NotifyUser(ctx, n); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if len(n.Actions) == 0 {
+		return n.Text, keyboard
+	}
+
+	if keyboard == nil {
+		keyboard = &telegram.InlineKeyboardMarkup{}
+	}
+	row := make([]telegram.InlineKeyboardButton, 0, len(n.Actions))
+	for _, action := range n.Actions {
+		row = append(row, telegram.InlineKeyboardButton{Text: action.Text, URL: action.URL})
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	return n.Text, keyboard
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+)
+
+// runRunReport sends --chat-id (the maintainer) a summary of run --run-id from the
+// --data-dir run and delivery logs. It reads the logs itself, since the delivery log
+// isn't opened in dry-run mode.
+func runRunReport(ctx context.Context) error {
+	if *dataDir == "" {
+		return errors.New("--run-report needs --data-dir")
+	}
+	if *runID == "" {
+		return errors.New("--run-report needs --run-id")
+	}
+	store, err := storage.New(*dataDir)
+	if err != nil {
+		return err
+	}
+	runs, err := store.LoadRuns()
+	if err != nil {
+		return err
+	}
+	deliveries, err := store.LoadDeliveries()
+	if err != nil {
+		return err
+	}
+
+	msg := telegram.FormatRunReport(storage.SummarizeRun(runs, deliveries, *runID, time.Now()))
+	if *dryRun {
+		fmt.Printf("DRY RUN MODE - Would send run report:\n\n%s\n", msg)
+		return nil
+	}
+
+	if *chatID == "" {
+		return errors.New("--run-report needs --chat-id")
+	}
+	client, err := telegram.NewClient(*botToken, *chatID)
+	if err != nil {
+		return err
+	}
+	if err := client.SendMessage(ctx, msg); err != nil {
+		return fmt.Errorf("sending run report: %w", err)
+	}
+	fmt.Println("Successfully sent run report")
+	return nil
+}
@@ -1,13 +1,11 @@
 package main
 
-import "github.com/pfrederiksen/vga-events/internal/cli"
-
-var (
-	version = "dev"
-	commit  = "none"
-	date    = "unknown"
+import (
+	"github.com/pfrederiksen/vga-events/internal/cli"
+	"github.com/pfrederiksen/vga-events/internal/plugin/directions"
 )
 
 func main() {
-	cli.Execute(version, commit, date)
+	// Compiled-in plugins, turned on with --plugins
+	cli.Execute(directions.Plugin{})
 }
@@ -0,0 +1,325 @@
+// Package api serves a read-only HTTP API over the bot's preferences and the latest
+// event snapshot. Users authenticate with the personal token from the bot's
+// /api-token command and only ever see their own data.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/calendar"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+// DefaultPrefsTTL is how long loaded preferences are reused, so a rotated or revoked
+// token stops working within a minute without loading the Gist on every request
+const DefaultPrefsTTL = time.Minute
+
+// PrefsLoader loads the current preferences
+type PrefsLoader func(ctx context.Context) (preferences.Preferences, error)
+
+// EventsLoader loads the current events
+type EventsLoader func() ([]*event.Event, error)
+
+// ClickRecorder records a click on a tracked link
+type ClickRecorder func(*storage.Click) error
+
+// Options configures a Server
+type Options struct {
+	Prefs    PrefsLoader
+	Events   EventsLoader
+	PrefsTTL time.Duration // 0 uses DefaultPrefsTTL
+	Clicks   ClickRecorder // nil redirects /r/ links without recording them
+	Features *flags.Set    // nil leaves every feature flag at its default
+}
+
+// Server is the HTTP API
+type Server struct {
+	loadPrefs  PrefsLoader
+	loadEvents EventsLoader
+	prefsTTL   time.Duration
+	clicks     ClickRecorder
+	features   *flags.Set
+	clickMu    sync.Mutex // Serializes clicks so log lines don't interleave
+	mux        *http.ServeMux
+
+	mu       sync.Mutex
+	prefs    preferences.Preferences
+	loadedAt time.Time
+}
+
+// New creates a Server
+func New(opts Options) *Server {
+	s := &Server{
+		loadPrefs:  opts.Prefs,
+		loadEvents: opts.Events,
+		prefsTTL:   opts.PrefsTTL,
+		clicks:     opts.Clicks,
+		features:   opts.Features,
+		mux:        http.NewServeMux(),
+	}
+	if s.prefsTTL <= 0 {
+		s.prefsTTL = DefaultPrefsTTL
+	}
+
+	s.mux.HandleFunc("GET /api/v1/me", s.authenticated(s.handleMe))
+	s.mux.HandleFunc("GET /api/v1/events", s.authenticated(s.handleEvents))
+	s.mux.HandleFunc("GET /api/v1/tracked", s.authenticated(s.handleTracked))
+	s.mux.HandleFunc("GET /api/v1/filters", s.authenticated(s.handleFilters))
+	s.mux.HandleFunc("GET /api/v1/calendar.ics", s.authenticated(s.handleCalendar))
+	s.mux.HandleFunc("GET /widget/{state}", s.handleWidget)
+	s.mux.HandleFunc("GET /og/{event}", s.handleCard)
+	s.mux.HandleFunc("GET /r/{token}", s.handleRedirect)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// preferences returns the cached preferences, reloading them once they're older than
+// the TTL. If a reload fails, the previous preferences are used.
+func (s *Server) preferences(ctx context.Context) (preferences.Preferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prefs != nil && time.Since(s.loadedAt) < s.prefsTTL {
+		return s.prefs, nil
+	}
+	prefs, err := s.loadPrefs(ctx)
+	if err != nil {
+		if s.prefs != nil {
+			fmt.Fprintf(os.Stderr, "Warning: reloading preferences: %v\n", err)
+			return s.prefs, nil
+		}
+		return nil, err
+	}
+	s.prefs = prefs
+	s.loadedAt = time.Now()
+	return prefs, nil
+}
+
+// request is an authenticated request with the caller's preferences
+type request struct {
+	*http.Request
+	chatID string
+	user   *preferences.UserPreferences
+}
+
+// authenticated checks the request's API token before calling next. The token is
+// read from "Authorization: Bearer <token>", or the token query parameter for
+// calendar apps that can't send headers.
+func (s *Server) authenticated(next func(http.ResponseWriter, *request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.URL.Query().Get("token")
+		}
+		if token == "" {
+			writeError(w, http.StatusUnauthorized, "missing API token: send it as \"Authorization: Bearer <token>\"; get one with /api-token in the bot")
+			return
+		}
+
+		prefs, err := s.preferences(r.Context())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading preferences: %v\n", err)
+			writeError(w, http.StatusServiceUnavailable, "preferences unavailable")
+			return
+		}
+		chatID, ok := prefs.FindAPIToken(strings.TrimSpace(token))
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid or revoked API token")
+			return
+		}
+		if !s.features.EnabledFor(flags.WebAPI, chatID) {
+			writeError(w, http.StatusForbidden, "the API isn't enabled for this account yet")
+			return
+		}
+
+		next(w, &request{Request: r, chatID: chatID, user: prefs[chatID]})
+	}
+}
+
+// meResponse is the caller's subscription settings
+type meResponse struct {
+	ChatID          string                           `json:"chat_id"`
+	States          []string                         `json:"states"`
+	Cities          []preferences.CitySubscription   `json:"cities,omitempty"`
+	FollowedCourses []string                         `json:"followed_courses,omitempty"`
+	Travel          []preferences.TravelSubscription `json:"travel,omitempty"`
+	DigestFrequency string                           `json:"digest_frequency"`
+	PausedUntil     *time.Time                       `json:"paused_until,omitempty"`
+	TokenCreated    time.Time                        `json:"token_created"`
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *request) {
+	resp := meResponse{
+		ChatID:          r.chatID,
+		States:          r.user.States,
+		Cities:          r.user.Cities,
+		FollowedCourses: r.user.FollowedCourses,
+		Travel:          r.user.Travel,
+		DigestFrequency: r.user.DigestFrequency,
+		TokenCreated:    time.Unix(r.user.APITokenCreated, 0).UTC(),
+	}
+	if resp.States == nil {
+		resp.States = []string{}
+	}
+	if r.user.IsPaused(time.Now()) {
+		until := r.user.PausedUntilTime()
+		resp.PausedUntil = &until
+	}
+	writeJSON(w, resp)
+}
+
+// eventsResponse lists events, soonest first
+type eventsResponse struct {
+	Events []*event.Event `json:"events"`
+	Count  int            `json:"count"`
+}
+
+// handleEvents lists current events. ?state=NV (or ALL) picks a state; by default
+// the caller's subscriptions are matched.
+func (s *Server) handleEvents(w http.ResponseWriter, r *request) {
+	events, ok := s.events(w)
+	if !ok {
+		return
+	}
+
+	state := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("state")))
+	if state != "" && !preferences.IsValidState(state) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid state %q", state))
+		return
+	}
+
+	selected := []*event.Event{}
+	for _, evt := range events {
+		switch {
+		case state == "ALL",
+			state != "" && strings.EqualFold(evt.State, state),
+			state == "" && r.user.MatchesSubscriptions(evt):
+			selected = append(selected, evt)
+		}
+	}
+	event.SortByDate(selected)
+	writeJSON(w, eventsResponse{Events: selected, Count: len(selected)})
+}
+
+// trackedEvent is an event the caller has marked with a status. Event is omitted
+// when it's no longer in the snapshot.
+type trackedEvent struct {
+	EventID string       `json:"event_id"`
+	Status  string       `json:"status"`
+	Note    string       `json:"note,omitempty"`
+	Event   *event.Event `json:"event,omitempty"`
+}
+
+func (s *Server) handleTracked(w http.ResponseWriter, r *request) {
+	events, ok := s.events(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, map[string]interface{}{"tracked": trackedEvents(r.user, events)})
+}
+
+// trackedEvents joins the user's statuses and notes with the current events, sorted
+// by event date with events no longer listed last
+func trackedEvents(user *preferences.UserPreferences, events []*event.Event) []trackedEvent {
+	byID := make(map[string]*event.Event, len(events))
+	for _, evt := range events {
+		byID[evt.ID] = evt
+	}
+
+	tracked := make([]trackedEvent, 0, len(user.EventStatuses))
+	for id, status := range user.EventStatuses {
+		tracked = append(tracked, trackedEvent{EventID: id, Status: status, Note: user.EventNotes[id], Event: byID[id]})
+	}
+	sort.Slice(tracked, func(i, j int) bool {
+		a, b := tracked[i].Event, tracked[j].Event
+		if (a == nil) != (b == nil) {
+			return b == nil
+		}
+		if a != nil {
+			da, db := event.ParseDate(a.DateText), event.ParseDate(b.DateText)
+			if !da.Equal(db) {
+				return da.Before(db)
+			}
+		}
+		return tracked[i].EventID < tracked[j].EventID
+	})
+	return tracked
+}
+
+// filtersResponse is the caller's saved filters
+type filtersResponse struct {
+	Filters      map[string]*filter.FilterPreset `json:"filters"`
+	ActiveFilter string                          `json:"active_filter,omitempty"`
+}
+
+func (s *Server) handleFilters(w http.ResponseWriter, r *request) {
+	filters := r.user.SavedFilters
+	if filters == nil {
+		filters = map[string]*filter.FilterPreset{}
+	}
+	writeJSON(w, filtersResponse{Filters: filters, ActiveFilter: r.user.ActiveFilter})
+}
+
+// handleCalendar serves the caller's tracked events (except skipped ones) as an
+// iCalendar feed that calendar apps can subscribe to
+func (s *Server) handleCalendar(w http.ResponseWriter, r *request) {
+	events, ok := s.events(w)
+	if !ok {
+		return
+	}
+
+	var feed []*event.Event
+	opts := make(map[string]*calendar.EventOptions)
+	for _, t := range trackedEvents(r.user, events) {
+		if t.Event == nil || t.Status == preferences.EventStatusSkip {
+			continue
+		}
+		feed = append(feed, t.Event)
+		opts[t.EventID] = &calendar.EventOptions{Status: t.Status, Note: t.Note}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	_, _ = fmt.Fprint(w, calendar.GenerateBulkICSWithOptions(feed, "VGA Golf - My Events", opts))
+}
+
+// events loads the current events, writing an error response if that fails
+func (s *Server) events(w http.ResponseWriter) ([]*event.Event, bool) {
+	events, err := s.loadEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading events: %v\n", err)
+		writeError(w, http.StatusServiceUnavailable, "events unavailable")
+		return nil, false
+	}
+	return events, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func newTestServer(t *testing.T) (*Server, string, preferences.Preferences) {
+	t.Helper()
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "NV")
+	user := prefs.GetUser("123")
+	token := user.NewAPIToken(time.Now())
+	user.SetEventStatus("nv1", preferences.EventStatusRegistered)
+	user.SetEventNote("nv1", "Tee time 8am")
+	user.SetEventStatus("gone", preferences.EventStatusInterested)
+	user.SetEventStatus("ca1", preferences.EventStatusSkip)
+	user.SaveFilter("weekends", &filter.Filter{WeekendsOnly: true})
+
+	prefs.AddState("456", "CA")
+	prefs.GetUser("456").NewAPIToken(time.Now())
+
+	events := []*event.Event{
+		{ID: "nv1", State: "NV", Title: "Wolf Creek", DateText: "Apr 12 2026"},
+		{ID: "nv2", State: "NV", Title: "Chimera", DateText: "Apr 2 2026"},
+		{ID: "ca1", State: "CA", Title: "Pebble Beach", DateText: "May 1 2026"},
+	}
+
+	s := New(Options{
+		Prefs:  func(ctx context.Context) (preferences.Preferences, error) { return prefs, nil },
+		Events: func() ([]*event.Event, error) { return events, nil },
+	})
+	return s, token, prefs
+}
+
+func get(t *testing.T, s http.Handler, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthentication(t *testing.T) {
+	s, token, _ := newTestServer(t)
+
+	if rec := get(t, s, "/api/v1/me", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status %d, want 401", rec.Code)
+	}
+	if rec := get(t, s, "/api/v1/me", "vga_WRONG"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d, want 401", rec.Code)
+	}
+
+	rec := get(t, s, "/api/v1/me", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var me meResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &me); err != nil {
+		t.Fatal(err)
+	}
+	if me.ChatID != "123" || len(me.States) != 1 || me.States[0] != "NV" {
+		t.Errorf("unexpected /me: %+v", me)
+	}
+}
+
+func TestRevokedTokenAfterReload(t *testing.T) {
+	s, token, prefs := newTestServer(t)
+	s.prefsTTL = time.Nanosecond
+
+	if rec := get(t, s, "/api/v1/me", token); rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	prefs.GetUser("123").RevokeAPIToken()
+	if rec := get(t, s, "/api/v1/me", token); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: status %d, want 401", rec.Code)
+	}
+}
+
+func TestWebAPIFlag(t *testing.T) {
+	s, token, _ := newTestServer(t)
+	s.features = flags.New()
+	if err := s.features.ApplyEnv("enable_web_api=0%"); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := get(t, s, "/api/v1/me", token); rec.Code != http.StatusForbidden {
+		t.Errorf("API off for the chat: status %d, want 403", rec.Code)
+	}
+	// Tokens are still checked first
+	if rec := get(t, s, "/api/v1/me", "vga_WRONG"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d, want 401", rec.Code)
+	}
+
+	s.features, _ = flags.Parse([]byte(`{"enable_web_api": {"chats": ["123"]}}`))
+	if rec := get(t, s, "/api/v1/me", token); rec.Code != http.StatusOK {
+		t.Errorf("API on for the chat: status %d, want 200", rec.Code)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	s, token, _ := newTestServer(t)
+
+	var resp eventsResponse
+	rec := get(t, s, "/api/v1/events", token)
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 2 || resp.Events[0].ID != "nv2" {
+		t.Errorf("subscribed events should be NV, soonest first: %+v", resp.Events)
+	}
+
+	rec = get(t, s, "/api/v1/events?state=all", token)
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 3 {
+		t.Errorf("?state=all returned %d events, want 3", resp.Count)
+	}
+
+	if rec := get(t, s, "/api/v1/events?state=XX", token); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid state: status %d, want 400", rec.Code)
+	}
+}
+
+func TestTrackedAndFilters(t *testing.T) {
+	s, token, _ := newTestServer(t)
+
+	var tracked struct {
+		Tracked []trackedEvent `json:"tracked"`
+	}
+	rec := get(t, s, "/api/v1/tracked", token)
+	if err := json.Unmarshal(rec.Body.Bytes(), &tracked); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked.Tracked) != 3 {
+		t.Fatalf("got %d tracked events, want 3", len(tracked.Tracked))
+	}
+	first, last := tracked.Tracked[0], tracked.Tracked[2]
+	if first.EventID != "nv1" || first.Note != "Tee time 8am" || first.Event == nil {
+		t.Errorf("unexpected first tracked event: %+v", first)
+	}
+	if last.EventID != "gone" || last.Event != nil {
+		t.Errorf("events no longer listed should come last without details: %+v", last)
+	}
+
+	var filters filtersResponse
+	rec = get(t, s, "/api/v1/filters", token)
+	if err := json.Unmarshal(rec.Body.Bytes(), &filters); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := filters.Filters["weekends"]; !ok {
+		t.Errorf("saved filter missing: %s", rec.Body)
+	}
+}
+
+func TestCalendarFeed(t *testing.T) {
+	s, token, _ := newTestServer(t)
+
+	rec := get(t, s, "/api/v1/calendar.ics?token="+token, "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Wolf Creek") {
+		t.Error("feed should include the registered event")
+	}
+	if strings.Contains(body, "Pebble Beach") {
+		t.Error("feed should leave out skipped events")
+	}
+}
+
+func TestPrefsUnavailable(t *testing.T) {
+	s := New(Options{
+		Prefs:  func(ctx context.Context) (preferences.Preferences, error) { return nil, errors.New("gist down") },
+		Events: func() ([]*event.Event, error) { return nil, nil },
+	})
+	if rec := get(t, s, "/api/v1/me", "vga_X"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want 503", rec.Code)
+	}
+}
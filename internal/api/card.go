@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/imagecard"
+)
+
+// cardMaxAge is how long social platforms and proxies may cache a card image
+const cardMaxAge = time.Hour
+
+// handleCard serves an event's social card image for og:image and twitter:image.
+// The event is named by its ID or short code, with an optional ".png" suffix:
+// /og/NV-417.png. It needs no token.
+func (s *Server) handleCard(w http.ResponseWriter, r *http.Request) {
+	ref := strings.TrimSuffix(r.PathValue("event"), ".png")
+
+	events, err := s.loadEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading events: %v\n", err)
+		http.Error(w, "events unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	evt := findEvent(events, ref)
+	if evt == nil {
+		http.Error(w, "event not found", http.StatusNotFound)
+		return
+	}
+
+	card := imagecard.FromEvent(evt)
+	etag := `"` + card.Key() + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(cardMaxAge.Seconds())))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := imagecard.Render(&buf, card); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering card for %s: %v\n", evt.ID, err)
+		http.Error(w, "rendering failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// findEvent returns the event with the given ID or short code (case-insensitive)
+func findEvent(events []*event.Event, ref string) *event.Event {
+	for _, evt := range events {
+		if evt.ID == ref || (evt.ShortCode != "" && strings.EqualFold(evt.ShortCode, ref)) {
+			return evt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestCard(t *testing.T) {
+	events := []*event.Event{{ID: "abc123", ShortCode: "NV-417", State: "NV", Title: "Wolf Creek", DateText: "Apr 12 2026"}}
+	s := New(Options{
+		Prefs:  func(ctx context.Context) (preferences.Preferences, error) { return preferences.NewPreferences(), nil },
+		Events: func() ([]*event.Event, error) { return events, nil },
+	})
+
+	for _, path := range []string{"/og/abc123.png", "/og/abc123", "/og/nv-417.png"} {
+		rec := get(t, s, path, "")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+			t.Fatalf("%s: status %d, type %q", path, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if _, err := png.Decode(bytes.NewReader(rec.Body.Bytes())); err != nil {
+			t.Errorf("%s: not a PNG: %v", path, err)
+		}
+	}
+
+	if rec := get(t, s, "/og/missing.png", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown event: status %d, want 404", rec.Code)
+	}
+
+	etag := get(t, s, "/og/abc123.png", "").Header().Get("ETag")
+	req := httptest.NewRequest(http.MethodGet, "/og/abc123.png", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("matching ETag: status %d, want 304", rec.Code)
+	}
+}
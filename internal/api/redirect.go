@@ -0,0 +1,62 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+// handleRedirect records a click on a tracked link and redirects to the event's page
+// on vgagolf.org, or the registration page. The token (from links.Track) names the
+// channel, campaign, and event; it's not trusted for the destination, so the
+// redirect can't be pointed anywhere else. It needs no token.
+func (s *Server) handleRedirect(w http.ResponseWriter, r *http.Request) {
+	source, campaign, eventID, err := links.ParseClickToken(r.PathValue("token"))
+	if err != nil {
+		http.Error(w, "link not found", http.StatusNotFound)
+		return
+	}
+
+	target := links.RegistrationURL
+	if eventID != "" {
+		if events, err := s.loadEvents(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: loading events for redirect: %v\n", err)
+		} else if evt := findEvent(events, eventID); evt != nil && isVGAURL(evt.SourceURL) {
+			target = evt.SourceURL
+		}
+	}
+
+	if s.clicks != nil {
+		s.clickMu.Lock()
+		err := s.clicks(&storage.Click{
+			At:       time.Now().UTC().Format(time.RFC3339),
+			Channel:  source,
+			Campaign: campaign,
+			EventID:  eventID,
+		})
+		s.clickMu.Unlock()
+		if err != nil {
+			// Losing a click isn't worth a broken link
+			fmt.Fprintf(os.Stderr, "Warning: recording click: %v\n", err)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, links.URL(target, source, campaign), http.StatusFound)
+}
+
+// isVGAURL reports whether rawURL is an https link on vgagolf.org
+func isVGAURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "vgagolf.org" || strings.HasSuffix(host, ".vgagolf.org")
+}
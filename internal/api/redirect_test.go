@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func TestRedirect(t *testing.T) {
+	events := []*event.Event{
+		{ID: "abc123", State: "NV", Title: "Wolf Creek", SourceURL: "https://vgagolf.org/events/123"},
+		{ID: "evil", State: "NV", Title: "Elsewhere", SourceURL: "https://example.com/phish"},
+	}
+	var clicks []*storage.Click
+	s := New(Options{
+		Prefs:  func(ctx context.Context) (preferences.Preferences, error) { return preferences.NewPreferences(), nil },
+		Events: func() ([]*event.Event, error) { return events, nil },
+		Clicks: func(c *storage.Click) error {
+			clicks = append(clicks, c)
+			return nil
+		},
+	})
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{links.ClickToken(links.SourceTelegram, links.CampaignReminder, "abc123"), "https://vgagolf.org/events/123"},
+		{links.ClickToken(links.SourceTwitter, links.CampaignNewEvent, "evil"), links.RegistrationURL},
+		{links.ClickToken(links.SourceTelegram, links.CampaignDigest, ""), links.RegistrationURL},
+	}
+	for _, tt := range tests {
+		rec := get(t, s, "/r/"+tt.token, "")
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != tt.want {
+			t.Errorf("/r/%s: status %d, location %q; want 302 to %q", tt.token, rec.Code, rec.Header().Get("Location"), tt.want)
+		}
+	}
+
+	if len(clicks) != 3 {
+		t.Fatalf("recorded %d clicks, want 3", len(clicks))
+	}
+	if c := clicks[0]; c.Channel != "telegram" || c.Campaign != "reminder" || c.EventID != "abc123" || c.At == "" {
+		t.Errorf("click = %+v", c)
+	}
+
+	if rec := get(t, s, "/r/not-a-token!", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("bad token: status %d, want 404", rec.Code)
+	}
+	if len(clicks) != 3 {
+		t.Errorf("bad token was recorded")
+	}
+}
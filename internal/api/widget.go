@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const (
+	// widgetMaxAge is how long browsers and proxies may cache a widget
+	widgetMaxAge = 15 * time.Minute
+
+	defaultWidgetLimit = 5
+	maxWidgetLimit     = 25
+	maxWidgetTitle     = 80
+)
+
+// hexColor matches a color parameter: 3 or 6 hex digits, without the "#"
+var hexColor = regexp.MustCompile(`^([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// widgetThemes are the default colors for ?theme=
+var widgetThemes = map[string]widgetColors{
+	"light": {Background: "#ffffff", Text: "#1d2a1f", Accent: "#2e7d32", Muted: "#666666"},
+	"dark":  {Background: "#1e1e1e", Text: "#eeeeee", Accent: "#81c784", Muted: "#aaaaaa"},
+}
+
+// widgetFonts are the font stacks for ?font=
+var widgetFonts = map[string]string{
+	"sans":  "system-ui, -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif",
+	"serif": "Georgia, 'Times New Roman', serif",
+	"mono":  "ui-monospace, Menlo, Consolas, monospace",
+}
+
+type widgetColors struct {
+	Background, Text, Accent, Muted string
+}
+
+// widgetOptions are the styling and content parameters of a widget
+type widgetOptions struct {
+	Title    string
+	Limit    int
+	Days     int // Only events within this many days, 0 for all
+	Colors   widgetColors
+	Font     template.CSS
+	FontSize int // px
+}
+
+// widgetData is what the widget template renders
+type widgetData struct {
+	widgetOptions
+	State  string
+	Events []*event.Event
+}
+
+var widgetTemplate = template.Must(template.New("widget").Funcs(template.FuncMap{"link": widgetLink}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; padding: 0.75em; background: {{.Colors.Background}}; color: {{.Colors.Text}}; font-family: {{.Font}}; font-size: {{.FontSize}}px; }
+h1 { font-size: 1.15em; margin: 0 0 0.5em; color: {{.Colors.Accent}}; }
+ul { list-style: none; margin: 0; padding: 0; }
+li { padding: 0.35em 0; border-bottom: 1px solid {{.Colors.Muted}}33; }
+li:last-child { border-bottom: none; }
+a { color: {{.Colors.Text}}; text-decoration: none; font-weight: 600; }
+a:hover { color: {{.Colors.Accent}}; }
+.meta { display: block; color: {{.Colors.Muted}}; font-size: 0.85em; }
+.empty, footer { color: {{.Colors.Muted}}; font-size: 0.85em; }
+footer { margin-top: 0.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Events}}<ul>
+{{range .Events}}<li>{{if .SourceURL}}<a href="{{link .SourceURL}}" target="_blank" rel="noopener noreferrer">{{.Title}}</a>{{else}}<strong>{{.Title}}</strong>{{end}}
+<span class="meta">{{.DateText}}{{with .City}} · {{.}}{{end}}{{if eq $.State "ALL"}} · {{.State}}{{end}}</span></li>
+{{end}}</ul>
+{{else}}<p class="empty">No upcoming events.</p>
+{{end}}<footer><a href="https://vgagolf.org" target="_blank" rel="noopener noreferrer">VGA Golf</a></footer>
+</body>
+</html>
+`))
+
+// handleWidget serves a small, cacheable HTML page of a state's upcoming events
+// (or ALL states) for club websites to embed in an iframe. It needs no token and
+// no JavaScript.
+//
+// Query parameters: limit (1-25, default 5), days (only events within N days),
+// theme (light or dark), accent, bg, and text (hex colors without "#"),
+// font (sans, serif, or mono), size (font size in px, 10-24), and title.
+func (s *Server) handleWidget(w http.ResponseWriter, r *http.Request) {
+	state := strings.ToUpper(r.PathValue("state"))
+	if state != "ALL" && !preferences.IsValidState(state) {
+		http.Error(w, fmt.Sprintf("invalid state %q", state), http.StatusBadRequest)
+		return
+	}
+	opts, err := parseWidgetOptions(r.URL.Query(), state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.loadEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading events: %v\n", err)
+		http.Error(w, "events unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+	data := widgetData{widgetOptions: opts, State: state, Events: upcomingEvents(events, state, opts, time.Now())}
+	if err := widgetTemplate.Execute(&buf, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering widget: %v\n", err)
+		http.Error(w, "rendering failed", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(widgetMaxAge.Seconds())))
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors *")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// parseWidgetOptions reads and validates the widget's query parameters
+func parseWidgetOptions(query url.Values, state string) (widgetOptions, error) {
+	opts := widgetOptions{
+		Title:    "Upcoming VGA Golf Events",
+		Limit:    defaultWidgetLimit,
+		Font:     template.CSS(widgetFonts["sans"]),
+		FontSize: 14,
+	}
+	if state != "ALL" {
+		opts.Title += " in " + preferences.GetStateName(state)
+	}
+
+	if v := query.Get("title"); v != "" {
+		if len(v) > maxWidgetTitle {
+			return opts, fmt.Errorf("title must be at most %d characters", maxWidgetTitle)
+		}
+		opts.Title = v
+	}
+
+	var err error
+	if opts.Limit, err = intParam(query, "limit", opts.Limit, 1, maxWidgetLimit); err != nil {
+		return opts, err
+	}
+	if opts.Days, err = intParam(query, "days", 0, 0, 366); err != nil {
+		return opts, err
+	}
+	if opts.FontSize, err = intParam(query, "size", opts.FontSize, 10, 24); err != nil {
+		return opts, err
+	}
+
+	theme := query.Get("theme")
+	if theme == "" {
+		theme = "light"
+	}
+	colors, ok := widgetThemes[theme]
+	if !ok {
+		return opts, fmt.Errorf("theme must be light or dark")
+	}
+	for param, color := range map[string]*string{"accent": &colors.Accent, "bg": &colors.Background, "text": &colors.Text} {
+		if v := query.Get(param); v != "" {
+			if !hexColor.MatchString(v) {
+				return opts, fmt.Errorf("%s must be a hex color without \"#\", like 2e7d32", param)
+			}
+			*color = "#" + v
+		}
+	}
+	opts.Colors = colors
+
+	if v := query.Get("font"); v != "" {
+		font, ok := widgetFonts[v]
+		if !ok {
+			return opts, fmt.Errorf("font must be sans, serif, or mono")
+		}
+		opts.Font = template.CSS(font)
+	}
+	return opts, nil
+}
+
+// intParam reads an integer query parameter in [low, high], or def when it's absent
+func intParam(query url.Values, name string, def, low, high int) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < low || n > high {
+		return 0, fmt.Errorf("%s must be a number from %d to %d", name, low, high)
+	}
+	return n, nil
+}
+
+// widgetLink returns an event's source link tagged as coming from the widget
+func widgetLink(sourceURL string) string {
+	return links.URL(sourceURL, links.SourceWidget, links.CampaignWidget)
+}
+
+// upcomingEvents returns up to opts.Limit events in state (or all states) that haven't
+// happened yet, soonest first. Events on today's date are still upcoming.
+func upcomingEvents(events []*event.Event, state string, opts widgetOptions, now time.Time) []*event.Event {
+	today := now.UTC().Truncate(24 * time.Hour)
+	var upcoming []*event.Event
+	for _, evt := range events {
+		if state != "ALL" && !strings.EqualFold(evt.State, state) {
+			continue
+		}
+		if evt.IsPastEventAt(today) || !evt.IsWithinDaysAt(opts.Days, now) {
+			continue
+		}
+		upcoming = append(upcoming, evt)
+	}
+	event.SortByDate(upcoming)
+	if len(upcoming) > opts.Limit {
+		upcoming = upcoming[:opts.Limit]
+	}
+	return upcoming
+}
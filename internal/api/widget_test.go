@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func newWidgetServer(now time.Time) *Server {
+	date := func(days int) string { return now.AddDate(0, 0, days).Format("Jan 2 2006") }
+	events := []*event.Event{
+		{ID: "past", State: "NV", Title: "Last Month", DateText: date(-30)},
+		{ID: "soon", State: "NV", Title: "Wolf Creek", DateText: date(3), City: "Mesquite", SourceURL: "https://vgagolf.org/e/1"},
+		{ID: "later", State: "NV", Title: "Chimera <Open>", DateText: date(60)},
+		{ID: "ca", State: "CA", Title: "Pebble Beach", DateText: date(10)},
+	}
+	return New(Options{
+		Prefs:  func(ctx context.Context) (preferences.Preferences, error) { return preferences.NewPreferences(), nil },
+		Events: func() ([]*event.Event, error) { return events, nil },
+	})
+}
+
+func TestWidget(t *testing.T) {
+	s := newWidgetServer(time.Now())
+
+	rec := get(t, s, "/widget/nv", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Upcoming VGA Golf Events in Nevada") {
+		t.Error("default title should name the state")
+	}
+	if strings.Contains(body, "Last Month") || strings.Contains(body, "Pebble Beach") {
+		t.Error("widget should only list upcoming events in the state")
+	}
+	if strings.Index(body, "Wolf Creek") > strings.Index(body, "Chimera") {
+		t.Error("events should be soonest first")
+	}
+	if !strings.Contains(body, "Chimera &lt;Open&gt;") {
+		t.Error("event titles should be escaped")
+	}
+	if strings.Contains(body, "<script") {
+		t.Error("widget must not use JavaScript")
+	}
+	if rec.Header().Get("Cache-Control") != "public, max-age=900" || rec.Header().Get("ETag") == "" {
+		t.Errorf("widget should be cacheable: %v", rec.Header())
+	}
+
+	body = get(t, s, "/widget/NV?days=30&limit=1", "").Body.String()
+	if !strings.Contains(body, "Wolf Creek") || strings.Contains(body, "Chimera") {
+		t.Error("days and limit should narrow the list")
+	}
+
+	body = get(t, s, "/widget/ALL?theme=dark&accent=ff0000&font=serif&title=Club+Events", "").Body.String()
+	for _, want := range []string{"Pebble Beach", "#ff0000", "Georgia", "Club Events", "#1e1e1e"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("styled ALL widget missing %q", want)
+		}
+	}
+}
+
+func TestWidgetNotModified(t *testing.T) {
+	s := newWidgetServer(time.Now())
+	etag := get(t, s, "/widget/NV", "").Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, "/widget/NV", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("matching ETag: status %d with %d bytes, want 304", rec.Code, rec.Body.Len())
+	}
+}
+
+func TestWidgetRejectsBadParams(t *testing.T) {
+	s := newWidgetServer(time.Now())
+
+	for _, query := range []string{
+		"accent=" + url.QueryEscape("red;}body{display:none"),
+		"bg=12345",
+		"theme=neon",
+		"font=comic",
+		"limit=0",
+		"limit=100",
+		"size=abc",
+		"title=" + strings.Repeat("x", maxWidgetTitle+1),
+	} {
+		if rec := get(t, s, "/widget/NV?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, rec.Code)
+		}
+	}
+	if rec := get(t, s, "/widget/XX", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid state: status %d, want 400", rec.Code)
+	}
+}
+
+func TestUpcomingEventsIncludesToday(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC)
+	events := []*event.Event{
+		{ID: "today", State: "NV", DateText: "Jun 10 2026"},
+		{ID: "yesterday", State: "NV", DateText: "Jun 9 2026"},
+		{ID: "undated", State: "NV", DateText: "TBD"},
+	}
+	got := upcomingEvents(events, "NV", widgetOptions{Limit: 5}, now)
+	ids := make([]string, len(got))
+	for i, evt := range got {
+		ids[i] = evt.ID
+	}
+	if fmt.Sprint(ids) != "[today undated]" {
+		t.Errorf("upcoming = %v, want [today undated]", ids)
+	}
+}
@@ -0,0 +1,81 @@
+// Package buildinfo holds the version, commit, and build date of the running binary.
+// Releases set them with the linker:
+//
+//	go build -ldflags "-X github.com/pfrederiksen/vga-events/internal/buildinfo.Version=v1.2.3 \
+//	  -X github.com/pfrederiksen/vga-events/internal/buildinfo.Commit=abc1234 \
+//	  -X github.com/pfrederiksen/vga-events/internal/buildinfo.Date=2026-01-02T03:04:05Z" ./cmd/...
+//
+// A plain go build from a checkout leaves them unset; the commit and date then come
+// from the VCS information Go records in the binary.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time with -ldflags -X
+var (
+	Version = "dev"
+	Commit  = "none"
+	Date    = "unknown"
+)
+
+// readBuildInfo returns the build information Go embedded; tests replace it
+var readBuildInfo = debug.ReadBuildInfo
+
+// Info describes a build
+type Info struct {
+	Version   string
+	Commit    string
+	Date      string
+	GoVersion string
+	Modified  bool // Built from a checkout with uncommitted changes
+}
+
+// Get returns the running binary's build information
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	bi, ok := readBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "none" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "unknown" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// Short returns the version, or for development builds "dev-" and the commit
+// (e.g. "dev-0123456789ab-dirty"), to tell apart reports from different builds
+func (i Info) Short() string {
+	if i.Version != "dev" || i.Commit == "none" {
+		return i.Version
+	}
+	short := "dev-" + i.Commit[:min(len(i.Commit), 12)]
+	if i.Modified {
+		short += "-dirty"
+	}
+	return short
+}
+
+// String returns the --version output for the named binary
+func (i Info) String(name string) string {
+	commit := i.Commit
+	if i.Modified {
+		commit += " (modified)"
+	}
+	return fmt.Sprintf("%s version %s\ncommit: %s\nbuilt: %s\ngo: %s\n", name, i.Version, commit, i.Date, i.GoVersion)
+}
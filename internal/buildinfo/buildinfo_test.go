@@ -0,0 +1,72 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	vcs := &debug.BuildInfo{Settings: []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+		{Key: "vcs.time", Value: "2026-01-02T03:04:05Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}}
+
+	tests := []struct {
+		name                  string
+		version, commit, date string
+		build                 *debug.BuildInfo
+		want                  Info
+		short                 string
+	}{
+		{
+			name:    "linker flags win",
+			version: "v1.2.3", commit: "abc1234", date: "2026-05-06T00:00:00Z",
+			build: vcs,
+			want:  Info{Version: "v1.2.3", Commit: "abc1234", Date: "2026-05-06T00:00:00Z", Modified: true},
+			short: "v1.2.3",
+		},
+		{
+			name:    "checkout build uses VCS info",
+			version: "dev", commit: "none", date: "unknown",
+			build: vcs,
+			want:  Info{Version: "dev", Commit: "0123456789abcdef0123", Date: "2026-01-02T03:04:05Z", Modified: true},
+			short: "dev-0123456789ab-dirty",
+		},
+		{
+			name:    "no build info",
+			version: "dev", commit: "none", date: "unknown",
+			want:  Info{Version: "dev", Commit: "none", Date: "unknown"},
+			short: "dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origVersion, origCommit, origDate, origRead := Version, Commit, Date, readBuildInfo
+			defer func() { Version, Commit, Date, readBuildInfo = origVersion, origCommit, origDate, origRead }()
+			Version, Commit, Date = tt.version, tt.commit, tt.date
+			readBuildInfo = func() (*debug.BuildInfo, bool) { return tt.build, tt.build != nil }
+
+			got := Get()
+			got.GoVersion = ""
+			if got != tt.want {
+				t.Errorf("Get() = %+v, want %+v", got, tt.want)
+			}
+			if short := got.Short(); short != tt.short {
+				t.Errorf("Short() = %q, want %q", short, tt.short)
+			}
+		})
+	}
+}
+
+func TestInfoString(t *testing.T) {
+	info := Info{Version: "v1.2.3", Commit: "abc1234", Date: "2026-05-06T00:00:00Z", GoVersion: "go1.24.0", Modified: true}
+	got := info.String("vga-events-bot")
+	for _, want := range []string{"vga-events-bot version v1.2.3\n", "commit: abc1234 (modified)\n", "built: 2026-05-06T00:00:00Z\n", "go: go1.24.0\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, missing %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package calendar
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// BulkOptions selects the events of a bulk export and how they're split into files
+type BulkOptions struct {
+	// Events are per-event options by event ID, as for GenerateBulkICSWithOptions
+	Events map[string]*EventOptions
+	// Statuses keeps only events whose Events status is one of these; empty keeps all
+	Statuses []string
+	// From and To keep only events dated within them, inclusive; a zero time leaves that
+	// end open. Events without a parseable date are left out when either is set.
+	From, To time.Time
+	// PerState splits the export into one calendar per state, zipped together
+	PerState bool
+}
+
+// BulkFile is a generated bulk export: one .ics calendar, or a .zip of them per state
+type BulkFile struct {
+	Name    string
+	Content []byte
+	Events  int      // Events included
+	States  []string // States of the included events, sorted
+}
+
+// FilterBulk returns the events matching opts' status and date filters, in order
+func FilterBulk(events []*event.Event, opts BulkOptions) []*event.Event {
+	var selected []*event.Event
+	for _, evt := range events {
+		if len(opts.Statuses) > 0 {
+			eventOpts := opts.Events[evt.ID]
+			if eventOpts == nil || !slices.Contains(opts.Statuses, eventOpts.Status) {
+				continue
+			}
+		}
+		if !opts.From.IsZero() || !opts.To.IsZero() {
+			date := event.ParseDate(evt.DateText)
+			if date.IsZero() || (!opts.From.IsZero() && date.Before(opts.From)) || (!opts.To.IsZero() && date.After(opts.To)) {
+				continue
+			}
+		}
+		selected = append(selected, evt)
+	}
+	return selected
+}
+
+// GenerateBulkFile exports the events matching opts as baseName.ics, or with PerState as
+// baseName.zip holding a baseName-STATE.ics calendar for each state. Returns nil if no
+// event matches.
+func GenerateBulkFile(events []*event.Event, calendarName, baseName string, opts BulkOptions) (*BulkFile, error) {
+	events = FilterBulk(events, opts)
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	byState := make(map[string][]*event.Event)
+	for _, evt := range events {
+		byState[evt.State] = append(byState[evt.State], evt)
+	}
+	states := make([]string, 0, len(byState))
+	for state := range byState {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	file := &BulkFile{Events: len(events), States: states}
+	if !opts.PerState {
+		file.Name = baseName + ".ics"
+		file.Content = []byte(GenerateBulkICSWithOptions(events, calendarName, opts.Events))
+		return file, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, state := range states {
+		w, err := zw.Create(fmt.Sprintf("%s-%s.ics", baseName, state))
+		if err != nil {
+			return nil, fmt.Errorf("adding %s calendar: %w", state, err)
+		}
+		name := state
+		if calendarName != "" {
+			name = calendarName + " - " + state
+		}
+		if _, err := w.Write([]byte(GenerateBulkICSWithOptions(byState[state], name, opts.Events))); err != nil {
+			return nil, fmt.Errorf("writing %s calendar: %w", state, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing zip: %w", err)
+	}
+	file.Name = baseName + ".zip"
+	file.Content = buf.Bytes()
+	return file, nil
+}
@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestGenerateBulkFile(t *testing.T) {
+	events := []*event.Event{
+		{ID: "nv1", State: "NV", Title: "Chimera Golf Club", DateText: "Apr 4 2026"},
+		{ID: "nv2", State: "NV", Title: "Wolf Creek", DateText: "May 9 2026"},
+		{ID: "ca1", State: "CA", Title: "Pebble Beach", DateText: "Apr 18 2026"},
+		{ID: "az1", State: "AZ", Title: "Troon North", DateText: "TBD"},
+	}
+	opts := BulkOptions{
+		Events: map[string]*EventOptions{
+			"nv1": {Status: "registered"},
+			"nv2": {Status: "registered"},
+			"ca1": {Status: "interested"},
+			"az1": {Status: "registered"},
+		},
+	}
+
+	t.Run("status filter", func(t *testing.T) {
+		opts := opts
+		opts.Statuses = []string{"registered"}
+		file, err := GenerateBulkFile(events, "Mine", "vga-events", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Name != "vga-events.ics" || file.Events != 3 || !reflect.DeepEqual(file.States, []string{"AZ", "NV"}) {
+			t.Errorf("got %s with %d events in %v", file.Name, file.Events, file.States)
+		}
+		if !strings.Contains(string(file.Content), "✅ VGA Golf - Chimera Golf Club") {
+			t.Error("events should be labelled with their status")
+		}
+	})
+
+	t.Run("date range", func(t *testing.T) {
+		opts := opts
+		opts.From = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		opts.To = time.Date(2026, 4, 30, 23, 59, 59, 0, time.UTC)
+		got := FilterBulk(events, opts)
+		if len(got) != 2 || got[0].ID != "nv1" || got[1].ID != "ca1" {
+			t.Errorf("FilterBulk() = %v, want nv1 and ca1 (undated events are left out)", got)
+		}
+	})
+
+	t.Run("per state zip", func(t *testing.T) {
+		opts := opts
+		opts.PerState = true
+		file, err := GenerateBulkFile(events, "VGA", "vga-events", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(file.Content), int64(len(file.Content)))
+		if err != nil {
+			t.Fatalf("%s isn't a zip: %v", file.Name, err)
+		}
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		if want := []string{"vga-events-AZ.ics", "vga-events-CA.ics", "vga-events-NV.ics"}; file.Name != "vga-events.zip" || !reflect.DeepEqual(names, want) {
+			t.Errorf("got %s with %v, want vga-events.zip with %v", file.Name, names, want)
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		opts := opts
+		opts.Statuses = []string{"maybe"}
+		if file, err := GenerateBulkFile(events, "", "vga-events", opts); file != nil || err != nil {
+			t.Errorf("GenerateBulkFile() = %v, %v, want nil", file, err)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
 )
 
 // EventOptions contains optional configuration for ICS generation
@@ -152,7 +153,7 @@ func writeEvent(ics *strings.Builder, evt *event.Event, opts *EventOptions) {
 	ics.WriteString(fmt.Sprintf("LOCATION:%s\r\n", escapeICS(location)))
 
 	// URL - link to registration
-	ics.WriteString("URL:https://vgagolf.org/state-events\r\n")
+	ics.WriteString(fmt.Sprintf("URL:%s\r\n", links.URL(links.RegistrationURL, links.SourceCalendar, links.CampaignCalendar)))
 
 	// STATUS - confirmed
 	ics.WriteString("STATUS:CONFIRMED\r\n")
@@ -219,7 +220,7 @@ func buildDescription(evt *event.Event, opts *EventOptions) string {
 	}
 
 	// Registration link
-	desc.WriteString("\nRegister at: https://vgagolf.org/state-events")
+	desc.WriteString("\nRegister at: " + links.URL(links.RegistrationURL, links.SourceCalendar, links.CampaignCalendar))
 
 	return desc.String()
 }
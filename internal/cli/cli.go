@@ -1,12 +1,19 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/buildinfo"
+	"github.com/pfrederiksen/vga-events/internal/clock"
+	"github.com/pfrederiksen/vga-events/internal/errreport"
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/plugin"
 	"github.com/pfrederiksen/vga-events/internal/scraper"
 	"github.com/pfrederiksen/vga-events/internal/storage"
 	"github.com/spf13/cobra"
@@ -29,14 +36,25 @@ var (
 	flagShowAll    bool
 	flagVersion    bool
 	flagSort       string
-)
 
-var (
-	version = "dev"
-	commit  = "none"
-	date    = "unknown"
+	flagRequestInterval time.Duration
+	flagContact         string
+	flagCompress        bool
+	flagHistory         bool
+	flagErrorDSN        string
+	flagConfirmRemovals int
+	flagPlugins         string
+	flagRunID           string
+	flagRetentionDays   int
+	flagArchiveRemoved  bool
 )
 
+// availablePlugins are the plugins compiled in by main; --plugins turns them on
+var availablePlugins []plugin.Plugin
+
+// clk is the clock user-events judges time filters and queues digests by
+var clk clock.Clock = clock.System
+
 // NewRootCmd creates the root command
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -56,13 +74,23 @@ Tracks events across runs and reports only new events since last check.`,
 	cmd.Flags().BoolVar(&flagShowAll, "show-all", false, "Show all events, not just new ones")
 	cmd.Flags().StringVar(&flagSort, "sort", "date", "Sort order: date, state, or title")
 	cmd.Flags().BoolVarP(&flagVersion, "version", "v", false, "Print version information")
+	cmd.Flags().BoolVar(&flagCompress, "compress", false, "Store snapshots gzip-compressed (.json.gz); both formats are always readable")
+	cmd.Flags().BoolVar(&flagHistory, "history", false, "Append changed events to a delta history file on every snapshot save")
+	cmd.Flags().DurationVar(&flagRequestInterval, "request-interval", scraper.DefaultHostInterval, "Minimum time between requests to the same host")
+	cmd.Flags().StringVar(&flagContact, "contact", os.Getenv("VGA_EVENTS_CONTACT"), "Contact email or URL sent in the User-Agent (or env: VGA_EVENTS_CONTACT)")
+	cmd.Flags().IntVar(&flagConfirmRemovals, "confirm-removals", 2, "Report an event removed only after this many consecutive scrapes miss it (1 reports it at once)")
+	cmd.Flags().StringVar(&flagErrorDSN, "error-dsn", os.Getenv("ERROR_REPORT_DSN"), "Sentry DSN or rollbar://token to report scrape failures to (or env: ERROR_REPORT_DSN)")
+	cmd.Flags().IntVar(&flagRetentionDays, "removed-retention-days", event.DefaultRemovedRetentionDays, "Days a removed event is kept in the snapshot, so it's reported as restored rather than new if it comes back")
+	cmd.Flags().BoolVar(&flagArchiveRemoved, "archive-removed", false, "Move removed events past --removed-retention-days to the archive (removed_archive.jsonl) instead of deleting them")
+	cmd.Flags().StringVar(&flagRunID, "run-id", os.Getenv("GITHUB_RUN_ID"), "Run ID recorded in the run log (runs.jsonl) with what this check parsed (or env: GITHUB_RUN_ID)")
+	cmd.Flags().StringVar(&flagPlugins, "plugins", os.Getenv("VGA_PLUGINS"), "Comma-separated plugins to run on new and removed events (or env: VGA_PLUGINS)")
+
+	cmd.AddCommand(newPrefsCmd(), newDeliveryReportCmd(), newClickReportCmd(), newErrorIssuesCmd(), newHeartbeatCmd(), newWatchdogCmd(), newReplayCmd(), newUserEventsCmd(), newServeAPICmd(), newServeWebCmd(), newExportCmd(), newSnapshotCmd())
 
 	// Make check-state optional if version is requested
 	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		if flagVersion {
-			fmt.Printf("vga-events version %s\n", version)
-			fmt.Printf("commit: %s\n", commit)
-			fmt.Printf("built: %s\n", date)
+			fmt.Print(buildinfo.Get().String("vga-events"))
 			os.Exit(ExitSuccess)
 		}
 		// Validate check-state is provided if not version
@@ -92,8 +120,33 @@ func filterEventsByState(events []*event.Event, state string) []*event.Event {
 	return filtered
 }
 
+// renamedEvents returns the events renames point to, for looking up their details
+func renamedEvents(renames []*event.EventChange, snapshot *event.Snapshot) []*event.Event {
+	var events []*event.Event
+	for _, change := range renames {
+		if evt, ok := snapshot.Events[change.EventID]; ok {
+			events = append(events, evt)
+		}
+	}
+	return events
+}
+
+// assignShortCodes sets each event's short code ("NV-417"). Codes recorded in the saved
+// snapshot are kept so an event's code doesn't change between runs.
+func assignShortCodes(store *storage.Storage, state string, events []*event.Event, verbose bool) {
+	previous, err := store.LoadSnapshot(state)
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Could not load snapshot for short codes: %v\n", err)
+		}
+		previous = nil
+	}
+
+	event.AssignShortCodes(events, previous)
+}
+
 // handleShowAll handles the --show-all flag to display all events
-func handleShowAll(currentEvents []*event.Event, state string, format OutputFormat, verbose bool, sortOrder SortOrder, store *storage.Storage) error {
+func handleShowAll(ctx context.Context, currentEvents []*event.Event, state string, format OutputFormat, verbose bool, sortOrder SortOrder, store *storage.Storage) error {
 	// Filter events by state
 	filteredEvents := make([]*event.Event, 0)
 	stateMap := make(map[string][]*event.Event)
@@ -129,7 +182,7 @@ func handleShowAll(currentEvents []*event.Event, state string, format OutputForm
 	eventsToSave := filterEventsByState(currentEvents, state)
 
 	// Save snapshot with only the filtered events
-	if err := store.CreateSnapshotFromEvents(eventsToSave, state); err != nil {
+	if err := store.CreateSnapshotFromEvents(ctx, eventsToSave, state); err != nil {
 		return fmt.Errorf("saving snapshot: %w", err)
 	}
 
@@ -192,38 +245,76 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid sort order: %s (must be 'date', 'state', or 'title')", flagSort)
 	}
 
+	if flagRetentionDays < 1 {
+		return fmt.Errorf("invalid --removed-retention-days: %d (must be at least 1)", flagRetentionDays)
+	}
+
 	if flagVerbose {
 		fmt.Fprintf(os.Stderr, "Checking state: %s\n", state)
 		fmt.Fprintf(os.Stderr, "Data directory: %s\n", flagDataDir)
 		fmt.Fprintf(os.Stderr, "Sort order: %s\n", sortOrder)
 	}
 
+	hooks, err := plugin.Select(availablePlugins, flagPlugins)
+	if err != nil {
+		return err
+	}
+
 	// Initialize storage
-	store, err := storage.New(flagDataDir)
+	store, err := storage.NewWithOptions(flagDataDir, storage.Options{
+		Compress: flagCompress,
+		History:  flagHistory,
+	})
 	if err != nil {
 		return fmt.Errorf("initializing storage: %w", err)
 	}
 
+	// Report scrape failures to Sentry/Rollbar if configured, and log them for error-issues
+	reporter, err := errreport.New(errreport.Options{DSN: flagErrorDSN, Release: buildinfo.Get().Short(), Component: "cli", Log: store, RunID: flagRunID})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: error reporting disabled: %v\n", err)
+	}
+
 	// Initialize scraper
-	sc := scraper.New()
+	sc := scraper.NewWithOptions(scraper.Options{
+		HostInterval: flagRequestInterval,
+		Contact:      flagContact,
+	})
 
 	// Fetch current events
 	if flagVerbose {
-		fmt.Fprintf(os.Stderr, "Fetching events from %s\n", scraper.StateEventsURL)
+		fmt.Fprintf(os.Stderr, "Fetching events from %s and %s\n", scraper.StateEventsURL, scraper.MajorEventsURL)
 	}
+	started := time.Now()
 
-	currentEvents, err := sc.FetchEvents()
+	ctx := cmd.Context()
+	fetched, err := sc.FetchAll(ctx)
 	if err != nil {
+		if ctx.Err() == nil {
+			reporter.Error(ctx, err, errreport.Context{URL: scraper.StateEventsURL})
+		}
 		return fmt.Errorf("fetching events: %w", err)
 	}
+	currentEvents := fetched.Events
+
+	// Some pages failed: continue with partial results, keeping those pages' events
+	var failedPages []string
+	for _, warning := range fetched.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipped page %v\n", warning)
+		reporter.Error(ctx, warning, errreport.Context{URL: warning.URL})
+		failedPages = append(failedPages, warning.URL)
+	}
 
 	if flagVerbose {
 		fmt.Fprintf(os.Stderr, "Fetched %d total events\n", len(currentEvents))
 	}
 
+	// Give events short reference codes, keeping the codes from the saved snapshot
+	assignShortCodes(store, state, currentEvents, flagVerbose)
+
 	// Handle --show-all mode
 	if flagShowAll {
-		return handleShowAll(currentEvents, state, format, flagVerbose, sortOrder, store)
+		return handleShowAll(ctx, currentEvents, state, format, flagVerbose, sortOrder, store)
 	}
 
 	// Load previous snapshot
@@ -239,8 +330,26 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Compute diff
-	diff := event.Diff(previous, currentEvents, state)
+	// Compute diff and the next snapshot
+	update := event.UpdateSnapshot(previous, filterEventsByState(currentEvents, state), event.UpdateOptions{
+		State:           state,
+		ConfirmRemovals: flagConfirmRemovals,
+		RetentionDays:   flagRetentionDays,
+		Now:             clk.Now(),
+		FailedPages:     failedPages,
+	})
+	diff, newSnapshot, changedEvents := update.Diff, update.Snapshot, update.Changes
+	if flagVerbose && len(diff.Missing) > 0 {
+		fmt.Fprintf(os.Stderr, "%d event(s) missing from this scrape, not yet reported as removed\n", len(diff.Missing))
+	}
+
+	// Let plugins enrich the events before they're saved and reported
+	if err := hooks.EventNew(ctx, diff.NewEvents); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := hooks.EventRemoved(ctx, diff.RemovedEvents); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
 	// Sort new events
 	sortEvents(diff.NewEvents, sortOrder)
@@ -252,52 +361,21 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Filter events by state before saving snapshot
-	eventsToSave := filterEventsByState(currentEvents, state)
-
-	// Create new snapshot with filtered events
-	newSnapshot := event.CreateSnapshot(eventsToSave, time.Now().UTC().Format(time.RFC3339))
-
-	// Detect changes between snapshots (date/title/city changes)
-	var changedEvents []*event.EventChange
-	if previous != nil {
-		// Build current events map for change detection
-		currentEventsMap := make(map[string]*event.Event)
-		for _, evt := range eventsToSave {
-			currentEventsMap[evt.ID] = evt
-		}
-
-		// Compare snapshots to detect changes
-		changedEvents = event.CompareSnapshots(previous.Events, currentEventsMap, previous.StableIndex, newSnapshot.StableIndex)
-
-		// Filter out "new" and "removed" change types - those are handled separately
-		filteredChanges := make([]*event.EventChange, 0)
-		for _, change := range changedEvents {
-			if change.ChangeType != "new" && change.ChangeType != "removed" {
-				filteredChanges = append(filteredChanges, change)
+	// Archive removed events past the retention window if asked. A failed archive
+	// keeps them in the snapshot for the next run to try again.
+	if flagArchiveRemoved && len(update.Expired) > 0 {
+		if err := store.ArchiveRemoved(state, update.Expired); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not archive removed events: %v\n", err)
+			for _, evt := range update.Expired {
+				newSnapshot.RemovedEvents[evt.ID] = evt
 			}
-		}
-		changedEvents = filteredChanges
-
-		// Store changes in the new snapshot's ChangeLog
-		newSnapshot.ChangeLog = append(newSnapshot.ChangeLog, changedEvents...)
-
-		// Keep only the most recent 100 changes to prevent unbounded growth
-		if len(newSnapshot.ChangeLog) > 100 {
-			newSnapshot.ChangeLog = newSnapshot.ChangeLog[len(newSnapshot.ChangeLog)-100:]
+		} else if flagVerbose {
+			fmt.Fprintf(os.Stderr, "Archived %d removed event(s)\n", len(update.Expired))
 		}
 	}
 
-	// Store removed events in snapshot (kept for 30 days)
-	if len(diff.RemovedEvents) > 0 {
-		newSnapshot.StoreRemovedEvents(diff.RemovedEvents)
-	}
-
-	// Clean up old removed events (>30 days old)
-	newSnapshot.CleanupRemovedEvents()
-
 	// Save updated snapshot
-	if err := store.SaveSnapshot(newSnapshot, state); err != nil {
+	if err := store.SaveSnapshot(ctx, newSnapshot, state); err != nil {
 		return fmt.Errorf("saving snapshot: %w", err)
 	}
 
@@ -307,11 +385,14 @@ func runCheck(cmd *cobra.Command, args []string) error {
 
 	// Prepare output
 	result := &OutputResult{
-		CheckedAt:     time.Now().UTC(),
-		NewEvents:     diff.NewEvents,
-		RemovedEvents: diff.RemovedEvents,
-		ChangedEvents: changedEvents,
-		EventCount:    len(diff.NewEvents),
+		CheckedAt:      time.Now().UTC(),
+		NewEvents:      diff.NewEvents,
+		RemovedEvents:  diff.RemovedEvents,
+		RestoredEvents: diff.RestoredEvents,
+		RenamedEvents:  renamedEvents(diff.Renames, newSnapshot),
+		ChangedEvents:  changedEvents,
+		NewStates:      event.NewStates(previous, diff.NewEvents),
+		EventCount:     len(diff.NewEvents),
 	}
 
 	// Determine states checked
@@ -331,6 +412,13 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Record what this run parsed for the maintainer's run report
+	if !flagRefresh {
+		if err := store.AppendRun(runRecord(started, fetched, result)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: recording run: %v\n", err)
+		}
+	}
+
 	// In refresh mode, don't output new events
 	if flagRefresh {
 		if format == FormatText {
@@ -338,6 +426,7 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		} else {
 			// Still output JSON but with zero new events
 			result.NewEvents = nil
+			result.NewStates = nil
 			result.EventCount = 0
 			result.ByState = nil
 			_ = WriteOutput(os.Stdout, result, format, flagVerbose)
@@ -351,8 +440,8 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("writing output: %w", err)
 	}
 
-	// Set exit code based on whether new events were found
-	if len(diff.NewEvents) > 0 {
+	// Set exit code based on whether new (or restored or renamed) events were found
+	if len(diff.NewEvents) > 0 || len(diff.RestoredEvents) > 0 || len(diff.Renames) > 0 {
 		os.Exit(ExitNewEvents)
 	} else {
 		os.Exit(ExitSuccess)
@@ -361,14 +450,42 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// Execute runs the CLI
-func Execute(v, c, d string) {
-	// Set version information
-	version = v
-	commit = c
-	date = d
+// runRecord summarizes a check for the run log: events parsed, the diff, skipped pages
+// and events whose date didn't parse, and how long it took
+func runRecord(started time.Time, fetched *scraper.Result, result *OutputResult) *storage.RunRecord {
+	r := &storage.RunRecord{
+		At:       started.UTC().Format(time.RFC3339),
+		RunID:    flagRunID,
+		Source:   storage.RunSourceScrape,
+		Events:   len(fetched.Events),
+		New:      len(result.NewEvents),
+		Changed:  len(result.ChangedEvents),
+		Removed:  len(result.RemovedEvents),
+		Restored: len(result.RestoredEvents),
+		Seconds:  time.Since(started).Seconds(),
+	}
+	for _, warning := range fetched.Warnings {
+		r.AddWarning(fmt.Sprintf("skipped page %v", warning))
+	}
+	for _, evt := range fetched.Events {
+		if event.ParseDate(evt.DateText).IsZero() {
+			r.AddWarning(fmt.Sprintf("no date parsed for %s - %s (%q)", evt.State, evt.Title, evt.DateText))
+		}
+	}
+	return r
+}
 
-	if err := NewRootCmd().Execute(); err != nil {
+// Execute runs the CLI with the given compiled-in plugins
+func Execute(plugins ...plugin.Plugin) {
+	availablePlugins = plugins
+	event.SetClock(clk)
+
+	// Interrupts cancel in-flight requests and skip the snapshot save
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := NewRootCmd().ExecuteContext(ctx)
+	stop()
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitError)
 	}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagClickDataDir string
+	flagClickDays    int
+	flagClickTop     int
+)
+
+// newClickReportCmd creates the "click-report" command summarizing tracked link clicks
+func newClickReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "click-report",
+		Short: "Summarize clicks on tracked links per channel, message type, and event",
+		Long: `Reads the click log (clicks.jsonl) that serve-api appends to when someone follows
+a tracked /r/ link, and prints click counts per channel and message type, followed
+by the most-clicked events. Links are tracked when the notifiers run with
+--click-url pointing at serve-api.`,
+		Args: cobra.NoArgs,
+		RunE: runClickReport,
+	}
+
+	cmd.Flags().StringVar(&flagClickDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the click log")
+	cmd.Flags().IntVar(&flagClickDays, "days", 30, "Only count clicks from the last N days (0 for all)")
+	cmd.Flags().IntVar(&flagClickTop, "top", 10, "Number of most-clicked events to list")
+
+	return cmd
+}
+
+// runClickReport loads the click log and prints the report
+func runClickReport(cmd *cobra.Command, args []string) error {
+	store, err := storage.New(flagClickDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+
+	clicks, err := store.LoadClicks()
+	if err != nil {
+		return err
+	}
+	if len(clicks) == 0 {
+		fmt.Println("No clicks recorded")
+		return nil
+	}
+
+	since := ""
+	if flagClickDays > 0 {
+		since = time.Now().UTC().AddDate(0, 0, -flagClickDays).Format(time.RFC3339)
+	}
+
+	// Event titles are a nicety; the report works from IDs alone
+	titles := make(map[string]string)
+	if snapshot, err := store.LoadSnapshot(StateAll); err == nil {
+		for id, evt := range snapshot.Events {
+			titles[id] = evt.Title
+		}
+	}
+
+	writeClickReport(os.Stdout, flagClickDays, storage.SummarizeClicks(clicks, since), titles, flagClickTop)
+	return nil
+}
+
+// writeClickReport writes click counts per channel, campaign, and top events
+func writeClickReport(w io.Writer, days int, stats *storage.ClickStats, titles map[string]string, top int) {
+	if days > 0 {
+		fmt.Fprintf(w, "Clicks: last %d days\n", days)
+	} else {
+		fmt.Fprintln(w, "Clicks: all time")
+	}
+	if stats.Total == 0 {
+		fmt.Fprintln(w, "\nNo clicks recorded in this period")
+		return
+	}
+	fmt.Fprintf(w, "Total: %d\n", stats.Total)
+
+	fmt.Fprintln(w, "\nBy channel:")
+	for _, c := range stats.ByChannel {
+		fmt.Fprintf(w, "  %-14s %d\n", c.Key, c.Clicks)
+	}
+
+	if len(stats.ByCampaign) > 0 {
+		fmt.Fprintln(w, "\nBy message type:")
+		for _, c := range stats.ByCampaign {
+			fmt.Fprintf(w, "  %-14s %d\n", c.Key, c.Clicks)
+		}
+	}
+
+	if len(stats.ByEvent) > 0 && top > 0 {
+		fmt.Fprintln(w, "\nTop events:")
+		for i, c := range stats.ByEvent {
+			if i == top {
+				break
+			}
+			name := titles[c.Key]
+			if name == "" {
+				name = c.Key
+			}
+			fmt.Fprintf(w, "  %4d  %s\n", c.Clicks, name)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagDeliveryDataDir string
+	flagDeliveryRun     string
+	flagDeliveryAll     bool
+)
+
+// newDeliveryReportCmd creates the "delivery-report" command summarizing the delivery log
+func newDeliveryReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delivery-report",
+		Short: "Summarize sent and failed notifications per channel from the delivery log",
+		Long: `Reads the delivery log (deliveries.jsonl) that the notifiers append to in the
+data directory and prints sent, failed, and unreachable counts per channel,
+followed by each failure. Defaults to the most recent run.`,
+		Args: cobra.NoArgs,
+		RunE: runDeliveryReport,
+	}
+
+	cmd.Flags().StringVar(&flagDeliveryDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the delivery log")
+	cmd.Flags().StringVar(&flagDeliveryRun, "run", "", "Report on this run ID instead of the most recent run")
+	cmd.Flags().BoolVar(&flagDeliveryAll, "all", false, "Report on every recorded run")
+
+	return cmd
+}
+
+// runDeliveryReport loads the delivery log and prints the report
+func runDeliveryReport(cmd *cobra.Command, args []string) error {
+	store, err := storage.New(flagDeliveryDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+
+	deliveries, err := store.LoadDeliveries()
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		fmt.Println("No deliveries recorded")
+		return nil
+	}
+
+	runID := flagDeliveryRun
+	if runID == "" && !flagDeliveryAll {
+		runID = storage.LastRunID(deliveries)
+	}
+
+	writeDeliveryReport(os.Stdout, runID, storage.SummarizeDeliveries(deliveries, runID))
+	return nil
+}
+
+// writeDeliveryReport writes per-channel delivery stats and failures
+func writeDeliveryReport(w io.Writer, runID string, summary []*storage.ChannelStats) {
+	if runID == "" {
+		fmt.Fprintln(w, "Deliveries: all runs")
+	} else {
+		fmt.Fprintf(w, "Deliveries: run %s\n", runID)
+	}
+
+	if len(summary) == 0 {
+		fmt.Fprintln(w, "\nNo deliveries recorded for this run")
+		return
+	}
+
+	for _, stats := range summary {
+		fmt.Fprintf(w, "\n%s: %d attempted for %d user(s)\n", stats.Channel, stats.Total(), stats.Users)
+		fmt.Fprintf(w, "  Sent:        %d%s\n", stats.Sent, formatTypeCounts(stats.ByType))
+		fmt.Fprintf(w, "  Failed:      %d\n", stats.Failed)
+		fmt.Fprintf(w, "  Unreachable: %d\n", stats.Unreachable)
+
+		if len(stats.Failures) > 0 {
+			fmt.Fprintln(w, "  Failures:")
+			for _, d := range stats.Failures {
+				target := d.EventID
+				if target == "" {
+					target = "-"
+				}
+				fmt.Fprintf(w, "    %s  user %s  %-8s %-12s %s  %s\n", d.At, d.User, d.Type, target, d.Result, d.Error)
+			}
+		}
+	}
+}
+
+// formatTypeCounts formats sent counts per notification type, e.g. " (new 4, reminder 1)"
+func formatTypeCounts(byType map[string]int) string {
+	if len(byType) == 0 {
+		return ""
+	}
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s %d", t, byType[t]))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
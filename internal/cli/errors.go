@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/issues"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// errorIssueLabel is put on the issues opened for recurring errors
+const errorIssueLabel = "recurring-error"
+
+var (
+	flagErrorIssuesDataDir   string
+	flagErrorIssuesRepo      string
+	flagErrorIssuesToken     string
+	flagErrorIssuesThreshold int
+	flagErrorIssuesDays      int
+	flagErrorIssuesDryRun    bool
+)
+
+// newErrorIssuesCmd creates the "error-issues" command filing recurring errors on GitHub
+func newErrorIssuesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "error-issues",
+		Short: "Open or update a GitHub issue for each error that keeps recurring",
+		Long: `Reads the error log (errors.jsonl) that vga-events and the notifiers append to in
+the data directory and groups errors by fingerprint: the same root error reported
+from the same place. An error seen --threshold times in the last --days days gets a
+GitHub issue with its occurrence count, runs, and latest stack. Later runs update
+the issue when the error happens again, reopening it if it was closed.`,
+		Args: cobra.NoArgs,
+		RunE: runErrorIssues,
+	}
+
+	cmd.Flags().StringVar(&flagErrorIssuesDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the error log")
+	cmd.Flags().StringVar(&flagErrorIssuesRepo, "repo", os.Getenv("GITHUB_REPOSITORY"), "Repository to file issues in, as owner/name (or env: GITHUB_REPOSITORY)")
+	cmd.Flags().StringVar(&flagErrorIssuesToken, "github-token", os.Getenv("GITHUB_TOKEN"), "GitHub token that can create and edit issues (or env: GITHUB_TOKEN)")
+	cmd.Flags().IntVar(&flagErrorIssuesThreshold, "threshold", 5, "Occurrences before an issue is opened")
+	cmd.Flags().IntVar(&flagErrorIssuesDays, "days", 7, "Count occurrences over the last N days")
+	cmd.Flags().BoolVar(&flagErrorIssuesDryRun, "dry-run", false, "Print the issues that would be opened or updated")
+
+	return cmd
+}
+
+// runErrorIssues files the recurring errors with new occurrences since they were last filed
+func runErrorIssues(cmd *cobra.Command, args []string) error {
+	if !flagErrorIssuesDryRun && (flagErrorIssuesRepo == "" || flagErrorIssuesToken == "") {
+		return fmt.Errorf("--repo and --github-token are required (or use --dry-run)")
+	}
+
+	store, err := storage.New(flagErrorIssuesDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	records, err := store.LoadErrors()
+	if err != nil {
+		return err
+	}
+	filed, err := store.LoadErrorIssues()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	client := issues.NewClient(issues.Config{Repo: flagErrorIssuesRepo, Token: flagErrorIssuesToken})
+	count := 0
+	for _, e := range storage.GroupErrors(records, now.AddDate(0, 0, -flagErrorIssuesDays)) {
+		if e.Count < flagErrorIssuesThreshold {
+			break // Sorted most frequent first
+		}
+		prev := filed.Get(e.Fingerprint)
+		if prev != nil && prev.LastSeen >= e.LastSeen {
+			continue // Nothing new since the issue was last updated
+		}
+		count++
+
+		title, body := formatErrorIssue(e, flagErrorIssuesDays)
+		if flagErrorIssuesDryRun {
+			action := "open"
+			if prev != nil {
+				action = fmt.Sprintf("update #%d", prev.Number)
+			}
+			fmt.Printf("Would %s: %s\n\n%s\n", action, title, body)
+			continue
+		}
+
+		number := 0
+		if prev == nil {
+			created, err := client.Create(cmd.Context(), issues.Issue{Title: title, Body: body, Labels: []string{errorIssueLabel}})
+			if err != nil {
+				return err
+			}
+			number = created.Number
+			fmt.Printf("Opened #%d: %s\n", number, title)
+		} else {
+			number = prev.Number
+			if err := client.Update(cmd.Context(), number, issues.Issue{Body: body, State: "open"}); err != nil {
+				return err
+			}
+			fmt.Printf("Updated #%d: %s\n", number, title)
+		}
+		if err := filed.Record(e.Fingerprint, number, e.LastSeen, now); err != nil {
+			return err
+		}
+	}
+
+	if count == 0 {
+		fmt.Println("No recurring errors to file")
+	}
+	return nil
+}
+
+// formatErrorIssue builds the issue title and Markdown body for a recurring error
+func formatErrorIssue(e *storage.RecurringError, days int) (string, string) {
+	message := strings.Join(strings.Fields(e.Message), " ")
+	if runes := []rune(message); len(runes) > 80 {
+		message = string(runes[:80]) + "…"
+	}
+	where, _, _ := strings.Cut(e.Location, " ← ")
+	title := fmt.Sprintf("Recurring error in %s: %s", where, message)
+
+	w := &strings.Builder{}
+	fmt.Fprintf(w, "Filed by `vga-events error-issues` from the error log. Updated when the error recurs.\n\n")
+	fmt.Fprintf(w, "| | |\n|---|---|\n")
+	fmt.Fprintf(w, "| Fingerprint | `%s` |\n", e.Fingerprint)
+	fmt.Fprintf(w, "| Reported by | %s |\n", strings.Join(e.Components, ", "))
+	fmt.Fprintf(w, "| Location | `%s` |\n", e.Location)
+	fmt.Fprintf(w, "| Type | `%s` |\n", e.Type)
+	fmt.Fprintf(w, "| Occurrences | %d in %d run(s), last %d days |\n", e.Count, e.Runs, days)
+	fmt.Fprintf(w, "| First seen | %s |\n", e.FirstSeen)
+	fmt.Fprintf(w, "| Last seen | %s |\n", e.LastSeen)
+	fmt.Fprintf(w, "\n**Latest message**\n\n```\n%s\n```\n", e.Message)
+	if e.Stack != "" {
+		fmt.Fprintf(w, "\n**Stack**\n\n```\n%s\n```\n", strings.TrimRight(e.Stack, "\n"))
+	}
+	return title, w.String()
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/calendar"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/pdf"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagExportFormat    string
+	flagExportDataDir   string
+	flagExportState     string
+	flagExportPrefsFile string
+	flagExportChat      string
+	flagExportOutput    string
+	flagExportStatus    string
+	flagExportFrom      string
+	flagExportTo        string
+	flagExportPerState  bool
+)
+
+// newExportCmd creates the "export" command that writes a printable schedule
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a printable schedule or calendar of events",
+		Long: `Writes the events in the snapshot in --data-dir as a one-page schedule (date,
+course, city, status) or a calendar. With --state, it lists that state's upcoming
+events; with --prefs-file and --chat-id, a user's tracked events (any status but
+skip), or with all three, that state's events labelled with the user's statuses.
+
+--format pdf writes the same document the bot's /export-pdf sends. --format ics
+writes an iCalendar file like /export-calendar, and can be narrowed with --status
+(e.g. registered,interested) and --from/--to (YYYY-MM-DD), or split with --per-state
+into a .zip with one calendar per state.`,
+		Args: cobra.NoArgs,
+		RunE: runExport,
+	}
+
+	cmd.Flags().StringVar(&flagExportFormat, "format", "pdf", "Output format: pdf or ics")
+	cmd.Flags().StringVar(&flagExportDataDir, "data-dir", "~/.local/share/vga-events", "Data directory for snapshots")
+	cmd.Flags().StringVar(&flagExportState, "state", "", "Only upcoming events in this state (e.g. NV)")
+	cmd.Flags().StringVar(&flagExportPrefsFile, "prefs-file", "", "Preferences JSON file, for a user's statuses")
+	cmd.Flags().StringVar(&flagExportChat, "chat-id", "", "User's chat ID in --prefs-file")
+	cmd.Flags().StringVarP(&flagExportOutput, "output", "o", "", "Output file (- for stdout; default schedule.pdf, events.ics, or events.zip)")
+	cmd.Flags().StringVar(&flagExportStatus, "status", "", "With --format ics, only events with these comma-separated statuses (needs --prefs-file)")
+	cmd.Flags().StringVar(&flagExportFrom, "from", "", "With --format ics, only events on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&flagExportTo, "to", "", "With --format ics, only events on or before this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&flagExportPerState, "per-state", false, "With --format ics, write a .zip with one calendar per state")
+
+	return cmd
+}
+
+// runExport loads the snapshot and writes the schedule
+func runExport(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(flagExportFormat)
+	if format != "pdf" && format != "ics" {
+		return fmt.Errorf("invalid format: %s (must be 'pdf' or 'ics')", flagExportFormat)
+	}
+	if format != "ics" && (flagExportStatus != "" || flagExportFrom != "" || flagExportTo != "" || flagExportPerState) {
+		return fmt.Errorf("--status, --from, --to, and --per-state need --format ics")
+	}
+	if flagExportStatus != "" && flagExportPrefsFile == "" {
+		return fmt.Errorf("--status needs --prefs-file and --chat-id")
+	}
+	state := strings.ToUpper(flagExportState)
+	if state != "" && !preferences.IsValidState(state) {
+		return fmt.Errorf("invalid state: %s", flagExportState)
+	}
+	if (flagExportPrefsFile == "") != (flagExportChat == "") {
+		return fmt.Errorf("--prefs-file and --chat-id must be given together")
+	}
+	if state == "" && flagExportChat == "" {
+		return fmt.Errorf("give --state, or --prefs-file and --chat-id")
+	}
+
+	store, err := storage.New(flagExportDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	snapshot, err := store.LoadSnapshot(StateAll)
+	if err != nil {
+		return fmt.Errorf("loading snapshot: %w", err)
+	}
+
+	var statuses map[string]string
+	if flagExportPrefsFile != "" {
+		data, err := storage.ReadFile(flagExportPrefsFile)
+		if err != nil {
+			return fmt.Errorf("reading preferences: %w", err)
+		}
+		prefs, err := preferences.FromJSON(data)
+		if err != nil {
+			return err
+		}
+		user, ok := prefs[flagExportChat]
+		if !ok {
+			return fmt.Errorf("no preferences for chat %s", flagExportChat)
+		}
+		statuses = user.EventStatuses
+	}
+
+	now := time.Now()
+	events := selectExportEvents(snapshot.Listed(), state, statuses, now)
+	if format == "ics" {
+		return writeExportICS(events, state, statuses)
+	}
+	subtitle := "Tracked events"
+	if state != "" {
+		subtitle = state + " events"
+	}
+	doc := pdf.Render(pdf.Schedule{
+		Title:     "VGA Golf Schedule",
+		Subtitle:  fmt.Sprintf("%s (%d)", subtitle, len(events)),
+		Rows:      pdf.EventRows(events, statuses),
+		Generated: now,
+	})
+
+	return writeExportOutput(doc, "schedule.pdf", len(events))
+}
+
+// writeExportICS writes the events as a calendar, narrowed by --status, --from, and --to
+func writeExportICS(events []*event.Event, state string, statuses map[string]string) error {
+	opts := calendar.BulkOptions{
+		Events:   make(map[string]*calendar.EventOptions, len(events)),
+		PerState: flagExportPerState,
+	}
+	for _, evt := range events {
+		opts.Events[evt.ID] = &calendar.EventOptions{Status: statuses[evt.ID]}
+	}
+	if flagExportStatus != "" {
+		for _, status := range strings.Split(flagExportStatus, ",") {
+			opts.Statuses = append(opts.Statuses, strings.ToLower(strings.TrimSpace(status)))
+		}
+	}
+	var err error
+	if opts.From, err = parseExportDate(flagExportFrom, "from"); err != nil {
+		return err
+	}
+	if opts.To, err = parseExportDate(flagExportTo, "to"); err != nil {
+		return err
+	}
+	if !opts.To.IsZero() {
+		opts.To = opts.To.Add(24*time.Hour - time.Second)
+	}
+
+	calendarName := "VGA Golf - Tracked Events"
+	if state != "" {
+		calendarName = "VGA Golf Events - " + state
+	}
+	file, err := calendar.GenerateBulkFile(events, calendarName, "events", opts)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return fmt.Errorf("no events match")
+	}
+	return writeExportOutput(file.Content, file.Name, file.Events)
+}
+
+// parseExportDate parses a --from or --to date; empty is the zero time
+func parseExportDate(value, flagName string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s date %q: use YYYY-MM-DD", flagName, value)
+	}
+	return t, nil
+}
+
+// writeExportOutput writes an export to --output, or defaultName if it isn't set
+func writeExportOutput(content []byte, defaultName string, count int) error {
+	output := flagExportOutput
+	if output == "" {
+		output = defaultName
+	}
+	if output == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(output, content, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d event(s) to %s\n", count, output)
+	return nil
+}
+
+// selectExportEvents returns the events to export, soonest first: the upcoming events in
+// state, or with no state, the events with a status in statuses. Skipped events are
+// always left out.
+func selectExportEvents(events map[string]*event.Event, state string, statuses map[string]string, now time.Time) []*event.Event {
+	var selected []*event.Event
+	for id, evt := range events {
+		status := statuses[id]
+		if status == preferences.EventStatusSkip {
+			continue
+		}
+		if state != "" {
+			if strings.EqualFold(evt.State, state) && !evt.IsPastEventAt(now) {
+				selected = append(selected, evt)
+			}
+		} else if status != "" {
+			selected = append(selected, evt)
+		}
+	}
+	// Map order is random, so same-day events are ordered by title
+	sort.Slice(selected, func(i, j int) bool {
+		di, dj := event.ParseDate(selected[i].DateText), event.ParseDate(selected[j].DateText)
+		if !di.Equal(dj) {
+			return compareByDate(selected[i], selected[j])
+		}
+		return selected[i].Title < selected[j].Title
+	})
+	return selected
+}
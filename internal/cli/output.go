@@ -20,14 +20,17 @@ const (
 
 // OutputResult contains data to be output
 type OutputResult struct {
-	CheckedAt     time.Time                 `json:"checked_at"`
-	States        []string                  `json:"states"`
-	NewEvents     []*event.Event            `json:"new_events"`
-	RemovedEvents []*event.Event            `json:"removed_events,omitempty"`
-	ChangedEvents []*event.EventChange      `json:"changed_events,omitempty"`
-	EventCount    int                       `json:"event_count"`
-	ByState       map[string][]*event.Event `json:"by_state,omitempty"`
-	ShowAll       bool                      `json:"show_all,omitempty"`
+	CheckedAt      time.Time                 `json:"checked_at"`
+	States         []string                  `json:"states"`
+	NewEvents      []*event.Event            `json:"new_events"`
+	RemovedEvents  []*event.Event            `json:"removed_events,omitempty"`
+	RestoredEvents []*event.Event            `json:"restored_events,omitempty"` // back after being reported removed
+	ChangedEvents  []*event.EventChange      `json:"changed_events,omitempty"`
+	RenamedEvents  []*event.Event            `json:"renamed_events,omitempty"` // current details of events in "renamed" changes
+	NewStates      []string                  `json:"new_states,omitempty"`     // states listing their first ever events
+	EventCount     int                       `json:"event_count"`
+	ByState        map[string][]*event.Event `json:"by_state,omitempty"`
+	ShowAll        bool                      `json:"show_all,omitempty"`
 }
 
 // WriteOutput writes the result in the specified format
@@ -76,6 +79,10 @@ func writeText(w io.Writer, result *OutputResult, verbose bool) error {
 			states = append(states, state)
 		}
 		sort.Strings(states)
+		newStates := make(map[string]bool, len(result.NewStates))
+		for _, state := range result.NewStates {
+			newStates[state] = true
+		}
 
 		for _, state := range states {
 			events := result.ByState[state]
@@ -83,7 +90,11 @@ func writeText(w io.Writer, result *OutputResult, verbose bool) error {
 				continue
 			}
 
-			fmt.Fprintf(w, "\n%s (%d %s):\n", state, len(events), eventLabel)
+			if newStates[state] {
+				fmt.Fprintf(w, "\n%s (%d %s, first events listed in this state):\n", state, len(events), eventLabel)
+			} else {
+				fmt.Fprintf(w, "\n%s (%d %s):\n", state, len(events), eventLabel)
+			}
 			for _, evt := range events {
 				if eventPrefix != "" {
 					fmt.Fprintf(w, "  %s: %s\n", eventPrefix, evt.Raw)
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/experiment"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// largestUsersShown is how many of the biggest users the size report lists
+const largestUsersShown = 5
+
+var (
+	flagPrefsGistID        string
+	flagPrefsGitHubToken   string
+	flagPrefsEncryptionKey string
+	flagPrefsDryRun        bool
+	flagPrefsSeenDays      int
+	flagPrefsExperiment    string
+	flagPrefsDataDir       string
+	flagPrefsEventsFile    string
+	flagPrefsFlag          string
+)
+
+// newPrefsCmd creates the "prefs" command for maintaining the bot's preferences Gist
+func newPrefsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prefs",
+		Short: "Inspect and maintain the Telegram bot preferences Gist",
+	}
+
+	cmd.PersistentFlags().StringVar(&flagPrefsGistID, "gist-id", os.Getenv("TELEGRAM_GIST_ID"), "GitHub Gist ID (or env: TELEGRAM_GIST_ID)")
+	cmd.PersistentFlags().StringVar(&flagPrefsGitHubToken, "github-token", os.Getenv("TELEGRAM_GITHUB_TOKEN"), "GitHub token with gist scope (or env: TELEGRAM_GITHUB_TOKEN)")
+	cmd.PersistentFlags().StringVar(&flagPrefsEncryptionKey, "encryption-key", os.Getenv("TELEGRAM_ENCRYPTION_KEY"), "Encryption key for sensitive fields (or env: TELEGRAM_ENCRYPTION_KEY)")
+
+	sizeCmd := &cobra.Command{
+		Use:   "size",
+		Short: "Report the serialized size of the preferences document and its largest users",
+		Args:  cobra.NoArgs,
+		RunE:  runPrefsSize,
+	}
+
+	compactCmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Prune old seen events, archive stale stats, and remove empty entries",
+		Long: `Shrinks the preferences document without changing what users see:
+SeenEventIDs older than --seen-days are pruned, weekly stats left over from a
+missed rollover are archived, archived weeks with no activity are dropped, and
+blank notes/statuses and empty groups are removed.
+Use --dry-run to report the savings without saving.`,
+		Args: cobra.NoArgs,
+		RunE: runPrefsCompact,
+	}
+	compactCmd.Flags().BoolVar(&flagPrefsDryRun, "dry-run", false, "Report savings without saving")
+	compactCmd.Flags().IntVar(&flagPrefsSeenDays, "seen-days", preferences.DefaultSeenEventDays, "Keep seen events from the last N days (0 keeps all)")
+
+	experimentsCmd := &cobra.Command{
+		Use:   "experiments",
+		Short: "Compare button tap rates between the variants of an A/B experiment",
+		Long: `Counts new-event messages sent in each variant of --experiment from the delivery
+log in --data-dir, and button taps on them from the preferences, and prints the tap
+rate per variant. Notifiers and the bot record these when run with --experiment.`,
+		Args: cobra.NoArgs,
+		RunE: runPrefsExperiments,
+	}
+	experimentsCmd.Flags().StringVar(&flagPrefsExperiment, "experiment", experiment.NewEventFormat.Name, "Experiment to report on")
+	experimentsCmd.Flags().StringVar(&flagPrefsDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the delivery log")
+
+	renamesCmd := &cobra.Command{
+		Use:   "apply-renames",
+		Short: "Move users' statuses and notes from renamed events to their new IDs",
+		Long: `Reads the "renamed" changes from a check's JSON output (--format json) and
+moves each user's status, note, seen time, and other per-event data from the old
+event ID to the new one, so a course renaming its event doesn't lose anything.
+Use --dry-run to report how many users would change without saving.`,
+		Args: cobra.NoArgs,
+		RunE: runPrefsApplyRenames,
+	}
+	renamesCmd.Flags().StringVar(&flagPrefsEventsFile, "events-file", "", "JSON output of a check (required)")
+	renamesCmd.Flags().BoolVar(&flagPrefsDryRun, "dry-run", false, "Report changes without saving")
+	_ = renamesCmd.MarkFlagRequired("events-file")
+
+	rolloutCmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Compare engagement between users with and without a feature in a staged rollout",
+		Long: `Totals events viewed, marked, and registered per week for users with --flag and
+users without it, from the weeks the bot recorded their cohort. The bot records
+cohorts for users with stats on while a flag is rolled out to some chats but not
+everyone, so compare before turning the flag on for everyone.`,
+		Args: cobra.NoArgs,
+		RunE: runPrefsRollout,
+	}
+	rolloutCmd.Flags().StringVar(&flagPrefsFlag, "flag", flags.WebAPI.Name, "Feature flag to report on")
+
+	cmd.AddCommand(sizeCmd, compactCmd, experimentsCmd, rolloutCmd, renamesCmd)
+	return cmd
+}
+
+// loadPrefsStorage opens the preferences Gist from the prefs flags
+func loadPrefsStorage(ctx context.Context) (*preferences.GistStorage, preferences.Preferences, error) {
+	store, err := preferences.NewGistStorageWithEncryption(flagPrefsGistID, flagPrefsGitHubToken, flagPrefsEncryptionKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("initializing gist storage: %w", err)
+	}
+
+	prefs, err := store.Load(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading preferences: %w", err)
+	}
+
+	return store, prefs, nil
+}
+
+// runPrefsSize prints the size report
+func runPrefsSize(cmd *cobra.Command, args []string) error {
+	_, prefs, err := loadPrefsStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	report, err := preferences.MeasureSize(prefs)
+	if err != nil {
+		return err
+	}
+
+	writeSizeReport(os.Stdout, report)
+	return nil
+}
+
+// runPrefsCompact compacts the preferences and reports the savings
+func runPrefsCompact(cmd *cobra.Command, args []string) error {
+	store, prefs, err := loadPrefsStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	before, err := preferences.MeasureSize(prefs)
+	if err != nil {
+		return err
+	}
+
+	result := prefs.Compact(preferences.CompactOptions{SeenEventDays: flagPrefsSeenDays})
+
+	after, err := preferences.MeasureSize(prefs)
+	if err != nil {
+		return err
+	}
+
+	writeCompactReport(os.Stdout, before, after, result)
+
+	if flagPrefsDryRun {
+		fmt.Println("\n[DRY RUN] Preferences not saved")
+		return nil
+	}
+
+	if err := store.Save(cmd.Context(), prefs); err != nil {
+		return fmt.Errorf("saving preferences: %w", err)
+	}
+	fmt.Println("\n✅ Preferences saved")
+	return nil
+}
+
+// runPrefsApplyRenames moves user data over the renames in a check's output
+func runPrefsApplyRenames(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(flagPrefsEventsFile)
+	if err != nil {
+		return fmt.Errorf("opening events file: %w", err)
+	}
+	defer f.Close()
+
+	renames := make(map[string]string)
+	err = event.StreamDiff(f, event.DiffStream{
+		OnChanged: func(change *event.EventChange) error {
+			if change.ChangeType == "renamed" && change.PreviousID != "" {
+				renames[change.PreviousID] = change.EventID
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("reading events file: %w", err)
+	}
+	if len(renames) == 0 {
+		fmt.Println("No renamed events")
+		return nil
+	}
+
+	store, prefs, err := loadPrefsStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	changed := prefs.RenameEvents(renames)
+	fmt.Printf("%d renamed event(s), %d user(s) updated\n", len(renames), changed)
+	if changed == 0 {
+		return nil
+	}
+	if flagPrefsDryRun {
+		fmt.Println("[DRY RUN] Preferences not saved")
+		return nil
+	}
+
+	if err := store.Save(cmd.Context(), prefs); err != nil {
+		return fmt.Errorf("saving preferences: %w", err)
+	}
+	fmt.Println("✅ Preferences saved")
+	return nil
+}
+
+// runPrefsExperiments prints the experiment's results
+func runPrefsExperiments(cmd *cobra.Command, args []string) error {
+	exp, err := experiment.Lookup(flagPrefsExperiment)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.New(flagPrefsDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	deliveries, err := store.LoadDeliveries()
+	if err != nil {
+		return err
+	}
+
+	_, prefs, err := loadPrefsStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	writeExperimentReport(os.Stdout, exp, experiment.Summarize(exp, deliveries, prefs))
+	return nil
+}
+
+// runPrefsRollout prints engagement per cohort of a staged feature flag
+func runPrefsRollout(cmd *cobra.Command, args []string) error {
+	f, err := flags.Lookup(flagPrefsFlag)
+	if err != nil {
+		return err
+	}
+
+	_, prefs, err := loadPrefsStorage(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	writeRolloutReport(os.Stdout, f, prefs.CompareCohorts(f.Name))
+	return nil
+}
+
+// writeSizeReport writes the document size, limit usage, and largest users
+func writeSizeReport(w io.Writer, report *preferences.SizeReport) {
+	fmt.Fprintf(w, "Preferences: %s for %d user(s) (%.1f%% of %s Gist limit)\n",
+		preferences.FormatBytes(report.Total), len(report.Users), report.PercentOfLimit(), preferences.FormatBytes(preferences.GistFileSizeLimit))
+
+	if largest := report.Largest(largestUsersShown); len(largest) > 0 {
+		fmt.Fprintln(w, "\nLargest users:")
+		for _, u := range largest {
+			fmt.Fprintf(w, "  %-15s %s\n", u.ChatID, preferences.FormatBytes(u.Bytes))
+		}
+	}
+
+	if warning := report.Warning(); warning != "" {
+		fmt.Fprintf(w, "\n⚠️  Warning: %s\n", warning)
+	}
+}
+
+// writeCompactReport writes what compaction removed and the size saved
+func writeCompactReport(w io.Writer, before, after *preferences.SizeReport, result preferences.CompactResult) {
+	fmt.Fprintf(w, "Seen events pruned:  %d\n", result.SeenEventsPruned)
+	fmt.Fprintf(w, "Weeks archived:      %d\n", result.WeeksArchived)
+	fmt.Fprintf(w, "Empty weeks pruned:  %d\n", result.EmptyWeeksPruned)
+	fmt.Fprintf(w, "Empty entries:       %d\n", result.EmptyEntries)
+	fmt.Fprintf(w, "Expired trips:       %d\n", result.TravelExpired)
+	fmt.Fprintf(w, "Old comments:        %d\n", result.CommentsPruned)
+
+	saved := before.Total - after.Total
+	percent := 0.0
+	if before.Total > 0 {
+		percent = float64(saved) * 100 / float64(before.Total)
+	}
+	fmt.Fprintf(w, "\nSize: %s → %s (saved %s, %.1f%%)\n",
+		preferences.FormatBytes(before.Total), preferences.FormatBytes(after.Total), preferences.FormatBytes(saved), percent)
+}
+
+// writeExperimentReport writes messages sent, taps, and tap rate per variant
+func writeExperimentReport(w io.Writer, exp *experiment.Experiment, results []experiment.Result) {
+	fmt.Fprintf(w, "Experiment: %s\n%s\n\n", exp.Name, exp.Description)
+	fmt.Fprintf(w, "  %-12s %6s %6s %6s %9s\n", "Variant", "Users", "Sent", "Taps", "Tap rate")
+	for _, r := range results {
+		fmt.Fprintf(w, "  %-12s %6d %6d %6d %8.1f%%\n", r.Variant, r.Users, r.Sent, r.Taps, r.TapRate()*100)
+	}
+}
+
+// writeRolloutReport writes users, weeks, and per-week engagement for each cohort
+func writeRolloutReport(w io.Writer, f *flags.Flag, cohorts []preferences.CohortStats) {
+	fmt.Fprintf(w, "Feature flag: %s\n%s\n\n", f.Name, f.Description)
+	fmt.Fprintf(w, "  %-12s %6s %6s %9s %9s %9s\n", "Cohort", "Users", "Weeks", "Viewed/wk", "Marked/wk", "Reg'd/wk")
+	for _, c := range cohorts {
+		name := "without"
+		if c.Enabled {
+			name = "with"
+		}
+		fmt.Fprintf(w, "  %-12s %6d %6d %9.2f %9.2f %9.2f\n", name, c.Users, c.Weeks,
+			c.PerWeek(c.EventsViewed), c.PerWeek(c.EventsMarked), c.PerWeek(c.EventsRegistered))
+	}
+}
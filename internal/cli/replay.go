@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/replay"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// replayEventsShown is how many notifications per user the text report lists
+const replayEventsShown = 20
+
+var (
+	flagReplayFrom        string
+	flagReplaySpeed       string
+	flagReplayPrefsFile   string
+	flagReplayMaxMessages int
+	flagReplayFormat      string
+)
+
+// newReplayCmd creates the "replay" command
+func newReplayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay archived snapshots and report the notifications each user would have received",
+		Long: `Replays archived snapshots through the diff and dispatch pipeline without
+sending anything. --from is a directory holding a delta history (history.jsonl,
+written with --history) or copies of snapshot.json taken over time. The first
+snapshot is the baseline; every later one is diffed against the one before it
+and dispatched to the users in the preferences, starting from nothing seen.
+
+Preferences come from --prefs-file, or from the Gist (TELEGRAM_GIST_ID,
+TELEGRAM_GITHUB_TOKEN, TELEGRAM_ENCRYPTION_KEY) when it's not set.`,
+		Args: cobra.NoArgs,
+		RunE: runReplay,
+	}
+
+	cmd.Flags().StringVar(&flagReplayFrom, "from", "", "Directory of archived snapshots or delta history (required)")
+	cmd.Flags().StringVar(&flagReplaySpeed, "speed", "max", `Replay speed relative to when snapshots were taken, e.g. "10x" (pauses are capped at 5s), or "max"`)
+	cmd.Flags().StringVar(&flagReplayPrefsFile, "prefs-file", "", "Read preferences from this JSON file instead of the Gist")
+	cmd.Flags().IntVar(&flagReplayMaxMessages, "max-messages", replay.DefaultMaxMessages, "Maximum immediate notifications per user per run")
+	cmd.Flags().StringVar(&flagReplayFormat, "format", "text", "Output format: text or json")
+	cmd.Flags().StringVar(&flagPrefsGistID, "gist-id", os.Getenv("TELEGRAM_GIST_ID"), "GitHub Gist ID (or env: TELEGRAM_GIST_ID)")
+	cmd.Flags().StringVar(&flagPrefsGitHubToken, "github-token", os.Getenv("TELEGRAM_GITHUB_TOKEN"), "GitHub token with gist scope (or env: TELEGRAM_GITHUB_TOKEN)")
+	cmd.Flags().StringVar(&flagPrefsEncryptionKey, "encryption-key", os.Getenv("TELEGRAM_ENCRYPTION_KEY"), "Encryption key for sensitive fields (or env: TELEGRAM_ENCRYPTION_KEY)")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// parseSpeed parses a replay speed such as "10x", "2.5", or "max" (no pauses, returned as 0)
+func parseSpeed(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "max" {
+		return 0, nil
+	}
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %q: use a multiplier like 10x, or max", s)
+	}
+	return speed, nil
+}
+
+// runReplay loads snapshots and preferences, replays them, and prints the report
+func runReplay(cmd *cobra.Command, args []string) error {
+	speed, err := parseSpeed(flagReplaySpeed)
+	if err != nil {
+		return err
+	}
+	if flagReplayFormat != "text" && flagReplayFormat != "json" {
+		return fmt.Errorf("invalid format %q: use text or json", flagReplayFormat)
+	}
+
+	frames, err := replay.LoadFrames(flagReplayFrom)
+	if err != nil {
+		return fmt.Errorf("loading snapshots: %w", err)
+	}
+	if len(frames) < 2 {
+		return fmt.Errorf("found %d snapshot(s) in %s; replay needs at least 2", len(frames), flagReplayFrom)
+	}
+
+	prefs, err := loadReplayPrefs(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts := replay.Options{Speed: speed, MaxMessages: flagReplayMaxMessages}
+	if flagReplayFormat == "text" {
+		opts.OnFrame = func(index int, frame *replay.Frame, newEvents, removedEvents int) {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s  %s: %d new, %d removed\n",
+				index, len(frames)-1, frame.At.Format(time.RFC3339), frame.Source, newEvents, removedEvents)
+		}
+	}
+
+	report, err := replay.Run(cmd.Context(), frames, prefs, opts)
+	if err != nil {
+		return fmt.Errorf("replaying: %w", err)
+	}
+
+	if flagReplayFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	writeReplayReport(os.Stdout, report)
+	return nil
+}
+
+// loadReplayPrefs reads preferences from --prefs-file or the Gist
+func loadReplayPrefs(cmd *cobra.Command) (preferences.Preferences, error) {
+	if flagReplayPrefsFile == "" {
+		_, prefs, err := loadPrefsStorage(cmd.Context())
+		return prefs, err
+	}
+	return readPrefsFile(flagReplayPrefsFile)
+}
+
+// readPrefsFile reads preferences from a JSON file (plain or gzip-compressed)
+func readPrefsFile(path string) (preferences.Preferences, error) {
+	data, err := storage.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading preferences: %w", err)
+	}
+	prefs, err := preferences.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing preferences: %w", err)
+	}
+	prefs.ShareHouseholdData()
+	return prefs, nil
+}
+
+// writeReplayReport writes the replay summary and each user's notifications
+func writeReplayReport(w io.Writer, report *replay.Report) {
+	fmt.Fprintf(w, "Replayed %d snapshot(s) from %s to %s\n",
+		report.Frames, report.From.Format("2006-01-02 15:04"), report.To.Format("2006-01-02 15:04"))
+	fmt.Fprintf(w, "Events: %d new, %d removed\n", report.NewEvents, report.RemovedEvents)
+
+	if len(report.Users) == 0 {
+		fmt.Fprintln(w, "\nNo active users with subscriptions")
+		return
+	}
+
+	for _, u := range report.Users {
+		fmt.Fprintf(w, "\nUser %s: %d immediate, %d digest", u.ChatID, u.Count(replay.TypeNew), u.Count(replay.TypeDigest))
+		if u.Filtered > 0 {
+			fmt.Fprintf(w, ", %d filtered", u.Filtered)
+		}
+		if u.OverLimit > 0 {
+			fmt.Fprintf(w, ", %d over the per-run limit", u.OverLimit)
+		}
+		fmt.Fprintln(w)
+
+		for i, n := range u.Notifications {
+			if i == replayEventsShown {
+				fmt.Fprintf(w, "  … %d more\n", len(u.Notifications)-replayEventsShown)
+				break
+			}
+			fmt.Fprintf(w, "  %s  %-6s %-2s %-10s %s\n", n.At.Format("2006-01-02"), n.Type, n.State, n.Date, n.Title)
+		}
+	}
+}
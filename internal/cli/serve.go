@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/api"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/flags"
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout is how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+var (
+	flagServeAddr      string
+	flagServeDataDir   string
+	flagServePrefsFile string
+	flagServePrefsTTL  time.Duration
+	flagServeLinkUTM   bool
+	flagServeFlagsFile string
+)
+
+// newServeAPICmd creates the "serve-api" command
+func newServeAPICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-api",
+		Short: "Serve the read-only HTTP API for users' tracked events, filters, and calendar feed, plus embeddable widgets",
+		Long: `Serves a read-only JSON API authenticated with the personal tokens users get
+from the bot's /api-token command. Each token only sees its own user's data:
+
+  GET /api/v1/me             Subscriptions and settings
+  GET /api/v1/events         Current events matching the subscriptions (?state=NV or ALL)
+  GET /api/v1/tracked        Events marked with a status, with notes
+  GET /api/v1/filters        Saved filters
+  GET /api/v1/calendar.ics   Tracked events as a subscribable calendar feed
+  GET /widget/{state}        Embeddable HTML list of a state's upcoming events (no token)
+  GET /og/{event}.png        Social card image for an event ID or short code (no token)
+  GET /r/{token}             Tracked link: logs the click to --data-dir, then redirects (no token)
+
+Send the token as "Authorization: Bearer <token>", or as ?token= for calendar apps.
+Events come from the snapshot in --data-dir. Preferences come from --prefs-file, or
+from the Gist (TELEGRAM_GIST_ID, TELEGRAM_GITHUB_TOKEN, TELEGRAM_ENCRYPTION_KEY), and
+are reloaded every --prefs-ttl so new, rotated, and revoked tokens take effect.`,
+		Args: cobra.NoArgs,
+		RunE: runServeAPI,
+	}
+
+	cmd.Flags().StringVar(&flagServeAddr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().StringVar(&flagServeDataDir, "data-dir", "~/.local/share/vga-events", "Data directory for snapshots")
+	cmd.Flags().StringVar(&flagServePrefsFile, "prefs-file", "", "Read preferences from this JSON file instead of the Gist")
+	cmd.Flags().DurationVar(&flagServePrefsTTL, "prefs-ttl", api.DefaultPrefsTTL, "How long loaded preferences are reused")
+	cmd.Flags().StringVar(&flagPrefsGistID, "gist-id", os.Getenv("TELEGRAM_GIST_ID"), "GitHub Gist ID (or env: TELEGRAM_GIST_ID)")
+	cmd.Flags().StringVar(&flagPrefsGitHubToken, "github-token", os.Getenv("TELEGRAM_GITHUB_TOKEN"), "GitHub token with gist scope (or env: TELEGRAM_GITHUB_TOKEN)")
+	cmd.Flags().StringVar(&flagPrefsEncryptionKey, "encryption-key", os.Getenv("TELEGRAM_ENCRYPTION_KEY"), "Encryption key for sensitive fields (or env: TELEGRAM_ENCRYPTION_KEY)")
+	cmd.Flags().StringVar(&flagServeFlagsFile, "flags-file", os.Getenv("VGA_FLAGS_FILE"), "JSON file of feature flags; VGA_FLAGS overrides it (or env: VGA_FLAGS_FILE)")
+	cmd.Flags().BoolVar(&flagServeLinkUTM, "utm", os.Getenv("VGA_LINK_UTM") == "true", "Add UTM parameters to widget and calendar feed links (or env: VGA_LINK_UTM=true)")
+
+	return cmd
+}
+
+// runServeAPI serves the API until interrupted
+func runServeAPI(cmd *cobra.Command, args []string) error {
+	links.Configure(links.Options{UTM: flagServeLinkUTM})
+
+	features, err := flags.Load(flagServeFlagsFile, os.Getenv("VGA_FLAGS"))
+	if err != nil {
+		return err
+	}
+	if features.Dark(flags.WebAPI) {
+		return fmt.Errorf("the web API is turned off (%s)", flags.WebAPI.Name)
+	}
+
+	store, err := storage.New(flagServeDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+
+	loadPrefs, err := servePrefsLoader()
+	if err != nil {
+		return err
+	}
+
+	handler := api.New(api.Options{
+		Prefs:    loadPrefs,
+		Events:   snapshotEvents(store),
+		PrefsTTL: flagServePrefsTTL,
+		Clicks:   store.AppendClick,
+		Features: features,
+	})
+	return serveHTTP(cmd.Context(), flagServeAddr, handler)
+}
+
+// servePrefsLoader returns a loader for --prefs-file or the Gist
+func servePrefsLoader() (api.PrefsLoader, error) {
+	if flagServePrefsFile != "" {
+		return func(ctx context.Context) (preferences.Preferences, error) {
+			return readPrefsFile(flagServePrefsFile)
+		}, nil
+	}
+
+	gist, err := preferences.NewGistStorageWithEncryption(flagPrefsGistID, flagPrefsGitHubToken, flagPrefsEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("initializing gist storage: %w", err)
+	}
+	return gist.Load, nil
+}
+
+// snapshotEvents returns a loader for the current events in the all-states snapshot
+func snapshotEvents(store *storage.Storage) api.EventsLoader {
+	return func() ([]*event.Event, error) {
+		snapshot, err := store.LoadSnapshot(StateAll)
+		if err != nil {
+			return nil, err
+		}
+		listed := snapshot.Listed()
+		events := make([]*event.Event, 0, len(listed))
+		for _, evt := range listed {
+			events = append(events, evt)
+		}
+		return events, nil
+	}
+}
+
+// serveHTTP serves handler on addr until ctx is canceled, then shuts down gracefully
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Printf("Listening on %s\n", addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSnapshotDataDir   string
+	flagSnapshotState     string
+	flagSnapshotRetention int
+	flagSnapshotDryRun    bool
+	flagSnapshotCompress  bool
+)
+
+// snapshotExpiringDays is how far ahead the snapshot command looks for removed events
+// about to age out
+const snapshotExpiringDays = 7
+
+// newSnapshotCmd creates the "snapshot" command for inspecting and repairing saved snapshots
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Inspect, verify, and repair saved snapshots",
+	}
+
+	cmd.PersistentFlags().StringVar(&flagSnapshotDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the snapshot")
+	cmd.PersistentFlags().StringVar(&flagSnapshotState, "state", "all", "State code (e.g., NV) or 'all'")
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show what a snapshot holds: events, removed events, and the archive",
+		Long: `Reads a state's snapshot from the data directory and prints its event count,
+the removed events it still keeps and how many of them age out of the retention
+window within a week, the length of its change log, and how many expired removed
+events have been archived (with --archive-removed).`,
+		Args: cobra.NoArgs,
+		RunE: runSnapshotShow,
+	}
+	showCmd.Flags().IntVar(&flagSnapshotRetention, "removed-retention-days", event.DefaultRemovedRetentionDays, "Retention window the checks run with, for counting removed events about to expire")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check a snapshot's events against its stable key and short code indices",
+		Long: `Reports events filed under a key other than their ID, listed events also kept
+as removed, stable index and short code entries that point to no matching event,
+events missing from the indices, and events sharing a short code. Events sharing a
+stable key (recurring events at one course) are reported as warnings.
+Exits with an error if anything but warnings is found.`,
+		Args: cobra.NoArgs,
+		RunE: runSnapshotVerify,
+	}
+
+	repairCmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Rekey a snapshot's events and rebuild its indices",
+		Long: `Fixes what verify reports: events are rekeyed by their IDs, listed events are
+dropped from the removed events, and the stable key and short code indices are
+rebuilt from the listed events. Use after hand-editing a snapshot or a schema change,
+with --dry-run to list the fixes without saving.`,
+		Args: cobra.NoArgs,
+		RunE: runSnapshotRepair,
+	}
+	repairCmd.Flags().BoolVar(&flagSnapshotDryRun, "dry-run", false, "List fixes without saving")
+	repairCmd.Flags().BoolVar(&flagSnapshotCompress, "compress", false, "Save the repaired snapshot gzip-compressed, as checks run with --compress do")
+
+	cmd.AddCommand(showCmd, verifyCmd, repairCmd)
+	return cmd
+}
+
+// loadSnapshotFlags loads the snapshot named by the snapshot flags
+func loadSnapshotFlags() (*storage.Storage, string, *event.Snapshot, error) {
+	store, err := storage.NewWithOptions(flagSnapshotDataDir, storage.Options{Compress: flagSnapshotCompress})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("initializing storage: %w", err)
+	}
+
+	state := strings.ToUpper(strings.TrimSpace(flagSnapshotState))
+	snapshot, err := store.LoadSnapshot(state)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return store, state, snapshot, nil
+}
+
+// runSnapshotShow loads the snapshot and archive and prints their counts
+func runSnapshotShow(cmd *cobra.Command, args []string) error {
+	store, state, snapshot, err := loadSnapshotFlags()
+	if err != nil {
+		return err
+	}
+	archived, err := store.LoadArchive(state)
+	if err != nil {
+		return err
+	}
+
+	writeSnapshotInfo(os.Stdout, state, snapshot, len(archived), flagSnapshotRetention, time.Now())
+	return nil
+}
+
+// runSnapshotVerify prints the snapshot's problems, failing unless they're all warnings
+func runSnapshotVerify(cmd *cobra.Command, args []string) error {
+	_, state, snapshot, err := loadSnapshotFlags()
+	if err != nil {
+		return err
+	}
+
+	problems := snapshot.Verify()
+	writeSnapshotProblems(os.Stdout, problems)
+
+	failures := 0
+	for _, p := range problems {
+		if !p.IsWarning() {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("snapshot %s has %d problem(s); run \"vga-events snapshot repair\" to fix them", state, failures)
+	}
+	fmt.Printf("Snapshot %s OK (%d events)\n", state, len(snapshot.Events))
+	return nil
+}
+
+// runSnapshotRepair repairs the snapshot and saves it, unless --dry-run
+func runSnapshotRepair(cmd *cobra.Command, args []string) error {
+	store, state, snapshot, err := loadSnapshotFlags()
+	if err != nil {
+		return err
+	}
+
+	fixed := snapshot.Repair()
+	if len(fixed) == 0 {
+		fmt.Printf("Snapshot %s needs no repairs\n", state)
+		return nil
+	}
+	writeSnapshotProblems(os.Stdout, fixed)
+
+	if flagSnapshotDryRun {
+		fmt.Printf("\nDry run: would fix %d problem(s) in snapshot %s\n", len(fixed), state)
+		return nil
+	}
+	if err := store.SaveSnapshot(cmd.Context(), snapshot, state); err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+	fmt.Printf("\nFixed %d problem(s) in snapshot %s\n", len(fixed), state)
+	return nil
+}
+
+// writeSnapshotProblems lists problems one per line, warnings marked
+func writeSnapshotProblems(w io.Writer, problems []event.SnapshotProblem) {
+	for _, p := range problems {
+		level := "error"
+		if p.IsWarning() {
+			level = "warning"
+		}
+		fmt.Fprintf(w, "%-7s  %-20s  %s: %s\n", level, p.Kind, p.Key, p.Detail)
+	}
+}
+
+// writeSnapshotInfo writes a snapshot's counts
+func writeSnapshotInfo(w io.Writer, state string, snapshot *event.Snapshot, archived, retentionDays int, now time.Time) {
+	fmt.Fprintf(w, "Snapshot: %s\n", state)
+	if snapshot.UpdatedAt != "" {
+		fmt.Fprintf(w, "Updated:             %s\n", snapshot.UpdatedAt)
+	}
+	fmt.Fprintf(w, "Events:              %d\n", len(snapshot.Events))
+	fmt.Fprintf(w, "Removed events:      %d (kept %d days)\n", len(snapshot.RemovedEvents), retentionDays)
+
+	cutoff := now.AddDate(0, 0, snapshotExpiringDays-retentionDays)
+	fmt.Fprintf(w, "  Expiring in %d days: %d\n", snapshotExpiringDays, len(snapshot.RemovedBefore(cutoff)))
+	if oldest := snapshot.RemovedBefore(now); len(oldest) > 0 {
+		fmt.Fprintf(w, "  Oldest removal:    %s\n", oldest[0].RemovedAt.Format("2006-01-02"))
+	}
+
+	fmt.Fprintf(w, "Archived removed:    %d\n", archived)
+	fmt.Fprintf(w, "Change log entries:  %d\n", len(snapshot.ChangeLog))
+}
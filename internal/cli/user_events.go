@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/eventquery"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagUserEventsFile string
+	flagUserPrefsFile  string
+	flagUserEventsChat string
+	flagUserCourses    string
+	flagUserQueue      bool
+)
+
+// newUserEventsCmd creates the "user-events" command the notification workflow uses to
+// pick each user's new events
+func newUserEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user-events",
+		Short: "Select the new events one user is subscribed to from a check's JSON output",
+		Long: `Reads the JSON written by --format json and prints the new events matching one
+user's state and city subscriptions and time filters (hide past events, days ahead)
+that they haven't seen yet, in the same JSON
+shape, for passing to vga-events-telegram --events-file. City subscriptions match
+by name, or within their radius when both cities can be located. Events already
+sent to a chat linked with the user's (/link) count as seen. Restored events (back
+after being removed) are included for users who never saw them; users who did get
+vga-events-telegram --restored-notification instead.
+
+--followed-courses only selects just the events at courses the user follows, and
+--followed-courses exclude leaves them out, so the workflow can send those
+immediately to users who otherwise get a digest.
+
+--queue-digest adds the selected events to the user's digest queue (pending_events)
+and marks them seen, writing the result back to --prefs-file. vga-events-bot
+--send-digests sends the queue later.`,
+		Args: cobra.NoArgs,
+		RunE: runUserEvents,
+	}
+
+	cmd.Flags().StringVar(&flagUserEventsFile, "events-file", "", "JSON output of a check (required)")
+	cmd.Flags().StringVar(&flagUserPrefsFile, "prefs-file", "", "Preferences JSON file (required)")
+	cmd.Flags().StringVar(&flagUserEventsChat, "chat-id", "", "User's chat ID (required)")
+	cmd.Flags().StringVar(&flagUserCourses, "followed-courses", "", "only: just events at followed courses; exclude: leave them out")
+	cmd.Flags().BoolVar(&flagUserQueue, "queue-digest", false, "Queue the selected events for the user's digest and update --prefs-file")
+	_ = cmd.MarkFlagRequired("events-file")
+	_ = cmd.MarkFlagRequired("prefs-file")
+	_ = cmd.MarkFlagRequired("chat-id")
+
+	return cmd
+}
+
+// runUserEvents filters the check output for one user and writes it to stdout
+func runUserEvents(cmd *cobra.Command, args []string) error {
+	if flagUserCourses != "" && flagUserCourses != "only" && flagUserCourses != "exclude" {
+		return fmt.Errorf("invalid --followed-courses %q: use only or exclude", flagUserCourses)
+	}
+
+	data, err := storage.ReadFile(flagUserEventsFile)
+	if err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+	var result OutputResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("parsing events: %w", err)
+	}
+
+	data, err = storage.ReadFile(flagUserPrefsFile)
+	if err != nil {
+		return fmt.Errorf("reading preferences: %w", err)
+	}
+	prefs, err := preferences.FromJSON(data)
+	if err != nil {
+		return err
+	}
+	// Events a linked chat in the household has already been sent count as seen
+	prefs.ShareHouseholdData()
+	user, ok := prefs[flagUserEventsChat]
+	if !ok {
+		return fmt.Errorf("no preferences for chat %s", flagUserEventsChat)
+	}
+
+	// A restored event is only new to users who haven't seen it; the seen check drops
+	// the rest
+	candidates := append(append([]*event.Event{}, result.NewEvents...), result.RestoredEvents...)
+	now := clk.Now()
+	events := selectUserEvents(user, candidates, flagUserCourses, now)
+	if flagUserQueue {
+		user.QueueDigest(events, now)
+		data, err := prefs.ToJSON()
+		if err != nil {
+			return fmt.Errorf("encoding preferences: %w", err)
+		}
+		if err := os.WriteFile(flagUserPrefsFile, data, 0600); err != nil {
+			return fmt.Errorf("writing preferences: %w", err)
+		}
+	}
+	return writeJSON(os.Stdout, &OutputResult{
+		CheckedAt:  result.CheckedAt,
+		States:     user.States,
+		NewEvents:  events,
+		EventCount: len(events),
+	})
+}
+
+// selectUserEvents returns the events matching a user's subscriptions and time filters
+// at now that they haven't seen, never nil. courses is "only" or "exclude" to split out
+// followed-course events.
+func selectUserEvents(user *preferences.UserPreferences, events []*event.Event, courses string, now time.Time) []*event.Event {
+	selected := eventquery.ForUser(user, now).Subscriptions().HidePast().Horizon().
+		Where(func(evt *event.Event) bool {
+			switch courses {
+			case "only":
+				return user.FollowsCourse(evt)
+			case "exclude":
+				return !user.FollowsCourse(evt)
+			}
+			return true
+		}).
+		Where(func(evt *event.Event) bool {
+			_, seen := user.SeenEventIDs[evt.ID]
+			return !seen
+		}).
+		Run(events)
+	if selected == nil {
+		selected = []*event.Event{}
+	}
+	return selected
+}
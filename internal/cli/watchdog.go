@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/telegram"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagHeartbeatDataDir string
+	flagHeartbeatJob     string
+	flagHeartbeatRunID   string
+
+	flagWatchdogDataDir  string
+	flagWatchdogJobs     []string
+	flagWatchdogBotToken string
+	flagWatchdogChatID   string
+	flagWatchdogDryRun   bool
+)
+
+// newHeartbeatCmd creates the "heartbeat" command recording a successful scheduled run
+func newHeartbeatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "heartbeat",
+		Short: "Record that a scheduled job finished successfully, for the watchdog",
+		Long: `Appends a heartbeat for --job to the heartbeat log (heartbeats.jsonl) in the data
+directory. Run it as the last step of a scheduled workflow; vga-events watchdog
+alerts when a job's heartbeats stop.`,
+		Args: cobra.NoArgs,
+		RunE: runHeartbeat,
+	}
+
+	cmd.Flags().StringVar(&flagHeartbeatDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the heartbeat log")
+	cmd.Flags().StringVar(&flagHeartbeatJob, "job", "", "Name of the job that finished, e.g. notify")
+	cmd.Flags().StringVar(&flagHeartbeatRunID, "run-id", os.Getenv("GITHUB_RUN_ID"), "Run ID recorded with the heartbeat (or env: GITHUB_RUN_ID)")
+	_ = cmd.MarkFlagRequired("job")
+
+	return cmd
+}
+
+// runHeartbeat appends the heartbeat
+func runHeartbeat(cmd *cobra.Command, args []string) error {
+	store, err := storage.New(flagHeartbeatDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	return store.AppendHeartbeat(&storage.Heartbeat{
+		At:    time.Now().UTC().Format(time.RFC3339),
+		Job:   flagHeartbeatJob,
+		RunID: flagHeartbeatRunID,
+	})
+}
+
+// newWatchdogCmd creates the "watchdog" command alerting when scheduled runs stop
+func newWatchdogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watchdog",
+		Short: "Alert the admin chat when a scheduled job stops recording heartbeats",
+		Long: `Reads the heartbeat log (heartbeats.jsonl) in the data directory and checks each
+--job name=interval against it. A job whose last heartbeat is more than twice its
+interval old, or that has none, is reported to --chat-id on Telegram, and the
+command exits with an error. Catches workflows that stop running, or keep failing
+before their last step, without anyone noticing.`,
+		Args: cobra.NoArgs,
+		RunE: runWatchdog,
+	}
+
+	cmd.Flags().StringVar(&flagWatchdogDataDir, "data-dir", "~/.local/share/vga-events", "Data directory holding the heartbeat log")
+	cmd.Flags().StringArrayVar(&flagWatchdogJobs, "job", []string{"notify=1h"}, "Job to watch and how often it runs, as name=interval (repeatable)")
+	cmd.Flags().StringVar(&flagWatchdogBotToken, "bot-token", os.Getenv("TELEGRAM_BOT_TOKEN"), "Telegram bot token (or env: TELEGRAM_BOT_TOKEN)")
+	cmd.Flags().StringVar(&flagWatchdogChatID, "chat-id", os.Getenv("TELEGRAM_ADMIN_CHAT_ID"), "Chat to alert (or env: TELEGRAM_ADMIN_CHAT_ID)")
+	cmd.Flags().BoolVar(&flagWatchdogDryRun, "dry-run", false, "Print the alert instead of sending it")
+
+	return cmd
+}
+
+// runWatchdog checks the heartbeats and sends an alert for stale jobs
+func runWatchdog(cmd *cobra.Command, args []string) error {
+	intervals, err := parseWatchdogJobs(flagWatchdogJobs)
+	if err != nil {
+		return err
+	}
+	if !flagWatchdogDryRun && (flagWatchdogBotToken == "" || flagWatchdogChatID == "") {
+		return fmt.Errorf("--bot-token and --chat-id are required (or use --dry-run)")
+	}
+
+	store, err := storage.New(flagWatchdogDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	latest, err := store.LatestHeartbeats()
+	if err != nil {
+		return err
+	}
+
+	stale := storage.StaleJobs(latest, intervals, time.Now())
+	if len(stale) == 0 {
+		fmt.Printf("All %d job(s) are running on schedule\n", len(intervals))
+		return nil
+	}
+
+	alert := formatWatchdogAlert(stale)
+	if flagWatchdogDryRun {
+		fmt.Printf("[DRY RUN] Would send to %s:\n%s\n", flagWatchdogChatID, alert)
+	} else {
+		client, err := telegram.NewClient(flagWatchdogBotToken, flagWatchdogChatID)
+		if err != nil {
+			return err
+		}
+		if err := client.SendMessage(cmd.Context(), alert); err != nil {
+			return fmt.Errorf("sending watchdog alert: %w", err)
+		}
+		fmt.Printf("Sent watchdog alert for %d job(s)\n", len(stale))
+	}
+
+	// Fail the run too, so the watchdog's own workflow shows the problem
+	fmt.Fprintf(os.Stderr, "%d job(s) missed their schedule\n", len(stale))
+	os.Exit(ExitError)
+	return nil
+}
+
+// parseWatchdogJobs parses --job name=interval flags
+func parseWatchdogJobs(jobs []string) (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration, len(jobs))
+	for _, job := range jobs {
+		name, value, ok := strings.Cut(job, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --job %q: use name=interval, e.g. notify=1h", job)
+		}
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid --job %q: interval must be a positive duration, e.g. 1h or 30m", job)
+		}
+		intervals[name] = interval
+	}
+	return intervals, nil
+}
+
+// formatWatchdogAlert builds the Telegram alert listing the stale jobs
+func formatWatchdogAlert(stale []*storage.StaleJob) string {
+	var msg strings.Builder
+	msg.WriteString("🚨 <b>Scheduled runs have stopped</b>\n\n")
+	for _, job := range stale {
+		fmt.Fprintf(&msg, "• <b>%s</b> (every %s): ", html.EscapeString(job.Job), job.Interval)
+		if job.Last == nil {
+			msg.WriteString("no successful run recorded\n")
+			continue
+		}
+		fmt.Fprintf(&msg, "last succeeded %s ago, %s", job.Since.Truncate(time.Minute), html.EscapeString(job.Last.At))
+		if job.Last.RunID != "" {
+			fmt.Fprintf(&msg, " (run %s)", html.EscapeString(job.Last.RunID))
+		}
+		msg.WriteString("\n")
+	}
+	msg.WriteString("\nCheck the workflow's recent runs in GitHub Actions: it may be disabled, failing, or not being scheduled.")
+	return msg.String()
+}
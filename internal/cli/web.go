@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/api"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+	"github.com/pfrederiksen/vga-events/internal/web"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagWebAddr      string
+	flagWebDataDir   string
+	flagWebPrefsFile string
+	flagWebPrefsTTL  time.Duration
+)
+
+// newServeWebCmd creates the "serve-web" command
+func newServeWebCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-web",
+		Short: "Serve the web dashboard for browsing events and managing subscriptions and filters",
+		Long: `Serves a small server-rendered web UI. Users sign in with the personal token
+from the bot's /api-token command and can:
+
+  /events          Browse current events by subscription or state, with saved filters
+  /subscriptions   Subscribe and unsubscribe from states, and set digest frequency
+  /filters         Create, activate, and delete saved filters
+  /stats           View activity stats and charts
+
+Events come from the snapshot in --data-dir. Preferences come from --prefs-file, or
+from the Gist (TELEGRAM_GIST_ID, TELEGRAM_GITHUB_TOKEN, TELEGRAM_ENCRYPTION_KEY).
+Changes are saved back to the same place, so the bot sees them on its next run.
+Serve it behind HTTPS: the session cookie holds the user's token.`,
+		Args: cobra.NoArgs,
+		RunE: runServeWeb,
+	}
+
+	cmd.Flags().StringVar(&flagWebAddr, "addr", ":8081", "Address to listen on")
+	cmd.Flags().StringVar(&flagWebDataDir, "data-dir", "~/.local/share/vga-events", "Data directory for snapshots")
+	cmd.Flags().StringVar(&flagWebPrefsFile, "prefs-file", "", "Read and save preferences in this JSON file instead of the Gist")
+	cmd.Flags().DurationVar(&flagWebPrefsTTL, "prefs-ttl", api.DefaultPrefsTTL, "How long loaded preferences are reused for viewing")
+	cmd.Flags().StringVar(&flagPrefsGistID, "gist-id", os.Getenv("TELEGRAM_GIST_ID"), "GitHub Gist ID (or env: TELEGRAM_GIST_ID)")
+	cmd.Flags().StringVar(&flagPrefsGitHubToken, "github-token", os.Getenv("TELEGRAM_GITHUB_TOKEN"), "GitHub token with gist scope (or env: TELEGRAM_GITHUB_TOKEN)")
+	cmd.Flags().StringVar(&flagPrefsEncryptionKey, "encryption-key", os.Getenv("TELEGRAM_ENCRYPTION_KEY"), "Encryption key for sensitive fields (or env: TELEGRAM_ENCRYPTION_KEY)")
+
+	return cmd
+}
+
+// runServeWeb serves the dashboard until interrupted
+func runServeWeb(cmd *cobra.Command, args []string) error {
+	store, err := storage.New(flagWebDataDir)
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+
+	var prefs preferences.Storage = prefsFile(flagWebPrefsFile)
+	if flagWebPrefsFile == "" {
+		gist, err := preferences.NewGistStorageWithEncryption(flagPrefsGistID, flagPrefsGitHubToken, flagPrefsEncryptionKey)
+		if err != nil {
+			return fmt.Errorf("initializing gist storage: %w", err)
+		}
+		prefs = gist
+	}
+
+	handler := web.New(web.Options{
+		Prefs:    prefs,
+		Events:   snapshotEvents(store),
+		PrefsTTL: flagWebPrefsTTL,
+	})
+	return serveHTTP(cmd.Context(), flagWebAddr, handler)
+}
+
+// prefsFile is preferences storage in a local JSON file (plain or gzip-compressed)
+type prefsFile string
+
+// Load reads the preferences file
+func (f prefsFile) Load(ctx context.Context) (preferences.Preferences, error) {
+	return readPrefsFile(string(f))
+}
+
+// Save writes the preferences file
+func (f prefsFile) Save(ctx context.Context, prefs preferences.Preferences) error {
+	data, err := prefs.ToJSON()
+	if err != nil {
+		return fmt.Errorf("marshaling preferences: %w", err)
+	}
+	return storage.WriteFile(string(f), data, 0600)
+}
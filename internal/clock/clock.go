@@ -0,0 +1,20 @@
+// Package clock lets time-dependent code be tested at a fixed time. Code that needs
+// the current time takes a Clock (or the time from one) instead of calling time.Now;
+// production passes System, and tests a fake such as testutil.FakeClock.
+package clock
+
+import "time"
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the real clock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,109 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Alias sends events whose title contains Pattern to a specific course, for titles the
+// fuzzy search matches to the wrong one
+type Alias struct {
+	Pattern  string `json:"pattern"`
+	CourseID int    `json:"course_id"`
+	AddedBy  string `json:"added_by,omitempty"` // Chat ID of the maintainer who added it
+	AddedAt  int64  `json:"added_at,omitempty"` // Unix timestamp
+}
+
+// Aliases is the maintainer-edited alias map, keyed by normalized pattern
+type Aliases map[string]Alias
+
+// normalizeAliasPattern lowercases a title or pattern and collapses its whitespace, so
+// matching ignores case and spacing
+func normalizeAliasPattern(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// Add sets the course for a pattern, replacing any existing alias for it. Returns
+// false for an empty pattern or an invalid course ID.
+func (a Aliases) Add(pattern string, courseID int, addedBy string, now time.Time) bool {
+	key := normalizeAliasPattern(pattern)
+	if key == "" || courseID <= 0 {
+		return false
+	}
+	a[key] = Alias{Pattern: strings.TrimSpace(pattern), CourseID: courseID, AddedBy: addedBy, AddedAt: now.Unix()}
+	return true
+}
+
+// Remove deletes the alias for a pattern. Returns false if there was none.
+func (a Aliases) Remove(pattern string) bool {
+	key := normalizeAliasPattern(pattern)
+	if _, ok := a[key]; !ok {
+		return false
+	}
+	delete(a, key)
+	return true
+}
+
+// List returns the aliases sorted by pattern
+func (a Aliases) List() []Alias {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]Alias, len(keys))
+	for i, key := range keys {
+		list[i] = a[key]
+	}
+	return list
+}
+
+// Match returns the alias for an event title: the longest pattern the title contains,
+// so "bali hai" doesn't override a more specific "bali hai executive"
+func (a Aliases) Match(title string) (Alias, bool) {
+	title = normalizeAliasPattern(title)
+	var best string
+	for key := range a {
+		if !strings.Contains(title, key) {
+			continue
+		}
+		if len(key) > len(best) || (len(key) == len(best) && key < best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Alias{}, false
+	}
+	return a[best], true
+}
+
+// SetAliases sets the aliases FindBestMatch consults before searching
+func (c *Client) SetAliases(aliases Aliases) {
+	c.aliases = aliases
+}
+
+// aliasedCourse fetches an aliased course by ID, cached like search results
+func (c *Client) aliasedCourse(ctx context.Context, courseID int) (*CourseInfo, error) {
+	key := fmt.Sprintf("#%d", courseID)
+	if c.cache != nil {
+		if cached := c.cache.Get(key, "", ""); cached != nil {
+			return cached, nil
+		}
+	}
+
+	info, err := c.GetCourse(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching aliased course %d: %w", courseID, err)
+	}
+	// The detail endpoint already has website, phone, and image
+	info.DetailsFetched = true
+
+	if c.cache != nil {
+		c.cache.Set(key, "", "", info)
+	}
+	return info, nil
+}
@@ -0,0 +1,72 @@
+package course
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAliases(t *testing.T) {
+	aliases := make(Aliases)
+	now := time.Now()
+	if aliases.Add("  ", 1, "admin", now) || aliases.Add("Bali Hai", 0, "admin", now) {
+		t.Error("Add() should reject an empty pattern and a non-positive course ID")
+	}
+	aliases.Add("Bali Hai", 100, "admin", now)
+	aliases.Add("bali  hai EXECUTIVE", 200, "admin", now)
+
+	tests := []struct {
+		title string
+		want  int
+	}{
+		{"Bali Hai Golf Club 1.25.26", 100},
+		{"BALI HAI Executive Course", 200}, // Longest pattern wins
+		{"Chimera Golf Club", 0},
+	}
+	for _, tt := range tests {
+		alias, ok := aliases.Match(tt.title)
+		if got := alias.CourseID; got != tt.want || ok != (tt.want != 0) {
+			t.Errorf("Match(%q) = %d, %v; want %d", tt.title, got, ok, tt.want)
+		}
+	}
+
+	// Adding the same pattern replaces the alias
+	aliases.Add("BALI HAI", 300, "admin", now)
+	if list := aliases.List(); len(list) != 2 || list[0].CourseID != 300 {
+		t.Errorf("List() = %+v, want the replaced alias first", list)
+	}
+
+	if !aliases.Remove("bali hai executive") || aliases.Remove("bali hai executive") {
+		t.Error("Remove() should delete the alias once")
+	}
+}
+
+func TestFindBestMatch_Alias(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(DetailResult{Course: CourseInfo{ID: 42, ClubName: "Right Course"}})
+	}))
+	defer server.Close()
+
+	client := &Client{apiKey: "test-api-key", baseURL: server.URL, httpClient: &http.Client{}, cache: NewCache()}
+	aliases := make(Aliases)
+	aliases.Add("Wrong Match", 42, "admin", time.Now())
+	client.SetAliases(aliases)
+
+	for i := 0; i < 2; i++ {
+		match, err := client.FindBestMatch(context.Background(), "Wrong Match GC 3.1.26", "Las Vegas", "NV")
+		if err != nil {
+			t.Fatalf("FindBestMatch() error = %v", err)
+		}
+		if match == nil || match.ID != 42 || !match.DetailsFetched {
+			t.Fatalf("FindBestMatch() = %+v, want the aliased course", match)
+		}
+	}
+	if len(paths) != 1 || paths[0] != "/v1/courses/42" {
+		t.Errorf("requests = %v, want one course lookup and no search", paths)
+	}
+}
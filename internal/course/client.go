@@ -1,12 +1,15 @@
 package course
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
 )
 
 // Client is a client for the Golf Course API
@@ -15,6 +18,7 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	cache      *Cache
+	aliases    Aliases
 }
 
 // NewClient creates a new Golf Course API client
@@ -76,6 +80,12 @@ type CourseInfo struct {
 	CourseName string   `json:"course_name"`
 	Location   Location `json:"location"`
 	Tees       Tees     `json:"tees"`
+
+	// Contact and media details (filled in by EnrichDetails when search omits them)
+	Website        string `json:"website,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	ImageURL       string `json:"image_url,omitempty"`
+	DetailsFetched bool   `json:"details_fetched,omitempty"` // Detail lookup already attempted
 }
 
 // SearchResult represents the API search response
@@ -83,8 +93,13 @@ type SearchResult struct {
 	Courses []CourseInfo `json:"courses"`
 }
 
+// DetailResult represents the API course detail response
+type DetailResult struct {
+	Course CourseInfo `json:"course"`
+}
+
 // Search searches for golf courses by name
-func (c *Client) Search(searchQuery string) ([]CourseInfo, error) {
+func (c *Client) Search(ctx context.Context, searchQuery string) ([]CourseInfo, error) {
 	// Build query parameters
 	params := url.Values{}
 	params.Add("search_query", searchQuery)
@@ -93,7 +108,7 @@ func (c *Client) Search(searchQuery string) ([]CourseInfo, error) {
 	reqURL := fmt.Sprintf("%s/v1/search?%s", c.baseURL, params.Encode())
 
 	// Create request
-	req, err := http.NewRequest("GET", reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -110,7 +125,7 @@ func (c *Client) Search(searchQuery string) ([]CourseInfo, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		return nil, errs.Status(resp.StatusCode, "API returned status %d", resp.StatusCode)
 	}
 
 	// Parse response
@@ -122,8 +137,13 @@ func (c *Client) Search(searchQuery string) ([]CourseInfo, error) {
 	return result.Courses, nil
 }
 
-// FindBestMatch searches for a course and returns the best match
-func (c *Client) FindBestMatch(courseName, city, state string) (*CourseInfo, error) {
+// FindBestMatch searches for a course and returns the best match. A maintainer alias
+// matching the name wins over the search.
+func (c *Client) FindBestMatch(ctx context.Context, courseName, city, state string) (*CourseInfo, error) {
+	if alias, ok := c.aliases.Match(courseName); ok {
+		return c.aliasedCourse(ctx, alias.CourseID)
+	}
+
 	// Clean up course name (remove dates, special chars)
 	cleanName := CleanCourseName(courseName)
 
@@ -135,7 +155,7 @@ func (c *Client) FindBestMatch(courseName, city, state string) (*CourseInfo, err
 	}
 
 	// Try searching with course name only (city/state in query often returns 0 results)
-	courses, err := c.Search(cleanName)
+	courses, err := c.Search(ctx, cleanName)
 	if err != nil {
 		return nil, fmt.Errorf("searching courses: %w", err)
 	}
@@ -184,6 +204,71 @@ func (c *Client) FindBestMatch(courseName, city, state string) (*CourseInfo, err
 	return bestMatch, nil
 }
 
+// GetCourse fetches the full course record for a course ID
+func (c *Client) GetCourse(ctx context.Context, id int) (*CourseInfo, error) {
+	reqURL := fmt.Sprintf("%s/v1/courses/%d", c.baseURL, id)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Key %s", c.apiKey))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.Status(resp.StatusCode, "API returned status %d", resp.StatusCode)
+	}
+
+	var result DetailResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	return &result.Course, nil
+}
+
+// EnrichDetails fills in website, phone, and image fields from the course detail endpoint.
+// The lookup is attempted at most once per course; since cached entries share the same
+// pointer, the enriched fields are persisted along with the course cache.
+func (c *Client) EnrichDetails(ctx context.Context, info *CourseInfo) {
+	if info == nil || info.DetailsFetched || info.ID == 0 {
+		return
+	}
+
+	// Mark as fetched even on failure to avoid hammering the API for missing details
+	info.DetailsFetched = true
+
+	if info.Website != "" && info.Phone != "" && info.ImageURL != "" {
+		return
+	}
+
+	details, err := c.GetCourse(ctx, info.ID)
+	if err != nil || details == nil {
+		if ctx.Err() != nil || errs.IsRetryable(err) {
+			// Canceled or temporarily unavailable, not missing: try again on a later run
+			info.DetailsFetched = false
+		}
+		return
+	}
+
+	if info.Website == "" {
+		info.Website = strings.TrimSpace(details.Website)
+	}
+	if info.Phone == "" {
+		info.Phone = strings.TrimSpace(details.Phone)
+	}
+	if info.ImageURL == "" {
+		info.ImageURL = strings.TrimSpace(details.ImageURL)
+	}
+}
+
 // GetBestTee returns the best tee information (prefers men's championship tees)
 func (info *CourseInfo) GetBestTee() *TeeInfo {
 	// Prefer men's tees
@@ -1,6 +1,7 @@
 package course
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -117,7 +118,7 @@ func TestSearch(t *testing.T) {
 				cache:      NewCache(),
 			}
 
-			courses, err := client.Search(tt.searchQuery)
+			courses, err := client.Search(context.Background(), tt.searchQuery)
 
 			if tt.wantError {
 				if err == nil {
@@ -267,7 +268,7 @@ func TestFindBestMatch(t *testing.T) {
 				cache:      NewCache(),
 			}
 
-			match, err := client.FindBestMatch(tt.courseName, tt.city, tt.state)
+			match, err := client.FindBestMatch(context.Background(), tt.courseName, tt.city, tt.state)
 
 			if err != nil {
 				t.Errorf("FindBestMatch() unexpected error: %v", err)
@@ -316,7 +317,7 @@ func TestFindBestMatch_Caching(t *testing.T) {
 	}
 
 	// First call should hit the API
-	match1, err := client.FindBestMatch("Test Course", "City", "ST")
+	match1, err := client.FindBestMatch(context.Background(), "Test Course", "City", "ST")
 	if err != nil {
 		t.Fatalf("First FindBestMatch() error: %v", err)
 	}
@@ -325,7 +326,7 @@ func TestFindBestMatch_Caching(t *testing.T) {
 	}
 
 	// Second call with same parameters should use cache
-	match2, err := client.FindBestMatch("Test Course", "City", "ST")
+	match2, err := client.FindBestMatch(context.Background(), "Test Course", "City", "ST")
 	if err != nil {
 		t.Fatalf("Second FindBestMatch() error: %v", err)
 	}
@@ -362,7 +363,7 @@ func TestFindBestMatch_NegativeCaching(t *testing.T) {
 	}
 
 	// First call with no results
-	match1, err := client.FindBestMatch("Nonexistent", "City", "ST")
+	match1, err := client.FindBestMatch(context.Background(), "Nonexistent", "City", "ST")
 	if err != nil {
 		t.Fatalf("First FindBestMatch() error: %v", err)
 	}
@@ -384,7 +385,7 @@ func TestFindBestMatch_NegativeCaching(t *testing.T) {
 	// So this will still hit the API again because the cache returns nil
 	// This is actually expected behavior - the cache doesn't distinguish between
 	// "not in cache" and "cached as not found"
-	match2, err := client.FindBestMatch("Nonexistent", "City", "ST")
+	match2, err := client.FindBestMatch(context.Background(), "Nonexistent", "City", "ST")
 	if err != nil {
 		t.Fatalf("Second FindBestMatch() error: %v", err)
 	}
@@ -558,3 +559,87 @@ func TestGetDisplayName(t *testing.T) {
 		})
 	}
 }
+
+func TestEnrichDetails(t *testing.T) {
+	callCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if r.URL.Path != "/v1/courses/123" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(DetailResult{
+			Course: CourseInfo{
+				ID:       123,
+				Website:  "https://www.testcourse.com",
+				Phone:    "(555) 123-4567",
+				ImageURL: "https://images.example.com/course.jpg",
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:     "test-api-key",
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+		cache:      NewCache(),
+	}
+
+	info := &CourseInfo{ID: 123, ClubName: "Test Course", Phone: "(555) 000-0000"}
+	client.EnrichDetails(context.Background(), info)
+
+	if info.Website != "https://www.testcourse.com" {
+		t.Errorf("Website = %q, want https://www.testcourse.com", info.Website)
+	}
+	if info.Phone != "(555) 000-0000" {
+		t.Errorf("Phone = %q, existing value should be kept", info.Phone)
+	}
+	if info.ImageURL != "https://images.example.com/course.jpg" {
+		t.Errorf("ImageURL = %q, want https://images.example.com/course.jpg", info.ImageURL)
+	}
+	if !info.DetailsFetched {
+		t.Error("DetailsFetched should be true after enrichment")
+	}
+
+	// Second call should not hit the API again
+	client.EnrichDetails(context.Background(), info)
+	if callCount != 1 {
+		t.Errorf("Call count = %d, want 1", callCount)
+	}
+
+	// Missing ID and nil info are no-ops
+	client.EnrichDetails(context.Background(), &CourseInfo{})
+	client.EnrichDetails(context.Background(), nil)
+	if callCount != 1 {
+		t.Errorf("Call count = %d, want 1 (no lookup without ID)", callCount)
+	}
+}
+
+func TestEnrichDetails_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:     "test-api-key",
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+		cache:      NewCache(),
+	}
+
+	if _, err := client.GetCourse(context.Background(), 123); err == nil {
+		t.Error("GetCourse() expected error for 404, got nil")
+	}
+
+	info := &CourseInfo{ID: 123}
+	client.EnrichDetails(context.Background(), info)
+	if info.Website != "" || info.Phone != "" || info.ImageURL != "" {
+		t.Errorf("EnrichDetails() should leave fields empty on error, got %+v", info)
+	}
+	if !info.DetailsFetched {
+		t.Error("DetailsFetched should be set even when lookup fails")
+	}
+}
@@ -0,0 +1,353 @@
+// Package errreport sends errors and recovered panics to an error tracking service
+// (Sentry or Rollbar), so production failures surface without digging through Actions
+// logs. Reporting is optional: with no DSN configured, New returns a nil *Reporter and
+// every method is a no-op.
+//
+// Reports can also be appended to the error log in the data directory (Options.Log),
+// each with a fingerprint of the root error and where it was reported from, so
+// vga-events error-issues can file a GitHub issue for errors that keep recurring.
+//
+// Chat IDs are hashed with a deployment secret (SetChatIDKey) before they leave the
+// process; message text is never sent.
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+const (
+	// sendTimeout bounds a single report, including during shutdown
+	sendTimeout = 5 * time.Second
+
+	clientName = "vga-events/1.0"
+)
+
+// rollbarAPIURL is a package variable (not const) to allow test overriding
+var rollbarAPIURL = "https://api.rollbar.com/api/1/item/"
+
+// Options configures a Reporter
+type Options struct {
+	DSN         string // Sentry DSN (https://key@host/project) or rollbar://access-token
+	Environment string // Deployment environment (default: "production")
+	Release     string // Version of the running binary
+	Component   string // Which program is reporting: "cli", "bot", or "notifier"
+
+	Log   ErrorLog // Also append every report here (optional)
+	RunID string   // Workflow run recorded in the log
+}
+
+// ErrorLog keeps reports across runs (storage.Storage)
+type ErrorLog interface {
+	AppendError(r *storage.ErrorRecord) error
+}
+
+// Context describes what was happening when an error occurred. Empty fields are omitted.
+type Context struct {
+	Command string // Bot command or callback action, e.g. "/search"
+	ChatID  string // Telegram chat; hashed before sending
+	EventID string // Event being handled
+	URL     string // Page or API being fetched
+}
+
+// Reporter sends error reports to Sentry or Rollbar
+type Reporter struct {
+	opts       Options
+	provider   string // "sentry" or "rollbar"
+	endpoint   string
+	key        string
+	httpClient *http.Client
+}
+
+// New creates a Reporter from opts. It returns nil (and no error) when neither a DSN
+// nor a log is configured, so callers can use the result without checking.
+func New(opts Options) (*Reporter, error) {
+	if opts.DSN == "" && opts.Log == nil {
+		return nil, nil
+	}
+	if opts.Environment == "" {
+		opts.Environment = "production"
+	}
+
+	r := &Reporter{
+		opts:       opts,
+		httpClient: &http.Client{Timeout: sendTimeout},
+	}
+	if opts.DSN == "" {
+		return r, nil // Log only
+	}
+
+	dsn, err := url.Parse(opts.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("parsing DSN: %w", err)
+	}
+
+	switch dsn.Scheme {
+	case "rollbar":
+		r.provider = "rollbar"
+		r.endpoint = rollbarAPIURL
+		r.key = dsn.Host
+	case "http", "https":
+		project := strings.Trim(dsn.Path, "/")
+		if dsn.User == nil || dsn.User.Username() == "" || project == "" {
+			return nil, fmt.Errorf("invalid Sentry DSN: expected https://key@host/project")
+		}
+		r.provider = "sentry"
+		r.endpoint = fmt.Sprintf("%s://%s/api/%s/envelope/", dsn.Scheme, dsn.Host, project)
+		r.key = dsn.User.Username()
+	default:
+		return nil, fmt.Errorf("unsupported DSN scheme %q (use a Sentry DSN or rollbar://token)", dsn.Scheme)
+	}
+
+	if r.key == "" {
+		return nil, fmt.Errorf("DSN is missing its key")
+	}
+	return r, nil
+}
+
+// chatIDKey keys HashChatID; see SetChatIDKey
+var chatIDKey []byte
+
+// SetChatIDKey sets the secret HashChatID is keyed with (ERROR_REPORT_SALT). Every
+// program writing to the same reports and ledgers must use the same key. Without one,
+// chat IDs, being short numbers, can be recovered from their hashes by trying them all.
+func SetChatIDKey(key string) {
+	chatIDKey = []byte(key)
+}
+
+// HashChatID returns a short, stable HMAC of a chat ID, so reports from the same user
+// can be grouped without revealing who they are to anyone who doesn't know the key
+func HashChatID(chatID string) string {
+	mac := hmac.New(sha256.New, chatIDKey)
+	mac.Write([]byte(chatID))
+	return hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// report is one error or panic, independent of the service it's sent to
+type report struct {
+	id          string
+	level       string // "error" or "fatal"
+	errType     string
+	message     string
+	stack       string
+	tags        map[string]string
+	timestamp   time.Time
+	fingerprint string
+	location    string
+}
+
+// Error reports err. Failures to send are logged, never returned, so reporting can't
+// change how the caller handles err.
+func (r *Reporter) Error(ctx context.Context, err error, c Context) {
+	if r == nil || err == nil {
+		return
+	}
+	rep := r.newReport("error", fmt.Sprintf("%T", err), err.Error(), "", c)
+	root := rootError(err)
+	var callers string
+	rep.location, callers = callSite(3) // Skip runtime.Callers, callSite, and Error
+	rep.fingerprint = fingerprint(r.opts.Component, fmt.Sprintf("%T", root), root.Error(), rep.location)
+	r.log(rep, callers)
+	r.send(ctx, rep)
+}
+
+// Panic reports a recovered panic with its stack trace
+func (r *Reporter) Panic(ctx context.Context, value interface{}, stack []byte, c Context) {
+	if r == nil {
+		return
+	}
+	rep := r.newReport("fatal", "panic", fmt.Sprint(value), string(stack), c)
+	rep.location = panicLocation(string(stack))
+	rep.fingerprint = fingerprint(r.opts.Component, "panic", rep.message, rep.location)
+	r.log(rep, rep.stack)
+	r.send(ctx, rep)
+}
+
+// log appends a report to the error log, if there is one
+func (r *Reporter) log(rep *report, stack string) {
+	if r.opts.Log == nil {
+		return
+	}
+	err := r.opts.Log.AppendError(&storage.ErrorRecord{
+		At:          rep.timestamp.Format(time.RFC3339),
+		RunID:       r.opts.RunID,
+		Component:   r.opts.Component,
+		Fingerprint: rep.fingerprint,
+		Type:        rep.errType,
+		Message:     rep.message,
+		Location:    rep.location,
+		Stack:       stack,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging error report: %v\n", err)
+	}
+}
+
+func (r *Reporter) newReport(level, errType, message, stack string, c Context) *report {
+	tags := map[string]string{"component": r.opts.Component}
+	if c.Command != "" {
+		tags["command"] = c.Command
+	}
+	if c.ChatID != "" {
+		tags["chat"] = HashChatID(c.ChatID)
+	}
+	if c.EventID != "" {
+		tags["event_id"] = c.EventID
+	}
+	if c.URL != "" {
+		tags["url"] = c.URL
+	}
+
+	return &report{
+		id:        newEventID(),
+		level:     level,
+		errType:   errType,
+		message:   message,
+		stack:     stack,
+		tags:      tags,
+		timestamp: time.Now().UTC(),
+	}
+}
+
+// send delivers a report. It still runs after ctx is canceled (errors during shutdown
+// are worth seeing), bounded by sendTimeout.
+func (r *Reporter) send(ctx context.Context, rep *report) {
+	if r.provider == "" {
+		return // Log only
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+	defer cancel()
+
+	var body []byte
+	var err error
+	header := http.Header{}
+	switch r.provider {
+	case "rollbar":
+		body, err = r.rollbarPayload(rep)
+		header.Set("Content-Type", "application/json")
+		header.Set("X-Rollbar-Access-Token", r.key)
+	default:
+		body, err = r.sentryEnvelope(rep)
+		header.Set("Content-Type", "application/x-sentry-envelope")
+		header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, r.key))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: building error report: %v\n", err)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: creating error report request: %v\n", err)
+		return
+	}
+	req.Header = header
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: sending error report: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		fmt.Fprintf(os.Stderr, "Warning: %s rejected error report (status %d)\n", r.provider, resp.StatusCode)
+	}
+}
+
+// sentryEnvelope builds a Sentry envelope holding one event
+func (r *Reporter) sentryEnvelope(rep *report) ([]byte, error) {
+	type exception struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	evt := struct {
+		EventID     string                 `json:"event_id"`
+		Timestamp   string                 `json:"timestamp"`
+		Platform    string                 `json:"platform"`
+		Level       string                 `json:"level"`
+		Logger      string                 `json:"logger,omitempty"`
+		Environment string                 `json:"environment"`
+		Release     string                 `json:"release,omitempty"`
+		Exception   map[string]interface{} `json:"exception"`
+		Tags        map[string]string      `json:"tags"`
+		Extra       map[string]string      `json:"extra,omitempty"`
+	}{
+		EventID:     rep.id,
+		Timestamp:   rep.timestamp.Format(time.RFC3339),
+		Platform:    "go",
+		Level:       rep.level,
+		Logger:      r.opts.Component,
+		Environment: r.opts.Environment,
+		Release:     r.opts.Release,
+		Exception:   map[string]interface{}{"values": []exception{{Type: rep.errType, Value: rep.message}}},
+		Tags:        rep.tags,
+	}
+	if rep.stack != "" {
+		evt.Extra = map[string]string{"stack": rep.stack}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Encode adds the newline that ends each envelope line
+	if err := enc.Encode(map[string]string{"event_id": rep.id, "sent_at": rep.timestamp.Format(time.RFC3339)}); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(map[string]string{"type": "event"}); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(evt); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rollbarPayload builds a Rollbar item
+func (r *Reporter) rollbarPayload(rep *report) ([]byte, error) {
+	message := map[string]string{"body": fmt.Sprintf("%s: %s", rep.errType, rep.message)}
+	if rep.stack != "" {
+		message["stack"] = rep.stack
+	}
+
+	level := "error"
+	if rep.level == "fatal" {
+		level = "critical" // Rollbar's name for the most severe level
+	}
+
+	item := map[string]interface{}{
+		"data": map[string]interface{}{
+			"uuid":         rep.id,
+			"timestamp":    rep.timestamp.Unix(),
+			"environment":  r.opts.Environment,
+			"level":        level,
+			"platform":     "go",
+			"language":     "go",
+			"framework":    r.opts.Component,
+			"code_version": r.opts.Release,
+			"body":         map[string]interface{}{"message": message},
+			"custom":       rep.tags,
+		},
+	}
+	return json.Marshal(item)
+}
+
+// newEventID returns a random 32-character hex ID, the format Sentry expects
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
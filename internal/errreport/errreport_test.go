@@ -0,0 +1,211 @@
+package errreport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// capture records the last request a test server received
+type capture struct {
+	header http.Header
+	path   string
+	body   []byte
+}
+
+func newCaptureServer(t *testing.T) (*httptest.Server, *capture) {
+	t.Helper()
+	got := &capture{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.header = r.Header.Clone()
+		got.path = r.URL.Path
+		got.body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsn      string
+		wantNil  bool
+		wantErr  bool
+		provider string
+		endpoint string
+	}{
+		{name: "disabled", dsn: "", wantNil: true},
+		{name: "sentry", dsn: "https://abc123@o1.ingest.sentry.io/42", provider: "sentry", endpoint: "https://o1.ingest.sentry.io/api/42/envelope/"},
+		{name: "rollbar", dsn: "rollbar://token456", provider: "rollbar", endpoint: rollbarAPIURL},
+		{name: "sentry without key", dsn: "https://o1.ingest.sentry.io/42", wantErr: true},
+		{name: "sentry without project", dsn: "https://abc@o1.ingest.sentry.io/", wantErr: true},
+		{name: "rollbar without token", dsn: "rollbar://", wantErr: true},
+		{name: "unknown scheme", dsn: "ftp://x@y/1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Options{DSN: tt.dsn})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (r == nil) != tt.wantNil {
+				t.Fatalf("New() = %v, wantNil %v", r, tt.wantNil)
+			}
+			if r != nil && (r.provider != tt.provider || r.endpoint != tt.endpoint) {
+				t.Errorf("New() provider = %s endpoint = %s, want %s %s", r.provider, r.endpoint, tt.provider, tt.endpoint)
+			}
+		})
+	}
+}
+
+func TestNilReporter(t *testing.T) {
+	var r *Reporter
+	// Must not panic
+	r.Error(context.Background(), errors.New("boom"), Context{})
+	r.Panic(context.Background(), "boom", nil, Context{})
+}
+
+func TestSentryReport(t *testing.T) {
+	server, got := newCaptureServer(t)
+	dsn := strings.Replace(server.URL, "http://", "http://key789@", 1) + "/7"
+
+	r, err := New(Options{DSN: dsn, Component: "bot", Release: "1.2.3"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	r.Error(context.Background(), errors.New("fetching events: timeout"), Context{
+		Command: "/search",
+		ChatID:  "123456789",
+		EventID: "evt-1",
+	})
+
+	if got.path != "/api/7/envelope/" {
+		t.Errorf("path = %s, want /api/7/envelope/", got.path)
+	}
+	if auth := got.header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=key789") {
+		t.Errorf("X-Sentry-Auth = %q, want sentry_key", auth)
+	}
+
+	// Envelope: header line, item header line, event line
+	scanner := bufio.NewScanner(bytes.NewReader(got.body))
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("envelope has %d lines, want 3:\n%s", len(lines), got.body)
+	}
+
+	var evt struct {
+		Level     string            `json:"level"`
+		Release   string            `json:"release"`
+		Tags      map[string]string `json:"tags"`
+		Exception struct {
+			Values []struct {
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"exception"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &evt); err != nil {
+		t.Fatalf("parsing event: %v", err)
+	}
+	if evt.Level != "error" || evt.Release != "1.2.3" {
+		t.Errorf("level = %s release = %s, want error 1.2.3", evt.Level, evt.Release)
+	}
+	if len(evt.Exception.Values) != 1 || evt.Exception.Values[0].Value != "fetching events: timeout" {
+		t.Errorf("exception = %+v, want error message", evt.Exception)
+	}
+	if evt.Tags["command"] != "/search" || evt.Tags["event_id"] != "evt-1" || evt.Tags["component"] != "bot" {
+		t.Errorf("tags = %v, want command, event_id, and component", evt.Tags)
+	}
+	if evt.Tags["chat"] != HashChatID("123456789") {
+		t.Errorf("chat tag = %q, want hashed chat ID", evt.Tags["chat"])
+	}
+	if strings.Contains(string(got.body), "123456789") {
+		t.Error("raw chat ID should never be sent")
+	}
+}
+
+func TestRollbarPanicReport(t *testing.T) {
+	server, got := newCaptureServer(t)
+	original := rollbarAPIURL
+	rollbarAPIURL = server.URL + "/item/"
+	defer func() { rollbarAPIURL = original }()
+
+	r, err := New(Options{DSN: "rollbar://tok", Component: "bot"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	r.Panic(context.Background(), "nil map", []byte("goroutine 1 [running]:\nmain.handle()"), Context{Command: "status"})
+
+	if got.header.Get("X-Rollbar-Access-Token") != "tok" {
+		t.Errorf("access token header = %q, want tok", got.header.Get("X-Rollbar-Access-Token"))
+	}
+
+	var item struct {
+		Data struct {
+			Level string `json:"level"`
+			Body  struct {
+				Message struct {
+					Body  string `json:"body"`
+					Stack string `json:"stack"`
+				} `json:"message"`
+			} `json:"body"`
+			Custom map[string]string `json:"custom"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(got.body, &item); err != nil {
+		t.Fatalf("parsing item: %v", err)
+	}
+	if item.Data.Level != "critical" {
+		t.Errorf("level = %s, want critical", item.Data.Level)
+	}
+	if item.Data.Body.Message.Body != "panic: nil map" || !strings.Contains(item.Data.Body.Message.Stack, "main.handle") {
+		t.Errorf("message = %+v, want panic with stack", item.Data.Body.Message)
+	}
+	if item.Data.Custom["command"] != "status" {
+		t.Errorf("custom = %v, want command tag", item.Data.Custom)
+	}
+}
+
+func TestReportAfterCancel(t *testing.T) {
+	server, got := newCaptureServer(t)
+	dsn := strings.Replace(server.URL, "http://", "http://k@", 1) + "/1"
+	r, _ := New(Options{DSN: dsn})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Error(ctx, errors.New("during shutdown"), Context{})
+
+	if len(got.body) == 0 {
+		t.Error("reports should still be sent after ctx is canceled")
+	}
+}
+
+func TestHashChatID(t *testing.T) {
+	a, b := HashChatID("123"), HashChatID("124")
+	if a == b || len(a) != 12 || a != HashChatID("123") {
+		t.Errorf("HashChatID should be stable, distinct, and 12 chars: %q %q", a, b)
+	}
+
+	// The hash depends on the deployment's key, so it can't be reversed without it
+	SetChatIDKey("secret")
+	t.Cleanup(func() { SetChatIDKey("") })
+	if keyed := HashChatID("123"); keyed == a || keyed != HashChatID("123") {
+		t.Errorf("keyed HashChatID = %q, want a stable hash different from the unkeyed %q", keyed, a)
+	}
+}
@@ -0,0 +1,98 @@
+package errreport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// locationFrames is how many functions, innermost first, identify where an error was
+// reported. Two tell apart errors reported through a shared helper.
+const locationFrames = 2
+
+// maxStackFrames caps the frames kept as stack context for an error
+const maxStackFrames = 12
+
+// digits matches the numbers in error messages (IDs, status codes, counts) that vary
+// between occurrences of the same error
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// fingerprint identifies one recurring error: the same root error, reported from the
+// same place, by the same component. Numbers in the message are ignored; line numbers
+// aren't part of the location, so the fingerprint survives unrelated edits.
+func fingerprint(component, rootType, rootMessage, location string) string {
+	key := strings.Join([]string{component, rootType, digits.ReplaceAllString(rootMessage, "N"), location}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
+
+// rootError returns the innermost error err wraps
+func rootError(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// callSite returns the functions that reported an error, innermost first (e.g.
+// "cli.runCheck ← cli.Execute"), and the stack as context. skip is as for
+// runtime.Callers.
+func callSite(skip int) (string, string) {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var funcs []string
+	var stack strings.Builder
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			if len(funcs) < locationFrames {
+				funcs = append(funcs, path.Base(frame.Function))
+			}
+			fmt.Fprintf(&stack, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.Join(funcs, " ← "), stack.String()
+}
+
+// panicLocation returns the functions that panicked, innermost first, from a
+// debug.Stack trace: the first frames outside the runtime after its panic frame
+func panicLocation(stack string) string {
+	var funcs []string
+	afterPanic := false
+	for _, line := range strings.Split(stack, "\n") {
+		if line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "goroutine ") {
+			continue
+		}
+		if strings.HasPrefix(line, "panic(") {
+			afterPanic = true
+			continue
+		}
+		if !afterPanic || strings.HasPrefix(line, "runtime.") {
+			continue // Runtime frames, such as a nil map assignment's, aren't the caller's code
+		}
+		if i := strings.LastIndex(line, "("); i > 0 && strings.HasSuffix(line, ")") {
+			line = line[:i]
+		}
+		funcs = append(funcs, path.Base(line))
+		if len(funcs) == locationFrames {
+			break
+		}
+	}
+	if len(funcs) == 0 {
+		return "unknown"
+	}
+	return strings.Join(funcs, " ← ")
+}
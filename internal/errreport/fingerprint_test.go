@@ -0,0 +1,104 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+// memoryLog keeps appended error records
+type memoryLog struct {
+	records []*storage.ErrorRecord
+}
+
+func (l *memoryLog) AppendError(r *storage.ErrorRecord) error {
+	l.records = append(l.records, r)
+	return nil
+}
+
+var errTimeout = errors.New("timeout after 30s")
+
+// reportFetch reports err the way one call site would, for fingerprint tests
+func reportFetch(r *Reporter, err error) {
+	r.Error(context.Background(), err, Context{})
+}
+
+// reportSave is a second call site
+func reportSave(r *Reporter, err error) {
+	r.Error(context.Background(), err, Context{})
+}
+
+func TestErrorLogFingerprints(t *testing.T) {
+	log := &memoryLog{}
+	r, err := New(Options{Component: "cli", Log: log, RunID: "7"})
+	if err != nil || r == nil {
+		t.Fatalf("New() with only a log = %v, %v; want a Reporter", r, err)
+	}
+
+	reportFetch(r, fmt.Errorf("fetching page 1: %w", errTimeout))
+	reportFetch(r, fmt.Errorf("fetching page 12: %w", errTimeout))
+	reportFetch(r, fmt.Errorf("fetching page 3: %w", errors.New("connection reset")))
+	reportSave(r, fmt.Errorf("saving: %w", errTimeout))
+
+	if len(log.records) != 4 {
+		t.Fatalf("logged %d records, want 4", len(log.records))
+	}
+	first := log.records[0]
+	if first.RunID != "7" || first.Component != "cli" || first.Message != "fetching page 1: timeout after 30s" {
+		t.Errorf("record = %+v", first)
+	}
+	if !strings.HasPrefix(first.Location, "errreport.reportFetch ← errreport.TestErrorLogFingerprints") {
+		t.Errorf("location = %q; want the reporting function and its caller", first.Location)
+	}
+	if !strings.Contains(first.Stack, "fingerprint_test.go") {
+		t.Errorf("stack should include the call site:\n%s", first.Stack)
+	}
+
+	fp := func(i int) string { return log.records[i].Fingerprint }
+	if fp(0) != fp(1) {
+		t.Error("the same root error from the same place should share a fingerprint")
+	}
+	if fp(0) == fp(2) {
+		t.Error("a different root error should get its own fingerprint")
+	}
+	if fp(0) == fp(3) {
+		t.Error("the same error from a different place should get its own fingerprint")
+	}
+}
+
+func TestPanicLocation(t *testing.T) {
+	var stack string
+	func() {
+		defer func() {
+			if recover() != nil {
+				stack = string(debug.Stack())
+			}
+		}()
+		panicky()
+	}()
+
+	if got := panicLocation(stack); !strings.HasPrefix(got, "errreport.panicky ← errreport.TestPanicLocation") {
+		t.Errorf("panicLocation() = %q\n%s", got, stack)
+	}
+	if got := panicLocation("not a stack"); got != "unknown" {
+		t.Errorf("panicLocation() of junk = %q", got)
+	}
+
+	log := &memoryLog{}
+	r, _ := New(Options{Component: "bot", Log: log})
+	r.Panic(context.Background(), "boom 1", []byte(stack), Context{})
+	r.Panic(context.Background(), "boom 2", []byte(stack), Context{})
+	if len(log.records) != 2 || log.records[0].Type != "panic" || log.records[0].Fingerprint != log.records[1].Fingerprint {
+		t.Errorf("panic records = %+v", log.records)
+	}
+}
+
+func panicky() {
+	var m map[string]int
+	m["x"] = 1
+}
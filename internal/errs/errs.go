@@ -0,0 +1,163 @@
+// Package errs classifies failures from the external services the project talks to
+// (Telegram, the VGA website, the Golf Course API, GitHub Gists), so callers can decide
+// whether to retry, skip, or give up with errors.Is instead of matching message text.
+//
+// Errors keep their original messages; the class is attached by wrapping:
+//
+//	err := errs.Status(resp.StatusCode, "API returned status %d", resp.StatusCode)
+//	if errors.Is(err, errs.ErrNotFound) { ... }
+//	if errs.IsRetryable(err) { ... }
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrRateLimited means the service asked us to slow down (HTTP 429)
+	ErrRateLimited = errors.New("rate limited")
+	// ErrNotFound means the requested chat, course, page, or event doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrAuth means the credentials were rejected or access was denied (HTTP 401/403),
+	// e.g. an invalid token or a user who blocked the bot
+	ErrAuth = errors.New("not authorized")
+	// ErrTransient means a temporary server or network failure worth retrying
+	ErrTransient = errors.New("temporary failure")
+	// ErrConflict means the resource changed underneath the request (HTTP 409/412);
+	// retrying the same request won't help, but redoing the change on a fresh copy may
+	ErrConflict = errors.New("conflict")
+)
+
+// StatusError is an HTTP error response. Its message is the caller's; errors.Is
+// matches the class for its status code.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // How long the service asked us to wait, if it said
+	// Class overrides the class derived from StatusCode, for services that report the
+	// real reason in the body (e.g. Telegram's 400 "chat not found")
+	Class error
+	msg   string
+}
+
+// Status returns a StatusError for an HTTP status code with a formatted message
+func Status(code int, format string, args ...interface{}) *StatusError {
+	return &StatusError{StatusCode: code, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *StatusError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the class for the status code, or nil for unclassified codes (e.g. 400)
+func (e *StatusError) Unwrap() error {
+	if e.Class != nil {
+		return e.Class
+	}
+	return classify(e.StatusCode)
+}
+
+// classify maps an HTTP status code to an error class
+func classify(code int) error {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code == http.StatusNotFound || code == http.StatusGone:
+		return ErrNotFound
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrAuth
+	case code == http.StatusConflict || code == http.StatusPreconditionFailed:
+		return ErrConflict
+	case code == http.StatusRequestTimeout || code >= 500:
+		return ErrTransient
+	}
+	return nil
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds, returning 0 if it's
+// missing or not a number
+func ParseRetryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// IsRetryable reports whether err is a temporary failure: a rate limit, a server error,
+// or a network error. Canceled requests and unknown hosts are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrTransient) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	var netErr net.Error // Includes *url.Error from http.Client.Do
+	return errors.As(err, &netErr)
+}
+
+// IsPermanent reports whether retrying err can't help: the target doesn't exist or
+// we aren't allowed to reach it
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrAuth)
+}
+
+// retryAfter returns the wait the service asked for, if any
+func retryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
+
+// maxRetryDelay caps the wait between attempts, including requested Retry-After waits
+const maxRetryDelay = 30 * time.Second
+
+// Backoff describes how failed calls are retried
+type Backoff struct {
+	Attempts  int           // Total calls, including the first
+	BaseDelay time.Duration // Wait before the first retry; doubles after each attempt
+}
+
+// DefaultBackoff makes three attempts, waiting 1s and then 2s between them
+var DefaultBackoff = Backoff{Attempts: 3, BaseDelay: time.Second}
+
+// Retry calls fn until it succeeds or Attempts calls have been made, waiting between
+// attempts with exponential backoff (or longer, if the service sent Retry-After). Only
+// retryable errors are retried; the last error is returned. Waiting stops early if ctx
+// is canceled.
+func (b Backoff) Retry(ctx context.Context, fn func() error) error {
+	delay := b.BaseDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= b.Attempts || !IsRetryable(err) {
+			return err
+		}
+
+		wait := delay
+		if requested := retryAfter(err); requested > wait {
+			wait = requested
+		}
+		wait = min(wait, maxRetryDelay)
+		delay *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStatusClassification(t *testing.T) {
+	tests := []struct {
+		code      int
+		want      error
+		retryable bool
+		permanent bool
+	}{
+		{http.StatusTooManyRequests, ErrRateLimited, true, false},
+		{http.StatusNotFound, ErrNotFound, false, true},
+		{http.StatusGone, ErrNotFound, false, true},
+		{http.StatusUnauthorized, ErrAuth, false, true},
+		{http.StatusForbidden, ErrAuth, false, true},
+		{http.StatusRequestTimeout, ErrTransient, true, false},
+		{http.StatusInternalServerError, ErrTransient, true, false},
+		{http.StatusServiceUnavailable, ErrTransient, true, false},
+		{http.StatusConflict, ErrConflict, false, false},
+		{http.StatusPreconditionFailed, ErrConflict, false, false},
+		{http.StatusBadRequest, nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code), func(t *testing.T) {
+			err := fmt.Errorf("fetching: %w", Status(tt.code, "status %d", tt.code))
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			if got := IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+			if got := IsPermanent(err); got != tt.permanent {
+				t.Errorf("IsPermanent() = %v, want %v", got, tt.permanent)
+			}
+			if err.Error() != fmt.Sprintf("fetching: status %d", tt.code) {
+				t.Errorf("Error() = %q, message should be kept", err.Error())
+			}
+		})
+	}
+}
+
+func TestStatusClassOverride(t *testing.T) {
+	err := Status(http.StatusBadRequest, "chat not found")
+	err.Class = ErrNotFound
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("Class should override the status code's class")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("invalid input"), false},
+		{"canceled", fmt.Errorf("sending: %w", context.Canceled), false},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, false},
+		{"wrapped transient", fmt.Errorf("x: %w", ErrTransient), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    0,
+		"5":   5 * time.Second,
+		"-1":  0,
+		"abc": 0,
+	}
+	for value, want := range tests {
+		header := http.Header{}
+		if value != "" {
+			header.Set("Retry-After", value)
+		}
+		if got := ParseRetryAfter(header); got != want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestBackoffRetry(t *testing.T) {
+	backoff := Backoff{Attempts: 3, BaseDelay: time.Millisecond}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		err := backoff.Retry(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return Status(http.StatusBadGateway, "bad gateway")
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("Retry() = %v after %d calls, want nil after 3", err, calls)
+		}
+	})
+
+	t.Run("gives up after attempts", func(t *testing.T) {
+		calls := 0
+		err := backoff.Retry(context.Background(), func() error {
+			calls++
+			return Status(http.StatusServiceUnavailable, "unavailable")
+		})
+		if !errors.Is(err, ErrTransient) || calls != 3 {
+			t.Errorf("Retry() = %v after %d calls, want ErrTransient after 3", err, calls)
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		calls := 0
+		err := backoff.Retry(context.Background(), func() error {
+			calls++
+			return Status(http.StatusNotFound, "missing")
+		})
+		if !errors.Is(err, ErrNotFound) || calls != 1 {
+			t.Errorf("Retry() = %v after %d calls, want ErrNotFound after 1", err, calls)
+		}
+	})
+
+	t.Run("stops when canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := Backoff{Attempts: 5, BaseDelay: time.Hour}
+		calls := 0
+		err := slow.Retry(ctx, func() error {
+			calls++
+			cancel()
+			return Status(http.StatusInternalServerError, "boom")
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("Retry() = %v after %d calls, want error after 1", err, calls)
+		}
+	})
+}
@@ -47,14 +47,14 @@ func ParseDate(dateText string) time.Time {
 	t, err = time.Parse("Jan 02", dateText)
 	if err == nil {
 		// Add the current year
-		now := time.Now()
+		now := clk.Now()
 		return time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 	}
 
 	// Try "Jan 2" format (single digit day, no year)
 	t, err = time.Parse("Jan 2", dateText)
 	if err == nil {
-		now := time.Now()
+		now := clk.Now()
 		return time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 	}
 
@@ -65,16 +65,26 @@ func ParseDate(dateText string) time.Time {
 // IsPastEvent checks if an event's date has passed.
 // Returns false if the date cannot be parsed (safer default).
 func (e *Event) IsPastEvent() bool {
+	return e.IsPastEventAt(clk.Now())
+}
+
+// IsPastEventAt checks if an event's date was already past at the given time
+func (e *Event) IsPastEventAt(now time.Time) bool {
 	parsed := ParseDate(e.DateText)
 	if parsed.IsZero() {
 		return false // Can't determine, don't filter
 	}
-	return parsed.Before(time.Now())
+	return parsed.Before(now)
 }
 
 // IsWithinDays checks if an event is within N days from now.
 // Returns true if days <= 0 (feature disabled) or date is unparseable.
 func (e *Event) IsWithinDays(days int) bool {
+	return e.IsWithinDaysAt(days, clk.Now())
+}
+
+// IsWithinDaysAt checks if an event is within N days of the given time
+func (e *Event) IsWithinDaysAt(days int, now time.Time) bool {
 	if days <= 0 {
 		return true // Feature disabled
 	}
@@ -82,7 +92,6 @@ func (e *Event) IsWithinDays(days int) bool {
 	if parsed.IsZero() {
 		return true // Can't determine, include it
 	}
-	now := time.Now()
 	cutoff := now.AddDate(0, 0, days)
 	// Compare dates only (truncate to start of day) to include today's events
 	nowDate := now.Truncate(24 * time.Hour)
@@ -96,7 +105,59 @@ func (e *Event) IsUpcoming() bool {
 	if parsed.IsZero() {
 		return true // Can't determine, include it
 	}
-	return parsed.After(time.Now())
+	return parsed.After(clk.Now())
+}
+
+// DaysUntilDeadlineAt returns how many calendar days remain until the event's
+// registration deadline (0 on the deadline day, negative once it has passed), and false
+// if there's no deadline or it can't be parsed
+func (e *Event) DaysUntilDeadlineAt(now time.Time) (int, bool) {
+	deadline := ParseDate(e.RegistrationDeadline)
+	if deadline.IsZero() {
+		return 0, false
+	}
+	return daysBetween(now, deadline), true
+}
+
+// DaysUntilAt returns how many calendar days remain until the event (0 on the day,
+// negative once it has passed), and false if its date can't be parsed
+func (e *Event) DaysUntilAt(now time.Time) (int, bool) {
+	date := ParseDate(e.DateText)
+	if date.IsZero() {
+		return 0, false
+	}
+	return daysBetween(now, date), true
+}
+
+// daysBetween counts calendar days from now's date to day's date. Both dates are taken
+// as written and compared in UTC, so a daylight saving change in now's time zone
+// can't make a day 23 or 25 hours long.
+func daysBetween(now, day time.Time) int {
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// FormatDeadline returns a short registration deadline line like "Register by Mar 28",
+// or "Registration closed Mar 28" once it has passed. Returns "" if there's no deadline.
+func (e *Event) FormatDeadline(now time.Time) string {
+	if e.RegistrationDeadline == "" {
+		return ""
+	}
+	days, ok := e.DaysUntilDeadlineAt(now)
+	if !ok {
+		return "Register by " + e.RegistrationDeadline
+	}
+	date := ParseDate(e.RegistrationDeadline).Format("Jan 2")
+	switch {
+	case days < 0:
+		return "Registration closed " + date
+	case days == 0:
+		return "Register by today (" + date + ")"
+	case days == 1:
+		return "Register by tomorrow (" + date + ")"
+	}
+	return "Register by " + date
 }
 
 // SortByDate sorts events by date (soonest first).
@@ -144,10 +205,7 @@ func FormatDateNice(dateText string) string {
 	formatted := parsed.Format("Mon, Jan 2, 2006")
 
 	// Add countdown
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	eventDate := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
-	daysUntil := int(eventDate.Sub(today).Hours() / 24)
+	daysUntil := daysBetween(clk.Now(), parsed)
 
 	if daysUntil == 0 {
 		formatted += " (today!)"
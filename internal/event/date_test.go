@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/clock"
+	"github.com/pfrederiksen/vga-events/internal/testutil"
 )
 
 func TestParseDate(t *testing.T) {
@@ -449,3 +452,98 @@ func TestFormatDateNice_RelativeTime(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatDateNiceUsesClock(t *testing.T) {
+	t.Cleanup(func() { SetClock(clock.System) })
+	SetClock(testutil.NewFakeClock(time.Date(2026, 12, 30, 9, 0, 0, 0, time.UTC)))
+
+	if got := FormatDateNice("Jan 2 2027"); !strings.Contains(got, "(in 3 days)") {
+		t.Errorf("FormatDateNice(Jan 2 2027) = %q, want it 3 days away", got)
+	}
+	if got := FormatDateNice("Dec 29"); !strings.Contains(got, "2026 (yesterday)") {
+		t.Errorf("FormatDateNice(Dec 29) = %q, want yesterday in the clock's year", got)
+	}
+}
+
+func TestEvent_FormatDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 26, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		deadline string
+		want     string
+		days     int
+		ok       bool
+	}{
+		{"", "", 0, false},
+		{"Mar 28 2026", "Register by Mar 28", 2, true},
+		{"3.27.26", "Register by tomorrow (Mar 27)", 1, true},
+		{"Mar 26 2026", "Register by today (Mar 26)", 0, true},
+		{"Mar 20 2026", "Registration closed Mar 20", -6, true},
+		{"end of month", "Register by end of month", 0, false},
+	}
+	for _, tt := range tests {
+		evt := &Event{RegistrationDeadline: tt.deadline}
+		if got := evt.FormatDeadline(now); got != tt.want {
+			t.Errorf("FormatDeadline(%q) = %q, want %q", tt.deadline, got, tt.want)
+		}
+		if days, ok := evt.DaysUntilDeadlineAt(now); days != tt.days || ok != tt.ok {
+			t.Errorf("DaysUntilDeadlineAt(%q) = %d, %v; want %d, %v", tt.deadline, days, ok, tt.days, tt.ok)
+		}
+	}
+}
+
+func TestEvent_DaysUntilAt(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		date string
+		want int
+	}{
+		{"spring forward", time.Date(2026, 3, 7, 22, 0, 0, 0, la), "Mar 9 2026", 2},
+		{"fall back", time.Date(2026, 10, 31, 1, 0, 0, 0, la), "Nov 2 2026", 2},
+		{"year boundary", time.Date(2026, 12, 30, 23, 30, 0, 0, la), "Jan 2 2027", 3},
+		{"day of", time.Date(2026, 3, 8, 23, 59, 0, 0, la), "Mar 8 2026", 0},
+		{"passed", time.Date(2027, 1, 1, 0, 5, 0, 0, la), "Dec 31 2026", -1},
+	}
+	for _, tt := range tests {
+		evt := &Event{DateText: tt.date}
+		if got, ok := evt.DaysUntilAt(tt.now); !ok || got != tt.want {
+			t.Errorf("%s: DaysUntilAt() = %d, %v; want %d", tt.name, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := (&Event{DateText: "TBD"}).DaysUntilAt(time.Now()); ok {
+		t.Error("DaysUntilAt() should fail for an unparseable date")
+	}
+}
+
+func TestEvent_DaysUntilAtCountdown(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	// Counting down a day at a time through a daylight saving change and into a new
+	// year hits every value once, ending at 0 on the day
+	for _, tt := range []struct {
+		start time.Time
+		date  string
+		days  int
+	}{
+		{time.Date(2026, 3, 1, 8, 0, 0, 0, la), "Mar 15 2026", 14},
+		{time.Date(2026, 12, 25, 8, 0, 0, 0, la), "Jan 3 2027", 9},
+	} {
+		clk := testutil.NewFakeClock(tt.start)
+		evt := &Event{DateText: tt.date}
+		for want := tt.days; want >= 0; want-- {
+			if got, _ := evt.DaysUntilAt(clk.Now()); got != want {
+				t.Errorf("%s on %s: DaysUntilAt() = %d, want %d", tt.date, clk.Now().Format("Jan 2"), got, want)
+			}
+			clk.AddDate(0, 0, 1)
+		}
+	}
+}
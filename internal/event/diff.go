@@ -10,12 +10,21 @@ import (
 
 // Snapshot represents a collection of events at a point in time
 type Snapshot struct {
-	Events        map[string]*Event `json:"events"`         // keyed by Event.ID
-	RemovedEvents map[string]*Event `json:"removed_events"` // recently removed events (kept for 30 days)
-	StableIndex   map[string]string `json:"stable_index"`   // StableKey → ID mapping
-	ChangeLog     []*EventChange    `json:"change_log"`     // Recent changes
-	CourseCache   *course.Cache     `json:"course_cache"`   // Cached course information
-	UpdatedAt     string            `json:"updated_at"`     // RFC3339 timestamp
+	Events        map[string]*Event `json:"events"`                // keyed by Event.ID
+	RemovedEvents map[string]*Event `json:"removed_events"`        // recently removed events (kept for 30 days by default)
+	StableIndex   map[string]string `json:"stable_index"`          // StableKey → ID mapping
+	ShortCodes    map[string]string `json:"short_codes,omitempty"` // ShortCode → ID mapping
+	ChangeLog     []*EventChange    `json:"change_log"`            // Recent changes
+	CourseCache   *course.Cache     `json:"course_cache"`          // Cached course information
+	UpdatedAt     string            `json:"updated_at"`            // RFC3339 timestamp
+
+	// StatesSeen maps each state that has ever listed an event to when it was first
+	// seen (RFC3339), so a state that gains its first event can be announced
+	StatesSeen map[string]string `json:"states_seen,omitempty"`
+
+	// SchemaVersion is the snapshot format (see DecodeSnapshot); zero in files saved
+	// before it was recorded
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
 
 // NewSnapshot creates an empty snapshot
@@ -24,35 +33,109 @@ func NewSnapshot() *Snapshot {
 		Events:        make(map[string]*Event),
 		RemovedEvents: make(map[string]*Event),
 		StableIndex:   make(map[string]string),
+		ShortCodes:    make(map[string]string),
 		ChangeLog:     make([]*EventChange, 0),
 		CourseCache:   course.NewCache(),
+		SchemaVersion: SnapshotSchemaVersion,
 	}
 }
 
+// Listed returns the snapshot's events that the last scrape found, keyed by ID. Events
+// missing from it but not yet confirmed removed (MissingCount > 0) are only kept so the
+// next run can count them, and are left out.
+func (s *Snapshot) Listed() map[string]*Event {
+	listed := make(map[string]*Event, len(s.Events))
+	for id, evt := range s.Events {
+		if evt.MissingCount == 0 {
+			listed[id] = evt
+		}
+	}
+	return listed
+}
+
 // DiffResult contains the results of comparing two snapshots
 type DiffResult struct {
 	NewEvents     []*Event
 	RemovedEvents []*Event
 	States        map[string][]*Event // new events grouped by state
+
+	// RestoredEvents are events back on the site after being reported removed. They
+	// aren't in NewEvents, since users may already have been told about them.
+	RestoredEvents []*Event
+
+	// Renames link events that came back under a slightly different title to the
+	// events they replace ("renamed" changes, EventID the new ID). Neither side is in
+	// NewEvents or RemovedEvents.
+	Renames []*EventChange
+
+	// Missing holds events absent from this scrape that haven't been missing long enough
+	// to count as removed. Callers keep them in the next snapshot so a later scrape can
+	// confirm the removal or find them again.
+	Missing []*Event
+}
+
+// DiffCounts is how many events one run added, changed, and removed
+type DiffCounts struct {
+	New     int
+	Changed int
+	Removed int
+}
+
+// Total returns the number of events counted
+func (c DiffCounts) Total() int {
+	return c.New + c.Changed + c.Removed
+}
+
+// CountByState tallies a diff by state. An event with several changes (date and city,
+// say) counts once. stateOf resolves a changed event's ID to its state; changes it can't
+// resolve are counted under "".
+func CountByState(newEvents, removedEvents []*Event, changes []*EventChange, stateOf func(id string) string) map[string]DiffCounts {
+	counts := make(map[string]DiffCounts)
+	for _, evt := range newEvents {
+		c := counts[evt.State]
+		c.New++
+		counts[evt.State] = c
+	}
+	for _, evt := range removedEvents {
+		c := counts[evt.State]
+		c.Removed++
+		counts[evt.State] = c
+	}
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		if seen[change.EventID] {
+			continue
+		}
+		seen[change.EventID] = true
+		state := stateOf(change.EventID)
+		c := counts[state]
+		c.Changed++
+		counts[state] = c
+	}
+	return counts
 }
 
 // MarkDuplicates identifies duplicate events across states and marks them
 // Events with the same normalized course name and date are considered duplicates
 // The first event (alphabetically by state) is kept as primary, others are marked with AlsoIn
 func MarkDuplicates(events []*Event) []*Event {
-	// Build duplication index: key -> list of events
-	dupIndex := make(map[string][]*Event)
+	// Build duplication index: key -> list of events, remembering first-seen key order
+	dupIndex := make(map[string][]*Event, len(events))
+	keys := make([]string, 0, len(events))
 
 	for _, evt := range events {
 		dupKey := GenerateDuplicationKey(evt.Title, evt.DateText)
+		if _, exists := dupIndex[dupKey]; !exists {
+			keys = append(keys, dupKey)
+		}
 		dupIndex[dupKey] = append(dupIndex[dupKey], evt)
 	}
 
 	// For each duplicate group, mark the duplicates
-	deduped := make([]*Event, 0)
-	seen := make(map[string]bool)
+	deduped := make([]*Event, 0, len(keys))
 
-	for _, evtList := range dupIndex {
+	for _, key := range keys {
+		evtList := dupIndex[key]
 		if len(evtList) <= 1 {
 			// Not a duplicate, include as-is
 			deduped = append(deduped, evtList[0])
@@ -68,10 +151,9 @@ func MarkDuplicates(events []*Event) []*Event {
 		primary := evtList[0]
 
 		// Collect other states
-		otherStates := make([]string, 0)
+		otherStates := make([]string, 0, len(evtList)-1)
 		for _, evt := range evtList[1:] {
 			otherStates = append(otherStates, evt.State)
-			seen[evt.ID] = true // Mark as seen to skip later
 		}
 
 		// Mark primary with other states
@@ -86,6 +168,17 @@ func MarkDuplicates(events []*Event) []*Event {
 
 // Diff compares current events against a previous snapshot and returns new and removed events
 func Diff(previous *Snapshot, current []*Event, stateFilter string) *DiffResult {
+	return DiffConfirmed(previous, current, stateFilter, 1)
+}
+
+// DiffConfirmed is Diff, but an event is only reported removed once it has been missing
+// from confirmRuns consecutive scrapes, so one bad scrape can't remove everything. Until
+// then it's returned in Missing with its MissingCount and MissingSince updated; if it
+// comes back it's neither new nor removed. confirmRuns below 1 is treated as 1.
+func DiffConfirmed(previous *Snapshot, current []*Event, stateFilter string, confirmRuns int) *DiffResult {
+	if confirmRuns < 1 {
+		confirmRuns = 1
+	}
 	result := &DiffResult{
 		NewEvents:     make([]*Event, 0),
 		RemovedEvents: make([]*Event, 0),
@@ -97,55 +190,67 @@ func Diff(previous *Snapshot, current []*Event, stateFilter string) *DiffResult
 	}
 
 	// Build current event ID map for removal detection
-	currentIDs := make(map[string]bool)
+	currentIDs := make(map[string]struct{}, len(current))
 	for _, evt := range current {
-		currentIDs[evt.ID] = true
+		currentIDs[evt.ID] = struct{}{}
 	}
 
+	filterState := stateFilter != "" && stateFilter != "ALL"
+
 	// Check for new events
 	for _, evt := range current {
 		// Apply state filter
-		if stateFilter != "" && stateFilter != "ALL" {
-			if !strings.EqualFold(evt.State, stateFilter) {
-				continue
-			}
+		if filterState && !strings.EqualFold(evt.State, stateFilter) {
+			continue
 		}
 
 		// Check if this event exists in previous snapshot
-		if _, exists := previous.Events[evt.ID]; !exists {
-			result.NewEvents = append(result.NewEvents, evt)
-
-			// Group by state
-			if result.States[evt.State] == nil {
-				result.States[evt.State] = make([]*Event, 0)
-			}
-			result.States[evt.State] = append(result.States[evt.State], evt)
+		if _, exists := previous.Events[evt.ID]; exists {
+			continue
 		}
+		if _, removed := previous.RemovedEvents[evt.ID]; removed {
+			result.RestoredEvents = append(result.RestoredEvents, evt)
+			continue
+		}
+		result.NewEvents = append(result.NewEvents, evt)
 	}
 
 	// Check for removed events (in previous but not in current)
+	var absent []*Event
 	for eventID, evt := range previous.Events {
-		if !currentIDs[eventID] {
-			// Apply state filter
-			if stateFilter != "" && stateFilter != "ALL" {
-				if !strings.EqualFold(evt.State, stateFilter) {
-					continue
-				}
-			}
+		if _, exists := currentIDs[eventID]; exists {
+			continue
+		}
+		// Apply state filter
+		if filterState && !strings.EqualFold(evt.State, stateFilter) {
+			continue
+		}
+		absent = append(absent, evt)
+	}
 
-			result.RemovedEvents = append(result.RemovedEvents, evt)
+	// A new event standing in for an absent one (same date and city, similar title)
+	// is a rename rather than a removal plus a new event
+	now := clk.Now().UTC()
+	result.NewEvents, absent, result.Renames = linkRenames(result.NewEvents, absent, now)
+
+	for _, evt := range absent {
+		missing := *evt
+		missing.MissingCount++
+		if missing.MissingSince.IsZero() {
+			missing.MissingSince = now
+		}
+		if missing.MissingCount < confirmRuns {
+			result.Missing = append(result.Missing, &missing)
+			continue
 		}
+		missing.MissingCount = 0
+		missing.MissingSince = time.Time{}
+		result.RemovedEvents = append(result.RemovedEvents, &missing)
 	}
 
-	// Mark duplicates across states before sorting
+	// Mark duplicates across states, then group by state
 	result.NewEvents = MarkDuplicates(result.NewEvents)
-
-	// Rebuild state groups after deduplication
-	result.States = make(map[string][]*Event)
 	for _, evt := range result.NewEvents {
-		if result.States[evt.State] == nil {
-			result.States[evt.State] = make([]*Event, 0)
-		}
 		result.States[evt.State] = append(result.States[evt.State], evt)
 	}
 
@@ -157,13 +262,15 @@ func Diff(previous *Snapshot, current []*Event, stateFilter string) *DiffResult
 		return result.NewEvents[i].Raw < result.NewEvents[j].Raw
 	})
 
-	// Sort removed events for consistent output
-	sort.Slice(result.RemovedEvents, func(i, j int) bool {
-		if result.RemovedEvents[i].State != result.RemovedEvents[j].State {
-			return result.RemovedEvents[i].State < result.RemovedEvents[j].State
-		}
-		return result.RemovedEvents[i].Raw < result.RemovedEvents[j].Raw
-	})
+	// Sort removed and restored events for consistent output
+	for _, events := range [][]*Event{result.RemovedEvents, result.RestoredEvents} {
+		sort.Slice(events, func(i, j int) bool {
+			if events[i].State != events[j].State {
+				return events[i].State < events[j].State
+			}
+			return events[i].Raw < events[j].Raw
+		})
+	}
 
 	// Sort within each state group
 	for state := range result.States {
@@ -186,6 +293,10 @@ func CreateSnapshot(events []*Event, updatedAt string) *Snapshot {
 		if evt.StableKey != "" {
 			snap.StableIndex[evt.StableKey] = evt.ID
 		}
+		// Build short code index
+		if evt.ShortCode != "" {
+			snap.ShortCodes[evt.ShortCode] = evt.ID
+		}
 	}
 
 	return snap
@@ -197,7 +308,7 @@ func (s *Snapshot) StoreRemovedEvents(removed []*Event) {
 		s.RemovedEvents = make(map[string]*Event)
 	}
 
-	now := time.Now().UTC()
+	now := clk.Now().UTC()
 	for _, evt := range removed {
 		// Set RemovedAt timestamp if not already set
 		if evt.RemovedAt.IsZero() {
@@ -207,32 +318,49 @@ func (s *Snapshot) StoreRemovedEvents(removed []*Event) {
 	}
 }
 
+// DefaultRemovedRetentionDays is how long removed events are kept in a snapshot unless
+// a deployment configures otherwise
+const DefaultRemovedRetentionDays = 30
+
 // CleanupRemovedEvents removes events that were removed more than 30 days ago
 func (s *Snapshot) CleanupRemovedEvents() int {
-	if s.RemovedEvents == nil {
-		return 0
-	}
+	return len(s.ExpireRemovedEvents(DefaultRemovedRetentionDays, clk.Now()))
+}
 
-	cutoff := time.Now().AddDate(0, 0, -30)
-	removed := 0
+// ExpireRemovedEvents removes events that were removed more than days before now and
+// returns them, oldest removal first, so the caller can archive them
+func (s *Snapshot) ExpireRemovedEvents(days int, now time.Time) []*Event {
+	expired := s.RemovedBefore(now.AddDate(0, 0, -days))
+	for _, evt := range expired {
+		delete(s.RemovedEvents, evt.ID)
+	}
+	return expired
+}
 
-	for id, evt := range s.RemovedEvents {
-		// If RemovedAt is older than 30 days ago, clean it up
-		// Skip events with zero RemovedAt (shouldn't happen, but be safe)
+// RemovedBefore returns the removed events whose removal is before cutoff, oldest first.
+// Events with no removal time are never returned.
+func (s *Snapshot) RemovedBefore(cutoff time.Time) []*Event {
+	var events []*Event
+	for _, evt := range s.RemovedEvents {
 		if !evt.RemovedAt.IsZero() && evt.RemovedAt.Before(cutoff) {
-			delete(s.RemovedEvents, id)
-			removed++
+			events = append(events, evt)
 		}
 	}
-
-	return removed
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].RemovedAt.Equal(events[j].RemovedAt) {
+			return events[i].RemovedAt.Before(events[j].RemovedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events
 }
 
 // EventChange represents a change detected in an event
 type EventChange struct {
 	EventID    string    `json:"event_id"`
+	PreviousID string    `json:"previous_id,omitempty"` // the replaced event's ID, for "renamed"
 	StableKey  string    `json:"stable_key"`
-	ChangeType string    `json:"change_type"` // "date", "title", "city", "new", "removed"
+	ChangeType string    `json:"change_type"` // "date", "title", "city", "renamed", "new", "removed"
 	OldValue   string    `json:"old_value"`
 	NewValue   string    `json:"new_value"`
 	DetectedAt time.Time `json:"detected_at"`
@@ -240,6 +368,12 @@ type EventChange struct {
 
 // DetectChanges compares two events and returns detected changes
 func DetectChanges(previous, current *Event) []*EventChange {
+	return detectChangesAt(previous, current, clk.Now().UTC())
+}
+
+// detectChangesAt is DetectChanges with a fixed detection time, so a whole snapshot
+// comparison shares one timestamp
+func detectChangesAt(previous, current *Event, now time.Time) []*EventChange {
 	var changes []*EventChange
 
 	// If no previous event, this is a new event
@@ -251,7 +385,7 @@ func DetectChanges(previous, current *Event) []*EventChange {
 				ChangeType: "new",
 				OldValue:   "",
 				NewValue:   current.Title,
-				DetectedAt: time.Now().UTC(),
+				DetectedAt: now,
 			},
 		}
 	}
@@ -264,7 +398,7 @@ func DetectChanges(previous, current *Event) []*EventChange {
 			ChangeType: "date",
 			OldValue:   previous.DateText,
 			NewValue:   current.DateText,
-			DetectedAt: time.Now().UTC(),
+			DetectedAt: now,
 		})
 	}
 
@@ -276,19 +410,19 @@ func DetectChanges(previous, current *Event) []*EventChange {
 			ChangeType: "title",
 			OldValue:   previous.Title,
 			NewValue:   current.Title,
-			DetectedAt: time.Now().UTC(),
+			DetectedAt: now,
 		})
 	}
 
-	// Detect city change
-	if previous.City != current.City {
+	// Detect city change (ignoring spelling the cleanup fixed)
+	if !sameCity(previous, current) {
 		changes = append(changes, &EventChange{
 			EventID:    current.ID,
 			StableKey:  current.StableKey,
 			ChangeType: "city",
 			OldValue:   previous.City,
 			NewValue:   current.City,
-			DetectedAt: time.Now().UTC(),
+			DetectedAt: now,
 		})
 	}
 
@@ -298,6 +432,7 @@ func DetectChanges(previous, current *Event) []*EventChange {
 // CompareSnapshots compares two sets of events and returns all detected changes
 func CompareSnapshots(previousEvents, currentEvents map[string]*Event, previousIndex, currentIndex map[string]string) []*EventChange {
 	var allChanges []*EventChange
+	now := clk.Now().UTC()
 
 	// Check each stable key in current snapshot
 	for stableKey, currentID := range currentIndex {
@@ -306,12 +441,10 @@ func CompareSnapshots(previousEvents, currentEvents map[string]*Event, previousI
 		// Look for previous event with same stable key
 		if previousID, exists := previousIndex[stableKey]; exists {
 			previousEvent := previousEvents[previousID]
-			changes := DetectChanges(previousEvent, currentEvent)
-			allChanges = append(allChanges, changes...)
+			allChanges = append(allChanges, detectChangesAt(previousEvent, currentEvent, now)...)
 		} else {
 			// New event (stable key doesn't exist in previous)
-			changes := DetectChanges(nil, currentEvent)
-			allChanges = append(allChanges, changes...)
+			allChanges = append(allChanges, detectChangesAt(nil, currentEvent, now)...)
 		}
 	}
 
@@ -326,7 +459,7 @@ func CompareSnapshots(previousEvents, currentEvents map[string]*Event, previousI
 				ChangeType: "removed",
 				OldValue:   previousEvent.Title,
 				NewValue:   "",
-				DetectedAt: time.Now().UTC(),
+				DetectedAt: now,
 			})
 		}
 	}
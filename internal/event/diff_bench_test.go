@@ -0,0 +1,99 @@
+package event
+
+import (
+	"fmt"
+	"testing"
+)
+
+var benchStates = []string{"NV", "CA", "TX", "AZ", "FL", "UT", "CO", "WA", "OR", "GA"}
+
+// benchEvents generates n synthetic events. About a fifth of the courses are listed in
+// two states on the same date, so MarkDuplicates has work to do.
+func benchEvents(n int) []*Event {
+	events := make([]*Event, n)
+	for i := range events {
+		state := benchStates[i%len(benchStates)]
+		course := i
+		if i%5 == 0 && i > 0 {
+			course = i - 1 // Same course and date as the previous event, different state
+		}
+		date := fmt.Sprintf("%d.%d.26", course%12+1, course%28+1)
+		title := fmt.Sprintf("The Desert %d Golf Club %s", course, date)
+		events[i] = NewEvent(state, title, date, "Las Vegas", state+" - "+title+" - Las Vegas", "https://vgagolf.org/state-events/")
+	}
+	return events
+}
+
+// churn returns a copy of events with 1% removed, 1% added, and 1% rescheduled
+func churn(events []*Event) []*Event {
+	next := make([]*Event, 0, len(events))
+	for i, evt := range events {
+		switch {
+		case i%100 == 0:
+			continue
+		case i%100 == 1:
+			moved := *evt
+			moved.DateText = "12.31.26"
+			next = append(next, &moved)
+		default:
+			next = append(next, evt)
+		}
+	}
+	for i := 0; i < len(events)/100; i++ {
+		next = append(next, NewEvent("NV", fmt.Sprintf("New Course %d", i), "6.1.26", "Reno", fmt.Sprintf("NV - New Course %d - Reno", i), ""))
+	}
+	return next
+}
+
+func BenchmarkDiff(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			events := benchEvents(n)
+			previous := CreateSnapshot(events, "")
+			current := churn(events)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Diff(previous, current, "ALL")
+			}
+		})
+	}
+}
+
+func BenchmarkMarkDuplicates(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			events := benchEvents(n)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				MarkDuplicates(events)
+			}
+		})
+	}
+}
+
+func BenchmarkCompareSnapshots(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("events=%d", n), func(b *testing.B) {
+			events := benchEvents(n)
+			previous := CreateSnapshot(events, "")
+			current := CreateSnapshot(churn(events), "")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				CompareSnapshots(previous.Events, current.Events, previous.StableIndex, current.StableIndex)
+			}
+		})
+	}
+}
+
+func BenchmarkNormalizeCourseTitle(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NormalizeCourseTitle("  The Desert Pines Golf Club  4.4.26 ")
+	}
+}
@@ -1,6 +1,7 @@
 package event
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -194,6 +195,16 @@ func TestDetectChanges(t *testing.T) {
 		}
 	})
 
+	t.Run("ignores city cleanup", func(t *testing.T) {
+		// Snapshots saved before cities were cleaned have the scraped spelling
+		previous := NewEvent("NV", "Aliante GC", "Jun 1 2026", "N. Las  Vegas", "NV - Aliante GC Jun 1 2026 - N. Las  Vegas", "https://example.com")
+		current := NewEvent("NV", "Aliante GC", "Jun 1 2026", "North Las Vegas", "NV - Aliante GC Jun 1 2026 - N. Las  Vegas", "https://example.com")
+
+		if changes := DetectChanges(previous, current); len(changes) != 0 {
+			t.Errorf("expected no changes, got %+v", changes[0])
+		}
+	})
+
 	t.Run("detects multiple changes", func(t *testing.T) {
 		previous := NewEvent("AZ", "Phoenix Golf Resort", "Jul 10 2026", "Phoenix", "AZ - Phoenix Golf Resort Jul 10 2026 - Phoenix", "https://example.com")
 		current := NewEvent("AZ", "Phoenix Golf Resort", "Jul 17 2026", "Scottsdale", "AZ - Phoenix Golf Resort Jul 17 2026 - Scottsdale", "https://example.com")
@@ -359,6 +370,105 @@ func TestDiffWithRemovedEvents(t *testing.T) {
 	})
 }
 
+func TestDiffRestoredEvents(t *testing.T) {
+	evt1 := NewEvent("NV", "Event 1", "4.4.26", "Las Vegas", "NV - Event 1 4.4.26 - Las Vegas", "https://example.com")
+	evt2 := NewEvent("NV", "Event 2", "5.5.26", "Las Vegas", "NV - Event 2 5.5.26 - Las Vegas", "https://example.com")
+	evt3 := NewEvent("CA", "Event 3", "6.6.26", "Los Angeles", "CA - Event 3 6.6.26 - Los Angeles", "https://example.com")
+
+	previous := CreateSnapshot([]*Event{evt1}, "")
+	previous.StoreRemovedEvents([]*Event{evt2})
+
+	result := Diff(previous, []*Event{evt1, evt2, evt3}, "")
+
+	if len(result.RestoredEvents) != 1 || result.RestoredEvents[0].ID != evt2.ID {
+		t.Fatalf("expected evt2 restored, got %d restored event(s)", len(result.RestoredEvents))
+	}
+	if len(result.NewEvents) != 1 || result.NewEvents[0].ID != evt3.ID {
+		t.Errorf("expected only evt3 new, got %d new event(s)", len(result.NewEvents))
+	}
+
+	t.Run("respects state filter", func(t *testing.T) {
+		result := Diff(previous, []*Event{evt1, evt2}, "CA")
+		if len(result.RestoredEvents) != 0 {
+			t.Errorf("expected no restored CA events, got %d", len(result.RestoredEvents))
+		}
+	})
+}
+
+func TestDiffConfirmedRemovals(t *testing.T) {
+	evt1 := NewEvent("NV", "Event 1", "4.4.26", "Las Vegas", "NV - Event 1 4.4.26 - Las Vegas", "https://example.com")
+	evt2 := NewEvent("NV", "Event 2", "5.5.26", "Las Vegas", "NV - Event 2 5.5.26 - Las Vegas", "https://example.com")
+
+	// run diffs one scrape against the snapshot and returns the snapshot the CLI would save
+	run := func(previous *Snapshot, current []*Event, confirmRuns int) (*DiffResult, *Snapshot) {
+		result := DiffConfirmed(previous, current, "", confirmRuns)
+		return result, CreateSnapshot(append(append([]*Event{}, current...), result.Missing...), "")
+	}
+
+	t.Run("removal waits for confirmation", func(t *testing.T) {
+		snap := CreateSnapshot([]*Event{evt1, evt2}, "")
+
+		result, snap := run(snap, []*Event{evt1}, 3)
+		if len(result.RemovedEvents) != 0 || len(result.Missing) != 1 {
+			t.Fatalf("scrape 1: removed %d, missing %d; want 0, 1", len(result.RemovedEvents), len(result.Missing))
+		}
+		if result.Missing[0].MissingCount != 1 || result.Missing[0].MissingSince.IsZero() {
+			t.Errorf("scrape 1: MissingCount = %d, MissingSince = %v", result.Missing[0].MissingCount, result.Missing[0].MissingSince)
+		}
+		since := result.Missing[0].MissingSince
+
+		result, snap = run(snap, []*Event{evt1}, 3)
+		if len(result.RemovedEvents) != 0 || len(result.Missing) != 1 || result.Missing[0].MissingCount != 2 {
+			t.Fatalf("scrape 2: removed %d, missing %d; want 0, 1 (count 2)", len(result.RemovedEvents), len(result.Missing))
+		}
+		if !result.Missing[0].MissingSince.Equal(since) {
+			t.Errorf("scrape 2: MissingSince changed from %v to %v", since, result.Missing[0].MissingSince)
+		}
+
+		result, _ = run(snap, []*Event{evt1}, 3)
+		if len(result.RemovedEvents) != 1 || result.RemovedEvents[0].ID != evt2.ID {
+			t.Fatalf("scrape 3: expected evt2 removed, got %d removed", len(result.RemovedEvents))
+		}
+		if len(result.Missing) != 0 {
+			t.Errorf("scrape 3: expected no missing events, got %d", len(result.Missing))
+		}
+		if result.RemovedEvents[0].MissingCount != 0 {
+			t.Errorf("removed event should have MissingCount reset, got %d", result.RemovedEvents[0].MissingCount)
+		}
+	})
+
+	t.Run("flapping event is neither removed nor new", func(t *testing.T) {
+		snap := CreateSnapshot([]*Event{evt1, evt2}, "")
+		for i, current := range [][]*Event{{evt1}, {evt1, evt2}, {evt1}, {evt1, evt2}} {
+			var result *DiffResult
+			result, snap = run(snap, current, 2)
+			if len(result.RemovedEvents) != 0 || len(result.NewEvents) != 0 {
+				t.Fatalf("scrape %d: removed %d, new %d; want none", i+1, len(result.RemovedEvents), len(result.NewEvents))
+			}
+		}
+		if got := snap.Events[evt2.ID]; got == nil || got.MissingCount != 0 {
+			t.Errorf("expected evt2 back in the snapshot with MissingCount 0, got %+v", got)
+		}
+	})
+
+	t.Run("a scrape that finds it resets the count", func(t *testing.T) {
+		snap := CreateSnapshot([]*Event{evt1, evt2}, "")
+		_, snap = run(snap, []*Event{evt1}, 2)
+		_, snap = run(snap, []*Event{evt1, evt2}, 2)
+		result, _ := run(snap, []*Event{evt1}, 2)
+		if len(result.RemovedEvents) != 0 || len(result.Missing) != 1 || result.Missing[0].MissingCount != 1 {
+			t.Errorf("expected evt2 missing once after reappearing, got removed %d, missing %d", len(result.RemovedEvents), len(result.Missing))
+		}
+	})
+
+	t.Run("confirmRuns of 1 removes at once", func(t *testing.T) {
+		result := DiffConfirmed(CreateSnapshot([]*Event{evt1, evt2}, ""), []*Event{evt1}, "", 1)
+		if len(result.RemovedEvents) != 1 || len(result.Missing) != 0 {
+			t.Errorf("removed %d, missing %d; want 1, 0", len(result.RemovedEvents), len(result.Missing))
+		}
+	})
+}
+
 func TestCompareSnapshotsWithRemovals(t *testing.T) {
 	// Create previous snapshot
 	evt1 := NewEvent("NV", "Event 1", "Apr 4 2026", "Las Vegas", "NV - Event 1 Apr 4 2026 - Las Vegas", "https://example.com")
@@ -619,6 +729,11 @@ func TestNormalizeCourseTitle(t *testing.T) {
 			input:    "Shadow   Creek    Golf   Club",
 			expected: "shadow creek",
 		},
+		{
+			name:     "handles non-breaking spaces",
+			input:    "Pine\u00a0Valley",
+			expected: "pine valley",
+		},
 		{
 			name:     "handles lowercase input",
 			input:    "shadow creek golf club",
@@ -780,3 +895,60 @@ func TestDiffWithDeduplication(t *testing.T) {
 		}
 	})
 }
+
+func TestCountByState(t *testing.T) {
+	newEvents := []*Event{{ID: "a", State: "NV"}, {ID: "b", State: "NV"}, {ID: "c", State: "CA"}}
+	removed := []*Event{{ID: "d", State: "NV"}}
+	changes := []*EventChange{
+		{EventID: "x", ChangeType: "date"},
+		{EventID: "x", ChangeType: "city"},
+		{EventID: "y", ChangeType: "title"},
+	}
+	states := map[string]string{"x": "AZ"}
+
+	counts := CountByState(newEvents, removed, changes, func(id string) string { return states[id] })
+	want := map[string]DiffCounts{
+		"NV": {New: 2, Removed: 1},
+		"CA": {New: 1},
+		"AZ": {Changed: 1},
+		"":   {Changed: 1},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountByState() = %+v, want %+v", counts, want)
+	}
+	if counts["NV"].Total() != 3 {
+		t.Errorf("Total() = %d, want 3", counts["NV"].Total())
+	}
+}
+
+func TestExpireRemovedEvents(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := NewSnapshot()
+	for id, daysAgo := range map[string]int{"a": 100, "b": 70, "c": 20} {
+		snapshot.RemovedEvents[id] = &Event{ID: id, RemovedAt: now.AddDate(0, 0, -daysAgo)}
+	}
+	snapshot.RemovedEvents["undated"] = &Event{ID: "undated"}
+
+	expired := snapshot.ExpireRemovedEvents(60, now)
+	if len(expired) != 2 || expired[0].ID != "a" || expired[1].ID != "b" {
+		t.Fatalf("ExpireRemovedEvents(60) = %v, want a and b, oldest first", expired)
+	}
+	if len(snapshot.RemovedEvents) != 2 {
+		t.Errorf("RemovedEvents = %d, want c and undated kept", len(snapshot.RemovedEvents))
+	}
+	if expired := snapshot.ExpireRemovedEvents(60, now); len(expired) != 0 {
+		t.Errorf("second ExpireRemovedEvents() = %v, want nothing", expired)
+	}
+}
+
+func TestSnapshotListed(t *testing.T) {
+	listed := NewEvent("NV", "Event 1", "4.4.26", "Las Vegas", "NV - Event 1 4.4.26 - Las Vegas", "https://example.com")
+	missing := NewEvent("NV", "Event 2", "5.5.26", "Reno", "NV - Event 2 5.5.26 - Reno", "https://example.com")
+	missing.MissingCount = 1
+	snapshot := CreateSnapshot([]*Event{listed, missing}, "")
+
+	got := snapshot.Listed()
+	if len(got) != 1 || got[listed.ID] != listed {
+		t.Errorf("Listed() = %v, want only the listed event", got)
+	}
+}
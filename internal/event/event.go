@@ -5,8 +5,19 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/clock"
 )
 
+// clk is the time source for functions that don't take the time, such as Diff and
+// IsPastEvent; binaries pass their clock to SetClock
+var clk clock.Clock = clock.System
+
+// SetClock sets the clock the package reads the current time from
+func SetClock(c clock.Clock) {
+	clk = c
+}
+
 // Event represents a VGA Golf state event
 type Event struct {
 	ID        string    `json:"id"`
@@ -20,6 +31,40 @@ type Event struct {
 	FirstSeen time.Time `json:"first_seen"`
 	RemovedAt time.Time `json:"removed_at,omitempty"` // When event was removed from VGA website
 	AlsoIn    []string  `json:"also_in,omitempty"`    // Other states where this event appears (for duplicates)
+	ShortCode string    `json:"short_code,omitempty"` // Human-friendly reference like "NV-417"
+
+	// MissingCount is how many consecutive scrapes haven't found the event, and
+	// MissingSince when it was first missed; both are zero while it's listed.
+	// See DiffConfirmed.
+	MissingCount int       `json:"missing_count,omitempty"`
+	MissingSince time.Time `json:"missing_since,omitempty"`
+
+	// RegistrationDeadline is the last day to register, as listed on the VGA site
+	// (same formats as DateText); empty when the site doesn't list one
+	RegistrationDeadline string `json:"registration_deadline,omitempty"`
+
+	// FieldFilled is how many players have registered and FieldSize the player limit, as
+	// listed on the VGA site; zero when the site doesn't list them
+	FieldFilled int `json:"field_filled,omitempty"`
+	FieldSize   int `json:"field_size,omitempty"`
+
+	// Tags are categories inferred from the title (see TagRules), e.g. "championship"
+	Tags []string `json:"tags,omitempty"`
+
+	// Scope is ScopeNational for events listed on the national/majors page; empty
+	// (or ScopeState) for state events
+	Scope string `json:"scope,omitempty"`
+}
+
+// Event scopes
+const (
+	ScopeState    = "state"
+	ScopeNational = "national"
+)
+
+// IsNational reports whether the event is listed on the national/majors page
+func (e *Event) IsNational() bool {
+	return e.Scope == ScopeNational
 }
 
 // GenerateID creates a deterministic ID for an event based on stable fields
@@ -53,32 +98,35 @@ func NewEvent(state, title, dateText, city, raw, sourceURL string) *Event {
 		City:      city,
 		Raw:       raw,
 		SourceURL: sourceURL,
-		FirstSeen: time.Now().UTC(),
+		FirstSeen: clk.Now().UTC(),
 		AlsoIn:    []string{}, // Initialize empty slice
+		Tags:      InferTags(title),
 	}
 }
 
+// courseTitleReplacements are removed from course titles in order. Suffixes come before
+// "the " so results don't depend on the order replacements are applied in.
+var courseTitleReplacements = []string{
+	" golf club",
+	" golf course",
+	" country club",
+	" cc",
+	" gc",
+	"the ",
+}
+
 // NormalizeCourseTitle normalizes a course title for deduplication
 // Removes common variations and standardizes formatting
 func NormalizeCourseTitle(title string) string {
-	// Convert to lowercase and trim
-	normalized := strings.ToLower(strings.TrimSpace(title))
-
-	// Remove extra whitespace first
-	normalized = strings.Join(strings.Fields(normalized), " ")
+	// Convert to lowercase and collapse whitespace, including non-breaking and other
+	// Unicode spaces, to single spaces
+	normalized := strings.Join(strings.Fields(strings.ToLower(title)), " ")
 
 	// Remove common suffixes and prefixes
-	replacements := map[string]string{
-		" golf club":    "",
-		" golf course":  "",
-		" country club": "",
-		" cc":           "",
-		" gc":           "",
-		"the ":          "",
-	}
-
-	for old, new := range replacements {
-		normalized = strings.ReplaceAll(normalized, old, new)
+	for _, old := range courseTitleReplacements {
+		if strings.Contains(normalized, old) {
+			normalized = strings.ReplaceAll(normalized, old, "")
+		}
 	}
 
 	return normalized
@@ -87,6 +135,5 @@ func NormalizeCourseTitle(title string) string {
 // GenerateDuplicationKey creates a key for detecting duplicate events across states
 // Based on normalized course name and date
 func GenerateDuplicationKey(title, dateText string) string {
-	normalized := NormalizeCourseTitle(title)
-	return fmt.Sprintf("%s|%s", normalized, dateText)
+	return NormalizeCourseTitle(title) + "|" + dateText
 }
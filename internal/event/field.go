@@ -0,0 +1,40 @@
+package event
+
+import "fmt"
+
+// AlmostFullThreshold is the share of the field taken at which an event is almost full
+const AlmostFullThreshold = 0.9
+
+// SpotsLeft returns how many spots are open, and false unless both the players
+// registered and the field size are listed
+func (e *Event) SpotsLeft() (int, bool) {
+	if e.FieldSize <= 0 || e.FieldFilled <= 0 {
+		return 0, false
+	}
+	return max(e.FieldSize-e.FieldFilled, 0), true
+}
+
+// IsAlmostFull reports whether at least threshold (0 to 1) of the field is taken. It's
+// false when the field isn't listed.
+func (e *Event) IsAlmostFull(threshold float64) bool {
+	if _, ok := e.SpotsLeft(); !ok {
+		return false
+	}
+	return float64(e.FieldFilled) >= threshold*float64(e.FieldSize)
+}
+
+// FormatField returns a short field-size line like "Field: 64 / 72 spots", with just the
+// size or the players when only one is listed. Returns "" if neither is.
+func (e *Event) FormatField() string {
+	switch left, ok := e.SpotsLeft(); {
+	case ok && left == 0:
+		return fmt.Sprintf("Field: %d / %d spots (full)", e.FieldFilled, e.FieldSize)
+	case ok:
+		return fmt.Sprintf("Field: %d / %d spots", e.FieldFilled, e.FieldSize)
+	case e.FieldSize > 0:
+		return fmt.Sprintf("Field: %d spots", e.FieldSize)
+	case e.FieldFilled > 0:
+		return fmt.Sprintf("Field: %d players", e.FieldFilled)
+	}
+	return ""
+}
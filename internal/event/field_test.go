@@ -0,0 +1,33 @@
+package event
+
+import "testing"
+
+func TestEvent_FormatField(t *testing.T) {
+	tests := []struct {
+		filled, size int
+		want         string
+		left         int
+		ok           bool
+		almostFull   bool
+	}{
+		{0, 0, "", 0, false, false},
+		{64, 72, "Field: 64 / 72 spots", 8, true, false},
+		{66, 72, "Field: 66 / 72 spots", 6, true, true},
+		{72, 72, "Field: 72 / 72 spots (full)", 0, true, true},
+		{75, 72, "Field: 75 / 72 spots (full)", 0, true, true},
+		{0, 72, "Field: 72 spots", 0, false, false},
+		{40, 0, "Field: 40 players", 0, false, false},
+	}
+	for _, tt := range tests {
+		evt := &Event{FieldFilled: tt.filled, FieldSize: tt.size}
+		if got := evt.FormatField(); got != tt.want {
+			t.Errorf("FormatField(%d/%d) = %q, want %q", tt.filled, tt.size, got, tt.want)
+		}
+		if left, ok := evt.SpotsLeft(); left != tt.left || ok != tt.ok {
+			t.Errorf("SpotsLeft(%d/%d) = %d, %v; want %d, %v", tt.filled, tt.size, left, ok, tt.left, tt.ok)
+		}
+		if got := evt.IsAlmostFull(AlmostFullThreshold); got != tt.almostFull {
+			t.Errorf("IsAlmostFull(%d/%d) = %v, want %v", tt.filled, tt.size, got, tt.almostFull)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package event
+
+import (
+	"strings"
+	"time"
+)
+
+// titleMatchSimilarity is the share of words a title must have in common with an
+// event's for FindByTitle to match it
+const titleMatchSimilarity = 0.5
+
+// FindByTitle returns the event whose title is most like title, scored like renames, or
+// nil if none is close enough. When state or date is given, events in other states or
+// on other days don't match. Ties go to the earlier event in events.
+func FindByTitle(events []*Event, title, state string, date time.Time) *Event {
+	var best *Event
+	bestScore := 0.0
+	for _, evt := range events {
+		if state != "" && !strings.EqualFold(evt.State, state) {
+			continue
+		}
+		if !date.IsZero() {
+			evtDate := ParseDate(evt.DateText)
+			if evtDate.IsZero() || evtDate.Format("2006-01-02") != date.Format("2006-01-02") {
+				continue
+			}
+		}
+		if score := titleSimilarity(evt.Title, title); score >= titleMatchSimilarity && score > bestScore {
+			best, bestScore = evt, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,39 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindByTitle(t *testing.T) {
+	wolfCreek := &Event{ID: "a", State: "NV", Title: "Wolf Creek Golf Club", DateText: "Apr 18 2026"}
+	wolfCreekLater := &Event{ID: "b", State: "NV", Title: "Wolf Creek Golf Club", DateText: "May 9 2026"}
+	tpc := &Event{ID: "c", State: "NV", Title: "TPC Las Vegas Championship", DateText: "Apr 4 2026"}
+	torrey := &Event{ID: "d", State: "CA", Title: "Torrey Pines South", DateText: "Apr 4 2026"}
+	events := []*Event{wolfCreek, wolfCreekLater, tpc, torrey}
+
+	tests := []struct {
+		name  string
+		title string
+		state string
+		date  time.Time
+		want  *Event
+	}{
+		{"exact", "Wolf Creek Golf Club", "", time.Time{}, wolfCreek},
+		{"shorter title", "wolf creek", "", time.Time{}, wolfCreek},
+		{"date picks the later event", "Wolf Creek", "", time.Date(2026, 5, 9, 0, 0, 0, 0, time.UTC), wolfCreekLater},
+		{"similar words", "TPC Las Vegas Classic", "", time.Time{}, tpc},
+		{"state", "Torrey Pines", "CA", time.Time{}, torrey},
+		{"wrong state", "Torrey Pines", "NV", time.Time{}, nil},
+		{"wrong date", "TPC Las Vegas", "", time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"unrelated", "Pebble Beach", "", time.Time{}, nil},
+		{"empty", "", "", time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindByTitle(events, tt.title, tt.state, tt.date); got != tt.want {
+				t.Errorf("FindByTitle(%q) = %v, want %v", tt.title, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package event
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/geo"
+)
+
+// renameSimilarity is the share of words two normalized titles must have in common
+// for one to count as a rename of the other
+const renameSimilarity = 0.5
+
+// linkRenames pairs new events with absent ones at the same state, date, and city whose
+// titles are similar, taking the closest title when there are several. A renamed event
+// keeps the short code and first-seen time of the event it replaces. It returns the new
+// and absent events left unpaired and a "renamed" change for each pair.
+func linkRenames(added, absent []*Event, now time.Time) ([]*Event, []*Event, []*EventChange) {
+	if len(added) == 0 || len(absent) == 0 {
+		return added, absent, nil
+	}
+
+	// Map iteration order is random, so candidates are ordered for a deterministic result
+	sort.Slice(absent, func(i, j int) bool { return absent[i].ID < absent[j].ID })
+
+	var renames []*EventChange
+	paired := make(map[string]bool)
+	remaining := make([]*Event, 0, len(added))
+	for _, evt := range added {
+		var best *Event
+		bestScore := 0.0
+		for _, old := range absent {
+			if paired[old.ID] || !sameSlot(old, evt) {
+				continue
+			}
+			if score := titleSimilarity(old.Title, evt.Title); score >= renameSimilarity && score > bestScore {
+				best, bestScore = old, score
+			}
+		}
+		if best == nil {
+			remaining = append(remaining, evt)
+			continue
+		}
+
+		paired[best.ID] = true
+		if best.ShortCode != "" {
+			evt.ShortCode = best.ShortCode
+		}
+		if !best.FirstSeen.IsZero() {
+			evt.FirstSeen = best.FirstSeen
+		}
+		renames = append(renames, &EventChange{
+			EventID:    evt.ID,
+			PreviousID: best.ID,
+			StableKey:  evt.StableKey,
+			ChangeType: "renamed",
+			OldValue:   best.Title,
+			NewValue:   evt.Title,
+			DetectedAt: now,
+		})
+	}
+
+	unpaired := make([]*Event, 0, len(absent)-len(paired))
+	for _, old := range absent {
+		if !paired[old.ID] {
+			unpaired = append(unpaired, old)
+		}
+	}
+	return remaining, unpaired, renames
+}
+
+// sameSlot reports whether two events are in the same state on the same date in the
+// same city
+func sameSlot(a, b *Event) bool {
+	return strings.EqualFold(a.State, b.State) &&
+		strings.TrimSpace(a.DateText) == strings.TrimSpace(b.DateText) &&
+		sameCity(a, b)
+}
+
+// sameCity reports whether two events are in the same city once their names are
+// cleaned, so events saved before cities were cleaned at parse time still match
+func sameCity(a, b *Event) bool {
+	return geo.NormalizeCity(geo.CleanCity(a.City, a.State)) == geo.NormalizeCity(geo.CleanCity(b.City, b.State))
+}
+
+// titleSimilarity scores how alike two course titles are, from 0 to 1: 1 when they're
+// equal once normalized or one contains the other, otherwise the share of distinct
+// words they have in common
+func titleSimilarity(a, b string) float64 {
+	na, nb := NormalizeCourseTitle(a), NormalizeCourseTitle(b)
+	na, nb = strings.Join(strings.Fields(na), " "), strings.Join(strings.Fields(nb), " ")
+	if na == "" || nb == "" {
+		return 0
+	}
+	if strings.Contains(na, nb) || strings.Contains(nb, na) {
+		return 1
+	}
+
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(na) {
+		words[w] = true
+	}
+	shared, union := 0, len(words)
+	seen := make(map[string]bool)
+	for _, w := range strings.Fields(nb) {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if words[w] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
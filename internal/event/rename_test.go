@@ -0,0 +1,73 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Shadow Creek", "Shadow Creek Golf Club", true},
+		{"Wolf Creek Golf Club", "Wolf Creek", true},
+		{"TPC Las Vegas Championship", "TPC Las Vegas Classic", true},
+		{"Shadow Creek", "Pebble Beach", false},
+		{"Red Rock Country Club", "Painted Desert", false},
+		{"", "Shadow Creek", false},
+	}
+	for _, tt := range tests {
+		if got := titleSimilarity(tt.a, tt.b) >= renameSimilarity; got != tt.want {
+			t.Errorf("titleSimilarity(%q, %q) = %.2f, similar = %v, want %v", tt.a, tt.b, titleSimilarity(tt.a, tt.b), got, tt.want)
+		}
+	}
+}
+
+func TestLinkRenames(t *testing.T) {
+	old := NewEvent("NV", "TPC Las Vegas Championship", "4.4.26", "Las Vegas", "NV - TPC Las Vegas Championship 4.4.26 - Las Vegas", "")
+	old.ShortCode = "NV-417"
+	old.FirstSeen = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	renamed := NewEvent("NV", "TPC Las Vegas Classic", "4.4.26", "Las Vegas", "NV - TPC Las Vegas Classic 4.4.26 - Las Vegas", "")
+	otherDay := NewEvent("NV", "TPC Las Vegas Open", "5.5.26", "Las Vegas", "NV - TPC Las Vegas Open 5.5.26 - Las Vegas", "")
+	unrelated := NewEvent("NV", "Painted Desert", "4.4.26", "Las Vegas", "NV - Painted Desert 4.4.26 - Las Vegas", "")
+
+	now := time.Now().UTC()
+	added, absent, renames := linkRenames([]*Event{renamed, otherDay, unrelated}, []*Event{old}, now)
+
+	if len(renames) != 1 {
+		t.Fatalf("expected 1 rename, got %d", len(renames))
+	}
+	r := renames[0]
+	if r.ChangeType != "renamed" || r.EventID != renamed.ID || r.PreviousID != old.ID {
+		t.Errorf("rename = %+v, want %s → %s", r, old.ID, renamed.ID)
+	}
+	if r.OldValue != old.Title || r.NewValue != renamed.Title {
+		t.Errorf("rename values = %q → %q", r.OldValue, r.NewValue)
+	}
+	if len(added) != 2 || len(absent) != 0 {
+		t.Errorf("expected 2 unpaired new and 0 absent events, got %d and %d", len(added), len(absent))
+	}
+	if renamed.ShortCode != "NV-417" || !renamed.FirstSeen.Equal(old.FirstSeen) {
+		t.Errorf("renamed event should keep short code and first seen, got %s, %v", renamed.ShortCode, renamed.FirstSeen)
+	}
+}
+
+func TestDiffReportsRenames(t *testing.T) {
+	evt1 := NewEvent("NV", "Shadow Creek", "4.4.26", "Las Vegas", "NV - Shadow Creek 4.4.26 - Las Vegas", "")
+	evt2 := NewEvent("NV", "Wolf Creek", "5.5.26", "Mesquite", "NV - Wolf Creek 5.5.26 - Mesquite", "")
+	renamed := NewEvent("NV", "Wolf Creek Golf Club", "5.5.26", "Mesquite", "NV - Wolf Creek Golf Club 5.5.26 - Mesquite", "")
+
+	previous := CreateSnapshot([]*Event{evt1, evt2}, "")
+
+	// Links right away, even while removals wait for confirmation
+	for _, confirmRuns := range []int{1, 2} {
+		result := DiffConfirmed(previous, []*Event{evt1, renamed}, "", confirmRuns)
+		if len(result.NewEvents) != 0 || len(result.RemovedEvents) != 0 || len(result.Missing) != 0 {
+			t.Errorf("confirmRuns %d: new %d, removed %d, missing %d; want none", confirmRuns, len(result.NewEvents), len(result.RemovedEvents), len(result.Missing))
+		}
+		if len(result.Renames) != 1 || result.Renames[0].PreviousID != evt2.ID {
+			t.Errorf("confirmRuns %d: expected evt2 renamed, got %d rename(s)", confirmRuns, len(result.Renames))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pfrederiksen/vga-events/internal/course"
+)
+
+// Snapshot formats, in the order they were introduced
+const (
+	// SnapshotSchemaEvents is the original format: events and the update time only,
+	// with no stable keys
+	SnapshotSchemaEvents = 1
+
+	// SnapshotSchemaStableIndex added stable keys, the stable index, the change log,
+	// and the course cache (v0.5.0)
+	SnapshotSchemaStableIndex = 2
+
+	// SnapshotSchemaRemoved added recently removed events (v0.6.0)
+	SnapshotSchemaRemoved = 3
+
+	// SnapshotSchemaShortCodes added short codes and the states seen, and records
+	// the schema version
+	SnapshotSchemaShortCodes = 4
+
+	// SnapshotSchemaVersion is the format snapshots are saved in
+	SnapshotSchemaVersion = SnapshotSchemaShortCodes
+)
+
+// DecodeSnapshot parses a snapshot saved in any format. Structures older formats lack
+// are filled in, so nothing downstream meets a nil map; snapshots older than the
+// current format get their stable keys and indices rebuilt from their events (see
+// Repair). The result is marked with the current SchemaVersion.
+func DecodeSnapshot(data []byte) (*Snapshot, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("parsing snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parsing snapshot: %w", err)
+	}
+
+	version := snapshot.SchemaVersion
+	if version == 0 {
+		version = detectSchemaVersion(fields)
+	}
+	if version > SnapshotSchemaVersion {
+		return nil, fmt.Errorf("snapshot format %d is newer than this version supports (%d)", version, SnapshotSchemaVersion)
+	}
+
+	snapshot.fillMissing()
+	if version < SnapshotSchemaVersion {
+		for _, evt := range snapshot.Events {
+			if evt != nil && evt.StableKey == "" {
+				evt.StableKey = GenerateStableKey(evt.State, evt.Title)
+			}
+		}
+		snapshot.Repair()
+	}
+	snapshot.SchemaVersion = SnapshotSchemaVersion
+
+	return &snapshot, nil
+}
+
+// detectSchemaVersion works out an unversioned snapshot's format from the fields it has
+func detectSchemaVersion(fields map[string]json.RawMessage) int {
+	switch {
+	case fields["short_codes"] != nil || fields["states_seen"] != nil:
+		return SnapshotSchemaShortCodes
+	case fields["removed_events"] != nil:
+		return SnapshotSchemaRemoved
+	case fields["stable_index"] != nil:
+		return SnapshotSchemaStableIndex
+	default:
+		return SnapshotSchemaEvents
+	}
+}
+
+// fillMissing replaces the snapshot's nil maps, lists, and cache with empty ones
+func (s *Snapshot) fillMissing() {
+	if s.Events == nil {
+		s.Events = make(map[string]*Event)
+	}
+	if s.RemovedEvents == nil {
+		s.RemovedEvents = make(map[string]*Event)
+	}
+	if s.StableIndex == nil {
+		s.StableIndex = make(map[string]string)
+	}
+	if s.ShortCodes == nil {
+		s.ShortCodes = make(map[string]string)
+	}
+	if s.ChangeLog == nil {
+		s.ChangeLog = make([]*EventChange, 0)
+	}
+	if s.CourseCache == nil {
+		s.CourseCache = course.NewCache()
+	} else if s.CourseCache.Courses == nil {
+		s.CourseCache.Courses = make(map[string]*course.CachedCourse)
+	}
+}
@@ -0,0 +1,95 @@
+package event
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeSnapshotFixtures(t *testing.T) {
+	tests := []struct {
+		file    string
+		version int
+		events  int
+		removed int
+		indexed int
+	}{
+		{"v1_events.json", SnapshotSchemaEvents, 2, 0, 2},
+		{"v2_stable_index.json", SnapshotSchemaStableIndex, 2, 0, 2},
+		{"v3_removed.json", SnapshotSchemaRemoved, 1, 1, 1},
+		{"v4_short_codes.json", SnapshotSchemaShortCodes, 1, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("../../testdata/fixtures/snapshots", tt.file))
+			if err != nil {
+				t.Fatalf("reading fixture: %v", err)
+			}
+
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatalf("fixture isn't JSON: %v", err)
+			}
+			if got := detectSchemaVersion(fields); got != tt.version {
+				t.Errorf("detectSchemaVersion() = %d, want %d", got, tt.version)
+			}
+
+			snapshot, err := DecodeSnapshot(data)
+			if err != nil {
+				t.Fatalf("DecodeSnapshot() error = %v", err)
+			}
+			if snapshot.SchemaVersion != SnapshotSchemaVersion {
+				t.Errorf("SchemaVersion = %d, want %d", snapshot.SchemaVersion, SnapshotSchemaVersion)
+			}
+			if snapshot.Events == nil || snapshot.RemovedEvents == nil || snapshot.StableIndex == nil ||
+				snapshot.ShortCodes == nil || snapshot.ChangeLog == nil || snapshot.CourseCache == nil || snapshot.CourseCache.Courses == nil {
+				t.Fatalf("DecodeSnapshot() left nil structures: %+v", snapshot)
+			}
+			if len(snapshot.Events) != tt.events || len(snapshot.RemovedEvents) != tt.removed || len(snapshot.StableIndex) != tt.indexed {
+				t.Errorf("events %d, removed %d, indexed %d; want %d, %d, %d",
+					len(snapshot.Events), len(snapshot.RemovedEvents), len(snapshot.StableIndex), tt.events, tt.removed, tt.indexed)
+			}
+			for _, p := range snapshot.Verify() {
+				if !p.IsWarning() {
+					t.Errorf("Verify() after decoding: %+v", p)
+				}
+			}
+
+			// The decoded snapshot works with the rest of the pipeline
+			snapshot.StoreRemovedEvents([]*Event{{ID: "gone", State: "UT"}})
+			snapshot.ShortCodes["UT-1"] = "gone"
+			snapshot.CourseCache.Courses["x"] = nil
+		})
+	}
+}
+
+func TestDecodeSnapshotRebuildsStableKeys(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/fixtures/snapshots/v1_events.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	snapshot, err := DecodeSnapshot(data)
+	if err != nil {
+		t.Fatalf("DecodeSnapshot() error = %v", err)
+	}
+
+	want := GenerateStableKey("NV", "Shadow Creek")
+	if snapshot.Events["e1"].StableKey != want || snapshot.StableIndex[want] != "e1" {
+		t.Errorf("stable key not rebuilt: event %q, index %v", snapshot.Events["e1"].StableKey, snapshot.StableIndex)
+	}
+}
+
+func TestDecodeSnapshotErrors(t *testing.T) {
+	if _, err := DecodeSnapshot([]byte(`{"events": {}, "schema_version": 99}`)); err == nil {
+		t.Error("DecodeSnapshot() should reject a newer format")
+	}
+	if _, err := DecodeSnapshot([]byte(`not json`)); err == nil {
+		t.Error("DecodeSnapshot() should reject invalid JSON")
+	}
+	snapshot, err := DecodeSnapshot([]byte(`{}`))
+	if err != nil || snapshot.Events == nil || snapshot.StableIndex == nil {
+		t.Errorf("DecodeSnapshot({}) = %+v, %v", snapshot, err)
+	}
+}
@@ -0,0 +1,103 @@
+package event
+
+import (
+	"crypto/sha1" // #nosec G505 - SHA1 used for non-cryptographic code derivation, not security
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Short code numbers start at 3 digits; states with more than 900 codes in use spill into 4 digits
+const (
+	shortCodeMin      = 100
+	shortCodeMax      = 999
+	shortCodeOverflow = 1000
+)
+
+var shortCodePattern = regexp.MustCompile(`^[A-Za-z]{2}-\d{3,5}$`)
+
+// IsShortCode reports whether s looks like a short event code such as "NV-417"
+func IsShortCode(s string) bool {
+	return shortCodePattern.MatchString(strings.TrimSpace(s))
+}
+
+// NormalizeShortCode returns the canonical (uppercase, trimmed) form of a short code
+func NormalizeShortCode(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// shortCodeNumber derives the preferred number for an event ID, so the same event
+// gets the same code wherever codes are assigned, as long as there's no collision
+func shortCodeNumber(id string) int {
+	h := sha1.Sum([]byte(id)) // #nosec G401 - SHA1 used for non-cryptographic code derivation
+	return shortCodeMin + int(binary.BigEndian.Uint32(h[:4])%uint32(shortCodeMax-shortCodeMin+1))
+}
+
+// AssignShortCodes sets ShortCode on each event. Events keep the code recorded for them in
+// the previous snapshot (including recently removed events, whose codes aren't reused);
+// new events get a code derived from their ID, probing upward on collision.
+// Events are processed in ID order so assignment is deterministic.
+func AssignShortCodes(events []*Event, previous *Snapshot) {
+	used := make(map[string]string)  // code → event ID
+	known := make(map[string]string) // event ID → code
+
+	if previous != nil {
+		for code, id := range previous.ShortCodes {
+			used[code] = id
+			known[id] = code
+		}
+		for id, evt := range previous.RemovedEvents {
+			if evt.ShortCode != "" {
+				used[evt.ShortCode] = id
+				known[id] = evt.ShortCode
+			}
+		}
+	}
+
+	sorted := make([]*Event, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	// Keep existing codes first so new events can't take them
+	var unassigned []*Event
+	for _, evt := range sorted {
+		if code, ok := known[evt.ID]; ok {
+			evt.ShortCode = code
+			continue
+		}
+		unassigned = append(unassigned, evt)
+	}
+
+	for _, evt := range unassigned {
+		state := strings.ToUpper(evt.State)
+		n := shortCodeNumber(evt.ID)
+		for tries := 0; ; tries++ {
+			code := fmt.Sprintf("%s-%d", state, n)
+			if _, taken := used[code]; !taken {
+				evt.ShortCode = code
+				used[code] = evt.ID
+				break
+			}
+
+			n++
+			if n > shortCodeMax && tries < shortCodeMax-shortCodeMin {
+				n = shortCodeMin
+			} else if tries >= shortCodeMax-shortCodeMin && n < shortCodeOverflow {
+				n = shortCodeOverflow
+			}
+		}
+	}
+}
+
+// FindByShortCode returns the event with the given short code, or nil
+func FindByShortCode(events []*Event, code string) *Event {
+	code = NormalizeShortCode(code)
+	for _, evt := range events {
+		if evt.ShortCode == code {
+			return evt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestIsShortCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"NV-417", true},
+		{"nv-417", true},
+		{" CA-1001 ", true},
+		{"NV417", false},
+		{"NEV-417", false},
+		{"NV-41", false},
+		{"a94a8fe5ccb19ba61c4c0873d391e987982fbbd3", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsShortCode(tt.input); got != tt.want {
+			t.Errorf("IsShortCode(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAssignShortCodes(t *testing.T) {
+	evt1 := NewEvent("NV", "Event 1", "4.4.26", "Las Vegas", "NV - Event 1 4.4.26 - Las Vegas", "https://example.com")
+	evt2 := NewEvent("CA", "Event 2", "5.5.26", "Los Angeles", "CA - Event 2 5.5.26 - Los Angeles", "https://example.com")
+
+	AssignShortCodes([]*Event{evt1, evt2}, nil)
+
+	if !strings.HasPrefix(evt1.ShortCode, "NV-") || !IsShortCode(evt1.ShortCode) {
+		t.Errorf("evt1 code = %q, want NV-nnn", evt1.ShortCode)
+	}
+	if !strings.HasPrefix(evt2.ShortCode, "CA-") || !IsShortCode(evt2.ShortCode) {
+		t.Errorf("evt2 code = %q, want CA-nnn", evt2.ShortCode)
+	}
+
+	t.Run("deterministic without a snapshot", func(t *testing.T) {
+		copy1 := *evt1
+		copy1.ShortCode = ""
+		AssignShortCodes([]*Event{evt2, &copy1}, nil)
+		if copy1.ShortCode != evt1.ShortCode {
+			t.Errorf("code changed between runs: %q vs %q", copy1.ShortCode, evt1.ShortCode)
+		}
+	})
+
+	t.Run("keeps codes from previous snapshot", func(t *testing.T) {
+		previous := NewSnapshot()
+		previous.ShortCodes["NV-123"] = evt1.ID
+
+		copy1 := *evt1
+		AssignShortCodes([]*Event{&copy1}, previous)
+		if copy1.ShortCode != "NV-123" {
+			t.Errorf("ShortCode = %q, want NV-123 from snapshot", copy1.ShortCode)
+		}
+	})
+
+	t.Run("does not reuse removed event codes", func(t *testing.T) {
+		removed := NewEvent("NV", "Gone", "1.1.26", "", "NV - Gone", "https://example.com")
+		copy1 := *evt1
+		AssignShortCodes([]*Event{&copy1}, nil)
+
+		// Pretend a removed event already holds the code evt1 would get
+		removed.ShortCode = copy1.ShortCode
+		previous := NewSnapshot()
+		previous.RemovedEvents[removed.ID] = removed
+
+		copy2 := *evt1
+		copy2.ShortCode = ""
+		AssignShortCodes([]*Event{&copy2}, previous)
+		if copy2.ShortCode == removed.ShortCode {
+			t.Errorf("reused removed event's code %q", removed.ShortCode)
+		}
+	})
+}
+
+func TestAssignShortCodesUnique(t *testing.T) {
+	var events []*Event
+	for i := 0; i < 950; i++ {
+		raw := fmt.Sprintf("NV - Event %d", i)
+		events = append(events, NewEvent("NV", fmt.Sprintf("Event %d", i), "", "", raw, "https://example.com"))
+	}
+
+	AssignShortCodes(events, nil)
+
+	seen := make(map[string]bool)
+	for _, evt := range events {
+		if evt.ShortCode == "" {
+			t.Fatalf("event %s has no code", evt.ID)
+		}
+		if seen[evt.ShortCode] {
+			t.Fatalf("duplicate code %s", evt.ShortCode)
+		}
+		seen[evt.ShortCode] = true
+	}
+}
+
+func TestCreateSnapshotShortCodeIndex(t *testing.T) {
+	evt := NewEvent("NV", "Event 1", "4.4.26", "Las Vegas", "NV - Event 1 4.4.26 - Las Vegas", "https://example.com")
+	evt.ShortCode = "NV-417"
+
+	snap := CreateSnapshot([]*Event{evt}, "")
+	if snap.ShortCodes["NV-417"] != evt.ID {
+		t.Errorf("ShortCodes index = %v, want NV-417 → %s", snap.ShortCodes, evt.ID)
+	}
+
+	if found := FindByShortCode([]*Event{evt}, "nv-417"); found != evt {
+		t.Errorf("FindByShortCode did not match lowercase code")
+	}
+}
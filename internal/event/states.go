@@ -0,0 +1,67 @@
+package event
+
+import "sort"
+
+// knownStates returns the states a snapshot knows have listed events: those it has
+// tracked, plus the states of its current and recently removed events (snapshots
+// saved before states were tracked only have the latter)
+func knownStates(snapshot *Snapshot) map[string]bool {
+	known := make(map[string]bool, len(snapshot.StatesSeen))
+	for state := range snapshot.StatesSeen {
+		known[state] = true
+	}
+	for _, evt := range snapshot.Events {
+		known[evt.State] = true
+	}
+	for _, evt := range snapshot.RemovedEvents {
+		known[evt.State] = true
+	}
+	return known
+}
+
+// NewStates returns the states, sorted, whose first ever events are among events. The
+// first run (no previous snapshot, or an empty one) has nothing to compare with, so
+// every state would look new; it returns none.
+func NewStates(previous *Snapshot, events []*Event) []string {
+	if previous == nil {
+		return nil
+	}
+	known := knownStates(previous)
+	if len(known) == 0 {
+		return nil
+	}
+
+	var states []string
+	for _, evt := range events {
+		if evt.State == "" || known[evt.State] {
+			continue
+		}
+		known[evt.State] = true
+		states = append(states, evt.State)
+	}
+	sort.Strings(states)
+	return states
+}
+
+// TrackStates records the states s has events in, keeping the states previous
+// already knew and when they were first seen
+func (s *Snapshot) TrackStates(previous *Snapshot) {
+	if s.StatesSeen == nil {
+		s.StatesSeen = make(map[string]string)
+	}
+	if previous != nil {
+		for state, seen := range previous.StatesSeen {
+			s.StatesSeen[state] = seen
+		}
+		for state := range knownStates(previous) {
+			if _, ok := s.StatesSeen[state]; !ok {
+				s.StatesSeen[state] = previous.UpdatedAt
+			}
+		}
+	}
+	for _, evt := range s.Events {
+		if _, ok := s.StatesSeen[evt.State]; !ok && evt.State != "" {
+			s.StatesSeen[evt.State] = s.UpdatedAt
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewStates(t *testing.T) {
+	previous := CreateSnapshot([]*Event{{ID: "a", State: "NV"}}, "2026-01-01T00:00:00Z")
+	previous.StatesSeen = map[string]string{"AZ": "2025-06-01T00:00:00Z"}
+	previous.StoreRemovedEvents([]*Event{{ID: "b", State: "UT"}})
+
+	events := []*Event{
+		{ID: "c", State: "NV"},
+		{ID: "d", State: "MT"},
+		{ID: "e", State: "AZ"},
+		{ID: "f", State: "MT"},
+		{ID: "g", State: "UT"},
+		{ID: "h", State: "ID"},
+	}
+	if got, want := NewStates(previous, events), []string{"ID", "MT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NewStates() = %v, want %v", got, want)
+	}
+
+	if got := NewStates(nil, events); got != nil {
+		t.Errorf("NewStates() without a previous snapshot = %v, want none", got)
+	}
+	if got := NewStates(NewSnapshot(), events); got != nil {
+		t.Errorf("NewStates() after an empty snapshot = %v, want none", got)
+	}
+}
+
+func TestTrackStates(t *testing.T) {
+	previous := CreateSnapshot([]*Event{{ID: "a", State: "NV"}}, "2026-01-01T00:00:00Z")
+	previous.StatesSeen = map[string]string{"AZ": "2025-06-01T00:00:00Z"}
+
+	// NV's only event is gone, but the state is still remembered
+	snap := CreateSnapshot([]*Event{{ID: "b", State: "MT"}}, "2026-02-01T00:00:00Z")
+	snap.TrackStates(previous)
+
+	want := map[string]string{
+		"AZ": "2025-06-01T00:00:00Z",
+		"NV": "2026-01-01T00:00:00Z",
+		"MT": "2026-02-01T00:00:00Z",
+	}
+	if !reflect.DeepEqual(snap.StatesSeen, want) {
+		t.Errorf("StatesSeen = %v, want %v", snap.StatesSeen, want)
+	}
+	if got := NewStates(snap, []*Event{{ID: "c", State: "NV"}}); got != nil {
+		t.Errorf("a state that had events before isn't new: %v", got)
+	}
+}
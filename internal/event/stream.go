@@ -0,0 +1,131 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DiffStream receives events from a diff/digest JSON document as they are decoded.
+// Nil callbacks skip their array without decoding it.
+type DiffStream struct {
+	OnNew      func(*Event) error       // Called for each entry in "new_events"
+	OnRemoved  func(*Event) error       // Called for each entry in "removed_events"
+	OnRestored func(*Event) error       // Called for each entry in "restored_events"
+	OnRenamed  func(*Event) error       // Called for each entry in "renamed_events"
+	OnChanged  func(*EventChange) error // Called for each entry in "changed_events"
+	OnNewState func(string) error       // Called for each entry in "new_states"
+}
+
+// StreamDiff decodes a diff/digest document (the JSON written by "vga-events --format json")
+// one event at a time, so large event sets don't have to be held in memory twice.
+// Fields without a callback, including the "by_state" copy of every event, are skipped
+// token by token. Returning an error from a callback stops decoding.
+func StreamDiff(r io.Reader, s DiffStream) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("reading key: %w", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got %v", tok)
+		}
+
+		switch {
+		case key == "new_events" && s.OnNew != nil:
+			err = StreamArray(dec, s.OnNew)
+		case key == "removed_events" && s.OnRemoved != nil:
+			err = StreamArray(dec, s.OnRemoved)
+		case key == "restored_events" && s.OnRestored != nil:
+			err = StreamArray(dec, s.OnRestored)
+		case key == "renamed_events" && s.OnRenamed != nil:
+			err = StreamArray(dec, s.OnRenamed)
+		case key == "changed_events" && s.OnChanged != nil:
+			err = StreamArray(dec, s.OnChanged)
+		case key == "new_states" && s.OnNewState != nil:
+			err = StreamArray(dec, s.OnNewState)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+	return nil
+}
+
+// StreamArray decodes a JSON array from dec one element at a time, calling fn for each.
+// A null value is treated as an empty array.
+func StreamArray[T any](dec *json.Decoder, fn func(T) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("reading array: %w", err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected array, got %v", tok)
+	}
+
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return fmt.Errorf("decoding element: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing bracket
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("reading array: %w", err)
+	}
+	return nil
+}
+
+// expectDelim reads the next token and checks that it's the given delimiter
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("parsing JSON: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("parsing JSON: expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// skipValue consumes the next value without building it in memory
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("skipping value: %w", err)
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const streamDoc = `{
+  "checked_at": "2025-01-15T10:00:00Z",
+  "states": ["NV", "CA"],
+  "new_events": [
+    {"id": "a", "state": "NV", "title": "Shadow Creek"},
+    {"id": "b", "state": "CA", "title": "Pebble Beach"}
+  ],
+  "removed_events": [
+    {"id": "c", "state": "NV", "title": "Wolf Creek"}
+  ],
+  "restored_events": [
+    {"id": "d", "state": "CA", "title": "Torrey Pines"}
+  ],
+  "changed_events": [
+    {"event_id": "a", "change_type": "date", "old_value": "Jan 1", "new_value": "Jan 2"}
+  ],
+  "new_states": ["CA"],
+  "event_count": 2,
+  "by_state": {"NV": [{"id": "a", "state": "NV", "title": "Shadow Creek", "also_in": ["CA"]}]},
+  "show_all": false
+}`
+
+func TestStreamDiff(t *testing.T) {
+	var newIDs, removedIDs, restoredIDs []string
+	var changes []*EventChange
+	var newStates []string
+
+	err := StreamDiff(strings.NewReader(streamDoc), DiffStream{
+		OnNew: func(evt *Event) error {
+			newIDs = append(newIDs, evt.ID)
+			return nil
+		},
+		OnRemoved: func(evt *Event) error {
+			removedIDs = append(removedIDs, evt.ID)
+			return nil
+		},
+		OnRestored: func(evt *Event) error {
+			restoredIDs = append(restoredIDs, evt.ID)
+			return nil
+		},
+		OnChanged: func(change *EventChange) error {
+			changes = append(changes, change)
+			return nil
+		},
+		OnNewState: func(state string) error {
+			newStates = append(newStates, state)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("StreamDiff() error = %v", err)
+	}
+
+	if strings.Join(newIDs, ",") != "a,b" {
+		t.Errorf("new events = %v, want [a b]", newIDs)
+	}
+	if strings.Join(removedIDs, ",") != "c" {
+		t.Errorf("removed events = %v, want [c]", removedIDs)
+	}
+	if strings.Join(restoredIDs, ",") != "d" {
+		t.Errorf("restored events = %v, want [d]", restoredIDs)
+	}
+	if len(changes) != 1 || changes[0].EventID != "a" || changes[0].NewValue != "Jan 2" {
+		t.Errorf("changed events = %+v, want one date change for a", changes)
+	}
+	if strings.Join(newStates, ",") != "CA" {
+		t.Errorf("new states = %v, want [CA]", newStates)
+	}
+}
+
+func TestStreamDiffSkipsUnusedFields(t *testing.T) {
+	count := 0
+	err := StreamDiff(strings.NewReader(streamDoc), DiffStream{
+		OnRemoved: func(evt *Event) error {
+			count++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("StreamDiff() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("removed events = %d, want 1", count)
+	}
+}
+
+func TestStreamDiffNullAndMissing(t *testing.T) {
+	count := 0
+	onNew := func(evt *Event) error {
+		count++
+		return nil
+	}
+
+	for _, doc := range []string{`{"new_events": null}`, `{}`, `{"event_count": 0}`} {
+		if err := StreamDiff(strings.NewReader(doc), DiffStream{OnNew: onNew}); err != nil {
+			t.Errorf("StreamDiff(%s) error = %v", doc, err)
+		}
+	}
+	if count != 0 {
+		t.Errorf("got %d events, want 0", count)
+	}
+}
+
+func TestStreamDiffErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"not an object", `[1, 2]`},
+		{"truncated", `{"new_events": [{"id": "a"}`},
+		{"events not an array", `{"new_events": {"id": "a"}}`},
+		{"invalid event", `{"new_events": [{"id": 5}]}`},
+		{"empty", ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StreamDiff(strings.NewReader(tt.doc), DiffStream{OnNew: func(*Event) error { return nil }})
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestStreamDiffCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	count := 0
+	err := StreamDiff(strings.NewReader(streamDoc), DiffStream{OnNew: func(*Event) error {
+		count++
+		return stop
+	}})
+	if !errors.Is(err, stop) {
+		t.Errorf("error = %v, want %v", err, stop)
+	}
+	if count != 1 {
+		t.Errorf("callback called %d times, want 1", count)
+	}
+}
+
+func TestStreamArray(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`[1, 2, 3]`))
+	sum := 0
+	if err := StreamArray(dec, func(n int) error {
+		sum += n
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamArray() error = %v", err)
+	}
+	if sum != 6 {
+		t.Errorf("sum = %d, want 6", sum)
+	}
+}
+
+// BenchmarkStreamDiff compares streaming the new events out of a diff document with
+// decoding the whole document, which also materializes the by_state copy
+func BenchmarkStreamDiff(b *testing.B) {
+	events := benchEvents(10000)
+	byState := make(map[string][]*Event)
+	for _, evt := range events {
+		byState[evt.State] = append(byState[evt.State], evt)
+	}
+	doc, err := json.Marshal(map[string]interface{}{
+		"new_events": events,
+		"by_state":   byState,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			count := 0
+			if err := StreamDiff(bytes.NewReader(doc), DiffStream{OnNew: func(*Event) error {
+				count++
+				return nil
+			}}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("decode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var result struct {
+				NewEvents []*Event            `json:"new_events"`
+				ByState   map[string][]*Event `json:"by_state"`
+			}
+			if err := json.NewDecoder(bytes.NewReader(doc)).Decode(&result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
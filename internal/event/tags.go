@@ -0,0 +1,86 @@
+package event
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Event tags inferred from titles
+const (
+	TagChampionship = "championship"
+	TagScramble     = "scramble"
+	TagMajor        = "major"
+	TagCharity      = "charity"
+	TagQualifier    = "qualifier"
+)
+
+// TagRule tags events whose title contains one of Keywords as a whole word
+type TagRule struct {
+	Tag      string
+	Label    string // Shown on badges, e.g. "Championship"
+	Emoji    string
+	Keywords []string
+
+	pattern *regexp.Regexp
+}
+
+// TagRules are checked in order; an event gets every tag whose rule matches, in this
+// order
+var TagRules = []*TagRule{
+	{Tag: TagChampionship, Label: "Championship", Emoji: "🏆", Keywords: []string{"championship", "championships", "champ", "club champ"}},
+	{Tag: TagMajor, Label: "Major", Emoji: "🎖️", Keywords: []string{"major", "majors", "masters", "open"}},
+	{Tag: TagQualifier, Label: "Qualifier", Emoji: "🎟️", Keywords: []string{"qualifier", "qualifying", "qualification"}},
+	{Tag: TagScramble, Label: "Scramble", Emoji: "🔀", Keywords: []string{"scramble", "best ball", "shamble"}},
+	{Tag: TagCharity, Label: "Charity", Emoji: "❤️", Keywords: []string{"charity", "benefit", "fundraiser", "foundation"}},
+}
+
+func init() {
+	for _, rule := range TagRules {
+		words := make([]string, len(rule.Keywords))
+		for i, keyword := range rule.Keywords {
+			words[i] = regexp.QuoteMeta(keyword)
+		}
+		rule.pattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(words, "|") + `)\b`)
+	}
+}
+
+// InferTags returns the tags for an event title, in TagRules order
+func InferTags(title string) []string {
+	var tags []string
+	for _, rule := range TagRules {
+		if rule.pattern.MatchString(title) {
+			tags = append(tags, rule.Tag)
+		}
+	}
+	return tags
+}
+
+// LookupTag returns the rule for a tag name, ignoring case
+func LookupTag(tag string) (*TagRule, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, rule := range TagRules {
+		if rule.Tag == tag {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// GetTags returns the event's tags, inferring them for events saved before tags were
+// stored
+func (e *Event) GetTags() []string {
+	if e.Tags != nil {
+		return e.Tags
+	}
+	return InferTags(e.Title)
+}
+
+// HasTag reports whether the event has a tag
+func (e *Event) HasTag(tag string) bool {
+	for _, t := range e.GetTags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferTags(t *testing.T) {
+	tests := []struct {
+		title string
+		want  []string
+	}{
+		{"Chimera Golf Club", nil},
+		{"2026 Club Championship", []string{TagChampionship}},
+		{"NV Open Qualifier", []string{TagMajor, TagQualifier}},
+		{"Two-Man Best Ball Charity Scramble", []string{TagScramble, TagCharity}},
+		{"Opening Day at Bali Hai", nil}, // whole words only
+		{"SCRAMBLE FOR THE FOUNDATION", []string{TagScramble, TagCharity}},
+	}
+	for _, tt := range tests {
+		if got := InferTags(tt.title); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("InferTags(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	evt := NewEvent("NV", "Spring Scramble", "Apr 4 2026", "Las Vegas", "", "")
+	if !reflect.DeepEqual(evt.Tags, []string{TagScramble}) {
+		t.Errorf("NewEvent() Tags = %v, want [scramble]", evt.Tags)
+	}
+
+	// Events saved before tags were stored infer them
+	old := &Event{Title: "Member Championship"}
+	if !old.HasTag(TagChampionship) || old.HasTag(TagCharity) {
+		t.Errorf("GetTags() = %v, want [championship]", old.GetTags())
+	}
+
+	if rule, ok := LookupTag(" Charity "); !ok || rule.Tag != TagCharity {
+		t.Errorf("LookupTag(Charity) = %v, %v", rule, ok)
+	}
+	if _, ok := LookupTag("pro-am"); ok {
+		t.Error("LookupTag(pro-am) should fail")
+	}
+}
@@ -0,0 +1,111 @@
+package event
+
+import "time"
+
+// MaxChangeLog is how many recent changes a snapshot's ChangeLog keeps
+const MaxChangeLog = 100
+
+// UpdateOptions configures UpdateSnapshot
+type UpdateOptions struct {
+	State           string    // State code the events were filtered to, or "ALL"
+	ConfirmRemovals int       // Runs an event must be missing before it's removed (see DiffConfirmed)
+	RetentionDays   int       // Days removed events are kept
+	Now             time.Time // Recorded as the snapshot's UpdatedAt
+
+	// FailedPages are the source URLs of pages that failed to fetch this run. Events
+	// from them are carried forward unchanged rather than counted missing, so one
+	// failed page can't remove its events.
+	FailedPages []string
+}
+
+// Update is the result of diffing a fetch against the previous snapshot
+type Update struct {
+	Diff     *DiffResult
+	Snapshot *Snapshot      // The snapshot to keep for the next run
+	Changes  []*EventChange // Date, title, and city changes, and renames
+	Expired  []*Event       // Removed events past retention, dropped from Snapshot
+}
+
+// UpdateSnapshot diffs the listed events (already filtered to opts.State) against the
+// previous snapshot, which may be nil, and builds the next snapshot: events missing
+// but not yet confirmed removed are kept so the next run can count them again, earlier
+// removals are carried over until they expire, and the changes are added to the
+// ChangeLog. previous isn't modified.
+func UpdateSnapshot(previous *Snapshot, listed []*Event, opts UpdateOptions) *Update {
+	listed = carryUnfetched(previous, listed, opts.FailedPages)
+	diff := DiffConfirmed(previous, listed, opts.State, opts.ConfirmRemovals)
+
+	// Listed events go last, so an event listed with a new date or city replaces its
+	// missing former listing in the StableIndex and the change is detected
+	kept := append(append([]*Event(nil), diff.Missing...), listed...)
+	next := CreateSnapshot(kept, opts.Now.UTC().Format(time.RFC3339))
+	next.TrackStates(previous)
+
+	var changes []*EventChange
+	if previous != nil {
+		byID := make(map[string]*Event, len(kept))
+		for _, evt := range kept {
+			byID[evt.ID] = evt
+		}
+
+		// "new" and "removed" are reported by the diff, and title changes that are
+		// renames by diff.Renames
+		renamed := make(map[string]bool, len(diff.Renames))
+		for _, change := range diff.Renames {
+			renamed[change.EventID] = true
+		}
+		for _, change := range CompareSnapshots(previous.Events, byID, previous.StableIndex, next.StableIndex) {
+			switch {
+			case change.ChangeType == "new", change.ChangeType == "removed":
+			case change.ChangeType == "title" && renamed[change.EventID]:
+			default:
+				changes = append(changes, change)
+			}
+		}
+		changes = append(changes, diff.Renames...)
+
+		next.ChangeLog = append(append(next.ChangeLog, previous.ChangeLog...), changes...)
+		if len(next.ChangeLog) > MaxChangeLog {
+			next.ChangeLog = next.ChangeLog[len(next.ChangeLog)-MaxChangeLog:]
+		}
+
+		// An event that comes back within the retention window is reported as
+		// restored rather than new
+		for id, evt := range previous.RemovedEvents {
+			if _, back := next.Events[id]; !back {
+				next.RemovedEvents[id] = evt
+			}
+		}
+	}
+	next.StoreRemovedEvents(diff.RemovedEvents)
+
+	return &Update{
+		Diff:     diff,
+		Snapshot: next,
+		Changes:  changes,
+		Expired:  next.ExpireRemovedEvents(opts.RetentionDays, opts.Now),
+	}
+}
+
+// carryUnfetched adds the previous events from failed pages that aren't among listed
+func carryUnfetched(previous *Snapshot, listed []*Event, failedPages []string) []*Event {
+	if previous == nil || len(failedPages) == 0 {
+		return listed
+	}
+	failed := make(map[string]bool, len(failedPages))
+	for _, page := range failedPages {
+		failed[page] = true
+	}
+	present := make(map[string]bool, len(listed))
+	for _, evt := range listed {
+		present[evt.ID] = true
+	}
+
+	carried := append([]*Event(nil), listed...)
+	for id, evt := range previous.Events {
+		if !present[id] && failed[evt.SourceURL] {
+			carried = append(carried, evt)
+		}
+	}
+	return carried
+}
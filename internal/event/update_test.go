@@ -0,0 +1,75 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateSnapshot(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	kept := NewEvent("NV", "Event 1", "4.4.26", "Las Vegas", "NV - Event 1 4.4.26 - Las Vegas", "https://example.com")
+	moved := NewEvent("NV", "Event 2", "5.5.26", "Las Vegas", "NV - Event 2 5.5.26 - Las Vegas", "https://example.com")
+	missing := NewEvent("NV", "Event 3", "6.6.26", "Reno", "NV - Event 3 6.6.26 - Reno", "https://example.com")
+	old := NewEvent("NV", "Event 4", "1.1.26", "Reno", "NV - Event 4 1.1.26 - Reno", "https://example.com")
+	old.RemovedAt = now.AddDate(0, 0, -40)
+
+	previous := CreateSnapshot([]*Event{kept, moved, missing}, now.Add(-time.Hour).Format(time.RFC3339))
+	previous.RemovedEvents[old.ID] = old
+	for i := 0; i < MaxChangeLog; i++ {
+		previous.ChangeLog = append(previous.ChangeLog, &EventChange{EventID: kept.ID, ChangeType: "city"})
+	}
+
+	rescheduled := NewEvent("NV", "Event 2", "5.12.26", "Las Vegas", "NV - Event 2 5.12.26 - Las Vegas", "https://example.com")
+	update := UpdateSnapshot(previous, []*Event{kept, rescheduled}, UpdateOptions{
+		State:           "NV",
+		ConfirmRemovals: 2,
+		RetentionDays:   30,
+		Now:             now,
+	})
+
+	if len(update.Diff.Missing) != 2 || update.Snapshot.Events[missing.ID] == nil {
+		t.Errorf("missing events, including the rescheduled one's old listing, should be kept until confirmed, have %v", update.Diff.Missing)
+	}
+	if len(update.Changes) != 1 || update.Changes[0].ChangeType != "date" {
+		t.Fatalf("Changes = %+v, want the date change", update.Changes)
+	}
+	log := update.Snapshot.ChangeLog
+	if len(log) != MaxChangeLog || log[len(log)-1] != update.Changes[0] {
+		t.Errorf("ChangeLog has %d entries, want the previous ones capped at %d with the new change last", len(log), MaxChangeLog)
+	}
+	if len(update.Expired) != 1 || update.Expired[0].ID != old.ID || update.Snapshot.RemovedEvents[old.ID] != nil {
+		t.Errorf("Expired = %v, want the removal past retention dropped", update.Expired)
+	}
+	if update.Snapshot.UpdatedAt != now.Format(time.RFC3339) {
+		t.Errorf("UpdatedAt = %q", update.Snapshot.UpdatedAt)
+	}
+	if len(previous.Events) != 3 || len(previous.ChangeLog) != MaxChangeLog {
+		t.Error("previous should not be modified")
+	}
+}
+
+func TestUpdateSnapshotFailedPage(t *testing.T) {
+	const statePage, majorsPage = "https://example.com/state", "https://example.com/majors"
+	local := NewEvent("NV", "Wolf Creek", "4.4.26", "Mesquite", "NV - Wolf Creek 4.4.26 - Mesquite", statePage)
+	major := NewEvent("NV", "Shadow Creek", "5.5.26", "Las Vegas", "NV - Shadow Creek 5.5.26 - Las Vegas", majorsPage)
+	major.Scope = ScopeNational
+	opts := UpdateOptions{State: "ALL", ConfirmRemovals: 1, RetentionDays: 30, Now: time.Now()}
+	previous := UpdateSnapshot(nil, []*Event{local, major}, opts).Snapshot
+
+	// The majors page fails, so only the state page's events were fetched
+	opts.FailedPages = []string{majorsPage}
+	update := UpdateSnapshot(previous, []*Event{local}, opts)
+	if len(update.Diff.RemovedEvents) != 0 || len(update.Diff.Missing) != 0 {
+		t.Errorf("removed %v, missing %v; want the failed page's event left alone", update.Diff.RemovedEvents, update.Diff.Missing)
+	}
+	if evt := update.Snapshot.Events[major.ID]; evt == nil || evt.MissingCount != 0 {
+		t.Errorf("the failed page's event should be carried forward unchanged, have %+v", evt)
+	}
+
+	// Once the page is read again, an event it no longer lists is removed
+	opts.FailedPages = nil
+	update = UpdateSnapshot(update.Snapshot, []*Event{local}, opts)
+	if len(update.Diff.RemovedEvents) != 1 || update.Diff.RemovedEvents[0].ID != major.ID {
+		t.Errorf("removed %v, want the major", update.Diff.RemovedEvents)
+	}
+}
@@ -0,0 +1,176 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Snapshot problem kinds found by Verify
+const (
+	ProblemIDMismatch         = "id-mismatch"          // An events map key isn't its event's ID
+	ProblemListedAndRemoved   = "listed-and-removed"   // An event is in both Events and RemovedEvents
+	ProblemOrphanedStableKey  = "orphaned-stable-key"  // A StableIndex entry names no event with that key
+	ProblemUnindexedStableKey = "unindexed-stable-key" // An event's stable key is missing from StableIndex
+	ProblemDuplicateStableKey = "duplicate-stable-key" // Events share a stable key; only one can be indexed
+	ProblemOrphanedShortCode  = "orphaned-short-code"  // A ShortCodes entry names no event with that code
+	ProblemUnindexedShortCode = "unindexed-short-code" // An event's short code is missing from ShortCodes
+	ProblemDuplicateShortCode = "duplicate-short-code" // Events share a short code
+)
+
+// SnapshotProblem is one inconsistency in a snapshot's events and indices
+type SnapshotProblem struct {
+	Kind   string
+	Key    string // The map key, stable key, or short code concerned
+	Detail string
+}
+
+// IsWarning reports whether the problem can happen in a healthy snapshot: the stable key
+// is the state and title, so recurring events at one course share it
+func (p SnapshotProblem) IsWarning() bool {
+	return p.Kind == ProblemDuplicateStableKey
+}
+
+// Verify checks that the events maps are keyed by event ID and that StableIndex and
+// ShortCodes match the listed events. Problems are sorted by kind, then key.
+func (s *Snapshot) Verify() []SnapshotProblem {
+	var problems []SnapshotProblem
+	add := func(kind, key, format string, args ...any) {
+		problems = append(problems, SnapshotProblem{Kind: kind, Key: key, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	for name, events := range map[string]map[string]*Event{"events": s.Events, "removed_events": s.RemovedEvents} {
+		for key, evt := range events {
+			switch {
+			case evt == nil:
+				add(ProblemIDMismatch, key, "%s entry is empty", name)
+			case evt.ID != key:
+				add(ProblemIDMismatch, key, "%s entry holds event %s", name, evt.ID)
+			}
+		}
+	}
+	for id, evt := range s.RemovedEvents {
+		if _, listed := s.Events[id]; listed && evt != nil {
+			add(ProblemListedAndRemoved, id, "%q is listed and also kept as removed", evt.Title)
+		}
+	}
+
+	byStableKey := make(map[string][]string)
+	byShortCode := make(map[string][]string)
+	for id, evt := range s.Events {
+		if evt == nil {
+			continue
+		}
+		if evt.StableKey != "" {
+			byStableKey[evt.StableKey] = append(byStableKey[evt.StableKey], id)
+		}
+		if evt.ShortCode != "" {
+			byShortCode[evt.ShortCode] = append(byShortCode[evt.ShortCode], id)
+		}
+	}
+
+	for key, id := range s.StableIndex {
+		if evt := s.Events[id]; evt == nil || evt.StableKey != key {
+			add(ProblemOrphanedStableKey, key, "points to %s, which isn't a listed event with this key", id)
+		}
+	}
+	for key, ids := range byStableKey {
+		sort.Strings(ids)
+		if _, indexed := s.StableIndex[key]; !indexed {
+			add(ProblemUnindexedStableKey, key, "event %s isn't in the stable index", ids[0])
+		}
+		if len(ids) > 1 {
+			add(ProblemDuplicateStableKey, key, "shared by %d events: %v", len(ids), ids)
+		}
+	}
+
+	for code, id := range s.ShortCodes {
+		if evt := s.Events[id]; evt == nil || evt.ShortCode != code {
+			add(ProblemOrphanedShortCode, code, "points to %s, which isn't a listed event with this code", id)
+		}
+	}
+	for code, ids := range byShortCode {
+		sort.Strings(ids)
+		if _, indexed := s.ShortCodes[code]; !indexed {
+			add(ProblemUnindexedShortCode, code, "event %s isn't in the short code index", ids[0])
+		}
+		if len(ids) > 1 {
+			add(ProblemDuplicateShortCode, code, "shared by %d events: %v", len(ids), ids)
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Kind != problems[j].Kind {
+			return problems[i].Kind < problems[j].Kind
+		}
+		if problems[i].Key != problems[j].Key {
+			return problems[i].Key < problems[j].Key
+		}
+		return problems[i].Detail < problems[j].Detail
+	})
+	return problems
+}
+
+// Repair rekeys the events maps by event ID, drops listed events from RemovedEvents,
+// and rebuilds StableIndex and ShortCodes from the listed events. Events sharing a
+// stable key are indexed by the first seen; events sharing a short code keep it only
+// for the first seen, and the others get a new code on the next check. Returns the
+// problems it fixed.
+func (s *Snapshot) Repair() []SnapshotProblem {
+	var fixed []SnapshotProblem
+	for _, p := range s.Verify() {
+		if !p.IsWarning() {
+			fixed = append(fixed, p)
+		}
+	}
+
+	s.Events = rekeyEvents(s.Events)
+	s.RemovedEvents = rekeyEvents(s.RemovedEvents)
+	for id := range s.Events {
+		delete(s.RemovedEvents, id)
+	}
+
+	events := make([]*Event, 0, len(s.Events))
+	for _, evt := range s.Events {
+		events = append(events, evt)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].FirstSeen.Equal(events[j].FirstSeen) {
+			return events[i].FirstSeen.Before(events[j].FirstSeen)
+		}
+		return events[i].ID < events[j].ID
+	})
+
+	s.StableIndex = make(map[string]string)
+	s.ShortCodes = make(map[string]string)
+	for _, evt := range events {
+		if _, taken := s.StableIndex[evt.StableKey]; evt.StableKey != "" && !taken {
+			s.StableIndex[evt.StableKey] = evt.ID
+		}
+		if evt.ShortCode == "" {
+			continue
+		}
+		if _, taken := s.ShortCodes[evt.ShortCode]; taken {
+			evt.ShortCode = ""
+			continue
+		}
+		s.ShortCodes[evt.ShortCode] = evt.ID
+	}
+
+	return fixed
+}
+
+// rekeyEvents returns events keyed by their own IDs, without empty entries. When two
+// entries hold the same ID, the one already keyed correctly wins.
+func rekeyEvents(events map[string]*Event) map[string]*Event {
+	rekeyed := make(map[string]*Event, len(events))
+	for key, evt := range events {
+		if evt == nil || evt.ID == "" {
+			continue
+		}
+		if _, exists := rekeyed[evt.ID]; exists && key != evt.ID {
+			continue
+		}
+		rekeyed[evt.ID] = evt
+	}
+	return rekeyed
+}
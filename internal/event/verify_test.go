@@ -0,0 +1,61 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotVerifyAndRepair(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := CreateSnapshot([]*Event{
+		{ID: "a", StableKey: "k1", ShortCode: "NV-1", FirstSeen: day},
+		{ID: "b", StableKey: "k2", ShortCode: "NV-2", FirstSeen: day},
+	}, "")
+	if problems := snapshot.Verify(); len(problems) != 0 {
+		t.Fatalf("Verify() on a fresh snapshot = %+v", problems)
+	}
+
+	// Hand edits: an event filed under the wrong key, a second event with a's stable
+	// key and short code, an index entry for a deleted event, and a listed event
+	// left in RemovedEvents
+	snapshot.Events["wrong"] = &Event{ID: "c", StableKey: "k3", FirstSeen: day}
+	snapshot.Events["d"] = &Event{ID: "d", StableKey: "k1", ShortCode: "NV-1", FirstSeen: day.Add(time.Hour)}
+	snapshot.StableIndex["gone"] = "zzz"
+	snapshot.RemovedEvents["b"] = &Event{ID: "b", Title: "Second"}
+
+	kinds := make(map[string]int)
+	for _, p := range snapshot.Verify() {
+		kinds[p.Kind]++
+	}
+	for kind, want := range map[string]int{
+		ProblemIDMismatch:         1,
+		ProblemListedAndRemoved:   1,
+		ProblemOrphanedStableKey:  1,
+		ProblemUnindexedStableKey: 1, // k3, filed under "wrong"
+		ProblemDuplicateStableKey: 1,
+		ProblemDuplicateShortCode: 1,
+	} {
+		if kinds[kind] != want {
+			t.Errorf("Verify() found %d %s, want %d (all: %v)", kinds[kind], kind, want, kinds)
+		}
+	}
+
+	fixed := snapshot.Repair()
+	if len(fixed) != 5 {
+		t.Errorf("Repair() fixed %d problems, want 5: %+v", len(fixed), fixed)
+	}
+	problems := snapshot.Verify()
+	if len(problems) != 1 || !problems[0].IsWarning() {
+		t.Errorf("Verify() after Repair() = %+v, want only the duplicate stable key warning", problems)
+	}
+
+	if snapshot.Events["c"] == nil || snapshot.Events["wrong"] != nil {
+		t.Error("Repair() should rekey events by ID")
+	}
+	if len(snapshot.RemovedEvents) != 0 {
+		t.Errorf("RemovedEvents = %v, want listed events dropped", snapshot.RemovedEvents)
+	}
+	if snapshot.StableIndex["k1"] != "a" || snapshot.ShortCodes["NV-1"] != "a" || snapshot.Events["d"].ShortCode != "" {
+		t.Errorf("the first seen event should keep shared keys: index %v, codes %v", snapshot.StableIndex, snapshot.ShortCodes)
+	}
+}
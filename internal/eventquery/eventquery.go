@@ -0,0 +1,122 @@
+// Package eventquery selects the events one user sees, so every list and notification
+// path applies states, past events, the days-ahead horizon, and the active filter the
+// same way.
+//
+// A query is built from steps that run in the order they're added:
+//
+//	events := eventquery.ForUser(user, now).
+//		States().HidePast().Horizon().ApplyFilter().Sort().
+//		Run(allEvents)
+//
+// Handlers add their own conditions with Where, e.g. a keyword or city match.
+package eventquery
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// AllStates is the state code that matches events in every state
+const AllStates = "ALL"
+
+// Query is a list of steps applied to events for one user
+type Query struct {
+	user  *preferences.UserPreferences
+	now   time.Time
+	steps []func([]*event.Event) []*event.Event
+}
+
+// ForUser starts a query for user, judging past events and the horizon at now (the
+// caller's clock, so tests and replays control it)
+func ForUser(user *preferences.UserPreferences, now time.Time) *Query {
+	return &Query{user: user, now: now}
+}
+
+// Where keeps the events keep returns true for
+func (q *Query) Where(keep func(*event.Event) bool) *Query {
+	q.steps = append(q.steps, func(events []*event.Event) []*event.Event {
+		var kept []*event.Event
+		for _, evt := range events {
+			if keep(evt) {
+				kept = append(kept, evt)
+			}
+		}
+		return kept
+	})
+	return q
+}
+
+// States keeps events in the user's subscribed states
+func (q *Query) States() *Query {
+	return q.InStates(q.user.States)
+}
+
+// InStates keeps events in the given states (or ALL), instead of the user's
+func (q *Query) InStates(states []string) *Query {
+	return q.Where(func(evt *event.Event) bool { return MatchesStates(evt, states) })
+}
+
+// Subscriptions keeps events matching any of the user's subscriptions: states, cities,
+// trips, followed courses, and majors
+func (q *Query) Subscriptions() *Query {
+	return q.Where(q.user.MatchesSubscriptions)
+}
+
+// HidePast drops past events if the user hides them
+func (q *Query) HidePast() *Query {
+	if !q.user.HidePastEvents {
+		return q
+	}
+	return q.Upcoming()
+}
+
+// Upcoming drops past events whatever the user's setting
+func (q *Query) Upcoming() *Query {
+	return q.Where(func(evt *event.Event) bool { return !evt.IsPastEventAt(q.now) })
+}
+
+// Horizon drops events more than the user's DaysAhead days away. Like the setting
+// itself, a horizon starts today, so it drops past events too.
+func (q *Query) Horizon() *Query {
+	if q.user.DaysAhead <= 0 {
+		return q
+	}
+	return q.Where(func(evt *event.Event) bool { return evt.IsWithinDaysAt(q.user.DaysAhead, q.now) })
+}
+
+// ApplyFilter applies the user's active filter, if any
+func (q *Query) ApplyFilter() *Query {
+	q.steps = append(q.steps, q.user.ApplyFiltersToEvents)
+	return q
+}
+
+// Sort orders the events by date, soonest first
+func (q *Query) Sort() *Query {
+	q.steps = append(q.steps, func(events []*event.Event) []*event.Event {
+		event.SortByDate(events)
+		return events
+	})
+	return q
+}
+
+// Run applies the query's steps to events. The input slice is never modified.
+func (q *Query) Run(events []*event.Event) []*event.Event {
+	result := append([]*event.Event(nil), events...)
+	for _, step := range q.steps {
+		result = step(result)
+	}
+	return result
+}
+
+// MatchesStates reports whether an event is in one of the given states (or ALL)
+func MatchesStates(evt *event.Event, states []string) bool {
+	for _, state := range states {
+		if state == AllStates || strings.EqualFold(evt.State, state) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package eventquery
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+var now = time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+
+// testEvents are listed out of date order
+func testEvents() []*event.Event {
+	return []*event.Event{
+		{ID: "later", State: "NV", City: "Reno", DateText: "Nov 01 2026"},
+		{ID: "soon", State: "NV", City: "Las Vegas", DateText: "Oct 20 2026"},
+		{ID: "past", State: "NV", City: "Reno", DateText: "Oct 01 2026"},
+		{ID: "far", State: "NV", City: "Elko", DateText: "Mar 01 2027"},
+		{ID: "california", State: "CA", City: "Fresno", DateText: "Oct 21 2026"},
+		{ID: "undated", State: "NV", City: "Ely", DateText: "TBD"},
+	}
+}
+
+func ids(events []*event.Event) string {
+	names := make([]string, len(events))
+	for i, evt := range events {
+		names[i] = evt.ID
+	}
+	return strings.Join(names, ",")
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*preferences.UserPreferences)
+		query func(*Query) *Query
+		want  string
+	}{
+		{
+			name:  "no steps keeps everything in order",
+			query: func(q *Query) *Query { return q },
+			want:  "later,soon,past,far,california,undated",
+		},
+		{
+			name:  "states",
+			query: func(q *Query) *Query { return q.States() },
+			want:  "later,soon,past,far,undated",
+		},
+		{
+			name:  "all states",
+			setup: func(u *preferences.UserPreferences) { u.States = []string{AllStates} },
+			query: func(q *Query) *Query { return q.States() },
+			want:  "later,soon,past,far,california,undated",
+		},
+		{
+			name:  "explicit states",
+			query: func(q *Query) *Query { return q.InStates([]string{"ca"}) },
+			want:  "california",
+		},
+		{
+			name:  "hide past",
+			query: func(q *Query) *Query { return q.States().HidePast() },
+			want:  "later,soon,far,undated",
+		},
+		{
+			name:  "past shown",
+			setup: func(u *preferences.UserPreferences) { u.HidePastEvents = false },
+			query: func(q *Query) *Query { return q.States().HidePast() },
+			want:  "later,soon,past,far,undated",
+		},
+		{
+			name:  "upcoming ignores the setting",
+			setup: func(u *preferences.UserPreferences) { u.HidePastEvents = false },
+			query: func(q *Query) *Query { return q.States().Upcoming() },
+			want:  "later,soon,far,undated",
+		},
+		{
+			name:  "horizon",
+			setup: func(u *preferences.UserPreferences) { u.DaysAhead = 30 },
+			query: func(q *Query) *Query { return q.States().HidePast().Horizon() },
+			want:  "later,soon,undated",
+		},
+		{
+			name: "horizon drops past events even when they're shown",
+			setup: func(u *preferences.UserPreferences) {
+				u.HidePastEvents = false
+				u.DaysAhead = 30
+			},
+			query: func(q *Query) *Query { return q.States().HidePast().Horizon() },
+			want:  "later,soon,undated",
+		},
+		{
+			name: "active filter",
+			setup: func(u *preferences.UserPreferences) {
+				u.SaveFilter("reno", &filter.Filter{Cities: []string{"reno"}})
+				u.SetActiveFilter("reno")
+			},
+			query: func(q *Query) *Query { return q.States().HidePast().ApplyFilter() },
+			want:  "later",
+		},
+		{
+			name:  "where",
+			query: func(q *Query) *Query { return q.Where(func(evt *event.Event) bool { return evt.City == "Reno" }) },
+			want:  "later,past",
+		},
+		{
+			name:  "sorted",
+			query: func(q *Query) *Query { return q.States().HidePast().Sort() },
+			want:  "soon,later,far,undated",
+		},
+		{
+			name:  "subscriptions",
+			query: func(q *Query) *Query { return q.Subscriptions() },
+			want:  "later,soon,past,far,undated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := preferences.NewPreferences().GetUser("123")
+			user.States = []string{"NV"}
+			if tt.setup != nil {
+				tt.setup(user)
+			}
+			events := testEvents()
+			got := ids(tt.query(ForUser(user, now)).Run(events))
+			if got != tt.want {
+				t.Errorf("Run() = [%s], want [%s]", got, tt.want)
+			}
+			if ids(events) != ids(testEvents()) {
+				t.Errorf("Run() reordered its input: [%s]", ids(events))
+			}
+		})
+	}
+}
+
+func TestDigestQueue(t *testing.T) {
+	// Digests re-check subscriptions and time filters, which can change after queueing
+	user := preferences.NewPreferences().GetUser("123")
+	user.States = []string{"NV"}
+	user.DaysAhead = 30
+	digest := func() string {
+		return ids(ForUser(user, now).Subscriptions().HidePast().Horizon().Sort().Run(testEvents()))
+	}
+
+	if got := digest(); got != "soon,later,undated" {
+		t.Errorf("digest = [%s], want [soon later undated]", got)
+	}
+
+	user.HidePastEvents = false
+	user.DaysAhead = 0
+	if got := digest(); got != "past,soon,later,far,undated" {
+		t.Errorf("digest without time filters = [%s]", got)
+	}
+}
@@ -0,0 +1,127 @@
+// Package experiment runs A/B tests of notification formats. Each user is assigned to a
+// variant by hashing their chat ID, so they see the same variant on every run without
+// storing anything. Notifiers record which variant each message used in the delivery
+// log, buttons on those messages carry a one-letter variant tag back to the bot, and
+// Summarize compares how often each variant's buttons are tapped.
+package experiment
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+// Experiment is a set of message variants; the first is the control
+type Experiment struct {
+	Name        string
+	Description string
+	Variants    []string
+}
+
+// NewEventFormat compares the full new-event card with a compact one
+var NewEventFormat = &Experiment{
+	Name:        "new-event-format",
+	Description: "Full new-event card vs. a compact card without course details",
+	Variants:    []string{"control", "compact"},
+}
+
+// experiments are the experiments that can be turned on with --experiment
+var experiments = []*Experiment{NewEventFormat}
+
+// Lookup returns the experiment with the given name
+func Lookup(name string) (*Experiment, error) {
+	for _, e := range experiments {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	names := make([]string, 0, len(experiments))
+	for _, e := range experiments {
+		names = append(names, e.Name)
+	}
+	return nil, fmt.Errorf("unknown experiment %q (known: %s)", name, strings.Join(names, ", "))
+}
+
+// Assign returns the chat's variant. The same chat always gets the same variant, and
+// different experiments split users independently.
+func (e *Experiment) Assign(chatID string) string {
+	sum := sha256.Sum256([]byte(e.Name + "|" + chatID))
+	return e.Variants[binary.BigEndian.Uint64(sum[:8])%uint64(len(e.Variants))]
+}
+
+// Tag returns the one-letter tag ("a", "b", ...) for a variant, short enough to fit in
+// button callback data, or "" for an unknown variant
+func (e *Experiment) Tag(variant string) string {
+	for i, v := range e.Variants {
+		if v == variant {
+			return string(rune('a' + i))
+		}
+	}
+	return ""
+}
+
+// VariantForTag returns the variant a Tag stands for
+func (e *Experiment) VariantForTag(tag string) (string, bool) {
+	if len(tag) != 1 {
+		return "", false
+	}
+	i := int(tag[0]) - 'a'
+	if i < 0 || i >= len(e.Variants) {
+		return "", false
+	}
+	return e.Variants[i], true
+}
+
+// Result is how one variant performed
+type Result struct {
+	Variant string
+	Users   int // Users sent at least one message in this variant
+	Sent    int // Messages sent
+	Taps    int // Button taps on those messages
+}
+
+// TapRate returns taps per message sent
+func (r Result) TapRate() float64 {
+	if r.Sent == 0 {
+		return 0
+	}
+	return float64(r.Taps) / float64(r.Sent)
+}
+
+// Summarize counts messages sent per variant from the delivery log and button taps
+// per variant from users' preferences, in variant order
+func Summarize(e *Experiment, deliveries []*storage.Delivery, prefs preferences.Preferences) []Result {
+	results := make(map[string]*Result, len(e.Variants))
+	users := make(map[string]map[string]bool, len(e.Variants))
+	for _, v := range e.Variants {
+		results[v] = &Result{Variant: v}
+		users[v] = make(map[string]bool)
+	}
+
+	for _, d := range deliveries {
+		if d.Experiment != e.Name || d.Result != storage.DeliverySent {
+			continue
+		}
+		if r, ok := results[d.Variant]; ok {
+			r.Sent++
+			users[d.Variant][d.User] = true
+		}
+	}
+
+	for _, user := range prefs {
+		for _, v := range e.Variants {
+			results[v].Taps += user.ExperimentTaps[preferences.ExperimentKey(e.Name, v)]
+		}
+	}
+
+	summary := make([]Result, 0, len(e.Variants))
+	for _, v := range e.Variants {
+		results[v].Users = len(users[v])
+		summary = append(summary, *results[v])
+	}
+	return summary
+}
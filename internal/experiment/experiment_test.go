@@ -0,0 +1,85 @@
+package experiment
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func TestAssign(t *testing.T) {
+	e := NewEventFormat
+	if e.Assign("12345") != e.Assign("12345") {
+		t.Fatal("Assign() should be deterministic")
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[e.Assign(fmt.Sprintf("%d", 100000+i))]++
+	}
+	for _, v := range e.Variants {
+		if counts[v] < 400 {
+			t.Errorf("variant %s got %d of 1000 users, want roughly half", v, counts[v])
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	e := NewEventFormat
+	for _, v := range e.Variants {
+		tag := e.Tag(v)
+		if got, ok := e.VariantForTag(tag); !ok || got != v {
+			t.Errorf("VariantForTag(Tag(%q)) = %q, %v", v, got, ok)
+		}
+	}
+	if e.Tag("bogus") != "" {
+		t.Error("unknown variant should have no tag")
+	}
+	for _, tag := range []string{"", "z", "ab", "A"} {
+		if _, ok := e.VariantForTag(tag); ok {
+			t.Errorf("VariantForTag(%q) should fail", tag)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if e, err := Lookup("new-event-format"); err != nil || e != NewEventFormat {
+		t.Errorf("Lookup() = %v, %v", e, err)
+	}
+	if _, err := Lookup("nope"); err == nil {
+		t.Error("Lookup() of an unknown experiment should fail")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	e := NewEventFormat
+	deliveries := []*storage.Delivery{
+		{User: "u1", Experiment: e.Name, Variant: "control", Result: storage.DeliverySent},
+		{User: "u1", Experiment: e.Name, Variant: "control", Result: storage.DeliverySent},
+		{User: "u2", Experiment: e.Name, Variant: "compact", Result: storage.DeliverySent},
+		{User: "u2", Experiment: e.Name, Variant: "compact", Result: storage.DeliveryFailed},
+		{User: "u3", Result: storage.DeliverySent}, // Not in the experiment
+	}
+	prefs := preferences.NewPreferences()
+	prefs.GetUser("1").RecordExperimentTap(e.Name, "control")
+	prefs.GetUser("2").RecordExperimentTap(e.Name, "compact")
+	prefs.GetUser("2").RecordExperimentTap(e.Name, "compact")
+
+	results := Summarize(e, deliveries, prefs)
+	want := []Result{
+		{Variant: "control", Users: 1, Sent: 2, Taps: 1},
+		{Variant: "compact", Users: 1, Sent: 1, Taps: 2},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Summarize() = %+v", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+	if rate := results[0].TapRate(); rate != 0.5 {
+		t.Errorf("TapRate() = %v, want 0.5", rate)
+	}
+}
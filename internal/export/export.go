@@ -0,0 +1,212 @@
+// Package export builds the archive /export-all sends: a ZIP of everything the bot keeps
+// for a user, with their preferences as JSON, event notes as Markdown, tracked events as
+// CSV, and a calendar of the tracked events.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/calendar"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// Files in the archive. CalendarFile is left out when no tracked event is still listed.
+const (
+	PreferencesFile = "preferences.json"
+	NotesFile       = "notes.md"
+	EventsFile      = "tracked-events.csv"
+	CalendarFile    = "calendar.ics"
+)
+
+// trackedEvent is a row of the tracked events CSV
+type trackedEvent struct {
+	ID       string
+	Title    string
+	Date     string
+	City     string
+	State    string
+	Status   string
+	Note     string
+	Archived bool
+}
+
+// Build returns a ZIP archive of the user's data. events are the current events, which
+// give tracked events their title, date, and city; events no longer listed fall back to
+// their archived record, or just their ID. Credentials (the API token hash and /link
+// code) are left out of the preferences.
+func Build(user *preferences.UserPreferences, events []*event.Event, now time.Time) ([]byte, error) {
+	prefsJSON, err := preferencesJSON(user)
+	if err != nil {
+		return nil, err
+	}
+	tracked, listed := trackedEvents(user, events)
+	eventsCSV, err := trackedCSV(tracked)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{PreferencesFile, prefsJSON},
+		{NotesFile, []byte(notesMarkdown(tracked, now))},
+		{EventsFile, eventsCSV},
+	}
+	if len(listed) > 0 {
+		opts := make(map[string]*calendar.EventOptions, len(listed))
+		for _, evt := range listed {
+			opts[evt.ID] = &calendar.EventOptions{
+				Status: user.GetEventStatus(evt.ID),
+				Note:   user.GetEventNote(evt.ID),
+			}
+		}
+		files = append(files, struct {
+			name    string
+			content []byte
+		}{CalendarFile, []byte(calendar.GenerateBulkICSWithOptions(listed, "VGA Golf - My Events", opts))})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, fmt.Errorf("adding %s: %w", f.name, err)
+		}
+		if _, err := w.Write(f.content); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// preferencesJSON returns the user's preferences without credentials
+func preferencesJSON(user *preferences.UserPreferences) ([]byte, error) {
+	redacted := *user
+	redacted.APITokenHash = ""
+	redacted.LinkCode = ""
+	redacted.LinkCodeExpires = 0
+	data, err := json.MarshalIndent(&redacted, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding preferences: %w", err)
+	}
+	return data, nil
+}
+
+// trackedEvents returns the user's tracked and archived events, soonest first, and the
+// tracked events still listed in events, except skipped ones, for the calendar
+func trackedEvents(user *preferences.UserPreferences, events []*event.Event) ([]trackedEvent, []*event.Event) {
+	byID := make(map[string]*event.Event, len(events))
+	for _, evt := range events {
+		byID[evt.ID] = evt
+	}
+
+	var tracked []trackedEvent
+	var listed []*event.Event
+	ids := user.TrackedEventIDs()
+	for _, id := range ids {
+		row := trackedEvent{ID: id, Status: user.GetEventStatus(id), Note: user.GetEventNote(id)}
+		if evt, ok := byID[id]; ok {
+			row.Title, row.Date, row.City, row.State = evt.Title, evt.DateText, evt.City, evt.State
+			if row.Status != preferences.EventStatusSkip {
+				listed = append(listed, evt)
+			}
+		}
+		tracked = append(tracked, row)
+	}
+	for id, archived := range user.ArchivedEvents {
+		if slices.Contains(ids, id) {
+			continue
+		}
+		tracked = append(tracked, trackedEvent{
+			ID:       id,
+			Title:    archived.Title,
+			Date:     archived.Date,
+			Status:   archived.Status,
+			Note:     archived.Note,
+			Archived: true,
+		})
+	}
+
+	sort.SliceStable(tracked, func(i, j int) bool {
+		di, dj := event.ParseDate(tracked[i].Date), event.ParseDate(tracked[j].Date)
+		if !di.Equal(dj) {
+			// Undated events go last
+			return !di.IsZero() && (dj.IsZero() || di.Before(dj))
+		}
+		return tracked[i].ID < tracked[j].ID
+	})
+	event.SortByDate(listed)
+	return tracked, listed
+}
+
+// trackedCSV returns the tracked events as CSV with a header row
+func trackedCSV(tracked []trackedEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"event_id", "title", "date", "city", "state", "status", "note", "archived"})
+	for _, row := range tracked {
+		_ = w.Write([]string{row.ID, row.Title, row.Date, row.City, row.State, row.Status, row.Note, fmt.Sprint(row.Archived)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("writing CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// notesMarkdown returns the user's event notes as a Markdown document, one section per
+// event with a note
+func notesMarkdown(tracked []trackedEvent, now time.Time) string {
+	var md strings.Builder
+	md.WriteString("# VGA Golf Event Notes\n\n")
+	md.WriteString(fmt.Sprintf("Exported %s.\n", now.UTC().Format("January 2, 2006")))
+
+	count := 0
+	for _, row := range tracked {
+		if row.Note == "" {
+			continue
+		}
+		count++
+		heading := row.Title
+		if heading == "" {
+			heading = "Event " + row.ID
+		}
+		if row.State != "" {
+			heading += " (" + row.State + ")"
+		}
+		md.WriteString("\n## " + heading + "\n\n")
+
+		var details []string
+		if row.Date != "" {
+			details = append(details, "**Date:** "+row.Date)
+		}
+		if row.Status != "" {
+			details = append(details, "**Status:** "+row.Status)
+		}
+		if row.Archived {
+			details = append(details, "*Archived*")
+		}
+		if len(details) > 0 {
+			md.WriteString(strings.Join(details, " · ") + "\n\n")
+		}
+		md.WriteString(row.Note + "\n")
+	}
+	if count == 0 {
+		md.WriteString("\nNo notes yet.\n")
+	}
+	return md.String()
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// readZip returns the archive's files by name, in order
+func readZip(t *testing.T, data []byte) ([]string, map[string]string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("reading zip: %v", err)
+	}
+	var names []string
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		names = append(names, f.Name)
+		files[f.Name] = string(content)
+	}
+	return names, files
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []*event.Event{
+		{ID: "aaa", State: "NV", Title: "Wolf Creek", City: "Mesquite", DateText: "Apr 18 2026"},
+		{ID: "bbb", State: "CA", Title: "Torrey Pines", City: "San Diego", DateText: "Apr 4 2026"},
+		{ID: "ccc", State: "NV", Title: "Skipped Event", City: "Reno", DateText: "May 2 2026"},
+	}
+	user := &preferences.UserPreferences{
+		States:        []string{"NV"},
+		EventStatuses: map[string]string{"aaa": "registered", "bbb": "interested", "ccc": "skip", "gone": "maybe"},
+		EventNotes:    map[string]string{"aaa": "Bring rain gear", "gone": "Ask about carts"},
+		ArchivedEvents: map[string]*preferences.ArchivedEvent{
+			"old": {Title: "Bali Hai", Date: "Jan 10 2026", Status: "registered", Note: "Shot 82", ArchivedAt: now},
+		},
+		APITokenHash:    "secret-hash",
+		LinkCode:        "ABC123",
+		LinkCodeExpires: 12345,
+	}
+
+	data, err := Build(user, events, now)
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	names, files := readZip(t, data)
+	if got := strings.Join(names, ","); got != "preferences.json,notes.md,tracked-events.csv,calendar.ics" {
+		t.Errorf("files = %s", got)
+	}
+
+	prefsJSON := files[PreferencesFile]
+	var decoded preferences.UserPreferences
+	if err := json.Unmarshal([]byte(prefsJSON), &decoded); err != nil {
+		t.Fatalf("preferences.json: %v", err)
+	}
+	if decoded.GetEventStatus("aaa") != "registered" || len(decoded.States) != 1 {
+		t.Errorf("preferences.json lost data: %+v", decoded)
+	}
+	if strings.Contains(prefsJSON, "secret-hash") || strings.Contains(prefsJSON, "ABC123") {
+		t.Error("preferences.json should leave out credentials")
+	}
+	if user.APITokenHash == "" {
+		t.Error("Build should not change the user")
+	}
+
+	notes := files[NotesFile]
+	for _, want := range []string{"## Wolf Creek (NV)", "Bring rain gear", "## Event gone", "Ask about carts", "## Bali Hai", "*Archived*", "Shot 82"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("notes.md missing %q:\n%s", want, notes)
+		}
+	}
+	if strings.Index(notes, "Bali Hai") > strings.Index(notes, "Wolf Creek") {
+		t.Error("notes should be soonest first")
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(files[EventsFile])).ReadAll()
+	if err != nil {
+		t.Fatalf("tracked-events.csv: %v", err)
+	}
+	var ids []string
+	for _, row := range rows[1:] {
+		ids = append(ids, row[0])
+	}
+	// Dated events soonest first, then undated
+	if got := strings.Join(ids, ","); got != "old,bbb,aaa,ccc,gone" {
+		t.Errorf("CSV rows = %s", got)
+	}
+	if rows[0][0] != "event_id" || rows[3][3] != "Mesquite" || rows[1][7] != "true" {
+		t.Errorf("unexpected CSV:\n%v", rows)
+	}
+
+	ics := files[CalendarFile]
+	if !strings.Contains(ics, "Wolf Creek") || !strings.Contains(ics, "Torrey Pines") {
+		t.Error("calendar.ics should include tracked events")
+	}
+	if strings.Contains(ics, "Skipped Event") {
+		t.Error("calendar.ics should leave out skipped events")
+	}
+}
+
+func TestBuild_NoTrackedEvents(t *testing.T) {
+	data, err := Build(&preferences.UserPreferences{States: []string{"NV"}}, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	names, files := readZip(t, data)
+	if len(names) != 3 {
+		t.Errorf("files = %v, want no calendar.ics", names)
+	}
+	if !strings.Contains(files[NotesFile], "No notes yet.") {
+		t.Errorf("notes.md = %q", files[NotesFile])
+	}
+}
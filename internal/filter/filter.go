@@ -4,6 +4,7 @@
 //   - Date ranges (from/to dates)
 //   - Course names (substring matching, case-insensitive)
 //   - Cities (substring matching, case-insensitive)
+//   - Tags inferred from event titles (championship, scramble, ...)
 //   - States (subset of user's subscribed states)
 //   - Weekends only (Saturday/Sunday)
 //   - Maximum price (placeholder for future use)
@@ -51,6 +52,9 @@ type Filter struct {
 	// City filtering (case-insensitive substring match)
 	Cities []string `json:"cities,omitempty"`
 
+	// Tag filtering (event has at least one of the tags, see event.TagRules)
+	Tags []string `json:"tags,omitempty"`
+
 	// Price filtering (if available in future)
 	MaxPrice float64 `json:"max_price,omitempty"`
 }
@@ -70,6 +74,7 @@ func NewFilter() *Filter {
 		Courses: []string{},
 		States:  []string{},
 		Cities:  []string{},
+		Tags:    []string{},
 	}
 }
 
@@ -94,6 +99,7 @@ func (f *Filter) IsEmpty() bool {
 		!f.WeekendsOnly &&
 		len(f.States) == 0 &&
 		len(f.Cities) == 0 &&
+		len(f.Tags) == 0 &&
 		f.MaxPrice == 0
 }
 
@@ -105,6 +111,7 @@ func (f *Filter) IsEmpty() bool {
 //   - Date range: Event date must be within DateFrom and DateTo (inclusive)
 //   - Courses: Event title must contain at least one course name (case-insensitive)
 //   - Cities: Event city must contain at least one city name (case-insensitive)
+//   - Tags: Event must have at least one of the tags
 //   - States: Event state must match at least one state code (case-insensitive)
 //   - WeekendsOnly: Event must be on Saturday or Sunday
 //   - MaxPrice: Currently a no-op, reserved for future use
@@ -153,6 +160,11 @@ func (f *Filter) Matches(evt *event.Event) bool {
 		return false
 	}
 
+	// Check tag filtering (any of the tags)
+	if !matchesAnyTag(evt, f.Tags) {
+		return false
+	}
+
 	// Check max price (placeholder for future implementation)
 	// Price data would need to be added to Event struct
 	// TODO: Implement when price data is available
@@ -213,6 +225,10 @@ func (f *Filter) String() string {
 		parts = append(parts, fmt.Sprintf("Cities: %s", strings.Join(f.Cities, ", ")))
 	}
 
+	if len(f.Tags) > 0 {
+		parts = append(parts, fmt.Sprintf("Tags: %s", strings.Join(f.Tags, ", ")))
+	}
+
 	if f.MaxPrice > 0 {
 		parts = append(parts, fmt.Sprintf("Max price: $%.2f", f.MaxPrice))
 	}
@@ -305,9 +321,31 @@ func (f *Filter) Clone() *Filter {
 		clone.Cities = []string{}
 	}
 
+	if len(f.Tags) > 0 {
+		clone.Tags = make([]string, len(f.Tags))
+		copy(clone.Tags, f.Tags)
+	} else {
+		clone.Tags = []string{}
+	}
+
 	return clone
 }
 
+// matchesAnyTag checks if the event has any of the tags.
+// Returns true if tags is empty.
+func matchesAnyTag(evt *event.Event, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		if evt.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // matchesAnySubstring checks if value contains any of the terms (case-insensitive).
 // Returns true if terms is empty or if value matches any term.
 func matchesAnySubstring(value string, terms []string) bool {
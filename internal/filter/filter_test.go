@@ -171,6 +171,31 @@ func TestFilter_Matches(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "tag filter matches inferred tag",
+			filter: &Filter{
+				Tags: []string{"scramble", "charity"},
+			},
+			event: &event.Event{
+				Title:    "Spring Scramble",
+				State:    "NV",
+				DateText: "March 15, 2026",
+			},
+			want: true,
+		},
+		{
+			name: "tag filter does not match",
+			filter: &Filter{
+				Tags: []string{"championship"},
+			},
+			event: &event.Event{
+				Title:    "Spring Scramble",
+				State:    "NV",
+				Tags:     []string{"scramble"},
+				DateText: "March 15, 2026",
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
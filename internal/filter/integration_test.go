@@ -6,6 +6,7 @@ import (
 
 	"github.com/pfrederiksen/vga-events/internal/event"
 	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/testutil"
 )
 
 // TestIntegration demonstrates the full filter workflow
@@ -42,8 +43,11 @@ func TestIntegration(t *testing.T) {
 		},
 	}
 
+	// Pin the clock before March 2026 so the year inferred for "March" is 2026
+	clk := testutil.NewFakeClock(time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC))
+
 	t.Run("Filter by date range", func(t *testing.T) {
-		from, to, err := filter.ParseDateRange("March 1-20")
+		from, to, err := filter.ParseDateRangeAt("March 1-20", clk.Now())
 		if err != nil {
 			t.Fatalf("ParseDateRange failed: %v", err)
 		}
@@ -96,7 +100,7 @@ func TestIntegration(t *testing.T) {
 	})
 
 	t.Run("Combine multiple filters", func(t *testing.T) {
-		from, to, err := filter.ParseDateRange("March")
+		from, to, err := filter.ParseDateRangeAt("March", clk.Now())
 		if err != nil {
 			t.Fatalf("ParseDateRange failed: %v", err)
 		}
@@ -23,6 +23,11 @@ import (
 // Returns (dateFrom, dateTo, error). Times are in UTC.
 // Start time is at 00:00:00, end time is at 23:59:59.
 func ParseDateRange(input string) (*time.Time, *time.Time, error) {
+	return ParseDateRangeAt(input, time.Now())
+}
+
+// ParseDateRangeAt is ParseDateRange, inferring years relative to now
+func ParseDateRangeAt(input string, now time.Time) (*time.Time, *time.Time, error) {
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return nil, nil, fmt.Errorf("date range cannot be empty")
@@ -48,7 +53,7 @@ func ParseDateRange(input string) (*time.Time, *time.Time, error) {
 			return nil, nil, fmt.Errorf("invalid day: %s", matches[3])
 		}
 
-		year := getYearForMonth(month)
+		year := getYearForMonth(month, now)
 		from := time.Date(year, month, day1, 0, 0, 0, 0, time.UTC)
 		to := time.Date(year, month, day2, 23, 59, 59, 0, time.UTC)
 
@@ -82,8 +87,8 @@ func ParseDateRange(input string) (*time.Time, *time.Time, error) {
 			return nil, nil, fmt.Errorf("invalid day: %s", matches[4])
 		}
 
-		year1 := getYearForMonth(month1)
-		year2 := getYearForMonth(month2)
+		year1 := getYearForMonth(month1, now)
+		year2 := getYearForMonth(month2, now)
 
 		// If month2 < month1, assume month2 is in the next year
 		if month2 < month1 {
@@ -108,7 +113,7 @@ func ParseDateRange(input string) (*time.Time, *time.Time, error) {
 			return nil, nil, fmt.Errorf("invalid month: %s", matches[1])
 		}
 
-		year := getYearForMonth(month)
+		year := getYearForMonth(month, now)
 		from := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
 		// Last day of month
 		to := time.Date(year, month+1, 0, 23, 59, 59, 0, time.UTC)
@@ -143,8 +148,7 @@ func parseMonth(name string) time.Month {
 
 // getYearForMonth returns the appropriate year for a given month
 // If the month has already passed this year, returns next year
-func getYearForMonth(month time.Month) int {
-	now := time.Now()
+func getYearForMonth(month time.Month, now time.Time) int {
 	year := now.Year()
 
 	// If month is in the past, use next year
@@ -189,7 +189,7 @@ func TestGetYearForMonth(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getYearForMonth(tt.month)
+			got := getYearForMonth(tt.month, now)
 			// Allow for edge cases around year boundary
 			if got != tt.want && got != tt.want+1 {
 				t.Errorf("getYearForMonth(%v) = %v, want %v (or %v for year boundary)", tt.month, got, tt.want, tt.want+1)
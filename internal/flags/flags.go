@@ -0,0 +1,292 @@
+// Package flags holds feature toggles, so a large new subsystem can ship dark and be
+// turned on per deployment, for a percentage of users, or for a list of chats.
+//
+// Toggles come from a JSON file (--flags-file or VGA_FLAGS_FILE) keyed by flag name,
+// each either a bool or a rollout rule:
+//
+//	{
+//	  "enable_geocoding": false,
+//	  "enable_web_api": {"percent": 20, "chats": ["123456789"]}
+//	}
+//
+// and from VGA_FLAGS, which overrides the file one flag at a time:
+//
+//	VGA_FLAGS="enable_web_api=on,enable_natural_queries=10%"
+//
+// A flag that isn't configured keeps its default. Cohorts are picked by hashing the
+// chat ID with the flag name, so the same chats stay enabled as the percentage grows.
+// While a flag is staged, the bot records each user's cohort in their weekly stats so
+// `vga-events prefs rollout` can compare engagement before the flag goes to 100%.
+package flags
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Flag is a feature that can be toggled
+type Flag struct {
+	Name        string
+	Description string
+	Default     bool // Whether the feature is on when the flag isn't configured
+}
+
+// Known flags
+var (
+	// Geocoding covers everything that locates cities: distances from home on event
+	// cards and the /home command
+	Geocoding = &Flag{
+		Name:        "enable_geocoding",
+		Description: "Distances from home on event cards, and /home",
+		Default:     true,
+	}
+
+	// WebAPI covers the HTTP API served by vga-events serve-api and the bot's
+	// /api-token command
+	WebAPI = &Flag{
+		Name:        "enable_web_api",
+		Description: "The HTTP API (serve-api) and /api-token",
+		Default:     true,
+	}
+
+	// NaturalQueries covers answering plain-text messages such as "events in NV next
+	// month" in the bot
+	NaturalQueries = &Flag{
+		Name:        "enable_natural_queries",
+		Description: "Answering plain-text questions about events in the bot",
+		Default:     true,
+	}
+)
+
+// known lists every flag a config may name
+var known = []*Flag{Geocoding, WebAPI, NaturalQueries}
+
+// Known returns every flag, in the order they're listed in help output
+func Known() []*Flag {
+	return slices.Clone(known)
+}
+
+// Lookup returns the flag with the given name
+func Lookup(name string) (*Flag, error) {
+	for _, f := range known {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	names := make([]string, len(known))
+	for i, f := range known {
+		names[i] = f.Name
+	}
+	return nil, fmt.Errorf("unknown feature flag %q (known: %s)", name, strings.Join(names, ", "))
+}
+
+// Rule is how a configured flag is rolled out. A chat has the feature if Enabled is
+// set, its ID is in Chats, or it falls in the first Percent of chats.
+type Rule struct {
+	Enabled bool     `json:"enabled,omitempty"`
+	Percent int      `json:"percent,omitempty"`
+	Chats   []string `json:"chats,omitempty"`
+}
+
+// UnmarshalJSON accepts a rule object or a bool, which is shorthand for {"enabled": b}
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*r = Rule{Enabled: enabled}
+		return nil
+	}
+
+	type plain Rule
+	var rule plain
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rule); err != nil {
+		return err
+	}
+	*r = Rule(rule)
+	return r.validate()
+}
+
+// validate checks the rule's percentage
+func (r *Rule) validate() error {
+	if r.Percent < 0 || r.Percent > 100 {
+		return fmt.Errorf("percent must be between 0 and 100, got %d", r.Percent)
+	}
+	return nil
+}
+
+// Set is the configured rules, keyed by flag name. A nil Set leaves every flag at
+// its default.
+type Set struct {
+	rules map[string]Rule
+}
+
+// New returns a Set with no flags configured
+func New() *Set {
+	return &Set{rules: make(map[string]Rule)}
+}
+
+// Parse reads a flags file
+func Parse(data []byte) (*Set, error) {
+	var rules map[string]Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing feature flags: %w", err)
+	}
+
+	s := New()
+	for name, rule := range rules {
+		if _, err := Lookup(name); err != nil {
+			return nil, err
+		}
+		s.rules[name] = rule
+	}
+	return s, nil
+}
+
+// Load reads the flags file at path, if any, then applies the overrides in env (the
+// VGA_FLAGS format)
+func Load(path, env string) (*Set, error) {
+	s := New()
+	if path != "" {
+		data, err := os.ReadFile(path) // #nosec G304 - Path comes from the operator's config
+		if err != nil {
+			return nil, fmt.Errorf("reading feature flags: %w", err)
+		}
+		if s, err = Parse(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ApplyEnv(env); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ApplyEnv applies comma-separated name=value overrides, where value is on, off, true,
+// false, or a percentage like 25%. An override replaces the flag's whole rule.
+func (s *Set) ApplyEnv(env string) error {
+	for _, item := range strings.Split(env, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("feature flag override %q: want name=value", item)
+		}
+		name = strings.TrimSpace(name)
+		if _, err := Lookup(name); err != nil {
+			return err
+		}
+
+		rule, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("feature flag %s: %w", name, err)
+		}
+		s.rules[name] = rule
+	}
+	return nil
+}
+
+// parseValue parses one VGA_FLAGS value
+func parseValue(value string) (Rule, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "1":
+		return Rule{Enabled: true}, nil
+	case "off", "false", "0":
+		return Rule{}, nil
+	}
+
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || !strings.HasSuffix(value, "%") {
+		return Rule{}, fmt.Errorf("want on, off, or a percentage like 25%%, got %q", value)
+	}
+	rule := Rule{Percent: percent}
+	return rule, rule.validate()
+}
+
+// Enabled reports whether f is on for everyone. A nil Flag is always on.
+func (s *Set) Enabled(f *Flag) bool {
+	if f == nil {
+		return true
+	}
+	rule, ok := s.rule(f)
+	if !ok {
+		return f.Default
+	}
+	return rule.Enabled
+}
+
+// EnabledFor reports whether f is on for a chat: on for everyone, listed, or in the
+// rollout percentage. A nil Flag is always on.
+func (s *Set) EnabledFor(f *Flag, chatID string) bool {
+	if f == nil {
+		return true
+	}
+	rule, ok := s.rule(f)
+	if !ok {
+		return f.Default
+	}
+	return rule.Enabled || slices.Contains(rule.Chats, chatID) || Bucket(f, chatID) < rule.Percent
+}
+
+// Dark reports whether f is off for every chat
+func (s *Set) Dark(f *Flag) bool {
+	rule, ok := s.rule(f)
+	if !ok {
+		return !f.Default
+	}
+	return !rule.Enabled && rule.Percent == 0 && len(rule.Chats) == 0
+}
+
+// Staged reports whether f is part way through a rollout: on for some chats but not
+// everyone. Engagement is recorded per cohort while a flag is staged.
+func (s *Set) Staged(f *Flag) bool {
+	return !s.Enabled(f) && !s.Dark(f)
+}
+
+// Status describes how f is rolled out, e.g. "on", "off", or "20% + 2 chats"
+func (s *Set) Status(f *Flag) string {
+	rule, ok := s.rule(f)
+	switch {
+	case !ok && f.Default, ok && rule.Enabled:
+		return "on"
+	case s.Dark(f):
+		return "off"
+	}
+
+	var parts []string
+	if rule.Percent > 0 {
+		parts = append(parts, fmt.Sprintf("%d%%", rule.Percent))
+	}
+	if n := len(rule.Chats); n == 1 {
+		parts = append(parts, "1 chat")
+	} else if n > 1 {
+		parts = append(parts, fmt.Sprintf("%d chats", n))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// rule returns f's configured rule, if any
+func (s *Set) rule(f *Flag) (Rule, bool) {
+	if s == nil {
+		return Rule{}, false
+	}
+	rule, ok := s.rules[f.Name]
+	return rule, ok
+}
+
+// Bucket places a chat in 0-99 for f's rollout. A chat is in a Percent rollout when
+// its bucket is below Percent, so the bucket is stable for a chat and flag and the
+// buckets of different flags are independent.
+func Bucket(f *Flag, chatID string) int {
+	sum := sha256.Sum256([]byte(f.Name + "|" + chatID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
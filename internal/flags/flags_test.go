@@ -0,0 +1,166 @@
+package flags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	var unset *Set
+	for _, s := range []*Set{unset, New()} {
+		if !s.Enabled(WebAPI) || !s.EnabledFor(Geocoding, "123") || s.Dark(NaturalQueries) {
+			t.Error("unconfigured flags should keep their defaults")
+		}
+		if !s.EnabledFor(nil, "123") {
+			t.Error("a nil flag should always be on")
+		}
+	}
+
+	dark := &Flag{Name: "enable_dark"}
+	if New().EnabledFor(dark, "123") || !New().Dark(dark) || New().Status(dark) != "off" {
+		t.Error("a flag that defaults off should stay off until configured")
+	}
+}
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`{
+		"enable_geocoding": false,
+		"enable_web_api": {"percent": 30, "chats": ["111", "222"]},
+		"enable_natural_queries": true
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if s.Enabled(Geocoding) || s.EnabledFor(Geocoding, "111") || !s.Dark(Geocoding) {
+		t.Error("enable_geocoding: false should turn geocoding off for everyone")
+	}
+	if s.Enabled(WebAPI) || !s.EnabledFor(WebAPI, "111") || s.Dark(WebAPI) {
+		t.Error("listed chats should get the web API before everyone")
+	}
+	if !s.Enabled(NaturalQueries) {
+		t.Error("enable_natural_queries: true should be on for everyone")
+	}
+
+	for name, want := range map[*Flag]string{Geocoding: "off", WebAPI: "30% + 2 chats", NaturalQueries: "on"} {
+		if got := s.Status(name); got != want {
+			t.Errorf("Status(%s) = %q, want %q", name.Name, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"enable_teleport": true}`,
+		`{"enable_web_api": {"percent": 101}}`,
+		`{"enable_web_api": {"percnt": 10}}`,
+		`{"enable_web_api": "yes"}`,
+		`[]`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s) should fail", data)
+		}
+	}
+}
+
+func TestPercentRollout(t *testing.T) {
+	s := New()
+	if err := s.ApplyEnv("enable_web_api=25%"); err != nil {
+		t.Fatalf("ApplyEnv() error: %v", err)
+	}
+
+	enabled := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		chatID := fmt.Sprintf("%d", 100000+i)
+		enabled[chatID] = s.EnabledFor(WebAPI, chatID)
+	}
+	count := 0
+	for _, on := range enabled {
+		if on {
+			count++
+		}
+	}
+	if count < 200 || count > 300 {
+		t.Errorf("25%% rollout enabled %d of 1000 chats", count)
+	}
+
+	// Growing the rollout keeps the chats that already had the feature
+	if err := s.ApplyEnv("enable_web_api=60%"); err != nil {
+		t.Fatalf("ApplyEnv() error: %v", err)
+	}
+	for chatID, on := range enabled {
+		if on && !s.EnabledFor(WebAPI, chatID) {
+			t.Fatalf("chat %s lost the feature when the rollout grew", chatID)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	s := New()
+	if err := s.ApplyEnv(" enable_geocoding=off , enable_web_api=ON,, "); err != nil {
+		t.Fatalf("ApplyEnv() error: %v", err)
+	}
+	if s.Enabled(Geocoding) || !s.Enabled(WebAPI) {
+		t.Error("overrides not applied")
+	}
+
+	for _, env := range []string{"enable_web_api", "enable_web_api=maybe", "enable_web_api=25", "enable_web_api=150%", "enable_teleport=on"} {
+		if err := New().ApplyEnv(env); err == nil {
+			t.Errorf("ApplyEnv(%q) should fail", env)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"enable_geocoding": false, "enable_web_api": {"chats": ["111"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The environment overrides the file one flag at a time
+	s, err := Load(path, "enable_geocoding=on")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !s.Enabled(Geocoding) || s.Enabled(WebAPI) || !s.EnabledFor(WebAPI, "111") {
+		t.Errorf("Load() = geocoding %s, web API %s", s.Status(Geocoding), s.Status(WebAPI))
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Error("Load() of a missing file should fail")
+	}
+	if s, err := Load("", ""); err != nil || !s.Enabled(WebAPI) {
+		t.Errorf("Load() with nothing configured = %v, %v", s, err)
+	}
+}
+
+func TestStaged(t *testing.T) {
+	s, err := Parse([]byte(`{"enable_geocoding": false, "enable_web_api": {"percent": 10}, "enable_natural_queries": true}`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if s.Staged(Geocoding) || !s.Staged(WebAPI) || s.Staged(NaturalQueries) {
+		t.Error("only a partial rollout should be staged")
+	}
+	var unset *Set
+	if unset.Staged(WebAPI) {
+		t.Error("an unconfigured flag isn't staged")
+	}
+
+	// The 10% cohort is exactly the chats whose bucket is below 10
+	for i := 0; i < 500; i++ {
+		chatID := fmt.Sprintf("%d", 200000+i)
+		b := Bucket(WebAPI, chatID)
+		if b < 0 || b > 99 {
+			t.Fatalf("Bucket(%s) = %d, want 0-99", chatID, b)
+		}
+		if s.EnabledFor(WebAPI, chatID) != (b < 10) {
+			t.Fatalf("chat %s in bucket %d: EnabledFor = %v", chatID, b, s.EnabledFor(WebAPI, chatID))
+		}
+		if Bucket(WebAPI, chatID) != b {
+			t.Fatalf("Bucket(%s) isn't deterministic", chatID)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package geo
+
+import "strings"
+
+// cityAbbreviations expands an abbreviated first word of a city name, keyed by the
+// lowercased word without its period: "N. Las Vegas" is North Las Vegas. "St." is
+// left alone, since many cities spell it that way.
+var cityAbbreviations = map[string]string{
+	"n":  "North",
+	"no": "North",
+	"s":  "South",
+	"so": "South",
+	"e":  "East",
+	"w":  "West",
+	"mt": "Mount",
+	"ft": "Fort",
+	"pt": "Point",
+}
+
+// CleanCity tidies a scraped city name: it trims and collapses whitespace, drops a
+// trailing state code matching state ("Henderson NV"), expands abbreviations like
+// "N." and "Mt.", and fixes small misspellings of cities in the table. Known cities
+// get their table spelling; other all-caps or all-lowercase names are title-cased.
+func CleanCity(city, state string) string {
+	words := strings.Fields(strings.Trim(city, " ,"))
+
+	// Drop a trailing state code: "Henderson NV", "Henderson, NV"
+	if n := len(words); n > 1 && state != "" && strings.EqualFold(strings.TrimRight(words[n-1], "."), state) {
+		words = words[:n-1]
+		words[n-2] = strings.TrimRight(words[n-2], ",")
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	cleaned := strings.Join(words, " ")
+	if cleaned == strings.ToUpper(cleaned) || cleaned == strings.ToLower(cleaned) {
+		words = strings.Fields(titleCase(cleaned))
+	}
+	if len(words) > 1 {
+		if full, ok := cityAbbreviations[strings.ToLower(strings.TrimSuffix(words[0], "."))]; ok {
+			words[0] = full
+		}
+	}
+
+	cleaned = strings.Join(words, " ")
+	if known, ok := knownCity(cleaned, state); ok {
+		return known
+	}
+	return cleaned
+}
+
+// knownCity returns the table spelling of a city in state, allowing a typo or two in
+// longer names. The closest city wins; a tie is too ambiguous to correct.
+func knownCity(city, state string) (string, bool) {
+	key := NormalizeCity(city)
+	state = strings.ToUpper(strings.TrimSpace(state))
+	for _, e := range cities[key] {
+		if state == "" || e.state == state {
+			return e.name, true
+		}
+	}
+	if state == "" {
+		return "", false
+	}
+
+	maxDistance := 0
+	switch {
+	case len(key) >= 9:
+		maxDistance = 2
+	case len(key) >= 5:
+		maxDistance = 1
+	}
+
+	best, bestDistance, tied := "", maxDistance+1, false
+	for name, entries := range cities {
+		for _, e := range entries {
+			if e.state != state {
+				continue
+			}
+			switch d := editDistance(key, name); {
+			case d < bestDistance:
+				best, bestDistance, tied = e.name, d, false
+			case d == bestDistance:
+				tied = true
+			}
+		}
+	}
+	if best == "" || tied {
+		return "", false
+	}
+	return best, true
+}
+
+// titleCase capitalizes the first letter of each word and lowercases the rest
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = strings.ToUpper(string(r[:1])) + strings.ToLower(string(r[1:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
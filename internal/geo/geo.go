@@ -0,0 +1,160 @@
+// Package geo locates cities and measures the distance between them, for matching
+// events against city subscriptions with a radius.
+//
+// Coordinates come from a built-in table of cities where VGA events are commonly held;
+// there is no network geocoding. Cities that aren't in the table can still be matched
+// by name. The scraper cleans city names with CleanCity, so spelling variants like
+// "N. Las Vegas" or "Henderson NV" find their table entry.
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// earthRadiusMiles is the mean radius of the Earth
+const earthRadiusMiles = 3958.8
+
+// Point is a location in decimal degrees
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// NormalizeCity lowercases a city name, drops periods, and collapses whitespace, so
+// "Las  Vegas" and "las vegas" compare equal
+func NormalizeCity(city string) string {
+	city = strings.ReplaceAll(strings.ToLower(city), ".", "")
+	return strings.Join(strings.Fields(city), " ")
+}
+
+// Lookup returns the coordinates of a city. state may be empty, in which case the city
+// is found only if its name is unique in the table.
+func Lookup(city, state string) (Point, bool) {
+	entries := cities[NormalizeCity(city)]
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if state == "" {
+		if len(entries) == 1 {
+			return entries[0].point, true
+		}
+		return Point{}, false
+	}
+	for _, e := range entries {
+		if e.state == state {
+			return e.point, true
+		}
+	}
+	return Point{}, false
+}
+
+// DistanceMiles returns the great-circle distance between two points
+func DistanceMiles(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+type cityEntry struct {
+	name  string // Display name
+	state string
+	point Point
+}
+
+// cities maps normalized city names to their locations
+var cities = map[string][]cityEntry{}
+
+func init() {
+	for _, c := range []struct {
+		name, state string
+		lat, lon    float64
+	}{
+		// Nevada
+		{"Las Vegas", "NV", 36.1699, -115.1398},
+		{"Henderson", "NV", 36.0395, -114.9817},
+		{"North Las Vegas", "NV", 36.1989, -115.1175},
+		{"Boulder City", "NV", 35.9786, -114.8325},
+		{"Mesquite", "NV", 36.8055, -114.0672},
+		{"Pahrump", "NV", 36.2083, -115.9839},
+		{"Reno", "NV", 39.5296, -119.8138},
+		{"Sparks", "NV", 39.5349, -119.7527},
+		{"Carson City", "NV", 39.1638, -119.7674},
+
+		// Arizona
+		{"Phoenix", "AZ", 33.4484, -112.0740},
+		{"Scottsdale", "AZ", 33.4942, -111.9261},
+		{"Mesa", "AZ", 33.4152, -111.8315},
+		{"Tempe", "AZ", 33.4255, -111.9400},
+		{"Chandler", "AZ", 33.3062, -111.8413},
+		{"Gilbert", "AZ", 33.3528, -111.7890},
+		{"Glendale", "AZ", 33.5387, -112.1860},
+		{"Peoria", "AZ", 33.5806, -112.2374},
+		{"Surprise", "AZ", 33.6292, -112.3680},
+		{"Goodyear", "AZ", 33.4353, -112.3577},
+		{"Tucson", "AZ", 32.2226, -110.9747},
+		{"Marana", "AZ", 32.4367, -111.2254},
+		{"Sedona", "AZ", 34.8697, -111.7610},
+		{"Prescott", "AZ", 34.5400, -112.4685},
+
+		// California
+		{"Los Angeles", "CA", 34.0522, -118.2437},
+		{"San Diego", "CA", 32.7157, -117.1611},
+		{"Carlsbad", "CA", 33.1581, -117.3506},
+		{"Temecula", "CA", 33.4936, -117.1484},
+		{"Irvine", "CA", 33.6846, -117.8265},
+		{"Palm Springs", "CA", 33.8303, -116.5453},
+		{"Palm Desert", "CA", 33.7222, -116.3745},
+		{"La Quinta", "CA", 33.6634, -116.3100},
+		{"Indio", "CA", 33.7206, -116.2156},
+		{"San Francisco", "CA", 37.7749, -122.4194},
+		{"San Jose", "CA", 37.3382, -121.8863},
+		{"Sacramento", "CA", 38.5816, -121.4944},
+		{"Fresno", "CA", 36.7378, -119.7871},
+		{"Monterey", "CA", 36.6002, -121.8947},
+
+		// Utah, Colorado, New Mexico
+		{"Salt Lake City", "UT", 40.7608, -111.8910},
+		{"St. George", "UT", 37.0965, -113.5684},
+		{"Denver", "CO", 39.7392, -104.9903},
+		{"Colorado Springs", "CO", 38.8339, -104.8214},
+		{"Albuquerque", "NM", 35.0844, -106.6504},
+
+		// Texas
+		{"Dallas", "TX", 32.7767, -96.7970},
+		{"Fort Worth", "TX", 32.7555, -97.3308},
+		{"Plano", "TX", 33.0198, -96.6989},
+		{"Frisco", "TX", 33.1507, -96.8236},
+		{"Houston", "TX", 29.7604, -95.3698},
+		{"The Woodlands", "TX", 30.1658, -95.4613},
+		{"Austin", "TX", 30.2672, -97.7431},
+		{"San Antonio", "TX", 29.4241, -98.4936},
+
+		// Southeast
+		{"Orlando", "FL", 28.5383, -81.3792},
+		{"Tampa", "FL", 27.9506, -82.4572},
+		{"Miami", "FL", 25.7617, -80.1918},
+		{"Jacksonville", "FL", 30.3322, -81.6557},
+		{"Naples", "FL", 26.1420, -81.7948},
+		{"Fort Myers", "FL", 26.6406, -81.8723},
+		{"Atlanta", "GA", 33.7490, -84.3880},
+		{"Charlotte", "NC", 35.2271, -80.8431},
+		{"Raleigh", "NC", 35.7796, -78.6382},
+		{"Pinehurst", "NC", 35.1954, -79.4695},
+		{"Myrtle Beach", "SC", 33.6891, -78.8867},
+		{"Nashville", "TN", 36.1627, -86.7816},
+
+		// Elsewhere
+		{"Seattle", "WA", 47.6062, -122.3321},
+		{"Portland", "OR", 45.5152, -122.6784},
+		{"Chicago", "IL", 41.8781, -87.6298},
+		{"Minneapolis", "MN", 44.9778, -93.2650},
+		{"New York", "NY", 40.7128, -74.0060},
+		{"Boston", "MA", 42.3601, -71.0589},
+		{"Philadelphia", "PA", 39.9526, -75.1652},
+	} {
+		key := NormalizeCity(c.name)
+		cities[key] = append(cities[key], cityEntry{name: c.name, state: c.state, point: Point{Lat: c.lat, Lon: c.lon}})
+	}
+}
@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeCity(t *testing.T) {
+	tests := map[string]string{
+		"Las  Vegas":      "las vegas",
+		" St. George ":    "st george",
+		"NORTH LAS VEGAS": "north las vegas",
+	}
+	for in, want := range tests {
+		if got := NormalizeCity(in); got != want {
+			t.Errorf("NormalizeCity(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if _, ok := Lookup("las vegas", "NV"); !ok {
+		t.Error("Las Vegas, NV should be found")
+	}
+	if _, ok := Lookup("Las Vegas", ""); !ok {
+		t.Error("a unique city should be found without a state")
+	}
+	if _, ok := Lookup("Las Vegas", "NM"); ok {
+		t.Error("Las Vegas, NM is not in the table")
+	}
+	if _, ok := Lookup("Nowhere", ""); ok {
+		t.Error("unknown city should not be found")
+	}
+}
+
+func TestDistanceMiles(t *testing.T) {
+	lv, _ := Lookup("Las Vegas", "NV")
+	henderson, _ := Lookup("Henderson", "NV")
+	phoenix, _ := Lookup("Phoenix", "AZ")
+
+	if d := DistanceMiles(lv, lv); d != 0 {
+		t.Errorf("distance to self = %v, want 0", d)
+	}
+	if d := DistanceMiles(lv, henderson); d < 5 || d > 15 {
+		t.Errorf("Las Vegas to Henderson = %.1f mi, want about 10", d)
+	}
+	if d := DistanceMiles(lv, phoenix); math.Abs(d-256) > 15 {
+		t.Errorf("Las Vegas to Phoenix = %.1f mi, want about 256", d)
+	}
+}
+
+func TestCleanCity(t *testing.T) {
+	tests := []struct {
+		city, state, want string
+	}{
+		{"Las  Vegas", "NV", "Las Vegas"},
+		{"N. Las Vegas", "NV", "North Las Vegas"},
+		{"Henderson NV", "NV", "Henderson"},
+		{"Henderson, NV", "NV", "Henderson"},
+		{"Las Vegas", "", "Las Vegas"},
+		{"st george", "UT", "St. George"},
+		{"Scottsdail", "AZ", "Scottsdale"},  // One typo
+		{"Albuquerqe", "NM", "Albuquerque"}, // Corrected only within the state
+		{"Albuquerqe", "NV", "Albuquerqe"},  // Not corrected to another state's city
+		{"MT. CHARLESTON", "NV", "Mount Charleston"},
+		{"McKinney", "TX", "McKinney"}, // Mixed case is kept
+		{"Mesa", "AZ", "Mesa"},
+		{"Messa", "NV", "Messa"}, // Short names aren't corrected
+		{"  ", "NV", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanCity(tt.city, tt.state); got != tt.want {
+			t.Errorf("CleanCity(%q, %q) = %q, want %q", tt.city, tt.state, got, tt.want)
+		}
+	}
+}
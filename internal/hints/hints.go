@@ -0,0 +1,88 @@
+// Package hints works out quick decision aids shown on a new-event card: events the
+// user already tracks on the same day, and how far the event is from their home city.
+package hints
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/geo"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// Conflicts returns the events the user is tracking (any status but skip) on the same
+// day as evt, looked up in events by ID. Events that can't be dated never conflict.
+func Conflicts(user *preferences.UserPreferences, evt *event.Event, events map[string]*event.Event) []*event.Event {
+	date := event.ParseDate(evt.DateText)
+	if date.IsZero() {
+		return nil
+	}
+
+	var conflicts []*event.Event
+	for eventID, status := range user.EventStatuses {
+		if eventID == evt.ID || status == "" || status == preferences.EventStatusSkip {
+			continue
+		}
+		other, ok := events[eventID]
+		if !ok {
+			continue
+		}
+		if otherDate := event.ParseDate(other.DateText); !otherDate.IsZero() && otherDate.Equal(date) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Title < conflicts[j].Title })
+	return conflicts
+}
+
+// DistanceFromHome returns how many miles evt is from the user's home city (/home), and
+// false if either can't be located
+func DistanceFromHome(user *preferences.UserPreferences, evt *event.Event) (float64, bool) {
+	if user.Home == nil || evt.City == "" {
+		return 0, false
+	}
+	home, ok := geo.Lookup(user.Home.City, user.Home.State)
+	if !ok {
+		return 0, false
+	}
+	at, ok := geo.Lookup(evt.City, evt.State)
+	if !ok {
+		return 0, false
+	}
+	return geo.DistanceMiles(home, at), true
+}
+
+// For returns the hint lines for a new-event card, e.g. "⚠️ Conflicts with your Apr 4
+// event: Wolf Creek" and "🚗 210 miles from home". Lines are HTML-safe.
+func For(user *preferences.UserPreferences, evt *event.Event, events map[string]*event.Event) []string {
+	if user == nil {
+		return nil
+	}
+
+	var lines []string
+	if conflicts := Conflicts(user, evt, events); len(conflicts) > 0 {
+		titles := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			titles[i] = c.Title
+		}
+		noun := "event"
+		if len(conflicts) > 1 {
+			noun = "events"
+		}
+		day := event.ParseDate(evt.DateText).Format("Jan 2")
+		lines = append(lines, fmt.Sprintf("⚠️ Conflicts with your %s %s: %s", day, noun, html.EscapeString(strings.Join(titles, ", "))))
+	}
+
+	if miles, ok := DistanceFromHome(user, evt); ok {
+		if miles < 1 {
+			lines = append(lines, "🏠 In your home city")
+		} else {
+			lines = append(lines, fmt.Sprintf("🚗 %d miles from home", int(math.Round(miles))))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,41 @@
+package hints
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+func TestFor(t *testing.T) {
+	user := preferences.NewPreferences().GetUser("111")
+	evt := &event.Event{ID: "new", Title: "Wolf Creek", City: "Phoenix", State: "AZ", DateText: "Apr 4 2026"}
+	events := map[string]*event.Event{
+		"reg":  {ID: "reg", Title: "Coyote Springs", DateText: "Apr 4 2026"},
+		"skip": {ID: "skip", Title: "Paiute", DateText: "Apr 4 2026"},
+		"late": {ID: "late", Title: "Shadow Creek", DateText: "Apr 5 2026"},
+	}
+	user.SetEventStatus("reg", preferences.EventStatusRegistered)
+	user.SetEventStatus("skip", preferences.EventStatusSkip)
+	user.SetEventStatus("late", preferences.EventStatusInterested)
+
+	if lines := For(user, evt, events); len(lines) != 1 || lines[0] != "⚠️ Conflicts with your Apr 4 event: Coyote Springs" {
+		t.Errorf("For() without home = %q", lines)
+	}
+
+	user.Home = &preferences.CitySubscription{City: "Las Vegas", State: "NV"}
+	lines := For(user, evt, events)
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "🚗 ") || !strings.HasSuffix(lines[1], " miles from home") {
+		t.Errorf("For() with home = %q", lines)
+	}
+
+	evt.City, evt.State = "Las Vegas", "NV"
+	if lines := For(user, evt, nil); len(lines) != 1 || lines[0] != "🏠 In your home city" {
+		t.Errorf("For() in home city = %q", lines)
+	}
+
+	if For(nil, evt, events) != nil {
+		t.Error("For(nil) should return no hints")
+	}
+}
@@ -0,0 +1,224 @@
+// Package imagecard renders social card images for events: 1200×630 PNGs with the
+// course name, date, city, and a state badge, sized for Open Graph and Twitter link
+// previews. Fonts are the Go fonts, embedded in the binary.
+package imagecard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// Width and Height are the card size recommended for Open Graph images
+	Width  = 1200
+	Height = 630
+
+	margin        = 72
+	maxTitleLines = 3
+)
+
+var (
+	background = color.RGBA{0x1b, 0x5e, 0x20, 0xff}
+	badgeColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	badgeText  = color.RGBA{0x1b, 0x5e, 0x20, 0xff}
+	titleColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	metaColor  = color.RGBA{0xc8, 0xe6, 0xc9, 0xff}
+)
+
+// Card is the text on a card
+type Card struct {
+	Title  string
+	Date   string
+	City   string
+	State  string
+	Footer string
+}
+
+// FromEvent returns the card for an event
+func FromEvent(evt *event.Event) Card {
+	return Card{
+		Title:  evt.Title,
+		Date:   evt.DateText,
+		City:   evt.City,
+		State:  strings.ToUpper(evt.State),
+		Footer: "VGA Golf · vgagolf.org",
+	}
+}
+
+// Key returns a short hash of the card's text. Cards with the same key render the
+// same image, so it works as a cache key or ETag.
+func (c Card) Key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.Title, c.Date, c.City, c.State, c.Footer}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// faces are the font faces a card is drawn with. Faces aren't safe for concurrent
+// use, so each card gets its own.
+type faces struct {
+	title, meta, badge, footer font.Face
+}
+
+var (
+	parseOnce   sync.Once
+	boldFont    *opentype.Font
+	regularFont *opentype.Font
+	parseErr    error
+)
+
+// parseFonts parses the embedded fonts once
+func parseFonts() error {
+	parseOnce.Do(func() {
+		if boldFont, parseErr = opentype.Parse(gobold.TTF); parseErr != nil {
+			parseErr = fmt.Errorf("parsing bold font: %w", parseErr)
+			return
+		}
+		if regularFont, parseErr = opentype.Parse(goregular.TTF); parseErr != nil {
+			parseErr = fmt.Errorf("parsing regular font: %w", parseErr)
+		}
+	})
+	return parseErr
+}
+
+func newFaces() (faces, error) {
+	if err := parseFonts(); err != nil {
+		return faces{}, err
+	}
+
+	var f faces
+	for _, spec := range []struct {
+		face *font.Face
+		font *opentype.Font
+		size float64
+	}{
+		{&f.title, boldFont, 64},
+		{&f.meta, regularFont, 40},
+		{&f.badge, boldFont, 36},
+		{&f.footer, regularFont, 28},
+	} {
+		face, err := opentype.NewFace(spec.font, &opentype.FaceOptions{Size: spec.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return faces{}, fmt.Errorf("creating font face: %w", err)
+		}
+		*spec.face = face
+	}
+	return f, nil
+}
+
+// Render draws the card and writes it as a PNG
+func Render(w io.Writer, card Card) error {
+	img, err := Draw(card)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Draw draws the card
+func Draw(card Card) (*image.RGBA, error) {
+	f, err := newFaces()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	// State badge in the top-left corner
+	y := margin
+	if card.State != "" {
+		textWidth := font.MeasureString(f.badge, card.State).Ceil()
+		badge := image.Rect(margin, y, margin+textWidth+48, y+64)
+		draw.Draw(img, badge, image.NewUniform(badgeColor), image.Point{}, draw.Src)
+		drawText(img, f.badge, badgeText, margin+24, y+46, card.State)
+	}
+	y += 64 + 80
+
+	// Title, wrapped to the card width
+	lineHeight := f.title.Metrics().Height.Ceil() + 8
+	for _, line := range wrap(f.title, card.Title, Width-2*margin, maxTitleLines) {
+		drawText(img, f.title, titleColor, margin, y, line)
+		y += lineHeight
+	}
+
+	// Date and city
+	var meta []string
+	for _, s := range []string{card.Date, card.City} {
+		if s = strings.TrimSpace(s); s != "" {
+			meta = append(meta, s)
+		}
+	}
+	if len(meta) > 0 {
+		drawText(img, f.meta, metaColor, margin, y+16, truncate(f.meta, strings.Join(meta, " · "), Width-2*margin))
+	}
+
+	if card.Footer != "" {
+		drawText(img, f.footer, metaColor, margin, Height-margin+16, card.Footer)
+	}
+	return img, nil
+}
+
+// drawText draws s with its baseline at (x, y)
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// wrap splits s into lines no wider than width, at most maxLines of them. The last
+// line is truncated with an ellipsis if the text doesn't fit.
+func wrap(face font.Face, s string, width, maxLines int) []string {
+	words := strings.Fields(s)
+	var lines []string
+	line := ""
+	for i, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line == "" || font.MeasureString(face, candidate).Ceil() <= width {
+			line = candidate
+			continue
+		}
+
+		lines = append(lines, line)
+		line = word
+		if len(lines) == maxLines-1 {
+			line = strings.Join(words[i:], " ")
+			break
+		}
+	}
+	if line != "" {
+		lines = append(lines, truncate(face, line, width))
+	}
+	return lines
+}
+
+// truncate shortens s with an ellipsis until it's no wider than width
+func truncate(face font.Face, s string, width int) string {
+	if font.MeasureString(face, s).Ceil() <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "…"
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			return candidate
+		}
+	}
+	return "…"
+}
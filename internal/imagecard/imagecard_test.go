@@ -0,0 +1,101 @@
+package imagecard
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"golang.org/x/image/font"
+)
+
+func TestRender(t *testing.T) {
+	card := FromEvent(&event.Event{State: "nv", Title: "Wolf Creek Golf Club", DateText: "Apr 12 2026", City: "Mesquite"})
+	if card.State != "NV" {
+		t.Errorf("state badge = %q, want NV", card.State)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, card); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+		t.Errorf("size = %v, want %dx%d", b.Size(), Width, Height)
+	}
+
+	// The title is drawn in white on the green background
+	img2, _ := Draw(card)
+	white := 0
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			if c := img2.RGBAAt(x, y); c == titleColor {
+				white++
+			}
+		}
+	}
+	if white < 1000 {
+		t.Errorf("only %d title pixels drawn", white)
+	}
+}
+
+func TestRenderConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Draw(Card{Title: "Pebble Beach", State: "CA"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWrap(t *testing.T) {
+	f, err := newFaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := Width - 2*margin
+
+	if lines := wrap(f.title, "Short Title", width, maxTitleLines); len(lines) != 1 || lines[0] != "Short Title" {
+		t.Errorf("short title wrapped to %q", lines)
+	}
+
+	long := strings.Repeat("Championship Invitational ", 20)
+	lines := wrap(f.title, long, width, maxTitleLines)
+	if len(lines) != maxTitleLines {
+		t.Fatalf("got %d lines, want %d", len(lines), maxTitleLines)
+	}
+	for _, line := range lines {
+		if w := font.MeasureString(f.title, line).Ceil(); w > width {
+			t.Errorf("line %q is %dpx, wider than %dpx", line, w, width)
+		}
+	}
+	if !strings.HasSuffix(lines[len(lines)-1], "…") {
+		t.Errorf("overflowing title should end with an ellipsis: %q", lines[len(lines)-1])
+	}
+
+	if lines := wrap(f.title, strings.Repeat("W", 200), width, maxTitleLines); len(lines) != 1 || !strings.HasSuffix(lines[0], "…") {
+		t.Errorf("one long word should be truncated: %q", lines)
+	}
+}
+
+func TestKey(t *testing.T) {
+	a := Card{Title: "Wolf Creek", Date: "Apr 12 2026", State: "NV"}
+	b := a
+	if a.Key() != b.Key() {
+		t.Error("same cards should have the same key")
+	}
+	b.Date = "Apr 13 2026"
+	if a.Key() == b.Key() {
+		t.Error("a changed date should change the key")
+	}
+}
@@ -0,0 +1,113 @@
+// Package issues opens and updates GitHub issues, for problem reports from the bot and
+// for errors that keep recurring across runs.
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// DefaultAPIURL is the GitHub API used when Config.APIURL is empty
+const DefaultAPIURL = "https://api.github.com"
+
+// Config describes the repository issues are filed in
+type Config struct {
+	// Repo is the repository, as owner/name
+	Repo string
+
+	// Token is a GitHub token that can create and edit issues in Repo
+	Token string
+
+	// APIURL is the GitHub API base (default DefaultAPIURL)
+	APIURL string
+}
+
+// Issue is the content of an issue
+type Issue struct {
+	Title  string   `json:"title,omitempty"`
+	Body   string   `json:"body,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	State  string   `json:"state,omitempty"` // "open" or "closed"; updates only
+}
+
+// Created identifies a new issue
+type Created struct {
+	Number int    `json:"number"`
+	URL    string `json:"html_url"`
+}
+
+// Client files issues in one repository
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// NewClient creates a new GitHub issues client
+func NewClient(config Config) *Client {
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// Create opens an issue
+func (c *Client) Create(ctx context.Context, issue Issue) (*Created, error) {
+	var created Created
+	if err := c.do(ctx, "POST", "/repos/"+c.config.Repo+"/issues", issue, http.StatusCreated, &created); err != nil {
+		return nil, fmt.Errorf("creating issue: %w", err)
+	}
+	return &created, nil
+}
+
+// Update edits issue number; empty fields are left as they are
+func (c *Client) Update(ctx context.Context, number int, issue Issue) error {
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/repos/%s/issues/%d", c.config.Repo, number), issue, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("updating issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// do sends payload as JSON and decodes the response into result, if given. The
+// response body isn't included in errors to prevent information leakage.
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}, want int, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.APIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.config.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		return errs.Status(resp.StatusCode, "GitHub API error (status %d)", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+func TestCreateAndUpdate(t *testing.T) {
+	var method, path string
+	var got Issue
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		method, path = r.Method, r.URL.Path
+		got = Issue{}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.com/owner/repo/issues/42"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Repo: "owner/repo", Token: "secret", APIURL: server.URL})
+	created, err := client.Create(context.Background(), Issue{Title: "Broken", Body: "Details", Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.Number != 42 || created.URL != "https://github.com/owner/repo/issues/42" {
+		t.Errorf("Create() = %+v", created)
+	}
+	if method != "POST" || path != "/repos/owner/repo/issues" || got.Title != "Broken" || len(got.Labels) != 1 {
+		t.Errorf("Create() sent %s %s %+v", method, path, got)
+	}
+
+	if err := client.Update(context.Background(), 42, Issue{Body: "More", State: "open"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if method != "PATCH" || path != "/repos/owner/repo/issues/42" || got.Body != "More" || got.State != "open" || got.Title != "" {
+		t.Errorf("Update() sent %s %s %+v", method, path, got)
+	}
+
+	bad := NewClient(Config{Repo: "owner/repo", Token: "wrong", APIURL: server.URL})
+	if _, err := bad.Create(context.Background(), Issue{Title: "x"}); !errs.IsPermanent(err) {
+		t.Errorf("Create() with a bad token = %v; want a permanent error", err)
+	}
+}
@@ -0,0 +1,320 @@
+// Package league keeps season series: a set of events played by a group of members,
+// whose scores at each event rank them and earn points by finish. Standings add up the
+// points over the series.
+//
+// A league is stored with the preferences of the chat that created it, its admin, and
+// is only changed through the bot, so this package has no storage of its own.
+package league
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxEvents is the most events a series can have
+	MaxEvents = 40
+	// MaxMembers is the most members a league can have
+	MaxMembers = 50
+	// MaxNameLength is the longest league name, in bytes
+	MaxNameLength = 40
+	// MinScore and MaxScore bound a recorded score (strokes for a round)
+	MinScore = 18
+	MaxScore = 200
+)
+
+// DefaultPoints are the points for 1st, 2nd, 3rd, ... place when a league sets none.
+// Finishes past the end of the table earn nothing.
+var DefaultPoints = []int{25, 20, 16, 13, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+
+var (
+	// ErrNotMember is returned when recording a score for someone outside the league
+	ErrNotMember = errors.New("not a league member")
+
+	// ErrNotInSeries is returned when recording a score at an event outside the series
+	ErrNotInSeries = errors.New("event is not in the series")
+
+	// ErrInvalidScore is returned for scores outside MinScore to MaxScore
+	ErrInvalidScore = fmt.Errorf("score must be between %d and %d", MinScore, MaxScore)
+
+	// ErrTooManyEvents is returned when a series already has MaxEvents events
+	ErrTooManyEvents = fmt.Errorf("a series can have at most %d events", MaxEvents)
+
+	// ErrTooManyMembers is returned when a league already has MaxMembers members
+	ErrTooManyMembers = fmt.Errorf("a league can have at most %d members", MaxMembers)
+)
+
+// League is a season series and its members' scores
+type League struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Created  int64    `json:"created"`          // Unix time
+	EventIDs []string `json:"event_ids"`        // Events in the series, in the order added
+	Members  []string `json:"members"`          // Member chat IDs, the admin first
+	Points   []int    `json:"points,omitempty"` // Points by finish, 1st first; DefaultPoints when empty
+
+	// Scores by event ID, then member chat ID
+	Scores map[string]map[string]int `json:"scores,omitempty"`
+}
+
+// Standing is a member's line in the standings table
+type Standing struct {
+	Place    int // Shared by members tied on points
+	MemberID string
+	Points   int
+	Played   int // Events with a score
+	Wins     int
+	Best     int // Best finish, 0 if none
+}
+
+// Finish is a member's place at one event
+type Finish struct {
+	MemberID string
+	Score    int
+	Place    int // Members with the same score share the better place
+	Points   int
+}
+
+// IsMember reports whether chatID is in the league
+func (l *League) IsMember(chatID string) bool {
+	return slices.Contains(l.Members, chatID)
+}
+
+// HasEvent reports whether eventID is in the series
+func (l *League) HasEvent(eventID string) bool {
+	return slices.Contains(l.EventIDs, eventID)
+}
+
+// AddMember adds chatID to the league. Returns false if it's already a member.
+func (l *League) AddMember(chatID string) (bool, error) {
+	if l.IsMember(chatID) {
+		return false, nil
+	}
+	if len(l.Members) >= MaxMembers {
+		return false, ErrTooManyMembers
+	}
+	l.Members = append(l.Members, chatID)
+	return true, nil
+}
+
+// RemoveMember removes chatID and its scores. Returns false if it wasn't a member.
+func (l *League) RemoveMember(chatID string) bool {
+	i := slices.Index(l.Members, chatID)
+	if i < 0 {
+		return false
+	}
+	l.Members = slices.Delete(l.Members, i, i+1)
+	for eventID, scores := range l.Scores {
+		delete(scores, chatID)
+		if len(scores) == 0 {
+			delete(l.Scores, eventID)
+		}
+	}
+	return true
+}
+
+// AddEvents adds events to the series, skipping ones already in it, and returns how
+// many were added
+func (l *League) AddEvents(eventIDs []string) (int, error) {
+	added := 0
+	for _, id := range eventIDs {
+		if l.HasEvent(id) {
+			continue
+		}
+		if len(l.EventIDs) >= MaxEvents {
+			return added, ErrTooManyEvents
+		}
+		l.EventIDs = append(l.EventIDs, id)
+		added++
+	}
+	return added, nil
+}
+
+// RemoveEvent takes an event and its scores out of the series. Returns false if it
+// wasn't in it.
+func (l *League) RemoveEvent(eventID string) bool {
+	i := slices.Index(l.EventIDs, eventID)
+	if i < 0 {
+		return false
+	}
+	l.EventIDs = slices.Delete(l.EventIDs, i, i+1)
+	delete(l.Scores, eventID)
+	return true
+}
+
+// RecordScore records a member's score at an event in the series, replacing any earlier
+// one. A score of 0 removes it.
+func (l *League) RecordScore(eventID, memberID string, score int) error {
+	if !l.IsMember(memberID) {
+		return ErrNotMember
+	}
+	if !l.HasEvent(eventID) {
+		return ErrNotInSeries
+	}
+	if score == 0 {
+		delete(l.Scores[eventID], memberID)
+		if len(l.Scores[eventID]) == 0 {
+			delete(l.Scores, eventID)
+		}
+		return nil
+	}
+	if score < MinScore || score > MaxScore {
+		return ErrInvalidScore
+	}
+	if l.Scores == nil {
+		l.Scores = make(map[string]map[string]int)
+	}
+	if l.Scores[eventID] == nil {
+		l.Scores[eventID] = make(map[string]int)
+	}
+	l.Scores[eventID][memberID] = score
+	return nil
+}
+
+// PointsFor returns the points a finish earns
+func (l *League) PointsFor(place int) int {
+	table := l.Points
+	if len(table) == 0 {
+		table = DefaultPoints
+	}
+	if place < 1 || place > len(table) {
+		return 0
+	}
+	return table[place-1]
+}
+
+// Results returns the finishes at an event, lowest score first. Members tied on score
+// share the better place and its points.
+func (l *League) Results(eventID string) []Finish {
+	scores := l.Scores[eventID]
+	finishes := make([]Finish, 0, len(scores))
+	for memberID, score := range scores {
+		finishes = append(finishes, Finish{MemberID: memberID, Score: score})
+	}
+	sort.Slice(finishes, func(i, j int) bool {
+		if finishes[i].Score != finishes[j].Score {
+			return finishes[i].Score < finishes[j].Score
+		}
+		return finishes[i].MemberID < finishes[j].MemberID
+	})
+	for i := range finishes {
+		if i > 0 && finishes[i].Score == finishes[i-1].Score {
+			finishes[i].Place = finishes[i-1].Place
+		} else {
+			finishes[i].Place = i + 1
+		}
+		finishes[i].Points = l.PointsFor(finishes[i].Place)
+	}
+	return finishes
+}
+
+// Standings returns every member's points over the series, most first. Ties on points
+// go to more wins, then the better best finish; members still tied share the place.
+func (l *League) Standings() []Standing {
+	byMember := make(map[string]*Standing, len(l.Members))
+	standings := make([]*Standing, 0, len(l.Members))
+	for _, memberID := range l.Members {
+		s := &Standing{MemberID: memberID}
+		byMember[memberID] = s
+		standings = append(standings, s)
+	}
+	for _, eventID := range l.EventIDs {
+		for _, f := range l.Results(eventID) {
+			s, ok := byMember[f.MemberID]
+			if !ok {
+				continue
+			}
+			s.Points += f.Points
+			s.Played++
+			if f.Place == 1 {
+				s.Wins++
+			}
+			if s.Best == 0 || f.Place < s.Best {
+				s.Best = f.Place
+			}
+		}
+	}
+
+	// A member who hasn't finished sorts after any finish
+	best := func(s *Standing) int {
+		if s.Best == 0 {
+			return MaxMembers + 1
+		}
+		return s.Best
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return best(a) < best(b)
+	})
+
+	result := make([]Standing, len(standings))
+	for i, s := range standings {
+		s.Place = i + 1
+		if i > 0 {
+			prev := standings[i-1]
+			if prev.Points == s.Points && prev.Wins == s.Wins && best(prev) == best(s) {
+				s.Place = prev.Place
+			}
+		}
+		result[i] = *s
+	}
+	return result
+}
+
+// ParsePoints parses a points table like "25,20,16,13": points for 1st, 2nd, ...,
+// each no more than the one before
+func ParsePoints(text string) ([]int, error) {
+	var points []int
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		p, err := strconv.Atoi(field)
+		if err != nil || p < 0 || p > 1000 {
+			return nil, fmt.Errorf("invalid points %q", field)
+		}
+		if len(points) > 0 && p > points[len(points)-1] {
+			return nil, fmt.Errorf("points can't go up from one place to the next (%d after %d)", p, points[len(points)-1])
+		}
+		points = append(points, p)
+	}
+	if len(points) == 0 {
+		return nil, errors.New("no points given")
+	}
+	if len(points) > MaxMembers {
+		return nil, fmt.Errorf("at most %d places can earn points", MaxMembers)
+	}
+	return points, nil
+}
+
+// RenameEvent moves oldID's place in the series and its scores to newID, after the
+// event was renamed and got a new ID. Returns true if the event was in the series.
+func (l *League) RenameEvent(oldID, newID string) bool {
+	i := slices.Index(l.EventIDs, oldID)
+	if i < 0 || oldID == newID {
+		return false
+	}
+	if l.HasEvent(newID) {
+		l.EventIDs = slices.Delete(l.EventIDs, i, i+1)
+	} else {
+		l.EventIDs[i] = newID
+	}
+	if scores, ok := l.Scores[oldID]; ok {
+		delete(l.Scores, oldID)
+		if _, exists := l.Scores[newID]; !exists {
+			l.Scores[newID] = scores
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package league
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestLeague() *League {
+	l := &League{ID: "ABCDE", Name: "Tuesday Crew", Members: []string{"111", "222", "333"}}
+	l.AddEvents([]string{"evt1", "evt2", "evt3"})
+	return l
+}
+
+func TestRecordScore(t *testing.T) {
+	l := newTestLeague()
+
+	if err := l.RecordScore("evt1", "111", 84); err != nil {
+		t.Fatalf("RecordScore() error: %v", err)
+	}
+	if err := l.RecordScore("evt1", "999", 80); !errors.Is(err, ErrNotMember) {
+		t.Errorf("non-member: err = %v", err)
+	}
+	if err := l.RecordScore("other", "111", 80); !errors.Is(err, ErrNotInSeries) {
+		t.Errorf("event outside the series: err = %v", err)
+	}
+	if err := l.RecordScore("evt1", "111", 5); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("too low: err = %v", err)
+	}
+	if err := l.RecordScore("evt1", "111", 0); err != nil || len(l.Scores) != 0 {
+		t.Errorf("clearing the only score should drop the event: err = %v, scores = %v", err, l.Scores)
+	}
+}
+
+func TestResults(t *testing.T) {
+	l := newTestLeague()
+	l.RecordScore("evt1", "111", 84)
+	l.RecordScore("evt1", "222", 80)
+	l.RecordScore("evt1", "333", 84)
+
+	results := l.Results("evt1")
+	want := []Finish{
+		{MemberID: "222", Score: 80, Place: 1, Points: 25},
+		{MemberID: "111", Score: 84, Place: 2, Points: 20},
+		{MemberID: "333", Score: 84, Place: 2, Points: 20},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Results() = %+v", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
+
+func TestStandings(t *testing.T) {
+	l := newTestLeague()
+	l.Points = []int{10, 5}
+	l.RecordScore("evt1", "111", 80) // 111 1st (10), 222 2nd (5)
+	l.RecordScore("evt1", "222", 85)
+	l.RecordScore("evt2", "222", 78) // 222 1st (10), 111 2nd (5)
+	l.RecordScore("evt2", "111", 90)
+
+	standings := l.Standings()
+	if len(standings) != 3 {
+		t.Fatalf("Standings() = %+v", standings)
+	}
+	// 111 and 222 tie on points, wins, and best finish
+	for i, memberID := range []string{"111", "222"} {
+		s := standings[i]
+		if s.MemberID != memberID || s.Points != 15 || s.Place != 1 || s.Played != 2 || s.Wins != 1 || s.Best != 1 {
+			t.Errorf("standings[%d] = %+v", i, s)
+		}
+	}
+	if s := standings[2]; s.MemberID != "333" || s.Points != 0 || s.Place != 3 || s.Best != 0 {
+		t.Errorf("standings[2] = %+v", s)
+	}
+
+	// A third-place finish past the points table breaks no ties but counts as played
+	l.RecordScore("evt3", "333", 70)
+	l.RecordScore("evt3", "111", 75)
+	l.RecordScore("evt3", "222", 76)
+	standings = l.Standings()
+	if standings[0].MemberID != "111" || standings[0].Points != 20 {
+		t.Errorf("leader = %+v", standings[0])
+	}
+	if s := standings[1]; s.MemberID != "222" || s.Points != 15 || s.Played != 3 {
+		t.Errorf("second = %+v", s)
+	}
+}
+
+func TestMembersAndEvents(t *testing.T) {
+	l := newTestLeague()
+	l.RecordScore("evt1", "222", 80)
+	l.RecordScore("evt2", "222", 82)
+
+	if added, _ := l.AddMember("222"); added {
+		t.Error("adding a member twice should do nothing")
+	}
+	if !l.RemoveMember("222") || l.IsMember("222") || len(l.Scores) != 0 {
+		t.Errorf("removing a member should drop their scores: %v", l.Scores)
+	}
+
+	if added, err := l.AddEvents([]string{"evt3", "evt4"}); added != 1 || err != nil {
+		t.Errorf("AddEvents() = %d, %v", added, err)
+	}
+	for len(l.EventIDs) < MaxEvents {
+		l.EventIDs = append(l.EventIDs, "filler")
+	}
+	if _, err := l.AddEvents([]string{"evt5"}); !errors.Is(err, ErrTooManyEvents) {
+		t.Errorf("full series: err = %v", err)
+	}
+
+	l = newTestLeague()
+	l.RecordScore("evt1", "111", 80)
+	if !l.RenameEvent("evt1", "evt9") || !l.HasEvent("evt9") || l.HasEvent("evt1") || l.Scores["evt9"]["111"] != 80 {
+		t.Errorf("RenameEvent() didn't move the event: %v, %v", l.EventIDs, l.Scores)
+	}
+	if !l.RemoveEvent("evt9") || len(l.Scores) != 0 {
+		t.Errorf("RemoveEvent() should drop scores: %v", l.Scores)
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	points, err := ParsePoints("10, 8,6,,4")
+	if err != nil || len(points) != 4 || points[0] != 10 || points[3] != 4 {
+		t.Errorf("ParsePoints() = %v, %v", points, err)
+	}
+	for _, bad := range []string{"", "10,x", "5,8", "-1"} {
+		if _, err := ParsePoints(bad); err == nil {
+			t.Errorf("ParsePoints(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package links builds the outbound URLs notifiers put in messages. With UTM tagging
+// on, every link carries utm_source (the channel), utm_medium, and utm_campaign (the
+// kind of message), so click-through can be measured per channel and message type.
+// Links can also be shortened through a self-hosted shortener, or sent through the API
+// server's /r/<token> redirect so each click is logged.
+//
+// Configure is called once at startup; URL is safe for concurrent use. With nothing
+// configured, URL returns links unchanged.
+package links
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegistrationURL is the VGA page where members sign up for state events
+const RegistrationURL = "https://vgagolf.org/state-events"
+
+// Sources (utm_source) name the channel a link was sent on
+const (
+	SourceTelegram = "telegram"
+	SourceCalendar = "calendar"
+	SourceWidget   = "widget"
+	SourceTwitter  = "twitter"
+	SourceBluesky  = "bluesky"
+	SourceMastodon = "mastodon"
+)
+
+// Campaigns (utm_campaign) name the kind of message a link was in
+const (
+	CampaignNewEvent   = "new-event"
+	CampaignReminder   = "reminder"
+	CampaignDeadline   = "deadline"
+	CampaignAlmostFull = "almost-full"
+	CampaignNewState   = "new-state"
+	CampaignDigest     = "digest"
+	CampaignChange     = "event-change"
+	CampaignRemoval    = "event-removed"
+	CampaignRestored   = "event-restored"
+	CampaignCalendar   = "calendar-export"
+	CampaignWidget     = "widget"
+	CampaignSummary    = "run-summary"
+)
+
+// DefaultMedium is utm_medium when Options.Medium is empty
+const DefaultMedium = "notification"
+
+// defaultShortenTimeout bounds each shortener request; the long URL is used if it's slow
+const defaultShortenTimeout = 5 * time.Second
+
+// Options configures outbound links
+type Options struct {
+	UTM            bool          // Add utm_source, utm_medium, and utm_campaign
+	Medium         string        // utm_medium, DefaultMedium if empty
+	ShortenerURL   string        // Self-hosted shortener endpoint; disabled when empty
+	ShortenerToken string        // Sent as a bearer token to the shortener, if set
+	RedirectURL    string        // Base URL of serve-api; Track links go through its /r/ redirect when set
+	Timeout        time.Duration // Per shortener request, 5s if zero
+}
+
+var (
+	mu      sync.Mutex
+	opts    = Options{Medium: DefaultMedium, Timeout: defaultShortenTimeout}
+	client  = &http.Client{}
+	shorter = make(map[string]string) // Long URL → short URL
+)
+
+// Configure sets how URL builds links and clears the short-link cache
+func Configure(o Options) {
+	mu.Lock()
+	defer mu.Unlock()
+	if o.Medium == "" {
+		o.Medium = DefaultMedium
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultShortenTimeout
+	}
+	opts = o
+	shorter = make(map[string]string)
+}
+
+// URL returns rawURL tagged for the source and campaign and shortened, as configured.
+// If tagging or shortening fails, the link is returned without that step.
+func URL(rawURL, source, campaign string) string {
+	o := currentOptions()
+
+	link := rawURL
+	if o.UTM {
+		tagged, err := Tag(rawURL, source, o.Medium, campaign)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: tagging link: %v\n", err)
+		} else {
+			link = tagged
+		}
+	}
+	if o.ShortenerURL != "" {
+		link = shorten(o, link)
+	}
+	return link
+}
+
+// Track returns the link for a message about eventID (empty for messages about several
+// events). With Options.RedirectURL set, the link goes through the API server's
+// /r/<token> redirect, which logs the click and sends the reader to the event's page;
+// otherwise it's URL(rawURL, source, campaign).
+func Track(rawURL, eventID, source, campaign string) string {
+	o := currentOptions()
+	if o.RedirectURL == "" {
+		return URL(rawURL, source, campaign)
+	}
+	link := strings.TrimSuffix(o.RedirectURL, "/") + "/r/" + ClickToken(source, campaign, eventID)
+	if o.ShortenerURL != "" {
+		link = shorten(o, link)
+	}
+	return link
+}
+
+// ClickToken encodes what a tracked link is for into the /r/ path segment
+func ClickToken(source, campaign, eventID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(source + "\n" + campaign + "\n" + eventID))
+}
+
+// ErrInvalidToken is returned by ParseClickToken for tokens it didn't make
+var ErrInvalidToken = errors.New("invalid click token")
+
+var (
+	tokenFieldPattern = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+	tokenEventPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{0,100}$`)
+)
+
+// ParseClickToken decodes a ClickToken. Tokens aren't signed, so the fields are
+// checked strictly and never used to build the redirect target.
+func ParseClickToken(token string) (source, campaign, eventID string, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", "", ErrInvalidToken
+	}
+	parts := strings.Split(string(data), "\n")
+	if len(parts) != 3 || !tokenFieldPattern.MatchString(parts[0]) || !tokenFieldPattern.MatchString(parts[1]) || !tokenEventPattern.MatchString(parts[2]) {
+		return "", "", "", ErrInvalidToken
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// currentOptions returns the configured options
+func currentOptions() Options {
+	mu.Lock()
+	defer mu.Unlock()
+	return opts
+}
+
+// Tag adds utm_source, utm_medium, and utm_campaign to rawURL, replacing any it
+// already has. Other query parameters and the fragment are kept.
+func Tag(rawURL, source, medium, campaign string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("not an http(s) link: %q", rawURL)
+	}
+
+	q := u.Query()
+	for key, value := range map[string]string{"utm_source": source, "utm_medium": medium, "utm_campaign": campaign} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// shorten returns the short link for longURL, asking the shortener the first time
+func shorten(o Options, longURL string) string {
+	mu.Lock()
+	short, ok := shorter[longURL]
+	mu.Unlock()
+	if ok {
+		return short
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	short, err := requestShortURL(ctx, o, longURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: shortening link: %v\n", err)
+		return longURL
+	}
+
+	mu.Lock()
+	shorter[longURL] = short
+	mu.Unlock()
+	return short
+}
+
+// requestShortURL posts {"url": longURL} to the shortener, which answers with
+// {"short_url": "..."}
+func requestShortURL(ctx context.Context, o Options, longURL string) (string, error) {
+	body, err := json.Marshal(map[string]string{"url": longURL})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.ShortenerURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.ShortenerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+o.ShortenerToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling shortener: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("shortener returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		ShortURL string `json:"short_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding shortener response: %w", err)
+	}
+	if u, err := url.Parse(result.ShortURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("shortener returned an invalid link %q", result.ShortURL)
+	}
+	return result.ShortURL, nil
+}
@@ -0,0 +1,118 @@
+package links
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTag(t *testing.T) {
+	got, err := Tag("https://example.com/events?id=7&utm_source=old#top", SourceTelegram, DefaultMedium, CampaignReminder)
+	if err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("Tag() returned an invalid URL %q", got)
+	}
+	q := u.Query()
+	if q.Get("id") != "7" || q.Get("utm_source") != "telegram" || q.Get("utm_medium") != "notification" || q.Get("utm_campaign") != "reminder" {
+		t.Errorf("Tag() query = %v", q)
+	}
+	if u.Fragment != "top" {
+		t.Errorf("Tag() fragment = %q, want top", u.Fragment)
+	}
+
+	if _, err := Tag("mailto:golf@example.com", SourceTelegram, DefaultMedium, CampaignDigest); err == nil {
+		t.Error("Tag() should reject non-http links")
+	}
+}
+
+func TestURL(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	Configure(Options{})
+	if got := URL(RegistrationURL, SourceTelegram, CampaignNewEvent); got != RegistrationURL {
+		t.Errorf("URL() unconfigured = %q, want it unchanged", got)
+	}
+
+	Configure(Options{UTM: true, Medium: "bot"})
+	want := RegistrationURL + "?utm_campaign=digest&utm_medium=bot&utm_source=calendar"
+	if got := URL(RegistrationURL, SourceCalendar, CampaignDigest); got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
+
+func TestURLShortener(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct{ URL string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.URL == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"short_url": "https://go.example/abc"})
+	}))
+	defer srv.Close()
+
+	Configure(Options{ShortenerURL: srv.URL, ShortenerToken: "secret"})
+	for range 2 {
+		if got := URL(RegistrationURL, SourceTelegram, CampaignNewEvent); got != "https://go.example/abc" {
+			t.Errorf("URL() = %q, want the short link", got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("shortener called %d times, want 1 (cached)", calls)
+	}
+
+	// A failing shortener falls back to the long link
+	Configure(Options{ShortenerURL: srv.URL, ShortenerToken: "wrong"})
+	if got := URL(RegistrationURL, SourceTelegram, CampaignNewEvent); got != RegistrationURL {
+		t.Errorf("URL() with failing shortener = %q, want the long link", got)
+	}
+}
+
+func TestTrack(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	Configure(Options{})
+	if got := Track(RegistrationURL, "abc123", SourceTelegram, CampaignNewEvent); got != RegistrationURL {
+		t.Errorf("Track() without RedirectURL = %q, want the registration page", got)
+	}
+
+	Configure(Options{RedirectURL: "https://events.example.com/"})
+	got := Track(RegistrationURL, "abc123", SourceTelegram, CampaignNewEvent)
+	token, ok := strings.CutPrefix(got, "https://events.example.com/r/")
+	if !ok {
+		t.Fatalf("Track() = %q, want a /r/ link", got)
+	}
+	source, campaign, eventID, err := ParseClickToken(token)
+	if err != nil || source != SourceTelegram || campaign != CampaignNewEvent || eventID != "abc123" {
+		t.Errorf("ParseClickToken() = %q, %q, %q, %v", source, campaign, eventID, err)
+	}
+}
+
+func TestParseClickTokenRejectsJunk(t *testing.T) {
+	for _, token := range []string{
+		"%%%",
+		ClickToken("", CampaignDigest, ""),
+		ClickToken("Telegram<script>", CampaignDigest, ""),
+		ClickToken(SourceTelegram, CampaignDigest, "a/b"),
+		base64.RawURLEncoding.EncodeToString([]byte("telegram\ndigest")),
+	} {
+		if _, _, _, err := ParseClickToken(token); err == nil {
+			t.Errorf("ParseClickToken(%q) should fail", token)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Package pdf renders a printable one-page schedule of events: a US Letter page with a
+// title and a date / course / city / status table. It uses the PDF standard Helvetica
+// fonts, so nothing needs embedding and the output stays a few kilobytes.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+const (
+	pageWidth  = 612 // US Letter, in points
+	pageHeight = 792
+	margin     = 40
+
+	fontSize      = 9
+	maxRowHeight  = 16
+	minRowHeight  = 11
+	cellPadding   = 5
+	headerHeight  = 18
+	tableTop      = pageHeight - margin - 62
+	footerReserve = 30
+)
+
+// columns are the table's headings and widths in points; they add up to the page
+// width inside the margins
+var columns = []struct {
+	heading string
+	width   float64
+}{
+	{"Date", 96},
+	{"Course", 226},
+	{"City", 134},
+	{"Status", 76},
+}
+
+// Row is one event in the schedule
+type Row struct {
+	Date   string
+	Course string
+	City   string
+	Status string // e.g. "Registered"; empty for events without a status
+}
+
+// Schedule is the content of the page
+type Schedule struct {
+	Title     string
+	Subtitle  string // e.g. "Tracked events" or "Nevada"
+	Rows      []Row
+	Generated time.Time
+}
+
+// EventRow returns the row for an event with the given status label
+func EventRow(evt *event.Event, status string) Row {
+	city := evt.City
+	if city != "" && evt.State != "" {
+		city += ", " + strings.ToUpper(evt.State)
+	} else if city == "" {
+		city = strings.ToUpper(evt.State)
+	}
+	return Row{Date: evt.DateText, Course: evt.Title, City: city, Status: status}
+}
+
+// EventRows returns the rows for events, labelled with their status in statuses
+// (a user's EventStatuses)
+func EventRows(events []*event.Event, statuses map[string]string) []Row {
+	rows := make([]Row, len(events))
+	for i, evt := range events {
+		rows[i] = EventRow(evt, StatusLabel(statuses[evt.ID]))
+	}
+	return rows
+}
+
+// StatusLabel returns the Status column text for an event status
+func StatusLabel(status string) string {
+	switch status {
+	case preferences.EventStatusRegistered:
+		return "Registered"
+	case preferences.EventStatusInterested:
+		return "Interested"
+	case preferences.EventStatusMaybe:
+		return "Maybe"
+	case preferences.EventStatusSkip:
+		return "Skipped"
+	}
+	return ""
+}
+
+// Render returns the schedule as a one-page PDF. Rows are tightened to fit the page;
+// any that still don't fit are replaced by an "and N more" line.
+func Render(s Schedule) []byte {
+	var content bytes.Buffer
+	writeSchedule(&content, s)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// writeSchedule writes the page's content stream
+func writeSchedule(w *bytes.Buffer, s Schedule) {
+	// Title and subtitle
+	text(w, "F2", 18, margin, pageHeight-margin-18, s.Title)
+	subtitle := s.Subtitle
+	if !s.Generated.IsZero() {
+		if subtitle != "" {
+			subtitle += " • "
+		}
+		subtitle += "Generated " + s.Generated.Format("Jan 2, 2006")
+	}
+	text(w, "F1", 10, margin, pageHeight-margin-36, subtitle)
+
+	// Header row on a green band
+	tableWidth := float64(pageWidth - 2*margin)
+	y := float64(tableTop)
+	fmt.Fprintf(w, "0.106 0.369 0.125 rg %d %.1f %.1f %d re f\n", margin, y-headerHeight, tableWidth, headerHeight)
+	fmt.Fprintf(w, "1 g\n")
+	x := float64(margin)
+	for _, col := range columns {
+		text(w, "F2", fontSize, x+cellPadding, y-headerHeight+6, col.heading)
+		x += col.width
+	}
+	fmt.Fprintf(w, "0 g\n")
+	y -= headerHeight
+
+	if len(s.Rows) == 0 {
+		text(w, "F1", 10, margin+cellPadding, y-20, "No events to show.")
+		return
+	}
+
+	// Tighten rows so as many as possible fit above the footer
+	available := y - margin - footerReserve
+	rowHeight := float64(maxRowHeight)
+	if fit := available / float64(len(s.Rows)); fit < rowHeight {
+		rowHeight = max(fit, minRowHeight)
+	}
+	rows := s.Rows
+	hidden := 0
+	if maxRows := int(available / rowHeight); len(rows) > maxRows {
+		// Leave a row free for the "and N more" line
+		hidden = len(rows) - (maxRows - 1)
+		rows = rows[:maxRows-1]
+	}
+
+	for i, row := range rows {
+		if i%2 == 1 {
+			fmt.Fprintf(w, "0.93 0.96 0.93 rg %d %.1f %.1f %.1f re f 0 g\n", margin, y-rowHeight, tableWidth, rowHeight)
+		}
+		x := float64(margin)
+		for j, cell := range []string{row.Date, row.Course, row.City, row.Status} {
+			if cell == "" {
+				x += columns[j].width
+				continue
+			}
+			font := "F1"
+			if j == 3 && cell == "Registered" {
+				font = "F2"
+			}
+			text(w, font, fontSize, x+cellPadding, y-rowHeight+(rowHeight-fontSize)/2+2, fit(cell, columns[j].width-2*cellPadding))
+			x += columns[j].width
+		}
+		y -= rowHeight
+	}
+
+	// Rule under the table
+	fmt.Fprintf(w, "0.6 G 0.5 w %d %.1f m %.1f %.1f l S 0 G\n", margin, y, margin+tableWidth, y)
+
+	if hidden > 0 {
+		text(w, "F1", fontSize, margin+cellPadding, y-rowHeight+3, fmt.Sprintf("… and %d more event(s)", hidden))
+	}
+	text(w, "F1", 8, margin, margin, "VGA Golf • vgagolf.org")
+}
+
+// text writes a line of text at (x, y)
+func text(w *bytes.Buffer, font string, size, x, y float64, s string) {
+	fmt.Fprintf(w, "BT /%s %.0f Tf %.1f %.1f Td (%s) Tj ET\n", font, size, x, y, escape(encode(s)))
+}
+
+// fit shortens s with an ellipsis so it's at most width points wide in Helvetica at
+// fontSize
+func fit(s string, width float64) string {
+	if textWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "…"
+}
+
+// textWidth returns the width of s in points in Helvetica at fontSize
+func textWidth(s string) float64 {
+	units := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			units += helveticaWidths[r-' ']
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * fontSize / 1000
+}
+
+// helveticaWidths are the Helvetica glyph widths for ' ' through '~', in 1/1000 em
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// winAnsi maps the punctuation that commonly appears in event text to WinAnsiEncoding
+var winAnsi = map[rune]byte{
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '…': 0x85,
+}
+
+// encode converts s to WinAnsiEncoding for the standard fonts; characters they can't
+// show (emoji, most non-Latin scripts) become '?'
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// escape escapes a PDF literal string
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", "", "\n", " ").Replace(s)
+}
@@ -0,0 +1,72 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestRender(t *testing.T) {
+	doc := Render(Schedule{
+		Title:     "My VGA Schedule",
+		Subtitle:  "Tracked events",
+		Generated: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Rows: []Row{
+			EventRow(&event.Event{Title: "Wolf Creek (Mesquite)", City: "Mesquite", State: "nv", DateText: "Apr 4 2026"}, "Registered"),
+			{Date: "Apr 18 2026", Course: strings.Repeat("Very Long Course Name ", 10), City: "Las Vegas, NV"},
+		},
+	})
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	for _, want := range []string{"(My VGA Schedule)", "(Tracked events \x95 Generated Mar 1, 2026)", `(Wolf Creek \(Mesquite\))`, "(Mesquite, NV)", "(Registered)"} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("PDF missing %q", want)
+		}
+	}
+	if bytes.Contains(doc, []byte(strings.Repeat("Very Long Course Name ", 10))) {
+		t.Error("long course names should be shortened to fit the column")
+	}
+
+	// Every xref entry must point at its object
+	xref := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(doc, -1)
+	if len(xref) != 6 {
+		t.Fatalf("xref has %d entries, want 6", len(xref))
+	}
+	for i, entry := range xref {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, doc[offset:offset+10])
+		}
+	}
+}
+
+func TestRenderFitsOnePage(t *testing.T) {
+	var rows []Row
+	for i := range 120 {
+		rows = append(rows, Row{Date: "May 1 2026", Course: fmt.Sprintf("Course %d", i)})
+	}
+	doc := Render(Schedule{Title: "Nevada", Rows: rows})
+	if !bytes.Contains(doc, []byte(`and 67 more event\(s\)`)) {
+		t.Error("rows past the page should be summarized")
+	}
+	if bytes.Contains(doc, []byte("(Course 53)")) {
+		t.Error("the last rows shouldn't be drawn")
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := fit("Wolf Creek", 100); got != "Wolf Creek" {
+		t.Errorf("fit() = %q, want unchanged", got)
+	}
+	if got := fit("Wolf Creek Golf Club", 50); !strings.HasSuffix(got, "…") || textWidth(got) > 50 {
+		t.Errorf("fit() = %q (%.1fpt), want shortened to 50pt", got, textWidth(got))
+	}
+}
@@ -0,0 +1,34 @@
+// Package directions is an example plugin: it adds a 🗺️ Directions button, a map search
+// for the course, to new-event cards and reminders. Turn it on with --plugins directions.
+package directions
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/plugin"
+)
+
+// mapsSearchURL is Google Maps' cross-platform search link
+const mapsSearchURL = "https://www.google.com/maps/search/?api=1&query="
+
+// Plugin adds the Directions button
+type Plugin struct{}
+
+// Name implements plugin.Plugin
+func (Plugin) Name() string { return "directions" }
+
+// OnNotifyUser implements plugin.NotifyUserHook. Only notifications about an upcoming
+// round get the button, and only when the event has a city to search near.
+func (Plugin) OnNotifyUser(ctx context.Context, n *plugin.Notification) error {
+	if n.Event == nil || n.Event.City == "" {
+		return nil
+	}
+	if n.Kind != "new" && !strings.HasPrefix(n.Kind, "reminder-") {
+		return nil
+	}
+	query := strings.Join([]string{n.Event.Title, n.Event.City, n.Event.State}, ", ")
+	n.Actions = append(n.Actions, plugin.Action{Text: "🗺️ Directions", URL: mapsSearchURL + url.QueryEscape(query)})
+	return nil
+}
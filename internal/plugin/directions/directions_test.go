@@ -0,0 +1,39 @@
+package directions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/plugin"
+)
+
+func TestOnNotifyUser(t *testing.T) {
+	evt := &event.Event{ID: "e1", Title: "Bali Hai Golf Club", City: "Las Vegas", State: "NV"}
+
+	tests := []struct {
+		kind string
+		evt  *event.Event
+		want bool
+	}{
+		{"new", evt, true},
+		{"reminder-3", evt, true},
+		{"removed", evt, false},
+		{"deadline-2", evt, false},
+		{"new", &event.Event{ID: "e2", Title: "TBD", State: "NV"}, false},
+	}
+	for _, tt := range tests {
+		n := &plugin.Notification{Kind: tt.kind, Event: tt.evt}
+		if err := (Plugin{}).OnNotifyUser(context.Background(), n); err != nil {
+			t.Fatalf("OnNotifyUser(%s) error = %v", tt.kind, err)
+		}
+		if got := len(n.Actions) == 1; got != tt.want {
+			t.Errorf("OnNotifyUser(%s, %s) added button = %v, want %v", tt.kind, tt.evt.ID, got, tt.want)
+			continue
+		}
+		if tt.want && !strings.HasSuffix(n.Actions[0].URL, "query=Bali+Hai+Golf+Club%2C+Las+Vegas%2C+NV") {
+			t.Errorf("URL = %q", n.Actions[0].URL)
+		}
+	}
+}
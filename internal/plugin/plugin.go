@@ -0,0 +1,165 @@
+// Package plugin lets compiled-in extensions hook into the event pipeline without
+// patching it. A plugin implements Plugin plus any of the hook interfaces; the check
+// command calls OnEventNew and OnEventRemoved after each diff (changes to the events
+// are saved in the snapshot and reported), and the notifier calls OnNotifyUser before
+// each message, where a plugin can edit the text or add link buttons.
+//
+// Plugins are listed in each binary's main and turned on by name with --plugins, so
+// forks add theirs in one place:
+//
+//	cli.Execute(version, commit, date, directions.Plugin{}, myfork.Plugin{})
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// Plugin is a compiled-in extension
+type Plugin interface {
+	// Name is the plugin's --plugins name, lowercase
+	Name() string
+}
+
+// EventNewHook is called for each event new in this scrape. The event may be changed
+// in place, e.g. to fill in details from another source.
+type EventNewHook interface {
+	Plugin
+	OnEventNew(ctx context.Context, evt *event.Event) error
+}
+
+// EventRemovedHook is called for each event reported removed in this scrape
+type EventRemovedHook interface {
+	Plugin
+	OnEventRemoved(ctx context.Context, evt *event.Event) error
+}
+
+// NotifyUserHook is called before a notification is sent to a chat
+type NotifyUserHook interface {
+	Plugin
+	OnNotifyUser(ctx context.Context, n *Notification) error
+}
+
+// Notification is a message about to be sent to a chat. Hooks may change Text and
+// append Actions.
+type Notification struct {
+	ChatID  string
+	Kind    string // Ledger kind: new, removed, restored, reminder-3, deadline-2, ...
+	Event   *event.Event
+	Text    string   // Telegram HTML
+	Actions []Action // Extra link buttons, one row below the message's own
+}
+
+// Action is a link button added to a notification
+type Action struct {
+	Text string
+	URL  string
+}
+
+// Set is the plugins turned on for a run. A nil Set has no plugins.
+type Set struct {
+	plugins []Plugin
+}
+
+// Select returns the plugins in available named in names (a comma-separated list, as
+// given to --plugins), in that order. An empty list selects none.
+func Select(available []Plugin, names string) (*Set, error) {
+	set := &Set{}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, p := range available {
+			if p.Name() == name {
+				set.plugins = append(set.plugins, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown plugin %q (available: %s)", name, strings.Join(Names(available), ", "))
+		}
+	}
+	return set, nil
+}
+
+// Names returns the names of plugins
+func Names(plugins []Plugin) []string {
+	names := make([]string, len(plugins))
+	for i, p := range plugins {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// Len returns the number of plugins in the set
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.plugins)
+}
+
+// EventNew runs the OnEventNew hooks for each event. A failing hook doesn't stop the
+// others; the errors are returned together.
+func (s *Set) EventNew(ctx context.Context, events []*event.Event) error {
+	if s == nil {
+		return nil
+	}
+	var errs []error
+	for _, p := range s.plugins {
+		hook, ok := p.(EventNewHook)
+		if !ok {
+			continue
+		}
+		for _, evt := range events {
+			if err := hook.OnEventNew(ctx, evt); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: event %s: %w", p.Name(), evt.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EventRemoved runs the OnEventRemoved hooks for each event, like EventNew
+func (s *Set) EventRemoved(ctx context.Context, events []*event.Event) error {
+	if s == nil {
+		return nil
+	}
+	var errs []error
+	for _, p := range s.plugins {
+		hook, ok := p.(EventRemovedHook)
+		if !ok {
+			continue
+		}
+		for _, evt := range events {
+			if err := hook.OnEventRemoved(ctx, evt); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: event %s: %w", p.Name(), evt.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyUser runs the OnNotifyUser hooks in order, each seeing the changes of the ones
+// before. A failing hook's changes are still kept; the errors are returned together.
+func (s *Set) NotifyUser(ctx context.Context, n *Notification) error {
+	if s == nil {
+		return nil
+	}
+	var errs []error
+	for _, p := range s.plugins {
+		if hook, ok := p.(NotifyUserHook); ok {
+			if err := hook.OnNotifyUser(ctx, n); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: %w", p.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
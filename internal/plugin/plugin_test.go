@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// fakePlugin records the events it sees and tags notifications with its name
+type fakePlugin struct {
+	name    string
+	err     error
+	seen    []string
+	removed []string
+}
+
+func (p *fakePlugin) Name() string { return p.name }
+
+func (p *fakePlugin) OnEventNew(ctx context.Context, evt *event.Event) error {
+	p.seen = append(p.seen, evt.ID)
+	evt.Raw += "[" + p.name + "]"
+	return p.err
+}
+
+func (p *fakePlugin) OnEventRemoved(ctx context.Context, evt *event.Event) error {
+	p.removed = append(p.removed, evt.ID)
+	return p.err
+}
+
+func (p *fakePlugin) OnNotifyUser(ctx context.Context, n *Notification) error {
+	n.Text += " " + p.name
+	n.Actions = append(n.Actions, Action{Text: p.name, URL: "https://example.com/" + p.name})
+	return p.err
+}
+
+// namedOnly implements no hooks
+type namedOnly string
+
+func (n namedOnly) Name() string { return string(n) }
+
+func TestSelect(t *testing.T) {
+	a, b := &fakePlugin{name: "a"}, &fakePlugin{name: "b"}
+	available := []Plugin{a, b, namedOnly("c")}
+
+	set, err := Select(available, " B, a ,")
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if got := Names(set.plugins); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("Select() = %v, want [b a] in the order given", got)
+	}
+
+	if set, err := Select(available, ""); err != nil || set.Len() != 0 {
+		t.Errorf("Select(\"\") = %d plugins, %v; want none", set.Len(), err)
+	}
+
+	_, err = Select(available, "a,nope")
+	if err == nil || !strings.Contains(err.Error(), `"nope"`) || !strings.Contains(err.Error(), "a, b, c") {
+		t.Errorf("Select(unknown) error = %v, want it to name the plugin and list the available ones", err)
+	}
+}
+
+func TestEventHooks(t *testing.T) {
+	a, b := &fakePlugin{name: "a"}, &fakePlugin{name: "b", err: errors.New("lookup failed")}
+	set, err := Select([]Plugin{a, b, namedOnly("c")}, "a,b,c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*event.Event{{ID: "e1"}, {ID: "e2"}}
+
+	err = set.EventNew(context.Background(), events)
+	if err == nil || !strings.Contains(err.Error(), "plugin b: event e1") || !strings.Contains(err.Error(), "plugin b: event e2") {
+		t.Errorf("EventNew() error = %v, want b's failures for both events", err)
+	}
+	if events[0].Raw != "[a][b]" {
+		t.Errorf("Raw = %q, want both plugins to run in order", events[0].Raw)
+	}
+
+	if err := set.EventRemoved(context.Background(), events[:1]); err == nil {
+		t.Error("EventRemoved() should return b's error")
+	}
+	if !reflect.DeepEqual(a.removed, []string{"e1"}) {
+		t.Errorf("a.removed = %v, want [e1]", a.removed)
+	}
+}
+
+func TestNotifyUser(t *testing.T) {
+	set, err := Select([]Plugin{&fakePlugin{name: "a"}, &fakePlugin{name: "b"}}, "a,b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &Notification{Kind: "new", Text: "card"}
+	if err := set.NotifyUser(context.Background(), n); err != nil {
+		t.Fatalf("NotifyUser() error = %v", err)
+	}
+	if n.Text != "card a b" || len(n.Actions) != 2 {
+		t.Errorf("NotifyUser() = %q with %d actions, want both plugins applied", n.Text, len(n.Actions))
+	}
+}
+
+func TestNilSet(t *testing.T) {
+	var set *Set
+	if set.Len() != 0 {
+		t.Error("nil Set should have no plugins")
+	}
+	if err := set.EventNew(context.Background(), []*event.Event{{ID: "e1"}}); err != nil {
+		t.Error(err)
+	}
+	if err := set.EventRemoved(context.Background(), nil); err != nil {
+		t.Error(err)
+	}
+	if err := set.NotifyUser(context.Background(), &Notification{}); err != nil {
+		t.Error(err)
+	}
+}
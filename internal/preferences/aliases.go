@@ -0,0 +1,70 @@
+package preferences
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxAliases caps how many shortcuts one user can define
+	MaxAliases = 20
+
+	// MaxAliasExpansionLength is the longest command an alias can stand for
+	MaxAliasExpansionLength = 100
+)
+
+var (
+	// ErrInvalidAliasName is returned for names that aren't 1-20 lowercase letters,
+	// digits, or hyphens
+	ErrInvalidAliasName = errors.New("invalid alias name")
+
+	// ErrInvalidAliasExpansion is returned when the expansion isn't a /command or is too long
+	ErrInvalidAliasExpansion = errors.New("invalid alias expansion")
+
+	// ErrTooManyAliases is returned when the user already has MaxAliases other aliases
+	ErrTooManyAliases = errors.New("too many aliases")
+)
+
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,19}$`)
+
+// ValidAliasName reports whether name (without the leading slash) can be an alias
+func ValidAliasName(name string) bool {
+	return aliasNamePattern.MatchString(name)
+}
+
+// SetAlias defines /name as a shortcut for expansion (e.g. "/near Las Vegas"),
+// replacing any earlier definition of the name
+func (u *UserPreferences) SetAlias(name, expansion string) error {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	if !ValidAliasName(name) {
+		return ErrInvalidAliasName
+	}
+	expansion = strings.Join(strings.Fields(expansion), " ")
+	if !strings.HasPrefix(expansion, "/") || len(expansion) < 2 || len(expansion) > MaxAliasExpansionLength {
+		return ErrInvalidAliasExpansion
+	}
+	if _, replacing := u.Aliases[name]; !replacing && len(u.Aliases) >= MaxAliases {
+		return ErrTooManyAliases
+	}
+	if u.Aliases == nil {
+		u.Aliases = make(map[string]string)
+	}
+	u.Aliases[name] = expansion
+	return nil
+}
+
+// GetAlias returns what /name expands to, or "" if it isn't an alias
+func (u *UserPreferences) GetAlias(name string) string {
+	return u.Aliases[strings.ToLower(strings.TrimPrefix(name, "/"))]
+}
+
+// RemoveAlias deletes an alias, reporting whether it existed
+func (u *UserPreferences) RemoveAlias(name string) bool {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	if _, ok := u.Aliases[name]; !ok {
+		return false
+	}
+	delete(u.Aliases, name)
+	return true
+}
@@ -0,0 +1,48 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	user := NewPreferences().GetUser("111")
+
+	if err := user.SetAlias("/NE", "/near   Las Vegas"); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+	if got := user.GetAlias("ne"); got != "/near Las Vegas" {
+		t.Errorf("GetAlias = %q", got)
+	}
+
+	for _, tc := range []struct {
+		name, expansion string
+		want            error
+	}{
+		{"bad name!", "/events", ErrInvalidAliasName},
+		{"-x", "/events", ErrInvalidAliasName},
+		{"ok", "events", ErrInvalidAliasExpansion},
+		{"ok", "/", ErrInvalidAliasExpansion},
+	} {
+		if err := user.SetAlias(tc.name, tc.expansion); !errors.Is(err, tc.want) {
+			t.Errorf("SetAlias(%q, %q) = %v, want %v", tc.name, tc.expansion, err, tc.want)
+		}
+	}
+
+	for i := len(user.Aliases); i < MaxAliases; i++ {
+		if err := user.SetAlias(fmt.Sprintf("a%d", i), "/events"); err != nil {
+			t.Fatalf("alias %d: %v", i, err)
+		}
+	}
+	if err := user.SetAlias("one-more", "/events"); !errors.Is(err, ErrTooManyAliases) {
+		t.Errorf("over the limit: %v", err)
+	}
+	if err := user.SetAlias("ne", "/near Reno"); err != nil {
+		t.Errorf("redefining at the limit: %v", err)
+	}
+
+	if !user.RemoveAlias("ne") || user.RemoveAlias("ne") || user.GetAlias("ne") != "" {
+		t.Error("RemoveAlias should delete once")
+	}
+}
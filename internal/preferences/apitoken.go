@@ -0,0 +1,60 @@
+package preferences
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	// APITokenPrefix starts every personal API token, so they're easy to recognize
+	APITokenPrefix = "vga_"
+
+	apiTokenLength = 32 // Random characters after the prefix (160 bits)
+)
+
+// NewAPIToken creates a personal API token for the HTTP API, replacing (revoking) any
+// earlier one. Only a hash is stored, so the token can't be shown again.
+func (u *UserPreferences) NewAPIToken(now time.Time) string {
+	token := APITokenPrefix + randomCode(apiTokenLength)
+	u.APITokenHash = hashAPIToken(token)
+	u.APITokenCreated = now.Unix()
+	return token
+}
+
+// RevokeAPIToken removes the user's API token. Returns false if they didn't have one.
+func (u *UserPreferences) RevokeAPIToken() bool {
+	if u.APITokenHash == "" {
+		return false
+	}
+	u.APITokenHash = ""
+	u.APITokenCreated = 0
+	return true
+}
+
+// HasAPIToken reports whether the user has an API token
+func (u *UserPreferences) HasAPIToken() bool {
+	return u.APITokenHash != ""
+}
+
+// FindAPIToken returns the chat an API token belongs to
+func (p Preferences) FindAPIToken(token string) (string, bool) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return "", false
+	}
+	hash := []byte(hashAPIToken(token))
+	for chatID, user := range p {
+		if user.APITokenHash != "" && subtle.ConstantTimeCompare(hash, []byte(user.APITokenHash)) == 1 {
+			return chatID, true
+		}
+	}
+	return "", false
+}
+
+// hashAPIToken returns the hex SHA-256 of a token, as stored in preferences
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,46 @@
+package preferences
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIToken(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	prefs.GetUser("456").NewAPIToken(time.Now())
+
+	token := user.NewAPIToken(time.Now())
+	if !strings.HasPrefix(token, APITokenPrefix) || len(token) != len(APITokenPrefix)+apiTokenLength {
+		t.Fatalf("NewAPIToken() = %q", token)
+	}
+	if strings.Contains(user.APITokenHash, token) || !user.HasAPIToken() {
+		t.Error("only the token's hash should be stored")
+	}
+	if chatID, ok := prefs.FindAPIToken(token); !ok || chatID != "123" {
+		t.Errorf("FindAPIToken() = %q, %v", chatID, ok)
+	}
+
+	// Rotating invalidates the old token
+	rotated := user.NewAPIToken(time.Now())
+	if _, ok := prefs.FindAPIToken(token); ok {
+		t.Error("old token should stop working after rotation")
+	}
+	if _, ok := prefs.FindAPIToken(rotated); !ok {
+		t.Error("new token should work")
+	}
+
+	for _, bad := range []string{"", "vga_", "nope", strings.TrimPrefix(rotated, APITokenPrefix)} {
+		if _, ok := prefs.FindAPIToken(bad); ok {
+			t.Errorf("FindAPIToken(%q) should fail", bad)
+		}
+	}
+
+	if !user.RevokeAPIToken() || user.RevokeAPIToken() {
+		t.Error("RevokeAPIToken() should succeed once")
+	}
+	if _, ok := prefs.FindAPIToken(rotated); ok {
+		t.Error("revoked token should stop working")
+	}
+}
@@ -0,0 +1,92 @@
+package preferences
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// MaxNoteAttachments caps how many event notes can have an attachment
+	MaxNoteAttachments = 20
+
+	// MaxAttachmentBytes is the largest file that can be attached to a note
+	MaxAttachmentBytes = 10 << 20
+
+	// Attachment kinds, which decide how the file is re-sent
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+	AttachmentVoice    = "voice"
+
+	// PendingNoteWindow is how long after opening a note a voice message is added to it
+	PendingNoteWindow = 10 * time.Minute
+)
+
+var (
+	// ErrAttachmentTooLarge is returned for files over MaxAttachmentBytes
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+	// ErrTooManyAttachments is returned when MaxNoteAttachments notes already have one
+	ErrTooManyAttachments = errors.New("too many note attachments")
+)
+
+// NoteAttachment is a photo or file attached to an event note. Only Telegram's file_id
+// is kept; the bot re-sends the file by ID, so nothing is downloaded or stored.
+type NoteAttachment struct {
+	FileID string `json:"file_id"`
+	Kind   string `json:"kind"`           // AttachmentPhoto, AttachmentDocument, or AttachmentVoice
+	Size   int64  `json:"size,omitempty"` // Bytes, as reported by Telegram
+}
+
+// SetNoteAttachment attaches a file to an event's note, replacing any earlier one
+func (u *UserPreferences) SetNoteAttachment(eventID string, attachment *NoteAttachment) error {
+	if attachment.Size > MaxAttachmentBytes {
+		return ErrAttachmentTooLarge
+	}
+	if _, replacing := u.NoteAttachments[eventID]; !replacing && len(u.NoteAttachments) >= MaxNoteAttachments {
+		return ErrTooManyAttachments
+	}
+	if u.NoteAttachments == nil {
+		u.NoteAttachments = make(map[string]*NoteAttachment)
+	}
+	u.NoteAttachments[eventID] = attachment
+	return nil
+}
+
+// GetNoteAttachment returns the file attached to an event's note, or nil
+func (u *UserPreferences) GetNoteAttachment(eventID string) *NoteAttachment {
+	return u.NoteAttachments[eventID]
+}
+
+// RemoveNoteAttachment removes the file attached to an event's note, reporting whether
+// there was one
+func (u *UserPreferences) RemoveNoteAttachment(eventID string) bool {
+	if _, ok := u.NoteAttachments[eventID]; !ok {
+		return false
+	}
+	delete(u.NoteAttachments, eventID)
+	return true
+}
+
+// PendingNote is a note the user just opened with /note, so a voice message sent next
+// (which can't carry a /note caption) knows which event it's for
+type PendingNote struct {
+	EventID string `json:"event_id"`
+	Expires int64  `json:"expires"` // Unix time
+}
+
+// SetPendingNote makes eventID's note the one the next voice message is added to, for
+// PendingNoteWindow
+func (u *UserPreferences) SetPendingNote(eventID string, now time.Time) {
+	u.PendingNote = &PendingNote{EventID: eventID, Expires: now.Add(PendingNoteWindow).Unix()}
+}
+
+// TakePendingNote returns the event whose note is waiting for a voice message and clears
+// it, or "" if there's none or it has expired
+func (u *UserPreferences) TakePendingNote(now time.Time) string {
+	pending := u.PendingNote
+	u.PendingNote = nil
+	if pending == nil || now.Unix() > pending.Expires {
+		return ""
+	}
+	return pending.EventID
+}
@@ -0,0 +1,63 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNoteAttachments(t *testing.T) {
+	user := NewPreferences().GetUser("111")
+
+	if err := user.SetNoteAttachment("evt1", &NoteAttachment{FileID: "big", Size: MaxAttachmentBytes + 1}); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("oversized attachment: err = %v", err)
+	}
+
+	for i := range MaxNoteAttachments {
+		if err := user.SetNoteAttachment(fmt.Sprintf("evt%d", i), &NoteAttachment{FileID: "f", Kind: AttachmentPhoto}); err != nil {
+			t.Fatalf("attachment %d: %v", i, err)
+		}
+	}
+	if err := user.SetNoteAttachment("one-more", &NoteAttachment{FileID: "f"}); !errors.Is(err, ErrTooManyAttachments) {
+		t.Errorf("attachment over the cap: err = %v", err)
+	}
+	// Replacing an existing attachment doesn't count against the cap
+	if err := user.SetNoteAttachment("evt0", &NoteAttachment{FileID: "new", Kind: AttachmentDocument}); err != nil {
+		t.Errorf("replacing: %v", err)
+	}
+	if got := user.GetNoteAttachment("evt0"); got.FileID != "new" {
+		t.Errorf("GetNoteAttachment() = %+v", got)
+	}
+
+	user.SetEventStatus("evt1", EventStatusRegistered)
+	user.ArchiveEvent("evt1", "Wolf Creek", "Apr 4")
+	if user.GetNoteAttachment("evt1") != nil {
+		t.Error("archiving should drop the attachment")
+	}
+	if !user.RemoveNoteAttachment("evt0") || user.RemoveNoteAttachment("evt0") {
+		t.Error("RemoveNoteAttachment() should report whether there was one")
+	}
+}
+
+func TestPendingNote(t *testing.T) {
+	user := NewPreferences().GetUser("111")
+	now := time.Now()
+
+	if user.TakePendingNote(now) != "" {
+		t.Error("no note should be pending")
+	}
+
+	user.SetPendingNote("evt1", now)
+	if got := user.TakePendingNote(now.Add(time.Minute)); got != "evt1" {
+		t.Errorf("TakePendingNote() = %q, want evt1", got)
+	}
+	if user.TakePendingNote(now.Add(time.Minute)) != "" {
+		t.Error("a pending note should only be taken once")
+	}
+
+	user.SetPendingNote("evt1", now)
+	if user.TakePendingNote(now.Add(PendingNoteWindow+time.Minute)) != "" {
+		t.Error("an expired pending note shouldn't be returned")
+	}
+}
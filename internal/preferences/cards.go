@@ -0,0 +1,75 @@
+package preferences
+
+import "time"
+
+// EventCardMaxAge is how long after it's sent an event card is kept up to date
+const EventCardMaxAge = 14 * 24 * time.Hour
+
+// MaxEventCards caps the event cards tracked per user; the oldest are dropped first
+const MaxEventCards = 50
+
+// EventCard is an event message the bot sent, tracked by message_id so it can be
+// edited when the event or the user's status changes
+type EventCard struct {
+	EventID   string `json:"event_id"`
+	MessageID int    `json:"message_id"`
+	SentAt    int64  `json:"sent_at"`        // Unix time the card was sent
+	Hash      string `json:"hash,omitempty"` // Of the text last shown, so unchanged cards aren't edited
+}
+
+// TrackEventCard records that message messageID shows eventID with text hashing to
+// hash. A message already tracked keeps its sent time.
+func (u *UserPreferences) TrackEventCard(eventID string, messageID int, hash string, now time.Time) {
+	for _, card := range u.EventCards {
+		if card.MessageID == messageID {
+			card.EventID = eventID
+			card.Hash = hash
+			return
+		}
+	}
+	u.EventCards = append(u.EventCards, &EventCard{EventID: eventID, MessageID: messageID, SentAt: now.Unix(), Hash: hash})
+	if len(u.EventCards) > MaxEventCards {
+		u.EventCards = u.EventCards[len(u.EventCards)-MaxEventCards:]
+	}
+}
+
+// LastEventMessage returns the message ID of the most recent card tracked for eventID,
+// or 0 if none is
+func (u *UserPreferences) LastEventMessage(eventID string) int {
+	for i := len(u.EventCards) - 1; i >= 0; i-- {
+		if u.EventCards[i].EventID == eventID {
+			return u.EventCards[i].MessageID
+		}
+	}
+	return 0
+}
+
+// ForgetEventCard stops tracking a message, e.g. once it's deleted. Returns false if
+// it wasn't tracked.
+func (u *UserPreferences) ForgetEventCard(messageID int) bool {
+	for i, card := range u.EventCards {
+		if card.MessageID == messageID {
+			u.EventCards = append(u.EventCards[:i], u.EventCards[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// PruneEventCards drops cards sent more than EventCardMaxAge before now. Returns
+// whether any were dropped.
+func (u *UserPreferences) PruneEventCards(now time.Time) bool {
+	cutoff := now.Add(-EventCardMaxAge).Unix()
+	kept := u.EventCards[:0]
+	for _, card := range u.EventCards {
+		if card.SentAt >= cutoff {
+			kept = append(kept, card)
+		}
+	}
+	pruned := len(kept) < len(u.EventCards)
+	u.EventCards = kept
+	if len(u.EventCards) == 0 {
+		u.EventCards = nil
+	}
+	return pruned
+}
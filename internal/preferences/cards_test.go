@@ -0,0 +1,60 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackEventCard(t *testing.T) {
+	user := NewPreferences().GetUser("123")
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+
+	user.TrackEventCard("e1", 10, "h1", now)
+	user.TrackEventCard("e1", 10, "h2", now.Add(time.Hour)) // Refreshed in place
+	if len(user.EventCards) != 1 || user.EventCards[0].Hash != "h2" || user.EventCards[0].SentAt != now.Unix() {
+		t.Fatalf("EventCards = %+v, want one card with the new hash and first sent time", user.EventCards[0])
+	}
+
+	for i := 0; i < MaxEventCards; i++ {
+		user.TrackEventCard("e2", 100+i, "", now)
+	}
+	if len(user.EventCards) != MaxEventCards || user.EventCards[0].MessageID != 100 {
+		t.Errorf("tracking past the cap should drop the oldest card; first is %d of %d", user.EventCards[0].MessageID, len(user.EventCards))
+	}
+
+	if !user.ForgetEventCard(100) || user.ForgetEventCard(100) {
+		t.Error("ForgetEventCard() should report whether the card was tracked")
+	}
+	if len(user.EventCards) != MaxEventCards-1 || user.EventCards[0].MessageID != 101 {
+		t.Error("ForgetEventCard() didn't remove the card")
+	}
+}
+
+func TestPruneEventCards(t *testing.T) {
+	user := NewPreferences().GetUser("123")
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	user.TrackEventCard("old", 1, "", now.Add(-EventCardMaxAge-time.Hour))
+	user.TrackEventCard("recent", 2, "", now.Add(-time.Hour))
+
+	if !user.PruneEventCards(now) || len(user.EventCards) != 1 || user.EventCards[0].EventID != "recent" {
+		t.Errorf("PruneEventCards() left %+v", user.EventCards)
+	}
+	if user.PruneEventCards(now) {
+		t.Error("PruneEventCards() with nothing old should report no change")
+	}
+}
+
+func TestLastEventMessage(t *testing.T) {
+	user := NewPreferences().GetUser("123")
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	user.TrackEventCard("e1", 10, "", now)
+	user.TrackEventCard("e2", 11, "", now)
+	user.TrackEventCard("e1", 12, "", now)
+
+	if got := user.LastEventMessage("e1"); got != 12 {
+		t.Errorf("LastEventMessage(e1) = %d, want the latest card, 12", got)
+	}
+	if got := user.LastEventMessage("e3"); got != 0 {
+		t.Errorf("LastEventMessage(e3) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,127 @@
+package preferences
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/geo"
+)
+
+const (
+	// MaxCitySubscriptions is how many cities one user can follow
+	MaxCitySubscriptions = 10
+
+	// MaxCityRadiusMiles caps the radius of a city subscription
+	MaxCityRadiusMiles = 200
+)
+
+// CitySubscription follows events in one city, or within a radius of it when the city
+// can be located
+type CitySubscription struct {
+	City        string `json:"city"`                   // As the user typed it, e.g. "Las Vegas"
+	State       string `json:"state,omitempty"`        // Optional 2-letter code narrowing the match
+	RadiusMiles int    `json:"radius_miles,omitempty"` // 0 = match the city name only
+}
+
+// String formats a city subscription for display, e.g. "Las Vegas, NV (within 25 mi)"
+func (c CitySubscription) String() string {
+	s := c.City
+	if c.State != "" {
+		s += ", " + c.State
+	}
+	if c.RadiusMiles > 0 {
+		s += fmt.Sprintf(" (within %d mi)", c.RadiusMiles)
+	}
+	return s
+}
+
+// Matches reports whether an event is in the city or, with a radius, near it. Radius
+// matching needs both cities in the geo table; events that can't be located still
+// match by name.
+func (c CitySubscription) Matches(evt *event.Event) bool {
+	sameState := c.State == "" || strings.EqualFold(evt.State, c.State)
+	if sameState && geo.NormalizeCity(evt.City) == geo.NormalizeCity(c.City) {
+		return true
+	}
+	if c.RadiusMiles == 0 || evt.City == "" {
+		return false
+	}
+
+	center, ok := geo.Lookup(c.City, c.State)
+	if !ok {
+		return false
+	}
+	at, ok := geo.Lookup(evt.City, evt.State)
+	if !ok {
+		return false
+	}
+	return geo.DistanceMiles(center, at) <= float64(c.RadiusMiles)
+}
+
+// AddCity adds a city subscription, replacing any existing one for the same city and
+// state. Returns false if the user already follows MaxCitySubscriptions other cities.
+func (p Preferences) AddCity(chatID string, sub CitySubscription) bool {
+	user := p.GetUser(chatID)
+	for i, existing := range user.Cities {
+		if sameCity(existing, sub) {
+			user.Cities[i] = sub
+			return true
+		}
+	}
+	if len(user.Cities) >= MaxCitySubscriptions {
+		return false
+	}
+	user.Cities = append(user.Cities, sub)
+	return true
+}
+
+// RemoveCity removes city subscriptions with a matching name (and state, when given).
+// Returns how many were removed.
+func (p Preferences) RemoveCity(chatID, city, state string) int {
+	user := p.GetUser(chatID)
+	target := CitySubscription{City: city, State: state}
+	kept := user.Cities[:0]
+	removed := 0
+	for _, existing := range user.Cities {
+		if geo.NormalizeCity(existing.City) == geo.NormalizeCity(city) && (state == "" || sameCity(existing, target)) {
+			removed++
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	user.Cities = kept
+	if len(user.Cities) == 0 {
+		user.Cities = nil
+	}
+	return removed
+}
+
+// MatchesSubscriptions reports whether an event is in one of the user's states or
+// cities, at a course they follow, in a state they're traveling to during the trip, or
+// a national/major event they subscribed to with /subscribe majors
+func (u *UserPreferences) MatchesSubscriptions(evt *event.Event) bool {
+	if u.FollowsCourse(evt) || (u.Majors && evt.IsNational()) {
+		return true
+	}
+	for _, t := range u.Travel {
+		if t.Matches(evt) {
+			return true
+		}
+	}
+	for _, state := range u.States {
+		if state == "ALL" || strings.EqualFold(evt.State, state) {
+			return true
+		}
+	}
+	for _, c := range u.Cities {
+		if c.Matches(evt) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameCity(a, b CitySubscription) bool {
+	return geo.NormalizeCity(a.City) == geo.NormalizeCity(b.City) && strings.EqualFold(a.State, b.State)
+}
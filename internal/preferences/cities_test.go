@@ -0,0 +1,97 @@
+package preferences
+
+import (
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestCitySubscriptionMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  CitySubscription
+		evt  *event.Event
+		want bool
+	}{
+		{"same city", CitySubscription{City: "Las Vegas"}, &event.Event{City: "las  vegas", State: "NV"}, true},
+		{"other city", CitySubscription{City: "Las Vegas"}, &event.Event{City: "Reno", State: "NV"}, false},
+		{"state narrows", CitySubscription{City: "Glendale", State: "AZ"}, &event.Event{City: "Glendale", State: "CA"}, false},
+		{"within radius", CitySubscription{City: "Las Vegas", State: "NV", RadiusMiles: 25}, &event.Event{City: "Henderson", State: "NV"}, true},
+		{"outside radius", CitySubscription{City: "Las Vegas", State: "NV", RadiusMiles: 25}, &event.Event{City: "Mesquite", State: "NV"}, false},
+		{"radius crosses states", CitySubscription{City: "Las Vegas", RadiusMiles: 300}, &event.Event{City: "Phoenix", State: "AZ"}, true},
+		{"unknown event city", CitySubscription{City: "Las Vegas", RadiusMiles: 50}, &event.Event{City: "Smallville", State: "NV"}, false},
+		{"no event city", CitySubscription{City: "Las Vegas", RadiusMiles: 50}, &event.Event{State: "NV"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.Matches(tt.evt); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRemoveCity(t *testing.T) {
+	prefs := NewPreferences()
+
+	if !prefs.AddCity("123", CitySubscription{City: "Las Vegas", State: "NV"}) {
+		t.Fatal("AddCity() = false")
+	}
+	// Same city and state replaces the existing subscription
+	prefs.AddCity("123", CitySubscription{City: "las vegas", State: "NV", RadiusMiles: 20})
+	if cities := prefs.GetUser("123").Cities; len(cities) != 1 || cities[0].RadiusMiles != 20 {
+		t.Errorf("cities = %+v, want one with a 20 mi radius", cities)
+	}
+
+	for i := 1; i < MaxCitySubscriptions; i++ {
+		prefs.AddCity("123", CitySubscription{City: string(rune('a' + i))})
+	}
+	if prefs.AddCity("123", CitySubscription{City: "Reno"}) {
+		t.Error("AddCity() should refuse past MaxCitySubscriptions")
+	}
+
+	if n := prefs.RemoveCity("123", "Las Vegas", ""); n != 1 {
+		t.Errorf("RemoveCity() = %d, want 1", n)
+	}
+	if n := prefs.RemoveCity("123", "Las Vegas", ""); n != 0 {
+		t.Errorf("RemoveCity() again = %d, want 0", n)
+	}
+}
+
+func TestMatchesSubscriptions(t *testing.T) {
+	user := &UserPreferences{
+		States: []string{"AZ"},
+		Cities: []CitySubscription{{City: "Las Vegas"}},
+	}
+	if !user.MatchesSubscriptions(&event.Event{City: "Tucson", State: "AZ"}) {
+		t.Error("event in a subscribed state should match")
+	}
+	if !user.MatchesSubscriptions(&event.Event{City: "Las Vegas", State: "NV"}) {
+		t.Error("event in a subscribed city should match")
+	}
+	if user.MatchesSubscriptions(&event.Event{City: "Reno", State: "NV"}) {
+		t.Error("event elsewhere should not match")
+	}
+
+	all := &UserPreferences{States: []string{"ALL"}}
+	if !all.MatchesSubscriptions(&event.Event{State: "TX"}) {
+		t.Error("ALL should match every state")
+	}
+
+	user.Majors = true
+	if !user.MatchesSubscriptions(&event.Event{State: "TX", Scope: event.ScopeNational}) {
+		t.Error("national event should match a majors subscription in any state")
+	}
+	if user.MatchesSubscriptions(&event.Event{State: "TX"}) {
+		t.Error("majors subscription should not match state events")
+	}
+}
+
+func TestGetAllUsersIncludesCityOnly(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.AddCity("123", CitySubscription{City: "Las Vegas"})
+	prefs.GetUser("123").Active = true
+	if users := prefs.GetAllUsers(); len(users) != 1 {
+		t.Errorf("GetAllUsers() = %v, want the city-only user", users)
+	}
+}
@@ -0,0 +1,83 @@
+package preferences
+
+// RecordCohort notes in this week's stats whether the user had a feature flag that's
+// in a staged rollout, so the cohorts' engagement can be compared before the flag goes
+// to everyone. Returns true if anything changed.
+func (u *UserPreferences) RecordCohort(flag string, enabled bool) bool {
+	if !u.EnableStats {
+		return false
+	}
+	if u.WeeklyStats == nil {
+		u.WeeklyStats = NewWeeklyStats()
+	}
+	if had, ok := u.WeeklyStats.Cohorts[flag]; ok && had == enabled {
+		return false
+	}
+	if u.WeeklyStats.Cohorts == nil {
+		u.WeeklyStats.Cohorts = make(map[string]bool)
+	}
+	u.WeeklyStats.Cohorts[flag] = enabled
+	return true
+}
+
+// CohortStats is one side of a staged rollout's engagement: the weeks users spent with
+// the feature, or without it
+type CohortStats struct {
+	Enabled          bool
+	Users            int // Users with at least one week in this cohort
+	Weeks            int // User-weeks in this cohort
+	EventsViewed     int
+	EventsMarked     int
+	EventsRegistered int
+}
+
+// PerWeek returns n averaged over the cohort's user-weeks
+func (c CohortStats) PerWeek(n int) float64 {
+	if c.Weeks == 0 {
+		return 0
+	}
+	return float64(n) / float64(c.Weeks)
+}
+
+// CompareCohorts totals engagement for the weeks that recorded flag's cohort, with the
+// feature first. A user whose cohort changed as the rollout grew counts in both.
+func (p Preferences) CompareCohorts(flag string) []CohortStats {
+	cohorts := []CohortStats{{Enabled: true}, {Enabled: false}}
+	for _, user := range p {
+		var in [2]bool
+		weeks := make([]*WeeklyStats, 0, len(user.StatsHistory)+1)
+		weeks = append(weeks, user.WeeklyStats)
+		for _, stats := range user.StatsHistory {
+			weeks = append(weeks, stats)
+		}
+
+		for _, stats := range weeks {
+			if stats == nil {
+				continue
+			}
+			enabled, ok := stats.Cohorts[flag]
+			if !ok {
+				continue
+			}
+			i := 1
+			if enabled {
+				i = 0
+			}
+			in[i] = true
+			c := &cohorts[i]
+			c.Weeks++
+			c.EventsViewed += stats.EventsViewed
+			c.EventsRegistered += stats.EventsRegistered
+			for _, count := range stats.EventsMarked {
+				c.EventsMarked += count
+			}
+		}
+
+		for i := range in {
+			if in[i] {
+				cohorts[i].Users++
+			}
+		}
+	}
+	return cohorts
+}
@@ -0,0 +1,71 @@
+package preferences
+
+import (
+	"testing"
+)
+
+func TestRecordCohort(t *testing.T) {
+	user := NewPreferences().GetUser("123")
+	user.EnableStats = false
+	if user.RecordCohort("enable_web_api", true) {
+		t.Error("a user who turned stats off shouldn't be recorded")
+	}
+
+	user.EnableStats = true
+	user.WeeklyStats = nil
+	if !user.RecordCohort("enable_web_api", true) || !user.WeeklyStats.Cohorts["enable_web_api"] {
+		t.Fatal("cohort not recorded")
+	}
+	if user.RecordCohort("enable_web_api", true) {
+		t.Error("recording the same cohort again shouldn't change anything")
+	}
+	if !user.RecordCohort("enable_web_api", false) || user.WeeklyStats.Cohorts["enable_web_api"] {
+		t.Error("a changed cohort should be recorded")
+	}
+	if user.WeeklyStats.isEmpty() {
+		t.Error("a week with a cohort recorded shouldn't be compacted away")
+	}
+}
+
+func TestCompareCohorts(t *testing.T) {
+	week := func(viewed, marked, registered int, cohorts map[string]bool) *WeeklyStats {
+		return &WeeklyStats{
+			EventsViewed:     viewed,
+			EventsMarked:     map[string]int{"interested": marked},
+			EventsRegistered: registered,
+			Cohorts:          cohorts,
+		}
+	}
+	on := map[string]bool{"enable_web_api": true}
+	off := map[string]bool{"enable_web_api": false}
+
+	prefs := Preferences{
+		"early": {
+			WeeklyStats:  week(4, 2, 1, on),
+			StatsHistory: map[string]*WeeklyStats{"2026-W10": week(6, 0, 1, on), "2026-W09": week(1, 1, 0, off)},
+		},
+		"control": {
+			WeeklyStats:  week(2, 0, 0, off),
+			StatsHistory: map[string]*WeeklyStats{"2026-W10": week(3, 1, 0, off)},
+		},
+		"before": { // Weeks before the rollout was staged don't count
+			WeeklyStats:  week(9, 9, 9, nil),
+			StatsHistory: map[string]*WeeklyStats{"2026-W10": nil},
+		},
+	}
+
+	got := prefs.CompareCohorts("enable_web_api")
+	want := []CohortStats{
+		{Enabled: true, Users: 1, Weeks: 2, EventsViewed: 10, EventsMarked: 2, EventsRegistered: 2},
+		{Enabled: false, Users: 2, Weeks: 3, EventsViewed: 6, EventsMarked: 2, EventsRegistered: 0},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("CompareCohorts() = %+v, want %+v", got, want)
+	}
+	if rate := got[0].PerWeek(got[0].EventsViewed); rate != 5 {
+		t.Errorf("PerWeek() = %v, want 5", rate)
+	}
+	if rate := (CohortStats{}).PerWeek(3); rate != 0 {
+		t.Errorf("PerWeek() with no weeks = %v, want 0", rate)
+	}
+}
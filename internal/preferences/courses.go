@@ -0,0 +1,70 @@
+package preferences
+
+import (
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// MaxFollowedCourses is how many courses one user can follow
+const MaxFollowedCourses = 20
+
+// MatchesCourse reports whether an event is at a course, comparing titles the way
+// duplicate detection does ("The Chimera Golf Club" matches "chimera"). The course may
+// also appear as whole words within a longer title, as in "Spring Classic at Chimera".
+func MatchesCourse(title, course string) bool {
+	course = event.NormalizeCourseTitle(course)
+	if course == "" {
+		return false
+	}
+	title = event.NormalizeCourseTitle(title)
+	return title == course || strings.Contains(" "+title+" ", " "+course+" ")
+}
+
+// FollowCourse adds a course to the user's followed courses. Returns false if it's
+// already followed or the user follows MaxFollowedCourses courses.
+func (p Preferences) FollowCourse(chatID, course string) bool {
+	user := p.GetUser(chatID)
+	if user.FollowsCourseName(course) || len(user.FollowedCourses) >= MaxFollowedCourses {
+		return false
+	}
+	user.FollowedCourses = append(user.FollowedCourses, strings.TrimSpace(course))
+	return true
+}
+
+// UnfollowCourse removes a followed course by name. Returns false if it wasn't followed.
+func (p Preferences) UnfollowCourse(chatID, course string) bool {
+	user := p.GetUser(chatID)
+	normalized := event.NormalizeCourseTitle(course)
+	for i, c := range user.FollowedCourses {
+		if event.NormalizeCourseTitle(c) == normalized {
+			user.FollowedCourses = append(user.FollowedCourses[:i], user.FollowedCourses[i+1:]...)
+			if len(user.FollowedCourses) == 0 {
+				user.FollowedCourses = nil
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// FollowsCourseName reports whether the user already follows a course with this name
+func (u *UserPreferences) FollowsCourseName(course string) bool {
+	normalized := event.NormalizeCourseTitle(course)
+	for _, c := range u.FollowedCourses {
+		if event.NormalizeCourseTitle(c) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// FollowsCourse reports whether an event is at one of the user's followed courses
+func (u *UserPreferences) FollowsCourse(evt *event.Event) bool {
+	for _, c := range u.FollowedCourses {
+		if MatchesCourse(evt.Title, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package preferences
+
+import (
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestMatchesCourse(t *testing.T) {
+	tests := []struct {
+		title, course string
+		want          bool
+	}{
+		{"Chimera Golf Club", "Chimera Golf Club", true},
+		{"The Chimera Golf Club", "chimera", true},
+		{"Spring Classic at Chimera Golf Club", "Chimera Golf Club", true},
+		{"Chimeras Golf Club", "Chimera", false},
+		{"Wolf Creek", "Chimera", false},
+		{"Wolf Creek", "", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesCourse(tt.title, tt.course); got != tt.want {
+			t.Errorf("MatchesCourse(%q, %q) = %v, want %v", tt.title, tt.course, got, tt.want)
+		}
+	}
+}
+
+func TestFollowCourse(t *testing.T) {
+	prefs := NewPreferences()
+
+	if !prefs.FollowCourse("123", "Chimera Golf Club") {
+		t.Fatal("FollowCourse() = false")
+	}
+	if prefs.FollowCourse("123", "the chimera golf club") {
+		t.Error("following the same course twice should fail")
+	}
+
+	user := prefs.GetUser("123")
+	if !user.FollowsCourse(&event.Event{Title: "Chimera Golf Club", State: "NV"}) {
+		t.Error("event at a followed course should match")
+	}
+	if !user.MatchesSubscriptions(&event.Event{Title: "Chimera Golf Club", State: "TX"}) {
+		t.Error("followed course should match regardless of state")
+	}
+
+	if !prefs.UnfollowCourse("123", "CHIMERA") {
+		t.Error("UnfollowCourse() = false")
+	}
+	if user.FollowedCourses != nil {
+		t.Errorf("FollowedCourses = %v, want nil", user.FollowedCourses)
+	}
+
+	for i := 0; i < MaxFollowedCourses; i++ {
+		prefs.FollowCourse("123", string(rune('a'+i))+" course")
+	}
+	if prefs.FollowCourse("123", "One Too Many") {
+		t.Error("FollowCourse() should refuse past MaxFollowedCourses")
+	}
+}
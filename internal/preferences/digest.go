@@ -0,0 +1,83 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// QueueDigest adds events to the pending digest queue and marks them seen, so later
+// checks don't queue them again. Events already queued are skipped. Returns how
+// many were added.
+func (u *UserPreferences) QueueDigest(events []*event.Event, now time.Time) int {
+	queued := make(map[string]bool, len(u.PendingEvents))
+	for _, evt := range u.PendingEvents {
+		queued[evt.ID] = true
+	}
+	if u.SeenEventIDs == nil {
+		u.SeenEventIDs = make(map[string]int64)
+	}
+
+	added := 0
+	for _, evt := range events {
+		u.SeenEventIDs[evt.ID] = now.Unix()
+		if queued[evt.ID] {
+			continue
+		}
+		queued[evt.ID] = true
+		u.AddPendingEvent(evt)
+		added++
+	}
+	return added
+}
+
+// DigestLocation returns the time zone the digest day and hour are in: DigestTimezone,
+// or UTC if it's unset or unknown
+func (u *UserPreferences) DigestLocation() *time.Location {
+	if u.DigestTimezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.DigestTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// SetDigestTime sets when digests are sent: the hour (0-23) and, for weekly digests,
+// the day, in the IANA time zone tz (e.g. "America/Los_Angeles"). An empty tz keeps
+// the current time zone.
+func (u *UserPreferences) SetDigestTime(day time.Weekday, hour int, tz string) error {
+	if hour < 0 || hour > 23 || day < time.Sunday || day > time.Saturday {
+		return errors.New("invalid digest time")
+	}
+	if tz == "" {
+		tz = u.DigestLocation().String()
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q", tz)
+	}
+	u.DigestDayOfWeek = int(day)
+	u.DigestHour = hour
+	u.DigestTimezone = loc.String()
+	return nil
+}
+
+// DigestDue reports whether the user's daily or weekly digest is due in the hour
+// containing now, by their digest day and hour in their time zone
+func (u *UserPreferences) DigestDue(now time.Time) bool {
+	local := now.In(u.DigestLocation())
+	if local.Hour() != u.DigestHour {
+		return false
+	}
+	switch u.DigestFrequency {
+	case DigestFrequencyDaily:
+		return true
+	case DigestFrequencyWeekly:
+		return int(local.Weekday()) == u.DigestDayOfWeek
+	}
+	return false
+}
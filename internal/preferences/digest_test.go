@@ -0,0 +1,91 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestQueueDigest(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+
+	if got := user.QueueDigest([]*event.Event{{ID: "a"}, {ID: "b"}}, now); got != 2 {
+		t.Fatalf("QueueDigest() = %d, want 2", got)
+	}
+	// A re-run of the same check doesn't queue events twice
+	if got := user.QueueDigest([]*event.Event{{ID: "b"}, {ID: "c"}}, now); got != 1 {
+		t.Fatalf("QueueDigest() again = %d, want 1", got)
+	}
+	if len(user.PendingEvents) != 3 {
+		t.Errorf("PendingEvents = %d events, want 3", len(user.PendingEvents))
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if user.SeenEventIDs[id] != now.Unix() {
+			t.Errorf("event %s not marked seen", id)
+		}
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	user.DigestFrequency = DigestFrequencyWeekly
+	if err := user.SetDigestTime(time.Saturday, 7, "America/Los_Angeles"); err != nil {
+		t.Fatalf("SetDigestTime() error = %v", err)
+	}
+
+	// Saturday 7 AM PDT is 14:00 UTC
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2026, time.October, 17, 14, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, time.October, 17, 14, 59, 0, 0, time.UTC), true},
+		{time.Date(2026, time.October, 17, 7, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, time.October, 18, 14, 0, 0, 0, time.UTC), false},
+		// After the switch to PST, 7 AM is 15:00 UTC
+		{time.Date(2026, time.November, 7, 15, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := user.DigestDue(tt.now); got != tt.want {
+			t.Errorf("weekly DigestDue(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+
+	user.DigestFrequency = DigestFrequencyDaily
+	if !user.DigestDue(time.Date(2026, time.October, 18, 14, 30, 0, 0, time.UTC)) {
+		t.Error("daily digest should be due every day at its hour")
+	}
+	user.DigestFrequency = DigestFrequencyImmediate
+	if user.DigestDue(time.Date(2026, time.October, 18, 14, 30, 0, 0, time.UTC)) {
+		t.Error("immediate users never have a digest due")
+	}
+}
+
+func TestSetDigestTime(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	user.DigestFrequency = DigestFrequencyWeekly
+
+	if err := user.SetDigestTime(time.Monday, 9, "Mars/Olympus_Mons"); err == nil {
+		t.Error("SetDigestTime() with an unknown time zone should fail")
+	}
+	if err := user.SetDigestTime(time.Monday, 24, ""); err == nil {
+		t.Error("SetDigestTime() with hour 24 should fail")
+	}
+
+	// Midnight on Sunday survives the defaults applied on load
+	if err := user.SetDigestTime(time.Sunday, 0, ""); err != nil {
+		t.Fatalf("SetDigestTime() error = %v", err)
+	}
+	if user.DigestTimezone != "UTC" {
+		t.Errorf("DigestTimezone = %q, want UTC", user.DigestTimezone)
+	}
+	user = prefs.GetUser("123")
+	if user.DigestHour != 0 || user.DigestDayOfWeek != 0 {
+		t.Errorf("after GetUser(): hour %d day %d, want midnight Sunday", user.DigestHour, user.DigestDayOfWeek)
+	}
+}
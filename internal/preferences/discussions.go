@@ -0,0 +1,117 @@
+package preferences
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// MaxCommentLength caps one discussion message, in characters
+	MaxCommentLength = 280
+
+	// MaxCommentsPerEvent is how many of a user's messages are kept per event; older
+	// ones are dropped
+	MaxCommentsPerEvent = 20
+
+	// CommentRetention is how long discussion messages are kept by Compact
+	CommentRetention = 90 * 24 * time.Hour
+)
+
+// Comment is one message a user left in an event's discussion
+type Comment struct {
+	At   int64  `json:"at"` // Unix time
+	Text string `json:"text"`
+}
+
+// ThreadComment is a comment in a discussion with its author
+type ThreadComment struct {
+	ChatID string
+	Comment
+}
+
+// AddComment adds a message to the user's side of an event's discussion
+func (u *UserPreferences) AddComment(eventID, text string, now time.Time) {
+	if u.Comments == nil {
+		u.Comments = make(map[string][]Comment)
+	}
+	comments := append(u.Comments[eventID], Comment{At: now.Unix(), Text: text})
+	if len(comments) > MaxCommentsPerEvent {
+		comments = comments[len(comments)-MaxCommentsPerEvent:]
+	}
+	u.Comments[eventID] = comments
+}
+
+// Thread returns an event's discussion as chatID sees it: their own messages and their
+// friends', oldest first. Each user's friends form their own group, so a thread only
+// ever shows messages from people the viewer added with /invite.
+func (p Preferences) Thread(chatID, eventID string) []ThreadComment {
+	return p.friendComments(chatID, func(u *UserPreferences) []Comment { return u.Comments[eventID] })
+}
+
+// friendComments merges the comments comments returns for chatID and each of their
+// friends, oldest first
+func (p Preferences) friendComments(chatID string, comments func(*UserPreferences) []Comment) []ThreadComment {
+	var thread []ThreadComment
+	add := func(id string) {
+		if user, ok := p[id]; ok {
+			for _, c := range comments(user) {
+				thread = append(thread, ThreadComment{ChatID: id, Comment: c})
+			}
+		}
+	}
+
+	add(chatID)
+	if user, ok := p[chatID]; ok {
+		for _, friendID := range user.FriendChatIDs {
+			add(friendID)
+		}
+	}
+
+	sort.SliceStable(thread, func(i, j int) bool { return thread[i].At < thread[j].At })
+	return thread
+}
+
+// DiscussionParticipants returns the friends a new message in chatID's discussion of
+// an event is relayed to: those who have joined the discussion or are tracking the
+// event (any status but skip)
+func (p Preferences) DiscussionParticipants(chatID, eventID string) []string {
+	user, ok := p[chatID]
+	if !ok {
+		return nil
+	}
+
+	var participants []string
+	for _, friendID := range user.FriendChatIDs {
+		friend, ok := p[friendID]
+		if !ok {
+			continue
+		}
+		status := friend.EventStatuses[eventID]
+		if len(friend.Comments[eventID]) > 0 || (status != "" && status != EventStatusSkip) {
+			participants = append(participants, friendID)
+		}
+	}
+	return participants
+}
+
+// pruneComments drops discussion messages older than CommentRetention and returns how
+// many were removed
+func (u *UserPreferences) pruneComments(now time.Time) int {
+	cutoff := now.Add(-CommentRetention).Unix()
+	pruned := 0
+	for eventID, comments := range u.Comments {
+		kept := comments[:0]
+		for _, c := range comments {
+			if c.At >= cutoff {
+				kept = append(kept, c)
+			}
+		}
+		pruned += len(comments) - len(kept)
+		if len(kept) == 0 {
+			delete(u.Comments, eventID)
+		} else {
+			u.Comments[eventID] = kept
+		}
+	}
+	return pruned
+}
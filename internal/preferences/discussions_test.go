@@ -0,0 +1,85 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThread(t *testing.T) {
+	prefs := NewPreferences()
+	a, b, c := prefs.GetUser("111"), prefs.GetUser("222"), prefs.GetUser("333")
+	a.AddFriend("222")
+	b.AddFriend("111")
+
+	now := time.Now()
+	b.AddComment("evt1", "Anyone want to carpool?", now)
+	a.AddComment("evt1", "Count me in", now.Add(time.Minute))
+	c.AddComment("evt1", "Not a friend", now.Add(2*time.Minute))
+	a.AddComment("evt2", "Other event", now)
+
+	thread := prefs.Thread("111", "evt1")
+	if len(thread) != 2 {
+		t.Fatalf("Thread() returned %d comments, want 2: %+v", len(thread), thread)
+	}
+	if thread[0].ChatID != "222" || thread[1].ChatID != "111" || thread[1].Text != "Count me in" {
+		t.Errorf("Thread() = %+v, want the friend's message first", thread)
+	}
+	if got := prefs.Thread("333", "evt1"); len(got) != 1 {
+		t.Errorf("a user without friends should only see their own messages, got %+v", got)
+	}
+}
+
+func TestAddCommentCap(t *testing.T) {
+	user := NewPreferences().GetUser("111")
+	now := time.Now()
+	for i := range MaxCommentsPerEvent + 5 {
+		user.AddComment("evt1", "msg", now.Add(time.Duration(i)*time.Second))
+	}
+	comments := user.Comments["evt1"]
+	if len(comments) != MaxCommentsPerEvent {
+		t.Fatalf("kept %d comments, want %d", len(comments), MaxCommentsPerEvent)
+	}
+	if comments[0].At != now.Add(5*time.Second).Unix() {
+		t.Error("the oldest comments should be dropped first")
+	}
+}
+
+func TestDiscussionParticipants(t *testing.T) {
+	prefs := NewPreferences()
+	a := prefs.GetUser("111")
+	for _, id := range []string{"222", "333", "444", "555"} {
+		a.AddFriend(id)
+	}
+	prefs.GetUser("222").SetEventStatus("evt1", EventStatusInterested)
+	prefs.GetUser("333").SetEventStatus("evt1", EventStatusSkip)
+	prefs.GetUser("444").AddComment("evt1", "I'm thinking about it", time.Now())
+	// 555 is a friend who never used the bot
+
+	got := prefs.DiscussionParticipants("111", "evt1")
+	if len(got) != 2 || got[0] != "222" || got[1] != "444" {
+		t.Errorf("DiscussionParticipants() = %v, want [222 444]", got)
+	}
+	if got := prefs.DiscussionParticipants("999", "evt1"); got != nil {
+		t.Errorf("unknown user should have no participants, got %v", got)
+	}
+}
+
+func TestCompactPrunesComments(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("111")
+	now := time.Now()
+	user.AddComment("old", "Last season", now.Add(-CommentRetention-time.Hour))
+	user.AddComment("evt1", "Stale", now.Add(-CommentRetention-time.Hour))
+	user.AddComment("evt1", "Fresh", now)
+
+	result := prefs.Compact(CompactOptions{SeenEventDays: DefaultSeenEventDays})
+	if result.CommentsPruned != 2 {
+		t.Errorf("CommentsPruned = %d, want 2", result.CommentsPruned)
+	}
+	if _, ok := user.Comments["old"]; ok {
+		t.Error("an event with no remaining comments should be removed")
+	}
+	if len(user.Comments["evt1"]) != 1 || user.Comments["evt1"][0].Text != "Fresh" {
+		t.Errorf("Comments[evt1] = %+v, want only the fresh one", user.Comments["evt1"])
+	}
+}
@@ -5,4 +5,7 @@
 //
 // Preferences are stored in a private GitHub Gist as JSON, with each user's chat ID as the key
 // and their subscribed states as the value.
+//
+// A Preferences map isn't safe for concurrent use. Code that shares one between
+// goroutines wraps it in Shared, which locks around lookups and changes.
 package preferences
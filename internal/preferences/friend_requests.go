@@ -0,0 +1,139 @@
+package preferences
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MaxFriendRequests caps how many requests can wait for a user's approval; the oldest
+// is dropped to make room
+const MaxFriendRequests = 20
+
+// MaxBlockedChats caps how many chats a user can block
+const MaxBlockedChats = 200
+
+var (
+	// ErrAlreadyFriends is returned by RequestFriend when the two are already friends
+	ErrAlreadyFriends = errors.New("already friends")
+
+	// ErrRequestPending is returned by RequestFriend when the same request is waiting
+	ErrRequestPending = errors.New("friend request already pending")
+
+	// ErrBlocked is returned by RequestFriend when the requestee blocked the requester.
+	// Callers shouldn't tell the requester.
+	ErrBlocked = errors.New("requester is blocked")
+
+	// ErrAlreadyBlocked is returned by Block when the chat is already blocked
+	ErrAlreadyBlocked = errors.New("already blocked")
+
+	// ErrTooManyBlocked is returned by Block when the user has MaxBlockedChats blocked
+	ErrTooManyBlocked = errors.New("too many blocked chats")
+)
+
+// RequestFriend asks to to approve from as a friend
+func (p Preferences) RequestFriend(from, to string, now time.Time) error {
+	requester, requestee := p.GetUser(from), p.GetUser(to)
+	switch {
+	case requestee.IsBlocked(from):
+		return ErrBlocked
+	case requester.IsFriend(to) && requestee.IsFriend(from):
+		return ErrAlreadyFriends
+	}
+	if _, ok := requestee.FriendRequests[from]; ok {
+		return ErrRequestPending
+	}
+
+	if requestee.FriendRequests == nil {
+		requestee.FriendRequests = make(map[string]int64)
+	}
+	if len(requestee.FriendRequests) >= MaxFriendRequests {
+		oldest := requestee.PendingFriendRequests()[0]
+		delete(requestee.FriendRequests, oldest)
+	}
+	requestee.FriendRequests[from] = now.Unix()
+	return nil
+}
+
+// PendingFriendRequests returns the chats waiting for the user's approval, oldest first
+func (u *UserPreferences) PendingFriendRequests() []string {
+	ids := make([]string, 0, len(u.FriendRequests))
+	for id := range u.FriendRequests {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti, tj := u.FriendRequests[ids[i]], u.FriendRequests[ids[j]]
+		if ti != tj {
+			return ti < tj
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// ApproveFriend accepts from's request, making the two friends on both sides. Returns
+// false if there was no such request.
+func (p Preferences) ApproveFriend(chatID, from string) bool {
+	user := p.GetUser(chatID)
+	if _, ok := user.FriendRequests[from]; !ok {
+		return false
+	}
+	delete(user.FriendRequests, from)
+	user.AddFriend(from)
+	p.GetUser(from).AddFriend(chatID)
+	return true
+}
+
+// DeclineFriend drops from's request without telling them. Returns false if there was
+// no such request.
+func (u *UserPreferences) DeclineFriend(from string) bool {
+	if _, ok := u.FriendRequests[from]; !ok {
+		return false
+	}
+	delete(u.FriendRequests, from)
+	return true
+}
+
+// IsBlocked reports whether the user blocked chatID
+func (u *UserPreferences) IsBlocked(chatID string) bool {
+	return slices.Contains(u.BlockedChatIDs, chatID)
+}
+
+// Block stops other from sending the user friend requests. Any friendship or pending
+// request between them, in either direction, is removed, so neither sees the other's
+// shared events, discussions, or group notes. Returns ErrAlreadyBlocked if other is
+// already blocked, or ErrTooManyBlocked once MaxBlockedChats are.
+func (p Preferences) Block(chatID, other string) error {
+	user := p.GetUser(chatID)
+	switch {
+	case user.IsBlocked(other):
+		return ErrAlreadyBlocked
+	case len(user.BlockedChatIDs) >= MaxBlockedChats:
+		return ErrTooManyBlocked
+	}
+	user.BlockedChatIDs = append(user.BlockedChatIDs, other)
+	user.RemoveFriend(other)
+	delete(user.FriendRequests, other)
+	delete(user.FriendSharing, other)
+	if blocked, ok := p[other]; ok {
+		blocked.RemoveFriend(chatID)
+		delete(blocked.FriendRequests, chatID)
+		delete(blocked.FriendSharing, chatID)
+	}
+	return nil
+}
+
+// Unblock allows other to send friend requests again. Returns false if they weren't
+// blocked.
+func (u *UserPreferences) Unblock(other string) bool {
+	i := slices.Index(u.BlockedChatIDs, other)
+	if i < 0 {
+		return false
+	}
+	u.BlockedChatIDs = slices.Delete(u.BlockedChatIDs, i, i+1)
+	if len(u.BlockedChatIDs) == 0 {
+		u.BlockedChatIDs = nil
+	}
+	return true
+}
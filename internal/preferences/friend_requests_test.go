@@ -0,0 +1,102 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFriendRequests(t *testing.T) {
+	prefs := NewPreferences()
+	now := time.Date(2026, time.April, 10, 12, 0, 0, 0, time.UTC)
+
+	if err := prefs.RequestFriend("222", "111", now); err != nil {
+		t.Fatalf("RequestFriend() error = %v", err)
+	}
+	if err := prefs.RequestFriend("222", "111", now); !errors.Is(err, ErrRequestPending) {
+		t.Errorf("repeated request error = %v, want ErrRequestPending", err)
+	}
+	if prefs.GetUser("111").IsFriend("222") {
+		t.Fatal("a request shouldn't make friends before it's approved")
+	}
+
+	if !prefs.ApproveFriend("111", "222") {
+		t.Fatal("ApproveFriend() = false")
+	}
+	if !prefs.GetUser("111").IsFriend("222") || !prefs.GetUser("222").IsFriend("111") {
+		t.Error("approval should add the friendship on both sides")
+	}
+	if prefs.ApproveFriend("111", "222") {
+		t.Error("approving twice should return false")
+	}
+	if err := prefs.RequestFriend("222", "111", now); !errors.Is(err, ErrAlreadyFriends) {
+		t.Errorf("request between friends error = %v, want ErrAlreadyFriends", err)
+	}
+
+	_ = prefs.RequestFriend("333", "111", now)
+	if !prefs.GetUser("111").DeclineFriend("333") || prefs.GetUser("111").IsFriend("333") {
+		t.Error("DeclineFriend() should drop the request")
+	}
+}
+
+func TestFriendRequestsCapped(t *testing.T) {
+	prefs := NewPreferences()
+	now := time.Date(2026, time.April, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i <= MaxFriendRequests; i++ {
+		_ = prefs.RequestFriend(fmt.Sprintf("%d", 1000+i), "111", now.Add(time.Duration(i)*time.Minute))
+	}
+	pending := prefs.GetUser("111").PendingFriendRequests()
+	if len(pending) != MaxFriendRequests || pending[0] != "1001" {
+		t.Errorf("pending = %d starting %q, want %d starting 1001", len(pending), pending[0], MaxFriendRequests)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	prefs := NewPreferences()
+	now := time.Now()
+	_ = prefs.RequestFriend("222", "111", now)
+	prefs.ApproveFriend("111", "222")
+	_ = prefs.RequestFriend("111", "333", now)
+
+	if err := prefs.Block("111", "222"); err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	if err := prefs.Block("111", "222"); !errors.Is(err, ErrAlreadyBlocked) {
+		t.Fatalf("blocking twice error = %v, want ErrAlreadyBlocked", err)
+	}
+	if prefs.GetUser("111").IsFriend("222") || prefs.GetUser("222").IsFriend("111") {
+		t.Error("blocking should end the friendship on both sides")
+	}
+	if err := prefs.RequestFriend("222", "111", now); !errors.Is(err, ErrBlocked) {
+		t.Errorf("request from a blocked chat error = %v, want ErrBlocked", err)
+	}
+
+	_ = prefs.Block("333", "111")
+	if len(prefs.GetUser("333").FriendRequests) != 0 {
+		t.Error("blocking should drop the blocked chat's pending request")
+	}
+
+	if !prefs.GetUser("111").Unblock("222") || prefs.GetUser("111").Unblock("222") {
+		t.Error("Unblock() should succeed once")
+	}
+	if err := prefs.RequestFriend("222", "111", now); err != nil {
+		t.Errorf("request after unblocking error = %v", err)
+	}
+}
+
+func TestBlockCapped(t *testing.T) {
+	prefs := NewPreferences()
+	for i := 0; i < MaxBlockedChats; i++ {
+		if err := prefs.Block("111", strconv.Itoa(1000+i)); err != nil {
+			t.Fatalf("Block() #%d error = %v", i, err)
+		}
+	}
+	if err := prefs.Block("111", "999"); !errors.Is(err, ErrTooManyBlocked) {
+		t.Errorf("Block() past the cap error = %v, want ErrTooManyBlocked", err)
+	}
+	if n := len(prefs.GetUser("111").BlockedChatIDs); n != MaxBlockedChats {
+		t.Errorf("blocked %d chats, want %d", n, MaxBlockedChats)
+	}
+}
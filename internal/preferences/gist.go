@@ -2,18 +2,24 @@ package preferences
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/course"
 	"github.com/pfrederiksen/vga-events/internal/crypto"
+	"github.com/pfrederiksen/vga-events/internal/errs"
 )
 
 const (
 	gistAPIURL   = "https://api.github.com/gists"
 	gistFilename = "preferences.json"
 	timeout      = 15 * time.Second
+
+	// courseAliasesFilename holds the maintainer-edited course alias map
+	courseAliasesFilename = "course_aliases.json"
 )
 
 // GistStorage implements Storage using GitHub Gists
@@ -53,72 +59,62 @@ func NewGistStorageWithEncryption(gistID, githubToken, encryptionKey string) (*G
 	}, nil
 }
 
-// Load retrieves preferences from the Gist
-func (g *GistStorage) Load() (Preferences, error) {
-	url := fmt.Sprintf("%s/%s", gistAPIURL, g.gistID)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", g.githubToken))
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+// Check reads the Gist, confirming it exists and the token can access it
+func (g *GistStorage) Check(ctx context.Context) error {
+	_, err := g.fetchFiles(ctx)
+	return err
+}
 
-	resp, err := g.httpClient.Do(req)
+// Load retrieves preferences from the Gist
+func (g *GistStorage) Load(ctx context.Context) (Preferences, error) {
+	files, err := g.fetchFiles(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("fetching gist: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		// Don't include response body in error to prevent information leakage
-		return nil, fmt.Errorf("GitHub API error (status %d)", resp.StatusCode)
-	}
-
-	var gistResp struct {
-		Files map[string]struct {
-			Content string `json:"content"`
-		} `json:"files"`
+		return nil, err
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&gistResp); err != nil {
-		return nil, fmt.Errorf("decoding gist response: %w", err)
-	}
-
-	file, exists := gistResp.Files[gistFilename]
+	content, exists := files[gistFilename]
 	if !exists {
 		// File doesn't exist yet, return empty preferences
 		return NewPreferences(), nil
 	}
 
-	prefs, err := FromJSON([]byte(file.Content))
+	prefs, err := FromJSON([]byte(content))
 	if err != nil {
 		return nil, fmt.Errorf("parsing preferences: %w", err)
 	}
 
 	// Decrypt sensitive fields if encryptor is configured
+	needsSave := false
 	if g.encryptor != nil {
 		needsMigration, err := g.decryptPreferencesWithMigration(prefs)
 		if err != nil {
 			return nil, fmt.Errorf("decrypting preferences: %w", err)
 		}
+		needsSave = needsMigration
+	}
 
-		// If migration occurred, re-save with new encryption
-		if needsMigration {
-			if err := g.Save(prefs); err != nil {
-				// Log warning but don't fail the load
-				// Migration will be retried on next save
-				_ = err // Suppress linter warning
-			}
+	// Replace legacy invite codes here, so the new codes are saved before anyone shares them
+	if prefs.MigrateInviteCodes() {
+		needsSave = true
+	}
+
+	// If migration occurred, re-save with new encryption and invite codes
+	if needsSave {
+		if err := g.Save(ctx, prefs); err != nil {
+			// Log warning but don't fail the load
+			// Migration will be retried on next save
+			_ = err // Suppress linter warning
 		}
 	}
 
+	// Linked chats share their event history in memory
+	prefs.ShareHouseholdData()
+
 	return prefs, nil
 }
 
 // Save updates the Gist with new preferences
-func (g *GistStorage) Save(prefs Preferences) error {
+func (g *GistStorage) Save(ctx context.Context, prefs Preferences) error {
 	// Encrypt sensitive fields if encryptor is configured
 	if g.encryptor != nil {
 		// Create a copy to avoid modifying the original
@@ -139,12 +135,84 @@ func (g *GistStorage) Save(prefs Preferences) error {
 		return fmt.Errorf("marshaling preferences: %w", err)
 	}
 
+	return g.patchFile(ctx, gistFilename, prefsJSON)
+}
+
+// LoadCourseAliases reads the course alias map, kept in its own file in the Gist. A
+// missing file is an empty map.
+func (g *GistStorage) LoadCourseAliases(ctx context.Context) (course.Aliases, error) {
+	files, err := g.fetchFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(course.Aliases)
+	content, exists := files[courseAliasesFilename]
+	if !exists {
+		return aliases, nil
+	}
+	if err := json.Unmarshal([]byte(content), &aliases); err != nil {
+		return nil, fmt.Errorf("parsing course aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// SaveCourseAliases writes the course alias map; preferences are left as they are
+func (g *GistStorage) SaveCourseAliases(ctx context.Context, aliases course.Aliases) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling course aliases: %w", err)
+	}
+	return g.patchFile(ctx, courseAliasesFilename, data)
+}
+
+// fetchFiles returns the content of every file in the Gist, keyed by filename
+func (g *GistStorage) fetchFiles(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/%s", gistAPIURL, g.gistID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", g.githubToken))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching gist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, githubError(resp)
+	}
+
+	var gistResp struct {
+		Files map[string]struct {
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&gistResp); err != nil {
+		return nil, fmt.Errorf("decoding gist response: %w", err)
+	}
+
+	files := make(map[string]string, len(gistResp.Files))
+	for name, file := range gistResp.Files {
+		files[name] = file.Content
+	}
+	return files, nil
+}
+
+// patchFile replaces one file in the Gist; the Gist's other files are kept
+func (g *GistStorage) patchFile(ctx context.Context, filename string, content []byte) error {
 	url := fmt.Sprintf("%s/%s", gistAPIURL, g.gistID)
 
 	payload := map[string]interface{}{
 		"files": map[string]interface{}{
-			gistFilename: map[string]string{
-				"content": string(prefsJSON),
+			filename: map[string]string{
+				"content": string(content),
 			},
 		},
 	}
@@ -154,7 +222,7 @@ func (g *GistStorage) Save(prefs Preferences) error {
 		return fmt.Errorf("marshaling payload: %w", err)
 	}
 
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
@@ -170,15 +238,14 @@ func (g *GistStorage) Save(prefs Preferences) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		// Don't include response body in error to prevent information leakage
-		return fmt.Errorf("GitHub API error (status %d)", resp.StatusCode)
+		return githubError(resp)
 	}
 
 	return nil
 }
 
 // CreateGist creates a new private Gist and returns its ID
-func CreateGist(githubToken, description string) (string, error) {
+func CreateGist(ctx context.Context, githubToken, description string) (string, error) {
 	if githubToken == "" {
 		return "", fmt.Errorf("GitHub token is required")
 	}
@@ -204,7 +271,7 @@ func CreateGist(githubToken, description string) (string, error) {
 		return "", fmt.Errorf("marshaling payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", gistAPIURL, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", gistAPIURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return "", fmt.Errorf("creating request: %w", err)
 	}
@@ -221,8 +288,7 @@ func CreateGist(githubToken, description string) (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		// Don't include response body in error to prevent information leakage
-		return "", fmt.Errorf("GitHub API error (status %d)", resp.StatusCode)
+		return "", githubError(resp)
 	}
 
 	var gistResp struct {
@@ -333,3 +399,15 @@ func (g *GistStorage) decryptPreferencesWithMigration(prefs Preferences) (bool,
 
 	return anyMigration, nil
 }
+
+// githubError classifies a failed GitHub API response. The body isn't included in the
+// error to prevent information leakage. GitHub reports an exhausted rate limit as 403,
+// so that's told apart from a bad token by the rate-limit header.
+func githubError(resp *http.Response) error {
+	statusErr := errs.Status(resp.StatusCode, "GitHub API error (status %d)", resp.StatusCode)
+	statusErr.RetryAfter = errs.ParseRetryAfter(resp.Header)
+	if resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		statusErr.Class = errs.ErrRateLimited
+	}
+	return statusErr
+}
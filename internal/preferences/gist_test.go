@@ -1,11 +1,15 @@
 package preferences
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
 )
 
 func TestNewGistStorage(t *testing.T) {
@@ -155,7 +159,7 @@ func TestCreateGist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.githubToken == "" {
 				// Test empty token case without server
-				gistID, err := CreateGist(tt.githubToken, tt.description)
+				gistID, err := CreateGist(context.Background(), tt.githubToken, tt.description)
 				if err == nil {
 					t.Error("CreateGist() expected error for empty token, got nil")
 				}
@@ -213,7 +217,7 @@ func TestCreateGist(t *testing.T) {
 
 			// This test is limited because we can't override the gistAPIURL constant
 			// In a real scenario, we'd refactor CreateGist to accept a base URL parameter
-			_, err := CreateGist(tt.githubToken, tt.description)
+			_, err := CreateGist(context.Background(), tt.githubToken, tt.description)
 
 			// We expect an error because we can't point to our test server with the current implementation
 			// This test primarily validates input validation
@@ -279,3 +283,35 @@ func TestGistStorage_EncryptionIntegration(t *testing.T) {
 		t.Errorf("InviteCode = %q, want 'secret-invite' after decryption", decryptedUser.InviteCode)
 	}
 }
+
+func TestGithubError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		remaining string
+		want      error
+	}{
+		{"bad token", http.StatusUnauthorized, "", errs.ErrAuth},
+		{"no access", http.StatusForbidden, "42", errs.ErrAuth},
+		{"rate limited", http.StatusForbidden, "0", errs.ErrRateLimited},
+		{"missing gist", http.StatusNotFound, "", errs.ErrNotFound},
+		{"server error", http.StatusBadGateway, "", errs.ErrTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.remaining != "" {
+				resp.Header.Set("X-RateLimit-Remaining", tt.remaining)
+			}
+
+			err := githubError(resp)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("githubError() = %v, want %v", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), "GitHub API error") {
+				t.Errorf("githubError() = %q, want GitHub API error message", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package preferences
+
+import "time"
+
+const (
+	// MaxGroupNoteLength caps one line of a group note, in characters
+	MaxGroupNoteLength = 200
+
+	// MaxGroupNoteLines is how many lines each user can add to an event's group note;
+	// older ones are dropped
+	MaxGroupNoteLines = 10
+)
+
+// AppendGroupNote adds a line to the user's part of an event's group note
+func (u *UserPreferences) AppendGroupNote(eventID, text string, now time.Time) {
+	if u.GroupNotes == nil {
+		u.GroupNotes = make(map[string][]Comment)
+	}
+	lines := append(u.GroupNotes[eventID], Comment{At: now.Unix(), Text: text})
+	if len(lines) > MaxGroupNoteLines {
+		lines = lines[len(lines)-MaxGroupNoteLines:]
+	}
+	u.GroupNotes[eventID] = lines
+}
+
+// ClearGroupNote removes the lines the user added to an event's group note and returns
+// how many there were. Friends' lines are theirs to remove.
+func (u *UserPreferences) ClearGroupNote(eventID string) int {
+	n := len(u.GroupNotes[eventID])
+	delete(u.GroupNotes, eventID)
+	return n
+}
+
+// GroupNote returns an event's group note as chatID sees it: the lines they and their
+// friends added, oldest first. Unlike a personal note, every friend can read it and
+// add to it.
+func (p Preferences) GroupNote(chatID, eventID string) []ThreadComment {
+	return p.friendComments(chatID, func(u *UserPreferences) []Comment { return u.GroupNotes[eventID] })
+}
@@ -0,0 +1,38 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupNote(t *testing.T) {
+	prefs := NewPreferences()
+	a, b, c := prefs.GetUser("111"), prefs.GetUser("222"), prefs.GetUser("333")
+	a.AddFriend("222")
+	b.AddFriend("111")
+
+	now := time.Now()
+	b.AppendGroupNote("evt1", "Meeting at the range at 7:30", now)
+	a.AppendGroupNote("evt1", "I'll bring the scorecards", now.Add(time.Minute))
+	c.AppendGroupNote("evt1", "Not a friend", now)
+	a.AddComment("evt1", "Discussion, not the note", now)
+
+	note := prefs.GroupNote("111", "evt1")
+	if len(note) != 2 || note[0].ChatID != "222" || note[1].Text != "I'll bring the scorecards" {
+		t.Fatalf("GroupNote() = %+v", note)
+	}
+
+	for i := range MaxGroupNoteLines + 2 {
+		a.AppendGroupNote("evt2", "line", now.Add(time.Duration(i)*time.Second))
+	}
+	if got := len(a.GroupNotes["evt2"]); got != MaxGroupNoteLines {
+		t.Errorf("kept %d lines, want %d", got, MaxGroupNoteLines)
+	}
+
+	if n := a.ClearGroupNote("evt1"); n != 1 {
+		t.Errorf("ClearGroupNote() = %d, want 1", n)
+	}
+	if note := prefs.GroupNote("111", "evt1"); len(note) != 1 || note[0].ChatID != "222" {
+		t.Errorf("clearing should keep friends' lines, got %+v", note)
+	}
+}
@@ -0,0 +1,14 @@
+package preferences
+
+// MaxDaysAhead caps the DaysAhead horizon
+const MaxDaysAhead = 365
+
+// SetDaysAhead limits events to the next days days, or lifts the limit with 0.
+// Returns false if days is out of range.
+func (u *UserPreferences) SetDaysAhead(days int) bool {
+	if days < 0 || days > MaxDaysAhead {
+		return false
+	}
+	u.DaysAhead = days
+	return true
+}
@@ -0,0 +1,16 @@
+package preferences
+
+import "testing"
+
+func TestSetDaysAhead(t *testing.T) {
+	user := NewPreferences().GetUser("123")
+	if !user.SetDaysAhead(60) || user.DaysAhead != 60 {
+		t.Errorf("SetDaysAhead(60) -> %d", user.DaysAhead)
+	}
+	if user.SetDaysAhead(-1) || user.SetDaysAhead(MaxDaysAhead+1) || user.DaysAhead != 60 {
+		t.Errorf("out-of-range SetDaysAhead() changed DaysAhead to %d", user.DaysAhead)
+	}
+	if !user.SetDaysAhead(0) || user.DaysAhead != 0 {
+		t.Error("SetDaysAhead(0) should lift the limit")
+	}
+}
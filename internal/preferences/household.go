@@ -0,0 +1,251 @@
+package preferences
+
+import (
+	"crypto/rand"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	// MaxHouseholdSize caps how many chats can be linked together
+	MaxHouseholdSize = 4
+
+	// LinkCodeTTL is how long a /link code can be used
+	LinkCodeTTL = 24 * time.Hour
+
+	// linkCodeAlphabet leaves out characters that are easy to mix up (0/O, 1/I)
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	linkCodeLength   = 8
+)
+
+// NewLinkCode generates a one-time code another chat can use to link with this one,
+// replacing any earlier code
+func (u *UserPreferences) NewLinkCode(now time.Time) string {
+	u.LinkCode = randomCode(linkCodeLength)
+	u.LinkCodeExpires = now.Add(LinkCodeTTL).Unix()
+	return u.LinkCode
+}
+
+// randomCode returns n random characters from linkCodeAlphabet
+func randomCode(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand doesn't fail on supported platforms
+		panic(err)
+	}
+	for i, b := range buf {
+		buf[i] = linkCodeAlphabet[int(b)%len(linkCodeAlphabet)] // 256 is a multiple of 32, so no bias
+	}
+	return string(buf)
+}
+
+// FindLinkCode returns the chat that issued an unexpired link code
+func (p Preferences) FindLinkCode(code string, now time.Time) (string, bool) {
+	if code == "" {
+		return "", false
+	}
+	for chatID, user := range p {
+		if user.LinkCode == code && user.LinkCodeExpires > now.Unix() {
+			return chatID, true
+		}
+	}
+	return "", false
+}
+
+// Household returns the chat and the chats linked with it, sorted
+func (p Preferences) Household(chatID string) []string {
+	members := []string{chatID}
+	if user, ok := p[chatID]; ok {
+		members = append(members, user.LinkedChatIDs...)
+	}
+	sort.Strings(members)
+	return slices.Compact(members)
+}
+
+// LinkAccounts links two chats' households so they share event statuses, notes, and
+// seen-event history. Returns false if the combined household would be larger than
+// MaxHouseholdSize. The code used to link is cleared.
+func (p Preferences) LinkAccounts(chatID, otherChatID string) bool {
+	members := append(p.Household(chatID), p.Household(otherChatID)...)
+	sort.Strings(members)
+	members = slices.Compact(members)
+	if len(members) > MaxHouseholdSize {
+		return false
+	}
+
+	for _, id := range members {
+		user := p.GetUser(id)
+		user.LinkedChatIDs = nil
+		for _, other := range members {
+			if other != id {
+				user.LinkedChatIDs = append(user.LinkedChatIDs, other)
+			}
+		}
+		user.LinkCode = ""
+		user.LinkCodeExpires = 0
+	}
+	p.shareHousehold(members)
+	return true
+}
+
+// UnlinkAccount removes a chat from its household. It keeps a copy of the shared
+// history; later changes are no longer shared. Returns false if it wasn't linked.
+func (p Preferences) UnlinkAccount(chatID string) bool {
+	user := p.GetUser(chatID)
+	if len(user.LinkedChatIDs) == 0 {
+		return false
+	}
+
+	for _, id := range user.LinkedChatIDs {
+		other := p.GetUser(id)
+		other.LinkedChatIDs = slices.DeleteFunc(other.LinkedChatIDs, func(linked string) bool { return linked == chatID })
+		if len(other.LinkedChatIDs) == 0 {
+			other.LinkedChatIDs = nil
+		}
+	}
+	user.LinkedChatIDs = nil
+
+	user.SeenEventIDs = maps.Clone(user.SeenEventIDs)
+	user.EventStatuses = maps.Clone(user.EventStatuses)
+	user.EventNotes = maps.Clone(user.EventNotes)
+	user.NoteAttachments = maps.Clone(user.NoteAttachments)
+	user.StatusHistory = maps.Clone(user.StatusHistory)
+	user.ArchivedEvents = maps.Clone(user.ArchivedEvents)
+	return true
+}
+
+// ShareHouseholdData merges the event history of linked chats and makes them share
+// it, so a change by one member is seen by all. It runs after loading (and decrypting),
+// since each member's copy is saved separately and can drift: the notifier marks
+// events seen one chat at a time.
+func (p Preferences) ShareHouseholdData() {
+	done := make(map[string]bool)
+	for chatID, user := range p {
+		if done[chatID] || len(user.LinkedChatIDs) == 0 {
+			continue
+		}
+		members := p.Household(chatID)
+		for _, id := range members {
+			done[id] = true
+		}
+		p.shareHousehold(members)
+	}
+}
+
+// shareHousehold merges the members' event history into one set of maps and gives
+// every member the same maps
+func (p Preferences) shareHousehold(members []string) {
+	users := make([]*UserPreferences, 0, len(members))
+	for _, id := range members {
+		if user, ok := p[id]; ok {
+			users = append(users, user)
+		}
+	}
+
+	seen := make(map[string]int64)
+	statuses := make(map[string]string)
+	notes := make(map[string]string)
+	attachments := make(map[string]*NoteAttachment)
+	history := make(map[string][]StatusChange)
+	archived := make(map[string]*ArchivedEvent)
+
+	for _, user := range users {
+		for id, at := range user.SeenEventIDs {
+			if first, ok := seen[id]; !ok || at < first {
+				seen[id] = at // Keep when it was first seen
+			}
+		}
+		for id, changes := range user.StatusHistory {
+			history[id] = mergeStatusHistory(history[id], changes)
+		}
+		for id, note := range user.EventNotes {
+			existing, ok := notes[id]
+			switch {
+			case !ok || existing == "":
+				notes[id] = note
+			case note != "" && note != existing:
+				notes[id] = existing + "\n" + note // Keep both members' notes
+			}
+		}
+		for id, attachment := range user.NoteAttachments {
+			if _, ok := attachments[id]; !ok {
+				attachments[id] = attachment
+			}
+		}
+		for id, record := range user.ArchivedEvents {
+			if _, ok := archived[id]; !ok {
+				archived[id] = record
+			}
+		}
+	}
+
+	// Conflicting statuses resolve to the member who changed it most recently
+	latest := make(map[string]time.Time)
+	for _, user := range users {
+		for id, status := range user.EventStatuses {
+			var changed time.Time
+			if changes := user.StatusHistory[id]; len(changes) > 0 {
+				changed = changes[len(changes)-1].At
+			}
+			if at, ok := latest[id]; !ok || changed.After(at) {
+				statuses[id] = status
+				latest[id] = changed
+			}
+		}
+	}
+
+	for _, user := range users {
+		user.SeenEventIDs = seen
+		user.EventStatuses = statuses
+		user.EventNotes = notes
+		user.NoteAttachments = attachments
+		user.StatusHistory = history
+		user.ArchivedEvents = archived
+	}
+}
+
+// mergeStatusHistory combines two members' changes for an event, oldest first,
+// without duplicates and capped at MaxStatusHistory
+func mergeStatusHistory(a, b []StatusChange) []StatusChange {
+	merged := append(slices.Clone(a), b...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].At.Before(merged[j].At) })
+	merged = slices.CompactFunc(merged, func(x, y StatusChange) bool {
+		return x.From == y.From && x.To == y.To && x.At.Equal(y.At)
+	})
+	if len(merged) > MaxStatusHistory {
+		merged = merged[len(merged)-MaxStatusHistory:]
+	}
+	return merged
+}
+
+// HouseholdStats counts a household's activity with each event counted once, however
+// many members saw or marked it
+func (p Preferences) HouseholdStats(chatID string) *WeeklyStats {
+	seen := make(map[string]bool)
+	statuses := make(map[string]string)
+	for _, id := range p.Household(chatID) {
+		user, ok := p[id]
+		if !ok {
+			continue
+		}
+		for eventID := range user.SeenEventIDs {
+			seen[eventID] = true
+		}
+		for eventID, status := range user.EventStatuses {
+			if _, ok := statuses[eventID]; !ok {
+				statuses[eventID] = status
+			}
+		}
+	}
+
+	stats := &WeeklyStats{EventsViewed: len(seen), EventsMarked: make(map[string]int)}
+	for _, status := range statuses {
+		stats.EventsMarked[status]++
+		if status == EventStatusRegistered {
+			stats.EventsRegistered++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,147 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkCode(t *testing.T) {
+	prefs := NewPreferences()
+	now := time.Now()
+
+	code := prefs.GetUser("111").NewLinkCode(now)
+	if len(code) != linkCodeLength {
+		t.Fatalf("NewLinkCode() = %q, want %d characters", code, linkCodeLength)
+	}
+	if chatID, ok := prefs.FindLinkCode(code, now); !ok || chatID != "111" {
+		t.Errorf("FindLinkCode() = %q, %v", chatID, ok)
+	}
+	if _, ok := prefs.FindLinkCode(code, now.Add(LinkCodeTTL+time.Minute)); ok {
+		t.Error("expired code should not be found")
+	}
+	if _, ok := prefs.FindLinkCode("", now); ok {
+		t.Error("empty code should not be found")
+	}
+}
+
+func TestLinkAccounts(t *testing.T) {
+	prefs := NewPreferences()
+	a, b := prefs.GetUser("111"), prefs.GetUser("222")
+
+	a.MarkEventSeen("seen-a")
+	b.MarkEventSeen("seen-b")
+	a.SetEventStatus("evt1", EventStatusInterested)
+	time.Sleep(time.Millisecond) // b's change is the most recent
+	b.SetEventStatus("evt1", EventStatusRegistered)
+	a.SetEventNote("evt1", "Bring the rangefinder")
+	b.SetEventNote("evt1", "Tee time 8am")
+	a.NewLinkCode(time.Now())
+
+	if !prefs.LinkAccounts("222", "111") {
+		t.Fatal("LinkAccounts() = false")
+	}
+	if a.LinkCode != "" {
+		t.Error("link code should be cleared")
+	}
+	if got := prefs.Household("111"); len(got) != 2 || got[0] != "111" || got[1] != "222" {
+		t.Errorf("Household() = %v", got)
+	}
+
+	for _, u := range []*UserPreferences{a, b} {
+		if !u.HasSeenEvent("seen-a") || !u.HasSeenEvent("seen-b") {
+			t.Error("seen history should be merged")
+		}
+		if got := u.GetEventStatus("evt1"); got != EventStatusRegistered {
+			t.Errorf("status = %q, want the most recent change", got)
+		}
+		if got := u.GetEventNote("evt1"); got != "Bring the rangefinder\nTee time 8am" {
+			t.Errorf("note = %q, want both notes", got)
+		}
+	}
+
+	// Changes by one member are seen by the other
+	a.SetEventStatus("evt2", EventStatusMaybe)
+	if b.GetEventStatus("evt2") != EventStatusMaybe {
+		t.Error("status change should be shared")
+	}
+
+	for _, id := range []string{"333", "444"} {
+		if !prefs.LinkAccounts(id, "111") {
+			t.Fatalf("LinkAccounts(%s) = false", id)
+		}
+	}
+	if len(prefs.GetUser("222").LinkedChatIDs) != 3 {
+		t.Errorf("every member should list the others, got %v", prefs.GetUser("222").LinkedChatIDs)
+	}
+	if prefs.LinkAccounts("555", "111") {
+		t.Error("LinkAccounts() should fail past MaxHouseholdSize")
+	}
+}
+
+func TestUnlinkAccount(t *testing.T) {
+	prefs := NewPreferences()
+	a, b := prefs.GetUser("111"), prefs.GetUser("222")
+	prefs.LinkAccounts("111", "222")
+	a.SetEventStatus("evt1", EventStatusInterested)
+
+	if prefs.UnlinkAccount("333") {
+		t.Error("unlinking an unlinked chat should fail")
+	}
+	if !prefs.UnlinkAccount("222") {
+		t.Fatal("UnlinkAccount() = false")
+	}
+	if a.LinkedChatIDs != nil || b.LinkedChatIDs != nil {
+		t.Errorf("links should be removed, got %v and %v", a.LinkedChatIDs, b.LinkedChatIDs)
+	}
+	if b.GetEventStatus("evt1") != EventStatusInterested {
+		t.Error("unlinked chat should keep a copy of the shared history")
+	}
+	b.SetEventStatus("evt2", EventStatusSkip)
+	if a.GetEventStatus("evt2") != "" {
+		t.Error("changes after unlinking should not be shared")
+	}
+}
+
+func TestShareHouseholdDataAfterLoad(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.LinkAccounts("111", "222")
+	data, err := prefs.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := FromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The notifier marked an event seen for one member only
+	loaded.GetUser("111").MarkEventSeen("evt1")
+	loaded.ShareHouseholdData()
+
+	if !loaded.GetUser("222").HasSeenEvent("evt1") {
+		t.Error("seen history should be shared after loading")
+	}
+	loaded.GetUser("222").SetEventNote("evt1", "Carpool")
+	if loaded.GetUser("111").GetEventNote("evt1") != "Carpool" {
+		t.Error("loaded members should share maps")
+	}
+}
+
+func TestHouseholdStats(t *testing.T) {
+	prefs := NewPreferences()
+	a, b := prefs.GetUser("111"), prefs.GetUser("222")
+	a.MarkEventSeen("evt1")
+	b.MarkEventSeen("evt1")
+	b.MarkEventSeen("evt2")
+	a.SetEventStatus("evt1", EventStatusRegistered)
+	b.SetEventStatus("evt1", EventStatusRegistered)
+	prefs.LinkAccounts("111", "222")
+
+	stats := prefs.HouseholdStats("111")
+	if stats.EventsViewed != 2 {
+		t.Errorf("EventsViewed = %d, want 2", stats.EventsViewed)
+	}
+	if stats.EventsMarked[EventStatusRegistered] != 1 || stats.EventsRegistered != 1 {
+		t.Errorf("registered event should count once, got %+v", stats)
+	}
+}
@@ -0,0 +1,73 @@
+package preferences
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// InactiveAfter is how long without a message or button tap before a user is asked
+	// whether they still want notifications
+	InactiveAfter = 90 * 24 * time.Hour
+
+	// ReengageWindow is how long a user has to answer before being deactivated
+	ReengageWindow = 14 * 24 * time.Hour
+
+	// interactionResolution limits how often LastInteraction changes, so everyday use
+	// doesn't force a save on every update
+	interactionResolution = 24 * time.Hour
+)
+
+// RecordInteraction notes that the user sent a message or tapped a button. Any pending
+// re-engagement question is answered, and a user deactivated for not answering one is
+// turned back on. Returns true if anything changed.
+func (u *UserPreferences) RecordInteraction(now time.Time) bool {
+	changed := false
+	if now.Unix()-u.LastInteraction >= int64(interactionResolution.Seconds()) {
+		u.LastInteraction = now.Unix()
+		changed = true
+	}
+	if u.ReengageSentAt != 0 {
+		u.ReengageSentAt = 0
+		changed = true
+	}
+	if u.DeactivatedAt != 0 {
+		u.DeactivatedAt = 0
+		u.Active = true
+		changed = true
+	}
+	return changed
+}
+
+// MarkReengageSent records that the "still want notifications?" message was sent
+func (u *UserPreferences) MarkReengageSent(now time.Time) {
+	u.ReengageSentAt = now.Unix()
+}
+
+// SweepInactive runs the inactivity checks for all active users. Users who haven't
+// interacted for InactiveAfter are returned in reengage (those already asked aren't
+// asked again), and users who didn't answer within ReengageWindow are deactivated and
+// returned in deactivated. Users with no recorded interaction, from before it was
+// tracked, start counting from now. Both lists are sorted.
+func (p Preferences) SweepInactive(now time.Time) (reengage, deactivated []string) {
+	for chatID, user := range p {
+		if !user.Active {
+			continue
+		}
+		switch {
+		case user.LastInteraction == 0:
+			user.LastInteraction = now.Unix()
+		case user.ReengageSentAt != 0:
+			if now.Sub(time.Unix(user.ReengageSentAt, 0)) >= ReengageWindow {
+				user.Active = false
+				user.DeactivatedAt = now.Unix()
+				deactivated = append(deactivated, chatID)
+			}
+		case now.Sub(time.Unix(user.LastInteraction, 0)) >= InactiveAfter:
+			reengage = append(reengage, chatID)
+		}
+	}
+	sort.Strings(reengage)
+	sort.Strings(deactivated)
+	return reengage, deactivated
+}
@@ -0,0 +1,71 @@
+package preferences
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordInteraction(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	now := time.Date(2026, time.April, 10, 12, 0, 0, 0, time.UTC)
+
+	if !user.RecordInteraction(now) || user.LastInteraction != now.Unix() {
+		t.Fatal("first interaction should be recorded")
+	}
+	if user.RecordInteraction(now.Add(time.Hour)) {
+		t.Error("interaction on the same day should not change anything")
+	}
+	if !user.RecordInteraction(now.Add(25 * time.Hour)) {
+		t.Error("interaction a day later should be recorded")
+	}
+
+	user.Active = false
+	user.DeactivatedAt = now.Unix()
+	user.ReengageSentAt = now.Unix()
+	if !user.RecordInteraction(now.Add(26 * time.Hour)) {
+		t.Fatal("interaction by a deactivated user should change it")
+	}
+	if !user.Active || user.DeactivatedAt != 0 || user.ReengageSentAt != 0 {
+		t.Errorf("user not reactivated: active=%v deactivated_at=%d reengage_sent_at=%d", user.Active, user.DeactivatedAt, user.ReengageSentAt)
+	}
+}
+
+func TestSweepInactive(t *testing.T) {
+	now := time.Date(2026, time.April, 10, 12, 0, 0, 0, time.UTC)
+	prefs := NewPreferences()
+
+	prefs.GetUser("legacy")
+	prefs.GetUser("recent").LastInteraction = now.AddDate(0, 0, -10).Unix()
+	prefs.GetUser("idle").LastInteraction = now.Add(-InactiveAfter).Unix()
+
+	asked := prefs.GetUser("asked")
+	asked.LastInteraction = now.Add(-InactiveAfter - 48*time.Hour).Unix()
+	asked.MarkReengageSent(now.Add(-24 * time.Hour))
+
+	expired := prefs.GetUser("expired")
+	expired.LastInteraction = now.AddDate(-1, 0, 0).Unix()
+	expired.MarkReengageSent(now.Add(-ReengageWindow))
+
+	stopped := prefs.GetUser("stopped")
+	stopped.Active = false
+	stopped.LastInteraction = now.AddDate(-1, 0, 0).Unix()
+
+	reengage, deactivated := prefs.SweepInactive(now)
+	if want := []string{"idle"}; !reflect.DeepEqual(reengage, want) {
+		t.Errorf("reengage = %v, want %v", reengage, want)
+	}
+	if want := []string{"expired"}; !reflect.DeepEqual(deactivated, want) {
+		t.Errorf("deactivated = %v, want %v", deactivated, want)
+	}
+	if expired.Active || expired.DeactivatedAt != now.Unix() {
+		t.Error("expired user should be deactivated")
+	}
+	if got := prefs["legacy"].LastInteraction; got != now.Unix() {
+		t.Errorf("legacy LastInteraction = %d, want now", got)
+	}
+	if stopped.DeactivatedAt != 0 {
+		t.Error("inactive users should be left alone")
+	}
+}
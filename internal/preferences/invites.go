@@ -0,0 +1,87 @@
+package preferences
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// inviteCodeLength is the length of a friend invite code, from linkCodeAlphabet
+	inviteCodeLength = 8
+
+	// MaxInviteTTL caps how long an expiring invite code can last
+	MaxInviteTTL = 90 * 24 * time.Hour
+)
+
+// isLegacyInviteCode reports whether code is one of the old invite codes made from the
+// last 6 digits of the chat ID, which were guessable and gave part of the ID away
+func isLegacyInviteCode(chatID, code string) bool {
+	if len(chatID) >= 6 {
+		return code == chatID[len(chatID)-6:]
+	}
+	return code == chatID
+}
+
+// MigrateInviteCodes gives users with no invite code, or a legacy one derived from the
+// chat ID, a new random code, and reports whether any changed so the caller can save
+func (p Preferences) MigrateInviteCodes() bool {
+	changed := false
+	for chatID, user := range p {
+		if user.InviteCode == "" || isLegacyInviteCode(chatID, user.InviteCode) {
+			user.InviteCode = randomCode(inviteCodeLength)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// NewInviteCode replaces the user's invite code with a new random one, so the old code
+// stops working. A ttl above zero makes the code expire; a single-use code is replaced
+// as soon as someone joins with it.
+func (u *UserPreferences) NewInviteCode(now time.Time, ttl time.Duration, singleUse bool) string {
+	u.InviteCode = randomCode(inviteCodeLength)
+	u.InviteExpires = 0
+	if ttl > 0 {
+		u.InviteExpires = now.Add(ttl).Unix()
+	}
+	u.InviteSingleUse = singleUse
+	return u.InviteCode
+}
+
+// InviteExpired reports whether the user's invite code has expired at now
+func (u *UserPreferences) InviteExpired(now time.Time) bool {
+	return u.InviteExpires != 0 && u.InviteExpires <= now.Unix()
+}
+
+// InviteExpiresTime returns when the invite code expires, or the zero time if it doesn't
+func (u *UserPreferences) InviteExpiresTime() time.Time {
+	if u.InviteExpires == 0 {
+		return time.Time{}
+	}
+	return time.Unix(u.InviteExpires, 0).UTC()
+}
+
+// UseInviteCode is called after someone joins with the user's code; a single-use code
+// is replaced with a fresh one that has no limits
+func (u *UserPreferences) UseInviteCode(now time.Time) {
+	if u.InviteSingleUse {
+		u.NewInviteCode(now, 0, false)
+	}
+}
+
+// FindInviteCode returns the chat whose invite code is code, ignoring case. expired is
+// true when the code was found but has expired. Legacy codes derived from chat IDs are
+// never matched.
+func (p Preferences) FindInviteCode(code string, now time.Time) (chatID string, expired bool, ok bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "", false, false
+	}
+	for id, user := range p {
+		if user.InviteCode != code || isLegacyInviteCode(id, user.InviteCode) {
+			continue
+		}
+		return id, user.InviteExpired(now), true
+	}
+	return "", false, false
+}
@@ -0,0 +1,70 @@
+package preferences
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewInviteCode(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123456789")
+	now := time.Date(2026, time.April, 10, 12, 0, 0, 0, time.UTC)
+
+	old := user.GetInviteCode()
+	code := user.NewInviteCode(now, 7*24*time.Hour, true)
+	if code == old || len(code) != inviteCodeLength {
+		t.Errorf("NewInviteCode() = %q, old %q", code, old)
+	}
+	if _, _, ok := prefs.FindInviteCode(old, now); ok {
+		t.Error("old code should stop working")
+	}
+
+	chatID, expired, ok := prefs.FindInviteCode(strings.ToLower(code), now)
+	if !ok || expired || chatID != "123456789" {
+		t.Errorf("FindInviteCode(%q) = %q, %v, %v", code, chatID, expired, ok)
+	}
+	if _, expired, _ := prefs.FindInviteCode(code, now.AddDate(0, 0, 7)); !expired {
+		t.Error("code should have expired after 7 days")
+	}
+
+	user.UseInviteCode(now)
+	if user.InviteCode == code || user.InviteSingleUse || user.InviteExpires != 0 {
+		t.Error("single-use code should be replaced with an unlimited one after use")
+	}
+	code = user.InviteCode
+	user.UseInviteCode(now)
+	if user.InviteCode != code {
+		t.Error("reusable code should be kept after use")
+	}
+}
+
+func TestFindInviteCodeIgnoresLegacyCodes(t *testing.T) {
+	prefs := Preferences{"123456789": {InviteCode: "456789", Active: true}}
+	if _, _, ok := prefs.FindInviteCode("456789", time.Now()); ok {
+		t.Error("legacy code derived from the chat ID should not be accepted")
+	}
+}
+
+func TestMigrateInviteCodesSurvivesReload(t *testing.T) {
+	prefs := Preferences{"123456789": {InviteCode: "456789", Active: true}, "222": {Active: true}}
+	if !prefs.MigrateInviteCodes() {
+		t.Fatal("MigrateInviteCodes() = false, want the legacy and missing codes replaced")
+	}
+	code := prefs["123456789"].InviteCode
+
+	data, err := prefs.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error: %v", err)
+	}
+	reloaded, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON() error: %v", err)
+	}
+	if reloaded.MigrateInviteCodes() {
+		t.Error("reloaded codes shouldn't need migrating again")
+	}
+	if got := reloaded.GetUser("123456789").GetInviteCode(); got != code {
+		t.Errorf("invite code after reload = %q, want %q", got, code)
+	}
+}
@@ -0,0 +1,89 @@
+package preferences
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/league"
+)
+
+const (
+	// MaxLeagues is how many leagues a chat can run as admin
+	MaxLeagues = 5
+
+	// leagueIDLength is the length of a league's ID, which members type in commands
+	leagueIDLength = 5
+)
+
+// ErrTooManyLeagues is returned when the chat already runs MaxLeagues leagues
+var ErrTooManyLeagues = errors.New("too many leagues")
+
+// LeagueRef is a league and the chat that runs it
+type LeagueRef struct {
+	AdminID string
+	League  *league.League
+}
+
+// AddLeague starts a league run by this chat, with the admin and members as its members
+func (u *UserPreferences) AddLeague(name, adminID string, members []string, now time.Time) (*league.League, error) {
+	if len(u.Leagues) >= MaxLeagues {
+		return nil, ErrTooManyLeagues
+	}
+	l := &league.League{
+		ID:      randomCode(leagueIDLength),
+		Name:    name,
+		Created: now.Unix(),
+		Members: []string{adminID},
+	}
+	for _, member := range members {
+		if _, err := l.AddMember(member); err != nil {
+			break
+		}
+	}
+	u.Leagues = append(u.Leagues, l)
+	return l, nil
+}
+
+// RemoveLeague deletes one of the chat's leagues. Returns false if it has no such league.
+func (u *UserPreferences) RemoveLeague(id string) bool {
+	for i, l := range u.Leagues {
+		if strings.EqualFold(l.ID, id) {
+			u.Leagues = append(u.Leagues[:i], u.Leagues[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FindLeague returns the league with the given ID (any case) and the chat that runs it
+func (p Preferences) FindLeague(id string) (LeagueRef, bool) {
+	for chatID, user := range p {
+		for _, l := range user.Leagues {
+			if strings.EqualFold(l.ID, id) {
+				return LeagueRef{AdminID: chatID, League: l}, true
+			}
+		}
+	}
+	return LeagueRef{}, false
+}
+
+// LeaguesFor returns the leagues chatID runs or is a member of, oldest first
+func (p Preferences) LeaguesFor(chatID string) []LeagueRef {
+	var refs []LeagueRef
+	for adminID, user := range p {
+		for _, l := range user.Leagues {
+			if adminID == chatID || l.IsMember(chatID) {
+				refs = append(refs, LeagueRef{AdminID: adminID, League: l})
+			}
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].League.Created != refs[j].League.Created {
+			return refs[i].League.Created < refs[j].League.Created
+		}
+		return refs[i].League.ID < refs[j].League.ID
+	})
+	return refs
+}
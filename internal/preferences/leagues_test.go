@@ -0,0 +1,49 @@
+package preferences
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeagues(t *testing.T) {
+	prefs := NewPreferences()
+	admin := prefs.GetUser("111")
+	prefs.GetUser("222")
+
+	now := time.Now()
+	l, err := admin.AddLeague("Tuesday Crew", "111", []string{"222", "111"}, now)
+	if err != nil {
+		t.Fatalf("AddLeague() error: %v", err)
+	}
+	if len(l.Members) != 2 || l.Members[0] != "111" {
+		t.Errorf("members = %v, want the admin first and no duplicates", l.Members)
+	}
+
+	if ref, ok := prefs.FindLeague(l.ID); !ok || ref.AdminID != "111" || ref.League != l {
+		t.Errorf("FindLeague() = %+v, %v", ref, ok)
+	}
+	if refs := prefs.LeaguesFor("222"); len(refs) != 1 || refs[0].League != l {
+		t.Errorf("LeaguesFor(member) = %+v", refs)
+	}
+	if refs := prefs.LeaguesFor("333"); len(refs) != 0 {
+		t.Errorf("LeaguesFor(outsider) = %+v", refs)
+	}
+
+	for i := 1; i < MaxLeagues; i++ {
+		admin.AddLeague("Another", "111", nil, now.Add(time.Duration(i)*time.Second))
+	}
+	if _, err := admin.AddLeague("One too many", "111", nil, now); !errors.Is(err, ErrTooManyLeagues) {
+		t.Errorf("AddLeague() past MaxLeagues: err = %v", err)
+	}
+	if refs := prefs.LeaguesFor("111"); len(refs) != MaxLeagues || refs[0].League != l {
+		t.Errorf("LeaguesFor(admin) should list all leagues, oldest first: %d", len(refs))
+	}
+
+	if !admin.RemoveLeague(l.ID) {
+		t.Error("RemoveLeague() should find the league")
+	}
+	if _, ok := prefs.FindLeague(l.ID); ok {
+		t.Error("removed league still found")
+	}
+}
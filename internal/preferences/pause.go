@@ -0,0 +1,44 @@
+package preferences
+
+import (
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// MaxPauseDays caps how long notifications can be paused
+const MaxPauseDays = 90
+
+// Pause mutes notifications until the given time. Subscriptions are kept, and events
+// matched in the meantime are queued in PendingEvents for a catch-up digest.
+func (u *UserPreferences) Pause(until time.Time) {
+	u.PausedUntil = until.Unix()
+}
+
+// IsPaused reports whether notifications are paused at now
+func (u *UserPreferences) IsPaused(now time.Time) bool {
+	return u.PausedUntil > now.Unix()
+}
+
+// PauseEnded reports whether a pause ran out without /resume, so its catch-up digest
+// is still due
+func (u *UserPreferences) PauseEnded(now time.Time) bool {
+	return u.PausedUntil != 0 && !u.IsPaused(now)
+}
+
+// PausedUntilTime returns when the pause ends, or the zero time if not paused
+func (u *UserPreferences) PausedUntilTime() time.Time {
+	if u.PausedUntil == 0 {
+		return time.Time{}
+	}
+	return time.Unix(u.PausedUntil, 0).UTC()
+}
+
+// Resume ends a pause and returns the events queued for the catch-up digest,
+// clearing the queue
+func (u *UserPreferences) Resume() []*event.Event {
+	u.PausedUntil = 0
+	events := u.PendingEvents
+	u.ClearPendingEvents()
+	return events
+}
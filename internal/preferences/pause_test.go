@@ -0,0 +1,43 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestPause(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	now := time.Date(2026, time.April, 10, 12, 0, 0, 0, time.UTC)
+
+	if user.IsPaused(now) || user.PauseEnded(now) {
+		t.Fatal("new user should not be paused")
+	}
+
+	user.Pause(now.AddDate(0, 0, 14))
+	if !user.IsPaused(now) || user.PauseEnded(now) {
+		t.Error("user should be paused")
+	}
+	if got := user.PausedUntilTime(); !got.Equal(now.AddDate(0, 0, 14)) {
+		t.Errorf("PausedUntilTime() = %v", got)
+	}
+
+	later := now.AddDate(0, 0, 15)
+	if user.IsPaused(later) || !user.PauseEnded(later) {
+		t.Error("pause should have ended")
+	}
+
+	user.AddPendingEvent(&event.Event{ID: "a"})
+	user.AddPendingEvent(&event.Event{ID: "b"})
+	if events := user.Resume(); len(events) != 2 {
+		t.Errorf("Resume() returned %d events, want 2", len(events))
+	}
+	if user.PausedUntil != 0 || len(user.PendingEvents) != 0 || !user.PausedUntilTime().IsZero() {
+		t.Error("Resume() should clear the pause and the queue")
+	}
+	if user.PauseEnded(later) {
+		t.Error("resumed user should not have an ended pause")
+	}
+}
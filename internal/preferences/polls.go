@@ -0,0 +1,157 @@
+package preferences
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// MaxPolls is how many of a user's polls are kept; older ones are dropped
+	MaxPolls = 5
+
+	// pollIDLength is the length of a poll's ID, used in button callback data
+	pollIDLength = 6
+)
+
+// EventPoll is a "which event should we play?" vote started with /poll. It may be sent
+// to several chats (the user and their friends); votes from every copy are counted
+// together.
+type EventPoll struct {
+	ID       string         `json:"id"`
+	EventIDs []string       `json:"event_ids"` // One per poll option, in order
+	Created  int64          `json:"created"`   // Unix time
+	Closed   bool           `json:"closed,omitempty"`
+	Messages []PollMessage  `json:"messages,omitempty"`
+	Votes    map[string]int `json:"votes,omitempty"` // Voter chat ID -> option index
+}
+
+// PollMessage is one copy of a poll sent to a chat
+type PollMessage struct {
+	ChatID     string `json:"chat_id"`
+	MessageID  int    `json:"message_id"`
+	TelegramID string `json:"telegram_id"` // Poll ID reported in poll_answer updates
+}
+
+// AddPoll starts a poll between events and returns it
+func (u *UserPreferences) AddPoll(eventIDs []string, now time.Time) *EventPoll {
+	poll := &EventPoll{
+		ID:       randomCode(pollIDLength),
+		EventIDs: eventIDs,
+		Created:  now.Unix(),
+	}
+	u.Polls = append(u.Polls, poll)
+	if len(u.Polls) > MaxPolls {
+		u.Polls = u.Polls[len(u.Polls)-MaxPolls:]
+	}
+	return poll
+}
+
+// FindPoll returns the user's poll with the given ID
+func (u *UserPreferences) FindPoll(id string) *EventPoll {
+	for _, poll := range u.Polls {
+		if poll.ID == id {
+			return poll
+		}
+	}
+	return nil
+}
+
+// LatestOpenPoll returns the user's most recent poll that hasn't been closed
+func (u *UserPreferences) LatestOpenPoll() *EventPoll {
+	for i := len(u.Polls) - 1; i >= 0; i-- {
+		if !u.Polls[i].Closed {
+			return u.Polls[i]
+		}
+	}
+	return nil
+}
+
+// FindPollByID returns the poll with the given ID and the chat that started it
+func (p Preferences) FindPollByID(id string) (string, *EventPoll) {
+	for chatID, user := range p {
+		if poll := user.FindPoll(id); poll != nil {
+			return chatID, poll
+		}
+	}
+	return "", nil
+}
+
+// FindTelegramPoll returns the poll one of whose copies has the given Telegram poll ID,
+// and the chat that started it
+func (p Preferences) FindTelegramPoll(telegramID string) (string, *EventPoll) {
+	if telegramID == "" {
+		return "", nil
+	}
+	for chatID, user := range p {
+		for _, poll := range user.Polls {
+			for _, m := range poll.Messages {
+				if m.TelegramID == telegramID {
+					return chatID, poll
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// RecordVote records a voter's choice, or removes it when optionIDs is empty (a
+// retracted vote). It returns false if the poll is closed or the option is unknown.
+func (poll *EventPoll) RecordVote(voterID string, optionIDs []int) bool {
+	if poll.Closed {
+		return false
+	}
+	if len(optionIDs) == 0 {
+		if _, ok := poll.Votes[voterID]; !ok {
+			return false
+		}
+		delete(poll.Votes, voterID)
+		return true
+	}
+	option := optionIDs[0]
+	if option < 0 || option >= len(poll.EventIDs) {
+		return false
+	}
+	if poll.Votes == nil {
+		poll.Votes = make(map[string]int)
+	}
+	poll.Votes[voterID] = option
+	return true
+}
+
+// Tally returns the number of votes for each option
+func (poll *EventPoll) Tally() []int {
+	counts := make([]int, len(poll.EventIDs))
+	for _, option := range poll.Votes {
+		if option >= 0 && option < len(counts) {
+			counts[option]++
+		}
+	}
+	return counts
+}
+
+// Winner returns the option with the most votes, whether other options tied with it
+// (the earliest listed wins a tie), and false if nobody voted
+func (poll *EventPoll) Winner() (option int, tied, ok bool) {
+	counts := poll.Tally()
+	best := -1
+	for i, count := range counts {
+		switch {
+		case count == 0:
+		case best < 0 || count > counts[best]:
+			best, tied = i, false
+		case count == counts[best]:
+			tied = true
+		}
+	}
+	return best, tied, best >= 0
+}
+
+// Voters returns the chat IDs of everyone who voted, sorted
+func (poll *EventPoll) Voters() []string {
+	voters := make([]string, 0, len(poll.Votes))
+	for voterID := range poll.Votes {
+		voters = append(voters, voterID)
+	}
+	sort.Strings(voters)
+	return voters
+}
@@ -0,0 +1,62 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventPollVoting(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("111")
+	poll := user.AddPoll([]string{"evt1", "evt2", "evt3"}, time.Now())
+	poll.Messages = []PollMessage{{ChatID: "111", MessageID: 5, TelegramID: "tg-1"}, {ChatID: "222", MessageID: 9, TelegramID: "tg-2"}}
+
+	if owner, found := prefs.FindTelegramPoll("tg-2"); found != poll || owner != "111" {
+		t.Fatalf("FindTelegramPoll() = %q, %v", owner, found)
+	}
+	if owner, found := prefs.FindPollByID(poll.ID); found != poll || owner != "111" {
+		t.Fatalf("FindPollByID() = %q, %v", owner, found)
+	}
+
+	if _, _, ok := poll.Winner(); ok {
+		t.Error("a poll without votes has no winner")
+	}
+
+	poll.RecordVote("111", []int{1})
+	poll.RecordVote("222", []int{2})
+	poll.RecordVote("333", []int{2})
+	if poll.RecordVote("444", []int{7}) {
+		t.Error("a vote for an unknown option should be ignored")
+	}
+	if winner, tied, ok := poll.Winner(); !ok || winner != 2 || tied {
+		t.Errorf("Winner() = %d, %v, %v; want option 2", winner, tied, ok)
+	}
+
+	// 333 retracts their vote, leaving a tie the first listed option wins
+	poll.RecordVote("333", nil)
+	if winner, tied, _ := poll.Winner(); winner != 1 || !tied {
+		t.Errorf("Winner() after retraction = %d, tied %v; want 1, tied", winner, tied)
+	}
+	if voters := poll.Voters(); len(voters) != 2 || voters[0] != "111" {
+		t.Errorf("Voters() = %v", voters)
+	}
+
+	poll.Closed = true
+	if poll.RecordVote("333", []int{0}) {
+		t.Error("a closed poll should not accept votes")
+	}
+	if user.LatestOpenPoll() != nil {
+		t.Error("LatestOpenPoll() should skip closed polls")
+	}
+}
+
+func TestAddPollCap(t *testing.T) {
+	user := NewPreferences().GetUser("111")
+	first := user.AddPoll([]string{"a", "b"}, time.Now())
+	for range MaxPolls {
+		user.AddPoll([]string{"a", "b"}, time.Now())
+	}
+	if len(user.Polls) != MaxPolls || user.FindPoll(first.ID) != nil {
+		t.Errorf("kept %d polls, want the latest %d", len(user.Polls), MaxPolls)
+	}
+}
@@ -1,13 +1,17 @@
 package preferences
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/clock"
 	"github.com/pfrederiksen/vga-events/internal/event"
 	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/league"
 )
 
 const (
@@ -21,13 +25,50 @@ const (
 	EventStatusRegistered = "registered"
 	EventStatusMaybe      = "maybe"
 	EventStatusSkip       = "skip"
+
+	// MaxStatusHistory is how many status changes are kept per event
+	MaxStatusHistory = 10
+
+	// DefaultArchiveAfterDays is how long after an event's date its status and note stay live
+	DefaultArchiveAfterDays = 30
 )
 
+// clk is the time source for methods that don't take the time, such as GetUser's new
+// weekly stats and status history; binaries pass their clock to SetClock
+var clk clock.Clock = clock.System
+
+// SetClock sets the clock the package reads the current time from
+func SetClock(c clock.Clock) {
+	clk = c
+}
+
+// StatusChange records one status transition for an event
+type StatusChange struct {
+	From string    `json:"from,omitempty"` // Previous status ("" if none)
+	To   string    `json:"to,omitempty"`   // New status ("" when cleared)
+	At   time.Time `json:"at"`
+}
+
+// ArchivedEvent is the compact record kept for a tracked event after it's archived
+type ArchivedEvent struct {
+	Title      string    `json:"title,omitempty"`
+	Date       string    `json:"date,omitempty"`
+	Status     string    `json:"status,omitempty"`   // Last status before archiving
+	Note       string    `json:"note,omitempty"`     // Personal note, if any
+	Statuses   []string  `json:"statuses,omitempty"` // Statuses the event went through, oldest first
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
 // UserPreferences represents a user's subscription preferences
 type UserPreferences struct {
 	// Core subscription settings
-	States []string `json:"states"`
-	Active bool     `json:"active"`
+	States          []string             `json:"states"`
+	Cities          []CitySubscription   `json:"cities,omitempty"`
+	FollowedCourses []string             `json:"followed_courses,omitempty"` // Events here notify regardless of state
+	Travel          []TravelSubscription `json:"travel,omitempty"`           // Temporary state subscriptions for trips
+	Majors          bool                 `json:"majors,omitempty"`           // National/majors events, in any state
+	Home            *CitySubscription    `json:"home,omitempty"`             // For "miles from home" hints; RadiusMiles is unused
+	Active          bool                 `json:"active"`
 
 	// Event history tracking (Feature 1)
 	// Key: event.ID, Value: Unix timestamp when first seen
@@ -36,17 +77,27 @@ type UserPreferences struct {
 	// Digest mode configuration (Feature 4)
 	DigestFrequency string         `json:"digest_frequency,omitempty"`   // "immediate", "daily", "weekly"
 	DigestDayOfWeek int            `json:"digest_day_of_week,omitempty"` // 0-6 for weekly digest
-	DigestHour      int            `json:"digest_hour,omitempty"`        // 0-23 UTC
+	DigestHour      int            `json:"digest_hour,omitempty"`        // 0-23 in DigestTimezone
+	DigestTimezone  string         `json:"digest_timezone,omitempty"`    // IANA name; "" = UTC, never set with /settings time
 	PendingEvents   []*event.Event `json:"pending_events,omitempty"`     // Events queued for digest
+	PausedUntil     int64          `json:"paused_until,omitempty"`       // Unix time notifications resume, 0 = not paused
 
 	// Time-based filtering (Feature 3)
-	DaysAhead      int  `json:"days_ahead,omitempty"`       // 0 = disabled, >0 = only show events within N days
-	HidePastEvents bool `json:"hide_past_events,omitempty"` // Default: true
+	DaysAhead      int  `json:"days_ahead,omitempty"` // 0 = disabled, >0 = only show events within N days
+	HidePastEvents bool `json:"hide_past_events"`     // Default: true
 
 	// Event status tracking (Feature 9)
 	// Key: event.ID, Value: status ("interested", "registered", "maybe", "skip")
 	EventStatuses map[string]string `json:"event_statuses,omitempty"`
 
+	// Status change history per event, oldest first (capped at MaxStatusHistory)
+	// Key: event.ID, Value: transitions such as interested → registered
+	StatusHistory map[string][]StatusChange `json:"status_history,omitempty"`
+
+	// Archived statuses and notes for events long past or removed, kept out of the live maps
+	// Key: event.ID, Value: compact record
+	ArchivedEvents map[string]*ArchivedEvent `json:"archived_events,omitempty"`
+
 	// Event reminders (Week 3)
 	// Days before event to send reminders (e.g., [1, 3, 7] means 1 day, 3 days, and 1 week before)
 	ReminderDays []int `json:"reminder_days,omitempty"`
@@ -55,6 +106,13 @@ type UserPreferences struct {
 	// Key: event.ID, Value: user's personal note
 	EventNotes map[string]string `json:"event_notes,omitempty"`
 
+	// Photos and files attached to event notes (e.g. a pairing sheet)
+	// Key: event.ID, Value: Telegram file reference
+	NoteAttachments map[string]*NoteAttachment `json:"note_attachments,omitempty"`
+
+	// The note a voice message sent next is added to (set by /note <event_id>)
+	PendingNote *PendingNote `json:"pending_note,omitempty"`
+
 	// Change notifications (v0.5.0 Enhancement #3)
 	// Whether to be notified when tracked events change (date, title, city)
 	NotifyOnChanges bool `json:"notify_on_changes"` // Default: true
@@ -68,31 +126,86 @@ type UserPreferences struct {
 	StatsHistory map[string]*WeeklyStats `json:"stats_history,omitempty"` // week key → stats
 	EnableStats  bool                    `json:"enable_stats"`            // Default: true
 
+	// Button taps on A/B experiment messages, keyed by ExperimentKey
+	ExperimentTaps map[string]int `json:"experiment_taps,omitempty"`
+
 	// Friends and sharing (v0.5.0 Enhancement #7)
 	FriendChatIDs      []string            `json:"friend_chat_ids,omitempty"`     // List of friend chat IDs
 	PendingInvites     map[string]string   `json:"pending_invites,omitempty"`     // invite code → sender chat ID
-	ShareEvents        bool                `json:"share_events,omitempty"`        // Default: false (privacy)
+	ShareEvents        bool                `json:"share_events,omitempty"`        // Default: false (privacy); used when SharingDefault is unset
+	SharingDefault     string              `json:"sharing_default,omitempty"`     // Sharing level for friends without their own
+	FriendSharing      map[string]string   `json:"friend_sharing,omitempty"`      // friend chat ID → sharing level
 	InviteCode         string              `json:"invite_code,omitempty"`         // This user's invite code
+	InviteExpires      int64               `json:"invite_expires,omitempty"`      // Unix time the invite code expires, 0 = never
+	InviteSingleUse    bool                `json:"invite_single_use,omitempty"`   // Replace the invite code once someone joins with it
 	GroupSubscriptions map[string][]string `json:"group_subscriptions,omitempty"` // group ID → member chat IDs
+	FriendRequests     map[string]int64    `json:"friend_requests,omitempty"`     // requester chat ID → Unix time, awaiting approval
+	BlockedChatIDs     []string            `json:"blocked_chat_ids,omitempty"`    // Chats whose friend requests are ignored
+
+	// Discussion messages this user left for friends, per event
+	// Key: event.ID, Value: messages, oldest first (capped at MaxCommentsPerEvent)
+	Comments map[string][]Comment `json:"comments,omitempty"`
+
+	// Lines this user added to events' group notes, shared with their friends
+	// Key: event.ID, Value: lines, oldest first (capped at MaxGroupNoteLines)
+	GroupNotes map[string][]Comment `json:"group_notes,omitempty"`
+
+	// Polls started with /poll, oldest first (capped at MaxPolls)
+	Polls []*EventPoll `json:"polls,omitempty"`
+
+	// Season series this chat runs as the league admin (capped at MaxLeagues)
+	Leagues []*league.League `json:"leagues,omitempty"`
+
+	// Household linking: linked chats share event statuses, notes, and seen history
+	LinkedChatIDs   []string `json:"linked_chat_ids,omitempty"`   // Other chats in this chat's household
+	LinkCode        string   `json:"link_code,omitempty"`         // One-time code for /link
+	LinkCodeExpires int64    `json:"link_code_expires,omitempty"` // Unix time the link code expires
+
+	// Personal API token for the HTTP API; only its hash is stored
+	APITokenHash    string `json:"api_token_hash,omitempty"`
+	APITokenCreated int64  `json:"api_token_created,omitempty"` // Unix time the token was created
 
 	// Event filtering (v0.7.0)
 	SavedFilters map[string]*filter.FilterPreset `json:"saved_filters,omitempty"` // name → filter preset
 	ActiveFilter string                          `json:"active_filter,omitempty"` // name of active filter
+
+	// Saved filters delivered on a schedule
+	ScheduledReports []*ScheduledReport `json:"scheduled_reports,omitempty"`
+
+	// Event cards the bot sent, kept up to date by vga-events-bot --refresh-cards
+	EventCards []*EventCard `json:"event_cards,omitempty"`
+
+	// Bulk select mode: events checked in the selection list, kept between bot runs
+	SelectedEventIDs []string `json:"selected_event_ids,omitempty"`
+
+	// Personal command shortcuts: alias name (no slash) → command it expands to
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Inactivity tracking for re-engagement (Unix times, 0 = never)
+	LastInteraction int64 `json:"last_interaction,omitempty"` // Last message or button tap, to the day
+	ReengageSentAt  int64 `json:"reengage_sent_at,omitempty"` // When "still want notifications?" was sent
+	DeactivatedAt   int64 `json:"deactivated_at,omitempty"`   // When the user was deactivated for not answering it
 }
 
 // WeeklyStats tracks user engagement metrics for a week
 type WeeklyStats struct {
-	WeekStart        time.Time      `json:"week_start"`
-	EventsViewed     int            `json:"events_viewed"`
-	EventsMarked     map[string]int `json:"events_marked"`     // status → count
-	EventsRegistered int            `json:"events_registered"` // Count of events marked as registered
-	TopStates        []string       `json:"top_states"`        // States with most activity
+	WeekStart        time.Time       `json:"week_start"`
+	EventsViewed     int             `json:"events_viewed"`
+	EventsMarked     map[string]int  `json:"events_marked,omitempty"` // status → count
+	EventsRegistered int             `json:"events_registered"`       // Count of events marked as registered
+	TopStates        []string        `json:"top_states,omitempty"`    // States with most activity
+	Cohorts          map[string]bool `json:"cohorts,omitempty"`       // Feature flag → whether the user had it, for flags in a staged rollout
 }
 
 // NewWeeklyStats creates a new WeeklyStats for the current week
 func NewWeeklyStats() *WeeklyStats {
+	return NewWeeklyStatsAt(clk.Now())
+}
+
+// NewWeeklyStatsAt creates a new WeeklyStats for a week starting on now's day (UTC)
+func NewWeeklyStatsAt(now time.Time) *WeeklyStats {
 	return &WeeklyStats{
-		WeekStart:    time.Now().UTC().Truncate(24 * time.Hour), // Start of today
+		WeekStart:    now.UTC().Truncate(24 * time.Hour), // Start of the day
 		EventsViewed: 0,
 		EventsMarked: make(map[string]int),
 	}
@@ -101,10 +214,11 @@ func NewWeeklyStats() *WeeklyStats {
 // Preferences maps chat IDs to user preferences
 type Preferences map[string]*UserPreferences
 
-// Storage defines the interface for preferences storage
+// Storage defines the interface for preferences storage.
+// Implementations should abandon the load or save when ctx is canceled.
 type Storage interface {
-	Load() (Preferences, error)
-	Save(prefs Preferences) error
+	Load(ctx context.Context) (Preferences, error)
+	Save(ctx context.Context, prefs Preferences) error
 }
 
 // NewPreferences creates a new empty preferences map
@@ -112,6 +226,29 @@ func NewPreferences() Preferences {
 	return make(Preferences)
 }
 
+// UnmarshalJSON reads a user's preferences, turning on the settings that default to
+// on when they were saved before the setting existed. A saved false is kept, so
+// switching one off in /settings sticks.
+func (u *UserPreferences) UnmarshalJSON(data []byte) error {
+	type plain UserPreferences // Without this method
+	aux := struct {
+		*plain
+		HidePastEvents  *bool `json:"hide_past_events"`
+		NotifyOnChanges *bool `json:"notify_on_changes"`
+		NotifyOnRemoval *bool `json:"notify_on_removal"`
+		EnableStats     *bool `json:"enable_stats"`
+	}{plain: (*plain)(u)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	u.HidePastEvents = aux.HidePastEvents == nil || *aux.HidePastEvents
+	u.NotifyOnChanges = aux.NotifyOnChanges == nil || *aux.NotifyOnChanges
+	u.NotifyOnRemoval = aux.NotifyOnRemoval == nil || *aux.NotifyOnRemoval
+	u.EnableStats = aux.EnableStats == nil || *aux.EnableStats
+	return nil
+}
+
 // GetUser retrieves preferences for a specific user, creating them if they don't exist.
 // For existing users, initializes new fields with default values (migration).
 func (p Preferences) GetUser(chatID string) *UserPreferences {
@@ -125,10 +262,12 @@ func (p Preferences) GetUser(chatID string) *UserPreferences {
 		if user.DigestFrequency == "" {
 			user.DigestFrequency = DigestFrequencyImmediate // Keep current behavior
 		}
-		if user.DigestHour == 0 && user.DigestFrequency != DigestFrequencyImmediate {
+		// A digest time set with /settings time always has a time zone, so midnight and
+		// Sunday stay as chosen
+		if user.DigestHour == 0 && user.DigestFrequency != DigestFrequencyImmediate && user.DigestTimezone == "" {
 			user.DigestHour = 9 // 9 AM UTC default
 		}
-		if user.DigestDayOfWeek == 0 && user.DigestFrequency == "weekly" {
+		if user.DigestDayOfWeek == 0 && user.DigestFrequency == "weekly" && user.DigestTimezone == "" {
 			user.DigestDayOfWeek = 1 // Monday default
 		}
 		if user.EventStatuses == nil {
@@ -137,14 +276,6 @@ func (p Preferences) GetUser(chatID string) *UserPreferences {
 		if user.EventNotes == nil {
 			user.EventNotes = make(map[string]string)
 		}
-		// Migration: enable change notifications for existing users (if they have tracked events)
-		if !user.NotifyOnChanges && len(user.EventStatuses) > 0 {
-			user.NotifyOnChanges = true
-		}
-		// Migration: enable removal notifications for existing users
-		if !user.NotifyOnRemoval && len(user.States) > 0 {
-			user.NotifyOnRemoval = true
-		}
 		// Migration: initialize weekly stats for existing users
 		if user.WeeklyStats == nil {
 			user.WeeklyStats = NewWeeklyStats()
@@ -152,9 +283,6 @@ func (p Preferences) GetUser(chatID string) *UserPreferences {
 		if user.StatsHistory == nil {
 			user.StatsHistory = make(map[string]*WeeklyStats)
 		}
-		if !user.EnableStats && len(user.States) > 0 {
-			user.EnableStats = true // Enable for existing users
-		}
 		// Migration: initialize friend fields for existing users
 		if user.FriendChatIDs == nil {
 			user.FriendChatIDs = []string{}
@@ -162,8 +290,9 @@ func (p Preferences) GetUser(chatID string) *UserPreferences {
 		if user.PendingInvites == nil {
 			user.PendingInvites = make(map[string]string)
 		}
-		if user.InviteCode == "" {
-			user.InviteCode = generateInviteCode(chatID)
+		// Migration: replace codes derived from the chat ID with random ones
+		if user.InviteCode == "" || isLegacyInviteCode(chatID, user.InviteCode) {
+			user.InviteCode = randomCode(inviteCodeLength)
 		}
 		if user.GroupSubscriptions == nil {
 			user.GroupSubscriptions = make(map[string][]string)
@@ -173,8 +302,8 @@ func (p Preferences) GetUser(chatID string) *UserPreferences {
 			user.SavedFilters = make(map[string]*filter.FilterPreset)
 		}
 		// Note: ShareEvents defaults to false (zero value) - user must opt in
-		// Note: HidePastEvents defaults to false (zero value) for backward compatibility
-		// Users can enable it via settings
+		// Note: HidePastEvents, NotifyOnChanges, NotifyOnRemoval, and EnableStats saved
+		// before they existed default to true in UnmarshalJSON
 		return user
 	}
 
@@ -200,7 +329,7 @@ func (p Preferences) GetUser(chatID string) *UserPreferences {
 		FriendChatIDs:      []string{}, // New feature: friends list
 		PendingInvites:     make(map[string]string),
 		ShareEvents:        false, // Privacy: opt-in only
-		InviteCode:         generateInviteCode(chatID),
+		InviteCode:         randomCode(inviteCodeLength),
 		GroupSubscriptions: make(map[string][]string),
 		SavedFilters:       make(map[string]*filter.FilterPreset), // New feature: saved filters
 		ActiveFilter:       "",                                    // No active filter by default
@@ -268,11 +397,12 @@ func (p Preferences) HasState(chatID, state string) bool {
 	return false
 }
 
-// GetAllUsers returns all chat IDs with active subscriptions
+// GetAllUsers returns all chat IDs with active state, city, course, travel, or majors
+// subscriptions
 func (p Preferences) GetAllUsers() []string {
 	users := make([]string, 0, len(p))
 	for chatID, user := range p {
-		if user.Active && len(user.States) > 0 {
+		if user.Active && (len(user.States) > 0 || len(user.Cities) > 0 || len(user.FollowedCourses) > 0 || len(user.Travel) > 0 || user.Majors) {
 			users = append(users, chatID)
 		}
 	}
@@ -324,29 +454,42 @@ func IsValidState(state string) bool {
 func GetStateName(code string) string {
 	code = strings.ToUpper(strings.TrimSpace(code))
 
-	stateNames := map[string]string{
-		"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas",
-		"CA": "California", "CO": "Colorado", "CT": "Connecticut", "DE": "Delaware",
-		"FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho",
-		"IL": "Illinois", "IN": "Indiana", "IA": "Iowa", "KS": "Kansas",
-		"KY": "Kentucky", "LA": "Louisiana", "ME": "Maine", "MD": "Maryland",
-		"MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota", "MS": "Mississippi",
-		"MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
-		"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York",
-		"NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma",
-		"OR": "Oregon", "PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina",
-		"SD": "South Dakota", "TN": "Tennessee", "TX": "Texas", "UT": "Utah",
-		"VT": "Vermont", "VA": "Virginia", "WA": "Washington", "WV": "West Virginia",
-		"WI": "Wisconsin", "WY": "Wyoming", "DC": "Washington, D.C.",
-		"ALL": "All States",
-	}
-
 	if name, exists := stateNames[code]; exists {
 		return name
 	}
 	return code
 }
 
+// StateCodes returns every valid state code (without ALL), sorted by state name
+func StateCodes() []string {
+	codes := make([]string, 0, len(stateNames)-1)
+	for code := range stateNames {
+		if code != "ALL" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return stateNames[codes[i]] < stateNames[codes[j]] })
+	return codes
+}
+
+// stateNames maps each state code to its full name
+var stateNames = map[string]string{
+	"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas",
+	"CA": "California", "CO": "Colorado", "CT": "Connecticut", "DE": "Delaware",
+	"FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho",
+	"IL": "Illinois", "IN": "Indiana", "IA": "Iowa", "KS": "Kansas",
+	"KY": "Kentucky", "LA": "Louisiana", "ME": "Maine", "MD": "Maryland",
+	"MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota", "MS": "Mississippi",
+	"MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
+	"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York",
+	"NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma",
+	"OR": "Oregon", "PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina",
+	"SD": "South Dakota", "TN": "Tennessee", "TX": "Texas", "UT": "Utah",
+	"VT": "Vermont", "VA": "Virginia", "WA": "Washington", "WV": "West Virginia",
+	"WI": "Wisconsin", "WY": "Wyoming", "DC": "Washington, D.C.",
+	"ALL": "All States",
+}
+
 // CleanupOldHistory removes event history entries older than the specified number of days.
 // This prevents SeenEventIDs from growing unbounded.
 func (u *UserPreferences) CleanupOldHistory(daysToKeep int) int {
@@ -354,7 +497,7 @@ func (u *UserPreferences) CleanupOldHistory(daysToKeep int) int {
 		return 0
 	}
 
-	cutoff := time.Now().AddDate(0, 0, -daysToKeep).Unix()
+	cutoff := clk.Now().AddDate(0, 0, -daysToKeep).Unix()
 	removed := 0
 
 	for eventID, timestamp := range u.SeenEventIDs {
@@ -372,7 +515,7 @@ func (u *UserPreferences) MarkEventSeen(eventID string) {
 	if u.SeenEventIDs == nil {
 		u.SeenEventIDs = make(map[string]int64)
 	}
-	u.SeenEventIDs[eventID] = time.Now().Unix()
+	u.SeenEventIDs[eventID] = clk.Now().Unix()
 }
 
 // HasSeenEvent checks if a user has already seen a specific event.
@@ -421,6 +564,10 @@ func (u *UserPreferences) SetEventStatus(eventID, status string) bool {
 		u.EventStatuses = make(map[string]string)
 	}
 
+	if previous := u.EventStatuses[eventID]; previous != status {
+		u.recordStatusChange(eventID, previous, status)
+	}
+
 	u.EventStatuses[eventID] = status
 	return true
 }
@@ -436,10 +583,133 @@ func (u *UserPreferences) GetEventStatus(eventID string) string {
 // RemoveEventStatus removes the status for an event.
 func (u *UserPreferences) RemoveEventStatus(eventID string) {
 	if u.EventStatuses != nil {
+		if previous, ok := u.EventStatuses[eventID]; ok {
+			u.recordStatusChange(eventID, previous, "")
+		}
 		delete(u.EventStatuses, eventID)
 	}
 }
 
+// recordStatusChange appends a transition to the event's status history
+func (u *UserPreferences) recordStatusChange(eventID, from, to string) {
+	if u.StatusHistory == nil {
+		u.StatusHistory = make(map[string][]StatusChange)
+	}
+
+	history := append(u.StatusHistory[eventID], StatusChange{From: from, To: to, At: clk.Now().UTC()})
+	if len(history) > MaxStatusHistory {
+		history = history[len(history)-MaxStatusHistory:]
+	}
+	u.StatusHistory[eventID] = history
+}
+
+// GetStatusHistory returns the status changes recorded for an event, oldest first
+func (u *UserPreferences) GetStatusHistory(eventID string) []StatusChange {
+	if u.StatusHistory == nil {
+		return nil
+	}
+	return u.StatusHistory[eventID]
+}
+
+// StatusConversion counts events that were ever marked with the from status,
+// and how many of those were later marked with the to status
+// (e.g., interested → registered conversion). Archived events are included.
+func (u *UserPreferences) StatusConversion(from, to string) (converted, total int) {
+	count := func(statuses []string) {
+		sawFrom := false
+		reachedTo := false
+		for _, status := range statuses {
+			if status == from {
+				sawFrom = true
+			} else if sawFrom && status == to {
+				reachedTo = true
+			}
+		}
+		if sawFrom {
+			total++
+			if reachedTo {
+				converted++
+			}
+		}
+	}
+
+	for _, history := range u.StatusHistory {
+		count(historyStatuses(history))
+	}
+	for _, archived := range u.ArchivedEvents {
+		count(archived.Statuses)
+	}
+	return converted, total
+}
+
+// historyStatuses returns the statuses an event went through, oldest first (clears omitted)
+func historyStatuses(history []StatusChange) []string {
+	var statuses []string
+	for _, change := range history {
+		if change.To != "" {
+			statuses = append(statuses, change.To)
+		}
+	}
+	return statuses
+}
+
+// TrackedEventIDs returns the IDs of events with a status, note, or status history, sorted
+func (u *UserPreferences) TrackedEventIDs() []string {
+	seen := make(map[string]bool)
+	for id := range u.EventStatuses {
+		seen[id] = true
+	}
+	for id := range u.EventNotes {
+		seen[id] = true
+	}
+	for id := range u.NoteAttachments {
+		seen[id] = true
+	}
+	for id := range u.StatusHistory {
+		seen[id] = true
+	}
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ArchiveEvent moves an event's status, note, and status history into ArchivedEvents.
+// Title and date are kept for reference since the event may no longer be listed.
+// Returns false if nothing was tracked for the event.
+func (u *UserPreferences) ArchiveEvent(eventID, title, date string) bool {
+	status := u.EventStatuses[eventID]
+	note := u.EventNotes[eventID]
+	history := u.StatusHistory[eventID]
+	if status == "" && note == "" && len(history) == 0 {
+		return false
+	}
+
+	if u.ArchivedEvents == nil {
+		u.ArchivedEvents = make(map[string]*ArchivedEvent)
+	}
+	u.ArchivedEvents[eventID] = &ArchivedEvent{
+		Title:      title,
+		Date:       date,
+		Status:     status,
+		Note:       note,
+		Statuses:   historyStatuses(history),
+		ArchivedAt: clk.Now().UTC(),
+	}
+
+	delete(u.EventStatuses, eventID)
+	delete(u.EventNotes, eventID)
+	delete(u.NoteAttachments, eventID)
+	delete(u.StatusHistory, eventID)
+	if u.IsEventSelected(eventID) {
+		u.ToggleEventSelection(eventID)
+	}
+	return true
+}
+
 // GetEventsByStatus returns all event IDs with a specific status.
 func (u *UserPreferences) GetEventsByStatus(status string) []string {
 	if u.EventStatuses == nil {
@@ -539,6 +809,22 @@ func (u *UserPreferences) IncrementEventStatus(status string) {
 	}
 }
 
+// ExperimentKey returns the ExperimentTaps key for an experiment's variant
+func ExperimentKey(experiment, variant string) string {
+	return experiment + "/" + variant
+}
+
+// RecordExperimentTap counts a button tap on a message from an experiment variant
+func (u *UserPreferences) RecordExperimentTap(experiment, variant string) {
+	if !u.EnableStats {
+		return
+	}
+	if u.ExperimentTaps == nil {
+		u.ExperimentTaps = make(map[string]int)
+	}
+	u.ExperimentTaps[ExperimentKey(experiment, variant)]++
+}
+
 // GetWeekKey generates a week key for stats history (format: "2026-W01")
 func GetWeekKey(t time.Time) string {
 	year, week := t.ISOWeek()
@@ -547,6 +833,12 @@ func GetWeekKey(t time.Time) string {
 
 // ArchiveCurrentWeek moves current week stats to history and starts a new week
 func (u *UserPreferences) ArchiveCurrentWeek() {
+	u.ArchiveCurrentWeekAt(clk.Now())
+}
+
+// ArchiveCurrentWeekAt moves current week stats to history, keyed by the week they
+// started in, and starts a new week at now
+func (u *UserPreferences) ArchiveCurrentWeekAt(now time.Time) {
 	if u.WeeklyStats == nil {
 		return
 	}
@@ -561,7 +853,25 @@ func (u *UserPreferences) ArchiveCurrentWeek() {
 	u.StatsHistory[weekKey] = u.WeeklyStats
 
 	// Start new week
-	u.WeeklyStats = NewWeeklyStats()
+	u.WeeklyStats = NewWeeklyStatsAt(now)
+}
+
+// TrackingWeeks returns how many weeks stats have been tracked, counted from the oldest
+// recorded week (weeks without activity may have been compacted away)
+func (u *UserPreferences) TrackingWeeks() int {
+	if u.WeeklyStats == nil {
+		return len(u.StatsHistory)
+	}
+
+	oldest := u.WeeklyStats.WeekStart
+	for _, stats := range u.StatsHistory {
+		if stats != nil && !stats.WeekStart.IsZero() && stats.WeekStart.Before(oldest) {
+			oldest = stats.WeekStart
+		}
+	}
+
+	weeks := int(u.WeeklyStats.WeekStart.Sub(oldest).Hours()/(24*7)) + 1 // +1 for current week
+	return max(weeks, len(u.StatsHistory)+1)
 }
 
 // GetAllTimeStats aggregates stats from all history plus current week
@@ -592,16 +902,6 @@ func (u *UserPreferences) GetAllTimeStats() *WeeklyStats {
 
 // Friend Management Methods
 
-// generateInviteCode generates a unique invite code for a user
-func generateInviteCode(chatID string) string {
-	// Use last 6 characters of chat ID for simplicity
-	// In production, could use a proper random code generator
-	if len(chatID) >= 6 {
-		return chatID[len(chatID)-6:]
-	}
-	return chatID
-}
-
 // AddFriend adds a friend to the user's friend list
 func (u *UserPreferences) AddFriend(friendChatID string) bool {
 	// Check if already friends
@@ -653,30 +953,6 @@ func (u *UserPreferences) CreatePendingInvite() string {
 	return u.InviteCode
 }
 
-// GetFriendsForEvent returns list of friend chat IDs who are registered/interested in an event
-func (p Preferences) GetFriendsForEvent(chatID, eventID string) []string {
-	user := p.GetUser(chatID)
-	if !user.ShareEvents {
-		return []string{} // Privacy: sharing disabled
-	}
-
-	var friendsForEvent []string
-	for _, friendChatID := range user.FriendChatIDs {
-		friend := p.GetUser(friendChatID)
-		if !friend.ShareEvents {
-			continue // Friend has sharing disabled
-		}
-
-		// Check if friend is registered or interested
-		status := friend.GetEventStatus(eventID)
-		if status == EventStatusInterested || status == EventStatusRegistered {
-			friendsForEvent = append(friendsForEvent, friendChatID)
-		}
-	}
-
-	return friendsForEvent
-}
-
 // Filter Management Methods
 
 // SaveFilter saves a filter preset with the given name
@@ -688,7 +964,7 @@ func (u *UserPreferences) SaveFilter(name string, f *filter.Filter) {
 	// Check if filter already exists (update case)
 	if existing, exists := u.SavedFilters[name]; exists {
 		existing.Filter = f
-		existing.UpdatedAt = time.Now().UTC()
+		existing.UpdatedAt = clk.Now().UTC()
 	} else {
 		// Create new preset
 		u.SavedFilters[name] = filter.NewFilterPreset(name, f)
@@ -726,6 +1002,7 @@ func (u *UserPreferences) DeleteFilter(name string) bool {
 	if u.ActiveFilter == name {
 		u.ActiveFilter = ""
 	}
+	u.UnscheduleReport(name)
 
 	return true
 }
@@ -789,3 +1066,31 @@ func (u *UserPreferences) ApplyFiltersToEvents(events []*event.Event) []*event.E
 
 	return activeFilter.Apply(events)
 }
+
+// IsEventSelected reports whether an event is checked in select mode
+func (u *UserPreferences) IsEventSelected(eventID string) bool {
+	for _, id := range u.SelectedEventIDs {
+		if id == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleEventSelection checks or unchecks an event in select mode.
+// Returns true if the event is now selected.
+func (u *UserPreferences) ToggleEventSelection(eventID string) bool {
+	for i, id := range u.SelectedEventIDs {
+		if id == eventID {
+			u.SelectedEventIDs = append(u.SelectedEventIDs[:i], u.SelectedEventIDs[i+1:]...)
+			return false
+		}
+	}
+	u.SelectedEventIDs = append(u.SelectedEventIDs, eventID)
+	return true
+}
+
+// ClearEventSelection unchecks all events in select mode
+func (u *UserPreferences) ClearEventSelection() {
+	u.SelectedEventIDs = nil
+}
@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/clock"
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/testutil"
 )
 
 func TestPreferences(t *testing.T) {
@@ -809,6 +811,112 @@ func TestArchiveCurrentWeek(t *testing.T) {
 	}
 }
 
+func TestWeeklyRollover(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time // The first week's start; the rollover runs a week later each time
+		keys  []string
+	}{
+		{
+			// Sunday Dec 27 2026 is in ISO week 52, Sunday Jan 3 2027 still in 2026's week 53
+			name:  "year boundary",
+			start: time.Date(2026, 12, 27, 23, 59, 0, 0, time.UTC),
+			keys:  []string{"2026-W52", "2026-W53", "2027-W01"},
+		},
+		{
+			// Rolling over at the same local time each week crosses the spring-forward change
+			name:  "daylight saving",
+			start: time.Date(2026, 3, 1, 15, 59, 0, 0, la),
+			keys:  []string{"2026-W09", "2026-W10", "2026-W11"},
+		},
+	}
+
+	for _, tt := range tests {
+		clk := testutil.NewFakeClock(tt.start)
+		user := &UserPreferences{EnableStats: true, WeeklyStats: NewWeeklyStatsAt(clk.Now())}
+		for i := range tt.keys {
+			user.IncrementEventsViewed(i + 1)
+			clk.AddDate(0, 0, 7)
+			user.ArchiveCurrentWeekAt(clk.Now())
+		}
+
+		if len(user.StatsHistory) != len(tt.keys) {
+			t.Errorf("%s: archived %d weeks, want %d: %v", tt.name, len(user.StatsHistory), len(tt.keys), user.StatsHistory)
+		}
+		for i, key := range tt.keys {
+			if week := user.StatsHistory[key]; week == nil || week.EventsViewed != i+1 {
+				t.Errorf("%s: week %s = %+v, want %d events viewed", tt.name, key, week, i+1)
+			}
+		}
+		if want := clk.Now().UTC().Truncate(24 * time.Hour); !user.WeeklyStats.WeekStart.Equal(want) {
+			t.Errorf("%s: new week starts %v, want %v", tt.name, user.WeeklyStats.WeekStart, want)
+		}
+	}
+}
+
+func TestWeeklyStatsFollowClock(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	t.Cleanup(func() { SetClock(clock.System) })
+
+	tests := []struct {
+		name  string
+		start time.Time
+		keys  []string
+	}{
+		{"year boundary", time.Date(2026, 12, 27, 23, 59, 0, 0, time.UTC), []string{"2026-W52", "2026-W53", "2027-W01"}},
+		{"daylight saving", time.Date(2026, 3, 1, 15, 59, 0, 0, la), []string{"2026-W09", "2026-W10", "2026-W11"}},
+	}
+
+	for _, tt := range tests {
+		fake := testutil.NewFakeClock(tt.start)
+		SetClock(fake)
+
+		// GetUser and ArchiveCurrentWeek read the package clock
+		user := NewPreferences().GetUser("123")
+		for i := range tt.keys {
+			user.IncrementEventsViewed(i + 1)
+			fake.AddDate(0, 0, 7)
+			user.ArchiveCurrentWeek()
+		}
+
+		for i, key := range tt.keys {
+			if week := user.StatsHistory[key]; week == nil || week.EventsViewed != i+1 {
+				t.Errorf("%s: week %s = %+v, want %d events viewed", tt.name, key, week, i+1)
+			}
+		}
+		if want := fake.Now().UTC().Truncate(24 * time.Hour); !user.WeeklyStats.WeekStart.Equal(want) {
+			t.Errorf("%s: new week starts %v, want %v", tt.name, user.WeeklyStats.WeekStart, want)
+		}
+	}
+}
+
+func TestCompactMissedRollover(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Date(2026, 12, 28, 9, 0, 0, 0, time.UTC))
+	user := &UserPreferences{EnableStats: true, WeeklyStats: NewWeeklyStatsAt(clk.Now())}
+	user.IncrementEventsViewed(3)
+
+	clk.AddDate(0, 0, 6)
+	if result := user.Compact(CompactOptions{Now: clk.Now()}); result.WeeksArchived != 0 {
+		t.Errorf("a 6-day-old week shouldn't be archived: %+v", result)
+	}
+
+	clk.AddDate(0, 0, 1)
+	if result := user.Compact(CompactOptions{Now: clk.Now()}); result.WeeksArchived != 1 {
+		t.Errorf("a 7-day-old week should be archived: %+v", result)
+	}
+	if week := user.StatsHistory["2026-W53"]; week == nil || week.EventsViewed != 3 {
+		t.Errorf("StatsHistory = %v, want the week under 2026-W53", user.StatsHistory)
+	}
+}
+
 func TestGetAllTimeStats(t *testing.T) {
 	prefs := NewPreferences()
 	chatID := "12345"
@@ -954,25 +1062,16 @@ func TestGetInviteCode(t *testing.T) {
 	}
 }
 
-func TestGenerateInviteCode(t *testing.T) {
-	// Test with various chat IDs
-	tests := []struct {
-		chatID string
-		want   string
-	}{
-		{"123456789", "456789"},
-		{"12345", "12345"},
-		{"123", "123"},
-		{"", ""},
-	}
+func TestInviteCodeMigration(t *testing.T) {
+	prefs := NewPreferences()
+	prefs["123456789"] = &UserPreferences{InviteCode: "456789", Active: true}
 
-	for _, tt := range tests {
-		t.Run(tt.chatID, func(t *testing.T) {
-			got := generateInviteCode(tt.chatID)
-			if got != tt.want {
-				t.Errorf("generateInviteCode(%q) = %q, want %q", tt.chatID, got, tt.want)
-			}
-		})
+	code := prefs.GetUser("123456789").GetInviteCode()
+	if code == "456789" || len(code) != inviteCodeLength {
+		t.Errorf("legacy invite code not replaced: %q", code)
+	}
+	if isLegacyInviteCode("123456789", code) {
+		t.Error("new code shouldn't look like a legacy one")
 	}
 }
 
@@ -1138,6 +1237,33 @@ func TestHidePastEvents(t *testing.T) {
 	}
 }
 
+func TestSettingsDefaultOnSurviveReload(t *testing.T) {
+	// Saved before the settings existed: they default to on
+	prefs, err := FromJSON([]byte(`{"123": {"states": ["NV"], "active": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := prefs.GetUser("123")
+	if !user.HidePastEvents || !user.NotifyOnChanges || !user.NotifyOnRemoval || !user.EnableStats {
+		t.Errorf("legacy user = %+v, want the default-on settings on", user)
+	}
+
+	// Switched off, they stay off after saving and loading again
+	user.HidePastEvents, user.NotifyOnChanges, user.NotifyOnRemoval, user.EnableStats = false, false, false, false
+	user.SetEventStatus("evt1", EventStatusInterested)
+	data, err := prefs.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefs, err = FromJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	user = prefs.GetUser("123")
+	if user.HidePastEvents || user.NotifyOnChanges || user.NotifyOnRemoval || user.EnableStats {
+		t.Errorf("reloaded user = %+v, want the settings kept off", user)
+	}
+}
+
 func TestDaysAhead(t *testing.T) {
 	prefs := NewPreferences()
 	user := prefs.GetUser("12345")
@@ -1425,3 +1551,158 @@ func TestFriendMigration(t *testing.T) {
 		t.Error("ShareEvents should default to false")
 	}
 }
+
+func TestEventSelection(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	if user.IsEventSelected("evt1") {
+		t.Error("evt1 should not be selected initially")
+	}
+
+	if !user.ToggleEventSelection("evt1") {
+		t.Error("first toggle should select evt1")
+	}
+	user.ToggleEventSelection("evt2")
+	if !user.IsEventSelected("evt1") || !user.IsEventSelected("evt2") {
+		t.Errorf("expected evt1 and evt2 selected, got %v", user.SelectedEventIDs)
+	}
+
+	if user.ToggleEventSelection("evt1") {
+		t.Error("second toggle should unselect evt1")
+	}
+	if user.IsEventSelected("evt1") || len(user.SelectedEventIDs) != 1 {
+		t.Errorf("expected only evt2 selected, got %v", user.SelectedEventIDs)
+	}
+
+	user.ClearEventSelection()
+	if len(user.SelectedEventIDs) != 0 {
+		t.Errorf("expected empty selection after clear, got %v", user.SelectedEventIDs)
+	}
+}
+
+func TestStatusHistory(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	user.SetEventStatus("evt1", EventStatusInterested)
+	user.SetEventStatus("evt1", EventStatusInterested) // No change, not recorded
+	user.SetEventStatus("evt1", EventStatusRegistered)
+	user.RemoveEventStatus("evt1")
+
+	history := user.GetStatusHistory("evt1")
+	if len(history) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(history), history)
+	}
+
+	want := []StatusChange{
+		{From: "", To: EventStatusInterested},
+		{From: EventStatusInterested, To: EventStatusRegistered},
+		{From: EventStatusRegistered, To: ""},
+	}
+	for i, w := range want {
+		if history[i].From != w.From || history[i].To != w.To {
+			t.Errorf("change %d = %s → %s, want %s → %s", i, history[i].From, history[i].To, w.From, w.To)
+		}
+		if history[i].At.IsZero() {
+			t.Errorf("change %d has no timestamp", i)
+		}
+	}
+
+	if got := user.GetStatusHistory("unknown"); len(got) != 0 {
+		t.Errorf("expected no history for unknown event, got %+v", got)
+	}
+}
+
+func TestStatusHistoryCapped(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	statuses := []string{EventStatusInterested, EventStatusMaybe}
+	for i := 0; i < MaxStatusHistory+5; i++ {
+		user.SetEventStatus("evt1", statuses[i%2])
+	}
+
+	if got := len(user.GetStatusHistory("evt1")); got != MaxStatusHistory {
+		t.Errorf("history length = %d, want %d", got, MaxStatusHistory)
+	}
+}
+
+func TestStatusConversion(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	// Interested then registered: converted
+	user.SetEventStatus("evt1", EventStatusInterested)
+	user.SetEventStatus("evt1", EventStatusRegistered)
+	// Interested only
+	user.SetEventStatus("evt2", EventStatusInterested)
+	// Registered directly: not counted as interested
+	user.SetEventStatus("evt3", EventStatusRegistered)
+
+	converted, total := user.StatusConversion(EventStatusInterested, EventStatusRegistered)
+	if converted != 1 || total != 2 {
+		t.Errorf("StatusConversion = %d/%d, want 1/2", converted, total)
+	}
+}
+
+func TestArchiveEvent(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	user.SetEventStatus("evt1", EventStatusInterested)
+	user.SetEventStatus("evt1", EventStatusRegistered)
+	user.SetEventNote("evt1", "Bring rangefinder")
+	user.ToggleEventSelection("evt1")
+	user.SetEventStatus("evt2", EventStatusMaybe)
+
+	if !user.ArchiveEvent("evt1", "Desert Classic", "Mar 5 2026") {
+		t.Fatal("expected evt1 to be archived")
+	}
+	if user.ArchiveEvent("unknown", "", "") {
+		t.Error("untracked event should not be archived")
+	}
+
+	if user.GetEventStatus("evt1") != "" || user.GetEventNote("evt1") != "" || len(user.GetStatusHistory("evt1")) != 0 {
+		t.Error("archived event should be removed from live statuses, notes, and history")
+	}
+	if user.IsEventSelected("evt1") {
+		t.Error("archived event should be removed from the selection")
+	}
+
+	archived := user.ArchivedEvents["evt1"]
+	if archived == nil {
+		t.Fatal("expected archived record for evt1")
+	}
+	if archived.Status != EventStatusRegistered || archived.Note != "Bring rangefinder" || archived.Title != "Desert Classic" {
+		t.Errorf("unexpected archived record: %+v", archived)
+	}
+	if len(archived.Statuses) != 2 || archived.Statuses[0] != EventStatusInterested {
+		t.Errorf("archived statuses = %v", archived.Statuses)
+	}
+
+	if ids := user.TrackedEventIDs(); len(ids) != 1 || ids[0] != "evt2" {
+		t.Errorf("TrackedEventIDs() = %v, want [evt2]", ids)
+	}
+
+	// Conversion stats still count archived events
+	converted, total := user.StatusConversion(EventStatusInterested, EventStatusRegistered)
+	if converted != 1 || total != 1 {
+		t.Errorf("StatusConversion = %d/%d, want 1/1", converted, total)
+	}
+}
+
+func TestStateCodes(t *testing.T) {
+	codes := StateCodes()
+	if len(codes) != 51 {
+		t.Fatalf("got %d state codes, want 51 (50 states and DC)", len(codes))
+	}
+	for i, code := range codes {
+		if !IsValidState(code) || code == "ALL" {
+			t.Errorf("invalid state code %q", code)
+		}
+		if i > 0 && GetStateName(codes[i-1]) > GetStateName(code) {
+			t.Errorf("codes not sorted by name: %s before %s", codes[i-1], code)
+		}
+	}
+}
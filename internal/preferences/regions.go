@@ -0,0 +1,131 @@
+package preferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Region is a named group of states users can subscribe to at once
+type Region struct {
+	Key    string   `json:"-"`      // Lowercase identifier used in /subscribe, e.g. "southwest"
+	Name   string   `json:"name"`   // Display name, e.g. "Southwest"
+	States []string `json:"states"` // 2-letter state codes
+}
+
+// regionKeyPattern keeps region keys short enough for callback data (64 bytes)
+var regionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// regions holds the built-in presets in keyboard order; LoadRegions adds to or replaces them
+var regions = []*Region{
+	{Key: "southwest", Name: "Southwest", States: []string{"NV", "AZ", "CA", "UT", "NM"}},
+	{Key: "west-coast", Name: "West Coast", States: []string{"CA", "OR", "WA"}},
+	{Key: "mountain", Name: "Mountain", States: []string{"CO", "UT", "ID", "MT", "WY"}},
+	{Key: "texas-plus", Name: "Texas & Neighbors", States: []string{"TX", "OK", "LA", "NM", "AR"}},
+	{Key: "southeast", Name: "Southeast", States: []string{"FL", "GA", "SC", "NC", "AL", "TN"}},
+	{Key: "northeast", Name: "Northeast", States: []string{"NY", "NJ", "PA", "MA", "CT", "RI", "VT", "NH", "ME"}},
+	{Key: "midwest", Name: "Midwest", States: []string{"IL", "IN", "MI", "OH", "WI", "MN", "IA", "MO"}},
+}
+
+// NormalizeRegionKey lowercases a region name and joins words with hyphens, so
+// "West Coast" and "west_coast" both match "west-coast"
+func NormalizeRegionKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "-")
+}
+
+// GetRegion returns the region with the given key or name, or nil if there isn't one
+func GetRegion(name string) *Region {
+	key := NormalizeRegionKey(name)
+	for _, r := range regions {
+		if r.Key == key {
+			return r
+		}
+	}
+	return nil
+}
+
+// Regions returns every region in keyboard order
+func Regions() []*Region {
+	return append([]*Region(nil), regions...)
+}
+
+// LoadRegions reads extra region presets from a JSON file keyed by region, e.g.
+// {"four-corners": {"name": "Four Corners", "states": ["AZ", "CO", "NM", "UT"]}}.
+// A region with the same key as a built-in one replaces it; new regions are added
+// after the built-in ones in key order.
+func LoadRegions(path string) error {
+	data, err := os.ReadFile(path) // #nosec G304 - Path comes from the operator's flag
+	if err != nil {
+		return fmt.Errorf("reading regions: %w", err)
+	}
+
+	var loaded map[string]*Region
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("parsing regions: %w", err)
+	}
+
+	keys := make([]string, 0, len(loaded))
+	for key := range loaded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r := loaded[key]
+		if r == nil {
+			return fmt.Errorf("region %q: missing definition", key)
+		}
+		if err := r.validate(key); err != nil {
+			return err
+		}
+		if existing := GetRegion(r.Key); existing != nil {
+			*existing = *r
+			continue
+		}
+		regions = append(regions, r)
+	}
+	return nil
+}
+
+// validate normalizes a loaded region and checks its key and states
+func (r *Region) validate(key string) error {
+	r.Key = NormalizeRegionKey(key)
+	if !regionKeyPattern.MatchString(r.Key) {
+		return fmt.Errorf("region %q: keys must be 1-32 letters, digits, or hyphens", key)
+	}
+	if IsValidState(r.Key) {
+		return fmt.Errorf("region %q: key conflicts with a state code", key)
+	}
+	if r.Name == "" {
+		r.Name = key
+	}
+	if len(r.States) == 0 {
+		return fmt.Errorf("region %q: no states", key)
+	}
+	for i, state := range r.States {
+		state = strings.ToUpper(strings.TrimSpace(state))
+		if state == "ALL" || !IsValidState(state) {
+			return fmt.Errorf("region %q: invalid state code %q", key, r.States[i])
+		}
+		r.States[i] = state
+	}
+	return nil
+}
+
+// AddRegion subscribes a user to every state in a region and returns the states
+// that were newly added
+func (p Preferences) AddRegion(chatID string, region *Region) []string {
+	var added []string
+	for _, state := range region.States {
+		if p.AddState(chatID, state) {
+			added = append(added, state)
+		}
+	}
+	return added
+}
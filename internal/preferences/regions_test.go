@@ -0,0 +1,122 @@
+package preferences
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetRegion(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"southwest", "southwest"},
+		{"West Coast", "west-coast"},
+		{"west_coast", "west-coast"},
+		{" MIDWEST ", "midwest"},
+		{"NV", ""},
+		{"atlantis", ""},
+	}
+	for _, tt := range tests {
+		r := GetRegion(tt.name)
+		got := ""
+		if r != nil {
+			got = r.Key
+		}
+		if got != tt.want {
+			t.Errorf("GetRegion(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuiltInRegionsAreValid(t *testing.T) {
+	for _, r := range Regions() {
+		if !regionKeyPattern.MatchString(r.Key) {
+			t.Errorf("region key %q is invalid", r.Key)
+		}
+		for _, state := range r.States {
+			if state == "ALL" || !IsValidState(state) {
+				t.Errorf("region %s has invalid state %q", r.Key, state)
+			}
+		}
+	}
+}
+
+func TestLoadRegions(t *testing.T) {
+	original := regions
+	regions = append([]*Region(nil), original...)
+	regions[0] = &Region{Key: original[0].Key, Name: original[0].Name, States: original[0].States}
+	defer func() { regions = original }()
+
+	path := filepath.Join(t.TempDir(), "regions.json")
+	data := `{
+		"four-corners": {"name": "Four Corners", "states": ["az", "CO", "NM", "UT"]},
+		"southwest": {"name": "Desert Southwest", "states": ["NV", "AZ"]}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadRegions(path); err != nil {
+		t.Fatalf("LoadRegions() error = %v", err)
+	}
+
+	fc := GetRegion("four corners")
+	if fc == nil {
+		t.Fatal("four-corners region not added")
+	}
+	if !reflect.DeepEqual(fc.States, []string{"AZ", "CO", "NM", "UT"}) {
+		t.Errorf("four-corners states = %v", fc.States)
+	}
+	if all := Regions(); all[len(all)-1].Key != "four-corners" {
+		t.Error("new regions should come after the built-in ones")
+	}
+
+	sw := GetRegion("southwest")
+	if sw.Name != "Desert Southwest" || len(sw.States) != 2 {
+		t.Errorf("southwest not replaced: %+v", sw)
+	}
+	if len(Regions()) != len(original)+1 {
+		t.Errorf("got %d regions, want %d", len(Regions()), len(original)+1)
+	}
+}
+
+func TestLoadRegionsInvalid(t *testing.T) {
+	original := regions
+	defer func() { regions = original }()
+
+	tests := map[string]string{
+		"bad state":    `{"nowhere": {"states": ["XX"]}}`,
+		"no states":    `{"empty": {"name": "Empty"}}`,
+		"state key":    `{"nv": {"states": ["NV"]}}`,
+		"all state":    `{"everything": {"states": ["ALL"]}}`,
+		"bad key":      `{"west/coast": {"states": ["CA"]}}`,
+		"invalid json": `{`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "regions.json")
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := LoadRegions(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestAddRegion(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.AddState("123", "CA")
+
+	added := prefs.AddRegion("123", &Region{Key: "west-coast", States: []string{"CA", "OR", "WA"}})
+	if !reflect.DeepEqual(added, []string{"OR", "WA"}) {
+		t.Errorf("AddRegion() = %v, want [OR WA]", added)
+	}
+	if states := prefs.GetStates("123"); len(states) != 3 {
+		t.Errorf("states = %v, want 3", states)
+	}
+}
@@ -0,0 +1,79 @@
+package preferences
+
+// RenameEvent moves everything the user recorded for oldID (status and its history,
+// note and attachment, discussion, group note, seen time, selection, poll options,
+// league series and scores, and tracked cards) over to newID, after the event was renamed and got a new
+// ID. Anything already recorded for newID is kept. It returns true if anything moved.
+func (u *UserPreferences) RenameEvent(oldID, newID string) bool {
+	if oldID == newID {
+		return false
+	}
+
+	moved := moveEventKey(u.EventStatuses, oldID, newID)
+	moved = moveEventKey(u.StatusHistory, oldID, newID) || moved
+	moved = moveEventKey(u.EventNotes, oldID, newID) || moved
+	moved = moveEventKey(u.NoteAttachments, oldID, newID) || moved
+	moved = moveEventKey(u.Comments, oldID, newID) || moved
+	moved = moveEventKey(u.GroupNotes, oldID, newID) || moved
+	moved = moveEventKey(u.SeenEventIDs, oldID, newID) || moved
+
+	for i, id := range u.SelectedEventIDs {
+		if id == oldID {
+			u.SelectedEventIDs[i] = newID
+			moved = true
+		}
+	}
+	for _, poll := range u.Polls {
+		for i, id := range poll.EventIDs {
+			if id == oldID {
+				poll.EventIDs[i] = newID
+				moved = true
+			}
+		}
+	}
+	for _, card := range u.EventCards {
+		if card.EventID == oldID {
+			card.EventID = newID
+			moved = true
+		}
+	}
+	for _, l := range u.Leagues {
+		moved = l.RenameEvent(oldID, newID) || moved
+	}
+	if u.PendingNote != nil && u.PendingNote.EventID == oldID {
+		u.PendingNote.EventID = newID
+		moved = true
+	}
+	return moved
+}
+
+// RenameEvents applies RenameEvent for every old → new ID pair to every user and
+// returns how many users changed
+func (p Preferences) RenameEvents(renames map[string]string) int {
+	changed := 0
+	for _, user := range p {
+		userChanged := false
+		for oldID, newID := range renames {
+			if user.RenameEvent(oldID, newID) {
+				userChanged = true
+			}
+		}
+		if userChanged {
+			changed++
+		}
+	}
+	return changed
+}
+
+// moveEventKey moves m[from] to m[to] unless to already has a value
+func moveEventKey[V any](m map[string]V, from, to string) bool {
+	v, ok := m[from]
+	if !ok {
+		return false
+	}
+	delete(m, from)
+	if _, exists := m[to]; !exists {
+		m[to] = v
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package preferences
+
+import (
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/league"
+)
+
+func TestRenameEvent(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("123")
+	user.SetEventStatus("old", EventStatusRegistered)
+	user.SetEventNote("old", "Bring the push cart")
+	user.MarkEventSeen("old")
+	user.SelectedEventIDs = []string{"other", "old"}
+	user.Polls = []*EventPoll{{EventIDs: []string{"old", "other"}}}
+	user.Leagues = []*league.League{{EventIDs: []string{"other", "old"}, Scores: map[string]map[string]int{"old": {"123": 84}}}}
+
+	if !user.RenameEvent("old", "new") {
+		t.Fatal("RenameEvent() = false, want true")
+	}
+	if got := user.GetEventStatus("new"); got != EventStatusRegistered {
+		t.Errorf("status = %q, want registered", got)
+	}
+	if user.GetEventStatus("old") != "" {
+		t.Error("old status should be gone")
+	}
+	if got := user.GetEventNote("new"); got != "Bring the push cart" {
+		t.Errorf("note = %q", got)
+	}
+	if len(user.GetStatusHistory("new")) == 0 {
+		t.Error("status history should move")
+	}
+	if !user.HasSeenEvent("new") || user.HasSeenEvent("old") {
+		t.Error("seen time should move")
+	}
+	if user.SelectedEventIDs[1] != "new" || user.Polls[0].EventIDs[0] != "new" {
+		t.Errorf("selection %v and poll %v should point at the new ID", user.SelectedEventIDs, user.Polls[0].EventIDs)
+	}
+	if l := user.Leagues[0]; l.EventIDs[1] != "new" || l.Scores["new"]["123"] != 84 {
+		t.Errorf("league series %v and scores %v should point at the new ID", l.EventIDs, l.Scores)
+	}
+
+	if user.RenameEvent("old", "new") {
+		t.Error("second RenameEvent() = true, want false")
+	}
+}
+
+func TestRenameEventKeepsExisting(t *testing.T) {
+	user := NewPreferences().GetUser("123")
+	user.SetEventStatus("old", EventStatusMaybe)
+	user.SetEventStatus("new", EventStatusRegistered)
+
+	user.RenameEvent("old", "new")
+	if got := user.GetEventStatus("new"); got != EventStatusRegistered {
+		t.Errorf("status = %q, want the existing registered", got)
+	}
+}
+
+func TestRenameEvents(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.GetUser("1").SetEventStatus("a", EventStatusInterested)
+	prefs.GetUser("2").SetEventNote("b", "note")
+	prefs.GetUser("3")
+
+	if got := prefs.RenameEvents(map[string]string{"a": "a2", "b": "b2"}); got != 2 {
+		t.Errorf("RenameEvents() = %d users, want 2", got)
+	}
+	if prefs["1"].GetEventStatus("a2") != EventStatusInterested || prefs["2"].GetEventNote("b2") != "note" {
+		t.Error("expected both users' data under the new IDs")
+	}
+}
@@ -0,0 +1,110 @@
+package preferences
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxScheduledReports caps how many saved filters one user can have delivered on a schedule
+const MaxScheduledReports = 5
+
+var (
+	// ErrUnknownFilter is returned when scheduling a filter that isn't saved
+	ErrUnknownFilter = errors.New("no saved filter with that name")
+
+	// ErrTooManyReports is returned when the user already has MaxScheduledReports others
+	ErrTooManyReports = errors.New("too many scheduled reports")
+)
+
+// ScheduledReport delivers the events matching a saved filter daily or weekly, a
+// standing report on top of the filter system
+type ScheduledReport struct {
+	Filter    string `json:"filter"`                // Saved filter name
+	Frequency string `json:"frequency"`             // DigestFrequencyDaily or DigestFrequencyWeekly
+	Hour      int    `json:"hour"`                  // 0-23 UTC
+	DayOfWeek int    `json:"day_of_week,omitempty"` // Weekly reports: 0 (Sunday) - 6
+	LastSent  int64  `json:"last_sent,omitempty"`   // Unix time
+}
+
+// lastSlot returns the most recent scheduled time at or before now
+func (r *ScheduledReport) lastSlot(now time.Time) time.Time {
+	now = now.UTC()
+	slot := time.Date(now.Year(), now.Month(), now.Day(), r.Hour, 0, 0, 0, time.UTC)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if r.Frequency == DigestFrequencyWeekly {
+		for int(slot.Weekday()) != r.DayOfWeek {
+			slot = slot.AddDate(0, 0, -1)
+		}
+	}
+	return slot
+}
+
+// Due reports whether a scheduled time has passed since the report was last sent, so a
+// run that's late or skipped an hour still delivers it once
+func (r *ScheduledReport) Due(now time.Time) bool {
+	return r.LastSent < r.lastSlot(now).Unix()
+}
+
+// ScheduleReport schedules a saved filter, replacing any earlier schedule for it. The
+// first report goes out at the next scheduled time, not straight away.
+func (u *UserPreferences) ScheduleReport(filterName, frequency string, hour, dayOfWeek int, now time.Time) error {
+	if _, ok := u.SavedFilters[filterName]; !ok {
+		return ErrUnknownFilter
+	}
+	if frequency != DigestFrequencyDaily && frequency != DigestFrequencyWeekly {
+		return errors.New("frequency must be daily or weekly")
+	}
+	if hour < 0 || hour > 23 || dayOfWeek < 0 || dayOfWeek > 6 {
+		return errors.New("invalid report time")
+	}
+
+	report := &ScheduledReport{Filter: filterName, Frequency: frequency, Hour: hour, LastSent: now.Unix()}
+	if frequency == DigestFrequencyWeekly {
+		report.DayOfWeek = dayOfWeek
+	}
+	for i, existing := range u.ScheduledReports {
+		if existing.Filter == filterName {
+			u.ScheduledReports[i] = report
+			return nil
+		}
+	}
+	if len(u.ScheduledReports) >= MaxScheduledReports {
+		return ErrTooManyReports
+	}
+	u.ScheduledReports = append(u.ScheduledReports, report)
+	return nil
+}
+
+// GetScheduledReport returns the schedule for a saved filter, or nil
+func (u *UserPreferences) GetScheduledReport(filterName string) *ScheduledReport {
+	for _, report := range u.ScheduledReports {
+		if report.Filter == filterName {
+			return report
+		}
+	}
+	return nil
+}
+
+// UnscheduleReport stops a filter's report, reporting whether it was scheduled
+func (u *UserPreferences) UnscheduleReport(filterName string) bool {
+	for i, report := range u.ScheduledReports {
+		if report.Filter == filterName {
+			u.ScheduledReports = append(u.ScheduledReports[:i], u.ScheduledReports[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// DueReports returns the user's reports that should be sent now
+func (u *UserPreferences) DueReports(now time.Time) []*ScheduledReport {
+	var due []*ScheduledReport
+	for _, report := range u.ScheduledReports {
+		if report.Due(now) {
+			due = append(due, report)
+		}
+	}
+	return due
+}
@@ -0,0 +1,77 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/filter"
+)
+
+func TestScheduledReportDue(t *testing.T) {
+	// Wednesday 2026-03-04 08:30 UTC
+	now := time.Date(2026, 3, 4, 8, 30, 0, 0, time.UTC)
+
+	daily := &ScheduledReport{Frequency: DigestFrequencyDaily, Hour: 9, LastSent: now.Unix()}
+	if daily.Due(now) || daily.Due(now.Add(20*time.Minute)) {
+		t.Error("daily report isn't due before 9:00")
+	}
+	if !daily.Due(now.Add(40 * time.Minute)) {
+		t.Error("daily report should be due at 9:10")
+	}
+	daily.LastSent = now.Add(40 * time.Minute).Unix()
+	if daily.Due(now.Add(2 * time.Hour)) {
+		t.Error("daily report shouldn't repeat the same day")
+	}
+
+	weekly := &ScheduledReport{Frequency: DigestFrequencyWeekly, Hour: 9, DayOfWeek: int(time.Friday), LastSent: now.Unix()}
+	if weekly.Due(now.Add(24 * time.Hour)) {
+		t.Error("weekly report isn't due on Thursday")
+	}
+	if !weekly.Due(now.Add(49 * time.Hour)) {
+		t.Error("weekly report should be due Friday 9:30")
+	}
+	// A missed run still delivers it on Saturday
+	if !weekly.Due(now.Add(72 * time.Hour)) {
+		t.Error("weekly report should still be due after a missed run")
+	}
+}
+
+func TestScheduleReport(t *testing.T) {
+	now := time.Date(2026, 3, 4, 8, 30, 0, 0, time.UTC)
+	user := NewPreferences().GetUser("111")
+
+	if err := user.ScheduleReport("Missing", DigestFrequencyDaily, 9, 0, now); !errors.Is(err, ErrUnknownFilter) {
+		t.Errorf("unknown filter: %v", err)
+	}
+
+	for i := 0; i <= MaxScheduledReports; i++ {
+		user.SaveFilter(fmt.Sprintf("F%d", i), filter.NewFilter())
+	}
+	for i := 0; i < MaxScheduledReports; i++ {
+		if err := user.ScheduleReport(fmt.Sprintf("F%d", i), DigestFrequencyWeekly, 9, 1, now); err != nil {
+			t.Fatalf("schedule F%d: %v", i, err)
+		}
+	}
+	if err := user.ScheduleReport(fmt.Sprintf("F%d", MaxScheduledReports), DigestFrequencyDaily, 9, 0, now); !errors.Is(err, ErrTooManyReports) {
+		t.Errorf("over the limit: %v", err)
+	}
+	if err := user.ScheduleReport("F0", DigestFrequencyDaily, 17, 3, now); err != nil {
+		t.Errorf("rescheduling: %v", err)
+	}
+	if report := user.GetScheduledReport("F0"); report.Frequency != DigestFrequencyDaily || report.Hour != 17 || report.DayOfWeek != 0 {
+		t.Errorf("rescheduled report = %+v", report)
+	}
+	if len(user.DueReports(now)) != 0 {
+		t.Error("new schedules shouldn't be due straight away")
+	}
+
+	user.DeleteFilter("F1")
+	if user.GetScheduledReport("F1") != nil {
+		t.Error("deleting a filter should unschedule it")
+	}
+	if !user.UnscheduleReport("F0") || user.UnscheduleReport("F0") {
+		t.Error("UnscheduleReport should remove once")
+	}
+}
@@ -0,0 +1,213 @@
+package preferences
+
+import (
+	"encoding/json"
+	"flag"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var roundTripSeed = flag.Int64("roundtrip.seed", 0, "seed for the preference round-trip tests (0 = time-based)")
+
+// roundTripIterations is how many random users each round-trip test generates
+const roundTripIterations = 200
+
+// randomValue fills v, which must be settable, with random data. Every exported
+// field is set (except those JSON skips), so a field added to UserPreferences or
+// anything it holds is covered without changing the generator. Collections are
+// either nil or non-empty: omitempty can't tell an empty one from nil.
+func randomValue(r *rand.Rand, v reflect.Value, depth int) {
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		v.Set(reflect.ValueOf(time.Unix(r.Int63n(4e9), int64(r.Intn(1e9))).UTC()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(randomString(r))
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := v.Type().Bits()
+		v.SetInt(r.Int63n(1<<(bits-2)) - r.Int63n(1<<(bits-2)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(r.Int63n(1 << (v.Type().Bits() - 1))))
+	case reflect.Float32:
+		v.SetFloat(float64(r.Float32() * 1000))
+	case reflect.Float64:
+		v.SetFloat(r.NormFloat64() * 1000)
+	case reflect.Pointer:
+		if depth > 4 || r.Intn(4) == 0 {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		randomValue(r, p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Slice:
+		if depth > 4 || r.Intn(3) == 0 {
+			return
+		}
+		n := 1 + r.Intn(3)
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < s.Len(); i++ {
+			randomValue(r, s.Index(i), depth+1)
+		}
+		v.Set(s)
+	case reflect.Map:
+		if depth > 4 || r.Intn(3) == 0 {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		for i := 1 + r.Intn(3); i > 0; i-- {
+			key := reflect.New(v.Type().Key()).Elem()
+			randomValue(r, key, depth+1)
+			elem := reflect.New(v.Type().Elem()).Elem()
+			randomValue(r, elem, depth+1)
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			randomValue(r, v.Field(i), depth+1)
+		}
+	}
+}
+
+// randomString returns a short string, sometimes empty, sometimes with characters
+// JSON has to escape
+func randomString(r *rand.Rand) string {
+	const alphabet = "abcXYZ019 _-<>&\"\\\n\té⛳🏌"
+	runes := []rune(alphabet)
+	var b strings.Builder
+	for i := r.Intn(12); i > 0; i-- {
+		b.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return b.String()
+}
+
+// randomPreferences returns preferences for a few users filled with random data
+func randomPreferences(r *rand.Rand) Preferences {
+	prefs := make(Preferences)
+	for i := 1 + r.Intn(3); i > 0; i-- {
+		user := &UserPreferences{}
+		randomValue(r, reflect.ValueOf(user).Elem(), 0)
+		prefs[randomString(r)+"id"] = user
+	}
+	return prefs
+}
+
+// seededRand returns the generator for a round-trip test, logging the seed so a
+// failure can be replayed with -roundtrip.seed
+func seededRand(t *testing.T) *rand.Rand {
+	t.Helper()
+	seed := *roundTripSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("seed %d", seed)
+	return rand.New(rand.NewSource(seed)) // #nosec G404 - Test data only
+}
+
+// cloneJSON returns a deep copy of prefs made through JSON, failing the test on error
+func cloneJSON(t *testing.T, prefs Preferences) Preferences {
+	t.Helper()
+	data, err := prefs.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error: %v", err)
+	}
+	clone, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON() error: %v", err)
+	}
+	return clone
+}
+
+// diffUsers names the first UserPreferences field that differs between want and got
+func diffUsers(want, got Preferences) string {
+	if len(want) != len(got) {
+		return "user count"
+	}
+	for chatID, w := range want {
+		g, ok := got[chatID]
+		if !ok {
+			return "missing user " + chatID
+		}
+		wv, gv := reflect.ValueOf(w).Elem(), reflect.ValueOf(g).Elem()
+		for i := 0; i < wv.NumField(); i++ {
+			if !reflect.DeepEqual(wv.Field(i).Interface(), gv.Field(i).Interface()) {
+				return chatID + "." + wv.Type().Field(i).Name
+			}
+		}
+	}
+	return ""
+}
+
+func TestPreferencesJSONRoundTrip(t *testing.T) {
+	r := seededRand(t)
+
+	for i := 0; i < roundTripIterations; i++ {
+		prefs := randomPreferences(r)
+		got := cloneJSON(t, prefs)
+		if field := diffUsers(prefs, got); field != "" {
+			want, _ := json.Marshal(prefs)
+			t.Fatalf("iteration %d: %s changed in the JSON round trip\ninput: %s", i, field, want)
+		}
+	}
+}
+
+func TestPreferencesEncryptionRoundTrip(t *testing.T) {
+	r := seededRand(t)
+	storage, err := NewGistStorageWithEncryption("gist123", "token123", "test-encryption-key-12345") // gitleaks:allow - test key only
+	if err != nil {
+		t.Fatalf("NewGistStorageWithEncryption() error: %v", err)
+	}
+
+	for i := 0; i < roundTripIterations; i++ {
+		prefs := randomPreferences(r)
+
+		// As Save and Load do: encrypt a copy, store it as JSON, decrypt what's read back
+		stored := cloneJSON(t, prefs)
+		if err := storage.encryptPreferences(stored); err != nil {
+			t.Fatalf("iteration %d: encryptPreferences() error: %v", i, err)
+		}
+		loaded := cloneJSON(t, stored)
+		if err := storage.decryptPreferences(loaded); err != nil {
+			t.Fatalf("iteration %d: decryptPreferences() error: %v", i, err)
+		}
+
+		if field := diffUsers(prefs, loaded); field != "" {
+			want, _ := json.Marshal(prefs)
+			t.Fatalf("iteration %d: %s changed in the encrypted round trip\ninput: %s", i, field, want)
+		}
+	}
+}
+
+func TestRandomPreferencesCoverage(t *testing.T) {
+	// Every field the generator can reach should be set in some iteration, or the
+	// round-trip tests aren't exercising it
+	r := rand.New(rand.NewSource(1)) // #nosec G404 - Test data only
+	userType := reflect.TypeOf(UserPreferences{})
+	set := make(map[string]bool)
+	for i := 0; i < roundTripIterations; i++ {
+		for _, user := range randomPreferences(r) {
+			v := reflect.ValueOf(user).Elem()
+			for j := 0; j < v.NumField(); j++ {
+				if !v.Field(j).IsZero() {
+					set[userType.Field(j).Name] = true
+				}
+			}
+		}
+	}
+	for i := 0; i < userType.NumField(); i++ {
+		if field := userType.Field(i); field.IsExported() && !set[field.Name] {
+			t.Errorf("%s is never generated", field.Name)
+		}
+	}
+}
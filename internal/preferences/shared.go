@@ -0,0 +1,105 @@
+package preferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Shared guards Preferences for use from several goroutines. Readers get copies, so
+// nothing they hold can change under them; writers get the live users under a write
+// lock. Callers must not keep pointers from inside Update or UpdateAll after it
+// returns.
+type Shared struct {
+	mu    sync.RWMutex
+	prefs Preferences
+}
+
+// NewShared wraps prefs. The caller must not use prefs directly afterwards.
+func NewShared(prefs Preferences) *Shared {
+	if prefs == nil {
+		prefs = make(Preferences)
+	}
+	return &Shared{prefs: prefs}
+}
+
+// Len returns the number of users
+func (s *Shared) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.prefs)
+}
+
+// User returns a copy of a user's preferences, or false if there is no such user.
+// Changes to the copy aren't kept; use Update for that.
+func (s *Shared) User(chatID string) (*UserPreferences, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.prefs[chatID]
+	if !ok {
+		return nil, false
+	}
+	copied, err := copyUser(user)
+	if err != nil {
+		return nil, false
+	}
+	return copied, true
+}
+
+// View calls fn with the preferences under a read lock. fn must not modify them.
+func (s *Shared) View(fn func(Preferences)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.prefs)
+}
+
+// Update calls fn with a user's preferences under the write lock, creating the user
+// if needed
+func (s *Shared) Update(chatID string, fn func(*UserPreferences)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.prefs.GetUser(chatID))
+}
+
+// UpdateAll calls fn with all preferences under the write lock, for changes that span
+// several users such as friendships and households
+func (s *Shared) UpdateAll(fn func(Preferences)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.prefs)
+}
+
+// Snapshot returns a deep copy of the preferences, so they can be saved without holding
+// the lock
+func (s *Shared) Snapshot() (Preferences, error) {
+	s.mu.RLock()
+	data, err := json.Marshal(s.prefs)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("copying preferences: %w", err)
+	}
+	var copied Preferences
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("copying preferences: %w", err)
+	}
+	if copied == nil {
+		copied = make(Preferences)
+	}
+	// Households share their event maps; the copies should too
+	copied.ShareHouseholdData()
+	return copied, nil
+}
+
+// copyUser returns a deep copy of user
+func copyUser(user *UserPreferences) (*UserPreferences, error) {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return nil, fmt.Errorf("copying user: %w", err)
+	}
+	var copied UserPreferences
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("copying user: %w", err)
+	}
+	return &copied, nil
+}
@@ -0,0 +1,102 @@
+package preferences
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSharedUserIsACopy(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.AddState("111", "NV")
+	shared := NewShared(prefs)
+
+	user, ok := shared.User("111")
+	if !ok {
+		t.Fatal("User(111) not found")
+	}
+	user.States = append(user.States, "CA")
+	user.SetEventStatus("NV-1", EventStatusRegistered)
+
+	shared.View(func(p Preferences) {
+		if got := p["111"].States; len(got) != 1 {
+			t.Errorf("stored states = %v, want [NV]", got)
+		}
+		if _, ok := p["111"].EventStatuses["NV-1"]; ok {
+			t.Error("change to the copy reached the stored user")
+		}
+	})
+
+	if _, ok := shared.User("999"); ok {
+		t.Error("User(999) found, want missing")
+	}
+}
+
+func TestSharedSnapshotIsACopy(t *testing.T) {
+	shared := NewShared(NewPreferences())
+	shared.Update("111", func(user *UserPreferences) {
+		user.SetEventStatus("NV-1", EventStatusInterested)
+	})
+
+	snapshot, err := shared.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	shared.Update("111", func(user *UserPreferences) {
+		user.SetEventStatus("NV-1", EventStatusRegistered)
+	})
+
+	if got := snapshot["111"].EventStatuses["NV-1"]; got != EventStatusInterested {
+		t.Errorf("snapshot status = %q, want %q", got, EventStatusInterested)
+	}
+}
+
+// TestSharedConcurrentAccess is meant for go test -race: readers, writers and
+// snapshots run at once
+func TestSharedConcurrentAccess(t *testing.T) {
+	shared := NewShared(NewPreferences())
+
+	const workers = 8
+	const rounds = 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		chatID := fmt.Sprintf("%d", 100+w)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				shared.Update(chatID, func(user *UserPreferences) {
+					user.SetEventStatus(fmt.Sprintf("NV-%d", i), EventStatusInterested)
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if user, ok := shared.User(chatID); ok {
+					user.SetEventStatus("scratch", EventStatusSkip)
+				}
+				shared.View(func(p Preferences) { _ = len(p) })
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds/10; i++ {
+				if _, err := shared.Snapshot(); err != nil {
+					t.Errorf("Snapshot() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := shared.Len(); got != workers {
+		t.Errorf("Len() = %d, want %d", got, workers)
+	}
+	for w := 0; w < workers; w++ {
+		user, _ := shared.User(fmt.Sprintf("%d", 100+w))
+		if got := len(user.EventStatuses); got != rounds {
+			t.Errorf("user %d has %d statuses, want %d", 100+w, got, rounds)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package preferences
+
+import "sort"
+
+// Sharing levels: which of a user's event statuses a friend can see
+const (
+	ShareNothing    = "none"
+	ShareRegistered = "registered" // ✅ Registered events only
+	ShareInterested = "interested" // ✅ Registered and ⭐ Interested events
+)
+
+// SharingLevels lists the levels in the order the settings menu cycles through them
+var SharingLevels = []string{ShareNothing, ShareRegistered, ShareInterested}
+
+// IsValidSharingLevel reports whether level is one of SharingLevels
+func IsValidSharingLevel(level string) bool {
+	for _, l := range SharingLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSharingLevel returns the level for friends without their own setting. Before
+// per-friend settings, ShareEvents turned sharing of registered and interested events
+// on for every friend, so it still decides when no default was chosen.
+func (u *UserPreferences) DefaultSharingLevel() string {
+	if u.SharingDefault != "" {
+		return u.SharingDefault
+	}
+	if u.ShareEvents {
+		return ShareInterested
+	}
+	return ShareNothing
+}
+
+// SharingFor returns what the user shares with a friend
+func (u *UserPreferences) SharingFor(friendChatID string) string {
+	if level, ok := u.FriendSharing[friendChatID]; ok {
+		return level
+	}
+	return u.DefaultSharingLevel()
+}
+
+// SetSharing sets what the user shares with one friend. Returns false for an unknown
+// level.
+func (u *UserPreferences) SetSharing(friendChatID, level string) bool {
+	if !IsValidSharingLevel(level) {
+		return false
+	}
+	if u.FriendSharing == nil {
+		u.FriendSharing = make(map[string]string)
+	}
+	u.FriendSharing[friendChatID] = level
+	return true
+}
+
+// SetDefaultSharing sets the level for friends without their own setting. ShareEvents
+// is kept in step for older readers of the preferences.
+func (u *UserPreferences) SetDefaultSharing(level string) bool {
+	if !IsValidSharingLevel(level) {
+		return false
+	}
+	u.SharingDefault = level
+	u.ShareEvents = level != ShareNothing
+	return true
+}
+
+// NextSharingLevel returns the level after level in SharingLevels, wrapping around
+func NextSharingLevel(level string) string {
+	for i, l := range SharingLevels {
+		if l == level {
+			return SharingLevels[(i+1)%len(SharingLevels)]
+		}
+	}
+	return SharingLevels[0]
+}
+
+// sharesStatus reports whether a sharing level covers an event status
+func sharesStatus(level, status string) bool {
+	switch status {
+	case EventStatusRegistered:
+		return level == ShareRegistered || level == ShareInterested
+	case EventStatusInterested:
+		return level == ShareInterested
+	}
+	return false
+}
+
+// FriendStatuses returns the statuses on an event that chatID's friends share with
+// them, keyed by friend chat ID. Sharing is mutual: a friend is left out when chatID
+// shares nothing with them.
+func (p Preferences) FriendStatuses(chatID, eventID string) map[string]string {
+	statuses := make(map[string]string)
+	user, ok := p[chatID]
+	if !ok {
+		return statuses
+	}
+	for _, friendChatID := range user.FriendChatIDs {
+		friend, ok := p[friendChatID]
+		if !ok || user.SharingFor(friendChatID) == ShareNothing {
+			continue
+		}
+		status := friend.GetEventStatus(eventID)
+		if sharesStatus(friend.SharingFor(chatID), status) {
+			statuses[friendChatID] = status
+		}
+	}
+	return statuses
+}
+
+// GetFriendsForEvent returns the friends who share that they're registered for or
+// interested in an event with chatID, sorted
+func (p Preferences) GetFriendsForEvent(chatID, eventID string) []string {
+	statuses := p.FriendStatuses(chatID, eventID)
+	friends := make([]string, 0, len(statuses))
+	for id := range statuses {
+		friends = append(friends, id)
+	}
+	sort.Strings(friends)
+	return friends
+}
@@ -0,0 +1,58 @@
+package preferences
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSharingLevels(t *testing.T) {
+	prefs := NewPreferences()
+	viewer, alice, bob := prefs.GetUser("111"), prefs.GetUser("222"), prefs.GetUser("333")
+	viewer.AddFriend("222")
+	viewer.AddFriend("333")
+	viewer.ShareEvents = true
+	alice.SetEventStatus("evt1", EventStatusRegistered)
+	alice.SetEventStatus("evt2", EventStatusInterested)
+	bob.SetEventStatus("evt1", EventStatusInterested)
+
+	// Legacy ShareEvents shares registered and interested events
+	bob.ShareEvents = true
+	alice.SetSharing("111", ShareRegistered)
+
+	if got, want := prefs.FriendStatuses("111", "evt1"), map[string]string{"222": EventStatusRegistered, "333": EventStatusInterested}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FriendStatuses(evt1) = %v, want %v", got, want)
+	}
+	if got := prefs.GetFriendsForEvent("111", "evt2"); len(got) != 0 {
+		t.Errorf("registered-only sharing shouldn't show interested events, got %v", got)
+	}
+
+	alice.SetSharing("111", ShareNothing)
+	if got := prefs.GetFriendsForEvent("111", "evt1"); !reflect.DeepEqual(got, []string{"333"}) {
+		t.Errorf("GetFriendsForEvent(evt1) = %v, want [333]", got)
+	}
+
+	// Sharing is mutual: sharing nothing with a friend hides theirs
+	viewer.SetSharing("333", ShareNothing)
+	if got := prefs.GetFriendsForEvent("111", "evt1"); len(got) != 0 {
+		t.Errorf("GetFriendsForEvent(evt1) = %v, want none", got)
+	}
+}
+
+func TestDefaultSharing(t *testing.T) {
+	user := NewPreferences().GetUser("111")
+	if got := user.SharingFor("222"); got != ShareNothing {
+		t.Errorf("default sharing = %q, want %q", got, ShareNothing)
+	}
+	if !user.SetDefaultSharing(ShareRegistered) || !user.ShareEvents {
+		t.Error("SetDefaultSharing(registered) should turn ShareEvents on")
+	}
+	if got := user.SharingFor("222"); got != ShareRegistered {
+		t.Errorf("SharingFor() = %q, want the default", got)
+	}
+	if user.SetSharing("222", "everything") || user.SetDefaultSharing("") {
+		t.Error("unknown levels should be rejected")
+	}
+	if got := NextSharingLevel(ShareInterested); got != ShareNothing {
+		t.Errorf("NextSharingLevel(interested) = %q, want wrap to none", got)
+	}
+}
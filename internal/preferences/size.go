@@ -0,0 +1,210 @@
+package preferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// GistFileSizeLimit is the largest file the Gist API returns in full;
+	// bigger files come back truncated and can no longer be loaded
+	GistFileSizeLimit = 1 << 20
+
+	// SizeWarnThreshold is the document size that triggers a warning (75% of the limit),
+	// leaving headroom for encryption overhead and growth between runs
+	SizeWarnThreshold = GistFileSizeLimit * 3 / 4
+
+	// DefaultSeenEventDays is how long SeenEventIDs entries are kept by Compact
+	DefaultSeenEventDays = 90
+)
+
+// UserSize is the serialized size of one user's preferences
+type UserSize struct {
+	ChatID string
+	Bytes  int
+}
+
+// SizeReport describes the serialized size of a preferences document
+type SizeReport struct {
+	Total int        // Size of the whole document as stored (unencrypted)
+	Users []UserSize // Per-user sizes, largest first
+}
+
+// MeasureSize returns the serialized size of the preferences document and of each user.
+// Sizes are for unencrypted JSON; encryption makes notes and statuses somewhat larger.
+func MeasureSize(prefs Preferences) (*SizeReport, error) {
+	data, err := prefs.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling preferences: %w", err)
+	}
+
+	report := &SizeReport{Total: len(data)}
+	for chatID, user := range prefs {
+		userData, err := json.MarshalIndent(user, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshaling user %s: %w", chatID, err)
+		}
+		report.Users = append(report.Users, UserSize{ChatID: chatID, Bytes: len(userData)})
+	}
+
+	sort.Slice(report.Users, func(i, j int) bool {
+		if report.Users[i].Bytes != report.Users[j].Bytes {
+			return report.Users[i].Bytes > report.Users[j].Bytes
+		}
+		return report.Users[i].ChatID < report.Users[j].ChatID
+	})
+
+	return report, nil
+}
+
+// NearLimit reports whether the document is approaching the Gist file size limit
+func (r *SizeReport) NearLimit() bool {
+	return r.Total >= SizeWarnThreshold
+}
+
+// PercentOfLimit returns the document size as a percentage of the Gist file size limit
+func (r *SizeReport) PercentOfLimit() float64 {
+	return float64(r.Total) * 100 / float64(GistFileSizeLimit)
+}
+
+// Largest returns up to n of the largest users
+func (r *SizeReport) Largest(n int) []UserSize {
+	return r.Users[:min(n, len(r.Users))]
+}
+
+// Warning returns a message describing the document size when it's near the limit, or ""
+func (r *SizeReport) Warning() string {
+	if !r.NearLimit() {
+		return ""
+	}
+	return fmt.Sprintf("preferences document is %s (%.0f%% of the %s Gist limit); run `vga-events prefs compact`",
+		FormatBytes(r.Total), r.PercentOfLimit(), FormatBytes(GistFileSizeLimit))
+}
+
+// FormatBytes formats a byte count for display (e.g., "512 B", "12.3 KB", "1.0 MB")
+func FormatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// CompactOptions controls what Compact prunes
+type CompactOptions struct {
+	SeenEventDays int       // Drop SeenEventIDs entries older than this many days (0 keeps all)
+	Now           time.Time // When the compaction runs (default: the package clock)
+}
+
+// CompactResult counts what Compact removed
+type CompactResult struct {
+	SeenEventsPruned int // SeenEventIDs entries older than the cutoff
+	WeeksArchived    int // Current weeks left over from a missed stats rollover
+	EmptyWeeksPruned int // Archived stats weeks with no activity
+	EmptyEntries     int // Blank notes/statuses, empty histories, groups, and filters
+	TravelExpired    int // Travel subscriptions whose trip is over
+	CommentsPruned   int // Discussion messages older than CommentRetention
+}
+
+// Add accumulates another result into r
+func (r *CompactResult) Add(other CompactResult) {
+	r.SeenEventsPruned += other.SeenEventsPruned
+	r.WeeksArchived += other.WeeksArchived
+	r.EmptyWeeksPruned += other.EmptyWeeksPruned
+	r.EmptyEntries += other.EmptyEntries
+	r.TravelExpired += other.TravelExpired
+	r.CommentsPruned += other.CommentsPruned
+}
+
+// Compact prunes every user's preferences in place. See UserPreferences.Compact.
+func (p Preferences) Compact(opts CompactOptions) CompactResult {
+	var total CompactResult
+	for _, user := range p {
+		total.Add(user.Compact(opts))
+	}
+	return total
+}
+
+// Compact shrinks the user's preferences without changing what they see: old SeenEventIDs
+// entries are pruned, a current week older than 7 days (missed rollover) is archived,
+// stats weeks with no activity are dropped (all-time totals are unchanged), finished
+// trips and old discussion messages are removed, and blank or empty entries are removed.
+func (u *UserPreferences) Compact(opts CompactOptions) CompactResult {
+	var result CompactResult
+	now := opts.Now
+	if now.IsZero() {
+		now = clk.Now()
+	}
+
+	if opts.SeenEventDays > 0 {
+		result.SeenEventsPruned = u.CleanupOldHistory(opts.SeenEventDays)
+	}
+
+	if u.WeeklyStats != nil && now.Sub(u.WeeklyStats.WeekStart) >= 7*24*time.Hour {
+		u.ArchiveCurrentWeekAt(now)
+		result.WeeksArchived++
+	}
+
+	result.TravelExpired = u.ExpireTravel(now)
+	result.CommentsPruned = u.pruneComments(now)
+
+	for key, stats := range u.StatsHistory {
+		if stats == nil || stats.isEmpty() {
+			delete(u.StatsHistory, key)
+			result.EmptyWeeksPruned++
+		}
+	}
+
+	for id, note := range u.EventNotes {
+		if note == "" {
+			delete(u.EventNotes, id)
+			result.EmptyEntries++
+		}
+	}
+	for id, status := range u.EventStatuses {
+		if status == "" {
+			delete(u.EventStatuses, id)
+			result.EmptyEntries++
+		}
+	}
+	for id, history := range u.StatusHistory {
+		if len(history) == 0 {
+			delete(u.StatusHistory, id)
+			result.EmptyEntries++
+		}
+	}
+	for group, members := range u.GroupSubscriptions {
+		if len(members) == 0 {
+			delete(u.GroupSubscriptions, group)
+			result.EmptyEntries++
+		}
+	}
+	for name, preset := range u.SavedFilters {
+		if preset == nil {
+			delete(u.SavedFilters, name)
+			result.EmptyEntries++
+		}
+	}
+
+	return result
+}
+
+// isEmpty reports whether a week of stats recorded no activity. A week with a cohort
+// recorded isn't empty: the user messaged the bot, and dropping the week would skew
+// the rollout comparison.
+func (s *WeeklyStats) isEmpty() bool {
+	if s.EventsViewed > 0 || s.EventsRegistered > 0 || len(s.Cohorts) > 0 {
+		return false
+	}
+	for _, count := range s.EventsMarked {
+		if count > 0 {
+			return false
+		}
+	}
+	return true
+}
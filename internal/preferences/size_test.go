@@ -0,0 +1,128 @@
+package preferences
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMeasureSize(t *testing.T) {
+	prefs := NewPreferences()
+	small := prefs.GetUser("111")
+	small.States = []string{"NV"}
+	big := prefs.GetUser("222")
+	big.States = []string{"CA"}
+	big.SetEventNote("evt1", strings.Repeat("x", 2000))
+
+	report, err := MeasureSize(prefs)
+	if err != nil {
+		t.Fatalf("MeasureSize() error = %v", err)
+	}
+
+	data, _ := prefs.ToJSON()
+	if report.Total != len(data) {
+		t.Errorf("Total = %d, want %d", report.Total, len(data))
+	}
+	if len(report.Users) != 2 || report.Users[0].ChatID != "222" {
+		t.Errorf("expected largest user first, got %+v", report.Users)
+	}
+	if got := report.Largest(1); len(got) != 1 || got[0].ChatID != "222" {
+		t.Errorf("Largest(1) = %+v", got)
+	}
+	if got := report.Largest(10); len(got) != 2 {
+		t.Errorf("Largest(10) returned %d users, want 2", len(got))
+	}
+	if report.NearLimit() || report.Warning() != "" {
+		t.Error("small document should not be near the limit")
+	}
+
+	near := &SizeReport{Total: SizeWarnThreshold}
+	if !near.NearLimit() || !strings.Contains(near.Warning(), "prefs compact") {
+		t.Errorf("expected warning at threshold, got %q", near.Warning())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KB"},
+		{GistFileSizeLimit, "1.0 MB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	now := time.Now()
+	user.SeenEventIDs["old"] = now.AddDate(0, 0, -200).Unix()
+	user.SeenEventIDs["new"] = now.Unix()
+
+	user.StatsHistory["2026-W01"] = &WeeklyStats{EventsMarked: map[string]int{}}
+	user.StatsHistory["2026-W02"] = &WeeklyStats{EventsViewed: 4, EventsMarked: map[string]int{EventStatusInterested: 1}}
+	user.WeeklyStats = &WeeklyStats{WeekStart: now.AddDate(0, 0, -10), EventsViewed: 2}
+
+	user.EventNotes["blank"] = ""
+	user.EventNotes["kept"] = "Tee time 8am"
+	user.StatusHistory = map[string][]StatusChange{"empty": nil}
+	user.GroupSubscriptions["group"] = []string{}
+
+	user.Travel = []TravelSubscription{
+		{State: "NV", Start: now.AddDate(0, 0, -10), End: now.AddDate(0, 0, -5)},
+		{State: "AZ", Start: now.AddDate(0, 0, 5), End: now.AddDate(0, 0, 10)},
+	}
+
+	before := user.GetAllTimeStats()
+	result := prefs.Compact(CompactOptions{SeenEventDays: DefaultSeenEventDays})
+
+	if result.SeenEventsPruned != 1 || user.HasSeenEvent("old") || !user.HasSeenEvent("new") {
+		t.Errorf("expected only the old seen event pruned, result %+v", result)
+	}
+	if result.WeeksArchived != 1 {
+		t.Errorf("expected stale current week archived, got %d", result.WeeksArchived)
+	}
+	if result.EmptyWeeksPruned != 1 {
+		t.Errorf("expected 1 empty week pruned, got %d", result.EmptyWeeksPruned)
+	}
+	if _, ok := user.StatsHistory["2026-W01"]; ok {
+		t.Error("empty week should be removed")
+	}
+	if result.EmptyEntries != 3 {
+		t.Errorf("expected 3 empty entries removed, got %d", result.EmptyEntries)
+	}
+	if user.GetEventNote("kept") == "" {
+		t.Error("non-empty note should be kept")
+	}
+	if result.TravelExpired != 1 || len(user.Travel) != 1 || user.Travel[0].State != "AZ" {
+		t.Errorf("expected only the finished trip removed, got %d expired, trips %+v", result.TravelExpired, user.Travel)
+	}
+
+	after := user.GetAllTimeStats()
+	if after.EventsViewed != before.EventsViewed || after.EventsMarked[EventStatusInterested] != 1 {
+		t.Errorf("all-time stats changed: before %+v, after %+v", before, after)
+	}
+}
+
+func TestTrackingWeeks(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("12345")
+
+	if got := user.TrackingWeeks(); got != 1 {
+		t.Errorf("new user TrackingWeeks() = %d, want 1", got)
+	}
+
+	// A week three weeks ago survives compaction while the empty weeks between don't
+	start := user.WeeklyStats.WeekStart
+	user.StatsHistory["old"] = &WeeklyStats{WeekStart: start.AddDate(0, 0, -21), EventsViewed: 1}
+	if got := user.TrackingWeeks(); got != 4 {
+		t.Errorf("TrackingWeeks() = %d, want 4", got)
+	}
+}
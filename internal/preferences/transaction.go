@@ -0,0 +1,108 @@
+package preferences
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// UnitOfWork applies a change that spans several users, such as a friendship recorded
+// on both sides, and saves it in one write. If the save fails the users are rolled
+// back, so neither the stored nor the in-memory preferences keep one side of the change
+// without the other.
+type UnitOfWork struct {
+	storage Storage
+	backoff errs.Backoff
+}
+
+// NewUnitOfWork creates a unit of work that saves to storage
+func NewUnitOfWork(storage Storage) *UnitOfWork {
+	return &UnitOfWork{storage: storage, backoff: errs.DefaultBackoff}
+}
+
+// Apply runs change against prefs and saves it. change may only modify the users in
+// chatIDs. Rather than saving prefs whole, which would overwrite other users with what
+// this copy last saw, the stored preferences are reloaded and only the users in chatIDs
+// are replaced; the Gist has no conditional writes, so a save by another writer in
+// between can still be lost for those users. Temporary failures are retried with
+// backoff. If change, the reload, or the save fails, the users are restored to how they
+// were before and the error is returned.
+func (u *UnitOfWork) Apply(ctx context.Context, prefs Preferences, chatIDs []string, change func(Preferences) error) error {
+	before, err := snapshotUsers(prefs, chatIDs)
+	if err != nil {
+		return err
+	}
+
+	if err = change(prefs); err == nil {
+		err = u.backoff.Retry(ctx, func() error {
+			return u.save(ctx, prefs, chatIDs)
+		})
+	}
+	if err == nil {
+		return nil
+	}
+
+	if restoreErr := restoreUsers(prefs, before); restoreErr != nil {
+		return errors.Join(err, restoreErr)
+	}
+	return err
+}
+
+// save merges the users in chatIDs into the stored preferences and saves those
+func (u *UnitOfWork) save(ctx context.Context, prefs Preferences, chatIDs []string) error {
+	stored, err := u.storage.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("reloading preferences: %w", err)
+	}
+	for _, id := range chatIDs {
+		if user, ok := prefs[id]; ok {
+			stored[id] = user
+		} else {
+			delete(stored, id)
+		}
+	}
+	return u.storage.Save(ctx, stored)
+}
+
+// snapshotUsers records the users in chatIDs as JSON (nil for users that don't exist)
+func snapshotUsers(prefs Preferences, chatIDs []string) (map[string][]byte, error) {
+	snapshot := make(map[string][]byte, len(chatIDs))
+	for _, id := range chatIDs {
+		user, ok := prefs[id]
+		if !ok {
+			snapshot[id] = nil
+			continue
+		}
+		data, err := json.Marshal(user)
+		if err != nil {
+			return nil, fmt.Errorf("snapshotting user %s: %w", id, err)
+		}
+		snapshot[id] = data
+	}
+	return snapshot, nil
+}
+
+// restoreUsers puts back the users recorded by snapshotUsers, in place so existing
+// pointers to them see the restored values, then re-shares household data
+func restoreUsers(prefs Preferences, snapshot map[string][]byte) error {
+	for id, data := range snapshot {
+		if data == nil {
+			delete(prefs, id)
+			continue
+		}
+		var restored UserPreferences
+		if err := json.Unmarshal(data, &restored); err != nil {
+			return fmt.Errorf("restoring user %s: %w", id, err)
+		}
+		if user, ok := prefs[id]; ok {
+			*user = restored
+		} else {
+			prefs[id] = &restored
+		}
+	}
+	prefs.ShareHouseholdData()
+	return nil
+}
@@ -0,0 +1,141 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// scriptedStorage returns saveErrs in turn from Save (then succeeds) and keeps what
+// was saved as JSON
+type scriptedStorage struct {
+	data     []byte
+	saveErrs []error
+	saves    int
+}
+
+func (s *scriptedStorage) Load(ctx context.Context) (Preferences, error) {
+	if s.data == nil {
+		return NewPreferences(), nil
+	}
+	return FromJSON(s.data)
+}
+
+func (s *scriptedStorage) Save(ctx context.Context, prefs Preferences) error {
+	s.saves++
+	if len(s.saveErrs) > 0 {
+		err := s.saveErrs[0]
+		s.saveErrs = s.saveErrs[1:]
+		return err
+	}
+	data, err := prefs.ToJSON()
+	if err != nil {
+		return err
+	}
+	s.data = data
+	return nil
+}
+
+// befriend is the two-sided change handleJoin makes
+func befriend(a, b string) func(Preferences) error {
+	return func(p Preferences) error {
+		p.GetUser(a).AddFriend(b)
+		p.GetUser(b).AddFriend(a)
+		return nil
+	}
+}
+
+func TestUnitOfWorkCommits(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.GetUser("1")
+	prefs.GetUser("2")
+	store := &scriptedStorage{}
+
+	if err := NewUnitOfWork(store).Apply(context.Background(), prefs, []string{"1", "2"}, befriend("1", "2")); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	saved, _ := store.Load(context.Background())
+	if !saved["1"].IsFriend("2") || !saved["2"].IsFriend("1") {
+		t.Error("expected both sides of the friendship to be saved")
+	}
+}
+
+func TestUnitOfWorkRollsBackOnSaveFailure(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("1")
+	store := &scriptedStorage{saveErrs: []error{errs.Status(http.StatusBadRequest, "bad request")}}
+
+	err := NewUnitOfWork(store).Apply(context.Background(), prefs, []string{"1", "2"}, befriend("1", "2"))
+	if err == nil {
+		t.Fatal("Apply() error = nil, want the save error")
+	}
+	if user.IsFriend("2") {
+		t.Error("user 1 should be rolled back in place")
+	}
+	if _, ok := prefs["2"]; ok {
+		t.Error("user 2 didn't exist before and should be removed again")
+	}
+}
+
+func TestUnitOfWorkRollsBackOnChangeError(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.GetUser("1")
+	store := &scriptedStorage{}
+	errFull := errors.New("full")
+
+	err := NewUnitOfWork(store).Apply(context.Background(), prefs, []string{"1"}, func(p Preferences) error {
+		p.GetUser("1").AddFriend("2")
+		return errFull
+	})
+	if !errors.Is(err, errFull) {
+		t.Fatalf("Apply() error = %v, want %v", err, errFull)
+	}
+	if prefs["1"].IsFriend("2") || store.saves != 0 {
+		t.Error("a failed change should be rolled back without saving")
+	}
+}
+
+func TestUnitOfWorkSavesOnlyTouchedUsers(t *testing.T) {
+	// Another writer gave users 2 and 3 a state since this copy was loaded
+	stored := NewPreferences()
+	stored.GetUser("1")
+	stored.GetUser("2").States = []string{"NV"}
+	stored.GetUser("3").States = []string{"AZ"}
+	data, _ := stored.ToJSON()
+
+	prefs := NewPreferences()
+	prefs.GetUser("1")
+	prefs.GetUser("2")
+	prefs.GetUser("3")
+	store := &scriptedStorage{data: data}
+
+	if err := NewUnitOfWork(store).Apply(context.Background(), prefs, []string{"1", "2"}, befriend("1", "2")); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	saved, _ := store.Load(context.Background())
+	if !saved["1"].IsFriend("2") || !saved["2"].IsFriend("1") {
+		t.Error("expected both sides of the friendship to be saved")
+	}
+	if len(saved["3"].States) != 1 {
+		t.Errorf("an untouched user should keep the stored copy, got %+v", saved["3"])
+	}
+}
+
+func TestUnitOfWorkRollsBackOnConflict(t *testing.T) {
+	prefs := NewPreferences()
+	user := prefs.GetUser("1")
+	store := &scriptedStorage{saveErrs: []error{errs.Status(http.StatusConflict, "conflict")}}
+
+	err := NewUnitOfWork(store).Apply(context.Background(), prefs, []string{"1", "2"}, befriend("1", "2"))
+	if !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("Apply() error = %v, want a conflict", err)
+	}
+	if user.IsFriend("2") || store.saves != 1 {
+		t.Errorf("a conflict should roll back without retrying, saves = %d", store.saves)
+	}
+}
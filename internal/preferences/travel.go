@@ -0,0 +1,113 @@
+package preferences
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+const (
+	// MaxTravelSubscriptions is how many trips one user can have planned
+	MaxTravelSubscriptions = 5
+
+	// MaxTravelDays caps the length of one trip
+	MaxTravelDays = 60
+)
+
+// TravelSubscription temporarily follows a state for a trip: events there that fall
+// within the window match until it's over
+type TravelSubscription struct {
+	State string    `json:"state"`
+	Start time.Time `json:"start"` // First day, UTC midnight
+	End   time.Time `json:"end"`   // Last day (inclusive), UTC midnight
+}
+
+// String formats a trip for display, e.g. "NV, Apr 10-20" or "AZ, Apr 28-May 3"
+func (t TravelSubscription) String() string {
+	return t.State + ", " + FormatDateRange(t.Start, t.End)
+}
+
+// FormatDateRange formats an inclusive range of days, e.g. "Apr 10-20" or "Apr 28-May 3"
+func FormatDateRange(start, end time.Time) string {
+	switch {
+	case start.Equal(end):
+		return start.Format("Jan 2")
+	case start.Month() == end.Month() && start.Year() == end.Year():
+		return fmt.Sprintf("%s-%d", start.Format("Jan 2"), end.Day())
+	default:
+		return fmt.Sprintf("%s-%s", start.Format("Jan 2"), end.Format("Jan 2"))
+	}
+}
+
+// Expired reports whether the trip is over
+func (t TravelSubscription) Expired(now time.Time) bool {
+	return !now.Before(t.End.AddDate(0, 0, 1))
+}
+
+// Matches reports whether an event is in the trip's state and its date falls within
+// the window. Events with dates that can't be parsed don't match.
+func (t TravelSubscription) Matches(evt *event.Event) bool {
+	if !strings.EqualFold(evt.State, t.State) {
+		return false
+	}
+	date := event.ParseDate(evt.DateText)
+	if date.IsZero() {
+		return false
+	}
+	return !date.Before(t.Start) && !date.After(t.End)
+}
+
+// AddTravel adds a trip, replacing one for the same state that overlaps it. Returns
+// false if the user already has MaxTravelSubscriptions other trips.
+func (p Preferences) AddTravel(chatID string, trip TravelSubscription) bool {
+	user := p.GetUser(chatID)
+	for i, existing := range user.Travel {
+		if existing.State == trip.State && !existing.End.Before(trip.Start) && !trip.End.Before(existing.Start) {
+			user.Travel[i] = trip
+			return true
+		}
+	}
+	if len(user.Travel) >= MaxTravelSubscriptions {
+		return false
+	}
+	user.Travel = append(user.Travel, trip)
+	return true
+}
+
+// RemoveTravel removes every trip to a state and returns how many were removed
+func (u *UserPreferences) RemoveTravel(state string) int {
+	return u.removeTravelIf(func(t TravelSubscription) bool { return strings.EqualFold(t.State, state) })
+}
+
+// ExpireTravel removes trips that are over and returns how many were removed
+func (u *UserPreferences) ExpireTravel(now time.Time) int {
+	return u.removeTravelIf(func(t TravelSubscription) bool { return t.Expired(now) })
+}
+
+func (u *UserPreferences) removeTravelIf(remove func(TravelSubscription) bool) int {
+	kept := u.Travel[:0]
+	removed := 0
+	for _, t := range u.Travel {
+		if remove(t) {
+			removed++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	u.Travel = kept
+	if len(u.Travel) == 0 {
+		u.Travel = nil
+	}
+	return removed
+}
+
+// ExpireTravel removes finished trips for every user and returns how many were removed
+func (p Preferences) ExpireTravel(now time.Time) int {
+	removed := 0
+	for _, user := range p {
+		removed += user.ExpireTravel(now)
+	}
+	return removed
+}
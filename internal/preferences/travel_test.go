@@ -0,0 +1,123 @@
+package preferences
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTravelSubscriptionMatches(t *testing.T) {
+	trip := TravelSubscription{State: "NV", Start: day(2026, time.April, 10), End: day(2026, time.April, 20)}
+
+	tests := []struct {
+		name string
+		evt  *event.Event
+		want bool
+	}{
+		{"first day", &event.Event{State: "NV", DateText: "Apr 10 2026"}, true},
+		{"last day", &event.Event{State: "NV", DateText: "Apr 20 2026"}, true},
+		{"lowercase state", &event.Event{State: "nv", DateText: "Apr 15 2026"}, true},
+		{"before trip", &event.Event{State: "NV", DateText: "Apr 9 2026"}, false},
+		{"after trip", &event.Event{State: "NV", DateText: "Apr 21 2026"}, false},
+		{"other state", &event.Event{State: "AZ", DateText: "Apr 15 2026"}, false},
+		{"no date", &event.Event{State: "NV"}, false},
+	}
+	for _, tt := range tests {
+		if got := trip.Matches(tt.evt); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTravelSubscriptionExpired(t *testing.T) {
+	trip := TravelSubscription{State: "NV", Start: day(2026, time.April, 10), End: day(2026, time.April, 20)}
+
+	if trip.Expired(time.Date(2026, time.April, 20, 23, 0, 0, 0, time.UTC)) {
+		t.Error("trip should not be expired on its last day")
+	}
+	if !trip.Expired(day(2026, time.April, 21)) {
+		t.Error("trip should be expired the day after it ends")
+	}
+}
+
+func TestFormatDateRange(t *testing.T) {
+	tests := []struct {
+		start, end time.Time
+		want       string
+	}{
+		{day(2026, time.April, 10), day(2026, time.April, 10), "Apr 10"},
+		{day(2026, time.April, 10), day(2026, time.April, 20), "Apr 10-20"},
+		{day(2026, time.April, 28), day(2026, time.May, 3), "Apr 28-May 3"},
+		{day(2026, time.December, 28), day(2027, time.January, 3), "Dec 28-Jan 3"},
+	}
+	for _, tt := range tests {
+		if got := FormatDateRange(tt.start, tt.end); got != tt.want {
+			t.Errorf("FormatDateRange() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestAddTravel(t *testing.T) {
+	prefs := NewPreferences()
+
+	if !prefs.AddTravel("123", TravelSubscription{State: "NV", Start: day(2026, time.April, 10), End: day(2026, time.April, 20)}) {
+		t.Fatal("AddTravel() = false")
+	}
+	// An overlapping trip to the same state replaces the first
+	if !prefs.AddTravel("123", TravelSubscription{State: "NV", Start: day(2026, time.April, 15), End: day(2026, time.April, 25)}) {
+		t.Fatal("AddTravel() = false for overlapping trip")
+	}
+	user := prefs.GetUser("123")
+	if len(user.Travel) != 1 || !user.Travel[0].End.Equal(day(2026, time.April, 25)) {
+		t.Fatalf("overlapping trip should replace the first, got %+v", user.Travel)
+	}
+
+	for i := 1; i < MaxTravelSubscriptions; i++ {
+		prefs.AddTravel("123", TravelSubscription{State: "AZ", Start: day(2026, time.Month(i+5), 1), End: day(2026, time.Month(i+5), 5)})
+	}
+	if prefs.AddTravel("123", TravelSubscription{State: "CA", Start: day(2026, time.December, 1), End: day(2026, time.December, 5)}) {
+		t.Error("AddTravel() should fail past MaxTravelSubscriptions")
+	}
+
+	if got := user.RemoveTravel("az"); got != MaxTravelSubscriptions-1 {
+		t.Errorf("RemoveTravel() = %d, want %d", got, MaxTravelSubscriptions-1)
+	}
+	if len(user.Travel) != 1 {
+		t.Errorf("expected 1 trip left, got %d", len(user.Travel))
+	}
+}
+
+func TestExpireTravel(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.AddTravel("123", TravelSubscription{State: "NV", Start: day(2026, time.April, 10), End: day(2026, time.April, 20)})
+	prefs.AddTravel("123", TravelSubscription{State: "AZ", Start: day(2026, time.June, 1), End: day(2026, time.June, 5)})
+	prefs.AddTravel("456", TravelSubscription{State: "CA", Start: day(2026, time.March, 1), End: day(2026, time.March, 3)})
+
+	if got := prefs.ExpireTravel(day(2026, time.May, 1)); got != 2 {
+		t.Errorf("ExpireTravel() = %d, want 2", got)
+	}
+	if trips := prefs.GetUser("123").Travel; len(trips) != 1 || trips[0].State != "AZ" {
+		t.Errorf("expected only the AZ trip to remain, got %+v", trips)
+	}
+	if prefs.GetUser("456").Travel != nil {
+		t.Error("Travel should be nil once every trip has expired")
+	}
+}
+
+func TestMatchesSubscriptionsTravel(t *testing.T) {
+	prefs := NewPreferences()
+	prefs.AddTravel("123", TravelSubscription{State: "NV", Start: day(2026, time.April, 10), End: day(2026, time.April, 20)})
+	user := prefs.GetUser("123")
+
+	if !user.MatchesSubscriptions(&event.Event{State: "NV", DateText: "Apr 12 2026"}) {
+		t.Error("event during the trip should match")
+	}
+	if user.MatchesSubscriptions(&event.Event{State: "NV", DateText: "May 12 2026"}) {
+		t.Error("event after the trip should not match")
+	}
+}
@@ -0,0 +1,317 @@
+// Package replay runs archived snapshots back through the diff and dispatch pipeline
+// without sending anything, reporting which notifications each user would have received.
+// It's meant for checking filter, dedup, and dispatch changes against real history
+// before they're deployed.
+//
+// Dispatch follows the notification workflow: active users get new events in their
+// subscribed states and cities, or at courses they follow, that they haven't seen.
+// Immediate users get the soonest MaxMessages events per run after past-event and
+// days-ahead filtering, evaluated at the snapshot's time; daily and weekly users have
+// new events queued for their digest, except those at courses they follow, which are
+// sent right away. Every candidate is marked seen either way, as the workflow does.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/eventquery"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+const (
+	// DefaultMaxMessages matches the --max-messages the notification workflow passes
+	DefaultMaxMessages = 10
+
+	// maxFramePause caps the wait between frames, so a replay of weeks of history at 10x
+	// still finishes
+	maxFramePause = 5 * time.Second
+)
+
+// Notification types in a report
+const (
+	TypeNew    = "new"    // Sent immediately
+	TypeDigest = "digest" // Queued for the user's daily or weekly digest, or a catch-up digest after a pause
+)
+
+// Frame is the full event list at one point in time
+type Frame struct {
+	At     time.Time
+	Source string // File (or history entry) the frame was read from
+	Events []*event.Event
+}
+
+// Notification is one message a user would have received
+type Notification struct {
+	At      time.Time `json:"at"`
+	Type    string    `json:"type"`
+	EventID string    `json:"event_id"`
+	Title   string    `json:"title"`
+	State   string    `json:"state"`
+	Date    string    `json:"date"`
+}
+
+// UserReport lists what one user would have received
+type UserReport struct {
+	ChatID        string          `json:"chat_id"`
+	Notifications []*Notification `json:"notifications"`
+	Filtered      int             `json:"filtered"` // Marked seen but filtered out by past/days-ahead settings
+	OverLimit     int             `json:"over_limit"`
+}
+
+// Count returns how many notifications of a type the user would have received
+func (u *UserReport) Count(notificationType string) int {
+	n := 0
+	for _, notif := range u.Notifications {
+		if notif.Type == notificationType {
+			n++
+		}
+	}
+	return n
+}
+
+// Report summarizes a replay
+type Report struct {
+	Frames        int           `json:"frames"`
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"`
+	NewEvents     int           `json:"new_events"`
+	RemovedEvents int           `json:"removed_events"`
+	Users         []*UserReport `json:"users"`
+}
+
+// Options configures a replay
+type Options struct {
+	// Speed scales the real time between frames: 10 replays ten times faster than the
+	// snapshots were taken. Zero or less replays without pausing.
+	Speed float64
+	// MaxMessages caps immediate notifications per user per frame (default: DefaultMaxMessages)
+	MaxMessages int
+	// OnFrame is called after each frame is dispatched, for progress output
+	OnFrame func(index int, frame *Frame, newEvents, removedEvents int)
+}
+
+// LoadFrames reads archived snapshots from dir, oldest first. A delta history
+// (history.jsonl, written with --history) is used when present; otherwise every
+// snapshot file in the directory is a frame, ordered by its updated_at time.
+func LoadFrames(dir string) ([]*Frame, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	store, err := storage.New(dir)
+	if err != nil {
+		return nil, err
+	}
+	deltas, err := store.LoadHistory("all")
+	if err != nil {
+		return nil, err
+	}
+	if len(deltas) > 0 {
+		return framesFromHistory(deltas), nil
+	}
+
+	return framesFromSnapshots(dir)
+}
+
+// framesFromHistory rebuilds the event set after each delta
+func framesFromHistory(deltas []*storage.SnapshotDelta) []*Frame {
+	frames := make([]*Frame, 0, len(deltas))
+	events := make(map[string]*event.Event)
+	for i, delta := range deltas {
+		storage.ApplyDelta(events, delta)
+		at, _ := time.Parse(time.RFC3339, delta.At)
+		frames = append(frames, &Frame{
+			At:     at,
+			Source: fmt.Sprintf("history #%d", i+1),
+			Events: eventList(events),
+		})
+	}
+	return frames
+}
+
+// framesFromSnapshots reads every snapshot file in dir
+func framesFromSnapshots(dir string) ([]*Frame, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []*Frame
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := storage.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		var snapshot event.Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		if snapshot.Events == nil {
+			continue // Not a snapshot
+		}
+
+		at, err := time.Parse(time.RFC3339, snapshot.UpdatedAt)
+		if err != nil {
+			if info, statErr := entry.Info(); statErr == nil {
+				at = info.ModTime().UTC()
+			}
+		}
+		frames = append(frames, &Frame{At: at, Source: name, Events: eventList(snapshot.Events)})
+	}
+
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].At.Before(frames[j].At) })
+	return frames, nil
+}
+
+// eventList copies events into a list ordered by ID. Events are copied so dedup
+// marking in one frame doesn't leak into the next.
+func eventList(events map[string]*event.Event) []*event.Event {
+	list := make([]*event.Event, 0, len(events))
+	for _, evt := range events {
+		cp := *evt
+		cp.AlsoIn = nil
+		list = append(list, &cp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Run replays frames against prefs. The first frame is the baseline that later frames
+// are diffed against, as if the bot had been running since it was taken. Users start
+// with nothing seen, so the report shows what they'd have received over the period
+// regardless of what they've seen since. prefs is not modified.
+func Run(ctx context.Context, frames []*Frame, prefs preferences.Preferences, opts Options) (*Report, error) {
+	if opts.MaxMessages <= 0 {
+		opts.MaxMessages = DefaultMaxMessages
+	}
+
+	report := &Report{Frames: len(frames)}
+	if len(frames) == 0 {
+		return report, nil
+	}
+	report.From = frames[0].At
+	report.To = frames[len(frames)-1].At
+
+	// Same selection as the workflow: active users with at least one state or city
+	var users []*UserReport
+	seen := make(map[string]map[string]bool)
+	for _, chatID := range prefs.GetAllUsers() {
+		users = append(users, &UserReport{ChatID: chatID, Notifications: []*Notification{}})
+		seen[chatID] = make(map[string]bool)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ChatID < users[j].ChatID })
+	report.Users = users
+
+	previous := event.CreateSnapshot(frames[0].Events, frames[0].At.Format(time.RFC3339))
+	for i, frame := range frames[1:] {
+		if err := pause(ctx, frames[i].At, frame.At, opts.Speed); err != nil {
+			return report, err
+		}
+
+		diff := event.Diff(previous, frame.Events, "ALL")
+		report.NewEvents += len(diff.NewEvents)
+		report.RemovedEvents += len(diff.RemovedEvents)
+
+		for _, ur := range users {
+			dispatch(ur, prefs[ur.ChatID], seen[ur.ChatID], diff.NewEvents, frame.At, opts.MaxMessages)
+		}
+
+		if opts.OnFrame != nil {
+			opts.OnFrame(i+1, frame, len(diff.NewEvents), len(diff.RemovedEvents))
+		}
+		previous = event.CreateSnapshot(frame.Events, frame.At.Format(time.RFC3339))
+	}
+
+	return report, nil
+}
+
+// dispatch decides what one user receives for a frame's new events
+func dispatch(ur *UserReport, user *preferences.UserPreferences, seen map[string]bool, newEvents []*event.Event, at time.Time, maxMessages int) {
+	var candidates []*event.Event
+	for _, evt := range newEvents {
+		if user.MatchesSubscriptions(evt) && !seen[evt.ID] {
+			candidates = append(candidates, evt)
+		}
+	}
+	for _, evt := range candidates {
+		seen[evt.ID] = true
+	}
+
+	// Paused users get everything in their catch-up digest
+	immediate := candidates
+	if user.IsPaused(at) {
+		immediate = nil
+		for _, evt := range candidates {
+			ur.Notifications = append(ur.Notifications, newNotification(evt, TypeDigest, at))
+		}
+	} else if user.DigestFrequency == "daily" || user.DigestFrequency == "weekly" {
+		// Digest users still get events at followed courses right away
+		immediate = nil
+		for _, evt := range candidates {
+			if user.FollowsCourse(evt) {
+				immediate = append(immediate, evt)
+				continue
+			}
+			ur.Notifications = append(ur.Notifications, newNotification(evt, TypeDigest, at))
+		}
+	}
+
+	// The notifier sends the soonest events first, within the user's time filters
+	inRange := eventquery.ForUser(user, at).HidePast().Horizon().Sort().Run(immediate)
+	ur.Filtered += len(immediate) - len(inRange)
+	sent := 0
+	for _, evt := range inRange {
+		if sent == maxMessages {
+			ur.OverLimit++
+			continue
+		}
+		ur.Notifications = append(ur.Notifications, newNotification(evt, TypeNew, at))
+		sent++
+	}
+}
+
+func newNotification(evt *event.Event, notificationType string, at time.Time) *Notification {
+	return &Notification{
+		At:      at,
+		Type:    notificationType,
+		EventID: evt.ID,
+		Title:   evt.Title,
+		State:   evt.State,
+		Date:    evt.DateText,
+	}
+}
+
+// pause waits the scaled time between two frames, capped at maxFramePause
+func pause(ctx context.Context, from, to time.Time, speed float64) error {
+	if speed <= 0 || !to.After(from) {
+		return ctx.Err()
+	}
+	wait := min(time.Duration(float64(to.Sub(from))/speed), maxFramePause)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
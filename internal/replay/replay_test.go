@@ -0,0 +1,209 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func newEvent(id, state, date string) *event.Event {
+	return &event.Event{ID: id, State: state, Title: "Course " + id, DateText: date}
+}
+
+func TestRun(t *testing.T) {
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	frames := []*Frame{
+		{At: day, Events: []*event.Event{newEvent("a", "NV", "06.01.26")}},
+		{At: day.Add(24 * time.Hour), Events: []*event.Event{
+			newEvent("a", "NV", "06.01.26"),
+			newEvent("b", "NV", "06.02.26"),
+			newEvent("c", "CA", "06.03.26"),
+			newEvent("old", "NV", "01.01.26"), // Already past at replay time
+		}},
+		{At: day.Add(48 * time.Hour), Events: []*event.Event{
+			newEvent("b", "NV", "06.02.26"),
+			newEvent("c", "CA", "06.03.26"),
+			newEvent("d", "CA", "06.04.26"),
+		}},
+	}
+
+	prefs := preferences.Preferences{
+		"1": {States: []string{"NV"}, Active: true, HidePastEvents: true},
+		"2": {States: []string{"CA"}, Active: true, DigestFrequency: "daily"},
+		"3": {States: []string{"NV"}, Active: false},
+	}
+
+	frameCalls := 0
+	report, err := Run(context.Background(), frames, prefs, Options{
+		OnFrame: func(int, *Frame, int, int) { frameCalls++ },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Frames != 3 || frameCalls != 2 {
+		t.Errorf("Frames = %d, OnFrame calls = %d; want 3 and 2", report.Frames, frameCalls)
+	}
+	if report.NewEvents != 4 || report.RemovedEvents != 2 {
+		t.Errorf("NewEvents = %d, RemovedEvents = %d; want 4 and 2", report.NewEvents, report.RemovedEvents)
+	}
+	if len(report.Users) != 2 {
+		t.Fatalf("got %d users, want 2 (inactive users are skipped)", len(report.Users))
+	}
+
+	nv := report.Users[0]
+	if nv.ChatID != "1" || nv.Count(TypeNew) != 1 || nv.Notifications[0].EventID != "b" {
+		t.Errorf("NV user notifications = %+v, want only b", nv.Notifications)
+	}
+	if nv.Filtered != 1 {
+		t.Errorf("NV user Filtered = %d, want 1 past event", nv.Filtered)
+	}
+
+	ca := report.Users[1]
+	if ca.Count(TypeDigest) != 2 || ca.Count(TypeNew) != 0 {
+		t.Errorf("CA user notifications = %+v, want c and d queued for digest", ca.Notifications)
+	}
+
+	if len(prefs["1"].SeenEventIDs) != 0 {
+		t.Error("Run() should not modify prefs")
+	}
+}
+
+func TestRunMaxMessages(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var events []*event.Event
+	for _, id := range []string{"a", "b", "c"} {
+		events = append(events, newEvent(id, "NV", "12.01.26"))
+	}
+	frames := []*Frame{{At: start}, {At: start.Add(time.Hour), Events: events}}
+	prefs := preferences.Preferences{"1": {States: []string{"NV"}, Active: true}}
+
+	report, err := Run(context.Background(), frames, prefs, Options{MaxMessages: 2})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if u := report.Users[0]; u.Count(TypeNew) != 2 || u.OverLimit != 1 {
+		t.Errorf("sent %d with %d over limit, want 2 and 1", u.Count(TypeNew), u.OverLimit)
+	}
+}
+
+func TestRunFollowedCourses(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	events := []*event.Event{newEvent("a", "TX", "12.01.26"), newEvent("b", "NV", "12.01.26"), newEvent("c", "AZ", "12.01.26")}
+	frames := []*Frame{{At: start}, {At: start.Add(time.Hour), Events: events}}
+	prefs := preferences.Preferences{"1": {
+		States:          []string{"NV"},
+		FollowedCourses: []string{"Course a"},
+		DigestFrequency: "daily",
+		Active:          true,
+	}}
+
+	report, err := Run(context.Background(), frames, prefs, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// The followed course in another state is sent right away; the NV event waits for the digest
+	u := report.Users[0]
+	if u.Count(TypeNew) != 1 || u.Count(TypeDigest) != 1 {
+		t.Errorf("got %d immediate and %d digest, want 1 and 1", u.Count(TypeNew), u.Count(TypeDigest))
+	}
+}
+
+func TestRunPaused(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	frames := []*Frame{
+		{At: start},
+		{At: start.Add(time.Hour), Events: []*event.Event{newEvent("a", "NV", "12.01.26")}},
+		{At: start.Add(3 * time.Hour), Events: []*event.Event{newEvent("a", "NV", "12.01.26"), newEvent("b", "NV", "12.01.26")}},
+	}
+	prefs := preferences.Preferences{"1": {
+		States:          []string{"NV"},
+		FollowedCourses: []string{"Course a"},
+		PausedUntil:     start.Add(2 * time.Hour).Unix(),
+		Active:          true,
+	}}
+
+	report, err := Run(context.Background(), frames, prefs, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// Even the followed course waits while paused; the event after the pause is sent
+	u := report.Users[0]
+	if u.Count(TypeNew) != 1 || u.Count(TypeDigest) != 1 {
+		t.Errorf("got %d immediate and %d digest, want 1 and 1", u.Count(TypeNew), u.Count(TypeDigest))
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	frames := []*Frame{{At: start}, {At: start.Add(time.Hour)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, frames, preferences.Preferences{}, Options{Speed: 1}); err == nil {
+		t.Error("Run() should stop when ctx is canceled")
+	}
+}
+
+func TestLoadFrames(t *testing.T) {
+	t.Run("snapshot files", func(t *testing.T) {
+		dir := t.TempDir()
+		write := func(name, updatedAt string, events ...*event.Event) {
+			data, err := json.Marshal(event.CreateSnapshot(events, updatedAt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		write("later.json", "2026-03-02T00:00:00Z", newEvent("a", "NV", ""), newEvent("b", "NV", ""))
+		write("earlier.json", "2026-03-01T00:00:00Z", newEvent("a", "NV", ""))
+
+		frames, err := LoadFrames(dir)
+		if err != nil {
+			t.Fatalf("LoadFrames() error = %v", err)
+		}
+		if len(frames) != 2 || frames[0].Source != "earlier.json" || len(frames[1].Events) != 2 {
+			t.Errorf("LoadFrames() = %+v, want earlier.json then later.json", frames)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := storage.New(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		deltas := []*storage.SnapshotDelta{
+			{At: "2026-03-01T00:00:00Z", Added: []*event.Event{newEvent("a", "NV", "")}},
+			{At: "2026-03-02T00:00:00Z", Added: []*event.Event{newEvent("b", "NV", "")}, Removed: []string{"a"}},
+		}
+		for _, d := range deltas {
+			if err := store.AppendHistory("all", d); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		frames, err := LoadFrames(dir)
+		if err != nil {
+			t.Fatalf("LoadFrames() error = %v", err)
+		}
+		if len(frames) != 2 || len(frames[1].Events) != 1 || frames[1].Events[0].ID != "b" {
+			t.Errorf("LoadFrames() = %+v, want the event set after each delta", frames)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := LoadFrames(filepath.Join(t.TempDir(), "nope")); err == nil {
+			t.Error("LoadFrames() should fail for a missing directory")
+		}
+	})
+}
@@ -1,7 +1,11 @@
 // Package scraper provides HTTP fetching and HTML parsing for VGA Golf state events.
 //
-// The scraper package fetches the public state events page from vgagolf.org and extracts
-// event information including state codes, course names, dates, and cities. It handles
+// The scraper package fetches the public state events page from vgagolf.org, plus the
+// national/majors page (whose events get event.ScopeNational), and extracts event
+// information including state codes, course names, dates, and cities. It handles
 // multiple date formats including multi-line dates, embedded dates in titles, and bracketed
 // date formats.
+//
+// Multiple pages are fetched with FetchPages, which uses a bounded worker pool, spaces out
+// requests to the same host, and returns partial results with per-page warnings.
 package scraper
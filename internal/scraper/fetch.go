@@ -0,0 +1,204 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+const (
+	// DefaultConcurrency is how many pages are fetched at once
+	DefaultConcurrency = 4
+	// DefaultHostInterval is the minimum time between requests to the same host
+	DefaultHostInterval = 500 * time.Millisecond
+)
+
+// Options configures how the scraper fetches pages
+type Options struct {
+	Concurrency  int           // Maximum pages fetched at once (default: DefaultConcurrency)
+	HostInterval time.Duration // Minimum time between requests to one host (default: DefaultHostInterval, <0 disables)
+	Contact      string        // Contact info (email or URL) added to the User-Agent
+	IgnoreRobots bool          // Skip robots.txt checks (for tests against local servers)
+	Retry        errs.Backoff  // How pages that fail temporarily are retried (default: errs.DefaultBackoff)
+}
+
+// PageError records a page that failed to fetch or parse
+type PageError struct {
+	URL string
+	Err error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.URL, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// Result holds events from a multi-page fetch along with pages that failed
+type Result struct {
+	Events   []*event.Event // Events from all pages that succeeded, deduplicated by ID
+	Warnings []*PageError   // Pages that failed; Events are partial if non-empty
+}
+
+// hostLimiter spaces out requests to the same host
+type hostLimiter struct {
+	interval  time.Duration
+	mu        sync.Mutex
+	next      map[string]time.Time     // host → earliest time for the next request
+	overrides map[string]time.Duration // host → longer interval (robots.txt Crawl-delay)
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval:  interval,
+		next:      make(map[string]time.Time),
+		overrides: make(map[string]time.Duration),
+	}
+}
+
+// setInterval raises the interval for one host; shorter intervals than the default are ignored
+func (l *hostLimiter) setInterval(host string, interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if interval > l.interval {
+		l.overrides[host] = interval
+	}
+}
+
+// wait blocks until a request to the URL's host is allowed, or returns ctx's error
+// if it's canceled first
+func (l *hostLimiter) wait(ctx context.Context, pageURL string) error {
+	host := pageURL
+	if u, err := url.Parse(pageURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	l.mu.Lock()
+	interval := l.interval
+	if override, ok := l.overrides[host]; ok {
+		interval = override
+	}
+	if interval <= 0 {
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FetchAll fetches the state events listing and the national/majors page and returns
+// their events with any page warnings
+func (s *Scraper) FetchAll(ctx context.Context) (*Result, error) {
+	urls := []string{s.url}
+	if s.majorsURL != "" {
+		urls = append(urls, s.majorsURL)
+	}
+	return s.FetchPages(ctx, urls)
+}
+
+// FetchPages fetches and parses several pages with a bounded worker pool, spacing out
+// requests to the same host and skipping pages robots.txt disallows. Server errors, rate
+// limits, and network failures are retried with backoff; pages that still fail are
+// reported in Result.Warnings and the rest are still returned; an error is only returned
+// when every page fails or ctx is canceled.
+func (s *Scraper) FetchPages(ctx context.Context, urls []string) (*Result, error) {
+	if len(urls) == 0 {
+		return &Result{Events: []*event.Event{}}, nil
+	}
+
+	type pageResult struct {
+		events []*event.Event
+		err    error
+	}
+	results := make([]pageResult, len(urls))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.concurrency, len(urls)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if !s.ignoreRobots {
+					if err := s.checkRobots(ctx, urls[i]); err != nil {
+						results[i] = pageResult{err: err}
+						continue
+					}
+				}
+				var events []*event.Event
+				err := s.retry.Retry(ctx, func() error {
+					if err := s.limiter.wait(ctx, urls[i]); err != nil {
+						return err
+					}
+					var err error
+					events, err = s.fetchPage(ctx, urls[i])
+					return err
+				})
+				results[i] = pageResult{events: events, err: err}
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Partial results from an interrupted fetch would make the missing pages' events look removed
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Aggregate in page order so results are deterministic
+	result := &Result{Events: make([]*event.Event, 0)}
+	seen := make(map[string]*event.Event)
+	var pageErrs []error
+	for i, r := range results {
+		if r.err != nil {
+			pageErr := &PageError{URL: urls[i], Err: r.err}
+			result.Warnings = append(result.Warnings, pageErr)
+			pageErrs = append(pageErrs, pageErr)
+			continue
+		}
+		for _, evt := range r.events {
+			first, ok := seen[evt.ID]
+			if !ok {
+				seen[evt.ID] = evt
+				result.Events = append(result.Events, evt)
+				continue
+			}
+			// An event on both the state and majors pages is a major
+			if evt.IsNational() {
+				first.Scope = event.ScopeNational
+			}
+		}
+	}
+
+	if len(pageErrs) == len(urls) {
+		return nil, errors.Join(pageErrs...)
+	}
+	return result, nil
+}
@@ -0,0 +1,239 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// fastRetry keeps retry tests from waiting out the default backoff
+var fastRetry = errs.Backoff{Attempts: 3, BaseDelay: time.Millisecond}
+
+func TestFetchPages_PartialResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nv":
+			_, _ = w.Write([]byte(`<html><body>NV - Chimera Golf Club 4.4.26 - Las Vegas</body></html>`))
+		case "/ca":
+			_, _ = w.Write([]byte(`<html><body>CA - Pebble Beach Golf Links - Monterey
+NV - Chimera Golf Club 4.4.26 - Las Vegas</body></html>`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	s := NewWithOptions(Options{Concurrency: 2, HostInterval: -1, IgnoreRobots: true, Retry: fastRetry})
+	result, err := s.FetchPages(context.Background(), []string{server.URL + "/nv", server.URL + "/broken", server.URL + "/ca"})
+	if err != nil {
+		t.Fatalf("FetchPages() unexpected error: %v", err)
+	}
+
+	if len(result.Events) != 2 {
+		t.Errorf("expected 2 unique events, got %d", len(result.Events))
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].URL != server.URL+"/broken" {
+		t.Fatalf("expected one warning for /broken, got %v", result.Warnings)
+	}
+}
+
+func TestFetchAll_Majors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/state":
+			_, _ = w.Write([]byte(`<html><body>NV - Chimera Golf Club 4.4.26 - Las Vegas
+NV - VGA Nevada Open 5.2.26 - Mesquite</body></html>`))
+		case "/majors":
+			_, _ = w.Write([]byte(`<html><body>NV - VGA Nevada Open 5.2.26 - Mesquite
+AZ - VGA National Championship 10.10.26 - Scottsdale</body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	s := NewWithOptions(Options{HostInterval: -1, IgnoreRobots: true, Retry: fastRetry})
+	s.url, s.majorsURL = server.URL+"/state", server.URL+"/majors"
+	result, err := s.FetchAll(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAll() unexpected error: %v", err)
+	}
+
+	national := map[string]bool{}
+	for _, evt := range result.Events {
+		national[evt.Title] = evt.IsNational()
+	}
+	want := map[string]bool{
+		"Chimera Golf Club 4.4.26":           false,
+		"VGA Nevada Open 5.2.26":             true, // on both pages
+		"VGA National Championship 10.10.26": true,
+	}
+	if len(national) != len(want) {
+		t.Fatalf("got events %v, want %v", national, want)
+	}
+	for title, isNational := range want {
+		if national[title] != isNational {
+			t.Errorf("%s: IsNational() = %v, want %v", title, national[title], isNational)
+		}
+	}
+}
+
+func TestFetchPages_AllFail(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	s := NewWithOptions(Options{HostInterval: -1, IgnoreRobots: true, Retry: fastRetry})
+	_, err := s.FetchPages(context.Background(), []string{server.URL + "/a", server.URL + "/b"})
+	if err == nil {
+		t.Fatal("FetchPages() expected error when every page fails")
+	}
+
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) {
+		t.Errorf("expected error to wrap *PageError, got %v", err)
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected errs.ErrNotFound, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2 (404s aren't retried)", got)
+	}
+}
+
+func TestFetchPages_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body>NV - Chimera Golf Club 4.4.26 - Las Vegas</body></html>`))
+	}))
+	defer server.Close()
+
+	s := NewWithOptions(Options{HostInterval: -1, IgnoreRobots: true, Retry: fastRetry})
+	result, err := s.FetchPages(context.Background(), []string{server.URL + "/nv"})
+	if err != nil {
+		t.Fatalf("FetchPages() unexpected error: %v", err)
+	}
+	if len(result.Events) != 1 || len(result.Warnings) != 0 {
+		t.Errorf("expected 1 event and no warnings, got %d events, %v", len(result.Events), result.Warnings)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server called %d times, want 3", got)
+	}
+}
+
+func TestFetchPages_BoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			peak := atomic.LoadInt32(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`<html><body>NV - Event ` + r.URL.Path + ` - Reno</body></html>`))
+	}))
+	defer server.Close()
+
+	urls := make([]string, 8)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/page%d", server.URL, i)
+	}
+
+	s := NewWithOptions(Options{Concurrency: 3, HostInterval: -1, IgnoreRobots: true})
+	result, err := s.FetchPages(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("FetchPages() unexpected error: %v", err)
+	}
+	if len(result.Events) != len(urls) {
+		t.Errorf("expected %d events, got %d", len(urls), len(result.Events))
+	}
+	if peak := atomic.LoadInt32(&maxInFlight); peak > 3 {
+		t.Errorf("max concurrent requests = %d, want <= 3", peak)
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	limiter := newHostLimiter(30 * time.Millisecond)
+
+	var mu sync.Mutex
+	var times []time.Time
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.wait(context.Background(), "https://vgagolf.org/state-events/")
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Other hosts aren't delayed
+	start := time.Now()
+	limiter.wait(context.Background(), "https://example.com/")
+	if time.Since(start) > 20*time.Millisecond {
+		t.Error("request to a different host should not wait")
+	}
+
+	first, last := times[0], times[0]
+	for _, ts := range times {
+		if ts.Before(first) {
+			first = ts
+		}
+		if ts.After(last) {
+			last = ts
+		}
+	}
+	if spread := last.Sub(first); spread < 55*time.Millisecond {
+		t.Errorf("3 requests to one host spread over %v, want >= 60ms", spread)
+	}
+}
+
+func TestHostLimiter_Canceled(t *testing.T) {
+	limiter := newHostLimiter(time.Hour)
+	_ = limiter.wait(context.Background(), "https://vgagolf.org/") // Takes the first slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := limiter.wait(ctx, "https://vgagolf.org/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait() returned after %v, want prompt return on cancel", elapsed)
+	}
+}
+
+func TestFetchPages_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>NV - Chimera Golf Club 4.4.26 - Las Vegas</body></html>`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := NewWithOptions(Options{HostInterval: -1, IgnoreRobots: true})
+	if _, err := s.FetchPages(ctx, []string{server.URL + "/nv"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchPages() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,219 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsAgent is the product token matched against User-agent lines in robots.txt
+const robotsAgent = "vga-events"
+
+// maxRobotsSize caps how much of a robots.txt file is read (RFC 9309 requires at least 500 KiB)
+const maxRobotsSize = 512 * 1024
+
+// ErrDisallowed is returned for pages that robots.txt doesn't allow us to fetch
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsRule is one Allow or Disallow line
+type robotsRule struct {
+	path  string
+	allow bool
+}
+
+// robotsRules are the rules from a host's robots.txt that apply to us
+type robotsRules struct {
+	rules       []robotsRule
+	crawlDelay  time.Duration
+	disallowAll bool // robots.txt was unreachable (server error), so nothing may be fetched
+}
+
+// allows reports whether a path may be fetched. The longest matching rule wins and
+// Allow wins ties, as in RFC 9309.
+func (r *robotsRules) allows(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	best := -1
+	allowed := true
+	for _, rule := range r.rules {
+		if !robotsPathMatch(rule.path, path) {
+			continue
+		}
+		if n := len(rule.path); n > best || (n == best && rule.allow) {
+			best = n
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsPathMatch matches a robots.txt path pattern, supporting * wildcards and a trailing $
+func robotsPathMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	return err == nil && re.MatchString(path)
+}
+
+// parseRobots extracts the rules for agent from a robots.txt file. A group naming the
+// agent is used if present, otherwise the "*" group.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+
+	var specific, wildcard *robotsRules
+	var current []*robotsRules // groups the current rules apply to
+	inRules := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				current = nil
+				inRules = false
+			}
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case strings.Contains(agent, name):
+				if specific == nil {
+					specific = &robotsRules{}
+				}
+				current = append(current, specific)
+			}
+
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // "Disallow:" with no path allows everything
+			}
+			for _, group := range current {
+				group.rules = append(group.rules, robotsRule{path: value, allow: key == "allow"})
+			}
+
+		case "crawl-delay":
+			inRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				for _, group := range current {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if specific != nil {
+		return specific
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return &robotsRules{}
+}
+
+// robotsCache fetches and caches robots.txt rules per host
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsRules
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{hosts: make(map[string]*robotsRules)}
+}
+
+// checkRobots returns ErrDisallowed if robots.txt doesn't allow fetching pageURL.
+// The host's robots.txt is fetched on first use; any Crawl-delay is applied to the limiter.
+func (s *Scraper) checkRobots(ctx context.Context, pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return fmt.Errorf("parsing URL: %w", err)
+	}
+
+	s.robots.mu.Lock()
+	rules, ok := s.robots.hosts[u.Host]
+	if !ok {
+		rules = s.fetchRobots(ctx, u)
+		s.robots.hosts[u.Host] = rules
+		if rules.crawlDelay > 0 {
+			s.limiter.setInterval(u.Host, rules.crawlDelay)
+		}
+	}
+	s.robots.mu.Unlock()
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !rules.allows(path) {
+		return ErrDisallowed
+	}
+	return nil
+}
+
+// fetchRobots downloads a host's robots.txt. Per RFC 9309, a missing file (4xx) allows
+// everything and an unreachable one (5xx or network error) allows nothing.
+func (s *Scraper) fetchRobots(ctx context.Context, u *url.URL) *robotsRules {
+	robotsURL := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}).String()
+	if err := s.limiter.wait(ctx, robotsURL); err != nil {
+		return &robotsRules{disallowAll: true}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return &robotsRules{disallowAll: true}
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &robotsRules{disallowAll: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsRules{disallowAll: true}
+	case resp.StatusCode >= 400:
+		return &robotsRules{}
+	}
+	return parseRobots(resp.Body, robotsAgent)
+}
@@ -0,0 +1,125 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleRobots = `# Sample robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public-page
+Crawl-delay: 2
+
+User-agent: BadBot
+Disallow: /
+
+User-agent: vga-events
+Disallow: /wp-admin/
+Disallow: /*.pdf$
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent string
+		path  string
+		want  bool
+	}{
+		{"specific group used", "vga-events", "/private/page", true},
+		{"specific disallow", "vga-events", "/wp-admin/settings", false},
+		{"wildcard with anchor", "vga-events", "/files/schedule.pdf", false},
+		{"anchor requires end", "vga-events", "/files/schedule.pdf?x=1", true},
+		{"falls back to star group", "other-bot", "/private/page", false},
+		{"longest match allows", "other-bot", "/private/public-page", true},
+		{"other bot's group ignored", "other-bot", "/state-events/", true},
+		{"named bot blocked", "badbot", "/state-events/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(sampleRobots), tt.agent)
+			if got := rules.allows(tt.path); got != tt.want {
+				t.Errorf("allows(%q) for %s = %v, want %v", tt.path, tt.agent, got, tt.want)
+			}
+		})
+	}
+
+	if rules := parseRobots(strings.NewReader(sampleRobots), "other-bot"); rules.crawlDelay != 2*time.Second {
+		t.Errorf("crawl delay = %v, want 2s", rules.crawlDelay)
+	}
+	if rules := parseRobots(strings.NewReader(""), "vga-events"); !rules.allows("/anything") {
+		t.Error("empty robots.txt should allow everything")
+	}
+}
+
+func TestFetchPages_RespectsRobots(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+		default:
+			_, _ = w.Write([]byte(`<html><body>NV - Chimera Golf Club 4.4.26 - Las Vegas</body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	s := NewWithOptions(Options{Concurrency: 1, HostInterval: -1, Contact: "golf@example.com"})
+	result, err := s.FetchPages(context.Background(), []string{server.URL + "/events", server.URL + "/blocked"})
+	if err != nil {
+		t.Fatalf("FetchPages() unexpected error: %v", err)
+	}
+
+	if len(result.Events) != 1 {
+		t.Errorf("expected 1 event from the allowed page, got %d", len(result.Events))
+	}
+	if len(result.Warnings) != 1 || !errors.Is(result.Warnings[0], ErrDisallowed) {
+		t.Errorf("expected ErrDisallowed warning, got %v", result.Warnings)
+	}
+
+	// robots.txt is fetched once, and every request identifies us
+	if len(userAgents) != 2 {
+		t.Errorf("expected robots.txt + 1 page request, got %d requests", len(userAgents))
+	}
+	for _, ua := range userAgents {
+		if !strings.Contains(ua, "github.com/pfrederiksen/vga-events") || !strings.Contains(ua, "golf@example.com") {
+			t.Errorf("User-Agent %q should include project URL and contact", ua)
+		}
+	}
+}
+
+func TestFetchPages_RobotsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`NV - Chimera Golf Club 4.4.26 - Las Vegas`))
+	}))
+	defer server.Close()
+
+	s := NewWithOptions(Options{HostInterval: -1})
+	if _, err := s.FetchPages(context.Background(), []string{server.URL + "/events"}); !errors.Is(err, ErrDisallowed) {
+		t.Errorf("expected ErrDisallowed when robots.txt returns 5xx, got %v", err)
+	}
+}
+
+func TestCrawlDelayRaisesInterval(t *testing.T) {
+	limiter := newHostLimiter(100 * time.Millisecond)
+	limiter.setInterval("vgagolf.org", 50*time.Millisecond)
+	if _, ok := limiter.overrides["vgagolf.org"]; ok {
+		t.Error("shorter crawl delay should not lower the interval")
+	}
+
+	limiter.setInterval("vgagolf.org", 3*time.Second)
+	if got := limiter.overrides["vgagolf.org"]; got != 3*time.Second {
+		t.Errorf("override = %v, want 3s", got)
+	}
+}
@@ -1,46 +1,96 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/pfrederiksen/vga-events/internal/errs"
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/geo"
 )
 
 const (
 	StateEventsURL = "https://vgagolf.org/state-events/"
-	UserAgent      = "vga-events-cli/1.0 (github.com/pfrederiksen/vga-events)"
+	// MajorEventsURL lists national championships and majors, in the same line format
+	// as the state events page
+	MajorEventsURL = "https://vgagolf.org/national-events/"
+	UserAgent      = "vga-events/1.0 (+https://github.com/pfrederiksen/vga-events; contact: https://github.com/pfrederiksen/vga-events/issues)"
 	Timeout        = 30 * time.Second
 )
 
 // Scraper handles fetching and parsing VGA Golf state events
 type Scraper struct {
-	client *http.Client
-	url    string
+	client       *http.Client
+	url          string
+	majorsURL    string // "" skips the national/majors page
+	userAgent    string
+	concurrency  int
+	limiter      *hostLimiter
+	retry        errs.Backoff
+	robots       *robotsCache
+	ignoreRobots bool
 }
 
-// New creates a new Scraper instance
+// New creates a new Scraper instance with default options
 func New() *Scraper {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates a new Scraper with custom fetch options
+func NewWithOptions(opts Options) *Scraper {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.HostInterval == 0 {
+		opts.HostInterval = DefaultHostInterval
+	}
+	if opts.Retry.Attempts <= 0 {
+		opts.Retry = errs.DefaultBackoff
+	}
+
+	userAgent := UserAgent
+	if opts.Contact != "" {
+		userAgent = fmt.Sprintf("vga-events/1.0 (+https://github.com/pfrederiksen/vga-events; contact: %s)", opts.Contact)
+	}
+
 	return &Scraper{
 		client: &http.Client{
 			Timeout: Timeout,
 		},
-		url: StateEventsURL,
+		url:          StateEventsURL,
+		majorsURL:    MajorEventsURL,
+		userAgent:    userAgent,
+		concurrency:  opts.Concurrency,
+		limiter:      newHostLimiter(opts.HostInterval),
+		retry:        opts.Retry,
+		robots:       newRobotsCache(),
+		ignoreRobots: opts.IgnoreRobots,
 	}
 }
 
-// FetchEvents fetches and parses all state events from the VGA Golf website
-func (s *Scraper) FetchEvents() ([]*event.Event, error) {
-	req, err := http.NewRequest("GET", s.url, nil)
+// FetchEvents fetches and parses all state and national events from the VGA Golf website
+func (s *Scraper) FetchEvents(ctx context.Context) ([]*event.Event, error) {
+	result, err := s.FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.Events, nil
+}
+
+// fetchPage fetches and parses a single page
+func (s *Scraper) fetchPage(ctx context.Context, pageURL string) ([]*event.Event, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
-	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("User-Agent", s.userAgent)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -49,10 +99,21 @@ func (s *Scraper) FetchEvents() ([]*event.Event, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		statusErr := errs.Status(resp.StatusCode, "unexpected status code: %d", resp.StatusCode)
+		statusErr.RetryAfter = errs.ParseRetryAfter(resp.Header)
+		return nil, statusErr
 	}
 
-	return s.parseEvents(resp.Body, s.url)
+	events, err := s.parseEvents(resp.Body, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	if pageURL == s.majorsURL {
+		for _, evt := range events {
+			evt.Scope = event.ScopeNational
+		}
+	}
+	return events, nil
 }
 
 // parseEvents extracts events from HTML
@@ -78,6 +139,16 @@ func (s *Scraper) parseEvents(r io.Reader, sourceURL string) ([]*event.Event, er
 	dateEventPattern := regexp.MustCompile(`^\[(.*?)\]\s+([A-Z]{2})\s*-\s*(.+?)\s*-\s*(.+)$`)
 	dateEventPatternNoCity := regexp.MustCompile(`^\[(.*?)\]\s+([A-Z]{2})\s*-\s*(.+)$`)
 
+	// Pattern to match a registration deadline listed under an event:
+	// "Registration closes Mar 28 2026", "Register by: 3.28.26", "Deadline - Mar 28"
+	deadlinePattern := regexp.MustCompile(`(?i)^(?:registration\s+(?:deadline|closes)|register\s+by|deadline)\s*[:-]?\s*(.+)$`)
+
+	// Patterns to match a field size listed under an event: "Field: 64 / 72",
+	// "Players - 64 of 72", "64/72 spots filled", or just the limit, "Field size: 72"
+	fieldPattern := regexp.MustCompile(`(?i)^(?:field(?:\s+size)?|players|spots|registered)\s*[:-]?\s*(\d+)\s*(?:/|of)\s*(\d+)(?:\s+(?:spots|players))?(?:\s+(?:filled|taken|registered))?$`)
+	fieldCountPattern := regexp.MustCompile(`(?i)^(\d+)\s*(?:/|of)\s*(\d+)\s+(?:spots|players)(?:\s+(?:filled|taken|registered))?$`)
+	fieldSizePattern := regexp.MustCompile(`(?i)^field(?:\s+size)?\s*[:-]?\s*(\d+)(?:\s+(?:spots|players))?$`)
+
 	// Get all text content and process line by line to preserve order of dates and events
 	allText := doc.Text()
 	lines := strings.Split(allText, "\n")
@@ -95,6 +166,38 @@ func (s *Scraper) parseEvents(r io.Reader, sourceURL string) ([]*event.Event, er
 			continue
 		}
 
+		// A deadline line belongs to the event listed just before it
+		if matches := deadlinePattern.FindStringSubmatch(line); matches != nil {
+			if len(events) > 0 {
+				deadline := strings.Trim(strings.TrimSpace(matches[1]), "[]")
+				// Keep just the date from text like "Mar 28, 2026 at 5pm" so it parses
+				if d := extractDate(deadline); d != "" && event.ParseDate(deadline).IsZero() {
+					deadline = d
+				}
+				events[len(events)-1].RegistrationDeadline = deadline
+			}
+			continue
+		}
+
+		// A field size line belongs to the event listed just before it
+		fieldMatches := fieldPattern.FindStringSubmatch(line)
+		if fieldMatches == nil {
+			fieldMatches = fieldCountPattern.FindStringSubmatch(line)
+		}
+		if fieldMatches != nil {
+			if len(events) > 0 {
+				events[len(events)-1].FieldFilled, _ = strconv.Atoi(fieldMatches[1])
+				events[len(events)-1].FieldSize, _ = strconv.Atoi(fieldMatches[2])
+			}
+			continue
+		}
+		if matches := fieldSizePattern.FindStringSubmatch(line); matches != nil {
+			if len(events) > 0 {
+				events[len(events)-1].FieldSize, _ = strconv.Atoi(matches[1])
+			}
+			continue
+		}
+
 		// Check if this line is a month name
 		if monthPattern.MatchString(line) {
 			recentMonth = line
@@ -121,7 +224,7 @@ func (s *Scraper) parseEvents(r io.Reader, sourceURL string) ([]*event.Event, er
 			dateText := strings.TrimSpace(matches[1])
 			state := matches[2]
 			title := strings.TrimSpace(matches[3])
-			city := strings.TrimSpace(matches[4])
+			city := geo.CleanCity(matches[4], state)
 
 			// Extract raw event line without the date prefix
 			rawLine := strings.TrimSpace(strings.TrimPrefix(line, "["+matches[1]+"]"))
@@ -160,7 +263,7 @@ func (s *Scraper) parseEvents(r io.Reader, sourceURL string) ([]*event.Event, er
 		if matches := stateEventPattern.FindStringSubmatch(line); matches != nil {
 			state := matches[1]
 			title := strings.TrimSpace(matches[2])
-			city := strings.TrimSpace(matches[3])
+			city := geo.CleanCity(matches[3], state)
 
 			// Use bracketed date if available, otherwise extract from title
 			dateText := currentDate
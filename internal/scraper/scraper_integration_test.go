@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -67,8 +68,9 @@ func TestFetchEvents(t *testing.T) {
 			// Create scraper with test server URL
 			scraper := New()
 			scraper.url = server.URL
+			scraper.majorsURL = ""
 
-			events, err := scraper.FetchEvents()
+			events, err := scraper.FetchEvents(context.Background())
 
 			if tt.wantError {
 				if err == nil {
@@ -82,3 +82,85 @@ func TestExtractDate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEventsRegistrationDeadline(t *testing.T) {
+	page := `<html><body><pre>
+[Apr 4 2026] NV - Chimera Golf Club - Las Vegas
+Registration closes Mar 28 2026
+[Apr 11 2026] NV - Wolf Creek - Mesquite
+[Apr 18 2026] AZ - Longbow Golf Club - Mesa
+Register by: Apr 10, 2026 at 5pm
+</pre></body></html>`
+
+	events, err := New().parseEvents(strings.NewReader(page), "https://test.example.com")
+	if err != nil {
+		t.Fatalf("parseEvents failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	want := []string{"Mar 28 2026", "", "Apr 10"}
+	for i, evt := range events {
+		if evt.RegistrationDeadline != want[i] {
+			t.Errorf("%s: RegistrationDeadline = %q, want %q", evt.Title, evt.RegistrationDeadline, want[i])
+		}
+	}
+}
+
+func TestParseEventsCleansCities(t *testing.T) {
+	page := `<html><body><pre>
+[Apr 4 2026] NV - Aliante Golf Club - N. Las  Vegas
+[Apr 11 2026] NV - Revere Golf Club - Henderson NV
+NV - Wolf Creek 4.18.26 - MESQUITE
+</pre></body></html>`
+
+	events, err := New().parseEvents(strings.NewReader(page), "https://test.example.com")
+	if err != nil {
+		t.Fatalf("parseEvents failed: %v", err)
+	}
+
+	want := []string{"North Las Vegas", "Henderson", "Mesquite"}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, evt := range events {
+		if evt.City != want[i] {
+			t.Errorf("%s: City = %q, want %q", evt.Title, evt.City, want[i])
+		}
+	}
+	if !strings.HasSuffix(events[1].Raw, "Henderson NV") {
+		t.Errorf("Raw = %q, want the line as scraped (event IDs are based on it)", events[1].Raw)
+	}
+}
+
+func TestParseEventsFieldSize(t *testing.T) {
+	page := `<html><body><pre>
+[Apr 4 2026] NV - Chimera Golf Club - Las Vegas
+Field: 64 / 72
+[Apr 11 2026] NV - Wolf Creek - Mesquite
+Registration closes Apr 1 2026
+60 of 60 spots filled
+[Apr 18 2026] AZ - Longbow Golf Club - Mesa
+Field size: 48
+[Apr 25 2026] AZ - Quintero Golf Club - Peoria
+</pre></body></html>`
+
+	events, err := New().parseEvents(strings.NewReader(page), "https://test.example.com")
+	if err != nil {
+		t.Fatalf("parseEvents failed: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	want := [][2]int{{64, 72}, {60, 60}, {0, 48}, {0, 0}}
+	for i, evt := range events {
+		if evt.FieldFilled != want[i][0] || evt.FieldSize != want[i][1] {
+			t.Errorf("%s: field = %d / %d, want %d / %d", evt.Title, evt.FieldFilled, evt.FieldSize, want[i][0], want[i][1])
+		}
+	}
+	if events[1].RegistrationDeadline != "Apr 1 2026" {
+		t.Errorf("deadline = %q, want it kept alongside the field size", events[1].RegistrationDeadline)
+	}
+}
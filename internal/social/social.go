@@ -0,0 +1,219 @@
+// Package social formats event announcements for social channels (Twitter, Bluesky,
+// Mastodon). Post text comes from a template, and each state can add its own hashtags
+// and @mentions (such as @VGANevada) from a JSON config file instead of the formatter.
+package social
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// Channels and their post length limits in characters
+const (
+	ChannelTwitter  = "twitter"
+	ChannelBluesky  = "bluesky"
+	ChannelMastodon = "mastodon"
+)
+
+var channelLimits = map[string]int{
+	ChannelTwitter:  280,
+	ChannelBluesky:  300,
+	ChannelMastodon: 500,
+}
+
+// DefaultTemplate is the post text when the config doesn't set one
+const DefaultTemplate = `⛳ New VGA Golf event in {{.StateName}}!
+{{.Event.Title}}
+📅 {{.Event.DateText}}{{with .Event.City}} · {{.}}{{end}}
+{{.URL}}{{with .Tags}}
+{{.}}{{end}}`
+
+var (
+	hashtagPattern = regexp.MustCompile(`^#?[\p{L}\p{N}_]{1,50}$`)
+	mentionPattern = regexp.MustCompile(`^@?[A-Za-z0-9_]{1,30}(@[A-Za-z0-9.-]+\.[A-Za-z]{2,})?$`) // @user or Mastodon's @user@instance
+)
+
+// Tags are the hashtags and mentions added to a post
+type Tags struct {
+	Hashtags []string `json:"hashtags,omitempty"`
+	Mentions []string `json:"mentions,omitempty"`
+}
+
+// Config is the social post configuration
+type Config struct {
+	Template string          `json:"template,omitempty"` // text/template source, DefaultTemplate if empty
+	Default  Tags            `json:"default"`            // Added to every post
+	States   map[string]Tags `json:"states,omitempty"`   // Added to posts for events in the state
+
+	tmpl *template.Template
+}
+
+// postData is what the post template renders
+type postData struct {
+	Event     *event.Event
+	StateName string
+	URL       string // Event's link (or the registration page), tagged for the channel
+	Hashtags  string // Space-separated, with "#"
+	Mentions  string // Space-separated, with "@"
+	Tags      string // Mentions then hashtags
+}
+
+// NewConfig returns the configuration with the default template and no tags
+func NewConfig() *Config {
+	c := &Config{}
+	if err := c.validate(); err != nil {
+		panic(err) // The default template always parses
+	}
+	return c
+}
+
+// LoadConfig reads the social configuration from a JSON file, e.g.
+// {"default": {"hashtags": ["golf"]}, "states": {"NV": {"hashtags": ["VegasGolf"], "mentions": ["VGANevada"]}}}.
+// Hashtags and mentions may be written with or without "#" and "@".
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - Path comes from the operator's flag
+	if err != nil {
+		return nil, fmt.Errorf("reading social config: %w", err)
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing social config: %w", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// validate normalizes tags and state codes and parses the template
+func (c *Config) validate() error {
+	if err := c.Default.normalize("default"); err != nil {
+		return err
+	}
+
+	states := make(map[string]Tags, len(c.States))
+	for code, tags := range c.States {
+		state := strings.ToUpper(strings.TrimSpace(code))
+		if !preferences.IsValidState(state) {
+			return fmt.Errorf("social config: invalid state code %q", code)
+		}
+		if err := tags.normalize(state); err != nil {
+			return err
+		}
+		states[state] = tags
+	}
+	c.States = states
+
+	source := c.Template
+	if source == "" {
+		source = DefaultTemplate
+	}
+	tmpl, err := template.New("post").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return fmt.Errorf("social config: parsing template: %w", err)
+	}
+	c.tmpl = tmpl
+	return nil
+}
+
+// normalize checks each tag and adds the missing "#" or "@"
+func (t *Tags) normalize(scope string) error {
+	for i, tag := range t.Hashtags {
+		tag = strings.TrimSpace(tag)
+		if !hashtagPattern.MatchString(tag) {
+			return fmt.Errorf("social config %s: invalid hashtag %q", scope, t.Hashtags[i])
+		}
+		t.Hashtags[i] = "#" + strings.TrimPrefix(tag, "#")
+	}
+	for i, mention := range t.Mentions {
+		mention = strings.TrimSpace(mention)
+		if !mentionPattern.MatchString(mention) {
+			return fmt.Errorf("social config %s: invalid mention %q", scope, t.Mentions[i])
+		}
+		t.Mentions[i] = "@" + strings.TrimPrefix(mention, "@")
+	}
+	return nil
+}
+
+// TagsFor returns the default tags plus the state's, without duplicates
+func (c *Config) TagsFor(state string) Tags {
+	var tags Tags
+	seen := make(map[string]bool)
+	add := func(list *[]string, items []string) {
+		for _, item := range items {
+			if key := strings.ToLower(item); !seen[key] {
+				seen[key] = true
+				*list = append(*list, item)
+			}
+		}
+	}
+
+	stateTags := c.States[strings.ToUpper(state)]
+	add(&tags.Mentions, stateTags.Mentions)
+	add(&tags.Mentions, c.Default.Mentions)
+	add(&tags.Hashtags, stateTags.Hashtags)
+	add(&tags.Hashtags, c.Default.Hashtags)
+	return tags
+}
+
+// FormatPost renders the post for an event on a channel. Hashtags are dropped from
+// the end, then mentions, until the post fits the channel's length limit.
+func (c *Config) FormatPost(channel string, evt *event.Event) (string, error) {
+	limit, ok := channelLimits[channel]
+	if !ok {
+		return "", fmt.Errorf("unknown social channel %q", channel)
+	}
+
+	tags := c.TagsFor(evt.State)
+	for {
+		post, err := c.render(channel, evt, tags)
+		if err != nil {
+			return "", err
+		}
+		if utf8.RuneCountInString(post) <= limit {
+			return post, nil
+		}
+
+		switch {
+		case len(tags.Hashtags) > 0:
+			tags.Hashtags = tags.Hashtags[:len(tags.Hashtags)-1]
+		case len(tags.Mentions) > 0:
+			tags.Mentions = tags.Mentions[:len(tags.Mentions)-1]
+		default:
+			return "", fmt.Errorf("%s post for event %s is %d characters, over the %d limit", channel, evt.ID, utf8.RuneCountInString(post), limit)
+		}
+	}
+}
+
+// render executes the template with the given tags
+func (c *Config) render(channel string, evt *event.Event, tags Tags) (string, error) {
+	link := evt.SourceURL
+	if link == "" {
+		link = links.RegistrationURL
+	}
+	data := postData{
+		Event:     evt,
+		StateName: preferences.GetStateName(evt.State),
+		URL:       links.Track(link, evt.ID, channel, links.CampaignNewEvent),
+		Hashtags:  strings.Join(tags.Hashtags, " "),
+		Mentions:  strings.Join(tags.Mentions, " "),
+	}
+	data.Tags = strings.TrimSpace(data.Mentions + " " + data.Hashtags)
+
+	var buf bytes.Buffer
+	if err := c.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering post: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
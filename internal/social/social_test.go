@@ -0,0 +1,183 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "social.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig(writeConfig(t, `{
+		"default": {"hashtags": ["golf", "#VGAGolf"]},
+		"states": {"nv": {"hashtags": ["VegasGolf", "golf"], "mentions": ["VGANevada", "@golf@mastodon.social"]}}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	tags := c.TagsFor("NV")
+	if got := strings.Join(tags.Hashtags, " "); got != "#VegasGolf #golf #VGAGolf" {
+		t.Errorf("NV hashtags = %q", got)
+	}
+	if got := strings.Join(tags.Mentions, " "); got != "@VGANevada @golf@mastodon.social" {
+		t.Errorf("NV mentions = %q", got)
+	}
+	if got := strings.Join(c.TagsFor("CA").Hashtags, " "); got != "#golf #VGAGolf" {
+		t.Errorf("CA should only get the default hashtags, got %q", got)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"state":    `{"states": {"XX": {}}}`,
+		"hashtag":  `{"default": {"hashtags": ["two words"]}}`,
+		"mention":  `{"states": {"NV": {"mentions": ["not a handle!"]}}}`,
+		"template": `{"template": "{{.Event.Title"}`,
+		"json":     `{`,
+	} {
+		if _, err := LoadConfig(writeConfig(t, content)); err == nil {
+			t.Errorf("%s: LoadConfig() should fail", name)
+		}
+	}
+}
+
+func TestFormatPost(t *testing.T) {
+	c, err := LoadConfig(writeConfig(t, `{"states": {"NV": {"hashtags": ["VegasGolf"], "mentions": ["VGANevada"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt := &event.Event{ID: "nv1", State: "NV", Title: "Wolf Creek", DateText: "Apr 12 2026", City: "Mesquite", SourceURL: "https://vgagolf.org/e/1"}
+
+	post, err := c.FormatPost(ChannelTwitter, evt)
+	if err != nil {
+		t.Fatalf("FormatPost() error = %v", err)
+	}
+	for _, want := range []string{"in Nevada", "Wolf Creek", "Apr 12 2026 · Mesquite", "https://vgagolf.org/e/1", "@VGANevada #VegasGolf"} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post missing %q:\n%s", want, post)
+		}
+	}
+
+	if _, err := c.FormatPost("myspace", evt); err == nil {
+		t.Error("unknown channel should fail")
+	}
+}
+
+func TestFormatPostDropsTagsToFit(t *testing.T) {
+	c := NewConfig()
+	c.Default.Hashtags = []string{"#one", "#two"}
+	c.Default.Mentions = []string{"@someone"}
+
+	evt := &event.Event{ID: "long", State: "CA", Title: strings.Repeat("x", 180), DateText: "May 1 2026"}
+	post, err := c.FormatPost(ChannelTwitter, evt)
+	if err != nil {
+		t.Fatalf("FormatPost() error = %v", err)
+	}
+	if !strings.Contains(post, "@someone") || strings.Contains(post, "#two") {
+		t.Errorf("hashtags should be dropped before mentions:\n%s", post)
+	}
+
+	// Mastodon's higher limit keeps everything
+	if post, _ := c.FormatPost(ChannelMastodon, evt); !strings.Contains(post, "#two") {
+		t.Errorf("mastodon post should keep all tags:\n%s", post)
+	}
+
+	evt.Title = strings.Repeat("x", 400)
+	if _, err := c.FormatPost(ChannelTwitter, evt); err == nil {
+		t.Error("a post that can't fit should fail")
+	}
+}
+
+func TestCustomTemplate(t *testing.T) {
+	c, err := LoadConfig(writeConfig(t, `{"template": "{{.Mentions}}: {{.Event.Title}} ({{.Event.State}}) {{.Hashtags}}", "states": {"AZ": {"hashtags": ["AZGolf"], "mentions": ["VGAArizona"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := c.FormatPost(ChannelBluesky, &event.Event{State: "AZ", Title: "Troon North"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post != "@VGAArizona: Troon North (AZ) #AZGolf" {
+		t.Errorf("post = %q", post)
+	}
+}
+
+func TestFormatSummaryPost(t *testing.T) {
+	c, err := LoadConfig(writeConfig(t, `{"default": {"hashtags": ["VGAGolf"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := c.FormatSummaryPost(ChannelTwitter, map[string]event.DiffCounts{
+		"NV": {New: 2, Removed: 1},
+		"CA": {New: 1, Changed: 1},
+		"AZ": {New: 1},
+	})
+	if err != nil {
+		t.Fatalf("FormatSummaryPost() error = %v", err)
+	}
+	for _, want := range []string{"4 new, 1 changed, 1 removed", "NV +2 −1 · CA +1 ~1 · AZ +1", "#VGAGolf"} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post missing %q:\n%s", want, post)
+		}
+	}
+
+	// Every state won't fit in a tweet, so the quietest are summarized
+	many := make(map[string]event.DiffCounts)
+	for _, state := range []string{"AL", "AZ", "CA", "CO", "FL", "GA", "IL", "MI", "NV", "NY", "OH", "OR", "PA", "SC", "TX", "UT", "VA", "WA", "WI", "NC", "NM", "NJ", "MN", "MO", "MA", "KY", "LA", "ID"} {
+		many[state] = event.DiffCounts{New: 10, Changed: 10, Removed: 10}
+	}
+	post, err = c.FormatSummaryPost(ChannelTwitter, many)
+	if err != nil {
+		t.Fatalf("FormatSummaryPost() error = %v", err)
+	}
+	if !strings.Contains(post, "more states") || utf8.RuneCountInString(post) > 280 {
+		t.Errorf("long summary should be trimmed to fit:\n%s", post)
+	}
+}
+
+func TestTwitterPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer user-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["text"] != "hello" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data": {"id": "1234", "text": "hello"}}`))
+	}))
+	defer server.Close()
+
+	orig := twitterURL
+	twitterURL = server.URL
+	defer func() { twitterURL = orig }()
+
+	id, err := NewTwitter("user-token").Post(context.Background(), "hello")
+	if err != nil || id != "1234" {
+		t.Errorf("Post() = %q, %v", id, err)
+	}
+	if _, err := NewTwitter("wrong").Post(context.Background(), "hello"); err == nil {
+		t.Error("Post() with a bad token should fail")
+	}
+}
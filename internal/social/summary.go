@@ -0,0 +1,77 @@
+package social
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
+)
+
+// FormatSummaryPost formats one run's diff as a single post for a channel: totals, the
+// busiest states ("NV +2 −1"), the registration link, and the default hashtags. States are
+// dropped from the end until the post fits the channel's limit.
+func (c *Config) FormatSummaryPost(channel string, counts map[string]event.DiffCounts) (string, error) {
+	limit, ok := channelLimits[channel]
+	if !ok {
+		return "", fmt.Errorf("unknown social channel %q", channel)
+	}
+
+	var total event.DiffCounts
+	states := make([]string, 0, len(counts))
+	for state, n := range counts {
+		total.New += n.New
+		total.Changed += n.Changed
+		total.Removed += n.Removed
+		if state != "" {
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		ti, tj := counts[states[i]].Total(), counts[states[j]].Total()
+		if ti != tj {
+			return ti > tj
+		}
+		return states[i] < states[j]
+	})
+
+	var lines []string
+	for _, state := range states {
+		n := counts[state]
+		line := state
+		if n.New > 0 {
+			line += fmt.Sprintf(" +%d", n.New)
+		}
+		if n.Changed > 0 {
+			line += fmt.Sprintf(" ~%d", n.Changed)
+		}
+		if n.Removed > 0 {
+			line += fmt.Sprintf(" −%d", n.Removed)
+		}
+		lines = append(lines, line)
+	}
+
+	head := fmt.Sprintf("⛳ VGA Golf events update: %d new, %d changed, %d removed", total.New, total.Changed, total.Removed)
+	tail := links.Track(links.RegistrationURL, "", channel, links.CampaignSummary)
+	if tags := strings.Join(c.Default.Hashtags, " "); tags != "" {
+		tail += "\n" + tags
+	}
+
+	for shown := len(lines); shown >= 0; shown-- {
+		post := head + "\n"
+		if shown > 0 {
+			post += strings.Join(lines[:shown], " · ")
+			if shown < len(lines) {
+				post += fmt.Sprintf(" · +%d more states", len(lines)-shown)
+			}
+			post += "\n"
+		}
+		post += tail
+		if utf8.RuneCountInString(post) <= limit {
+			return post, nil
+		}
+	}
+	return "", fmt.Errorf("%s summary post is over the %d character limit", channel, limit)
+}
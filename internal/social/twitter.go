@@ -0,0 +1,69 @@
+package social
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// twitterURL is the X/Twitter API v2 endpoint for creating posts
+var twitterURL = "https://api.twitter.com/2/tweets"
+
+// Twitter publishes posts to an X/Twitter account
+type Twitter struct {
+	token      string
+	httpClient *http.Client
+}
+
+// NewTwitter creates a client that posts with an OAuth 2.0 user access token (with the
+// tweet.write scope) for the announcing account
+func NewTwitter(token string) *Twitter {
+	return &Twitter{
+		token:      token,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Post publishes text and returns the new post's ID
+func (t *Twitter) Post(ctx context.Context, text string) (string, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return "", fmt.Errorf("encoding post: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", twitterURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		statusErr := errs.Status(resp.StatusCode, "twitter API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+		statusErr.RetryAfter = errs.ParseRetryAfter(resp.Header)
+		return "", statusErr
+	}
+
+	var result struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+	return result.Data.ID, nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// getArchivePath returns the path to a state's archive of expired removed events. Like
+// history, an existing file keeps its format when compression is turned on or off.
+func (s *Storage) getArchivePath(state string) string {
+	name := "removed_archive.jsonl"
+	if state != "" && strings.ToUpper(state) != "ALL" {
+		name = fmt.Sprintf("removed_archive_%s.jsonl", strings.ToUpper(state))
+	}
+	return s.preferredPath(filepath.Join(s.dataDir, name))
+}
+
+// ArchiveRemoved appends removed events that aged out of the state's snapshot to its
+// archive, one JSON line per event, so they're kept after the retention window
+func (s *Storage) ArchiveRemoved(state string, events []*event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var lines []byte
+	for _, evt := range events {
+		line, err := json.Marshal(evt)
+		if err != nil {
+			return fmt.Errorf("encoding archived event: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	path := s.getArchivePath(state)
+	if strings.HasSuffix(path, gzipExt) {
+		var err error
+		if lines, err = gzipBytes(lines); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is constructed from validated state parameter
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	if _, err := f.Write(lines); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadArchive reads the state's archived removed events, oldest first.
+// Returns an empty list if nothing has been archived.
+func (s *Storage) LoadArchive(state string) ([]*event.Event, error) {
+	r, err := OpenFile(s.getArchivePath(state))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*event.Event{}, nil
+		}
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	defer r.Close()
+
+	events := make([]*event.Event, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var evt event.Event
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			return nil, fmt.Errorf("parsing archive: %w", err)
+		}
+		events = append(events, &evt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+
+	return events, nil
+}
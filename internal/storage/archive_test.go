@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestRemovedArchive(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		store, err := NewWithOptions(t.TempDir(), Options{Compress: compress})
+		if err != nil {
+			t.Fatalf("NewWithOptions() error = %v", err)
+		}
+
+		if events, err := store.LoadArchive("NV"); err != nil || len(events) != 0 {
+			t.Fatalf("LoadArchive() before archiving = %v, %v", events, err)
+		}
+
+		if err := store.ArchiveRemoved("NV", []*event.Event{{ID: "a", Title: "First"}, {ID: "b", Title: "Second"}}); err != nil {
+			t.Fatalf("ArchiveRemoved() error = %v", err)
+		}
+		if err := store.ArchiveRemoved("NV", nil); err != nil {
+			t.Fatalf("ArchiveRemoved(nil) error = %v", err)
+		}
+		if err := store.ArchiveRemoved("NV", []*event.Event{{ID: "c", Title: "Third"}}); err != nil {
+			t.Fatalf("ArchiveRemoved() error = %v", err)
+		}
+
+		events, err := store.LoadArchive("NV")
+		if err != nil {
+			t.Fatalf("LoadArchive() error = %v", err)
+		}
+		if len(events) != 3 || events[0].ID != "a" || events[2].Title != "Third" {
+			t.Errorf("compress=%v: LoadArchive() = %v", compress, events)
+		}
+		if events, _ := store.LoadArchive("all"); len(events) != 0 {
+			t.Errorf("compress=%v: archives should be kept per state, got %v", compress, events)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// clicksFile is the append-only log of tracked link clicks
+const clicksFile = "clicks.jsonl"
+
+// Click records one click on a tracked link
+type Click struct {
+	At       string `json:"at"`                 // RFC3339 timestamp of the click
+	Channel  string `json:"channel"`            // Channel the link was sent on, e.g. "telegram"
+	Campaign string `json:"campaign,omitempty"` // Kind of message, e.g. "reminder"
+	EventID  string `json:"event_id,omitempty"` // Empty for links not about one event, like digests
+}
+
+// AppendClick appends a record to the click log, one JSON line per record
+func (s *Storage) AppendClick(c *Click) error {
+	line, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding click: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(s.dataDir, clicksFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening click log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing click log: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadClicks reads the click log, oldest first.
+// Returns an empty list if nothing has been recorded.
+func (s *Storage) LoadClicks() ([]*Click, error) {
+	f, err := os.Open(filepath.Join(s.dataDir, clicksFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Click{}, nil
+		}
+		return nil, fmt.Errorf("opening click log: %w", err)
+	}
+	defer f.Close()
+
+	clicks := make([]*Click, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var c Click
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("parsing click log: %w", err)
+		}
+		clicks = append(clicks, &c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading click log: %w", err)
+	}
+
+	return clicks, nil
+}
+
+// ClickCount is the number of clicks for one channel, campaign, or event
+type ClickCount struct {
+	Key    string
+	Clicks int
+}
+
+// ClickStats summarizes the click log
+type ClickStats struct {
+	Total      int
+	ByChannel  []ClickCount // Most clicks first
+	ByCampaign []ClickCount // Most clicks first
+	ByEvent    []ClickCount // Most clicks first; links without an event aren't counted
+}
+
+// SummarizeClicks counts clicks at or after since (RFC3339; empty for all) by
+// channel, campaign, and event
+func SummarizeClicks(clicks []*Click, since string) *ClickStats {
+	channels := make(map[string]int)
+	campaigns := make(map[string]int)
+	events := make(map[string]int)
+
+	stats := &ClickStats{}
+	for _, c := range clicks {
+		if since != "" && c.At < since {
+			continue
+		}
+		stats.Total++
+		channels[c.Channel]++
+		if c.Campaign != "" {
+			campaigns[c.Campaign]++
+		}
+		if c.EventID != "" {
+			events[c.EventID]++
+		}
+	}
+
+	stats.ByChannel = rankClicks(channels)
+	stats.ByCampaign = rankClicks(campaigns)
+	stats.ByEvent = rankClicks(events)
+	return stats
+}
+
+// rankClicks orders counts by clicks, then key
+func rankClicks(counts map[string]int) []ClickCount {
+	ranked := make([]ClickCount, 0, len(counts))
+	for key, n := range counts {
+		ranked = append(ranked, ClickCount{Key: key, Clicks: n})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Clicks != ranked[j].Clicks {
+			return ranked[i].Clicks > ranked[j].Clicks
+		}
+		return ranked[i].Key < ranked[j].Key
+	})
+	return ranked
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestClickLog(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	clicks, err := store.LoadClicks()
+	if err != nil || len(clicks) != 0 {
+		t.Fatalf("LoadClicks() = %v, %v; want empty", clicks, err)
+	}
+
+	records := []*Click{
+		{At: "2026-05-01T10:00:00Z", Channel: "telegram", Campaign: "new-event", EventID: "e1"},
+		{At: "2026-05-02T10:00:00Z", Channel: "telegram", Campaign: "reminder", EventID: "e2"},
+		{At: "2026-05-03T10:00:00Z", Channel: "twitter", Campaign: "new-event", EventID: "e2"},
+		{At: "2026-05-04T10:00:00Z", Channel: "telegram", Campaign: "digest"},
+	}
+	for _, c := range records {
+		if err := store.AppendClick(c); err != nil {
+			t.Fatalf("AppendClick() error = %v", err)
+		}
+	}
+
+	clicks, err = store.LoadClicks()
+	if err != nil {
+		t.Fatalf("LoadClicks() error = %v", err)
+	}
+	if len(clicks) != len(records) {
+		t.Fatalf("loaded %d clicks, want %d", len(clicks), len(records))
+	}
+
+	stats := SummarizeClicks(clicks, "")
+	if stats.Total != 4 {
+		t.Errorf("Total = %d, want 4", stats.Total)
+	}
+	if len(stats.ByChannel) != 2 || stats.ByChannel[0] != (ClickCount{"telegram", 3}) {
+		t.Errorf("ByChannel = %v, want telegram first with 3", stats.ByChannel)
+	}
+	if len(stats.ByEvent) != 2 || stats.ByEvent[0] != (ClickCount{"e2", 2}) {
+		t.Errorf("ByEvent = %v, want e2 first with 2", stats.ByEvent)
+	}
+	if stats.ByCampaign[0] != (ClickCount{"new-event", 2}) {
+		t.Errorf("ByCampaign = %v, want new-event first with 2", stats.ByCampaign)
+	}
+
+	recent := SummarizeClicks(clicks, "2026-05-03T00:00:00Z")
+	if recent.Total != 2 || len(recent.ByEvent) != 1 {
+		t.Errorf("since filter: %+v, want 2 clicks on 1 event", recent)
+	}
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// gzipExt is the file extension used for compressed files
+const gzipExt = ".gz"
+
+// gzipMagic is the header every gzip stream starts with
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// IsGzip reports whether data starts with the gzip header
+func IsGzip(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+// ReadFile reads a file, decompressing it if it's gzip-compressed.
+// Compression is detected from the content, so ".json" and ".json.gz" files both work.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - Callers pass snapshot paths or CLI-provided files
+	if err != nil {
+		return nil, err
+	}
+	if !IsGzip(data) {
+		return data, nil
+	}
+	return gunzip(data)
+}
+
+// OpenFile opens a file for streaming reads, decompressing it if it's gzip-compressed.
+// The caller must close the returned reader.
+func OpenFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path) // #nosec G304 - Callers pass snapshot paths or CLI-provided files
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+	header, err := br.Peek(len(gzipMagic))
+	if err != nil || !IsGzip(header) {
+		// Too short to be gzip, or plain text
+		return readCloser{Reader: br, close: f.Close}, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("opening gzip stream: %w", err)
+	}
+	return readCloser{Reader: zr, close: func() error {
+		zerr := zr.Close()
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return zerr
+	}}, nil
+}
+
+// WriteFile writes data to path, gzip-compressing it if the path ends in ".gz"
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if strings.HasSuffix(path, gzipExt) {
+		compressed, err := gzipBytes(data)
+		if err != nil {
+			return err
+		}
+		data = compressed
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// gzipBytes compresses data
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("compressing: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compressing: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzip decompresses data, including multiple concatenated gzip members
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip stream: %w", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing: %w", err)
+	}
+	return out, nil
+}
+
+// readCloser pairs a reader with a close function
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
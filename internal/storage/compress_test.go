@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestReadWriteFile_Gzip(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte(`{"new_events": []}`)
+
+	plain := filepath.Join(dir, "digest.json")
+	compressed := filepath.Join(dir, "digest.json.gz")
+	if err := WriteFile(plain, content, 0600); err != nil {
+		t.Fatalf("WriteFile(plain) error = %v", err)
+	}
+	if err := WriteFile(compressed, content, 0600); err != nil {
+		t.Fatalf("WriteFile(gz) error = %v", err)
+	}
+
+	raw, _ := os.ReadFile(compressed)
+	if !IsGzip(raw) {
+		t.Error(".gz file should be gzip-compressed")
+	}
+
+	for _, path := range []string{plain, compressed} {
+		data, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		if string(data) != string(content) {
+			t.Errorf("ReadFile(%s) = %q, want %q", path, data, content)
+		}
+
+		r, err := OpenFile(path)
+		if err != nil {
+			t.Fatalf("OpenFile(%s) error = %v", path, err)
+		}
+		streamed, _ := io.ReadAll(r)
+		if err := r.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+		if string(streamed) != string(content) {
+			t.Errorf("OpenFile(%s) read %q, want %q", path, streamed, content)
+		}
+	}
+}
+
+func TestSnapshot_Compressed(t *testing.T) {
+	dir := t.TempDir()
+
+	// Start with a plain snapshot, then switch to compression
+	plainStore, _ := New(dir)
+	evt := &event.Event{ID: "evt-1", State: "NV", Title: "Desert Classic", DateText: "Mar 15 2026", FirstSeen: time.Now().UTC()}
+	if err := plainStore.CreateSnapshotFromEvents(context.Background(), []*event.Event{evt}, "all"); err != nil {
+		t.Fatalf("saving plain snapshot: %v", err)
+	}
+
+	store, err := NewWithOptions(dir, Options{Compress: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	// Plain snapshots are still readable
+	snapshot, err := store.LoadSnapshot("all")
+	if err != nil || len(snapshot.Events) != 1 {
+		t.Fatalf("LoadSnapshot() = %v events, err %v", len(snapshot.Events), err)
+	}
+
+	if err := store.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "snapshot.json.gz")); err != nil {
+		t.Errorf("expected compressed snapshot: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "snapshot.json")); !os.IsNotExist(err) {
+		t.Error("plain snapshot should be removed after a compressed save")
+	}
+
+	// Both compressed and uncompressed stores read the compressed file
+	for _, s := range []*Storage{store, plainStore} {
+		loaded, err := s.LoadSnapshot("all")
+		if err != nil {
+			t.Fatalf("LoadSnapshot() error = %v", err)
+		}
+		if got := loaded.Events["evt-1"]; got == nil || got.Title != "Desert Classic" {
+			t.Errorf("compressed snapshot round trip lost event: %+v", got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Delivery results
+const (
+	DeliverySent        = "sent"
+	DeliveryFailed      = "failed"      // Gave up after retries
+	DeliveryUnreachable = "unreachable" // Chat doesn't exist or blocked the bot
+)
+
+// deliveriesFile is the append-only audit log of outbound notifications
+const deliveriesFile = "deliveries.jsonl"
+
+// Delivery records one outbound notification and whether it got through
+type Delivery struct {
+	At      string `json:"at"`                 // RFC3339 timestamp of the send attempt
+	RunID   string `json:"run_id,omitempty"`   // Groups the deliveries of one workflow run
+	User    string `json:"user"`               // Hashed chat ID (errreport.HashChatID), never the raw ID
+	Channel string `json:"channel"`            // "telegram"
+	EventID string `json:"event_id,omitempty"` // Empty for digests
+	Type    string `json:"type"`               // "new", "removed", "changed", "reminder", or "digest"
+	Result  string `json:"result"`             // DeliverySent, DeliveryFailed, or DeliveryUnreachable
+	Error   string `json:"error,omitempty"`
+
+	Experiment string `json:"experiment,omitempty"` // A/B experiment the message was part of
+	Variant    string `json:"variant,omitempty"`    // Message variant the user was assigned
+}
+
+// AppendDelivery appends a record to the delivery log, one JSON line per record.
+// Records are written as they happen so a crash mid-run still leaves an accurate log.
+func (s *Storage) AppendDelivery(d *Delivery) error {
+	line, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("encoding delivery: %w", err)
+	}
+	line = append(line, '\n')
+
+	path := filepath.Join(s.dataDir, deliveriesFile)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening delivery log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing delivery log: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadDeliveries reads the delivery log, oldest first.
+// Returns an empty list if nothing has been recorded.
+func (s *Storage) LoadDeliveries() ([]*Delivery, error) {
+	f, err := os.Open(filepath.Join(s.dataDir, deliveriesFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Delivery{}, nil
+		}
+		return nil, fmt.Errorf("opening delivery log: %w", err)
+	}
+	defer f.Close()
+
+	deliveries := make([]*Delivery, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var d Delivery
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, fmt.Errorf("parsing delivery log: %w", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading delivery log: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// LastRunID returns the run ID of the most recent delivery, or "" if there are none
+func LastRunID(deliveries []*Delivery) string {
+	if len(deliveries) == 0 {
+		return ""
+	}
+	return deliveries[len(deliveries)-1].RunID
+}
+
+// ChannelStats summarizes deliveries on one channel
+type ChannelStats struct {
+	Channel     string
+	Sent        int
+	Failed      int
+	Unreachable int
+	Users       int            // Distinct users a notification was attempted for
+	Failures    []*Delivery    // Failed and unreachable records, oldest first
+	ByType      map[string]int // Sent notifications per type
+}
+
+// Total returns the number of delivery attempts
+func (c *ChannelStats) Total() int {
+	return c.Sent + c.Failed + c.Unreachable
+}
+
+// SummarizeDeliveries groups the deliveries from runID by channel, in channel order.
+// An empty runID summarizes every record.
+func SummarizeDeliveries(deliveries []*Delivery, runID string) []*ChannelStats {
+	byChannel := make(map[string]*ChannelStats)
+	users := make(map[string]map[string]bool)
+
+	for _, d := range deliveries {
+		if runID != "" && d.RunID != runID {
+			continue
+		}
+		stats, ok := byChannel[d.Channel]
+		if !ok {
+			stats = &ChannelStats{Channel: d.Channel, ByType: make(map[string]int)}
+			byChannel[d.Channel] = stats
+			users[d.Channel] = make(map[string]bool)
+		}
+		users[d.Channel][d.User] = true
+
+		switch d.Result {
+		case DeliverySent:
+			stats.Sent++
+			stats.ByType[d.Type]++
+		case DeliveryUnreachable:
+			stats.Unreachable++
+			stats.Failures = append(stats.Failures, d)
+		default:
+			stats.Failed++
+			stats.Failures = append(stats.Failures, d)
+		}
+	}
+
+	summary := make([]*ChannelStats, 0, len(byChannel))
+	for channel, stats := range byChannel {
+		stats.Users = len(users[channel])
+		summary = append(summary, stats)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Channel < summary[j].Channel })
+	return summary
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestDeliveryLog(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Nothing recorded yet
+	deliveries, err := store.LoadDeliveries()
+	if err != nil || len(deliveries) != 0 {
+		t.Fatalf("LoadDeliveries() = %v, %v; want empty", deliveries, err)
+	}
+
+	records := []*Delivery{
+		{RunID: "1", User: "aaa", Channel: "telegram", EventID: "e1", Type: "new", Result: DeliverySent},
+		{RunID: "2", User: "aaa", Channel: "telegram", EventID: "e2", Type: "new", Result: DeliverySent},
+		{RunID: "2", User: "aaa", Channel: "telegram", EventID: "e3", Type: "reminder", Result: DeliverySent},
+		{RunID: "2", User: "bbb", Channel: "telegram", EventID: "e2", Type: "new", Result: DeliveryFailed, Error: "timeout"},
+		{RunID: "2", User: "ccc", Channel: "telegram", Type: "digest", Result: DeliveryUnreachable},
+	}
+	for _, d := range records {
+		if err := store.AppendDelivery(d); err != nil {
+			t.Fatalf("AppendDelivery() error = %v", err)
+		}
+	}
+
+	deliveries, err = store.LoadDeliveries()
+	if err != nil {
+		t.Fatalf("LoadDeliveries() error = %v", err)
+	}
+	if len(deliveries) != len(records) {
+		t.Fatalf("loaded %d deliveries, want %d", len(deliveries), len(records))
+	}
+
+	runID := LastRunID(deliveries)
+	if runID != "2" {
+		t.Errorf("LastRunID() = %q, want 2", runID)
+	}
+
+	summary := SummarizeDeliveries(deliveries, runID)
+	if len(summary) != 1 {
+		t.Fatalf("summary has %d channels, want 1", len(summary))
+	}
+	stats := summary[0]
+	if stats.Sent != 2 || stats.Failed != 1 || stats.Unreachable != 1 || stats.Users != 3 || stats.Total() != 4 {
+		t.Errorf("stats = %+v, want 2 sent, 1 failed, 1 unreachable, 3 users", stats)
+	}
+	if stats.ByType["new"] != 1 || stats.ByType["reminder"] != 1 {
+		t.Errorf("ByType = %v, want 1 new and 1 reminder", stats.ByType)
+	}
+	if len(stats.Failures) != 2 || stats.Failures[0].Error != "timeout" {
+		t.Errorf("Failures = %v, want the failed and unreachable records", stats.Failures)
+	}
+
+	if all := SummarizeDeliveries(deliveries, ""); all[0].Sent != 3 {
+		t.Errorf("summary of all runs sent = %d, want 3", all[0].Sent)
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// digestsFile records each digest sent, so a retried digest run skips users it
+// already reached
+const digestsFile = "digests.jsonl"
+
+// DigestEntry records that a user was sent the digest for one period
+type DigestEntry struct {
+	At     string `json:"at"`               // RFC3339 timestamp
+	User   string `json:"user"`             // Hashed chat ID
+	Period string `json:"period"`           // e.g. "daily:2026-10-15" or "weekly:2026-W42"
+	Events int    `json:"events"`           // Events in the digest
+	RunID  string `json:"run_id,omitempty"` // Workflow run that sent it
+}
+
+func (e *DigestEntry) key() string {
+	return e.User + "|" + e.Period
+}
+
+// DigestLedger maps each user and period to the digest sent for it. Every entry is
+// appended to the ledger file and synced before it counts as sent.
+type DigestLedger struct {
+	store *Storage
+	sent  map[string]*DigestEntry
+}
+
+// LoadDigests reads the digest ledger. A missing ledger is empty.
+func (s *Storage) LoadDigests() (*DigestLedger, error) {
+	l := &DigestLedger{store: s, sent: make(map[string]*DigestEntry)}
+
+	f, err := os.Open(filepath.Join(s.dataDir, digestsFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("opening digest ledger: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e DigestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A crash mid-append can leave a partial last line; everything before it is intact
+			fmt.Fprintf(os.Stderr, "Warning: skipping malformed digest ledger entry: %v\n", err)
+			continue
+		}
+		l.sent[e.key()] = &e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading digest ledger: %w", err)
+	}
+
+	return l, nil
+}
+
+// Sent returns the digest sent to user for period, or nil if there wasn't one
+func (l *DigestLedger) Sent(user, period string) *DigestEntry {
+	return l.sent[(&DigestEntry{User: user, Period: period}).key()]
+}
+
+// Record appends a sent digest to the ledger and syncs it
+func (l *DigestLedger) Record(e *DigestEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding digest ledger entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(l.store.dataDir, digestsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening digest ledger: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing digest ledger: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("syncing digest ledger: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing digest ledger: %w", err)
+	}
+
+	l.sent[e.key()] = e
+	return nil
+}
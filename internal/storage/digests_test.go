@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDigestLedger(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ledger, err := store.LoadDigests()
+	if err != nil {
+		t.Fatalf("LoadDigests() error = %v", err)
+	}
+	if got := ledger.Sent("abc", "daily:2026-10-15"); got != nil {
+		t.Fatalf("Sent() on an empty ledger = %+v, want nil", got)
+	}
+
+	entry := &DigestEntry{At: "2026-10-15T09:00:12Z", User: "abc", Period: "daily:2026-10-15", Events: 3, RunID: "7"}
+	if err := ledger.Record(entry); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if got := ledger.Sent("abc", "daily:2026-10-15"); got != entry {
+		t.Errorf("Sent() after Record() = %+v, want the recorded entry", got)
+	}
+
+	// A partial line from a crash is skipped; earlier entries survive a reload
+	f, err := os.OpenFile(filepath.Join(dir, digestsFile), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"at":"2026-10-15T09:01`)
+	_ = f.Close()
+
+	reloaded, err := store.LoadDigests()
+	if err != nil {
+		t.Fatalf("LoadDigests() error = %v", err)
+	}
+	if got := reloaded.Sent("abc", "daily:2026-10-15"); got == nil || got.Events != 3 {
+		t.Errorf("Sent() after reload = %+v, want 3 events", got)
+	}
+	if got := reloaded.Sent("abc", "daily:2026-10-16"); got != nil {
+		t.Errorf("Sent() for another period = %+v, want nil", got)
+	}
+	if got := reloaded.Sent("def", "daily:2026-10-15"); got != nil {
+		t.Errorf("Sent() for another user = %+v, want nil", got)
+	}
+}
@@ -3,5 +3,28 @@
 // The storage package manages local snapshot files that track events across runs.
 // Snapshots are stored in JSON format, with separate files for each state
 // (snapshot_STATE.json) and a combined file for all states (snapshot.json).
-// The default storage location is ~/.local/share/vga-events/.
+// The default storage location is ~/.local/share/vga-events/. Snapshots saved by older
+// versions are upgraded as they're loaded (see event.DecodeSnapshot).
+//
+// With Options.Compress, snapshots are written gzip-compressed (snapshot.json.gz); both
+// formats are detected by their content when read, as are digest files opened with
+// OpenFile. With Options.History, each save appends the added, changed, and removed
+// events to a JSON-lines history file (history.jsonl), which ReplayHistory turns back
+// into the event set at any point. Removed events that age out of a snapshot can be
+// kept in a per-state archive (removed_archive.jsonl) with ArchiveRemoved.
+//
+// Notifiers record every message they send, or fail to send, to an append-only
+// delivery log (deliveries.jsonl) that SummarizeDeliveries groups by run and channel.
+// The delivery ledger (ledger.jsonl) tracks each notification from intent to sent to
+// confirmed, so a run that crashed between sending and saving seen lists can recover
+// without duplicates. The post ledger (posts.jsonl) maps each event to its post on a
+// social channel, so social notifiers skip events already posted and can thread
+// updates under, or delete, posts for events that changed or were removed. Clicks on
+// tracked links are appended to the click log (clicks.jsonl) that SummarizeClicks
+// counts by channel, campaign, and event. The run log (runs.jsonl) records what each
+// scrape parsed and each API error a notifier hit, which SummarizeRun turns into a
+// workflow run's report, flagging anomalies. Scheduled jobs append a heartbeat
+// (heartbeats.jsonl) after each successful run; StaleJobs finds the ones that stopped.
+// The digest ledger (digests.jsonl) records each user's digest per period, so a
+// retried digest run doesn't send it twice.
 package storage
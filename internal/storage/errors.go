@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	// errorsFile is the append-only log of reported errors and panics
+	errorsFile = "errors.jsonl"
+
+	// errorIssuesFile records the GitHub issue filed for each recurring error
+	errorIssuesFile = "error_issues.jsonl"
+
+	// maxErrorStack caps the stack kept with each error record
+	maxErrorStack = 4000
+)
+
+// ErrorRecord is one error or recovered panic, as reported to errreport
+type ErrorRecord struct {
+	At          string `json:"at"`               // RFC3339 timestamp
+	RunID       string `json:"run_id,omitempty"` // Workflow run the error happened in
+	Component   string `json:"component"`        // "cli", "bot", or "notifier"
+	Fingerprint string `json:"fingerprint"`      // Same root error at the same place
+	Type        string `json:"type"`             // Go type of the error, or "panic"
+	Message     string `json:"message"`
+	Location    string `json:"location"`        // Where it was reported from, innermost function first
+	Stack       string `json:"stack,omitempty"` // Stack at the report, trimmed
+}
+
+// AppendError appends an error to the error log
+func (s *Storage) AppendError(r *ErrorRecord) error {
+	if len(r.Stack) > maxErrorStack {
+		r.Stack = r.Stack[:maxErrorStack]
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding error record: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(s.dataDir, errorsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening error log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing error log: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadErrors reads the error log, oldest first.
+// Returns an empty list if nothing has been recorded.
+func (s *Storage) LoadErrors() ([]*ErrorRecord, error) {
+	f, err := os.Open(filepath.Join(s.dataDir, errorsFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*ErrorRecord{}, nil
+		}
+		return nil, fmt.Errorf("opening error log: %w", err)
+	}
+	defer f.Close()
+
+	records := make([]*ErrorRecord, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var r ErrorRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// A crash mid-append can leave a partial last line; everything before it is intact
+			fmt.Fprintf(os.Stderr, "Warning: skipping malformed error record: %v\n", err)
+			continue
+		}
+		records = append(records, &r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading error log: %w", err)
+	}
+
+	return records, nil
+}
+
+// RecurringError is every occurrence of one fingerprint. Type, Message, and Stack are
+// from the latest occurrence.
+type RecurringError struct {
+	Fingerprint string
+	Type        string
+	Message     string
+	Location    string
+	Stack       string
+	Components  []string // Components that reported it, sorted
+	Count       int      // Occurrences
+	Runs        int      // Distinct runs it happened in
+	FirstSeen   string   // RFC3339
+	LastSeen    string   // RFC3339
+}
+
+// GroupErrors groups the errors recorded at or after since by fingerprint, most
+// frequent first
+func GroupErrors(records []*ErrorRecord, since time.Time) []*RecurringError {
+	groups := make(map[string]*RecurringError)
+	runs := make(map[string]map[string]bool)
+	cutoff := since.UTC().Format(time.RFC3339)
+
+	for _, r := range records {
+		if r.At < cutoff {
+			continue
+		}
+		g, ok := groups[r.Fingerprint]
+		if !ok {
+			g = &RecurringError{Fingerprint: r.Fingerprint, FirstSeen: r.At}
+			groups[r.Fingerprint] = g
+			runs[r.Fingerprint] = make(map[string]bool)
+		}
+		g.Count++
+		runs[r.Fingerprint][r.RunID] = true
+		if !slices.Contains(g.Components, r.Component) {
+			g.Components = append(g.Components, r.Component)
+		}
+		if r.At >= g.LastSeen {
+			g.LastSeen = r.At
+			g.Type, g.Message, g.Location, g.Stack = r.Type, r.Message, r.Location, r.Stack
+		}
+	}
+
+	grouped := make([]*RecurringError, 0, len(groups))
+	for fingerprint, g := range groups {
+		g.Runs = len(runs[fingerprint])
+		sort.Strings(g.Components)
+		grouped = append(grouped, g)
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		if grouped[i].Count != grouped[j].Count {
+			return grouped[i].Count > grouped[j].Count
+		}
+		return grouped[i].Fingerprint < grouped[j].Fingerprint
+	})
+	return grouped
+}
+
+// ErrorIssue records the GitHub issue filed for a recurring error
+type ErrorIssue struct {
+	At          string `json:"at"`          // RFC3339 timestamp of the filing
+	Fingerprint string `json:"fingerprint"` // The error's fingerprint
+	Number      int    `json:"number"`      // Issue number
+	LastSeen    string `json:"last_seen"`   // Latest occurrence the issue includes
+}
+
+// ErrorIssues maps fingerprints to the issues filed for them. Every change is appended
+// to the file, so an issue is never opened twice for one error.
+type ErrorIssues struct {
+	store  *Storage
+	latest map[string]*ErrorIssue
+}
+
+// LoadErrorIssues reads the filed issues. A missing file is empty.
+func (s *Storage) LoadErrorIssues() (*ErrorIssues, error) {
+	l := &ErrorIssues{store: s, latest: make(map[string]*ErrorIssue)}
+
+	f, err := os.Open(filepath.Join(s.dataDir, errorIssuesFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("opening error issues: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e ErrorIssue
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping malformed error issue entry: %v\n", err)
+			continue
+		}
+		l.latest[e.Fingerprint] = &e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading error issues: %w", err)
+	}
+
+	return l, nil
+}
+
+// Get returns the issue filed for fingerprint, or nil if there isn't one
+func (l *ErrorIssues) Get(fingerprint string) *ErrorIssue {
+	return l.latest[fingerprint]
+}
+
+// Record notes that issue number now covers fingerprint's occurrences up to lastSeen
+func (l *ErrorIssues) Record(fingerprint string, number int, lastSeen string, now time.Time) error {
+	e := &ErrorIssue{
+		At:          now.UTC().Format(time.RFC3339),
+		Fingerprint: fingerprint,
+		Number:      number,
+		LastSeen:    lastSeen,
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding error issue: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(l.store.dataDir, errorIssuesFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening error issues: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing error issues: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	l.latest[fingerprint] = e
+	return nil
+}
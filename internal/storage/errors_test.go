@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorLog(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	records, err := store.LoadErrors()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("LoadErrors() = %v, %v; want empty", records, err)
+	}
+
+	long := &ErrorRecord{At: "2026-10-01T10:00:00Z", Component: "cli", Fingerprint: "a", Stack: strings.Repeat("x", maxErrorStack+100)}
+	if err := store.AppendError(long); err != nil {
+		t.Fatalf("AppendError() error = %v", err)
+	}
+	records, err = store.LoadErrors()
+	if err != nil || len(records) != 1 || len(records[0].Stack) != maxErrorStack {
+		t.Fatalf("LoadErrors() = %+v, %v; want 1 record with a trimmed stack", records, err)
+	}
+}
+
+func TestGroupErrors(t *testing.T) {
+	records := []*ErrorRecord{
+		{At: "2026-09-01T10:00:00Z", RunID: "1", Component: "cli", Fingerprint: "a", Message: "too old"},
+		{At: "2026-10-01T10:00:00Z", RunID: "2", Component: "notifier", Fingerprint: "a", Message: "status 502"},
+		{At: "2026-10-02T10:00:00Z", RunID: "3", Component: "cli", Fingerprint: "a", Message: "status 503", Stack: "latest"},
+		{At: "2026-10-01T11:00:00Z", RunID: "2", Component: "notifier", Fingerprint: "a", Message: "status 500"},
+		{At: "2026-10-01T12:00:00Z", RunID: "2", Component: "cli", Fingerprint: "b", Message: "once"},
+	}
+
+	groups := GroupErrors(records, time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC))
+	if len(groups) != 2 || groups[0].Fingerprint != "a" || groups[1].Fingerprint != "b" {
+		t.Fatalf("GroupErrors() = %+v; want a then b", groups)
+	}
+	a := groups[0]
+	if a.Count != 3 || a.Runs != 2 || strings.Join(a.Components, ",") != "cli,notifier" {
+		t.Errorf("a: count %d, runs %d, components %v", a.Count, a.Runs, a.Components)
+	}
+	if a.FirstSeen != "2026-10-01T10:00:00Z" || a.LastSeen != "2026-10-02T10:00:00Z" || a.Message != "status 503" || a.Stack != "latest" {
+		t.Errorf("a: first %s, last %s, message %q, stack %q; want the latest occurrence's details", a.FirstSeen, a.LastSeen, a.Message, a.Stack)
+	}
+}
+
+func TestErrorIssues(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	issues, err := store.LoadErrorIssues()
+	if err != nil || issues.Get("a") != nil {
+		t.Fatalf("LoadErrorIssues() = %v, %v; want empty", issues, err)
+	}
+
+	now := time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)
+	if err := issues.Record("a", 12, "2026-10-01T10:00:00Z", now); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := issues.Record("a", 12, "2026-10-02T10:00:00Z", now); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	reloaded, err := store.LoadErrorIssues()
+	if err != nil {
+		t.Fatalf("LoadErrorIssues() error = %v", err)
+	}
+	if got := reloaded.Get("a"); got == nil || got.Number != 12 || got.LastSeen != "2026-10-02T10:00:00Z" {
+		t.Errorf("Get() = %+v; want the latest filing of #12", got)
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// heartbeatsFile is the append-only log of scheduled runs that finished successfully
+const heartbeatsFile = "heartbeats.jsonl"
+
+// Heartbeat records that a scheduled job finished a run successfully
+type Heartbeat struct {
+	At    string `json:"at"`               // RFC3339 timestamp
+	Job   string `json:"job"`              // e.g. "notify"
+	RunID string `json:"run_id,omitempty"` // Workflow run that finished
+}
+
+// AppendHeartbeat appends a heartbeat to the heartbeat log
+func (s *Storage) AppendHeartbeat(h *Heartbeat) error {
+	line, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("encoding heartbeat: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(s.dataDir, heartbeatsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening heartbeat log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing heartbeat log: %w", err)
+	}
+	return f.Close()
+}
+
+// LatestHeartbeats returns each job's most recent heartbeat, keyed by job.
+// Returns an empty map if nothing has been recorded.
+func (s *Storage) LatestHeartbeats() (map[string]*Heartbeat, error) {
+	latest := make(map[string]*Heartbeat)
+
+	f, err := os.Open(filepath.Join(s.dataDir, heartbeatsFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return latest, nil
+		}
+		return nil, fmt.Errorf("opening heartbeat log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var h Heartbeat
+		if err := json.Unmarshal(scanner.Bytes(), &h); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping malformed heartbeat: %v\n", err)
+			continue
+		}
+		if prev := latest[h.Job]; prev == nil || h.At >= prev.At {
+			latest[h.Job] = &h
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading heartbeat log: %w", err)
+	}
+
+	return latest, nil
+}
+
+// StaleJob is a scheduled job that hasn't finished a run for too long
+type StaleJob struct {
+	Job      string
+	Interval time.Duration // How often the job is expected to run
+	Last     *Heartbeat    // Its latest heartbeat, or nil if there's none (or it's unreadable)
+	Since    time.Duration // Time since Last; zero when Last is nil
+}
+
+// StaleJobs returns the jobs in intervals whose latest heartbeat is older than twice
+// their expected interval, or missing, sorted by job
+func StaleJobs(latest map[string]*Heartbeat, intervals map[string]time.Duration, now time.Time) []*StaleJob {
+	var stale []*StaleJob
+	for job, interval := range intervals {
+		last := latest[job]
+		var at time.Time
+		if last != nil {
+			at, _ = time.Parse(time.RFC3339, last.At)
+		}
+		if at.IsZero() {
+			stale = append(stale, &StaleJob{Job: job, Interval: interval})
+			continue
+		}
+		if since := now.Sub(at); since > 2*interval {
+			stale = append(stale, &StaleJob{Job: job, Interval: interval, Last: last, Since: since})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Job < stale[j].Job })
+	return stale
+}
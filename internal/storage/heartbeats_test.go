@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeats(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	latest, err := store.LatestHeartbeats()
+	if err != nil || len(latest) != 0 {
+		t.Fatalf("LatestHeartbeats() = %v, %v; want empty", latest, err)
+	}
+
+	for _, h := range []*Heartbeat{
+		{At: "2026-10-01T10:05:00Z", Job: "notify", RunID: "1"},
+		{At: "2026-10-01T11:05:00Z", Job: "notify", RunID: "2"},
+		{At: "2026-10-01T06:00:00Z", Job: "commands", RunID: "3"},
+	} {
+		if err := store.AppendHeartbeat(h); err != nil {
+			t.Fatalf("AppendHeartbeat() error = %v", err)
+		}
+	}
+	latest, err = store.LatestHeartbeats()
+	if err != nil || len(latest) != 2 || latest["notify"].RunID != "2" || latest["commands"].RunID != "3" {
+		t.Fatalf("LatestHeartbeats() = %+v, %v", latest, err)
+	}
+}
+
+func TestStaleJobs(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	latest := map[string]*Heartbeat{
+		"notify":   {At: "2026-10-01T11:05:00Z", Job: "notify"},
+		"commands": {At: "2026-09-30T20:00:00Z", Job: "commands"},
+		"digest":   {At: "garbage", Job: "digest"},
+	}
+	intervals := map[string]time.Duration{
+		"notify":   time.Hour,     // 55m ago: fine
+		"commands": 6 * time.Hour, // 16h ago: more than 2×6h
+		"digest":   24 * time.Hour,
+		"reports":  24 * time.Hour, // Never recorded
+	}
+
+	stale := StaleJobs(latest, intervals, now)
+	if len(stale) != 3 {
+		t.Fatalf("StaleJobs() = %d jobs, want 3", len(stale))
+	}
+	if stale[0].Job != "commands" || stale[0].Since != 16*time.Hour || stale[0].Last == nil {
+		t.Errorf("stale[0] = %+v, want commands 16h ago", stale[0])
+	}
+	if stale[1].Job != "digest" || stale[1].Last != nil {
+		t.Errorf("stale[1] = %+v, want digest with no readable heartbeat", stale[1])
+	}
+	if stale[2].Job != "reports" || stale[2].Last != nil {
+		t.Errorf("stale[2] = %+v, want reports never recorded", stale[2])
+	}
+
+	// Exactly twice the interval is still on time
+	if got := StaleJobs(latest, map[string]time.Duration{"notify": 27*time.Minute + 30*time.Second}, now); len(got) != 0 {
+		t.Errorf("StaleJobs() at exactly 2× interval = %+v, want none", got)
+	}
+}
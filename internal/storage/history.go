@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// SnapshotDelta records the events that changed between two consecutive snapshots.
+// A history file holds one delta per save, so any earlier event set can be rebuilt
+// by replaying deltas instead of keeping full copies of every snapshot.
+type SnapshotDelta struct {
+	At      string         `json:"at"`                // RFC3339 timestamp of the newer snapshot
+	Added   []*event.Event `json:"added,omitempty"`   // Events new in this snapshot
+	Changed []*event.Event `json:"changed,omitempty"` // Events whose fields changed (full new version)
+	Removed []string       `json:"removed,omitempty"` // IDs of events no longer listed
+}
+
+// IsEmpty reports whether nothing changed
+func (d *SnapshotDelta) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// ComputeDelta returns the changes from prev to next. Events are listed in ID order.
+func ComputeDelta(prev, next map[string]*event.Event, at string) *SnapshotDelta {
+	delta := &SnapshotDelta{At: at}
+
+	for _, id := range sortedIDs(next) {
+		evt := next[id]
+		old, existed := prev[id]
+		switch {
+		case !existed:
+			delta.Added = append(delta.Added, evt)
+		case !sameEvent(old, evt):
+			delta.Changed = append(delta.Changed, evt)
+		}
+	}
+
+	for _, id := range sortedIDs(prev) {
+		if _, exists := next[id]; !exists {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+
+	return delta
+}
+
+// ApplyDelta updates events in place with a delta
+func ApplyDelta(events map[string]*event.Event, delta *SnapshotDelta) {
+	for _, evt := range delta.Added {
+		events[evt.ID] = evt
+	}
+	for _, evt := range delta.Changed {
+		events[evt.ID] = evt
+	}
+	for _, id := range delta.Removed {
+		delete(events, id)
+	}
+}
+
+// ReplayHistory rebuilds the event set after applying deltas in order, starting from nothing
+func ReplayHistory(deltas []*SnapshotDelta) map[string]*event.Event {
+	events := make(map[string]*event.Event)
+	for _, delta := range deltas {
+		ApplyDelta(events, delta)
+	}
+	return events
+}
+
+// sameEvent reports whether two versions of an event serialize identically
+func sameEvent(a, b *event.Event) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aj, bj)
+}
+
+// sortedIDs returns the map's keys in order
+func sortedIDs(events map[string]*event.Event) []string {
+	ids := make([]string, 0, len(events))
+	for id := range events {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// getHistoryPath returns the path to a state's delta history file. An existing file keeps
+// its format so history isn't split when compression is turned on or off.
+func (s *Storage) getHistoryPath(state string) string {
+	name := "history.jsonl"
+	if state != "" && strings.ToUpper(state) != "ALL" {
+		name = fmt.Sprintf("history_%s.jsonl", strings.ToUpper(state))
+	}
+	return s.preferredPath(filepath.Join(s.dataDir, name))
+}
+
+// AppendHistory appends a delta to the state's history file, one JSON line per delta.
+// Compressed history files get one gzip member per delta, which gzip readers treat
+// as a single stream.
+func (s *Storage) AppendHistory(state string, delta *SnapshotDelta) error {
+	line, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("encoding delta: %w", err)
+	}
+	line = append(line, '\n')
+
+	path := s.getHistoryPath(state)
+	if strings.HasSuffix(path, gzipExt) {
+		if line, err = gzipBytes(line); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is constructed from validated state parameter
+	if err != nil {
+		return fmt.Errorf("opening history: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadHistory reads all deltas recorded for a state, oldest first.
+// Returns an empty list if no history has been recorded.
+func (s *Storage) LoadHistory(state string) ([]*SnapshotDelta, error) {
+	r, err := OpenFile(s.getHistoryPath(state))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*SnapshotDelta{}, nil
+		}
+		return nil, fmt.Errorf("opening history: %w", err)
+	}
+	defer r.Close()
+
+	deltas := make([]*SnapshotDelta, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var delta SnapshotDelta
+		if err := json.Unmarshal(scanner.Bytes(), &delta); err != nil {
+			return nil, fmt.Errorf("parsing history: %w", err)
+		}
+		deltas = append(deltas, &delta)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+
+	return deltas, nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestComputeDelta(t *testing.T) {
+	prev := map[string]*event.Event{
+		"a": {ID: "a", Title: "Kept"},
+		"b": {ID: "b", Title: "Old title"},
+		"c": {ID: "c", Title: "Removed"},
+	}
+	next := map[string]*event.Event{
+		"a": {ID: "a", Title: "Kept"},
+		"b": {ID: "b", Title: "New title"},
+		"d": {ID: "d", Title: "Added"},
+	}
+
+	delta := ComputeDelta(prev, next, "2026-10-15T00:00:00Z")
+	if len(delta.Added) != 1 || delta.Added[0].ID != "d" {
+		t.Errorf("Added = %v, want [d]", delta.Added)
+	}
+	if len(delta.Changed) != 1 || delta.Changed[0].Title != "New title" {
+		t.Errorf("Changed = %v, want [b]", delta.Changed)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != "c" {
+		t.Errorf("Removed = %v, want [c]", delta.Removed)
+	}
+
+	if !ComputeDelta(next, next, "").IsEmpty() {
+		t.Error("delta between identical snapshots should be empty")
+	}
+
+	// Applying the delta to prev yields next
+	ApplyDelta(prev, delta)
+	if len(prev) != 3 || prev["b"].Title != "New title" || prev["c"] != nil || prev["d"] == nil {
+		t.Errorf("ApplyDelta() result = %v", prev)
+	}
+}
+
+func TestSnapshotHistory(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		store, err := NewWithOptions(t.TempDir(), Options{Compress: compress, History: true})
+		if err != nil {
+			t.Fatalf("NewWithOptions() error = %v", err)
+		}
+
+		runs := [][]*event.Event{
+			{{ID: "a", Title: "First"}, {ID: "b", Title: "Second"}},
+			{{ID: "a", Title: "First"}, {ID: "b", Title: "Second"}}, // unchanged, no delta
+			{{ID: "a", Title: "First (rescheduled)"}, {ID: "c", Title: "Third"}},
+		}
+		for _, events := range runs {
+			if err := store.CreateSnapshotFromEvents(context.Background(), events, "NV"); err != nil {
+				t.Fatalf("CreateSnapshotFromEvents() error = %v", err)
+			}
+		}
+
+		deltas, err := store.LoadHistory("NV")
+		if err != nil {
+			t.Fatalf("LoadHistory() error = %v", err)
+		}
+		if len(deltas) != 2 {
+			t.Fatalf("compress=%v: expected 2 deltas, got %d", compress, len(deltas))
+		}
+
+		replayed := ReplayHistory(deltas)
+		if len(replayed) != 2 || replayed["a"].Title != "First (rescheduled)" || replayed["c"] == nil {
+			t.Errorf("compress=%v: replayed history = %v", compress, replayed)
+		}
+	}
+}
+
+func TestLoadHistory_Missing(t *testing.T) {
+	store, _ := New(t.TempDir())
+	deltas, err := store.LoadHistory("all")
+	if err != nil || len(deltas) != 0 {
+		t.Errorf("LoadHistory() = %v, %v; want empty", deltas, err)
+	}
+}
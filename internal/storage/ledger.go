@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Ledger phases. A notification moves intent → sent → confirmed; the phase it was left
+// in after a crash tells the next run what to do with it.
+const (
+	LedgerIntent    = "intent"    // About to send; a crash here means the send may not have happened
+	LedgerSent      = "sent"      // Telegram accepted it, but the user's seen list isn't saved yet
+	LedgerConfirmed = "confirmed" // Seen list saved; the notification is done
+)
+
+const (
+	ledgerFile = "ledger.jsonl"
+
+	// ledgerRetention is how long confirmed entries are kept after compaction, long
+	// enough for a stale seen list to be caught without resending
+	ledgerRetention = 30 * 24 * time.Hour
+)
+
+// LedgerEntry records a notification reaching a phase
+type LedgerEntry struct {
+	At      string `json:"at"`       // RFC3339 timestamp
+	User    string `json:"user"`     // Hashed chat ID
+	EventID string `json:"event_id"` // Event the notification is about
+	Type    string `json:"type"`     // Notification type, e.g. "new" or "reminder-7"
+	Phase   string `json:"phase"`    // LedgerIntent, LedgerSent, or LedgerConfirmed
+}
+
+func (e *LedgerEntry) key() string {
+	return e.User + "|" + e.Type + "|" + e.EventID
+}
+
+// Ledger holds the latest phase of each notification. Each phase is appended to the
+// ledger file before the step it guards, so the file survives a crash at any point.
+type Ledger struct {
+	store  *Storage
+	latest map[string]*LedgerEntry
+}
+
+// LoadLedger reads the delivery ledger. A missing ledger is empty.
+func (s *Storage) LoadLedger() (*Ledger, error) {
+	l := &Ledger{store: s, latest: make(map[string]*LedgerEntry)}
+
+	f, err := os.Open(filepath.Join(s.dataDir, ledgerFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("opening ledger: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A crash mid-append can leave a partial last line; everything before it is intact
+			fmt.Fprintf(os.Stderr, "Warning: skipping malformed ledger entry: %v\n", err)
+			continue
+		}
+		l.latest[e.key()] = &e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading ledger: %w", err)
+	}
+
+	return l, nil
+}
+
+// Phase returns the latest phase recorded for a notification, or "" if there is none
+func (l *Ledger) Phase(user, eventID, kind string) string {
+	e := l.latest[(&LedgerEntry{User: user, EventID: eventID, Type: kind}).key()]
+	if e == nil {
+		return ""
+	}
+	return e.Phase
+}
+
+// Record appends a phase for a notification and syncs it to disk before returning,
+// so the caller can safely take the next step
+func (l *Ledger) Record(user, eventID, kind, phase string) error {
+	e := &LedgerEntry{
+		At:      time.Now().UTC().Format(time.RFC3339),
+		User:    user,
+		EventID: eventID,
+		Type:    kind,
+		Phase:   phase,
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding ledger entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(l.store.dataDir, ledgerFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening ledger: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing ledger: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("syncing ledger: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing ledger: %w", err)
+	}
+
+	l.latest[e.key()] = e
+	return nil
+}
+
+// Unconfirmed returns notifications left in the intent or sent phase, oldest first
+func (l *Ledger) Unconfirmed() []*LedgerEntry {
+	var entries []*LedgerEntry
+	for _, e := range l.latest {
+		if e.Phase != LedgerConfirmed {
+			entries = append(entries, e)
+		}
+	}
+	sortLedgerEntries(entries)
+	return entries
+}
+
+// ConfirmSent moves every notification in the sent phase to confirmed and compacts the
+// ledger. Call it once the seen lists covering those notifications have been saved.
+// Returns how many were confirmed.
+func (l *Ledger) ConfirmSent() (int, error) {
+	confirmed := 0
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, e := range l.latest {
+		if e.Phase == LedgerSent {
+			e.Phase = LedgerConfirmed
+			e.At = now
+			confirmed++
+		}
+	}
+	return confirmed, l.compact()
+}
+
+// compact rewrites the ledger with only the latest entry per notification, dropping
+// confirmed entries past the retention window. The new file replaces the old one with
+// a rename, so a crash leaves one or the other.
+func (l *Ledger) compact() error {
+	cutoff := time.Now().Add(-ledgerRetention)
+	entries := make([]*LedgerEntry, 0, len(l.latest))
+	for key, e := range l.latest {
+		if e.Phase == LedgerConfirmed {
+			if at, err := time.Parse(time.RFC3339, e.At); err == nil && at.Before(cutoff) {
+				delete(l.latest, key)
+				continue
+			}
+		}
+		entries = append(entries, e)
+	}
+	sortLedgerEntries(entries)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("encoding ledger entry: %w", err)
+		}
+	}
+
+	path := filepath.Join(l.store.dataDir, ledgerFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("writing ledger: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replacing ledger: %w", err)
+	}
+	return nil
+}
+
+// sortLedgerEntries orders entries by time, then key, so output is stable
+func sortLedgerEntries(entries []*LedgerEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].At != entries[j].At {
+			return entries[i].At < entries[j].At
+		}
+		return entries[i].key() < entries[j].key()
+	})
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLedgerPhases(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ledger, err := store.LoadLedger()
+	if err != nil {
+		t.Fatalf("LoadLedger() error = %v", err)
+	}
+	if phase := ledger.Phase("u1", "e1", "new"); phase != "" {
+		t.Errorf("Phase() on empty ledger = %q, want empty", phase)
+	}
+
+	// e1 completes sending, e2 crashes after the intent, e3 is sent to another user
+	steps := []struct{ user, event, phase string }{
+		{"u1", "e1", LedgerIntent},
+		{"u1", "e1", LedgerSent},
+		{"u1", "e2", LedgerIntent},
+		{"u2", "e3", LedgerIntent},
+		{"u2", "e3", LedgerSent},
+	}
+	for _, s := range steps {
+		if err := ledger.Record(s.user, s.event, "new", s.phase); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// A later run sees the same state from disk
+	reloaded, err := store.LoadLedger()
+	if err != nil {
+		t.Fatalf("LoadLedger() error = %v", err)
+	}
+	tests := []struct{ user, event, kind, want string }{
+		{"u1", "e1", "new", LedgerSent},
+		{"u1", "e2", "new", LedgerIntent},
+		{"u2", "e3", "new", LedgerSent},
+		{"u1", "e1", "reminder-7", ""}, // Types are tracked separately
+		{"u2", "e1", "new", ""},        // So are users
+	}
+	for _, tt := range tests {
+		if got := reloaded.Phase(tt.user, tt.event, tt.kind); got != tt.want {
+			t.Errorf("Phase(%s, %s, %s) = %q, want %q", tt.user, tt.event, tt.kind, got, tt.want)
+		}
+	}
+	if unconfirmed := reloaded.Unconfirmed(); len(unconfirmed) != 3 {
+		t.Errorf("Unconfirmed() = %d entries, want 3", len(unconfirmed))
+	}
+
+	confirmed, err := reloaded.ConfirmSent()
+	if err != nil {
+		t.Fatalf("ConfirmSent() error = %v", err)
+	}
+	if confirmed != 2 {
+		t.Errorf("ConfirmSent() = %d, want 2", confirmed)
+	}
+
+	// Compaction keeps one line per notification and the interrupted intent
+	after, err := store.LoadLedger()
+	if err != nil {
+		t.Fatalf("LoadLedger() error = %v", err)
+	}
+	if got := after.Phase("u1", "e1", "new"); got != LedgerConfirmed {
+		t.Errorf("Phase(e1) after confirm = %q, want confirmed", got)
+	}
+	if got := after.Phase("u1", "e2", "new"); got != LedgerIntent {
+		t.Errorf("Phase(e2) after confirm = %q, want intent", got)
+	}
+	if len(after.latest) != 3 {
+		t.Errorf("compacted ledger has %d entries, want 3", len(after.latest))
+	}
+}
+
+func TestLedgerSkipsTruncatedLine(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// A crash mid-append leaves a partial last line
+	data := `{"at":"2026-10-15T00:00:00Z","user":"u1","event_id":"e1","type":"new","phase":"sent"}` + "\n" + `{"at":"2026-10-15T00:00:01Z","user":"u1","eve`
+	if err := os.WriteFile(filepath.Join(dir, ledgerFile), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger, err := store.LoadLedger()
+	if err != nil {
+		t.Fatalf("LoadLedger() error = %v", err)
+	}
+	if got := ledger.Phase("u1", "e1", "new"); got != LedgerSent {
+		t.Errorf("Phase() = %q, want sent", got)
+	}
+}
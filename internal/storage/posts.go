@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// Social post states. A post is recorded as pending before it's published, so a run
+// that crashes mid-publish leaves a pending entry instead of posting twice.
+const (
+	PostPending   = "pending"   // About to publish; it may or may not have gone out
+	PostPublished = "published" // Published; PostID is set
+	PostDeleted   = "deleted"   // Taken down after the event was removed
+)
+
+const postsFile = "posts.jsonl"
+
+// PostEntry records the latest state of an event's post on a social channel
+type PostEntry struct {
+	At       string   `json:"at"`                  // RFC3339 timestamp
+	Channel  string   `json:"channel"`             // e.g. "twitter"
+	EventID  string   `json:"event_id"`            // Event the post is about
+	State    string   `json:"state"`               // PostPending, PostPublished, or PostDeleted
+	PostID   string   `json:"post_id,omitempty"`   // Channel's ID for the post, e.g. the tweet ID
+	ReplyIDs []string `json:"reply_ids,omitempty"` // Follow-up posts threaded under PostID
+	Hash     string   `json:"hash,omitempty"`      // PostHash of the event when last posted
+}
+
+func (e *PostEntry) key() string {
+	return e.Channel + "|" + e.EventID
+}
+
+// PostLedger maps each event to its post on each channel, so re-running a social
+// notifier skips events it already posted. Every change is appended to the ledger
+// file and synced before the caller acts on it.
+type PostLedger struct {
+	store  *Storage
+	latest map[string]*PostEntry
+}
+
+// LoadPosts reads the post ledger. A missing ledger is empty.
+func (s *Storage) LoadPosts() (*PostLedger, error) {
+	l := &PostLedger{store: s, latest: make(map[string]*PostEntry)}
+
+	f, err := os.Open(filepath.Join(s.dataDir, postsFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("opening post ledger: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e PostEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A crash mid-append can leave a partial last line; everything before it is intact
+			fmt.Fprintf(os.Stderr, "Warning: skipping malformed post ledger entry: %v\n", err)
+			continue
+		}
+		l.latest[e.key()] = &e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading post ledger: %w", err)
+	}
+
+	return l, nil
+}
+
+// Get returns the latest entry for an event's post on a channel, or nil if it was
+// never posted there
+func (l *PostLedger) Get(channel, eventID string) *PostEntry {
+	return l.latest[(&PostEntry{Channel: channel, EventID: eventID}).key()]
+}
+
+// MarkPending records that a post for the event is about to be published
+func (l *PostLedger) MarkPending(channel string, evt *event.Event) error {
+	e := l.next(channel, evt.ID)
+	e.State = PostPending
+	return l.append(e)
+}
+
+// MarkPublished records a published post and the event content it describes
+func (l *PostLedger) MarkPublished(channel string, evt *event.Event, postID string) error {
+	e := l.next(channel, evt.ID)
+	e.State = PostPublished
+	e.PostID = postID
+	e.ReplyIDs = nil
+	e.Hash = PostHash(evt)
+	return l.append(e)
+}
+
+// AddReply records a follow-up posted in the event's thread (for example, to announce
+// a date change) and the event content it describes
+func (l *PostLedger) AddReply(channel string, evt *event.Event, replyID string) error {
+	e := l.next(channel, evt.ID)
+	if e.State != PostPublished {
+		return fmt.Errorf("event %s has no published %s post to reply to", evt.ID, channel)
+	}
+	e.ReplyIDs = append(e.ReplyIDs, replyID)
+	e.Hash = PostHash(evt)
+	return l.append(e)
+}
+
+// MarkDeleted records that the event's post (and its replies) were taken down
+func (l *PostLedger) MarkDeleted(channel, eventID string) error {
+	e := l.next(channel, eventID)
+	e.State = PostDeleted
+	return l.append(e)
+}
+
+// next returns a copy of the latest entry for a post, or a new one, stamped now
+func (l *PostLedger) next(channel, eventID string) *PostEntry {
+	e := &PostEntry{Channel: channel, EventID: eventID}
+	if prev := l.Get(channel, eventID); prev != nil {
+		copied := *prev
+		copied.ReplyIDs = append([]string(nil), prev.ReplyIDs...)
+		e = &copied
+	}
+	e.At = time.Now().UTC().Format(time.RFC3339)
+	return e
+}
+
+// append writes an entry to the ledger file and syncs it before updating the index
+func (l *PostLedger) append(e *PostEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding post ledger entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(l.store.dataDir, postsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening post ledger: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing post ledger: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("syncing post ledger: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing post ledger: %w", err)
+	}
+
+	l.latest[e.key()] = e
+	return nil
+}
+
+// Pending returns posts left pending by a crashed run, oldest first. They may or may
+// not have been published, so they're reported rather than retried.
+func (l *PostLedger) Pending() []*PostEntry {
+	var entries []*PostEntry
+	for _, e := range l.latest {
+		if e.State == PostPending {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].At != entries[j].At {
+			return entries[i].At < entries[j].At
+		}
+		return entries[i].key() < entries[j].key()
+	})
+	return entries
+}
+
+// PostHash returns a short hash of the event fields a post shows. A changed hash means
+// the post is out of date.
+func PostHash(evt *event.Event) string {
+	sum := sha256.Sum256([]byte(evt.Title + "\x00" + evt.DateText + "\x00" + evt.City))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Post actions planned by PlanPosts
+const (
+	PostActionNew    = "new"    // Publish a post
+	PostActionUpdate = "update" // Reply in the thread (or edit) with the changed details
+	PostActionDelete = "delete" // Delete the post and its replies
+)
+
+// PostAction is one thing a social notifier should do
+type PostAction struct {
+	Action string
+	Event  *event.Event
+	Entry  *PostEntry // Existing ledger entry, nil for PostActionNew
+}
+
+// PlanPosts decides what a notifier for channel should do: publish events never
+// posted, update posts whose event changed, and delete posts for removed events.
+// Events already posted unchanged, and pending posts, are skipped.
+func (l *PostLedger) PlanPosts(channel string, current, removed []*event.Event) []PostAction {
+	var actions []PostAction
+	for _, evt := range current {
+		e := l.Get(channel, evt.ID)
+		switch {
+		case e == nil:
+			actions = append(actions, PostAction{Action: PostActionNew, Event: evt})
+		case e.State == PostPublished && e.Hash != PostHash(evt):
+			actions = append(actions, PostAction{Action: PostActionUpdate, Event: evt, Entry: e})
+		}
+	}
+	for _, evt := range removed {
+		if e := l.Get(channel, evt.ID); e != nil && e.State == PostPublished {
+			actions = append(actions, PostAction{Action: PostActionDelete, Event: evt, Entry: e})
+		}
+	}
+	return actions
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+func TestPostLedger(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	posts, err := store.LoadPosts()
+	if err != nil {
+		t.Fatalf("LoadPosts() error = %v", err)
+	}
+
+	nv1 := &event.Event{ID: "nv1", Title: "Wolf Creek", DateText: "Apr 12 2026"}
+	nv2 := &event.Event{ID: "nv2", Title: "Chimera", DateText: "Apr 2 2026"}
+	nv3 := &event.Event{ID: "nv3", Title: "Paiute", DateText: "May 2 2026"}
+
+	for _, evt := range []*event.Event{nv1, nv2} {
+		if err := posts.MarkPending("twitter", evt); err != nil {
+			t.Fatalf("MarkPending() error = %v", err)
+		}
+	}
+	if err := posts.MarkPublished("twitter", nv1, "tweet-1"); err != nil {
+		t.Fatalf("MarkPublished() error = %v", err)
+	}
+	// nv2 crashed mid-publish and stays pending
+
+	reloaded, err := store.LoadPosts()
+	if err != nil {
+		t.Fatalf("LoadPosts() error = %v", err)
+	}
+	if e := reloaded.Get("twitter", "nv1"); e == nil || e.State != PostPublished || e.PostID != "tweet-1" {
+		t.Errorf("Get(nv1) = %+v, want published tweet-1", e)
+	}
+	if pending := reloaded.Pending(); len(pending) != 1 || pending[0].EventID != "nv2" {
+		t.Errorf("Pending() = %+v, want nv2", pending)
+	}
+	if e := reloaded.Get("mastodon", "nv1"); e != nil {
+		t.Errorf("channels should be tracked separately, got %+v", e)
+	}
+
+	// Re-running with nothing changed only posts the new event
+	actions := reloaded.PlanPosts("twitter", []*event.Event{nv1, nv2, nv3}, nil)
+	if len(actions) != 1 || actions[0].Action != PostActionNew || actions[0].Event.ID != "nv3" {
+		t.Errorf("PlanPosts() = %+v, want only new nv3", actions)
+	}
+
+	// A date change is an update; a removal is a delete
+	moved := &event.Event{ID: "nv1", Title: "Wolf Creek", DateText: "Apr 19 2026"}
+	actions = reloaded.PlanPosts("twitter", []*event.Event{moved}, nil)
+	if len(actions) != 1 || actions[0].Action != PostActionUpdate || actions[0].Entry.PostID != "tweet-1" {
+		t.Fatalf("PlanPosts() after change = %+v, want update of tweet-1", actions)
+	}
+	if err := reloaded.AddReply("twitter", moved, "tweet-2"); err != nil {
+		t.Fatalf("AddReply() error = %v", err)
+	}
+	if actions := reloaded.PlanPosts("twitter", []*event.Event{moved}, nil); len(actions) != 0 {
+		t.Errorf("PlanPosts() after reply = %+v, want nothing", actions)
+	}
+
+	actions = reloaded.PlanPosts("twitter", nil, []*event.Event{moved})
+	if len(actions) != 1 || actions[0].Action != PostActionDelete {
+		t.Fatalf("PlanPosts() for removal = %+v, want delete", actions)
+	}
+	if err := reloaded.MarkDeleted("twitter", "nv1"); err != nil {
+		t.Fatalf("MarkDeleted() error = %v", err)
+	}
+
+	final, err := store.LoadPosts()
+	if err != nil {
+		t.Fatalf("LoadPosts() error = %v", err)
+	}
+	e := final.Get("twitter", "nv1")
+	if e == nil || e.State != PostDeleted || len(e.ReplyIDs) != 1 || e.ReplyIDs[0] != "tweet-2" {
+		t.Errorf("Get(nv1) after delete = %+v, want deleted with its reply kept", e)
+	}
+	if actions := final.PlanPosts("twitter", []*event.Event{moved}, []*event.Event{moved}); len(actions) != 0 {
+		t.Errorf("deleted posts should not be reposted or deleted again: %+v", actions)
+	}
+}
+
+func TestPostLedgerReplyNeedsPublishedPost(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	posts, _ := store.LoadPosts()
+	if err := posts.AddReply("twitter", &event.Event{ID: "x"}, "r1"); err == nil {
+		t.Error("AddReply() without a published post should fail")
+	}
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// runsFile is the append-only log of what each workflow run did
+const runsFile = "runs.jsonl"
+
+// Run record sources
+const (
+	RunSourceScrape   = "scrape"   // vga-events, once per run
+	RunSourceNotifier = "notifier" // A notifier, once per API error
+)
+
+// maxRunWarnings caps the warnings kept in one record
+const maxRunWarnings = 10
+
+// RunRecord is one process's part of a workflow run. The scrape appends one record
+// with what it parsed and the diff it found; notifiers append one for each API call
+// that failed, as it happens.
+type RunRecord struct {
+	At     string `json:"at"`               // RFC3339 timestamp: when the scrape started, or the error
+	RunID  string `json:"run_id,omitempty"` // Groups the records of one workflow run
+	Source string `json:"source"`           // RunSourceScrape or RunSourceNotifier
+
+	Events        int      `json:"events,omitempty"`         // Events parsed
+	New           int      `json:"new,omitempty"`            // New events in the diff
+	Changed       int      `json:"changed,omitempty"`        // Changes in the diff
+	Removed       int      `json:"removed,omitempty"`        // Removed events in the diff
+	Restored      int      `json:"restored,omitempty"`       // Restored events in the diff
+	ParseWarnings int      `json:"parse_warnings,omitempty"` // Pages skipped and events whose date didn't parse
+	Warnings      []string `json:"warnings,omitempty"`       // The first few warnings
+	Seconds       float64  `json:"seconds,omitempty"`        // How long the scrape took
+
+	API   string `json:"api,omitempty"`   // Notifier records: "golf-course", "tee-time", ...
+	Error string `json:"error,omitempty"` // Notifier records: what went wrong
+}
+
+// AddWarning counts a parse warning, keeping its text while there's room
+func (r *RunRecord) AddWarning(warning string) {
+	r.ParseWarnings++
+	if len(r.Warnings) < maxRunWarnings {
+		r.Warnings = append(r.Warnings, warning)
+	}
+}
+
+// AppendRun appends a record to the run log, one JSON line per record
+func (s *Storage) AppendRun(r *RunRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding run record: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(filepath.Join(s.dataDir, runsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		return fmt.Errorf("opening run log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing run log: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadRuns reads the run log, oldest first.
+// Returns an empty list if nothing has been recorded.
+func (s *Storage) LoadRuns() ([]*RunRecord, error) {
+	f, err := os.Open(filepath.Join(s.dataDir, runsFile)) // #nosec G304 - Path is inside the data directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*RunRecord{}, nil
+		}
+		return nil, fmt.Errorf("opening run log: %w", err)
+	}
+	defer f.Close()
+
+	runs := make([]*RunRecord, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var r RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("parsing run log: %w", err)
+		}
+		runs = append(runs, &r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading run log: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Anomaly thresholds
+const (
+	// RemovalSpikeFactor is how many times the usual removals count as a spike
+	RemovalSpikeFactor = 10
+
+	// minRemovalSpike keeps a handful of removals after quiet runs from counting
+	minRemovalSpike = 10
+
+	// baselineRuns is how many earlier scrapes the usual removals are averaged over
+	baselineRuns = 20
+)
+
+// RunSummary is one workflow run's operational summary for the maintainer
+type RunSummary struct {
+	RunID  string
+	Scrape *RunRecord // nil if the scrape didn't record (it failed before the diff)
+
+	APIErrors    map[string]int // Notifier API errors by API
+	Sent         int
+	Failed       int
+	Unreachable  int
+	Failures     []*Delivery // Failed and unreachable deliveries, oldest first
+	Runtime      time.Duration
+	UsualRemoved float64 // Average removals over earlier scrapes
+
+	Anomalies []string
+}
+
+// SummarizeRun builds runID's summary from the run and delivery logs, timing it from
+// the scrape's start to now, and flags anomalies: no events parsed, no scrape record,
+// or a spike in removals against the earlier scrapes
+func SummarizeRun(runs []*RunRecord, deliveries []*Delivery, runID string, now time.Time) *RunSummary {
+	summary := &RunSummary{RunID: runID, APIErrors: make(map[string]int)}
+
+	var earlier []*RunRecord
+	for _, r := range runs {
+		switch {
+		case r.RunID != runID:
+			if r.Source == RunSourceScrape && summary.Scrape == nil {
+				earlier = append(earlier, r)
+			}
+		case r.Source == RunSourceScrape:
+			summary.Scrape = r
+		case r.Source == RunSourceNotifier:
+			summary.APIErrors[r.API]++
+		}
+	}
+
+	for _, channel := range SummarizeDeliveries(deliveries, runID) {
+		summary.Sent += channel.Sent
+		summary.Failed += channel.Failed
+		summary.Unreachable += channel.Unreachable
+		summary.Failures = append(summary.Failures, channel.Failures...)
+	}
+	sort.SliceStable(summary.Failures, func(i, j int) bool { return summary.Failures[i].At < summary.Failures[j].At })
+
+	if len(earlier) > baselineRuns {
+		earlier = earlier[len(earlier)-baselineRuns:]
+	}
+	if len(earlier) > 0 {
+		total := 0
+		for _, r := range earlier {
+			total += r.Removed
+		}
+		summary.UsualRemoved = float64(total) / float64(len(earlier))
+	}
+
+	scrape := summary.Scrape
+	if scrape == nil {
+		summary.Anomalies = append(summary.Anomalies, "The scrape recorded nothing for this run")
+		return summary
+	}
+	if started, err := time.Parse(time.RFC3339, scrape.At); err == nil && now.After(started) {
+		summary.Runtime = now.Sub(started)
+	}
+
+	if scrape.Events == 0 {
+		summary.Anomalies = append(summary.Anomalies, "Zero events parsed")
+	}
+	if scrape.Removed >= minRemovalSpike && len(earlier) > 0 &&
+		float64(scrape.Removed) >= RemovalSpikeFactor*max(summary.UsualRemoved, 1) {
+		summary.Anomalies = append(summary.Anomalies,
+			fmt.Sprintf("%d removals, %.0fx the usual %.1f", scrape.Removed, float64(scrape.Removed)/max(summary.UsualRemoved, 1), summary.UsualRemoved))
+	}
+	return summary
+}
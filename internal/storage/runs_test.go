@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLog(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	runs, err := store.LoadRuns()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("LoadRuns() = %v, %v; want empty", runs, err)
+	}
+
+	scrape := &RunRecord{At: "2026-10-01T10:00:00Z", RunID: "1", Source: RunSourceScrape, Events: 120}
+	for i := 0; i < maxRunWarnings+2; i++ {
+		scrape.AddWarning("undated event")
+	}
+	if scrape.ParseWarnings != maxRunWarnings+2 || len(scrape.Warnings) != maxRunWarnings {
+		t.Errorf("AddWarning() kept %d of %d warnings", len(scrape.Warnings), scrape.ParseWarnings)
+	}
+
+	for _, r := range []*RunRecord{scrape, {At: "2026-10-01T10:01:00Z", RunID: "1", Source: RunSourceNotifier, API: "golf-course", Error: "timeout"}} {
+		if err := store.AppendRun(r); err != nil {
+			t.Fatalf("AppendRun() error = %v", err)
+		}
+	}
+	runs, err = store.LoadRuns()
+	if err != nil || len(runs) != 2 || runs[0].Events != 120 || runs[1].API != "golf-course" {
+		t.Fatalf("LoadRuns() = %+v, %v", runs, err)
+	}
+}
+
+func TestSummarizeRun(t *testing.T) {
+	var runs []*RunRecord
+	for i := 0; i < 5; i++ {
+		runs = append(runs, &RunRecord{RunID: string(rune('a' + i)), Source: RunSourceScrape, Events: 120, Removed: 2})
+	}
+	runs = append(runs,
+		&RunRecord{At: "2026-10-01T10:00:00Z", RunID: "now", Source: RunSourceScrape, Events: 100, New: 3, Removed: 25},
+		&RunRecord{RunID: "now", Source: RunSourceNotifier, API: "golf-course"},
+		&RunRecord{RunID: "now", Source: RunSourceNotifier, API: "golf-course"},
+		&RunRecord{RunID: "later", Source: RunSourceScrape, Removed: 500},
+	)
+	deliveries := []*Delivery{
+		{At: "2026-10-01T10:02:00Z", RunID: "now", Channel: "telegram", Result: DeliverySent},
+		{At: "2026-10-01T10:03:00Z", RunID: "now", Channel: "telegram", Result: DeliveryFailed, Error: "timeout"},
+		{At: "2026-10-01T10:03:00Z", RunID: "a", Channel: "telegram", Result: DeliveryFailed},
+	}
+
+	now := time.Date(2026, 10, 1, 10, 5, 0, 0, time.UTC)
+	s := SummarizeRun(runs, deliveries, "now", now)
+	if s.Scrape == nil || s.Scrape.Events != 100 || s.APIErrors["golf-course"] != 2 {
+		t.Errorf("SummarizeRun() = %+v", s)
+	}
+	if s.Sent != 1 || s.Failed != 1 || len(s.Failures) != 1 {
+		t.Errorf("deliveries: sent %d, failed %d, failures %d", s.Sent, s.Failed, len(s.Failures))
+	}
+	if s.Runtime != 5*time.Minute {
+		t.Errorf("Runtime = %v, want 5m", s.Runtime)
+	}
+	// Later runs don't count toward the usual removals
+	if s.UsualRemoved != 2 || len(s.Anomalies) != 1 || !strings.Contains(s.Anomalies[0], "25 removals") {
+		t.Errorf("usual %.1f, anomalies %v; want a removal spike", s.UsualRemoved, s.Anomalies)
+	}
+
+	runs[5].Removed = 12
+	runs[5].Events = 0
+	s = SummarizeRun(runs, deliveries, "now", now)
+	if len(s.Anomalies) != 1 || s.Anomalies[0] != "Zero events parsed" {
+		t.Errorf("anomalies = %v, want only zero events", s.Anomalies)
+	}
+
+	if s = SummarizeRun(runs, nil, "missing", now); len(s.Anomalies) != 1 || s.Scrape != nil {
+		t.Errorf("a run without a scrape record should be flagged: %v", s.Anomalies)
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,16 +9,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/errs"
 	"github.com/pfrederiksen/vga-events/internal/event"
 )
 
 // Storage handles persistence of event snapshots
 type Storage struct {
-	dataDir string
+	dataDir  string
+	compress bool
+	history  bool
+}
+
+// Options configures how snapshots are stored
+type Options struct {
+	Compress bool // Write snapshots and history gzip-compressed (".json.gz")
+	History  bool // Append a delta of changed events to a history file on every save
 }
 
 // New creates a new Storage instance
 func New(dataDir string) (*Storage, error) {
+	return NewWithOptions(dataDir, Options{})
+}
+
+// NewWithOptions creates a new Storage instance with compression and history options.
+// Compressed and plain snapshots are both read regardless of options.
+func NewWithOptions(dataDir string, opts Options) (*Storage, error) {
 	// Expand ~ to home directory
 	if strings.HasPrefix(dataDir, "~/") {
 		home, err := os.UserHomeDir()
@@ -33,11 +49,13 @@ func New(dataDir string) (*Storage, error) {
 	}
 
 	return &Storage{
-		dataDir: dataDir,
+		dataDir:  dataDir,
+		compress: opts.Compress,
+		history:  opts.History,
 	}, nil
 }
 
-// getSnapshotPath returns the path to the snapshot file
+// getSnapshotPath returns the path to the uncompressed snapshot file
 func (s *Storage) getSnapshotPath(state string) string {
 	if state == "" || strings.ToUpper(state) == "ALL" {
 		return filepath.Join(s.dataDir, "snapshot.json")
@@ -45,11 +63,46 @@ func (s *Storage) getSnapshotPath(state string) string {
 	return filepath.Join(s.dataDir, fmt.Sprintf("snapshot_%s.json", strings.ToUpper(state)))
 }
 
-// LoadSnapshot loads a snapshot from disk
+// preferredPath returns path or path+".gz": whichever exists, otherwise the one
+// matching the compression option
+func (s *Storage) preferredPath(path string) string {
+	if _, err := os.Stat(path + gzipExt); err == nil {
+		return path + gzipExt
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if s.compress {
+		return path + gzipExt
+	}
+	return path
+}
+
+// findSnapshot returns the existing snapshot file for a state, preferring the most
+// recently written one if both compressed and plain files exist
+func (s *Storage) findSnapshot(state string) string {
+	plain := s.getSnapshotPath(state)
+	compressed := plain + gzipExt
+
+	plainInfo, plainErr := os.Stat(plain)
+	compressedInfo, compressedErr := os.Stat(compressed)
+	switch {
+	case compressedErr != nil:
+		return plain
+	case plainErr != nil:
+		return compressed
+	case compressedInfo.ModTime().After(plainInfo.ModTime()):
+		return compressed
+	default:
+		return plain
+	}
+}
+
+// LoadSnapshot loads a snapshot from disk, reading gzip-compressed snapshots transparently
 func (s *Storage) LoadSnapshot(state string) (*event.Snapshot, error) {
-	path := s.getSnapshotPath(state)
+	path := s.findSnapshot(state)
 
-	data, err := os.ReadFile(path) // #nosec G304 - Path is constructed from validated state parameter
+	data, err := ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No previous snapshot, return empty one
@@ -58,48 +111,82 @@ func (s *Storage) LoadSnapshot(state string) (*event.Snapshot, error) {
 		return nil, fmt.Errorf("reading snapshot: %w", err)
 	}
 
-	var snapshot event.Snapshot
-	if err := json.Unmarshal(data, &snapshot); err != nil {
-		return nil, fmt.Errorf("parsing snapshot: %w", err)
-	}
-
-	// Ensure Events map is initialized
-	if snapshot.Events == nil {
-		snapshot.Events = make(map[string]*event.Event)
+	// Older formats are upgraded, with their missing structures filled in
+	snapshot, err := event.DecodeSnapshot(data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Restore course cache TTL (doesn't serialize from JSON)
-	if snapshot.CourseCache != nil {
-		snapshot.CourseCache.TTL = 30 * 24 * time.Hour // 30 days
-	}
+	snapshot.CourseCache.TTL = 30 * 24 * time.Hour // 30 days
 
-	return &snapshot, nil
+	return snapshot, nil
 }
 
-// SaveSnapshot saves a snapshot to disk
-func (s *Storage) SaveSnapshot(snapshot *event.Snapshot, state string) error {
+// SaveSnapshot saves a snapshot to disk. With compression enabled it's written as
+// ".json.gz" and the plain file is removed; with history enabled the changes since the
+// previous snapshot are appended to the history file. Nothing is written if ctx is
+// already canceled, so an interrupted run keeps the previous snapshot.
+func (s *Storage) SaveSnapshot(ctx context.Context, snapshot *event.Snapshot, state string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	path := s.getSnapshotPath(state)
+	stale := path + gzipExt
+	if s.compress {
+		path, stale = stale, path
+	}
 
 	// Set updated timestamp
 	snapshot.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	snapshot.SchemaVersion = event.SnapshotSchemaVersion
+
+	if s.history {
+		previous, err := s.LoadSnapshot(state)
+		if err != nil {
+			return fmt.Errorf("loading previous snapshot for history: %w", err)
+		}
+		prevEvents := previous.Events
+		if _, err := os.Stat(s.getHistoryPath(state)); os.IsNotExist(err) {
+			prevEvents = nil // First delta is a full baseline so history replays from nothing
+		}
+		delta := ComputeDelta(prevEvents, snapshot.Events, snapshot.UpdatedAt)
+		if !delta.IsEmpty() {
+			if err := s.AppendHistory(state, delta); err != nil {
+				return err
+			}
+		}
+	}
 
-	data, err := json.MarshalIndent(snapshot, "", "  ")
+	var data []byte
+	var err error
+	if s.compress {
+		data, err = json.Marshal(snapshot) // Indentation only adds bytes to compress
+	} else {
+		data, err = json.MarshalIndent(snapshot, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("encoding snapshot: %w", err)
 	}
 
 	// Use 0600 (owner read/write only) to prevent other users from reading snapshot data
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("writing snapshot: %w", err)
 	}
 
+	// Remove the other format so loads can't pick up an outdated copy
+	if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing old snapshot: %w", err)
+	}
+
 	return nil
 }
 
 // CreateSnapshotFromEvents creates and saves a snapshot from a list of events
-func (s *Storage) CreateSnapshotFromEvents(events []*event.Event, state string) error {
+func (s *Storage) CreateSnapshotFromEvents(ctx context.Context, events []*event.Event, state string) error {
 	snapshot := event.CreateSnapshot(events, time.Now().UTC().Format(time.RFC3339))
-	return s.SaveSnapshot(snapshot, state)
+	return s.SaveSnapshot(ctx, snapshot, state)
 }
 
 // GetEventByID retrieves an event by ID from the snapshot
@@ -115,5 +202,5 @@ func (s *Storage) GetEventByID(eventID string) (*event.Event, error) {
 		return evt, nil
 	}
 
-	return nil, fmt.Errorf("event not found: %s", eventID)
+	return nil, fmt.Errorf("event %w: %s", errs.ErrNotFound, eventID)
 }
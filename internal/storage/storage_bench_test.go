@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+)
+
+// benchEvents generates n synthetic events spread across states
+func benchEvents(n int) []*event.Event {
+	states := []string{"NV", "CA", "TX", "AZ", "FL", "UT", "CO", "WA"}
+	events := make([]*event.Event, n)
+	for i := range events {
+		state := states[i%len(states)]
+		title := fmt.Sprintf("Golf Club %d %d.%d.26", i, i%12+1, i%28+1)
+		evt := event.NewEvent(state, title, fmt.Sprintf("%d.%d.26", i%12+1, i%28+1), "City", state+" - "+title+" - City", "https://vgagolf.org/state-events/")
+		evt.FirstSeen = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+		events[i] = evt
+	}
+	return events
+}
+
+// BenchmarkSaveSnapshot compares plain and gzip snapshot writes and reports file size
+func BenchmarkSaveSnapshot(b *testing.B) {
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compress=%v", compress), func(b *testing.B) {
+			store, err := NewWithOptions(b.TempDir(), Options{Compress: compress})
+			if err != nil {
+				b.Fatal(err)
+			}
+			snapshot := event.CreateSnapshot(benchEvents(5000), "")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := store.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+
+			data, err := os.ReadFile(store.findSnapshot("all"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(len(data)), "bytes/snapshot")
+		})
+	}
+}
+
+// BenchmarkHistoryDelta reports the size of a delta for 1% churn against a full snapshot
+func BenchmarkHistoryDelta(b *testing.B) {
+	events := benchEvents(5000)
+	prev := make(map[string]*event.Event, len(events))
+	next := make(map[string]*event.Event, len(events))
+	for i, evt := range events {
+		prev[evt.ID] = evt
+		if i%100 == 0 {
+			continue // 1% removed
+		}
+		next[evt.ID] = evt
+	}
+	for _, evt := range benchEvents(5050)[5000:] {
+		evt.ID += "-new"
+		next[evt.ID] = evt // 1% added
+	}
+
+	full, _ := json.Marshal(next)
+
+	var delta *SnapshotDelta
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		delta = ComputeDelta(prev, next, "2026-10-15T00:00:00Z")
+	}
+	b.StopTimer()
+
+	deltaJSON, _ := json.Marshal(delta)
+	b.ReportMetric(float64(len(full)), "bytes/full")
+	b.ReportMetric(float64(len(deltaJSON)), "bytes/delta")
+}
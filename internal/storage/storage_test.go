@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -60,7 +62,7 @@ func TestGetEventByID(t *testing.T) {
 			name: "Successfully retrieve event from 'all' snapshot",
 			setup: func() {
 				snapshot := event.CreateSnapshot([]*event.Event{event1, event2}, time.Now().Format(time.RFC3339))
-				if err := storage.SaveSnapshot(snapshot, "all"); err != nil {
+				if err := storage.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
 					t.Fatalf("Failed to save snapshot: %v", err)
 				}
 			},
@@ -102,7 +104,7 @@ func TestGetEventByID(t *testing.T) {
 			setup: func() {
 				// Create a new empty snapshot, overwriting previous
 				snapshot := event.CreateSnapshot([]*event.Event{}, time.Now().Format(time.RFC3339))
-				if err := storage.SaveSnapshot(snapshot, "all"); err != nil {
+				if err := storage.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
 					t.Fatalf("Failed to save empty snapshot: %v", err)
 				}
 			},
@@ -219,13 +221,13 @@ func TestGetEventByID_StateSpecificFallback(t *testing.T) {
 	t.Run("Event not in 'all' snapshot - state-specific not implemented", func(t *testing.T) {
 		// Create a state-specific snapshot
 		snapshot := event.CreateSnapshot([]*event.Event{nvEvent}, time.Now().Format(time.RFC3339))
-		if err := storage.SaveSnapshot(snapshot, "NV"); err != nil {
+		if err := storage.SaveSnapshot(context.Background(), snapshot, "NV"); err != nil {
 			t.Fatalf("Failed to save NV snapshot: %v", err)
 		}
 
 		// Create an empty 'all' snapshot
 		emptySnapshot := event.CreateSnapshot([]*event.Event{}, time.Now().Format(time.RFC3339))
-		if err := storage.SaveSnapshot(emptySnapshot, "all"); err != nil {
+		if err := storage.SaveSnapshot(context.Background(), emptySnapshot, "all"); err != nil {
 			t.Fatalf("Failed to save all snapshot: %v", err)
 		}
 
@@ -363,7 +365,7 @@ func TestCreateSnapshotFromEvents(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := storage.CreateSnapshotFromEvents(tt.events, tt.state)
+			err := storage.CreateSnapshotFromEvents(context.Background(), tt.events, tt.state)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateSnapshotFromEvents() error = %v, wantErr %v", err, tt.wantErr)
@@ -473,3 +475,41 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadSnapshotLegacyFormats(t *testing.T) {
+	fixtures, err := filepath.Glob("../../testdata/fixtures/snapshots/*.json")
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("no snapshot fixtures: %v", err)
+	}
+
+	for _, fixture := range fixtures {
+		data, err := os.ReadFile(fixture) // #nosec G304 - Test fixture
+		if err != nil {
+			t.Fatalf("reading %s: %v", fixture, err)
+		}
+		store, err := New(t.TempDir())
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := os.WriteFile(store.getSnapshotPath("all"), data, 0600); err != nil {
+			t.Fatalf("writing snapshot: %v", err)
+		}
+
+		snapshot, err := store.LoadSnapshot("all")
+		if err != nil {
+			t.Fatalf("%s: LoadSnapshot() error = %v", filepath.Base(fixture), err)
+		}
+		if snapshot.CourseCache.TTL == 0 || snapshot.RemovedEvents == nil || len(snapshot.StableIndex) == 0 {
+			t.Errorf("%s: loaded snapshot not upgraded: %+v", filepath.Base(fixture), snapshot)
+		}
+
+		// Saving writes the current format
+		if err := store.SaveSnapshot(context.Background(), snapshot, "all"); err != nil {
+			t.Fatalf("SaveSnapshot() error = %v", err)
+		}
+		saved, err := os.ReadFile(store.getSnapshotPath("all"))
+		if err != nil || !strings.Contains(string(saved), `"schema_version": 4`) {
+			t.Errorf("%s: saved snapshot should record the schema version", filepath.Base(fixture))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package teetime
+
+import (
+	"sync"
+	"time"
+)
+
+// CachedAvailability represents a cached availability lookup with expiration
+type CachedAvailability struct {
+	Info      *Availability `json:"info"`
+	CachedAt  int64         `json:"cached_at"`  // Unix timestamp
+	ExpiresAt int64         `json:"expires_at"` // Unix timestamp
+}
+
+// Cache stores tee-time availability per course with TTL
+type Cache struct {
+	mu      sync.RWMutex
+	Entries map[string]*CachedAvailability `json:"entries"` // Key: "courseName|city|state|date"
+	TTL     time.Duration                  `json:"ttl"`     // How long to cache
+}
+
+// NewCache creates a new availability cache with 6-hour TTL
+func NewCache() *Cache {
+	return &Cache{
+		Entries: make(map[string]*CachedAvailability),
+		TTL:     6 * time.Hour, // Tee sheets change throughout the day
+	}
+}
+
+// cacheKey generates a cache key from course and date parameters
+func cacheKey(courseName, city, state, date string) string {
+	return courseName + "|" + city + "|" + state + "|" + date
+}
+
+// Get retrieves availability from cache if it exists and hasn't expired.
+// The second return value reports whether a (possibly negative) entry was found.
+func (c *Cache) Get(courseName, city, state, date string) (*Availability, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cached, exists := c.Entries[cacheKey(courseName, city, state, date)]
+	if !exists {
+		return nil, false
+	}
+
+	// Check if expired
+	if cached.ExpiresAt < time.Now().Unix() {
+		return nil, false
+	}
+
+	return cached.Info, true
+}
+
+// Set stores an availability result in the cache
+func (c *Cache) Set(courseName, city, state, date string, info *Availability) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().Unix()
+	c.Entries[cacheKey(courseName, city, state, date)] = &CachedAvailability{
+		Info:      info,
+		CachedAt:  now,
+		ExpiresAt: now + int64(c.TTL.Seconds()),
+	}
+}
+
+// Size returns the number of cached entries
+func (c *Cache) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.Entries)
+}
@@ -0,0 +1,149 @@
+// Package teetime looks up tee-time availability from a configurable provider
+// and builds booking links for event cards.
+package teetime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// dateLayout is the date format used in provider URLs and cache keys
+const dateLayout = "2006-01-02"
+
+// Config describes a tee-time provider
+type Config struct {
+	// SearchURL is a booking/search page template. Supported placeholders:
+	// {course}, {city}, {state}, {date} (YYYY-MM-DD). Values are URL-escaped.
+	SearchURL string
+
+	// AvailabilityURL is an optional JSON endpoint queried with
+	// course, city, state, and date parameters. Leave empty to skip lookups.
+	AvailabilityURL string
+
+	// APIKey is sent as a Bearer token to the availability endpoint
+	APIKey string
+}
+
+// Availability summarizes open tee times around a date
+type Availability struct {
+	Date      string `json:"date"`
+	Available bool   `json:"available"`
+	Slots     int    `json:"slots"`
+}
+
+// Client is a client for a tee-time provider
+type Client struct {
+	config     Config
+	httpClient *http.Client
+	cache      *Cache
+}
+
+// NewClient creates a new tee-time provider client
+func NewClient(config Config) *Client {
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		cache: NewCache(),
+	}
+}
+
+// BookingURL returns the provider search link for a course around a date,
+// or an empty string when no search URL is configured
+func (c *Client) BookingURL(courseName, city, state string, date time.Time) string {
+	if c.config.SearchURL == "" {
+		return ""
+	}
+
+	dateStr := ""
+	if !date.IsZero() {
+		dateStr = date.Format(dateLayout)
+	}
+
+	replacer := strings.NewReplacer(
+		"{course}", url.QueryEscape(courseName),
+		"{city}", url.QueryEscape(city),
+		"{state}", url.QueryEscape(state),
+		"{date}", url.QueryEscape(dateStr),
+	)
+	return replacer.Replace(c.config.SearchURL)
+}
+
+// CheckAvailability asks the provider whether the course has open tee times
+// around the given date. Results (including empty ones) are cached per course
+// and date. Returns nil without error when no availability endpoint is configured.
+func (c *Client) CheckAvailability(ctx context.Context, courseName, city, state string, date time.Time) (*Availability, error) {
+	if c.config.AvailabilityURL == "" || date.IsZero() {
+		return nil, nil
+	}
+
+	dateStr := date.Format(dateLayout)
+
+	// Check cache first
+	if c.cache != nil {
+		if cached, ok := c.cache.Get(courseName, city, state, dateStr); ok {
+			return cached, nil
+		}
+	}
+
+	params := url.Values{}
+	params.Add("course", courseName)
+	params.Add("city", city)
+	params.Add("state", state)
+	params.Add("date", dateStr)
+
+	sep := "?"
+	if strings.Contains(c.config.AvailabilityURL, "?") {
+		sep = "&"
+	}
+	reqURL := c.config.AvailabilityURL + sep + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// Course not listed with this provider - cache the miss
+		if c.cache != nil {
+			c.cache.Set(courseName, city, state, dateStr, nil)
+		}
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.Status(resp.StatusCode, "API returned status %d", resp.StatusCode)
+	}
+
+	var result Availability
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if result.Date == "" {
+		result.Date = dateStr
+	}
+
+	if c.cache != nil {
+		c.cache.Set(courseName, city, state, dateStr, &result)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,138 @@
+package teetime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBookingURL(t *testing.T) {
+	date := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		searchURL string
+		date      time.Time
+		want      string
+	}{
+		{
+			name:      "all placeholders",
+			searchURL: "https://tee.example.com/search?q={course}&loc={city},{state}&date={date}",
+			date:      date,
+			want:      "https://tee.example.com/search?q=Pebble+Beach&loc=Pebble+Beach,CA&date=2026-04-04",
+		},
+		{
+			name:      "unknown date",
+			searchURL: "https://tee.example.com/search?q={course}&date={date}",
+			date:      time.Time{},
+			want:      "https://tee.example.com/search?q=Pebble+Beach&date=",
+		},
+		{
+			name:      "not configured",
+			searchURL: "",
+			date:      date,
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(Config{SearchURL: tt.searchURL})
+			got := client.BookingURL("Pebble Beach", "Pebble Beach", "CA", tt.date)
+			if got != tt.want {
+				t.Errorf("BookingURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	callCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Authorization = %q, want Bearer test-key", r.Header.Get("Authorization"))
+		}
+		q := r.URL.Query()
+		if q.Get("course") != "Pebble Beach" || q.Get("state") != "CA" || q.Get("date") != "2026-04-04" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(Availability{Available: true, Slots: 12})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{AvailabilityURL: server.URL, APIKey: "test-key"})
+	date := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
+
+	result, err := client.CheckAvailability(context.Background(), "Pebble Beach", "Pebble Beach", "CA", date)
+	if err != nil {
+		t.Fatalf("CheckAvailability() error: %v", err)
+	}
+	if result == nil || !result.Available || result.Slots != 12 {
+		t.Fatalf("CheckAvailability() = %+v, want 12 available slots", result)
+	}
+	if result.Date != "2026-04-04" {
+		t.Errorf("Date = %q, want 2026-04-04", result.Date)
+	}
+
+	// Second lookup for the same course and date should use the cache
+	if _, err := client.CheckAvailability(context.Background(), "Pebble Beach", "Pebble Beach", "CA", date); err != nil {
+		t.Fatalf("Second CheckAvailability() error: %v", err)
+	}
+	if callCount != 1 {
+		t.Errorf("Call count = %d, want 1 (should use cache)", callCount)
+	}
+}
+
+func TestCheckAvailability_NotListed(t *testing.T) {
+	callCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{AvailabilityURL: server.URL})
+	date := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		result, err := client.CheckAvailability(context.Background(), "Unknown Course", "", "NV", date)
+		if err != nil {
+			t.Fatalf("CheckAvailability() error: %v", err)
+		}
+		if result != nil {
+			t.Errorf("CheckAvailability() = %+v, want nil", result)
+		}
+	}
+	if callCount != 1 {
+		t.Errorf("Call count = %d, want 1 (miss should be cached)", callCount)
+	}
+}
+
+func TestCheckAvailability_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	date := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
+
+	client := NewClient(Config{AvailabilityURL: server.URL})
+	if _, err := client.CheckAvailability(context.Background(), "Course", "", "NV", date); err == nil {
+		t.Error("CheckAvailability() expected error for 500, got nil")
+	}
+
+	// No endpoint or no date means no lookup
+	unconfigured := NewClient(Config{SearchURL: "https://tee.example.com"})
+	if result, err := unconfigured.CheckAvailability(context.Background(), "Course", "", "NV", date); result != nil || err != nil {
+		t.Errorf("CheckAvailability() without endpoint = %+v, %v; want nil, nil", result, err)
+	}
+	if result, err := client.CheckAvailability(context.Background(), "Course", "", "NV", time.Time{}); result != nil || err != nil {
+		t.Errorf("CheckAvailability() without date = %+v, %v; want nil, nil", result, err)
+	}
+}
@@ -1,11 +1,6 @@
 package telegram
 
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
-)
+import "context"
 
 // User represents a Telegram user
 type User struct {
@@ -38,9 +33,7 @@ type CallbackQuery struct {
 }
 
 // AnswerCallbackQuery sends an answer to a callback query
-func (c *Client) AnswerCallbackQuery(callbackID string, text string, showAlert bool) error {
-	url := fmt.Sprintf("%s%s/answerCallbackQuery", apiBaseURL, c.botToken)
-
+func (c *Client) AnswerCallbackQuery(ctx context.Context, callbackID string, text string, showAlert bool) error {
 	payload := map[string]interface{}{
 		"callback_query_id": callbackID,
 	}
@@ -50,35 +43,12 @@ func (c *Client) AnswerCallbackQuery(callbackID string, text string, showAlert b
 		payload["show_alert"] = showAlert
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshaling payload: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API error (status %d)", resp.StatusCode)
-	}
-
-	return nil
+	_, err := c.callMethod(ctx, "answerCallbackQuery", payload)
+	return err
 }
 
 // EditMessageText edits the text of a message
-func (c *Client) EditMessageText(chatID string, messageID int, text string, keyboard *InlineKeyboardMarkup) error {
-	url := fmt.Sprintf("%s%s/editMessageText", apiBaseURL, c.botToken)
-
+func (c *Client) EditMessageText(ctx context.Context, chatID string, messageID int, text string, keyboard *InlineKeyboardMarkup) error {
 	payload := map[string]interface{}{
 		"chat_id":                  chatID,
 		"message_id":               messageID,
@@ -91,27 +61,6 @@ func (c *Client) EditMessageText(chatID string, messageID int, text string, keyb
 		payload["reply_markup"] = keyboard
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshaling payload: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API error (status %d)", resp.StatusCode)
-	}
-
-	return nil
+	_, err := c.callMethod(ctx, "editMessageText", payload)
+	return err
 }
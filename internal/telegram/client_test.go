@@ -1,7 +1,10 @@
 package telegram
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestNewClient_Validation(t *testing.T) {
@@ -76,7 +79,7 @@ func TestSendMessage_Validation(t *testing.T) {
 	}
 
 	// Test empty message
-	err := client.SendMessage("")
+	err := client.SendMessage(context.Background(), "")
 	if err == nil {
 		t.Error("SendMessage() expected error for empty message, got nil")
 	}
@@ -92,7 +95,7 @@ func TestSendMessageWithKeyboard_Validation(t *testing.T) {
 	}
 
 	// Test empty message
-	err := client.SendMessageWithKeyboard("", nil)
+	err := client.SendMessageWithKeyboard(context.Background(), "", nil)
 	if err == nil {
 		t.Error("SendMessageWithKeyboard() expected error for empty message, got nil")
 	}
@@ -212,3 +215,29 @@ func TestCallbackQuery(t *testing.T) {
 		t.Errorf("Data = %q, want 'button_clicked'", cbq.Data)
 	}
 }
+
+func TestNewBotClient_Validation(t *testing.T) {
+	if _, err := NewBotClient(""); err == nil {
+		t.Error("NewBotClient(\"\") expected error, got nil")
+	}
+	if _, err := NewBotClient("test-token"); err != nil {
+		t.Errorf("NewBotClient() unexpected error: %v", err)
+	}
+}
+
+func TestPause(t *testing.T) {
+	if err := Pause(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Pause() error = %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := Pause(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Pause() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Pause() returned after %v, want prompt return on cancel", elapsed)
+	}
+}
@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BotCommand represents a command shown in Telegram's command menu and autocomplete
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// BotCommandScope selects which chats a command list applies to
+type BotCommandScope struct {
+	Type   string `json:"type"`              // default, all_private_chats, all_group_chats, chat
+	ChatID string `json:"chat_id,omitempty"` // Required when Type is "chat"
+}
+
+// Telegram limits for setMyCommands
+const (
+	MaxBotCommands           = 100
+	MaxCommandLength         = 32
+	MaxCommandDescriptionLen = 256
+)
+
+// NewBotClient creates a client for bot-level API methods that don't target a chat
+func NewBotClient(botToken string) (*Client, error) {
+	if botToken == "" {
+		return nil, fmt.Errorf("bot token is required")
+	}
+
+	return &Client{
+		botToken: botToken,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+// SetMyCommands registers the bot's command list for a scope and language.
+// A nil scope and empty languageCode set the default list for all users.
+func (c *Client) SetMyCommands(ctx context.Context, commands []BotCommand, scope *BotCommandScope, languageCode string) error {
+	if len(commands) > MaxBotCommands {
+		return fmt.Errorf("too many commands: %d (max %d)", len(commands), MaxBotCommands)
+	}
+
+	payload := map[string]interface{}{
+		"commands": commands,
+	}
+	if scope != nil {
+		payload["scope"] = scope
+	}
+	if languageCode != "" {
+		payload["language_code"] = languageCode
+	}
+
+	_, err := c.callMethod(ctx, "setMyCommands", payload)
+	return err
+}
+
+// GetMyCommands returns the command list currently registered for a scope and language
+func (c *Client) GetMyCommands(ctx context.Context, scope *BotCommandScope, languageCode string) ([]BotCommand, error) {
+	payload := map[string]interface{}{}
+	if scope != nil {
+		payload["scope"] = scope
+	}
+	if languageCode != "" {
+		payload["language_code"] = languageCode
+	}
+
+	raw, err := c.callMethod(ctx, "getMyCommands", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []BotCommand
+	if err := json.Unmarshal(raw, &commands); err != nil {
+		return nil, fmt.Errorf("parsing commands: %w", err)
+	}
+
+	return commands, nil
+}
+
+// GetMe returns the bot's own account, confirming the token is valid
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	raw, err := c.callMethod(ctx, "getMe", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	var me User
+	if err := json.Unmarshal(raw, &me); err != nil {
+		return nil, fmt.Errorf("parsing bot account: %w", err)
+	}
+
+	return &me, nil
+}
+
+// callMethod posts a JSON payload to a Bot API method and returns the raw result
+func (c *Client) callMethod(ctx context.Context, method string, payload map[string]interface{}) (json.RawMessage, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	return c.post(ctx, method, "application/json", jsonData)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
 )
 
 // FormatDigest formats a batch of events as a digest message
@@ -23,12 +24,25 @@ func FormatDigest(events []*event.Event, frequency string) string {
 	}
 	msg += fmt.Sprintf("🗓 %s digest • %d new event(s)\n\n", freqCapitalized, len(events))
 
-	// Group events by state
+	// National/majors events get their own section ahead of the states
+	var national []*event.Event
 	byState := make(map[string][]*event.Event)
 	for _, evt := range events {
+		if evt.IsNational() {
+			national = append(national, evt)
+			continue
+		}
 		byState[evt.State] = append(byState[evt.State], evt)
 	}
 
+	if len(national) > 0 {
+		msg += fmt.Sprintf("🏆 <b>National &amp; Majors</b> (%d event%s)\n", len(national), pluralize(len(national)))
+		for _, evt := range national {
+			msg += formatDigestEvent(evt, true)
+		}
+		msg += "\n"
+	}
+
 	// Sort states alphabetically
 	states := make([]string, 0, len(byState))
 	for state := range byState {
@@ -42,24 +56,38 @@ func FormatDigest(events []*event.Event, frequency string) string {
 		msg += fmt.Sprintf("📍 <b>%s</b> (%d event%s)\n", state, len(stateEvents), pluralize(len(stateEvents)))
 
 		for _, evt := range stateEvents {
-			msg += fmt.Sprintf("  • %s", evt.Title)
-			if evt.DateText != "" {
-				msg += fmt.Sprintf(" (%s)", evt.DateText)
-			}
-			if evt.City != "" {
-				msg += fmt.Sprintf(" - %s", evt.City)
-			}
-			msg += "\n"
+			msg += formatDigestEvent(evt, false)
 		}
 		msg += "\n"
 	}
 
-	msg += "🔗 <b>Register:</b> https://vgagolf.org/state-events\n\n"
+	msg += "🔗 <b>Register:</b> " + registrationLink("", links.CampaignDigest) + "\n\n"
 	msg += "💬 <i>/settings to change digest frequency</i>"
 
 	return msg
 }
 
+// formatDigestEvent formats one digest line; withState adds the state after the city,
+// for sections not grouped by state
+func formatDigestEvent(evt *event.Event, withState bool) string {
+	line := fmt.Sprintf("  • %s", evt.Title)
+	if badges := tagBadges(evt, false); badges != "" {
+		line += " " + badges
+	}
+	if evt.DateText != "" {
+		line += fmt.Sprintf(" (%s)", evt.DateText)
+	}
+	switch {
+	case withState && evt.City != "":
+		line += fmt.Sprintf(" - %s, %s", evt.City, evt.State)
+	case withState:
+		line += " - " + evt.State
+	case evt.City != "":
+		line += fmt.Sprintf(" - %s", evt.City)
+	}
+	return line + "\n"
+}
+
 // FormatDigestSummary creates a short summary for a digest
 func FormatDigestSummary(events []*event.Event, frequency string) string {
 	if len(events) == 0 {
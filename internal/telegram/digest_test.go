@@ -136,6 +136,50 @@ func TestFormatDigest(t *testing.T) {
 			},
 			wantEmpty: false,
 		},
+		{
+			name: "event with tags",
+			events: []*event.Event{
+				{
+					ID:        "evt1",
+					State:     "NV",
+					Title:     "Club Championship Scramble",
+					DateText:  "Apr 4 2026",
+					FirstSeen: time.Now(),
+				},
+			},
+			frequency: "daily",
+			wantContains: []string{
+				"Club Championship Scramble 🏆🔀",
+			},
+			wantEmpty: false,
+		},
+		{
+			name: "national events in their own section",
+			events: []*event.Event{
+				{
+					ID:        "evt1",
+					State:     "NV",
+					Title:     "Chimera Golf Club",
+					DateText:  "Apr 4 2026",
+					FirstSeen: time.Now(),
+				},
+				{
+					ID:        "evt2",
+					State:     "AZ",
+					Title:     "VGA National Championship",
+					DateText:  "Oct 10 2026",
+					City:      "Scottsdale",
+					Scope:     event.ScopeNational,
+					FirstSeen: time.Now(),
+				},
+			},
+			frequency: "weekly",
+			wantContains: []string{
+				"🏆 <b>National &amp; Majors</b> (1 event)\n  • VGA National Championship 🏆 (Oct 10 2026) - Scottsdale, AZ",
+				"📍 <b>NV</b> (1 event)\n  • Chimera Golf Club",
+			},
+			wantEmpty: false,
+		},
 	}
 
 	for _, tt := range tests {
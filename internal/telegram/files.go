@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// fileBaseURL is a package variable (not const) to allow test overriding
+var fileBaseURL = "https://api.telegram.org/file/bot"
+
+// MaxDownloadBytes is the largest file the Bot API lets bots download
+const MaxDownloadBytes = 20 << 20
+
+// DownloadFile fetches a file a user sent the bot, by its file_id
+func (c *Client) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	if fileID == "" {
+		return nil, fmt.Errorf("file ID is required")
+	}
+
+	raw, err := c.callMethod(ctx, "getFile", map[string]interface{}{"file_id": fileID})
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parsing getFile result: %w", err)
+	}
+	if file.FilePath == "" {
+		return nil, fmt.Errorf("file %s can't be downloaded", fileID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s/%s", fileBaseURL, c.botToken, file.FilePath), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.Status(resp.StatusCode, "file download returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, MaxDownloadBytes))
+}
+
+// SendVoice re-sends a voice message Telegram already has, by its file_id, with an
+// optional caption to the configured chat
+func (c *Client) SendVoice(ctx context.Context, fileID, caption string) error {
+	if fileID == "" {
+		return fmt.Errorf("file ID is required")
+	}
+
+	payload := map[string]interface{}{
+		"chat_id": c.chatID,
+		"voice":   fileID,
+	}
+
+	if caption != "" {
+		payload["caption"] = caption
+		payload["parse_mode"] = "HTML"
+	}
+
+	_, err := c.callMethod(ctx, "sendVoice", payload)
+	return err
+}
@@ -2,9 +2,13 @@ package telegram
 
 import (
 	"fmt"
+	"html"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
 	"github.com/pfrederiksen/vga-events/internal/preferences"
 )
 
@@ -20,10 +24,17 @@ type TeeDetails struct {
 
 // CourseDetails represents golf course information for formatting
 type CourseDetails struct {
-	Name    string
-	Tees    []TeeDetails
-	Website string
-	Phone   string
+	Name     string
+	Tees     []TeeDetails
+	Website  string
+	Phone    string
+	ImageURL string // Course photo sent alongside the event card, if provided
+
+	// Tee-time provider details
+	TeeTimeURL        string // Booking/search link for the "Check tee times" button
+	TeeTimesChecked   bool   // Availability lookup returned a result
+	TeeTimesAvailable bool   // Provider reports open tee times around the event date
+	TeeTimeSlots      int    // Number of open tee times, if the provider reports it
 }
 
 // FormatEvent formats a single event as a Telegram message
@@ -44,7 +55,7 @@ func FormatEventWithNote(evt *event.Event, note string) string {
 	}
 
 	// Registration link
-	msg.WriteString("\n🔗 <a href=\"https://vgagolf.org/state-events\">vgagolf.org/state-events</a>\n")
+	msg.WriteString("\n🔗 " + registrationLink(evt.ID, links.CampaignNewEvent) + "\n")
 	msg.WriteString("<i>(login required)</i>\n")
 
 	// Hashtags
@@ -86,23 +97,28 @@ func FormatEventWithCourse(evt *event.Event, course *CourseDetails, note string)
 		}
 
 		// Website if available
-		if course.Website != "" {
-			msg.WriteString(fmt.Sprintf("🌐 %s\n", course.Website))
+		if link := formatWebsiteLink(course.Website); link != "" {
+			msg.WriteString(fmt.Sprintf("🌐 %s\n", link))
 		}
 
-		// Phone if available
+		// Phone if available (Telegram auto-links phone numbers)
 		if course.Phone != "" {
-			msg.WriteString(fmt.Sprintf("📞 %s\n", course.Phone))
+			msg.WriteString(fmt.Sprintf("📞 %s\n", html.EscapeString(course.Phone)))
 		}
 	}
 
+	// Tee-time availability (shown even without tee data)
+	if course != nil && course.TeeTimesChecked {
+		msg.WriteString(formatTeeTimeAvailability(course.TeeTimesAvailable, course.TeeTimeSlots))
+	}
+
 	// Note (if available)
 	if note != "" {
 		msg.WriteString(fmt.Sprintf("\n📝 <i>%s</i>\n", note))
 	}
 
 	// Registration link
-	msg.WriteString("\n🔗 <a href=\"https://vgagolf.org/state-events\">vgagolf.org/state-events</a>\n")
+	msg.WriteString("\n🔗 " + registrationLink(evt.ID, links.CampaignNewEvent) + "\n")
 	msg.WriteString("<i>(login required)</i>\n")
 
 	// Hashtags
@@ -142,20 +158,43 @@ func FormatEventWithStatus(evt *event.Event, currentStatus string) (string, *Inl
 	return FormatEventWithStatusAndNote(evt, currentStatus, "", "", nil)
 }
 
-// FormatEventWithStatusAndNote formats an event message with status, note, friend count, and calendar buttons
+// friendsLine counts the friends who share that they're registered for or interested in
+// an event, as allowed by their sharing settings, or returns "" if there are none
+func friendsLine(prefs preferences.Preferences, chatID, eventID string) string {
+	registered, interested := 0, 0
+	for _, status := range prefs.FriendStatuses(chatID, eventID) {
+		if status == preferences.EventStatusRegistered {
+			registered++
+		} else {
+			interested++
+		}
+	}
+
+	var counts []string
+	if registered > 0 {
+		counts = append(counts, fmt.Sprintf("<b>%d</b> registered", registered))
+	}
+	if interested > 0 {
+		counts = append(counts, fmt.Sprintf("<b>%d</b> interested", interested))
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+	noun := "friends"
+	if registered+interested == 1 {
+		noun = "friend"
+	}
+	return fmt.Sprintf("\n👥 Your %s: %s\n", noun, strings.Join(counts, ", "))
+}
+
+// FormatEventWithStatusAndNote formats an event message with status, note, friend count, and calendar buttons.
+// The Refresh button re-renders the card with the event's current details.
 func FormatEventWithStatusAndNote(evt *event.Event, currentStatus, note, chatID string, prefs preferences.Preferences) (string, *InlineKeyboardMarkup) {
 	text := FormatEventWithNote(evt, note)
 
 	// Add friend count if user has friends registered/interested in this event
 	if chatID != "" && prefs != nil {
-		friendIDs := prefs.GetFriendsForEvent(chatID, evt.ID)
-		if len(friendIDs) > 0 {
-			friendText := ""
-			if len(friendIDs) == 1 {
-				friendText = "\n👥 <b>1 friend</b> registered for this event\n"
-			} else {
-				friendText = fmt.Sprintf("\n👥 <b>%d friends</b> registered for this event\n", len(friendIDs))
-			}
+		if friendText := friendsLine(prefs, chatID, evt.ID); friendText != "" {
 			// Insert friend info after the course info and before the registration link
 			text = strings.Replace(text, "\n🔗 <a href=", friendText+"\n🔗 <a href=", 1)
 		}
@@ -188,6 +227,8 @@ func FormatEventWithStatusAndNote(evt *event.Event, currentStatus, note, chatID
 		InlineKeyboard: [][]InlineKeyboardButton{
 			{
 				{Text: "📅 Calendar", CallbackData: fmt.Sprintf("calendar:%s", evt.ID)},
+				{Text: "💬 Discuss", CallbackData: fmt.Sprintf("discuss:%s", evt.ID)},
+				{Text: "🔄 Refresh", CallbackData: fmt.Sprintf("refresh:%s", evt.ID)},
 			},
 			{
 				{Text: "⭐ Interested", CallbackData: fmt.Sprintf("status:%s:interested", evt.ID)},
@@ -209,14 +250,7 @@ func FormatEventWithStatusAndCourse(evt *event.Event, course *CourseDetails, cur
 
 	// Add friend count if user has friends registered/interested in this event
 	if chatID != "" && prefs != nil {
-		friendIDs := prefs.GetFriendsForEvent(chatID, evt.ID)
-		if len(friendIDs) > 0 {
-			friendText := ""
-			if len(friendIDs) == 1 {
-				friendText = "\n👥 <b>1 friend</b> registered for this event\n"
-			} else {
-				friendText = fmt.Sprintf("\n👥 <b>%d friends</b> registered for this event\n", len(friendIDs))
-			}
+		if friendText := friendsLine(prefs, chatID, evt.ID); friendText != "" {
 			// Insert friend info after the course info and before the registration link
 			text = strings.Replace(text, "\n🔗 <a href=", friendText+"\n🔗 <a href=", 1)
 		}
@@ -249,6 +283,7 @@ func FormatEventWithStatusAndCourse(evt *event.Event, course *CourseDetails, cur
 		InlineKeyboard: [][]InlineKeyboardButton{
 			{
 				{Text: "📅 Calendar", CallbackData: fmt.Sprintf("calendar:%s", evt.ID)},
+				{Text: "💬 Discuss", CallbackData: fmt.Sprintf("discuss:%s", evt.ID)},
 			},
 			{
 				{Text: "⭐ Interested", CallbackData: fmt.Sprintf("status:%s:interested", evt.ID)},
@@ -260,10 +295,54 @@ func FormatEventWithStatusAndCourse(evt *event.Event, course *CourseDetails, cur
 			},
 		},
 	}
+	addTeeTimeButton(keyboard, course)
+
+	return text, keyboard
+}
+
+// Variants of the new-event card for experiment.NewEventFormat
+const (
+	VariantControl = "control" // FormatEventWithStatusAndCourse
+	VariantCompact = "compact" // FormatEventCompact
+)
 
+// FormatNewEventVariant formats a new-event notification in the given variant. Both
+// variants have the same buttons.
+func FormatNewEventVariant(variant string, evt *event.Event, course *CourseDetails) (string, *InlineKeyboardMarkup) {
+	text, keyboard := FormatEventWithStatusAndCourse(evt, course, "", "", "", nil)
+	if variant == VariantCompact {
+		text = FormatEventCompact(evt)
+	}
 	return text, keyboard
 }
 
+// FormatEventCompact formats an event in three or four lines, without course details
+// or hashtags
+func FormatEventCompact(evt *event.Event) string {
+	var msg strings.Builder
+
+	msg.WriteString(fmt.Sprintf("🏌️ <b>%s</b> (%s)\n", evt.Title, evt.State))
+
+	var when []string
+	if evt.DateText != "" {
+		when = append(when, event.FormatDateNice(evt.DateText))
+	}
+	if evt.City != "" {
+		when = append(when, evt.City)
+	}
+	if len(when) > 0 {
+		msg.WriteString("📅 " + strings.Join(when, " · ") + "\n")
+	}
+
+	formatTags(&msg, evt)
+	formatDeadline(&msg, evt)
+	formatField(&msg, evt)
+	formatShortCode(&msg, evt)
+	msg.WriteString("🔗 " + registrationLink(evt.ID, links.CampaignNewEvent))
+
+	return msg.String()
+}
+
 // FormatSummary creates a summary message for multiple events
 func FormatSummary(count int, states []string) string {
 	var msg strings.Builder
@@ -287,6 +366,97 @@ func FormatSummary(count int, states []string) string {
 	return msg.String()
 }
 
+// FormatDiffSummary formats one run's diff for a public announcements channel: totals,
+// then a line per state with any activity (busiest first), like FormatSummary but with
+// changes and removals. Changes whose state isn't known only count toward the totals.
+func FormatDiffSummary(counts map[string]event.DiffCounts) string {
+	var total event.DiffCounts
+	states := make([]string, 0, len(counts))
+	for state, c := range counts {
+		total.New += c.New
+		total.Changed += c.Changed
+		total.Removed += c.Removed
+		if state != "" {
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		ti, tj := counts[states[i]].Total(), counts[states[j]].Total()
+		if ti != tj {
+			return ti > tj
+		}
+		return states[i] < states[j]
+	})
+
+	var msg strings.Builder
+	msg.WriteString("🏌️ <b>VGA Events Update</b>\n\n")
+	msg.WriteString(fmt.Sprintf("🆕 <b>%d</b> new · ✏️ <b>%d</b> changed · ❌ <b>%d</b> removed\n\n", total.New, total.Changed, total.Removed))
+
+	for _, state := range states {
+		msg.WriteString(fmt.Sprintf("📍 <b>%s</b> — %s\n", preferences.GetStateName(state), formatDiffCounts(counts[state])))
+	}
+	if len(states) > 0 {
+		msg.WriteString("\n")
+	}
+
+	msg.WriteString("🔗 <b>Register:</b> " + registrationLink("", links.CampaignSummary) + "\n\n")
+	msg.WriteString("#VGAGolf")
+	return msg.String()
+}
+
+// formatDiffCounts lists a state's non-zero counts, e.g. "2 new, 1 removed"
+func formatDiffCounts(c event.DiffCounts) string {
+	var parts []string
+	if c.New > 0 {
+		parts = append(parts, fmt.Sprintf("%d new", c.New))
+	}
+	if c.Changed > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed", c.Changed))
+	}
+	if c.Removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", c.Removed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatNewState announces a state's first ever VGA events, for users subscribed to
+// all states and the announcements channel
+func FormatNewState(state string, events []*event.Event) string {
+	name := preferences.GetStateName(state)
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("🎉 <b>First VGA event listed in %s!</b>\n\n", name))
+	for _, evt := range events {
+		if evt.State != state {
+			continue
+		}
+		msg.WriteString(fmt.Sprintf("🏌️ %s", evt.Title))
+		if evt.DateText != "" {
+			msg.WriteString(" - " + event.FormatDateNice(evt.DateText))
+		}
+		msg.WriteString("\n")
+	}
+
+	msg.WriteString(fmt.Sprintf("\nSubscribe with /subscribe %s to hear about every event there.\n", state))
+	msg.WriteString("🔗 <b>Register:</b> " + registrationLink("", links.CampaignNewState) + "\n\n")
+	msg.WriteString(fmt.Sprintf("#VGAGolf #NewState #%s", strings.ReplaceAll(state, " ", "")))
+	return msg.String()
+}
+
+// FormatOverflowNotice tells the user how many new events weren't sent individually
+// because of the per-run cap. Its button lists every event first seen since the run started.
+func FormatOverflowNotice(remaining int, since time.Time) (string, *InlineKeyboardMarkup) {
+	msg := fmt.Sprintf("📬 <b>…and %d more new event%s</b>\n\nOnly the soonest events are sent one by one, so a busy run doesn't flood your chat.",
+		remaining, pluralize(remaining))
+
+	keyboard := &InlineKeyboardMarkup{
+		InlineKeyboard: [][]InlineKeyboardButton{
+			{{Text: "📋 View all", CallbackData: fmt.Sprintf("new:%d:0", since.Unix())}},
+		},
+	}
+	return msg, keyboard
+}
+
 // FormatReminder formats a reminder message for an upcoming event
 func FormatReminder(evt *event.Event, daysUntil int) (string, *InlineKeyboardMarkup) {
 	var msg strings.Builder
@@ -324,8 +494,12 @@ func FormatReminder(evt *event.Event, daysUntil int) (string, *InlineKeyboardMar
 		msg.WriteString(fmt.Sprintf("🏢 %s\n", evt.City))
 	}
 
+	formatDeadline(&msg, evt)
+	formatField(&msg, evt)
+	formatShortCode(&msg, evt)
+
 	// Registration link
-	msg.WriteString("\n🔗 <a href=\"https://vgagolf.org/state-events\">vgagolf.org/state-events</a>\n")
+	msg.WriteString("\n🔗 " + registrationLink(evt.ID, links.CampaignReminder) + "\n")
 	msg.WriteString("<i>(login required)</i>\n")
 
 	// Hashtags
@@ -337,6 +511,7 @@ func FormatReminder(evt *event.Event, daysUntil int) (string, *InlineKeyboardMar
 		InlineKeyboard: [][]InlineKeyboardButton{
 			{
 				{Text: "📅 Calendar", CallbackData: fmt.Sprintf("calendar:%s", evt.ID)},
+				{Text: "💬 Discuss", CallbackData: fmt.Sprintf("discuss:%s", evt.ID)},
 			},
 			{
 				{Text: "⭐ Interested", CallbackData: fmt.Sprintf("status:%s:interested", evt.ID)},
@@ -352,6 +527,92 @@ func FormatReminder(evt *event.Event, daysUntil int) (string, *InlineKeyboardMar
 	return msg.String(), keyboard
 }
 
+// FormatDeadlineReminder formats a reminder that registration for an event the user
+// is interested in closes soon
+func FormatDeadlineReminder(evt *event.Event, daysUntil int) (string, *InlineKeyboardMarkup) {
+	var msg strings.Builder
+
+	msg.WriteString("⏳ <b>Registration Closing Soon!</b>\n\n")
+
+	switch daysUntil {
+	case 0:
+		msg.WriteString("Registration closes <b>today</b> for an event you're interested in.\n\n")
+	case 1:
+		msg.WriteString("Registration closes <b>tomorrow</b> for an event you're interested in.\n\n")
+	default:
+		msg.WriteString(fmt.Sprintf("Registration closes in <b>%d days</b> for an event you're interested in.\n\n", daysUntil))
+	}
+
+	msg.WriteString(fmt.Sprintf("🏌️ <b>%s</b> - %s\n", evt.State, evt.Title))
+	if evt.DateText != "" {
+		msg.WriteString(fmt.Sprintf("📆 %s\n", event.FormatDateNice(evt.DateText)))
+	}
+	if evt.City != "" {
+		msg.WriteString(fmt.Sprintf("🏢 %s\n", evt.City))
+	}
+	formatDeadline(&msg, evt)
+	formatField(&msg, evt)
+	formatShortCode(&msg, evt)
+
+	msg.WriteString("\n🔗 " + registrationLink(evt.ID, links.CampaignDeadline) + "\n")
+	msg.WriteString("<i>(login required)</i>\n")
+
+	stateHashtag := fmt.Sprintf("#%s", strings.ReplaceAll(evt.State, " ", ""))
+	msg.WriteString(fmt.Sprintf("\n#VGAGolf #Golf %s #Deadline", stateHashtag))
+
+	keyboard := &InlineKeyboardMarkup{
+		InlineKeyboard: [][]InlineKeyboardButton{
+			{
+				{Text: "✅ I registered", CallbackData: fmt.Sprintf("status:%s:registered", evt.ID)},
+				{Text: "❌ Not going", CallbackData: fmt.Sprintf("status:%s:skip", evt.ID)},
+			},
+		},
+	}
+
+	return msg.String(), keyboard
+}
+
+// FormatAlmostFull formats an alert that an event the user is interested in is almost
+// full
+func FormatAlmostFull(evt *event.Event) (string, *InlineKeyboardMarkup) {
+	var msg strings.Builder
+
+	msg.WriteString("🔥 <b>Almost Full!</b>\n\n")
+	if left, ok := evt.SpotsLeft(); ok && left > 0 {
+		msg.WriteString(fmt.Sprintf("Only <b>%d spot(s)</b> left at an event you're interested in.\n\n", left))
+	} else {
+		msg.WriteString("The field is full at an event you're interested in; you may still get on a waitlist.\n\n")
+	}
+
+	msg.WriteString(fmt.Sprintf("🏌️ <b>%s</b> - %s\n", evt.State, evt.Title))
+	if evt.DateText != "" {
+		msg.WriteString(fmt.Sprintf("📆 %s\n", event.FormatDateNice(evt.DateText)))
+	}
+	if evt.City != "" {
+		msg.WriteString(fmt.Sprintf("🏢 %s\n", evt.City))
+	}
+	formatField(&msg, evt)
+	formatDeadline(&msg, evt)
+	formatShortCode(&msg, evt)
+
+	msg.WriteString("\n🔗 " + registrationLink(evt.ID, links.CampaignAlmostFull) + "\n")
+	msg.WriteString("<i>(login required)</i>\n")
+
+	stateHashtag := fmt.Sprintf("#%s", strings.ReplaceAll(evt.State, " ", ""))
+	msg.WriteString(fmt.Sprintf("\n#VGAGolf #Golf %s #AlmostFull", stateHashtag))
+
+	keyboard := &InlineKeyboardMarkup{
+		InlineKeyboard: [][]InlineKeyboardButton{
+			{
+				{Text: "✅ I registered", CallbackData: fmt.Sprintf("status:%s:registered", evt.ID)},
+				{Text: "❌ Not going", CallbackData: fmt.Sprintf("status:%s:skip", evt.ID)},
+			},
+		},
+	}
+
+	return msg.String(), keyboard
+}
+
 // FormatEventChange formats an event change notification
 func FormatEventChange(evt *event.Event, changeType, oldValue, newValue string) string {
 	var msg strings.Builder
@@ -369,6 +630,11 @@ func FormatEventChange(evt *event.Event, changeType, oldValue, newValue string)
 			displayNew = event.FormatDateNice(newValue)
 		}
 		formatChangeValue(&msg, oldValue, displayNew, "date")
+	case "renamed":
+		msg.WriteString("🏌️ <b>Event Renamed:</b>\n")
+		msg.WriteString(fmt.Sprintf("  ❌ <s>%s</s>\n", oldValue))
+		msg.WriteString(fmt.Sprintf("  ✅ %s\n", newValue))
+		msg.WriteString("<i>Your status and notes carry over.</i>\n")
 	case "title":
 		msg.WriteString("🏌️ <b>Title Changed:</b>\n")
 		msg.WriteString(fmt.Sprintf("  ❌ <s>%s</s>\n", oldValue))
@@ -378,7 +644,7 @@ func FormatEventChange(evt *event.Event, changeType, oldValue, newValue string)
 		formatChangeValue(&msg, oldValue, newValue, "city")
 	}
 
-	msg.WriteString("\n🔗 <a href=\"https://vgagolf.org/state-events\">vgagolf.org/state-events</a>\n")
+	msg.WriteString("\n🔗 " + registrationLink(evt.ID, links.CampaignChange) + "\n")
 	msg.WriteString("<i>(login required)</i>\n")
 
 	return msg.String()
@@ -483,7 +749,7 @@ func FormatRemovedEvent(evt *event.Event, status string, note string) string {
 
 	// Explanation
 	msg.WriteString("\n❗ <b>This event is no longer listed on the VGA website.</b>\n")
-	msg.WriteString("Please check <a href=\"https://vgagolf.org/state-events\">vgagolf.org/state-events</a> for updates.\n")
+	msg.WriteString("Please check " + registrationLink(evt.ID, links.CampaignRemoval) + " for updates.\n")
 
 	// Hashtags
 	stateHashtag := fmt.Sprintf("#%s", strings.ReplaceAll(evt.State, " ", ""))
@@ -492,6 +758,43 @@ func FormatRemovedEvent(evt *event.Event, status string, note string) string {
 	return msg.String()
 }
 
+// FormatRestoredEvent formats the follow-up to a removal notification when the event is
+// listed on the VGA website again. status is the user's status for it, if any.
+func FormatRestoredEvent(evt *event.Event, status string) string {
+	var msg strings.Builder
+
+	msg.WriteString("✅ <b>Event Restored</b>\n\n")
+	msg.WriteString(fmt.Sprintf("📍 <b>%s</b> - %s\n", evt.State, evt.Title))
+	if evt.DateText != "" {
+		msg.WriteString(fmt.Sprintf("📅 %s\n", event.FormatDateNice(evt.DateText)))
+	}
+	if evt.City != "" {
+		msg.WriteString(fmt.Sprintf("🏢 %s\n", evt.City))
+	}
+
+	msg.WriteString("\nThis event is back on the VGA website, so the earlier removal notice no longer applies.\n")
+	if status == preferences.EventStatusRegistered {
+		msg.WriteString("✅ You're still marked registered; check " + registrationLink(evt.ID, links.CampaignRestored) + " that your registration still stands.\n")
+	} else {
+		msg.WriteString("Details: " + registrationLink(evt.ID, links.CampaignRestored) + "\n")
+	}
+
+	stateHashtag := fmt.Sprintf("#%s", strings.ReplaceAll(evt.State, " ", ""))
+	msg.WriteString(fmt.Sprintf("\n#VGAGolf #EventRestored %s", stateHashtag))
+
+	return msg.String()
+}
+
+// FormatCancelledCard formats the text an event card is edited to once its event is
+// removed: the card as it was, marked CANCELLED with the title struck through. It's
+// sent without a keyboard, so the status buttons go away.
+func FormatCancelledCard(evt *event.Event, currentStatus, note, chatID string, prefs preferences.Preferences) string {
+	text, _ := FormatEventWithStatusAndNote(evt, currentStatus, note, chatID, prefs)
+	title := fmt.Sprintf("📍 <b>%s</b> - %s\n", evt.State, evt.Title)
+	text = strings.Replace(text, title, fmt.Sprintf("📍 <b>%s</b> - <s>%s</s>\n", evt.State, evt.Title), 1)
+	return "❌ <b>CANCELLED</b> - no longer listed on the VGA website\n\n" + text
+}
+
 // FormatRemovedEventGeneral formats a low-urgency notification for removed events
 // Used when user is subscribed to the state but didn't have the event tracked
 func FormatRemovedEventGeneral(evt *event.Event) string {
@@ -1,10 +1,16 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
+	"html"
 	"strings"
+	"time"
 
+	"github.com/pfrederiksen/vga-events/internal/course"
 	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/links"
+	"github.com/pfrederiksen/vga-events/internal/teetime"
 )
 
 // formatChangeValue formats old/new value display for event changes
@@ -46,4 +52,190 @@ func formatEventHeader(msg *strings.Builder, evt *event.Event, hasNote bool) {
 	if evt.City != "" {
 		msg.WriteString(fmt.Sprintf("🏢 %s\n", evt.City))
 	}
+
+	formatTags(msg, evt)
+	formatDeadline(msg, evt)
+	formatField(msg, evt)
+	formatShortCode(msg, evt)
+}
+
+// formatTags writes the event's tag badges, e.g. "🏆 Championship · ❤️ Charity"
+func formatTags(msg *strings.Builder, evt *event.Event) {
+	if badges := tagBadges(evt, true); badges != "" {
+		msg.WriteString(badges + "\n")
+	}
+}
+
+// tagBadges returns the emoji for each of the event's tags, with their labels if
+// labels is set
+func tagBadges(evt *event.Event, labels bool) string {
+	var badges []string
+	for _, tag := range evt.GetTags() {
+		rule, ok := event.LookupTag(tag)
+		if !ok {
+			continue
+		}
+		if labels {
+			badges = append(badges, rule.Emoji+" "+rule.Label)
+		} else {
+			badges = append(badges, rule.Emoji)
+		}
+	}
+	if labels {
+		return strings.Join(badges, " · ")
+	}
+	return strings.Join(badges, "")
+}
+
+// formatDeadline writes the event's registration deadline, if the site lists one
+func formatDeadline(msg *strings.Builder, evt *event.Event) {
+	if line := evt.FormatDeadline(time.Now()); line != "" {
+		msg.WriteString(fmt.Sprintf("⏳ %s\n", html.EscapeString(line)))
+	}
+}
+
+// formatField writes the event's field size, if the site lists it
+func formatField(msg *strings.Builder, evt *event.Event) {
+	if line := evt.FormatField(); line != "" {
+		msg.WriteString(fmt.Sprintf("👥 %s\n", line))
+	}
+}
+
+// formatShortCode writes the event's short reference code, used with /note and /bulk
+func formatShortCode(msg *strings.Builder, evt *event.Event) {
+	if evt.ShortCode != "" {
+		msg.WriteString(fmt.Sprintf("🔖 <code>%s</code>\n", evt.ShortCode))
+	}
+}
+
+// formatWebsiteLink returns a clickable HTML link for a course website.
+// The link text drops the scheme, "www." prefix, and trailing slash for readability.
+func formatWebsiteLink(website string) string {
+	website = strings.TrimSpace(website)
+	if website == "" {
+		return ""
+	}
+
+	href := website
+	if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+		href = "https://" + href
+	}
+
+	display := strings.TrimPrefix(strings.TrimPrefix(href, "https://"), "http://")
+	display = strings.TrimPrefix(display, "www.")
+	display = strings.TrimSuffix(display, "/")
+
+	return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(href), html.EscapeString(display))
+}
+
+// FormatCourseImageCaption formats the caption for a course photo sent with an event card
+func FormatCourseImageCaption(course *CourseDetails) string {
+	if course == nil {
+		return ""
+	}
+	return fmt.Sprintf("⛳ <b>%s</b>", html.EscapeString(course.Name))
+}
+
+// formatTeeTimeAvailability formats the tee-time availability line for the course section
+func formatTeeTimeAvailability(available bool, slots int) string {
+	switch {
+	case !available:
+		return "🕐 Tee times: <i>none open around event date</i>\n"
+	case slots == 1:
+		return "🕐 Tee times: <b>1 open</b> around event date\n"
+	case slots > 1:
+		return fmt.Sprintf("🕐 Tee times: <b>%d open</b> around event date\n", slots)
+	default:
+		return "🕐 Tee times: <b>available</b> around event date\n"
+	}
+}
+
+// addTeeTimeButton appends a "Check tee times" URL button when a booking link is available
+func addTeeTimeButton(keyboard *InlineKeyboardMarkup, course *CourseDetails) {
+	if keyboard == nil || course == nil || course.TeeTimeURL == "" {
+		return
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []InlineKeyboardButton{
+		{Text: "⛳ Check tee times", URL: course.TeeTimeURL},
+	})
+}
+
+// AddTeeTimeDetails adds the tee-time booking link and availability to course details,
+// creating minimal details from the event when no course info was found. A failed
+// availability lookup is returned for the caller to log; the details still get the
+// booking link.
+func AddTeeTimeDetails(ctx context.Context, client *teetime.Client, evt *event.Event, details *CourseDetails) (*CourseDetails, error) {
+	if client == nil {
+		return details, nil
+	}
+
+	courseName := course.CleanCourseName(evt.Title)
+	if details != nil {
+		courseName = details.Name
+	}
+	eventDate := event.ParseDate(evt.DateText)
+
+	bookingURL := client.BookingURL(courseName, evt.City, evt.State, eventDate)
+	availability, err := client.CheckAvailability(ctx, courseName, evt.City, evt.State, eventDate)
+
+	if bookingURL == "" && availability == nil {
+		return details, err
+	}
+	if details == nil {
+		details = &CourseDetails{Name: courseName}
+	}
+
+	details.TeeTimeURL = bookingURL
+	if availability != nil {
+		details.TeeTimesChecked = true
+		details.TeeTimesAvailable = availability.Available
+		details.TeeTimeSlots = availability.Slots
+	}
+	return details, err
+}
+
+// TagKeyboard appends an experiment variant tag to the status and calendar buttons'
+// callback data ("status:ID:registered:b"), so the bot can count taps per variant
+func TagKeyboard(keyboard *InlineKeyboardMarkup, tag string) {
+	if keyboard == nil || tag == "" {
+		return
+	}
+	for _, row := range keyboard.InlineKeyboard {
+		for i := range row {
+			if strings.HasPrefix(row[i].CallbackData, "status:") || strings.HasPrefix(row[i].CallbackData, "calendar:") {
+				row[i].CallbackData += ":" + tag
+			}
+		}
+	}
+}
+
+// CourseKeyboard returns a keyboard with course links for cards sent without status buttons,
+// or nil when there is nothing to link to
+func CourseKeyboard(course *CourseDetails) *InlineKeyboardMarkup {
+	if course == nil || course.TeeTimeURL == "" {
+		return nil
+	}
+	keyboard := &InlineKeyboardMarkup{}
+	addTeeTimeButton(keyboard, course)
+	return keyboard
+}
+
+// registrationLink returns an HTML link to the VGA registration page for a message
+// about eventID (empty for digests), tagged or tracked as configured
+func registrationLink(eventID, campaign string) string {
+	href := links.Track(links.RegistrationURL, eventID, links.SourceTelegram, campaign)
+	return fmt.Sprintf("<a href=\"%s\">vgagolf.org/state-events</a>", html.EscapeString(href))
+}
+
+// AddHints inserts decision hints (see package hints) into an event card just before
+// its registration link, or at the end if it has none
+func AddHints(text string, hints []string) string {
+	if len(hints) == 0 {
+		return text
+	}
+	block := "\n" + strings.Join(hints, "\n") + "\n"
+	if i := strings.Index(text, "\n🔗 "); i >= 0 {
+		return text[:i] + block + text[i:]
+	}
+	return text + "\n" + block
 }
@@ -1,11 +1,13 @@
 package telegram
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/pfrederiksen/vga-events/internal/event"
 	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/teetime"
 )
 
 // TestFormatChangeValue tests the formatChangeValue helper function
@@ -183,6 +185,31 @@ func TestFormatEventHeader(t *testing.T) {
 				"Jul 10, 2026",
 			},
 		},
+		{
+			name: "event with short code",
+			event: &event.Event{
+				State:     "NV",
+				Title:     "Wolf Creek",
+				ShortCode: "NV-417",
+			},
+			hasNote:    false,
+			wantEmojis: []string{"🏌️", "🔖"},
+			wantText: []string{
+				"<code>NV-417</code>",
+			},
+		},
+		{
+			name: "event with tags",
+			event: &event.Event{
+				State: "NV",
+				Title: "Club Championship Charity Scramble",
+			},
+			hasNote:    false,
+			wantEmojis: []string{"🏆", "🔀", "❤️"},
+			wantText: []string{
+				"🏆 Championship · 🔀 Scramble · ❤️ Charity",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -423,3 +450,184 @@ func TestFormatEventWithStatusAndCourseEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// TestFormatWebsiteLink tests the formatWebsiteLink helper function
+func TestFormatWebsiteLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		website string
+		want    string
+	}{
+		{
+			name:    "full URL",
+			website: "https://www.pebblebeach.com/",
+			want:    `<a href="https://www.pebblebeach.com/">pebblebeach.com</a>`,
+		},
+		{
+			name:    "missing scheme",
+			website: "torreypinesgolfcourse.com",
+			want:    `<a href="https://torreypinesgolfcourse.com">torreypinesgolfcourse.com</a>`,
+		},
+		{
+			name:    "escapes query string",
+			website: "http://example.com/?a=1&b=2",
+			want:    `<a href="http://example.com/?a=1&amp;b=2">example.com/?a=1&amp;b=2</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatWebsiteLink(tt.website); got != tt.want {
+				t.Errorf("formatWebsiteLink(%q) = %q, want %q", tt.website, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatCourseImageCaption tests the course photo caption
+func TestFormatCourseImageCaption(t *testing.T) {
+	if got := FormatCourseImageCaption(nil); got != "" {
+		t.Errorf("FormatCourseImageCaption(nil) = %q, want empty", got)
+	}
+
+	got := FormatCourseImageCaption(&CourseDetails{Name: "Bandon Dunes & Pacific"})
+	want := "⛳ <b>Bandon Dunes &amp; Pacific</b>"
+	if got != want {
+		t.Errorf("FormatCourseImageCaption() = %q, want %q", got, want)
+	}
+}
+
+// TestFormatTeeTimeAvailability tests the tee-time availability line
+func TestFormatTeeTimeAvailability(t *testing.T) {
+	tests := []struct {
+		name      string
+		available bool
+		slots     int
+		want      string
+	}{
+		{name: "none open", available: false, slots: 0, want: "none open"},
+		{name: "single slot", available: true, slots: 1, want: "<b>1 open</b>"},
+		{name: "many slots", available: true, slots: 12, want: "<b>12 open</b>"},
+		{name: "count unknown", available: true, slots: 0, want: "<b>available</b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatTeeTimeAvailability(tt.available, tt.slots)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("formatTeeTimeAvailability(%v, %d) = %q, want it to contain %q", tt.available, tt.slots, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTeeTimeButton tests the "Check tee times" URL button on event cards
+func TestTeeTimeButton(t *testing.T) {
+	evt := &event.Event{
+		ID:       "test123",
+		State:    "CA",
+		Title:    "Pebble Beach",
+		DateText: "Apr 4 2026",
+	}
+	course := &CourseDetails{
+		Name:            "Pebble Beach",
+		TeeTimeURL:      "https://tee.example.com/search?q=Pebble+Beach",
+		TeeTimesChecked: true,
+	}
+
+	msg, keyboard := FormatEventWithStatusAndCourse(evt, course, "", "", "", nil)
+	lastRow := keyboard.InlineKeyboard[len(keyboard.InlineKeyboard)-1]
+	if len(lastRow) != 1 || lastRow[0].URL != course.TeeTimeURL || lastRow[0].Text != "⛳ Check tee times" {
+		t.Errorf("Last keyboard row = %+v, want tee-time URL button", lastRow)
+	}
+	if !strings.Contains(msg, "🕐 Tee times:") {
+		t.Error("Message should contain tee-time availability line")
+	}
+
+	// Without a link, no extra row and no standalone keyboard
+	_, plain := FormatEventWithStatusAndCourse(evt, &CourseDetails{Name: "Pebble Beach"}, "", "", "", nil)
+	if len(plain.InlineKeyboard) != len(keyboard.InlineKeyboard)-1 {
+		t.Errorf("Keyboard without tee-time link has %d rows, want %d", len(plain.InlineKeyboard), len(keyboard.InlineKeyboard)-1)
+	}
+	if CourseKeyboard(nil) != nil || CourseKeyboard(&CourseDetails{}) != nil {
+		t.Error("CourseKeyboard() should be nil without a tee-time link")
+	}
+	if kb := CourseKeyboard(course); kb == nil || len(kb.InlineKeyboard) != 1 {
+		t.Errorf("CourseKeyboard() = %+v, want single tee-time row", kb)
+	}
+}
+
+func TestAddTeeTimeDetails(t *testing.T) {
+	evt := &event.Event{State: "CA", Title: "Pebble Beach", City: "Pebble Beach", DateText: "Apr 4 2026"}
+	client := teetime.NewClient(teetime.Config{SearchURL: "https://tee.example.com/search?q={course}&date={date}"})
+
+	if details, err := AddTeeTimeDetails(context.Background(), nil, evt, nil); details != nil || err != nil {
+		t.Errorf("without a client = %+v, %v; want nothing added", details, err)
+	}
+
+	details, err := AddTeeTimeDetails(context.Background(), client, evt, nil)
+	if err != nil {
+		t.Fatalf("AddTeeTimeDetails() error = %v", err)
+	}
+	if details == nil || details.Name != "Pebble Beach" || details.TeeTimeURL != "https://tee.example.com/search?q=Pebble+Beach&date=2026-04-04" {
+		t.Errorf("details = %+v, want minimal details with the booking link", details)
+	}
+
+	// Found course details keep their name for the search
+	details, _ = AddTeeTimeDetails(context.Background(), client, evt, &CourseDetails{Name: "Pebble Beach Golf Links"})
+	if !strings.Contains(details.TeeTimeURL, "q=Pebble+Beach+Golf+Links") {
+		t.Errorf("TeeTimeURL = %q, want the course's name", details.TeeTimeURL)
+	}
+}
+
+func TestFormatNewEventVariant(t *testing.T) {
+	evt := &event.Event{ID: "abc123", State: "NV", Title: "Wolf Creek", DateText: "Apr 12 2026", City: "Mesquite", ShortCode: "NV-417"}
+
+	control, controlKeyboard := FormatNewEventVariant(VariantControl, evt, nil)
+	compact, compactKeyboard := FormatNewEventVariant(VariantCompact, evt, nil)
+	if !strings.Contains(control, "New VGA Golf Event!") || strings.Contains(compact, "New VGA Golf Event!") {
+		t.Errorf("control should have the full header and compact shouldn't:\n%s\n---\n%s", control, compact)
+	}
+	for _, want := range []string{"Wolf Creek", "(NV)", "Mesquite", "NV-417", "vgagolf.org/state-events"} {
+		if !strings.Contains(compact, want) {
+			t.Errorf("compact card missing %q:\n%s", want, compact)
+		}
+	}
+	if strings.Count(compact, "\n") > 3 {
+		t.Errorf("compact card has %d lines, want at most 4:\n%s", strings.Count(compact, "\n")+1, compact)
+	}
+	if len(controlKeyboard.InlineKeyboard) != len(compactKeyboard.InlineKeyboard) {
+		t.Error("both variants should have the same buttons")
+	}
+}
+
+func TestTagKeyboard(t *testing.T) {
+	evt := &event.Event{ID: strings.Repeat("f", 40), State: "NV", Title: "Wolf Creek"} // Full-length SHA-1 ID
+	_, keyboard := FormatEventWithStatusAndCourse(evt, &CourseDetails{TeeTimeURL: "https://tee.example.com"}, "", "", "", nil)
+	TagKeyboard(keyboard, "b")
+
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.URL != "" || strings.HasPrefix(button.CallbackData, "discuss:") {
+				continue
+			}
+			if !strings.HasSuffix(button.CallbackData, ":b") {
+				t.Errorf("button %q callback %q isn't tagged", button.Text, button.CallbackData)
+			}
+			if len(button.CallbackData) > 64 {
+				t.Errorf("callback %q is over Telegram's 64-byte limit", button.CallbackData)
+			}
+		}
+	}
+}
+
+func TestAddHints(t *testing.T) {
+	text := "🏌️ <b>Wolf Creek</b>\n📅 Apr 4\n\n🔗 https://vgagolf.org"
+	got := AddHints(text, []string{"🚗 210 miles from home"})
+	if want := "📅 Apr 4\n\n🚗 210 miles from home\n\n🔗 https://vgagolf.org"; !strings.Contains(got, want) {
+		t.Errorf("AddHints() = %q, want hints before the link", got)
+	}
+	if AddHints(text, nil) != text {
+		t.Error("no hints should leave the card unchanged")
+	}
+}
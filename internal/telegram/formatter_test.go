@@ -106,6 +106,22 @@ func TestFormatEvent(t *testing.T) {
 	}
 }
 
+func TestFormatOverflowNotice(t *testing.T) {
+	since := time.Unix(1760000000, 0)
+	msg, keyboard := FormatOverflowNotice(32, since)
+
+	if !strings.Contains(msg, "and 32 more new events") {
+		t.Errorf("message = %q, want remaining count", msg)
+	}
+	if keyboard == nil || keyboard.InlineKeyboard[0][0].CallbackData != "new:1760000000:0" {
+		t.Errorf("keyboard = %+v, want View all button for the run start", keyboard)
+	}
+
+	if msg, _ := FormatOverflowNotice(1, since); !strings.Contains(msg, "1 more new event<") {
+		t.Errorf("singular message = %q", msg)
+	}
+}
+
 func TestFormatSummary(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -285,9 +301,15 @@ func TestFormatEventWithStatus(t *testing.T) {
 				t.Errorf("Expected 3 keyboard rows, got %d", len(keyboard.InlineKeyboard))
 			}
 
-			// First row: Calendar button
-			if len(keyboard.InlineKeyboard[0]) != 1 {
-				t.Errorf("Expected 1 button in first row (calendar), got %d", len(keyboard.InlineKeyboard[0]))
+			// First row: Calendar, Discuss, and Refresh buttons
+			if len(keyboard.InlineKeyboard[0]) != 3 {
+				t.Fatalf("Expected 3 buttons in first row (calendar, discuss, refresh), got %d", len(keyboard.InlineKeyboard[0]))
+			}
+			if discuss := keyboard.InlineKeyboard[0][1]; discuss.CallbackData != "discuss:test456" {
+				t.Errorf("Discuss button callback = %q, want 'discuss:test456'", discuss.CallbackData)
+			}
+			if refresh := keyboard.InlineKeyboard[0][2]; refresh.CallbackData != "refresh:test456" {
+				t.Errorf("Refresh button callback = %q, want 'refresh:test456'", refresh.CallbackData)
 			}
 			calendarButton := keyboard.InlineKeyboard[0][0]
 			if calendarButton.Text != "📅 Calendar" {
@@ -452,10 +474,10 @@ func TestFormatReminder(t *testing.T) {
 				t.Errorf("Keyboard has %d rows, want 3", len(keyboard.InlineKeyboard))
 			}
 
-			// First row: Calendar button
+			// First row: Calendar and Discuss buttons
 			if len(keyboard.InlineKeyboard) > 0 {
-				if len(keyboard.InlineKeyboard[0]) != 1 {
-					t.Errorf("First row has %d buttons, want 1", len(keyboard.InlineKeyboard[0]))
+				if len(keyboard.InlineKeyboard[0]) != 2 {
+					t.Errorf("First row has %d buttons, want 2", len(keyboard.InlineKeyboard[0]))
 				}
 				calButton := keyboard.InlineKeyboard[0][0]
 				if !strings.Contains(calButton.Text, "Calendar") {
@@ -480,6 +502,66 @@ func TestFormatReminder(t *testing.T) {
 	}
 }
 
+func TestFormatDeadlineReminder(t *testing.T) {
+	deadline := time.Now().AddDate(0, 0, 2).Format("Jan 2 2006")
+	evt := &event.Event{
+		ID:                   "test123",
+		State:                "NV",
+		Title:                "Chimera Golf Club",
+		DateText:             "Apr 4 2030",
+		RegistrationDeadline: deadline,
+	}
+
+	msg, keyboard := FormatDeadlineReminder(evt, 2)
+	for _, want := range []string{"Registration Closing Soon", "in <b>2 days</b>", "⏳ Register by", "Chimera Golf Club", "#Deadline"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("FormatDeadlineReminder() missing %q:\n%s", want, msg)
+		}
+	}
+	if got := keyboard.InlineKeyboard[0][0].CallbackData; got != "status:test123:registered" {
+		t.Errorf("first button = %q, want the registered status", got)
+	}
+
+	// New-event cards show the deadline too
+	if card := FormatEvent(evt); !strings.Contains(card, "⏳ Register by") {
+		t.Errorf("FormatEvent() should show the deadline:\n%s", card)
+	}
+	evt.RegistrationDeadline = ""
+	if card := FormatEvent(evt); strings.Contains(card, "⏳") {
+		t.Errorf("FormatEvent() without a deadline shouldn't show one:\n%s", card)
+	}
+}
+
+func TestFormatAlmostFull(t *testing.T) {
+	evt := &event.Event{
+		ID:          "test123",
+		State:       "NV",
+		Title:       "Chimera Golf Club",
+		DateText:    "Apr 4 2030",
+		FieldFilled: 68,
+		FieldSize:   72,
+	}
+
+	msg, keyboard := FormatAlmostFull(evt)
+	for _, want := range []string{"Almost Full", "<b>4 spot(s)</b> left", "👥 Field: 68 / 72 spots", "Chimera Golf Club", "#AlmostFull"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("FormatAlmostFull() missing %q:\n%s", want, msg)
+		}
+	}
+	if got := keyboard.InlineKeyboard[0][0].CallbackData; got != "status:test123:registered" {
+		t.Errorf("first button = %q, want the registered status", got)
+	}
+
+	// New-event cards show the field too
+	if card := FormatEvent(evt); !strings.Contains(card, "👥 Field: 68 / 72 spots") {
+		t.Errorf("FormatEvent() should show the field:\n%s", card)
+	}
+	evt.FieldFilled, evt.FieldSize = 0, 0
+	if card := FormatEvent(evt); strings.Contains(card, "👥") {
+		t.Errorf("FormatEvent() without a field size shouldn't show one:\n%s", card)
+	}
+}
+
 func TestFormatRemovedEvent(t *testing.T) {
 	evt := &event.Event{
 		ID:       "test-removed-1",
@@ -713,6 +795,31 @@ func TestCourseDetails(t *testing.T) {
 	}
 }
 
+func TestFormatEventWithCourse_ContactLinks(t *testing.T) {
+	evt := &event.Event{
+		ID:       "test123",
+		State:    "CA",
+		Title:    "Pebble Beach Golf Links",
+		DateText: "Apr 4 2026",
+		City:     "Pebble Beach",
+	}
+	course := &CourseDetails{
+		Name:    "Pebble Beach Golf Links",
+		Website: "https://www.pebblebeach.com",
+		Phone:   "831-622-8723",
+		Tees:    []TeeDetails{{Name: "Blue", Par: 72, Yardage: 6737}},
+	}
+
+	msg := FormatEventWithCourse(evt, course, "")
+
+	if !strings.Contains(msg, `<a href="https://www.pebblebeach.com">pebblebeach.com</a>`) {
+		t.Errorf("Message should contain clickable website link, got:\n%s", msg)
+	}
+	if !strings.Contains(msg, "📞 831-622-8723") {
+		t.Errorf("Message should contain phone line, got:\n%s", msg)
+	}
+}
+
 func TestFormatEvent_AlsoIn(t *testing.T) {
 	evt := &event.Event{
 		ID:       "test123",
@@ -756,6 +863,13 @@ func TestFormatEventChange(t *testing.T) {
 	if !strings.Contains(msg, "New Title") {
 		t.Error("Message should contain new value")
 	}
+
+	msg = FormatEventChange(evt, "renamed", "Wolf Creek", "Wolf Creek Golf Club")
+	for _, want := range []string{"Event Renamed", "<s>Wolf Creek</s>", "Wolf Creek Golf Club", "carry over"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("renamed message should contain %q:\n%s", want, msg)
+		}
+	}
 }
 
 func TestFormatEventChangeWithKeyboard(t *testing.T) {
@@ -933,6 +1047,47 @@ func TestFormatYardage(t *testing.T) {
 	}
 }
 
+func TestFormatRestoredEvent(t *testing.T) {
+	evt := &event.Event{
+		ID:       "test-restored",
+		State:    "NV",
+		Title:    "Shadow Creek",
+		DateText: "Apr 4 2026",
+		City:     "Las Vegas",
+	}
+
+	msg := FormatRestoredEvent(evt, "")
+	for _, want := range []string{"✅ <b>Event Restored</b>", "Shadow Creek", "Las Vegas", "back on the VGA website", "#EventRestored #NV"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in message:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "still marked registered") {
+		t.Error("did not expect registration reminder without a status")
+	}
+
+	if msg := FormatRestoredEvent(evt, preferences.EventStatusRegistered); !strings.Contains(msg, "still marked registered") {
+		t.Errorf("expected registration reminder for registered users:\n%s", msg)
+	}
+}
+
+func TestFormatCancelledCard(t *testing.T) {
+	evt := &event.Event{ID: "test-removed-3", State: "NV", Title: "Wolf Creek", DateText: "May 15 2026", City: "Mesquite"}
+	msg := FormatCancelledCard(evt, "registered", "Carpool with Sam", "", nil)
+
+	if !strings.HasPrefix(msg, "❌ <b>CANCELLED</b>") {
+		t.Errorf("expected the CANCELLED header first, got %q", msg)
+	}
+	if !strings.Contains(msg, "<s>Wolf Creek</s>") {
+		t.Error("expected the title struck through")
+	}
+	for _, want := range []string{"Mesquite", "Registered", "Carpool with Sam"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected the rest of the card (%q) kept", want)
+		}
+	}
+}
+
 func TestFormatRemovedEventGeneral(t *testing.T) {
 	evt := &event.Event{
 		ID:       "test-removed-2",
@@ -1021,3 +1176,64 @@ func TestFormatRemovedEventGeneral(t *testing.T) {
 		}
 	})
 }
+
+func TestFormatNewState(t *testing.T) {
+	msg := FormatNewState("MT", []*event.Event{
+		{ID: "a", State: "MT", Title: "Whitefish Lake", DateText: "Jun 6 2030"},
+		{ID: "b", State: "NV", Title: "Shadow Creek"},
+	})
+	for _, want := range []string{"First VGA event listed in Montana!", "🏌️ Whitefish Lake - ", "/subscribe MT", "#NewState #MT"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("FormatNewState() missing %q:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "Shadow Creek") {
+		t.Errorf("FormatNewState() should only list the state's events:\n%s", msg)
+	}
+}
+
+func TestFormatDiffSummary(t *testing.T) {
+	msg := FormatDiffSummary(map[string]event.DiffCounts{
+		"CA": {New: 1},
+		"NV": {New: 2, Removed: 1},
+		"":   {Changed: 1},
+	})
+
+	for _, want := range []string{
+		"🆕 <b>3</b> new · ✏️ <b>1</b> changed · ❌ <b>1</b> removed",
+		"📍 <b>Nevada</b> — 2 new, 1 removed\n📍 <b>California</b> — 1 new\n",
+		"#VGAGolf",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("FormatDiffSummary() missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestFormatEventWithStatusAndNoteFriends(t *testing.T) {
+	evt := &event.Event{ID: "evt1", State: "NV", Title: "Wolf Creek", DateText: "Apr 4 2026", City: "Mesquite"}
+	prefs := preferences.NewPreferences()
+	viewer := prefs.GetUser("111")
+	viewer.AddFriend("222")
+	viewer.AddFriend("333")
+	viewer.SetDefaultSharing(preferences.ShareInterested)
+	for _, id := range []string{"222", "333"} {
+		prefs.GetUser(id).SetSharing("111", preferences.ShareInterested)
+	}
+	prefs.GetUser("222").SetEventStatus("evt1", preferences.EventStatusRegistered)
+	prefs.GetUser("333").SetEventStatus("evt1", preferences.EventStatusInterested)
+
+	if msg, _ := FormatEventWithStatusAndNote(evt, "", "", "111", prefs); !strings.Contains(msg, "Your friends: <b>1</b> registered, <b>1</b> interested") {
+		t.Errorf("event card should count friends by status, got %q", msg)
+	}
+
+	prefs.GetUser("333").SetSharing("111", preferences.ShareRegistered)
+	if got := friendsLine(prefs, "111", "evt1"); !strings.Contains(got, "Your friend: <b>1</b> registered") || strings.Contains(got, "interested") {
+		t.Errorf("friendsLine() with registered-only sharing = %q", got)
+	}
+
+	prefs.GetUser("222").SetSharing("111", preferences.ShareNothing)
+	if got := friendsLine(prefs, "111", "evt1"); got != "" {
+		t.Errorf("friendsLine() with nothing shared = %q, want empty", got)
+	}
+}
@@ -1,13 +1,27 @@
 package telegram
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
 )
 
+// useFastRetries shortens the retry backoff for the duration of a test
+func useFastRetries(t *testing.T) {
+	t.Helper()
+	original := retryBackoff
+	retryBackoff = errs.Backoff{Attempts: 3, BaseDelay: time.Millisecond}
+	t.Cleanup(func() { retryBackoff = original })
+}
+
 // TestSendMessage_Success tests successful message sending
 func TestSendMessage_Success(t *testing.T) {
 	// Create a test server that mimics Telegram API
@@ -47,7 +61,7 @@ func TestSendMessage_Success(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.SendMessage("Test message")
+	err := client.SendMessage(context.Background(), "Test message")
 	if err != nil {
 		t.Errorf("SendMessage() unexpected error: %v", err)
 	}
@@ -76,7 +90,7 @@ func TestSendMessage_APIError(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.SendMessage("Test message")
+	err := client.SendMessage(context.Background(), "Test message")
 	if err == nil {
 		t.Error("SendMessage() expected error for API failure, got nil")
 	}
@@ -97,6 +111,7 @@ func TestSendMessage_HTTPError(t *testing.T) {
 	originalURL := apiBaseURL
 	apiBaseURL = server.URL + "/"
 	defer func() { apiBaseURL = originalURL }()
+	useFastRetries(t)
 
 	client := &Client{
 		botToken:   "test-token",
@@ -104,13 +119,16 @@ func TestSendMessage_HTTPError(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.SendMessage("Test message")
+	err := client.SendMessage(context.Background(), "Test message")
 	if err == nil {
 		t.Error("SendMessage() expected error for HTTP error, got nil")
 	}
 	if err != nil && !strings.Contains(err.Error(), "status 500") {
 		t.Errorf("SendMessage() error = %v, want error containing 'status 500'", err)
 	}
+	if !errors.Is(err, errs.ErrTransient) {
+		t.Errorf("SendMessage() error = %v, want errs.ErrTransient", err)
+	}
 }
 
 // TestSendMessageWithKeyboard_Success tests successful message with keyboard
@@ -146,10 +164,15 @@ func TestSendMessageWithKeyboard_Success(t *testing.T) {
 		},
 	}
 
-	err := client.SendMessageWithKeyboard("Test message", keyboard)
+	err := client.SendMessageWithKeyboard(context.Background(), "Test message", keyboard)
 	if err != nil {
 		t.Errorf("SendMessageWithKeyboard() unexpected error: %v", err)
 	}
+
+	messageID, err := client.SendTrackedMessage(context.Background(), "Test message", keyboard)
+	if err != nil || messageID != 123 {
+		t.Errorf("SendTrackedMessage() = %d, %v; want 123", messageID, err)
+	}
 }
 
 // TestSendMessageWithKeyboard_APIError tests keyboard message API error
@@ -180,7 +203,7 @@ func TestSendMessageWithKeyboard_APIError(t *testing.T) {
 		},
 	}
 
-	err := client.SendMessageWithKeyboard("Test", keyboard)
+	err := client.SendMessageWithKeyboard(context.Background(), "Test", keyboard)
 	if err == nil {
 		t.Error("SendMessageWithKeyboard() expected error, got nil")
 	}
@@ -208,7 +231,7 @@ func TestAnswerCallbackQuery_Success(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.AnswerCallbackQuery("callback123", "Success!", false)
+	err := client.AnswerCallbackQuery(context.Background(), "callback123", "Success!", false)
 	if err != nil {
 		t.Errorf("AnswerCallbackQuery() unexpected error: %v", err)
 	}
@@ -238,7 +261,7 @@ func testAnswerCallbackQueryError(t *testing.T, callbackID, errorDesc string) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.AnswerCallbackQuery(callbackID, "text", false)
+	err := client.AnswerCallbackQuery(context.Background(), callbackID, "text", false)
 	if err == nil {
 		t.Error("AnswerCallbackQuery() expected error, got nil")
 	}
@@ -278,7 +301,7 @@ func TestEditMessageText_Success(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.EditMessageText("12345", 123, "Updated text", nil)
+	err := client.EditMessageText(context.Background(), "12345", 123, "Updated text", nil)
 	if err != nil {
 		t.Errorf("EditMessageText() unexpected error: %v", err)
 	}
@@ -292,7 +315,7 @@ func TestEditMessageText_EmptyChatID(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.EditMessageText("", 123, "text", nil)
+	err := client.EditMessageText(context.Background(), "", 123, "text", nil)
 	if err == nil {
 		t.Error("EditMessageText() expected error for empty chat_id, got nil")
 	}
@@ -306,7 +329,7 @@ func TestEditMessageText_ZeroMessageID(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.EditMessageText("12345", 0, "text", nil)
+	err := client.EditMessageText(context.Background(), "12345", 0, "text", nil)
 	if err == nil {
 		t.Error("EditMessageText() expected error for message_id = 0, got nil")
 	}
@@ -320,7 +343,7 @@ func TestEditMessageText_EmptyText(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.EditMessageText("12345", 123, "", nil)
+	err := client.EditMessageText(context.Background(), "12345", 123, "", nil)
 	if err == nil {
 		t.Error("EditMessageText() expected error for empty text, got nil")
 	}
@@ -356,7 +379,7 @@ func TestEditMessageText_WithKeyboard(t *testing.T) {
 		},
 	}
 
-	err := client.EditMessageText("12345", 123, "Updated", keyboard)
+	err := client.EditMessageText(context.Background(), "12345", 123, "Updated", keyboard)
 	if err != nil {
 		t.Errorf("EditMessageText() with keyboard unexpected error: %v", err)
 	}
@@ -386,7 +409,7 @@ func TestEditMessageText_APIError(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.EditMessageText("12345", 999, "text", nil)
+	err := client.EditMessageText(context.Background(), "12345", 999, "text", nil)
 	if err == nil {
 		t.Error("EditMessageText() expected error, got nil")
 	}
@@ -425,12 +448,35 @@ func TestSendDocument_Success(t *testing.T) {
 	}
 
 	data := []byte("test file content")
-	err := client.SendDocument("test.txt", data, "Test document")
+	err := client.SendDocument(context.Background(), "test.txt", data, "Test document")
 	if err != nil {
 		t.Errorf("SendDocument() unexpected error: %v", err)
 	}
 }
 
+func TestUploadDocument_FileID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":123,"document":{"file_id":"doc123"}}}`))
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client := &Client{
+		botToken:   "test-token",
+		chatID:     "12345",
+		httpClient: &http.Client{},
+	}
+
+	fileID, err := client.UploadDocument(context.Background(), "event.ics", []byte("BEGIN:VCALENDAR"), "")
+	if err != nil || fileID != "doc123" {
+		t.Errorf("UploadDocument() = %q, %v, want doc123", fileID, err)
+	}
+}
+
 // TestSendDocument_EmptyData tests validation
 func TestSendDocument_EmptyData(t *testing.T) {
 	client := &Client{
@@ -439,7 +485,7 @@ func TestSendDocument_EmptyData(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	err := client.SendDocument("test.txt", nil, "caption")
+	err := client.SendDocument(context.Background(), "test.txt", nil, "caption")
 	if err == nil {
 		t.Error("SendDocument() expected error for empty data, got nil")
 	}
@@ -454,7 +500,7 @@ func TestSendDocument_EmptyFilename(t *testing.T) {
 	}
 
 	data := []byte("content")
-	err := client.SendDocument("", data, "caption")
+	err := client.SendDocument(context.Background(), "", data, "caption")
 	if err == nil {
 		t.Error("SendDocument() expected error for empty filename, got nil")
 	}
@@ -483,8 +529,414 @@ func TestSendDocument_APIError(t *testing.T) {
 	}
 
 	data := []byte("content")
-	err := client.SendDocument("test.txt", data, "caption")
+	err := client.SendDocument(context.Background(), "test.txt", data, "caption")
 	if err == nil {
 		t.Error("SendDocument() expected error, got nil")
 	}
 }
+
+// TestSendPhoto_Success tests successful photo sending
+func TestSendPhoto_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendPhoto") {
+			t.Errorf("Expected sendPhoto endpoint, got %s", r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["photo"] != "https://images.example.com/course.jpg" {
+			t.Errorf("photo = %v, want course image URL", body["photo"])
+		}
+		if body["caption"] != "⛳ <b>Test Course</b>" {
+			t.Errorf("caption = %v, want course caption", body["caption"])
+		}
+
+		response := map[string]interface{}{
+			"ok":     true,
+			"result": map[string]interface{}{"message_id": 123},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client := &Client{
+		botToken:   "test-token",
+		chatID:     "12345",
+		httpClient: &http.Client{},
+	}
+
+	err := client.SendPhoto(context.Background(), "https://images.example.com/course.jpg", "⛳ <b>Test Course</b>")
+	if err != nil {
+		t.Errorf("SendPhoto() unexpected error: %v", err)
+	}
+}
+
+// TestSendPhoto_EmptyURL tests that an empty photo URL is rejected
+func TestSendPhoto_EmptyURL(t *testing.T) {
+	client := &Client{
+		botToken:   "test-token",
+		chatID:     "12345",
+		httpClient: &http.Client{},
+	}
+
+	if err := client.SendPhoto(context.Background(), "", "caption"); err == nil {
+		t.Error("SendPhoto() expected error for empty URL, got nil")
+	}
+}
+
+// TestSendPhoto_APIError tests API error handling for photos
+func TestSendPhoto_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{
+			"ok":          false,
+			"description": "Bad Request: wrong file identifier/HTTP URL specified",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client := &Client{
+		botToken:   "test-token",
+		chatID:     "12345",
+		httpClient: &http.Client{},
+	}
+
+	err := client.SendPhoto(context.Background(), "https://images.example.com/missing.jpg", "")
+	if err == nil {
+		t.Error("SendPhoto() expected error for API failure, got nil")
+	}
+	if err != nil && !strings.Contains(err.Error(), "Bad Request") {
+		t.Errorf("SendPhoto() error = %v, want error containing 'Bad Request'", err)
+	}
+}
+
+// TestSetMyCommands_Success tests command registration payload
+func TestSetMyCommands_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/setMyCommands") {
+			t.Errorf("Expected setMyCommands endpoint, got %s", r.URL.Path)
+		}
+
+		var body struct {
+			Commands     []BotCommand     `json:"commands"`
+			Scope        *BotCommandScope `json:"scope"`
+			LanguageCode string           `json:"language_code"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Commands) != 2 || body.Commands[0].Command != "events" {
+			t.Errorf("Unexpected commands: %+v", body.Commands)
+		}
+		if body.Scope == nil || body.Scope.Type != "chat" || body.Scope.ChatID != "12345" {
+			t.Errorf("Unexpected scope: %+v", body.Scope)
+		}
+		if body.LanguageCode != "es" {
+			t.Errorf("language_code = %q, want es", body.LanguageCode)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": true})
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client, err := NewBotClient("test-token")
+	if err != nil {
+		t.Fatalf("NewBotClient() error: %v", err)
+	}
+
+	commands := []BotCommand{
+		{Command: "events", Description: "View events"},
+		{Command: "help", Description: "Show help"},
+	}
+	if err := client.SetMyCommands(context.Background(), commands, &BotCommandScope{Type: "chat", ChatID: "12345"}, "es"); err != nil {
+		t.Errorf("SetMyCommands() unexpected error: %v", err)
+	}
+}
+
+// TestSetMyCommands_APIError tests API error handling for command registration
+func TestSetMyCommands_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":          false,
+			"description": "Bad Request: command is invalid",
+		})
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client, _ := NewBotClient("test-token")
+	err := client.SetMyCommands(context.Background(), []BotCommand{{Command: "Bad-Name", Description: "x"}}, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "command is invalid") {
+		t.Errorf("SetMyCommands() error = %v, want API error", err)
+	}
+
+	tooMany := make([]BotCommand, MaxBotCommands+1)
+	if err := client.SetMyCommands(context.Background(), tooMany, nil, ""); err == nil {
+		t.Error("SetMyCommands() expected error for too many commands")
+	}
+}
+
+// TestGetMyCommands_Success tests reading the registered command list
+func TestGetMyCommands_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/getMyCommands") {
+			t.Errorf("Expected getMyCommands endpoint, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok": true,
+			"result": []map[string]string{
+				{"command": "events", "description": "View events"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client, _ := NewBotClient("test-token")
+	commands, err := client.GetMyCommands(context.Background(), nil, "")
+	if err != nil {
+		t.Fatalf("GetMyCommands() unexpected error: %v", err)
+	}
+	if len(commands) != 1 || commands[0].Command != "events" {
+		t.Errorf("GetMyCommands() = %+v, want events command", commands)
+	}
+}
+
+// TestGetMe tests reading the bot's account and classifying a rejected token
+func TestGetMe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/test-token/getMe" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error_code": 401, "description": "Unauthorized"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":     true,
+			"result": map[string]interface{}{"id": 42, "is_bot": true, "first_name": "VGA Events", "username": "vga_events_bot"},
+		})
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client, _ := NewBotClient("test-token")
+	me, err := client.GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("GetMe() unexpected error: %v", err)
+	}
+	if me.ID != 42 || me.Username != "vga_events_bot" {
+		t.Errorf("GetMe() = %+v, want vga_events_bot", me)
+	}
+
+	client, _ = NewBotClient("revoked-token")
+	if _, err := client.GetMe(context.Background()); !errors.Is(err, errs.ErrAuth) {
+		t.Errorf("GetMe() with a revoked token error = %v, want ErrAuth", err)
+	}
+}
+
+// TestSendMessage_RetriesTransientErrors tests that 5xx and 429 responses are retried
+func TestSendMessage_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok":          false,
+				"error_code":  429,
+				"description": "Too Many Requests: retry after 0",
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		}
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+	useFastRetries(t)
+
+	client := &Client{botToken: "test-token", chatID: "12345", httpClient: &http.Client{}}
+	if err := client.SendMessage(context.Background(), "Test message"); err != nil {
+		t.Fatalf("SendMessage() unexpected error: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server called %d times, want 3", got)
+	}
+}
+
+// TestSendMessage_ClassifiesErrors tests that permanent failures are classified and not retried
+func TestSendMessage_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		description string
+		want        error
+	}{
+		{"chat not found", http.StatusBadRequest, "Bad Request: chat not found", errs.ErrNotFound},
+		{"blocked by user", http.StatusForbidden, "Forbidden: bot was blocked by the user", errs.ErrAuth},
+		{"invalid token", http.StatusUnauthorized, "Unauthorized", errs.ErrAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"ok":          false,
+					"error_code":  tt.status,
+					"description": tt.description,
+				})
+			}))
+			defer server.Close()
+
+			originalURL := apiBaseURL
+			apiBaseURL = server.URL + "/"
+			defer func() { apiBaseURL = originalURL }()
+			useFastRetries(t)
+
+			client := &Client{botToken: "test-token", chatID: "12345", httpClient: &http.Client{}}
+			err := client.SendMessage(context.Background(), "Test message")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("SendMessage() error = %v, want %v", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.description) {
+				t.Errorf("SendMessage() error = %v, want description %q", err, tt.description)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("server called %d times, want 1", got)
+			}
+		})
+	}
+}
+
+// TestSendPoll tests sending a poll and reading back its IDs
+func TestSendPoll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendPoll") {
+			t.Errorf("unexpected method %s", r.URL.Path)
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		if payload["is_anonymous"] != false || len(payload["options"].([]interface{})) != 2 {
+			t.Errorf("unexpected payload %v", payload)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":     true,
+			"result": map[string]interface{}{"message_id": 42, "poll": map[string]interface{}{"id": "5012345"}},
+		})
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client := &Client{botToken: "test-token", chatID: "12345", httpClient: &http.Client{Timeout: 5 * time.Second}}
+	sent, err := client.SendPoll(context.Background(), "Which event?", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("SendPoll() error = %v", err)
+	}
+	if sent.MessageID != 42 || sent.PollID != "5012345" {
+		t.Errorf("SendPoll() = %+v", sent)
+	}
+
+	if _, err := client.SendPoll(context.Background(), "Which event?", []string{"A"}); err == nil {
+		t.Error("SendPoll() with one option should fail")
+	}
+}
+
+// TestSendDocumentID tests re-sending a file by its file_id
+func TestSendDocumentID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendDocument") {
+			t.Errorf("unexpected method %s", r.URL.Path)
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		if payload["document"] != "BQACAgIAAxk" || payload["caption"] != "📎 Pairings" {
+			t.Errorf("unexpected payload %v", payload)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": map[string]interface{}{}})
+	}))
+	defer server.Close()
+
+	originalURL := apiBaseURL
+	apiBaseURL = server.URL + "/"
+	defer func() { apiBaseURL = originalURL }()
+
+	client := &Client{botToken: "test-token", chatID: "12345", httpClient: &http.Client{Timeout: 5 * time.Second}}
+	if err := client.SendDocumentID(context.Background(), "BQACAgIAAxk", "📎 Pairings"); err != nil {
+		t.Fatalf("SendDocumentID() error = %v", err)
+	}
+	if err := client.SendDocumentID(context.Background(), "", ""); err == nil {
+		t.Error("SendDocumentID() without a file ID should fail")
+	}
+}
+
+// TestDownloadFile tests looking up a file's path and downloading it
+func TestDownloadFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/test-token/getFile":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok":     true,
+				"result": map[string]interface{}{"file_id": "AwACAgIAAxk", "file_path": "voice/file_7.oga"},
+			})
+		case "/files/test-token/voice/file_7.oga":
+			_, _ = w.Write([]byte("OggS..."))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	originalURL, originalFileURL := apiBaseURL, fileBaseURL
+	apiBaseURL, fileBaseURL = server.URL+"/", server.URL+"/files/"
+	defer func() { apiBaseURL, fileBaseURL = originalURL, originalFileURL }()
+
+	client := &Client{botToken: "test-token", chatID: "12345", httpClient: &http.Client{Timeout: 5 * time.Second}}
+	data, err := client.DownloadFile(context.Background(), "AwACAgIAAxk")
+	if err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+	if string(data) != "OggS..." {
+		t.Errorf("DownloadFile() = %q", data)
+	}
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Poll limits from the Bot API
+const (
+	MaxPollOptions      = 10
+	MaxPollOptionLength = 100
+	MaxPollQuestion     = 300
+)
+
+// PollAnswer is a user's vote in a non-anonymous poll. OptionIDs is empty when the
+// user retracts their vote.
+type PollAnswer struct {
+	PollID    string `json:"poll_id"`
+	User      User   `json:"user"`
+	OptionIDs []int  `json:"option_ids"`
+}
+
+// SentPoll identifies a poll sent with SendPoll
+type SentPoll struct {
+	MessageID int
+	PollID    string
+}
+
+// SendPoll sends a non-anonymous, single-choice poll to the configured chat. Votes
+// arrive as poll_answer updates carrying the returned PollID.
+func (c *Client) SendPoll(ctx context.Context, question string, options []string) (*SentPoll, error) {
+	if question == "" {
+		return nil, fmt.Errorf("poll question is required")
+	}
+	if len(options) < 2 || len(options) > MaxPollOptions {
+		return nil, fmt.Errorf("poll needs 2-%d options, got %d", MaxPollOptions, len(options))
+	}
+
+	pollOptions := make([]map[string]string, len(options))
+	for i, option := range options {
+		pollOptions[i] = map[string]string{"text": option}
+	}
+
+	payload := map[string]interface{}{
+		"chat_id":      c.chatID,
+		"question":     question,
+		"options":      pollOptions,
+		"is_anonymous": false,
+	}
+
+	raw, err := c.callMethod(ctx, "sendPoll", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg struct {
+		MessageID int `json:"message_id"`
+		Poll      struct {
+			ID string `json:"id"`
+		} `json:"poll"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, fmt.Errorf("parsing sent poll: %w", err)
+	}
+	return &SentPoll{MessageID: msg.MessageID, PollID: msg.Poll.ID}, nil
+}
+
+// StopPoll closes a poll the bot sent to the configured chat so no more votes can be cast
+func (c *Client) StopPoll(ctx context.Context, messageID int) error {
+	payload := map[string]interface{}{
+		"chat_id":    c.chatID,
+		"message_id": messageID,
+	}
+
+	_, err := c.callMethod(ctx, "stopPoll", payload)
+	return err
+}
@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+// maxRunReportLines caps the warnings and failures listed in a run report
+const maxRunReportLines = 3
+
+// FormatRunReport formats a run's operational summary for the maintainer: anomalies
+// first, then what the scrape parsed, its warnings, API errors, sends, and runtime
+func FormatRunReport(s *storage.RunSummary) string {
+	var msg strings.Builder
+	if len(s.Anomalies) > 0 {
+		msg.WriteString(fmt.Sprintf("⚠️ <b>Run %s: %d anomal%s</b>\n", html.EscapeString(s.RunID), len(s.Anomalies), pluralY(len(s.Anomalies))))
+		for _, anomaly := range s.Anomalies {
+			msg.WriteString("🚨 " + html.EscapeString(anomaly) + "\n")
+		}
+	} else {
+		msg.WriteString(fmt.Sprintf("✅ <b>Run %s</b>\n", html.EscapeString(s.RunID)))
+	}
+	msg.WriteString("\n")
+
+	if scrape := s.Scrape; scrape != nil {
+		msg.WriteString(fmt.Sprintf("📥 %d events parsed in %.1fs\n", scrape.Events, scrape.Seconds))
+		msg.WriteString(fmt.Sprintf("🆕 %d new · ✏️ %d changed · ❌ %d removed · ♻️ %d restored\n", scrape.New, scrape.Changed, scrape.Removed, scrape.Restored))
+		if scrape.ParseWarnings > 0 {
+			msg.WriteString(fmt.Sprintf("⚠️ %d parse warning%s\n", scrape.ParseWarnings, pluralize(scrape.ParseWarnings)))
+			for i, warning := range scrape.Warnings {
+				if i == maxRunReportLines {
+					break
+				}
+				msg.WriteString("  • " + html.EscapeString(warning) + "\n")
+			}
+		}
+	}
+
+	if len(s.APIErrors) == 0 {
+		msg.WriteString("🔌 No API errors\n")
+	} else {
+		apis := make([]string, 0, len(s.APIErrors))
+		for api := range s.APIErrors {
+			apis = append(apis, api)
+		}
+		sort.Strings(apis)
+		parts := make([]string, len(apis))
+		for i, api := range apis {
+			parts[i] = fmt.Sprintf("%s %d", html.EscapeString(api), s.APIErrors[api])
+		}
+		msg.WriteString("🔌 API errors: " + strings.Join(parts, ", ") + "\n")
+	}
+
+	msg.WriteString(fmt.Sprintf("📬 %d sent · %d failed · %d unreachable\n", s.Sent, s.Failed, s.Unreachable))
+	for i, d := range s.Failures {
+		if i == maxRunReportLines {
+			msg.WriteString(fmt.Sprintf("  … and %d more\n", len(s.Failures)-maxRunReportLines))
+			break
+		}
+		line := d.Type
+		if d.EventID != "" {
+			line += " " + d.EventID
+		}
+		if d.Error != "" {
+			line += ": " + d.Error
+		}
+		msg.WriteString("  • " + html.EscapeString(line) + "\n")
+	}
+
+	if s.Runtime > 0 {
+		msg.WriteString(fmt.Sprintf("⏱️ Runtime %s\n", s.Runtime.Round(time.Second)))
+	}
+	return strings.TrimSuffix(msg.String(), "\n")
+}
+
+// pluralY returns the ending for "anomaly"/"anomalies"
+func pluralY(count int) string {
+	if count == 1 {
+		return "y"
+	}
+	return "ies"
+}
@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func TestFormatRunReport(t *testing.T) {
+	s := &storage.RunSummary{
+		RunID: "42",
+		Scrape: &storage.RunRecord{
+			Events: 0, New: 1, Removed: 30, Seconds: 2.34,
+			ParseWarnings: 5, Warnings: []string{"skipped page <a>", "w2", "w3", "w4"},
+		},
+		APIErrors:   map[string]int{"tee-time": 1, "golf-course": 2},
+		Sent:        10,
+		Failed:      1,
+		Unreachable: 4,
+		Failures: []*storage.Delivery{
+			{Type: "new", EventID: "e1", Error: "timeout"},
+			{Type: "digest"}, {Type: "new"}, {Type: "new"}, {Type: "new"},
+		},
+		Runtime:   4*time.Minute + 12*time.Second + 400*time.Millisecond,
+		Anomalies: []string{"Zero events parsed", "30 removals, 15x the usual 2.0"},
+	}
+
+	msg := FormatRunReport(s)
+	for _, want := range []string{
+		"⚠️ <b>Run 42: 2 anomalies</b>",
+		"🚨 Zero events parsed",
+		"📥 0 events parsed in 2.3s",
+		"❌ 30 removed",
+		"⚠️ 5 parse warnings",
+		"• skipped page &lt;a&gt;",
+		"🔌 API errors: golf-course 2, tee-time 1",
+		"📬 10 sent · 1 failed · 4 unreachable",
+		"• new e1: timeout",
+		"… and 2 more",
+		"⏱️ Runtime 4m12s",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("FormatRunReport() missing %q:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "w4") {
+		t.Errorf("FormatRunReport() should list at most %d warnings:\n%s", maxRunReportLines, msg)
+	}
+
+	quiet := FormatRunReport(&storage.RunSummary{RunID: "43", APIErrors: map[string]int{}})
+	if !strings.HasPrefix(quiet, "✅ <b>Run 43</b>") || !strings.Contains(quiet, "No API errors") {
+		t.Errorf("FormatRunReport() for a quiet run:\n%s", quiet)
+	}
+}
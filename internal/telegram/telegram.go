@@ -2,12 +2,16 @@ package telegram
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
 )
 
 const timeout = 10 * time.Second
@@ -27,6 +31,77 @@ type InlineKeyboardMarkup struct {
 	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
 }
 
+// maxErrorBody caps how much of an error response is read into the error message
+const maxErrorBody = 4096
+
+// retryBackoff is a package variable (not const) to allow test overriding
+var retryBackoff = errs.DefaultBackoff
+
+// apiResponse is the envelope every Bot API method responds with
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// decodeResponse checks the status of a Bot API response and decodes its JSON body
+// straight from the stream, returning the method's result
+func decodeResponse(resp *http.Response) (json.RawMessage, error) {
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		statusErr := errs.Status(resp.StatusCode, "telegram API error (status %d): %s", resp.StatusCode, string(body))
+		statusErr.RetryAfter = errs.ParseRetryAfter(resp.Header)
+
+		var result apiResponse
+		if json.Unmarshal(body, &result) == nil {
+			classifyAPIError(statusErr, &result)
+		}
+		return nil, statusErr
+	}
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	if !result.OK {
+		statusErr := errs.Status(result.ErrorCode, "telegram API error: %s", result.Description)
+		classifyAPIError(statusErr, &result)
+		return nil, statusErr
+	}
+
+	return result.Result, nil
+}
+
+// classifyAPIError fills in what the response body says about a failure: how long to
+// back off, and whether a 400 really means the chat or message doesn't exist
+func classifyAPIError(statusErr *errs.StatusError, result *apiResponse) {
+	if result.Parameters.RetryAfter > 0 {
+		statusErr.RetryAfter = time.Duration(result.Parameters.RetryAfter) * time.Second
+	}
+	if statusErr.StatusCode == http.StatusBadRequest && strings.Contains(result.Description, "not found") {
+		statusErr.Class = errs.ErrNotFound
+	}
+}
+
+// Pause waits d between messages to stay under Telegram's rate limits.
+// It returns ctx's error early if ctx is canceled while waiting.
+func Pause(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Client represents a Telegram Bot API client
 type Client struct {
 	botToken   string
@@ -52,14 +127,39 @@ func NewClient(botToken, chatID string) (*Client, error) {
 	}, nil
 }
 
+// post calls a Bot API method with the given request body and returns the method's
+// result. Rate limits and temporary failures are retried with backoff; other errors are
+// returned right away.
+func (c *Client) post(ctx context.Context, method, contentType string, body []byte) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s%s/%s", apiBaseURL, c.botToken, method)
+
+	var result json.RawMessage
+	err := retryBackoff.Retry(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("sending request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		result, err = decodeResponse(resp)
+		return err
+	})
+	return result, err
+}
+
 // SendMessage sends a text message to the configured chat
-func (c *Client) SendMessage(text string) error {
+func (c *Client) SendMessage(ctx context.Context, text string) error {
 	if text == "" {
 		return fmt.Errorf("message text is required")
 	}
 
-	url := fmt.Sprintf("%s%s/sendMessage", apiBaseURL, c.botToken)
-
 	payload := map[string]interface{}{
 		"chat_id":                  c.chatID,
 		"text":                     text,
@@ -67,58 +167,23 @@ func (c *Client) SendMessage(text string) error {
 		"disable_web_page_preview": true,
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshaling payload: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	// Parse response to check for errors
-	var result struct {
-		OK          bool   `json:"ok"`
-		Description string `json:"description"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if !result.OK {
-		return fmt.Errorf("telegram API error: %s", result.Description)
-	}
-
-	return nil
+	_, err := c.callMethod(ctx, "sendMessage", payload)
+	return err
 }
 
 // SendMessageWithKeyboard sends a text message with an inline keyboard to the configured chat
-func (c *Client) SendMessageWithKeyboard(text string, keyboard *InlineKeyboardMarkup) error {
+func (c *Client) SendMessageWithKeyboard(ctx context.Context, text string, keyboard *InlineKeyboardMarkup) error {
+	_, err := c.SendTrackedMessage(ctx, text, keyboard)
+	return err
+}
+
+// SendTrackedMessage sends a text message with an inline keyboard to the configured
+// chat and returns its message_id, for messages the bot edits later
+func (c *Client) SendTrackedMessage(ctx context.Context, text string, keyboard *InlineKeyboardMarkup) (int, error) {
 	if text == "" {
-		return fmt.Errorf("message text is required")
+		return 0, fmt.Errorf("message text is required")
 	}
 
-	url := fmt.Sprintf("%s%s/sendMessage", apiBaseURL, c.botToken)
-
 	payload := map[string]interface{}{
 		"chat_id":                  c.chatID,
 		"text":                     text,
@@ -130,123 +195,116 @@ func (c *Client) SendMessageWithKeyboard(text string, keyboard *InlineKeyboardMa
 		payload["reply_markup"] = keyboard
 	}
 
-	jsonData, err := json.Marshal(payload)
+	raw, err := c.callMethod(ctx, "sendMessage", payload)
 	if err != nil {
-		return fmt.Errorf("marshaling payload: %w", err)
+		return 0, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+	var msg struct {
+		MessageID int `json:"message_id"`
 	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return 0, fmt.Errorf("parsing sent message: %w", err)
+	}
+	return msg.MessageID, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
+// SendPhoto sends a photo (by URL, or the file_id of one Telegram already has) with an
+// optional caption to the configured chat
+func (c *Client) SendPhoto(ctx context.Context, photoURL, caption string) error {
+	if photoURL == "" {
+		return fmt.Errorf("photo URL is required")
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+	payload := map[string]interface{}{
+		"chat_id": c.chatID,
+		"photo":   photoURL,
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API error (status %d): %s", resp.StatusCode, string(body))
+	if caption != "" {
+		payload["caption"] = caption
+		payload["parse_mode"] = "HTML"
 	}
 
-	// Parse response to check for errors
-	var result struct {
-		OK          bool   `json:"ok"`
-		Description string `json:"description"`
+	_, err := c.callMethod(ctx, "sendPhoto", payload)
+	return err
+}
+
+// SendDocumentID re-sends a file Telegram already has, by its file_id, with an optional
+// caption to the configured chat
+func (c *Client) SendDocumentID(ctx context.Context, fileID, caption string) error {
+	if fileID == "" {
+		return fmt.Errorf("file ID is required")
 	}
 
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
+	payload := map[string]interface{}{
+		"chat_id":  c.chatID,
+		"document": fileID,
 	}
 
-	if !result.OK {
-		return fmt.Errorf("telegram API error: %s", result.Description)
+	if caption != "" {
+		payload["caption"] = caption
+		payload["parse_mode"] = "HTML"
 	}
 
-	return nil
+	_, err := c.callMethod(ctx, "sendDocument", payload)
+	return err
 }
 
 // SendDocument sends a file document to the configured chat
-func (c *Client) SendDocument(filename string, content []byte, caption string) error {
-	url := fmt.Sprintf("%s%s/sendDocument", apiBaseURL, c.botToken)
+func (c *Client) SendDocument(ctx context.Context, filename string, content []byte, caption string) error {
+	_, err := c.UploadDocument(ctx, filename, content, caption)
+	return err
+}
 
+// UploadDocument sends a file document to the configured chat like SendDocument and
+// returns the file_id Telegram gave it, so the same file can be sent again with
+// SendDocumentID without re-uploading
+func (c *Client) UploadDocument(ctx context.Context, filename string, content []byte, caption string) (string, error) {
 	// Create multipart form
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	// Add chat_id field
 	if err := writer.WriteField("chat_id", c.chatID); err != nil {
-		return fmt.Errorf("writing chat_id field: %w", err)
+		return "", fmt.Errorf("writing chat_id field: %w", err)
 	}
 
 	// Add caption if provided
 	if caption != "" {
 		if err := writer.WriteField("caption", caption); err != nil {
-			return fmt.Errorf("writing caption field: %w", err)
+			return "", fmt.Errorf("writing caption field: %w", err)
 		}
 		if err := writer.WriteField("parse_mode", "HTML"); err != nil {
-			return fmt.Errorf("writing parse_mode field: %w", err)
+			return "", fmt.Errorf("writing parse_mode field: %w", err)
 		}
 	}
 
 	// Add file
 	part, err := writer.CreateFormFile("document", filename)
 	if err != nil {
-		return fmt.Errorf("creating form file: %w", err)
+		return "", fmt.Errorf("creating form file: %w", err)
 	}
 
 	if _, err := part.Write(content); err != nil {
-		return fmt.Errorf("writing file content: %w", err)
+		return "", fmt.Errorf("writing file content: %w", err)
 	}
 
 	if err := writer.Close(); err != nil {
-		return fmt.Errorf("closing multipart writer: %w", err)
-	}
-
-	// Send POST request
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
+		return "", fmt.Errorf("closing multipart writer: %w", err)
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	raw, err := c.post(ctx, "sendDocument", writer.FormDataContentType(), body.Bytes())
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return "", err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API error (status %d): %s", resp.StatusCode, string(respBody))
+	var msg struct {
+		Document struct {
+			FileID string `json:"file_id"`
+		} `json:"document"`
 	}
-
-	// Parse response to check for errors
-	var result struct {
-		OK          bool   `json:"ok"`
-		Description string `json:"description"`
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return "", fmt.Errorf("parsing sendDocument result: %w", err)
 	}
-
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return fmt.Errorf("parsing response: %w", err)
-	}
-
-	if !result.OK {
-		return fmt.Errorf("telegram API error: %s", result.Description)
-	}
-
-	return nil
+	return msg.Document.FileID, nil
 }
@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+	"github.com/pfrederiksen/vga-events/internal/trends"
+)
+
+// FormatTrends formats a trends report for /trends and the monthly channel post:
+// new events by state, the fastest-filling events, the most-tracked courses, how far
+// ahead events are posted, and how many notifications and clicks there were.
+// Sections without data are left out.
+func FormatTrends(r *trends.Report) string {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("📈 <b>VGA Trends</b> · %s – %s\n\n", r.Since.Format("Jan 2"), r.Until.Format("Jan 2")))
+
+	if r.NewEvents == 0 {
+		msg.WriteString("🆕 No new events were posted.\n")
+	} else {
+		msg.WriteString(fmt.Sprintf("🆕 <b>%d</b> new event%s\n", r.NewEvents, pluralize(r.NewEvents)))
+		for _, s := range r.ByState {
+			msg.WriteString(fmt.Sprintf("📍 %s — %d\n", preferences.GetStateName(s.State), s.Events))
+		}
+	}
+
+	if len(r.Fastest) > 0 {
+		msg.WriteString("\n🔥 <b>Fastest-filling</b>\n")
+		for _, f := range r.Fastest {
+			msg.WriteString(fmt.Sprintf("• %s (%s) — +%d spot%s in %s", f.Event.Title, f.Event.State, f.Gained, pluralize(f.Gained), formatTrendDays(f.Days)))
+			if field := f.Event.FormatField(); field != "" {
+				msg.WriteString(", " + strings.TrimPrefix(field, "Field: "))
+			}
+			msg.WriteString("\n")
+		}
+	}
+
+	if len(r.MostTracked) > 0 {
+		msg.WriteString("\n⭐ <b>Most-tracked courses</b>\n")
+		for _, c := range r.MostTracked {
+			msg.WriteString(fmt.Sprintf("• %s — %d user%s", c.Course, c.Users, pluralize(c.Users)))
+			if c.Clicks > 0 {
+				msg.WriteString(fmt.Sprintf(" · %d click%s", c.Clicks, pluralize(c.Clicks)))
+			}
+			msg.WriteString("\n")
+		}
+	}
+
+	if r.LeadEvents > 0 {
+		msg.WriteString(fmt.Sprintf("\n⏱️ Events were posted <b>%.0f days</b> ahead on average\n", r.LeadDays))
+	}
+	if r.Notifications > 0 || r.Clicks > 0 {
+		msg.WriteString(fmt.Sprintf("📬 %d notification%s sent · 🔗 %d link click%s\n", r.Notifications, pluralize(r.Notifications), r.Clicks, pluralize(r.Clicks)))
+	}
+
+	msg.WriteString("\n#VGAGolf #Trends")
+	return msg.String()
+}
+
+// formatTrendDays formats a span of days, e.g. "1 day" or "12 days"
+func formatTrendDays(days float64) string {
+	n := int(days + 0.5)
+	return fmt.Sprintf("%d day%s", n, pluralize(n))
+}
@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/trends"
+)
+
+func TestFormatTrends(t *testing.T) {
+	until := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	r := &trends.Report{
+		Since:     until.AddDate(0, 0, -30),
+		Until:     until,
+		NewEvents: 3,
+		ByState:   []trends.StateCount{{State: "NV", Events: 2}, {State: "AZ", Events: 1}},
+		Fastest: []trends.Fill{
+			{Event: &event.Event{Title: "Wolf Creek", State: "NV", FieldFilled: 70, FieldSize: 72}, Gained: 30, Days: 6},
+		},
+		MostTracked:   []trends.CourseCount{{Course: "Shadow Creek", Users: 4, Clicks: 1}},
+		LeadDays:      41.6,
+		LeadEvents:    3,
+		Notifications: 120,
+		Clicks:        9,
+	}
+
+	msg := FormatTrends(r)
+	for _, want := range []string{
+		"VGA Trends</b> · Sep 1 – Oct 1",
+		"<b>3</b> new events",
+		"📍 Nevada — 2",
+		"• Wolf Creek (NV) — +30 spots in 6 days, 70 / 72 spots",
+		"• Shadow Creek — 4 users · 1 click",
+		"<b>42 days</b> ahead",
+		"120 notifications sent · 🔗 9 link clicks",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("FormatTrends() missing %q:\n%s", want, msg)
+		}
+	}
+
+	empty := FormatTrends(&trends.Report{Since: r.Since, Until: until})
+	if !strings.Contains(empty, "No new events") || strings.Contains(empty, "Fastest") || strings.Contains(empty, "ahead") {
+		t.Errorf("FormatTrends() with no data:\n%s", empty)
+	}
+}
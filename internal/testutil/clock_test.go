@@ -0,0 +1,36 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/clock"
+)
+
+func TestFakeClock(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	start := time.Date(2026, 3, 7, 9, 0, 0, 0, la)
+
+	var c clock.Clock = NewFakeClock(start)
+	fake := c.(*FakeClock)
+	if !c.Now().Equal(start) {
+		t.Fatalf("Now() = %v, want %v", c.Now(), start)
+	}
+
+	// Across the spring-forward change a calendar day is 23 hours
+	fake.AddDate(0, 0, 1)
+	if got := c.Now(); got.Hour() != 9 || got.Sub(start) != 23*time.Hour {
+		t.Errorf("AddDate(0, 0, 1) = %v, %v after start", got, got.Sub(start))
+	}
+	fake.Advance(time.Hour)
+	if got := c.Now(); got.Hour() != 10 {
+		t.Errorf("Advance(1h) = %v", got)
+	}
+	fake.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("Set() = %v", c.Now())
+	}
+}
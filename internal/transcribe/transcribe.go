@@ -0,0 +1,105 @@
+// Package transcribe turns voice notes into text with a configurable speech-to-text
+// provider. Any service with an OpenAI-compatible /audio/transcriptions endpoint works
+// (OpenAI, Groq, a self-hosted Whisper server).
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+// DefaultModel is the model requested when Config.Model is empty
+const DefaultModel = "whisper-1"
+
+// Config describes a transcription provider
+type Config struct {
+	// URL is the transcription endpoint, e.g. https://api.openai.com/v1/audio/transcriptions
+	URL string
+
+	// APIKey is sent as a Bearer token
+	APIKey string
+
+	// Model is the provider's model name (default DefaultModel)
+	Model string
+}
+
+// Client is a client for a transcription provider
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// NewClient creates a new transcription provider client
+func NewClient(config Config) *Client {
+	if config.Model == "" {
+		config.Model = DefaultModel
+	}
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Transcribe returns the text spoken in audio. filename tells the provider the format
+// (Telegram voice messages are .oga, Ogg Opus).
+func (c *Client) Transcribe(ctx context.Context, filename string, audio []byte) (string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("model", c.config.Model); err != nil {
+		return "", fmt.Errorf("writing model field: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("creating form file: %w", err)
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", fmt.Errorf("writing audio: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("closing multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.URL, body)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", errs.Status(resp.StatusCode, "transcription API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		return "", fmt.Errorf("no speech recognized")
+	}
+	return text, nil
+}
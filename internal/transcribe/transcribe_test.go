@@ -0,0 +1,48 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pfrederiksen/vga-events/internal/errs"
+)
+
+func TestTranscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("model") != DefaultModel {
+			t.Errorf("model = %q", r.FormValue("model"))
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("reading file: %v", err)
+		}
+		audio, _ := io.ReadAll(file)
+		if header.Filename != "voice.oga" || string(audio) != "OggS..." {
+			t.Errorf("file = %s %q", header.Filename, audio)
+		}
+		_, _ = w.Write([]byte(`{"text": " Meet at the range at 7:30. "}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{URL: server.URL, APIKey: "secret"})
+	text, err := client.Transcribe(context.Background(), "voice.oga", []byte("OggS..."))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if text != "Meet at the range at 7:30." {
+		t.Errorf("Transcribe() = %q", text)
+	}
+
+	_, err = NewClient(Config{URL: server.URL}).Transcribe(context.Background(), "voice.oga", nil)
+	if !errors.Is(err, errs.ErrAuth) {
+		t.Errorf("without a key: err = %v, want ErrAuth", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package trends summarizes a stretch of VGA calendar activity, usually the last 30
+// days: new events per state, the events whose fields filled fastest, the courses users
+// track most, and how far ahead events are posted.
+//
+// It works from the snapshot history (one storage.SnapshotDelta per save), the delivery
+// and click logs, and a count of the users tracking each event, all of which the caller
+// loads; Build only does the arithmetic.
+package trends
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+const (
+	// DefaultDays is how far back a report looks
+	DefaultDays = 30
+
+	// top is how many fastest-filling events and most-tracked courses are kept
+	top = 5
+)
+
+// Input is what a report is built from
+type Input struct {
+	History    []*storage.SnapshotDelta // Oldest first, as storage.LoadHistory returns it
+	Deliveries []*storage.Delivery
+	Clicks     []*storage.Click
+	Tracking   map[string]int // Users tracking each event ID, with any status
+}
+
+// StateCount is the number of new events in one state
+type StateCount struct {
+	State  string
+	Events int
+}
+
+// Fill is how fast an event's field filled during the report's window
+type Fill struct {
+	Event  *event.Event // Latest version seen
+	Gained int          // Spots taken during the window
+	Days   float64      // Days between the first and last field counts, at least 1
+}
+
+// PerDay returns the spots taken per day
+func (f Fill) PerDay() float64 {
+	return float64(f.Gained) / f.Days
+}
+
+// CourseCount is how many users track events at a course, and how many clicks
+// its events' links got during the window
+type CourseCount struct {
+	Course string
+	Users  int
+	Clicks int
+}
+
+// Report summarizes the window from Since to Until
+type Report struct {
+	Since time.Time
+	Until time.Time
+
+	NewEvents   int
+	ByState     []StateCount  // Most new events first
+	Fastest     []Fill        // Most spots per day first
+	MostTracked []CourseCount // Most users first
+
+	LeadDays   float64 // Average days from an event being posted to its date
+	LeadEvents int     // New events with a known date the average covers
+
+	Notifications int // Notifications sent
+	Clicks        int // Clicks on tracked links
+}
+
+// fieldCount is one observation of an event's field
+type fieldCount struct {
+	at     time.Time
+	filled int
+}
+
+// Build summarizes the days before now
+func Build(in Input, now time.Time, days int) *Report {
+	since := now.AddDate(0, 0, -days)
+	r := &Report{Since: since, Until: now}
+
+	events := make(map[string]*event.Event)
+	states := make(map[string]int)
+	first := make(map[string]fieldCount)
+	last := make(map[string]fieldCount)
+	var leadTotal float64
+
+	for _, delta := range in.History {
+		at, err := time.Parse(time.RFC3339, delta.At)
+		if err != nil || at.After(now) {
+			continue
+		}
+		inWindow := !at.Before(since)
+
+		for _, evt := range delta.Added {
+			if inWindow {
+				r.NewEvents++
+				states[evt.State]++
+				if date := event.ParseDate(evt.DateText); !date.IsZero() && date.After(at) {
+					leadTotal += date.Sub(at).Hours() / 24
+					r.LeadEvents++
+				}
+			}
+		}
+
+		for _, evt := range slices.Concat(delta.Added, delta.Changed) {
+			events[evt.ID] = evt
+			if evt.FieldSize == 0 {
+				continue
+			}
+			count := fieldCount{at: at, filled: evt.FieldFilled}
+			// The window starts from the last count before it, or its first count
+			if _, seen := first[evt.ID]; !seen || !inWindow {
+				first[evt.ID] = count
+			}
+			last[evt.ID] = count
+		}
+	}
+
+	for state, n := range states {
+		r.ByState = append(r.ByState, StateCount{State: state, Events: n})
+	}
+	sort.Slice(r.ByState, func(i, j int) bool {
+		if r.ByState[i].Events != r.ByState[j].Events {
+			return r.ByState[i].Events > r.ByState[j].Events
+		}
+		return r.ByState[i].State < r.ByState[j].State
+	})
+
+	if r.LeadEvents > 0 {
+		r.LeadDays = leadTotal / float64(r.LeadEvents)
+	}
+
+	r.Fastest = fastestFilling(events, first, last, since)
+	r.MostTracked = mostTracked(events, in.Tracking, in.Clicks, since, now)
+
+	for _, d := range in.Deliveries {
+		if d.Result == storage.DeliverySent && inRange(d.At, since, now) {
+			r.Notifications++
+		}
+	}
+	for _, c := range in.Clicks {
+		if inRange(c.At, since, now) {
+			r.Clicks++
+		}
+	}
+
+	return r
+}
+
+// fastestFilling ranks the events whose fields gained spots during the window
+func fastestFilling(events map[string]*event.Event, first, last map[string]fieldCount, since time.Time) []Fill {
+	var fills []Fill
+	for id, end := range last {
+		start := first[id]
+		gained := end.filled - start.filled
+		if end.at.Before(since) || gained <= 0 {
+			continue
+		}
+		// A field that filled within a day counts as a day, so one busy save
+		// doesn't dwarf events that filled steadily
+		days := end.at.Sub(start.at).Hours() / 24
+		if days < 1 {
+			days = 1
+		}
+		fills = append(fills, Fill{Event: events[id], Gained: gained, Days: days})
+	}
+
+	sort.Slice(fills, func(i, j int) bool {
+		if fills[i].PerDay() != fills[j].PerDay() {
+			return fills[i].PerDay() > fills[j].PerDay()
+		}
+		return fills[i].Event.ID < fills[j].Event.ID
+	})
+	if len(fills) > top {
+		fills = fills[:top]
+	}
+	return fills
+}
+
+// mostTracked ranks courses by the users tracking their events, then by clicks on
+// their events' links during the window. Events are grouped by normalized title, and
+// a course is named by the shortest of its titles.
+func mostTracked(events map[string]*event.Event, tracking map[string]int, clicks []*storage.Click, since, now time.Time) []CourseCount {
+	courses := make(map[string]*CourseCount)
+	course := func(evt *event.Event) *CourseCount {
+		key := event.NormalizeCourseTitle(evt.Title)
+		c, ok := courses[key]
+		if !ok {
+			c = &CourseCount{Course: evt.Title}
+			courses[key] = c
+		} else if len(evt.Title) < len(c.Course) || (len(evt.Title) == len(c.Course) && evt.Title < c.Course) {
+			c.Course = evt.Title
+		}
+		return c
+	}
+
+	for id, users := range tracking {
+		if evt, ok := events[id]; ok && users > 0 {
+			course(evt).Users += users
+		}
+	}
+	for _, click := range clicks {
+		if evt, ok := events[click.EventID]; ok && inRange(click.At, since, now) {
+			course(evt).Clicks++
+		}
+	}
+
+	ranked := make([]CourseCount, 0, len(courses))
+	for _, c := range courses {
+		if c.Users > 0 {
+			ranked = append(ranked, *c)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Users != ranked[j].Users {
+			return ranked[i].Users > ranked[j].Users
+		}
+		if ranked[i].Clicks != ranked[j].Clicks {
+			return ranked[i].Clicks > ranked[j].Clicks
+		}
+		return ranked[i].Course < ranked[j].Course
+	})
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	return ranked
+}
+
+// inRange reports whether an RFC3339 timestamp falls between since and until
+func inRange(at string, since, until time.Time) bool {
+	t, err := time.Parse(time.RFC3339, at)
+	return err == nil && !t.Before(since) && !t.After(until)
+}
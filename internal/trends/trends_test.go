@@ -0,0 +1,96 @@
+package trends
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/storage"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(daysAgo int) string { return now.AddDate(0, 0, -daysAgo).Format(time.RFC3339) }
+
+	history := []*storage.SnapshotDelta{
+		// Before the window: only the field count is used
+		{At: at(40), Added: []*event.Event{
+			{ID: "old", State: "NV", Title: "Shadow Creek", DateText: "Dec 1 2026", FieldFilled: 10, FieldSize: 72},
+		}},
+		{At: at(20), Added: []*event.Event{
+			{ID: "a", State: "NV", Title: "Wolf Creek", DateText: "Nov 9 2026", FieldFilled: 20, FieldSize: 40},
+			{ID: "b", State: "AZ", Title: "TPC Scottsdale", DateText: "Oct 21 2026"},
+			{ID: "c", State: "NV", Title: "Cascata", FieldFilled: 5, FieldSize: 60},
+		}},
+		{At: at(10), Changed: []*event.Event{
+			{ID: "old", State: "NV", Title: "Shadow Creek", DateText: "Dec 1 2026", FieldFilled: 70, FieldSize: 72},
+			{ID: "a", State: "NV", Title: "Wolf Creek", DateText: "Nov 9 2026", FieldFilled: 30, FieldSize: 40},
+		}},
+		{At: at(5), Added: []*event.Event{
+			{ID: "d", State: "UT", Title: "Wolf Creek Golf Club", DateText: "Nov 4 2026"},
+		}},
+	}
+
+	deliveries := []*storage.Delivery{
+		{At: at(3), Result: storage.DeliverySent},
+		{At: at(3), Result: storage.DeliveryFailed},
+		{At: at(45), Result: storage.DeliverySent},
+	}
+	clicks := []*storage.Click{
+		{At: at(2), EventID: "a"},
+		{At: at(2), EventID: "d"},
+		{At: at(2)},
+		{At: at(50), EventID: "b"},
+	}
+	tracking := map[string]int{"a": 2, "d": 1, "b": 2, "gone": 9}
+
+	r := Build(Input{History: history, Deliveries: deliveries, Clicks: clicks, Tracking: tracking}, now, DefaultDays)
+
+	if r.NewEvents != 4 {
+		t.Errorf("NewEvents = %d, want 4", r.NewEvents)
+	}
+	wantStates := []StateCount{{"NV", 2}, {"AZ", 1}, {"UT", 1}}
+	if len(r.ByState) != len(wantStates) {
+		t.Fatalf("ByState = %+v", r.ByState)
+	}
+	for i, want := range wantStates {
+		if r.ByState[i] != want {
+			t.Errorf("ByState[%d] = %+v, want %+v", i, r.ByState[i], want)
+		}
+	}
+
+	// Shadow Creek gained 60 spots over 30 days (from its last count before the
+	// window); Wolf Creek 10 over 10
+	if len(r.Fastest) != 2 || r.Fastest[0].Event.ID != "old" || r.Fastest[1].Event.ID != "a" {
+		t.Fatalf("Fastest = %+v", r.Fastest)
+	}
+	if f := r.Fastest[0]; f.Gained != 60 || f.PerDay() != 2 {
+		t.Errorf("Fastest[0] gained %d at %.1f/day, want 60 at 2/day", f.Gained, f.PerDay())
+	}
+
+	// Wolf Creek events are one course, tracked by 3 users with 2 clicks
+	if len(r.MostTracked) != 2 || r.MostTracked[0] != (CourseCount{Course: "Wolf Creek", Users: 3, Clicks: 2}) {
+		t.Errorf("MostTracked = %+v", r.MostTracked)
+	}
+	if r.MostTracked[1].Course != "TPC Scottsdale" || r.MostTracked[1].Clicks != 0 {
+		t.Errorf("clicks before the window shouldn't count: %+v", r.MostTracked[1])
+	}
+
+	// Wolf Creek was posted 58.5 days ahead, TPC Scottsdale 39.5, Wolf Creek GC 38.5;
+	// Cascata has no date
+	if r.LeadEvents != 3 || math.Abs(r.LeadDays-45.5) > 0.01 {
+		t.Errorf("lead time = %.1f days over %d events, want 45.5 over 3", r.LeadDays, r.LeadEvents)
+	}
+
+	if r.Notifications != 1 || r.Clicks != 3 {
+		t.Errorf("Notifications = %d, Clicks = %d, want 1 and 3", r.Notifications, r.Clicks)
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	r := Build(Input{}, time.Now(), DefaultDays)
+	if r.NewEvents != 0 || r.LeadDays != 0 || len(r.Fastest) != 0 || len(r.MostTracked) != 0 {
+		t.Errorf("Build() with no data = %+v", r)
+	}
+}
@@ -0,0 +1,241 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// subscriptionsData is the subscriptions page
+type subscriptionsData struct {
+	States          []string
+	Available       []string // States not yet subscribed
+	Cities          []preferences.CitySubscription
+	FollowedCourses []string
+	Travel          []preferences.TravelSubscription
+	DigestFrequency string
+	Frequencies     []string
+}
+
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *request) {
+	data := subscriptionsData{
+		States:          append([]string(nil), r.user.States...),
+		Cities:          r.user.Cities,
+		FollowedCourses: r.user.FollowedCourses,
+		Travel:          r.user.Travel,
+		DigestFrequency: r.user.DigestFrequency,
+		Frequencies:     []string{preferences.DigestFrequencyImmediate, preferences.DigestFrequencyDaily, preferences.DigestFrequencyWeekly},
+	}
+	if data.DigestFrequency == "" {
+		data.DigestFrequency = preferences.DigestFrequencyImmediate
+	}
+	sort.Strings(data.States)
+	for _, code := range preferences.StateCodes() {
+		if !r.prefs.HasState(r.chatID, code) {
+			data.Available = append(data.Available, code)
+		}
+	}
+	s.render(w, r.Request, http.StatusOK, "subscriptions", page{Title: "Subscriptions", Nav: "subscriptions", ChatID: r.chatID, Data: &data})
+}
+
+// handleStates subscribes to (action=add) or unsubscribes from (action=remove) a state
+func (s *Server) handleStates(w http.ResponseWriter, r *request) {
+	state := strings.ToUpper(strings.TrimSpace(r.PostFormValue("state")))
+	action := r.PostFormValue("action")
+	if !preferences.IsValidState(state) || (action != "add" && action != "remove") {
+		http.Error(w, "invalid state or action", http.StatusBadRequest)
+		return
+	}
+
+	var changed bool
+	err := s.update(r.Context(), r.chatID, func(prefs preferences.Preferences, user *preferences.UserPreferences) error {
+		if action == "add" {
+			changed = prefs.AddState(r.chatID, state)
+		} else {
+			changed = prefs.RemoveState(r.chatID, state)
+		}
+		return nil
+	})
+	if err != nil {
+		updateError(w, err)
+		return
+	}
+
+	notice := map[string]map[bool]string{
+		"add":    {true: "subscribed", false: "already"},
+		"remove": {true: "unsubscribed", false: "not-subscribed"},
+	}[action][changed]
+	redirect(w, r, "/subscriptions", notice)
+}
+
+func (s *Server) handleDigest(w http.ResponseWriter, r *request) {
+	frequency := r.PostFormValue("frequency")
+	err := s.update(r.Context(), r.chatID, func(prefs preferences.Preferences, user *preferences.UserPreferences) error {
+		if !user.SetDigestFrequency(frequency) {
+			return errInvalidInput
+		}
+		return nil
+	})
+	if errors.Is(err, errInvalidInput) {
+		http.Error(w, "invalid frequency", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		updateError(w, err)
+		return
+	}
+	redirect(w, r, "/subscriptions", "digest")
+}
+
+// savedFilter is one saved filter on the filters page
+type savedFilter struct {
+	Name        string
+	Description string
+	Active      bool
+}
+
+// filterForm is the values entered in the new-filter form
+type filterForm struct {
+	Name, Dates, Courses, Cities, States string
+	WeekendsOnly                         bool
+}
+
+// filtersData is the filters page
+type filtersData struct {
+	Filters []savedFilter
+	Form    filterForm
+}
+
+func (s *Server) handleFilters(w http.ResponseWriter, r *request) {
+	s.renderFilters(w, r, http.StatusOK, filterForm{}, "")
+}
+
+func (s *Server) renderFilters(w http.ResponseWriter, r *request, status int, form filterForm, errMsg string) {
+	data := filtersData{Form: form}
+	for _, name := range filterNames(r.user) {
+		data.Filters = append(data.Filters, savedFilter{
+			Name:        name,
+			Description: r.user.GetFilter(name).String(),
+			Active:      name == r.user.ActiveFilter,
+		})
+	}
+	s.render(w, r.Request, status, "filters", page{Title: "Filters", Nav: "filters", ChatID: r.chatID, Error: errMsg, Data: &data})
+}
+
+// handleCreateFilter saves a filter from the form, replacing one with the same name
+func (s *Server) handleCreateFilter(w http.ResponseWriter, r *request) {
+	form := filterForm{
+		Name:         strings.TrimSpace(r.PostFormValue("name")),
+		Dates:        strings.TrimSpace(r.PostFormValue("dates")),
+		Courses:      r.PostFormValue("courses"),
+		Cities:       r.PostFormValue("cities"),
+		States:       r.PostFormValue("states"),
+		WeekendsOnly: r.PostFormValue("weekends") != "",
+	}
+
+	f, err := form.filter()
+	if err != nil {
+		s.renderFilters(w, r, http.StatusBadRequest, form, "Couldn't save the filter: "+err.Error())
+		return
+	}
+
+	err = s.update(r.Context(), r.chatID, func(prefs preferences.Preferences, user *preferences.UserPreferences) error {
+		user.SaveFilter(form.Name, f)
+		return nil
+	})
+	if err != nil {
+		updateError(w, err)
+		return
+	}
+	redirect(w, r, "/filters", "filter-saved")
+}
+
+// filter validates the form and builds the filter it describes
+func (form filterForm) filter() (*filter.Filter, error) {
+	if form.Name == "" || strings.HasPrefix(form.Name, "_") || len(form.Name) > 50 {
+		return nil, errors.New("name must be 1-50 characters and not start with \"_\"")
+	}
+
+	f := filter.NewFilter()
+	if form.Dates != "" {
+		from, to, err := filter.ParseDateRange(form.Dates)
+		if err != nil {
+			return nil, fmt.Errorf("dates: %w (try \"March\", \"Mar 1-15\", or \"March 1 - April 15\")", err)
+		}
+		f.DateFrom, f.DateTo = from, to
+	}
+	f.Courses = splitList(form.Courses)
+	f.Cities = splitList(form.Cities)
+	for _, state := range splitList(form.States) {
+		state = strings.ToUpper(state)
+		if !preferences.IsValidState(state) {
+			return nil, fmt.Errorf("unknown state %q", state)
+		}
+		f.States = append(f.States, state)
+	}
+	f.WeekendsOnly = form.WeekendsOnly
+
+	if f.IsEmpty() {
+		return nil, errors.New("set at least one criterion")
+	}
+	return f, nil
+}
+
+// splitList splits comma-separated form input, dropping blanks
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// handleActiveFilter makes a saved filter active, or clears it when name is empty
+func (s *Server) handleActiveFilter(w http.ResponseWriter, r *request) {
+	name := r.PostFormValue("name")
+	err := s.update(r.Context(), r.chatID, func(prefs preferences.Preferences, user *preferences.UserPreferences) error {
+		if name == "" {
+			user.ClearActiveFilter()
+			return nil
+		}
+		if !user.SetActiveFilter(name) {
+			return errInvalidInput
+		}
+		return nil
+	})
+	if errors.Is(err, errInvalidInput) {
+		redirect(w, r, "/filters", "filter-notfound")
+		return
+	}
+	if err != nil {
+		updateError(w, err)
+		return
+	}
+	redirect(w, r, "/filters", "filter-active")
+}
+
+func (s *Server) handleDeleteFilter(w http.ResponseWriter, r *request) {
+	name := r.PostFormValue("name")
+	err := s.update(r.Context(), r.chatID, func(prefs preferences.Preferences, user *preferences.UserPreferences) error {
+		if !user.DeleteFilter(name) {
+			return errInvalidInput
+		}
+		return nil
+	})
+	if errors.Is(err, errInvalidInput) {
+		redirect(w, r, "/filters", "filter-notfound")
+		return
+	}
+	if err != nil {
+		updateError(w, err)
+		return
+	}
+	redirect(w, r, "/filters", "filter-deleted")
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// bar is one bar of a chart. Percent is its length relative to the chart's largest bar.
+type bar struct {
+	Label   string
+	Count   int
+	Percent int
+}
+
+// statsData is the stats page
+type statsData struct {
+	Enabled          bool
+	Weeks            int
+	EventsViewed     int
+	EventsRegistered int
+	Converted, Total int // Interested → registered conversion
+	Marked           []bar
+	Tracked          []bar // Current statuses
+	ByState          []bar // Current events in the subscribed states
+}
+
+// statusLabels names the event statuses in chart order
+var statusLabels = []struct{ status, label string }{
+	{preferences.EventStatusInterested, "Interested"},
+	{preferences.EventStatusRegistered, "Registered"},
+	{preferences.EventStatusMaybe, "Maybe"},
+	{preferences.EventStatusSkip, "Skipped"},
+}
+
+// handleStats shows all-time stats, the current statuses, and how many events each
+// subscribed state has
+func (s *Server) handleStats(w http.ResponseWriter, r *request) {
+	data := statsData{Enabled: r.user.EnableStats && r.user.WeeklyStats != nil}
+	if data.Enabled {
+		stats := r.user.GetAllTimeStats()
+		data.Weeks = r.user.TrackingWeeks()
+		data.EventsViewed = stats.EventsViewed
+		data.EventsRegistered = stats.EventsRegistered
+		data.Converted, data.Total = r.user.StatusConversion(preferences.EventStatusInterested, preferences.EventStatusRegistered)
+		data.Marked = statusBars(stats.EventsMarked)
+	}
+
+	current := make(map[string]int)
+	for _, status := range r.user.EventStatuses {
+		current[status]++
+	}
+	data.Tracked = statusBars(current)
+
+	if events, err := s.loadEvents(); err == nil {
+		perState := make(map[string]int)
+		for _, evt := range events {
+			if r.prefs.HasState(r.chatID, evt.State) {
+				perState[evt.State]++
+			}
+		}
+		states := make([]string, 0, len(perState))
+		for state := range perState {
+			states = append(states, state)
+		}
+		sort.Strings(states)
+		for _, state := range states {
+			data.ByState = append(data.ByState, bar{Label: state, Count: perState[state]})
+		}
+		scaleBars(data.ByState)
+	}
+
+	s.render(w, r.Request, http.StatusOK, "stats", page{Title: "Stats", Nav: "stats", ChatID: r.chatID, Data: &data})
+}
+
+// statusBars charts status counts, skipping statuses with none
+func statusBars(counts map[string]int) []bar {
+	var bars []bar
+	for _, s := range statusLabels {
+		if counts[s.status] > 0 {
+			bars = append(bars, bar{Label: s.label, Count: counts[s.status]})
+		}
+	}
+	scaleBars(bars)
+	return bars
+}
+
+// scaleBars sets each bar's length relative to the largest
+func scaleBars(bars []bar) {
+	largest := 0
+	for _, b := range bars {
+		largest = max(largest, b.Count)
+	}
+	for i := range bars {
+		if largest > 0 {
+			bars[i].Percent = bars[i].Count * 100 / largest
+		}
+	}
+}
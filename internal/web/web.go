@@ -0,0 +1,399 @@
+// Package web serves a small server-rendered dashboard over the bot's preferences
+// and the latest event snapshot. Users sign in with the personal token from the
+// bot's /api-token command and can browse events, manage their state subscriptions,
+// digest frequency, and saved filters, and view their stats. Changes are saved to
+// the same preferences storage the bot uses.
+package web
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/api"
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// sessionCookie holds the signed-in user's API token
+const sessionCookie = "vga_session"
+
+// sessionMaxAge is how long the browser keeps the session cookie
+const sessionMaxAge = 30 * 24 * time.Hour
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var (
+	// errUnknownUser is returned when the signed-in user disappears between requests
+	errUnknownUser = errors.New("user not found")
+
+	// errInvalidInput is returned by update changes that reject the submitted form
+	errInvalidInput = errors.New("invalid input")
+)
+
+// Options configures a Server
+type Options struct {
+	Prefs    preferences.Storage
+	Events   api.EventsLoader
+	PrefsTTL time.Duration // 0 uses api.DefaultPrefsTTL
+}
+
+// Server is the web dashboard
+type Server struct {
+	store      preferences.Storage
+	loadEvents api.EventsLoader
+	prefsTTL   time.Duration
+	mux        *http.ServeMux
+	pages      map[string]*template.Template
+
+	mu       sync.Mutex // Guards prefs and loadedAt, and serializes saves
+	prefs    preferences.Preferences
+	loadedAt time.Time
+}
+
+// New creates a Server
+func New(opts Options) *Server {
+	s := &Server{
+		store:      opts.Prefs,
+		loadEvents: opts.Events,
+		prefsTTL:   opts.PrefsTTL,
+		mux:        http.NewServeMux(),
+		pages:      parsePages("login", "events", "subscriptions", "filters", "stats"),
+	}
+	if s.prefsTTL <= 0 {
+		s.prefsTTL = api.DefaultPrefsTTL
+	}
+
+	s.mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/events", http.StatusSeeOther)
+	})
+	s.mux.HandleFunc("GET /login", s.handleLoginPage)
+	s.mux.HandleFunc("POST /login", s.handleLogin)
+	s.mux.HandleFunc("POST /logout", s.handleLogout)
+	s.mux.HandleFunc("GET /events", s.authenticated(s.handleEvents))
+	s.mux.HandleFunc("GET /subscriptions", s.authenticated(s.handleSubscriptions))
+	s.mux.HandleFunc("POST /subscriptions/states", s.authenticated(s.handleStates))
+	s.mux.HandleFunc("POST /subscriptions/digest", s.authenticated(s.handleDigest))
+	s.mux.HandleFunc("GET /filters", s.authenticated(s.handleFilters))
+	s.mux.HandleFunc("POST /filters", s.authenticated(s.handleCreateFilter))
+	s.mux.HandleFunc("POST /filters/active", s.authenticated(s.handleActiveFilter))
+	s.mux.HandleFunc("POST /filters/delete", s.authenticated(s.handleDeleteFilter))
+	s.mux.HandleFunc("GET /stats", s.authenticated(s.handleStats))
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+	s.mux.ServeHTTP(w, r)
+}
+
+// parsePages parses each page template together with the shared layout
+func parsePages(names ...string) map[string]*template.Template {
+	funcs := template.FuncMap{
+		"stateName": preferences.GetStateName,
+	}
+	pages := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		pages[name] = template.Must(template.New(name).Funcs(funcs).ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return pages
+}
+
+// preferences returns the cached preferences, reloading them once they're older than
+// the TTL. If a reload fails, the previous preferences are used.
+func (s *Server) preferences(ctx context.Context) (preferences.Preferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prefs != nil && time.Since(s.loadedAt) < s.prefsTTL {
+		return s.prefs, nil
+	}
+	prefs, err := s.store.Load(ctx)
+	if err != nil {
+		if s.prefs != nil {
+			fmt.Fprintf(os.Stderr, "Warning: reloading preferences: %v\n", err)
+			return s.prefs, nil
+		}
+		return nil, err
+	}
+	s.prefs = prefs
+	s.loadedAt = time.Now()
+	return prefs, nil
+}
+
+// update applies change to the user in freshly loaded preferences and saves only that
+// user (see preferences.UnitOfWork), so changes the bot made since the cache was filled,
+// to this user or anyone else, are kept
+func (s *Server) update(ctx context.Context, chatID string, change func(preferences.Preferences, *preferences.UserPreferences) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefs, err := s.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("loading preferences: %w", err)
+	}
+	user, ok := prefs[chatID]
+	if !ok {
+		return errUnknownUser
+	}
+	err = preferences.NewUnitOfWork(s.store).Apply(ctx, prefs, []string{chatID}, func(prefs preferences.Preferences) error {
+		return change(prefs, user)
+	})
+	if err != nil {
+		return err
+	}
+	s.prefs = prefs
+	s.loadedAt = time.Now()
+	return nil
+}
+
+// request is an authenticated request with the caller's preferences
+type request struct {
+	*http.Request
+	chatID string
+	prefs  preferences.Preferences
+	user   *preferences.UserPreferences
+}
+
+// authenticated checks the session cookie before calling next, sending visitors
+// without a valid token to the login page. Form posts must come from this site.
+func (s *Server) authenticated(next func(http.ResponseWriter, *request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && !sameOrigin(r) {
+			http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil || cookie.Value == "" {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+
+		prefs, err := s.preferences(r.Context())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading preferences: %v\n", err)
+			http.Error(w, "preferences unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		chatID, ok := prefs.FindAPIToken(cookie.Value)
+		if !ok {
+			clearSession(w)
+			http.Redirect(w, r, "/login?expired=1", http.StatusSeeOther)
+			return
+		}
+
+		next(w, &request{Request: r, chatID: chatID, prefs: prefs, user: prefs[chatID]})
+	}
+}
+
+// sameOrigin reports whether a request's Origin (when the browser sends one) matches
+// its Host, so other sites can't post forms with the user's cookie
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func setSession(w http.ResponseWriter, r *http.Request, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(sessionMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func clearSession(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// page is the data every template gets
+type page struct {
+	Title  string
+	Nav    string // Current section, highlighted in the navigation
+	ChatID string
+	Notice string
+	Error  string
+	Data   interface{}
+}
+
+// notices are the confirmation messages redirects can ask a page to show
+var notices = map[string]string{
+	"subscribed":      "Subscribed.",
+	"unsubscribed":    "Unsubscribed.",
+	"digest":          "Notification frequency updated.",
+	"filter-saved":    "Filter saved.",
+	"filter-active":   "Active filter updated.",
+	"filter-deleted":  "Filter deleted.",
+	"already":         "You're already subscribed to that state.",
+	"not-subscribed":  "You weren't subscribed to that state.",
+	"filter-notfound": "That filter no longer exists.",
+}
+
+// render executes a page template, taking the notice from the ?notice= key
+func (s *Server) render(w http.ResponseWriter, r *http.Request, status int, name string, p page) {
+	if p.Notice == "" {
+		p.Notice = notices[r.URL.Query().Get("notice")]
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := s.pages[name].ExecuteTemplate(w, "layout", p); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering %s: %v\n", name, err)
+	}
+}
+
+// redirect sends the browser back to path with a notice
+func redirect(w http.ResponseWriter, r *request, path, notice string) {
+	http.Redirect(w, r.Request, path+"?notice="+url.QueryEscape(notice), http.StatusSeeOther)
+}
+
+// updateError responds to a failed update
+func updateError(w http.ResponseWriter, err error) {
+	fmt.Fprintf(os.Stderr, "Error updating preferences: %v\n", err)
+	http.Error(w, "could not save your changes, please try again", http.StatusServiceUnavailable)
+}
+
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	p := page{Title: "Sign in"}
+	if r.URL.Query().Has("expired") {
+		p.Error = "Your token was rotated or revoked. Sign in with your new token."
+	}
+	s.render(w, r, http.StatusOK, "login", p)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+		return
+	}
+
+	token := strings.TrimSpace(r.PostFormValue("token"))
+	prefs, err := s.preferences(r.Context())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading preferences: %v\n", err)
+		http.Error(w, "preferences unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if _, ok := prefs.FindAPIToken(token); !ok {
+		s.render(w, r, http.StatusUnauthorized, "login", page{Title: "Sign in", Error: "That token isn't valid. Get a new one with /api-token in the bot."})
+		return
+	}
+
+	setSession(w, r, token)
+	http.Redirect(w, r, "/events", http.StatusSeeOther)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+		return
+	}
+	clearSession(w)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// eventRow is one event in the events table
+type eventRow struct {
+	*event.Event
+	Status string
+	Note   string
+}
+
+// eventsData is the events page
+type eventsData struct {
+	State   string // "" for subscriptions, ALL, or a state code
+	Filter  string // Saved filter applied, "" for none
+	States  []string
+	Filters []string
+	Events  []eventRow
+}
+
+// handleEvents lists current events, soonest first. ?state= picks a state (or ALL)
+// instead of the subscriptions; ?filter= applies a saved filter, defaulting to the
+// active one.
+func (s *Server) handleEvents(w http.ResponseWriter, r *request) {
+	events, err := s.loadEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading events: %v\n", err)
+		http.Error(w, "events unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	data := eventsData{
+		State:   strings.ToUpper(strings.TrimSpace(query.Get("state"))),
+		Filter:  r.user.ActiveFilter,
+		States:  preferences.StateCodes(),
+		Filters: filterNames(r.user),
+	}
+	if query.Has("filter") {
+		data.Filter = query.Get("filter")
+	}
+	p := page{Title: "Events", Nav: "events", ChatID: r.chatID, Data: &data}
+
+	if data.State != "" && data.State != "ALL" && !preferences.IsValidState(data.State) {
+		p.Error = fmt.Sprintf("Unknown state %q.", data.State)
+		data.State = ""
+	}
+
+	var selected []*event.Event
+	for _, evt := range events {
+		switch {
+		case data.State == "ALL",
+			data.State != "" && strings.EqualFold(evt.State, data.State),
+			data.State == "" && r.user.MatchesSubscriptions(evt):
+			selected = append(selected, evt)
+		}
+	}
+	if data.Filter != "" {
+		if f := r.user.GetFilter(data.Filter); f != nil {
+			selected = f.Apply(selected)
+		} else {
+			data.Filter = ""
+		}
+	}
+	event.SortByDate(selected)
+
+	for _, evt := range selected {
+		data.Events = append(data.Events, eventRow{Event: evt, Status: r.user.GetEventStatus(evt.ID), Note: r.user.GetEventNote(evt.ID)})
+	}
+	s.render(w, r.Request, http.StatusOK, "events", p)
+}
+
+// filterNames returns the user's saved filter names, sorted, without the bot's
+// temporary filter
+func filterNames(user *preferences.UserPreferences) []string {
+	var names []string
+	for _, name := range user.GetAllFilterNames() {
+		if !strings.HasPrefix(name, "_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
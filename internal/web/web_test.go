@@ -0,0 +1,285 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/filter"
+	"github.com/pfrederiksen/vga-events/internal/preferences"
+)
+
+// memoryStorage stores preferences as JSON, so saved changes round-trip like the Gist
+type memoryStorage struct {
+	data  []byte
+	saves int
+}
+
+func (m *memoryStorage) Load(ctx context.Context) (preferences.Preferences, error) {
+	return preferences.FromJSON(m.data)
+}
+
+func (m *memoryStorage) Save(ctx context.Context, prefs preferences.Preferences) error {
+	data, err := prefs.ToJSON()
+	if err != nil {
+		return err
+	}
+	m.data = data
+	m.saves++
+	return nil
+}
+
+func (m *memoryStorage) user(t *testing.T, chatID string) *preferences.UserPreferences {
+	t.Helper()
+	prefs, err := m.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prefs.GetUser(chatID)
+}
+
+func newTestServer(t *testing.T) (*Server, *memoryStorage, string) {
+	t.Helper()
+	prefs := preferences.NewPreferences()
+	prefs.AddState("123", "NV")
+	user := prefs.GetUser("123")
+	token := user.NewAPIToken(time.Now())
+	user.SetEventStatus("nv1", preferences.EventStatusRegistered)
+	user.SetEventNote("nv1", "Tee time 8am")
+	user.SaveFilter("wolf", &filter.Filter{Courses: []string{"Wolf"}})
+
+	prefs.AddState("456", "CA")
+	prefs.GetUser("456").NewAPIToken(time.Now())
+
+	store := &memoryStorage{}
+	if err := store.Save(context.Background(), prefs); err != nil {
+		t.Fatal(err)
+	}
+	store.saves = 0
+
+	events := []*event.Event{
+		{ID: "nv1", State: "NV", Title: "Wolf Creek", DateText: "Apr 12 2026"},
+		{ID: "nv2", State: "NV", Title: "Chimera", DateText: "Apr 2 2026"},
+		{ID: "ca1", State: "CA", Title: "Pebble Beach", DateText: "May 1 2026"},
+	}
+
+	s := New(Options{
+		Prefs:  store,
+		Events: func() ([]*event.Event, error) { return events, nil },
+	})
+	return s, store, token
+}
+
+func do(t *testing.T, s http.Handler, method, path, token string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: token})
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLogin(t *testing.T) {
+	s, _, token := newTestServer(t)
+
+	if rec := do(t, s, http.MethodGet, "/events", "", nil); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login" {
+		t.Errorf("signed out: got %d to %q, want redirect to /login", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec := do(t, s, http.MethodPost, "/login", "", url.Values{"token": {"vga_WRONG"}})
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "isn&#39;t valid") {
+		t.Errorf("bad token: status %d", rec.Code)
+	}
+
+	rec = do(t, s, http.MethodPost, "/login", "", url.Values{"token": {token}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("login: status %d: %s", rec.Code, rec.Body)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != token || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+		t.Errorf("unexpected session cookie: %+v", cookies)
+	}
+
+	rec = do(t, s, http.MethodPost, "/logout", token, url.Values{})
+	if c := rec.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Errorf("logout should clear the cookie: %+v", c)
+	}
+}
+
+func TestEventsPage(t *testing.T) {
+	s, _, token := newTestServer(t)
+
+	body := do(t, s, http.MethodGet, "/events", token, nil).Body.String()
+	if !strings.Contains(body, "Wolf Creek") || !strings.Contains(body, "Chimera") || strings.Contains(body, "Pebble Beach") {
+		t.Errorf("subscribed events should be NV only:\n%s", body)
+	}
+	if !strings.Contains(body, "Tee time 8am") || !strings.Contains(body, "registered") {
+		t.Error("events should show the user's status and note")
+	}
+	if strings.Index(body, "Chimera") > strings.Index(body, "Wolf Creek") {
+		t.Error("events should be soonest first")
+	}
+
+	body = do(t, s, http.MethodGet, "/events?state=all", token, nil).Body.String()
+	if !strings.Contains(body, "Pebble Beach") {
+		t.Error("?state=all should include every state")
+	}
+
+	body = do(t, s, http.MethodGet, "/events?filter=wolf", token, nil).Body.String()
+	if !strings.Contains(body, "Wolf Creek") || strings.Contains(body, "Chimera") {
+		t.Error("?filter=wolf should apply the saved filter")
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	s, store, token := newTestServer(t)
+
+	rec := do(t, s, http.MethodPost, "/subscriptions/states", token, url.Values{"state": {"ca"}, "action": {"add"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("subscribe: status %d: %s", rec.Code, rec.Body)
+	}
+	if user := store.user(t, "123"); len(user.States) != 2 {
+		t.Errorf("states after subscribe = %v, want NV and CA", user.States)
+	}
+
+	do(t, s, http.MethodPost, "/subscriptions/states", token, url.Values{"state": {"NV"}, "action": {"remove"}})
+	if user := store.user(t, "123"); len(user.States) != 1 || user.States[0] != "CA" {
+		t.Errorf("states after unsubscribe = %v, want CA", user.States)
+	}
+
+	if rec := do(t, s, http.MethodPost, "/subscriptions/states", token, url.Values{"state": {"XX"}, "action": {"add"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid state: status %d, want 400", rec.Code)
+	}
+
+	do(t, s, http.MethodPost, "/subscriptions/digest", token, url.Values{"frequency": {"weekly"}})
+	if user := store.user(t, "123"); user.DigestFrequency != preferences.DigestFrequencyWeekly {
+		t.Errorf("digest frequency = %q, want weekly", user.DigestFrequency)
+	}
+
+	if other := store.user(t, "456"); len(other.States) != 1 || other.States[0] != "CA" {
+		t.Errorf("other users must not change: %v", other.States)
+	}
+
+	body := do(t, s, http.MethodGet, "/subscriptions?notice=digest", token, nil).Body.String()
+	if !strings.Contains(body, "Notification frequency updated.") {
+		t.Error("notice should be shown after a redirect")
+	}
+}
+
+func TestUpdateKeepsOtherWriters(t *testing.T) {
+	s, store, _ := newTestServer(t)
+	ctx := context.Background()
+
+	err := s.update(ctx, "123", func(prefs preferences.Preferences, user *preferences.UserPreferences) error {
+		// The bot saves another user while the form is being applied
+		stored, _ := store.Load(ctx)
+		stored.AddState("456", "AZ")
+		if err := store.Save(ctx, stored); err != nil {
+			return err
+		}
+		user.DigestFrequency = preferences.DigestFrequencyWeekly
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+
+	if user := store.user(t, "123"); user.DigestFrequency != preferences.DigestFrequencyWeekly {
+		t.Errorf("digest frequency = %q, want weekly", user.DigestFrequency)
+	}
+	if other := store.user(t, "456"); len(other.States) != 2 {
+		t.Errorf("the bot's change to another user was lost: %v", other.States)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	s, store, token := newTestServer(t)
+
+	rec := do(t, s, http.MethodPost, "/filters", token, url.Values{"name": {"weekend-nv"}, "states": {"nv"}, "weekends": {"1"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	f := store.user(t, "123").GetFilter("weekend-nv")
+	if f == nil || !f.WeekendsOnly || len(f.States) != 1 || f.States[0] != "NV" {
+		t.Fatalf("unexpected saved filter: %+v", f)
+	}
+
+	rec = do(t, s, http.MethodPost, "/filters", token, url.Values{"name": {"bad"}, "dates": {"Smarch 40"}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Smarch 40") {
+		t.Errorf("bad dates should re-render the form with the input kept: status %d", rec.Code)
+	}
+	if rec := do(t, s, http.MethodPost, "/filters", token, url.Values{"name": {"empty"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty filter: status %d, want 400", rec.Code)
+	}
+
+	do(t, s, http.MethodPost, "/filters/active", token, url.Values{"name": {"weekend-nv"}})
+	if user := store.user(t, "123"); user.ActiveFilter != "weekend-nv" {
+		t.Errorf("active filter = %q", user.ActiveFilter)
+	}
+
+	do(t, s, http.MethodPost, "/filters/delete", token, url.Values{"name": {"weekend-nv"}})
+	if user := store.user(t, "123"); user.GetFilter("weekend-nv") != nil || user.ActiveFilter != "" {
+		t.Error("deleting the active filter should remove and deactivate it")
+	}
+}
+
+func TestCrossOriginPostRejected(t *testing.T) {
+	s, store, token := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions/states", strings.NewReader("state=CA&action=add"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "https://evil.example")
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: token})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden || store.saves != 0 {
+		t.Errorf("cross-origin post: status %d with %d saves, want 403 and none", rec.Code, store.saves)
+	}
+}
+
+func TestRevokedTokenSignsOut(t *testing.T) {
+	s, store, token := newTestServer(t)
+
+	prefs, _ := store.Load(context.Background())
+	prefs.GetUser("123").RevokeAPIToken()
+	if err := store.Save(context.Background(), prefs); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := do(t, s, http.MethodGet, "/events", token, nil)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?expired=1" {
+		t.Errorf("revoked token: got %d to %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestStatsPage(t *testing.T) {
+	s, _, token := newTestServer(t)
+
+	rec := do(t, s, http.MethodGet, "/stats", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Registered") || !strings.Contains(body, "width: 100%") {
+		t.Errorf("stats should chart the tracked statuses:\n%s", body)
+	}
+}
+
+func TestScaleBars(t *testing.T) {
+	bars := []bar{{Count: 4}, {Count: 1}, {Count: 2}}
+	scaleBars(bars)
+	if bars[0].Percent != 100 || bars[1].Percent != 25 || bars[2].Percent != 50 {
+		t.Errorf("unexpected percents: %+v", bars)
+	}
+}
@@ -0,0 +1,54 @@
+package vgaevents_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/pkg/vgaevents"
+)
+
+func ExampleFetch() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	result, err := vgaevents.Fetch(ctx, vgaevents.FetchOptions{Contact: "you@example.com"})
+	if err != nil {
+		fmt.Println("fetch failed:", err)
+		return
+	}
+	for _, warning := range result.Warnings {
+		fmt.Println("skipped:", warning)
+	}
+	fmt.Printf("%d events listed\n", len(result.Events))
+}
+
+func ExampleUpdate() {
+	listing := func(state, title, date, city string) *vgaevents.Event {
+		raw := fmt.Sprintf("%s - %s %s - %s", state, title, date, city)
+		return vgaevents.NewEvent(state, title, date, city, raw, "https://vgagolf.org/state-events/")
+	}
+	shadowCreek := listing("NV", "Shadow Creek", "Apr 4 2026", "Las Vegas")
+	wolfCreek := listing("NV", "Wolf Creek", "May 9 2026", "Mesquite")
+
+	// First run: everything is new
+	snapshot, diff := vgaevents.Update(nil, []*vgaevents.Event{shadowCreek}, vgaevents.UpdateOptions{})
+	fmt.Println("first run:", len(diff.New), "new")
+
+	// Round-trip the snapshot, as a program saving it between runs would
+	var saved bytes.Buffer
+	if err := vgaevents.WriteSnapshot(&saved, snapshot); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if snapshot, err := vgaevents.ReadSnapshot(&saved); err == nil {
+		_, diff = vgaevents.Update(snapshot, []*vgaevents.Event{shadowCreek, wolfCreek}, vgaevents.UpdateOptions{})
+		for _, evt := range diff.New {
+			fmt.Println("new:", evt.Title, evt.DateText)
+		}
+	}
+	// Output:
+	// first run: 1 new
+	// new: Wolf Creek May 9 2026
+}
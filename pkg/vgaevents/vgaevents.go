@@ -0,0 +1,224 @@
+// Package vgaevents is the public Go API for the VGA Golf events calendar: fetch the
+// listed events, keep a snapshot between runs, and diff each fetch against it to find
+// new, removed, restored, and changed events.
+//
+// It wraps the packages under internal/, which the vga-events binaries build on and
+// which may change at any time. The functions and the option and result types defined
+// here follow semantic versioning: within a major version, they keep their meaning and
+// fields are only added. Event, Snapshot, and Change are aliases of the internal
+// types, so their JSON encoding is the snapshot format vga-events itself saves and
+// snapshots written by either can be read by the other; their fields follow that
+// format and aren't covered by the versioning promise.
+//
+// A typical run:
+//
+//	result, err := vgaevents.Fetch(ctx, vgaevents.FetchOptions{Contact: "me@example.com"})
+//	if err != nil {
+//		return err
+//	}
+//	previous, err := vgaevents.ReadSnapshot(f) // or vgaevents.NewSnapshot() the first time
+//	if err != nil {
+//		return err
+//	}
+//	next, diff := vgaevents.Update(previous, result.Events, vgaevents.UpdateOptions{FailedPages: result.FailedPages})
+//	for _, evt := range diff.New {
+//		fmt.Println(evt.State, evt.Title, evt.DateText)
+//	}
+//	return vgaevents.WriteSnapshot(out, next)
+package vgaevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/vga-events/internal/event"
+	"github.com/pfrederiksen/vga-events/internal/scraper"
+)
+
+type (
+	// Event is one listed event. Its ID is derived from the state and listing text,
+	// and its StableKey from the state and title, so an event keeps its StableKey
+	// when its date or city changes.
+	Event = event.Event
+
+	// Snapshot is the set of events seen by the last run, plus recently removed
+	// events and the indices diffs use
+	Snapshot = event.Snapshot
+
+	// Change is a date, title, or city change to a listed event, or a "renamed"
+	// event linked to the one it replaces
+	Change = event.EventChange
+)
+
+// AllStates selects every state in UpdateOptions.State
+const AllStates = "ALL"
+
+// Defaults used when options are left zero
+const (
+	DefaultRequestInterval = scraper.DefaultHostInterval
+	DefaultConfirmRemovals = 2
+	DefaultRetentionDays   = event.DefaultRemovedRetentionDays
+)
+
+// FetchOptions configures Fetch. The zero value fetches with the defaults.
+type FetchOptions struct {
+	// Contact (email or URL) is added to the User-Agent so the site can reach you
+	Contact string
+
+	// RequestInterval is the minimum time between requests to the site (default:
+	// DefaultRequestInterval); a longer robots.txt Crawl-delay wins
+	RequestInterval time.Duration
+}
+
+// FetchResult is the events from one fetch
+type FetchResult struct {
+	Events []*Event
+
+	// Warnings are pages that failed to fetch or parse; Events are partial when
+	// there are any
+	Warnings []error
+
+	// FailedPages are the URLs of the pages in Warnings; pass them to Update so
+	// those pages' events aren't counted missing
+	FailedPages []string
+}
+
+// Fetch fetches the state and national events listed on vgagolf.org. Pages robots.txt
+// disallows are skipped, and pages that fail temporarily are retried; an error is only
+// returned when no page could be read or ctx is canceled.
+func Fetch(ctx context.Context, opts FetchOptions) (*FetchResult, error) {
+	s := scraper.NewWithOptions(scraper.Options{
+		HostInterval: opts.RequestInterval,
+		Contact:      opts.Contact,
+	})
+	fetched, err := s.FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FetchResult{Events: fetched.Events}
+	for _, warning := range fetched.Warnings {
+		result.Warnings = append(result.Warnings, warning)
+		result.FailedPages = append(result.FailedPages, warning.URL)
+	}
+	return result, nil
+}
+
+// NewEvent creates an event with its ID, StableKey, and FirstSeen set, for events
+// from another source or tests
+func NewEvent(state, title, dateText, city, raw, sourceURL string) *Event {
+	return event.NewEvent(state, title, dateText, city, raw, sourceURL)
+}
+
+// NewSnapshot returns an empty snapshot, for the first run
+func NewSnapshot() *Snapshot {
+	return event.NewSnapshot()
+}
+
+// ReadSnapshot reads a JSON snapshot written by WriteSnapshot or vga-events. Snapshots
+// in older formats are upgraded.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	return event.DecodeSnapshot(data)
+}
+
+// WriteSnapshot writes a snapshot as indented JSON
+func WriteSnapshot(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// UpdateOptions configures Update. The zero value diffs every state with the defaults.
+type UpdateOptions struct {
+	// State limits the diff and the next snapshot to one state code (default: AllStates)
+	State string
+
+	// ConfirmRemovals is how many consecutive fetches must miss an event before it's
+	// reported removed (default: DefaultConfirmRemovals; 1 reports it at once)
+	ConfirmRemovals int
+
+	// RetentionDays is how long removed events are kept, so one that comes back is
+	// reported as restored rather than new (default: DefaultRetentionDays)
+	RetentionDays int
+
+	// Now is the time recorded in the next snapshot (default: time.Now)
+	Now time.Time
+
+	// FailedPages are pages that failed this fetch (FetchResult.FailedPages). Their
+	// events are kept as they were rather than counted missing.
+	FailedPages []string
+}
+
+// Diff is what changed between a snapshot and a fetch
+type Diff struct {
+	New      []*Event  // Events not seen before
+	Removed  []*Event  // Events no longer listed, once confirmed
+	Restored []*Event  // Events listed again after being reported removed
+	Changes  []*Change // Date, title, and city changes, and renamed events
+	States   []string  // States listing their first ever events (none on the first run)
+}
+
+// IsEmpty reports whether nothing changed
+func (d *Diff) IsEmpty() bool {
+	return len(d.New) == 0 && len(d.Removed) == 0 && len(d.Restored) == 0 && len(d.Changes) == 0
+}
+
+// Update diffs the fetched events against the previous snapshot (nil or empty on the
+// first run, which reports every event as new) and returns the snapshot to keep for
+// the next run along with the diff. Events are given short codes ("NV-417") that stay
+// the same from run to run. previous isn't modified.
+func Update(previous *Snapshot, current []*Event, opts UpdateOptions) (*Snapshot, *Diff) {
+	state := strings.ToUpper(strings.TrimSpace(opts.State))
+	if state == "" {
+		state = AllStates
+	}
+	if opts.ConfirmRemovals <= 0 {
+		opts.ConfirmRemovals = DefaultConfirmRemovals
+	}
+	if opts.RetentionDays <= 0 {
+		opts.RetentionDays = DefaultRetentionDays
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if previous != nil && len(previous.Events) == 0 && len(previous.RemovedEvents) == 0 {
+		previous = nil
+	}
+
+	var listed []*Event
+	for _, evt := range current {
+		if state == AllStates || strings.EqualFold(evt.State, state) {
+			listed = append(listed, evt)
+		}
+	}
+	event.AssignShortCodes(listed, previous)
+
+	update := event.UpdateSnapshot(previous, listed, event.UpdateOptions{
+		State:           state,
+		ConfirmRemovals: opts.ConfirmRemovals,
+		RetentionDays:   opts.RetentionDays,
+		Now:             opts.Now,
+		FailedPages:     opts.FailedPages,
+	})
+	next := update.Snapshot
+	diff := &Diff{
+		New:      update.Diff.NewEvents,
+		Removed:  update.Diff.RemovedEvents,
+		Restored: update.Diff.RestoredEvents,
+		Changes:  update.Changes,
+		States:   event.NewStates(previous, update.Diff.NewEvents),
+	}
+
+	return next, diff
+}
@@ -0,0 +1,75 @@
+package vgaevents
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestUpdate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	shadow := NewEvent("NV", "Shadow Creek", "Apr 4 2026", "Las Vegas", "NV - Shadow Creek Apr 4 2026 - Las Vegas", "")
+	wolf := NewEvent("NV", "Wolf Creek", "May 9 2026", "Mesquite", "NV - Wolf Creek May 9 2026 - Mesquite", "")
+	tpc := NewEvent("AZ", "TPC Scottsdale", "May 2 2026", "Scottsdale", "AZ - TPC Scottsdale May 2 2026 - Scottsdale", "")
+	opts := UpdateOptions{ConfirmRemovals: 1, Now: now}
+
+	snapshot, diff := Update(nil, []*Event{shadow, wolf}, opts)
+	if len(diff.New) != 2 || len(diff.States) != 0 {
+		t.Fatalf("first run: %d new, states %v; want 2 and none", len(diff.New), diff.States)
+	}
+	if shadow.ShortCode == "" {
+		t.Error("Update() should assign short codes")
+	}
+
+	// Wolf Creek is dropped, and Arizona lists its first event
+	snapshot, diff = Update(snapshot, []*Event{shadow, tpc}, opts)
+	if len(diff.New) != 1 || len(diff.Removed) != 1 || diff.Removed[0].ID != wolf.ID {
+		t.Fatalf("second run: new %v, removed %v", diff.New, diff.Removed)
+	}
+	if len(diff.States) != 1 || diff.States[0] != "AZ" {
+		t.Errorf("States = %v, want [AZ]", diff.States)
+	}
+
+	// Wolf Creek comes back with a new date under the same listing ID
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, snapshot); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	snapshot, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	_, diff = Update(snapshot, []*Event{shadow, tpc, wolf}, opts)
+	if len(diff.Restored) != 1 || len(diff.New) != 0 || diff.IsEmpty() {
+		t.Errorf("third run: restored %v, new %v", diff.Restored, diff.New)
+	}
+
+	// A date change on a listed event
+	moved := *shadow
+	moved.DateText = "Apr 11 2026"
+	moved.ID = "moved"
+	_, diff = Update(snapshot, []*Event{&moved, tpc}, opts)
+	if len(diff.Changes) != 1 || diff.Changes[0].ChangeType != "date" || diff.Changes[0].NewValue != "Apr 11 2026" {
+		t.Errorf("date change: %+v", diff.Changes)
+	}
+}
+
+func TestUpdateState(t *testing.T) {
+	events := []*Event{
+		NewEvent("NV", "Shadow Creek", "Apr 4 2026", "", "NV - Shadow Creek", ""),
+		NewEvent("AZ", "TPC Scottsdale", "May 2 2026", "", "AZ - TPC Scottsdale", ""),
+	}
+	snapshot, diff := Update(NewSnapshot(), events, UpdateOptions{State: "nv"})
+	if len(diff.New) != 1 || len(snapshot.Events) != 1 || diff.New[0].State != "NV" {
+		t.Errorf("State filter: new %v, snapshot %d events", diff.New, len(snapshot.Events))
+	}
+
+	// With the default ConfirmRemovals, one missed fetch isn't a removal
+	snapshot, diff = Update(snapshot, nil, UpdateOptions{State: "NV"})
+	if len(diff.Removed) != 0 || len(snapshot.Events) != 1 {
+		t.Errorf("first miss: removed %v, snapshot %d events", diff.Removed, len(snapshot.Events))
+	}
+	if _, diff = Update(snapshot, nil, UpdateOptions{State: "NV"}); len(diff.Removed) != 1 {
+		t.Errorf("second miss: removed %v, want the event", diff.Removed)
+	}
+}